@@ -2,26 +2,88 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/caesar-terminal/caesar/internal/config"
+	"github.com/caesar-terminal/caesar/internal/egress"
+	"github.com/caesar-terminal/caesar/internal/fx"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+	"github.com/caesar-terminal/caesar/internal/metrics"
 )
 
 func main() {
+	if _, err := config.ApplyProfileFlag(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid profile: %v\n", err)
+		os.Exit(2)
+	}
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
 		os.Exit(1)
 	}
+	for _, k := range config.UnknownEnv(os.Environ()) {
+		fmt.Fprintf(os.Stderr, "ignoring unknown setting %s; upgrade old env files with caesarctl config migrate\n", k)
+	}
 
-	fmt.Printf("Caesar Trading Terminal starting (env=%s)\n", cfg.Env)
+	msg := i18n.New(cfg.Locale)
+	fmt.Println(msg.T(i18n.CaesarStarting, cfg.Env))
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	// The market data layer and stream subscribers report into feed and
+	// traffic once they exist.
+	feed := metrics.NewFeed(nil)
+	traffic := metrics.NewTraffic(nil)
+	if cfg.MetricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler(feed, traffic))
+		srv := &http.Server{Addr: cfg.MetricsAddr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fmt.Fprintf(os.Stderr, "metrics server: %v\n", err)
+			}
+		}()
+		defer srv.Close()
+	}
+
+	// PnL and limit views convert into the home currency from fx once they
+	// exist; the rates are refreshed here so they are never fetched on a
+	// render path.
+	if cfg.HomeCurrency != fx.USD {
+		guard, err := egress.Parse(cfg.EgressAllow, cfg.EgressPins)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid egress config: %v\n", err)
+			os.Exit(1)
+		}
+		proxies, err := egress.ParseProxies(cfg.Proxy, cfg.ProxyRoutes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid proxy config: %v\n", err)
+			os.Exit(1)
+		}
+		src, err := fx.NewSource(cfg.FXSource, egress.NewHTTPClient(guard, proxies, egress.ClassPricing))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid fx config: %v\n", err)
+			os.Exit(1)
+		}
+		oracle := fx.NewOracle(src, nil)
+		oracle.OnError = func(err error) {
+			fmt.Fprintf(os.Stderr, "fx refresh failed: %v\n", err)
+		}
+		go func() {
+			if err := oracle.Refresh(ctx); err != nil {
+				oracle.OnError(err)
+			}
+			oracle.Run(ctx, time.Duration(cfg.FXRefreshSec)*time.Second)
+		}()
+	}
+
 	<-ctx.Done()
-	fmt.Println("Caesar shutting down")
+	fmt.Println(msg.T(i18n.CaesarShuttingDown))
 }
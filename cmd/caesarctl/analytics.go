@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/config"
+	"github.com/caesar-terminal/caesar/internal/fx"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+)
+
+// runAnalytics prints the signer's session analytics: per-market activity,
+// the rejection heatmap, the busiest hours, and what shadow policy rules
+// would have rejected. Notional is also shown in
+// the home currency, treating USDC as USD.
+func runAnalytics(args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("analytics", flag.ContinueOnError)
+	currency := fs.String("currency", cfg.HomeCurrency, "currency to show notional in, besides USDC")
+	session := fs.String("session", "", "named session to summarize (default: the default session)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("usage: caesarctl analytics [-currency CODE] [-session ID]")
+	}
+	var rate fx.Rate
+	if *currency = strings.ToUpper(*currency); *currency != fx.USD {
+		if rate, err = homeRate(cfg, *currency); err != nil {
+			return err
+		}
+	}
+
+	client, ctx, done, err := dialSigner()
+	if err != nil {
+		return err
+	}
+	defer done()
+	resp, err := client.GetSessionAnalytics(ctx, &signerv1.GetSessionAnalyticsRequest{SessionId: *session})
+	if err != nil {
+		return err
+	}
+
+	since := "-"
+	if resp.Since > 0 {
+		since = time.Unix(0, resp.Since).Format(time.RFC3339)
+	}
+	fmt.Println(msg.T(i18n.CtlAnalyticsSince, since))
+	if rate.Currency != "" {
+		fmt.Println(msg.T(i18n.CtlFXRate, rate.Currency, rate.PerUSD, rate.Currency, rate.Source, rate.At.Format(time.RFC3339)))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	if rate.Currency == "" {
+		fmt.Fprintln(w, "\nMARKET\tSIGNED\tNOTIONAL\tREJECTED")
+	} else {
+		fmt.Fprintf(w, "\nMARKET\tSIGNED\tNOTIONAL\tNOTIONAL (%s)\tREJECTED\n", rate.Currency)
+	}
+	for _, m := range resp.Markets {
+		if rate.Currency == "" {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%d\n", m.TokenId, m.Signed, m.Notional, m.Rejected)
+			continue
+		}
+		usdc, _ := strconv.ParseFloat(m.Notional, 64)
+		fmt.Fprintf(w, "%s\t%d\t%s\t%.2f\t%d\n", m.TokenId, m.Signed, m.Notional, rate.Convert(usdc/1_000_000), m.Rejected)
+	}
+	fmt.Fprintln(w, "\nHOUR (UTC)\tREASON\tREJECTED")
+	for _, b := range resp.Rejections {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", time.Unix(0, b.Hour).UTC().Format("2006-01-02 15:04"), b.Reason, b.Count)
+	}
+	fmt.Fprintln(w, "\nHOUR OF DAY\tSIGNED")
+	for _, h := range resp.BusiestHours {
+		fmt.Fprintf(w, "%02d:00\t%d\n", h.Hour, h.Signed)
+	}
+	if len(resp.ShadowHits) > 0 {
+		fmt.Fprintln(w, "\nSHADOW RULE\tWOULD REJECT")
+		for _, s := range resp.ShadowHits {
+			fmt.Fprintf(w, "%s\t%d\n", s.RuleId, s.Count)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/config"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+	"github.com/caesar-terminal/caesar/internal/signer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+const approvalsUsage = "usage: caesarctl approvals list | approve ID [-note TEXT] | deny ID [-note TEXT]"
+
+func runApprovals(args []string) error {
+	if len(args) == 0 {
+		return errors.New(approvalsUsage)
+	}
+
+	client, ctx, done, err := dialSigner()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	switch args[0] {
+	case "list":
+		return listApprovals(ctx, client)
+	case "approve", "deny":
+		if len(args) < 2 {
+			return errors.New(approvalsUsage)
+		}
+		fs := flag.NewFlagSet("approvals "+args[0], flag.ContinueOnError)
+		note := fs.String("note", "", "note recorded with the decision")
+		if err := fs.Parse(args[2:]); err != nil {
+			return err
+		}
+		resp, err := client.RespondToApproval(ctx, &signerv1.RespondToApprovalRequest{
+			ApprovalId: args[1],
+			Approve:    args[0] == "approve",
+			Note:       *note,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(msg.T(i18n.CtlApprovalsSet, args[1], resp.State))
+		return nil
+	default:
+		return errors.New(approvalsUsage)
+	}
+}
+
+// dialSigner connects to the local signer socket. The returned context
+// labels requests as coming from caesarctl; call done when finished.
+func dialSigner() (signerv1.SignerServiceClient, context.Context, func(), error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	conn, err := grpc.NewClient("unix://"+cfg.Signer.SocketPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx = metadata.AppendToOutgoingContext(ctx, signer.ClientLabelKey, "caesarctl")
+	done := func() {
+		cancel()
+		conn.Close()
+	}
+	return signerv1.NewSignerServiceClient(conn), ctx, done, nil
+}
+
+func listApprovals(ctx context.Context, client signerv1.SignerServiceClient) error {
+	resp, err := client.ListPendingApprovals(ctx, &signerv1.ListPendingApprovalsRequest{})
+	if err != nil {
+		return err
+	}
+	if len(resp.Approvals) == 0 {
+		fmt.Println(msg.T(i18n.CtlApprovalsNone))
+		return nil
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tKIND\tCLIENT\tEXPIRES IN\tSUMMARY")
+	for _, a := range resp.Approvals {
+		left := time.Unix(0, a.ExpiresAt).Sub(now).Truncate(time.Second)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", a.ApprovalId, a.Kind, a.Client, left, a.Summary)
+	}
+	return w.Flush()
+}
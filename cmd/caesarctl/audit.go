@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/anchor"
+	"github.com/caesar-terminal/caesar/internal/config"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+	"github.com/caesar-terminal/caesar/internal/policy"
+	"github.com/caesar-terminal/caesar/internal/signer"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func runAudit(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: caesarctl audit replay -log FILE [flags] | audit verify -log FILE [-anchors FILE] | audit export -log FILE [-out FILE]")
+	}
+	switch args[0] {
+	case "replay":
+		return runAuditReplay(args[1:])
+	case "verify":
+		return runAuditVerify(args[1:])
+	case "export":
+		return runAuditExport(args[1:])
+	default:
+		return fmt.Errorf("unknown audit subcommand %q", args[0])
+	}
+}
+
+func runAuditReplay(args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("audit replay", flag.ContinueOnError)
+	logPath := fs.String("log", "", "signer log containing audit decision records")
+	checksPath := fs.String("policy", cfg.Signer.PolicyChecks, "policy checks file to replay against")
+	marketsPath := fs.String("markets", cfg.Signer.PolicyMarkets, "market metadata file for policy checks")
+	maxOrders := fs.Int64("client-max-orders", cfg.Signer.ClientMaxOrders, "per-client order quota (0 = none)")
+	maxNotional := fs.String("client-max-notional", cfg.Signer.ClientMaxNotional, "per-client notional quota, USDC atomic units")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *logPath == "" {
+		return errors.New("-log is required")
+	}
+
+	checks, err := policy.NewEngine(*checksPath)
+	if err != nil {
+		return err
+	}
+	markets, err := policy.LoadMarkets(*marketsPath)
+	if err != nil {
+		return err
+	}
+	var notional *big.Int
+	if *maxNotional != "" {
+		var ok bool
+		if notional, ok = new(big.Int).SetString(*maxNotional, 10); !ok {
+			return fmt.Errorf("invalid -client-max-notional %q", *maxNotional)
+		}
+	}
+	quotas, err := signer.NewQuotaTracker(*maxOrders, notional)
+	if err != nil {
+		return err
+	}
+
+	records, err := readAuditLog(*logPath)
+	if err != nil {
+		return err
+	}
+
+	mismatches, err := signer.Replay(context.Background(), records, signer.WithPolicy(checks, markets), signer.WithQuotas(quotas))
+	if err != nil {
+		return err
+	}
+	if len(mismatches) == 0 {
+		fmt.Println(msg.T(i18n.CtlAuditReplayOK, len(records)))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "RECORD\tREQUEST\tFIELD\tRECORDED\tREPLAYED")
+	for _, m := range mismatches {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", m.Index+1, m.RequestID, m.Field, m.Recorded, m.Replayed)
+	}
+	w.Flush()
+	return fmt.Errorf("%d of %d decisions did not replay", len(mismatches), len(records))
+}
+
+// runAuditVerify checks the log's hash chain and that every anchored head
+// is still in the log, so history before the last anchor cannot have
+// been rewritten.
+func runAuditVerify(args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("audit verify", flag.ContinueOnError)
+	logPath := fs.String("log", "", "signer log containing audit decision records")
+	anchorsPath := fs.String("anchors", cfg.Signer.AuditAnchorFile, "anchor file written by the signer")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *logPath == "" {
+		return errors.New("-log is required")
+	}
+
+	records, err := readAuditLog(*logPath)
+	if err != nil {
+		return err
+	}
+	if err := signer.VerifyAuditChain(records); err != nil {
+		return err
+	}
+
+	var anchors []anchor.Anchor
+	if *anchorsPath != "" {
+		data, err := os.ReadFile(*anchorsPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		for i, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+			if len(line) == 0 {
+				continue
+			}
+			var a anchor.Anchor
+			if err := json.Unmarshal(line, &a); err != nil {
+				return fmt.Errorf("anchor %d: %w", i+1, err)
+			}
+			anchors = append(anchors, a)
+		}
+	}
+
+	heads := make(map[string]uint64, len(records))
+	for _, r := range records {
+		heads[r.Hash] = r.Seq
+	}
+	for _, a := range anchors {
+		if err := a.Verify(); err != nil {
+			return fmt.Errorf("anchor seq %d: %w", a.Seq, err)
+		}
+		if seq, ok := heads[a.Head]; !ok || seq != a.Seq {
+			return fmt.Errorf("anchor seq %d at %s: anchored record %s is missing from the log", a.Seq, a.At.Format(time.RFC3339), a.Head)
+		}
+	}
+	fmt.Println(msg.T(i18n.CtlAuditVerified, len(records), len(anchors)))
+	return nil
+}
+
+// runAuditExport writes one CSV row per decision, with any computed
+// report columns appended.
+func runAuditExport(args []string) error {
+	fs := flag.NewFlagSet("audit export", flag.ContinueOnError)
+	logPath := fs.String("log", "", "signer log containing audit decision records")
+	out := fs.String("out", "", "CSV output path (default stdout)")
+	tz := fs.String("tz", "", "display time zone for exported timestamps (default CAESAR_DISPLAY_TIMEZONE)")
+	columnsPath := fs.String("columns", "", "computed columns file (default CAESAR_REPORT_COLUMNS)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *logPath == "" {
+		return errors.New("-log is required")
+	}
+	loc, err := displayLocation(*tz)
+	if err != nil {
+		return err
+	}
+	columns, err := reportColumns(*columnsPath)
+	if err != nil {
+		return err
+	}
+	records, err := readAuditLog(*logPath)
+	if err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		if w, err = os.Create(*out); err != nil {
+			return err
+		}
+		defer w.Close()
+	}
+	cw := csv.NewWriter(w)
+	header := []string{"at", "request", "client", "decision", "code", "token", "side", "maker_amount", "taker_amount"}
+	if err := cw.Write(append(header, columns.Names()...)); err != nil {
+		return err
+	}
+	for i, r := range records {
+		order := &signerv1.PolymarketOrder{}
+		if err := protojson.Unmarshal(r.Order, order); err != nil {
+			return fmt.Errorf("record %d: %w", i+1, err)
+		}
+		makerAmount, _ := strconv.ParseFloat(order.MakerAmount, 64)
+		takerAmount, _ := strconv.ParseFloat(order.TakerAmount, 64)
+		side := strings.TrimPrefix(order.Side.String(), "ORDER_SIDE_")
+		at := r.At.In(loc).Format(time.RFC3339Nano)
+		row := []string{at, r.RequestID, r.Client, r.Decision, r.Code, order.TokenId, side, order.MakerAmount, order.TakerAmount}
+		computed, err := columns.Row(map[string]any{
+			"at": at, "request": r.RequestID, "client": r.Client, "decision": r.Decision, "code": r.Code,
+			"token": order.TokenId, "side": side, "maker_amount": makerAmount, "taker_amount": takerAmount,
+		})
+		if err != nil {
+			return fmt.Errorf("record %d: %w", i+1, err)
+		}
+		if err := cw.Write(append(row, computed...)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+	fmt.Fprintln(os.Stderr, msg.T(i18n.CtlAuditExported, len(records)))
+	return nil
+}
+
+func readAuditLog(path string) ([]signer.AuditRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	records, err := signer.ReadAuditLog(f)
+	if err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	return records, nil
+}
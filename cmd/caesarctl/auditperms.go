@@ -0,0 +1,165 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/caesar-terminal/caesar/internal/config"
+)
+
+// minMemlock is the locked-memory limit below which memguard may fail to
+// lock the session key once a few buffers are open.
+const minMemlock = 1 << 20
+
+// runAuditPermissions reports what stands between other local users and
+// the signer: modes of secrets, config and data files, where services
+// listen, the signer's memlock limit, and how callers are told apart.
+func runAuditPermissions(args []string) error {
+	flags := flag.NewFlagSet("audit-permissions", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	return reportChecks(auditPermissions(cfg, flags.Args()))
+}
+
+// auditPermissions checks cfg's files and listeners. secrets are extra
+// files that must be private to their owner, such as env files holding
+// credentials or offline signing keys.
+func auditPermissions(cfg *config.Config, secrets []string) []checkResult {
+	var results []checkResult
+	add := func(name, outcome, hint, format string, a ...any) {
+		results = append(results, checkResult{name: name, outcome: outcome, detail: fmt.Sprintf(format, a...), hint: hint})
+	}
+
+	for _, path := range secrets {
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			add("secret", checkFail, "", "%v", err)
+		case info.Mode().Perm()&0o077 != 0:
+			add("secret", checkFail, "chmod 600 "+path, "%s is %s; other users can read it", path, info.Mode().Perm())
+		default:
+			add("secret", checkOK, "", "%s is %s", path, info.Mode().Perm())
+		}
+	}
+
+	socket := cfg.Signer.SocketPath
+	dir := filepath.Dir(socket)
+	if info, err := os.Stat(dir); err != nil {
+		add("socket", checkWarn, "start the signer, or install the unit caesarctl init writes", "%s does not exist yet", dir)
+	} else if info.Mode().Perm()&0o007 != 0 {
+		add("socket", checkFail, "chmod o-rwx "+dir, "%s is %s; every local user can reach the socket", dir, info.Mode().Perm())
+	} else if info, err := os.Stat(socket); err == nil && info.Mode().Perm()&0o077 != 0 {
+		add("socket", checkFail, "restart the signer; it creates the socket 0600", "%s is %s; other users can connect", socket, info.Mode().Perm())
+	} else {
+		add("socket", checkOK, "", "%s reachable by its owner only", socket)
+	}
+
+	// Anyone who can rewrite these changes what the signer allows, or
+	// hides what it did.
+	for _, f := range []struct{ name, path string }{
+		{"policy", cfg.Signer.PolicyChecks},
+		{"markets", cfg.Signer.PolicyMarkets},
+		{"schemas", cfg.Signer.TypedDataSchemas},
+		{"anchors", cfg.Signer.AuditAnchorFile},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if writable, err := othersCanWrite(f.path); err != nil {
+			add(f.name, checkWarn, "", "%v", err)
+		} else if writable != "" {
+			add(f.name, checkFail, "chmod go-w "+writable, "%s is writable by other users", writable)
+		} else {
+			add(f.name, checkOK, "", "%s writable by its owner only", f.path)
+		}
+	}
+
+	add("listen", checkOK, "", "signer serves on a unix socket only")
+	if cfg.MetricsAddr != "" {
+		if host, port, err := net.SplitHostPort(cfg.MetricsAddr); err == nil && !isLoopback(host) {
+			add("listen", checkWarn, "CAESAR_METRICS_ADDR=127.0.0.1:"+port, "metrics served on %s, beyond this host", cfg.MetricsAddr)
+		} else {
+			add("listen", checkOK, "", "metrics on %s", cfg.MetricsAddr)
+		}
+	}
+	if cfg.Profile == config.ProfileProd && cfg.LocalStackEndpoint != "" {
+		add("listen", checkFail, "unset CAESAR_LOCALSTACK_ENDPOINT", "prod profile sends KMS calls to LocalStack at %s", cfg.LocalStackEndpoint)
+	}
+
+	switch limit, pid, err := signerMemlock(socket); {
+	case err != nil:
+		add("memlock", checkWarn, "", "%v", err)
+	case limit >= 0 && limit < minMemlock:
+		add("memlock", checkWarn, "LimitMEMLOCK=infinity in the signer's unit", "signer (pid %d) may lock only %d bytes", pid, limit)
+	default:
+		add("memlock", checkOK, "", "signer (pid %d) may lock enough memory", pid)
+	}
+
+	// The socket admits its owner only, so callers share one UID and are
+	// told apart only by labels with tokens; quotas keep one of them from
+	// spending the whole session limit.
+	switch {
+	case cfg.Signer.ClientMaxOrders == 0 && cfg.Signer.ClientMaxNotional == "":
+		add("access", checkWarn, "set CAESAR_SIGNER_CLIENT_MAX_ORDERS or CAESAR_SIGNER_CLIENT_MAX_NOTIONAL",
+			"no per-client quotas; any caller can spend the whole session limit")
+	case cfg.Signer.ClientTokens == "":
+		add("access", checkOK, "", "per-client quotas apply per UID; set CAESAR_SIGNER_CLIENT_TOKENS to split them by label")
+	default:
+		add("access", checkOK, "", "per-client quotas apply per authenticated label")
+	}
+	if cfg.Signer.ElevationHash == "" {
+		add("access", checkOK, "", "elevation disabled")
+	} else {
+		add("access", checkOK, "", "elevation requires the operator credential")
+	}
+	if cfg.Signer.AllowPermits {
+		add("access", checkWarn, "unset CAESAR_SIGNER_ALLOW_PERMITS once allowances are granted",
+			"any caller can sign token allowances for the exchange")
+	}
+	if cfg.Signer.AllowRelease {
+		add("access", checkWarn, "unset CAESAR_SIGNER_ALLOW_RELEASE unless cancels are checked before releasing",
+			"the operator can credit value back for orders whose signatures remain valid")
+	}
+	return results
+}
+
+// othersCanWrite returns path or its directory if users other than the
+// owner can write it, or "" if neither is. A sticky directory (like /tmp)
+// only lets owners replace their files.
+func othersCanWrite(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if err == nil && info.Mode().Perm()&0o022 != 0 {
+		return path, nil
+	}
+	dir := filepath.Dir(path)
+	dinfo, err := os.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+	if dinfo.Mode().Perm()&0o022 != 0 && dinfo.Mode()&fs.ModeSticky == 0 {
+		return dir, nil
+	}
+	return "", nil
+}
+
+// isLoopback reports whether a listen host is reachable from this
+// machine only. An empty host listens on every interface.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/book"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+)
+
+func runBook(args []string) error {
+	if len(args) == 0 || args[0] != "export" {
+		return errors.New("usage: caesarctl book export -in FILE -at RFC3339 [flags]")
+	}
+
+	fs := flag.NewFlagSet("book export", flag.ContinueOnError)
+	in := fs.String("in", "", "recorded snapshot file (JSON lines)")
+	token := fs.String("token", "", "token ID to export (default all)")
+	at := fs.String("at", "", "fill timestamp (RFC3339)")
+	before := fs.Duration("before", time.Minute, "window before the fill")
+	after := fs.Duration("after", time.Minute, "window after the fill")
+	out := fs.String("out", "", "CSV output path (default stdout)")
+	hook := fs.String("render-hook", "", "command run with the CSV path as its argument (e.g. a gnuplot/PNG script)")
+	tz := fs.String("tz", "", "display time zone for exported timestamps (default CAESAR_DISPLAY_TIMEZONE)")
+	columnsPath := fs.String("columns", "", "computed columns file (default CAESAR_REPORT_COLUMNS)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *in == "" || *at == "" {
+		return errors.New("-in and -at are required")
+	}
+	if *hook != "" && *out == "" {
+		return errors.New("-render-hook requires -out")
+	}
+
+	loc, err := displayLocation(*tz)
+	if err != nil {
+		return err
+	}
+
+	columns, err := reportColumns(*columnsPath)
+	if err != nil {
+		return err
+	}
+
+	fillAt, err := time.Parse(time.RFC3339, *at)
+	if err != nil {
+		return fmt.Errorf("invalid -at: %w", err)
+	}
+
+	f, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	snaps, err := book.ReadWindow(f, *token, fillAt.Add(-*before), fillAt.Add(*after))
+	if err != nil {
+		return fmt.Errorf("read snapshots: %w", err)
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		if w, err = os.Create(*out); err != nil {
+			return err
+		}
+		defer w.Close()
+	}
+	if err := book.WriteCSV(w, snaps, loc, columns); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	if *hook != "" {
+		cmd := exec.Command(*hook, *out)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("render hook: %w", err)
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, msg.T(i18n.CtlBookExported, len(snaps)))
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/caesar-terminal/caesar/internal/adapter"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+)
+
+// runClobAuth has the signer sign a ClobAuth message and prints the L1
+// headers for deriving or creating CLOB API credentials.
+func runClobAuth(args []string) error {
+	fs := flag.NewFlagSet("clob-auth", flag.ContinueOnError)
+	nonce := fs.Uint64("nonce", 0, "ClobAuth nonce; reuse the one the API key was created with to derive it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("usage: caesarctl clob-auth [-nonce N]")
+	}
+
+	client, ctx, done, err := dialSigner()
+	if err != nil {
+		return err
+	}
+	defer done()
+	resp, err := client.SignClobAuth(ctx, &signerv1.SignClobAuthRequest{Nonce: *nonce})
+	if err != nil {
+		return err
+	}
+
+	h := adapter.L1Headers(resp.Address, resp.Signature, resp.Timestamp, resp.Nonce)
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%s: %s\n", name, h[name][0])
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/config"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+	"github.com/caesar-terminal/caesar/internal/policy"
+	"github.com/caesar-terminal/caesar/internal/signer"
+)
+
+// runBundleKeygen creates the admin key config bundles are signed with.
+// The private key belongs offline; only the public key printed is
+// installed on the signer host, at config.BundleKeyPath, or compiled in.
+func runBundleKeygen(args []string) error {
+	fs := flag.NewFlagSet("config bundle-keygen", flag.ContinueOnError)
+	out := fs.String("o", "", "file to write the private key to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" || fs.NArg() != 0 {
+		return errors.New(configUsage)
+	}
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, base64.StdEncoding.EncodeToString(priv)); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Println(msg.T(i18n.CtlBundleKeygen, *out, config.BundleKeyPath, base64.StdEncoding.EncodeToString(pub)))
+	return nil
+}
+
+// runBundleSign writes BUNDLE.sig, refusing a bundle the signer would not
+// load so a typo is caught before the file is shipped.
+func runBundleSign(args []string) error {
+	fs := flag.NewFlagSet("config bundle-sign", flag.ContinueOnError)
+	keyPath := fs.String("key", "", "private key from bundle-keygen")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *keyPath == "" || fs.NArg() != 1 {
+		return errors.New(configUsage)
+	}
+	path := fs.Arg(0)
+
+	encoded, err := os.ReadFile(*keyPath)
+	if err != nil {
+		return err
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil || len(raw) != ed25519.PrivateKeySize {
+		return fmt.Errorf("%s is not a bundle private key", *keyPath)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	bundle, err := config.ParseBundle(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if rules, err := bundle.PolicyYAML(); err != nil {
+		return err
+	} else if rules != nil {
+		if _, err := policy.ParsePolicy(rules); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if schemas, err := bundle.TypedDataYAML(); err != nil {
+		return err
+	} else if schemas != nil {
+		if _, err := signer.ParseTypedDataSchemas(schemas); err != nil {
+			return fmt.Errorf("%s: typed data: %w", path, err)
+		}
+	}
+	if err := os.WriteFile(path+".sig", config.SignBundle(data, ed25519.PrivateKey(raw)), 0o644); err != nil {
+		return err
+	}
+	fmt.Println(msg.T(i18n.CtlBundleSigned, path, path+".sig"))
+	return nil
+}
+
+// runBundleAccept verifies an installed bundle and records it as the
+// oldest the signer may load from now on. The record belongs to root, so
+// this runs as root whenever a bundle is installed.
+func runBundleAccept(args []string) error {
+	if len(args) != 1 {
+		return errors.New(configUsage)
+	}
+	b, err := config.AcceptBundle(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(msg.T(i18n.CtlBundleAccepted, args[0], b.IssuedAt.Format(time.RFC3339), config.BundleStatePath))
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/caesar-terminal/caesar/internal/config"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+)
+
+const configUsage = "usage: caesarctl config migrate [-w] FILE | config bundle-keygen -o KEYFILE | config bundle-sign -key KEYFILE BUNDLE | config bundle-accept BUNDLE | config totp-secret [-account NAME]"
+
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return errors.New(configUsage)
+	}
+	switch args[0] {
+	case "migrate":
+		return runConfigMigrate(args[1:])
+	case "bundle-keygen":
+		return runBundleKeygen(args[1:])
+	case "bundle-sign":
+		return runBundleSign(args[1:])
+	case "bundle-accept":
+		return runBundleAccept(args[1:])
+	case "totp-secret":
+		return runTOTPSecret(args[1:])
+	}
+	return errors.New(configUsage)
+}
+
+func runConfigMigrate(args []string) error {
+	fs := flag.NewFlagSet("config migrate", flag.ContinueOnError)
+	write := fs.Bool("w", false, "rewrite FILE in place, keeping the original as FILE.bak")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New(configUsage)
+	}
+	path := fs.Arg(0)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	out, notes, err := config.MigrateEnv(data)
+	if err != nil {
+		return err
+	}
+	for _, n := range notes {
+		fmt.Fprintf(os.Stderr, "%s:%d: %s %s: %s\n", path, n.Line, n.Key, n.Action, n.Detail)
+	}
+
+	// Load the upgraded settings so a value the loader rejects is reported
+	// against the file now, not by a daemon at startup.
+	if err := loadsCleanly(out); err != nil {
+		return fmt.Errorf("%s still does not load: %w", path, err)
+	}
+
+	if !*write {
+		_, err := os.Stdout.Write(out)
+		return err
+	}
+	if len(notes) == 0 {
+		fmt.Println(msg.T(i18n.CtlConfigCurrent, path))
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".bak", data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+		return err
+	}
+	fmt.Println(msg.T(i18n.CtlConfigMigrated, path, len(notes), path+".bak"))
+	return nil
+}
+
+// loadsCleanly runs config.Load with the CAESAR_ variables of an env file
+// in place of the process's own.
+func loadsCleanly(env []byte) error {
+	for _, kv := range os.Environ() {
+		if k, _, _ := strings.Cut(kv, "="); strings.HasPrefix(k, "CAESAR_") {
+			os.Unsetenv(k)
+		}
+	}
+	for _, line := range strings.Split(string(env), "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "export ")
+		k, v, ok := strings.Cut(line, "=")
+		if !ok || !strings.HasPrefix(k, "CAESAR_") {
+			continue
+		}
+		os.Setenv(strings.TrimSpace(k), strings.Trim(strings.TrimSpace(v), `"'`))
+	}
+	_, err := config.Load()
+	return err
+}
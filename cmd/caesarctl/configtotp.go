@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/caesar-terminal/caesar/internal/signer"
+)
+
+// runTOTPSecret creates the secret behind the activation second factor.
+// The first line goes into the signer's environment; the otpauth URI is
+// enrolled in an authenticator app, typically as a QR code.
+func runTOTPSecret(args []string) error {
+	fs := flag.NewFlagSet("config totp-secret", flag.ContinueOnError)
+	account := fs.String("account", "signer", "account name shown in the authenticator app")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New(configUsage)
+	}
+	secret, err := signer.GenerateTOTPSecret()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("CAESAR_SIGNER_ACTIVATION_TOTP=%s\n", secret)
+	fmt.Println(signer.TOTPURI(secret, *account))
+	return nil
+}
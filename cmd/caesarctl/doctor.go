@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/config"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+	"github.com/caesar-terminal/caesar/internal/signer"
+)
+
+// Doctor check outcomes. A warning is a setup step still to do; a
+// failure is a setting the deployment cannot run with.
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "FAIL"
+)
+
+type checkResult struct {
+	name, outcome, detail string
+	hint                  string // remediation, for warnings and failures
+}
+
+func runDoctor(args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: caesarctl doctor")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	return reportChecks(doctor(cfg))
+}
+
+// reportChecks prints the results and fails if any check did.
+func reportChecks(results []checkResult) error {
+	failed := 0
+	for _, r := range results {
+		fmt.Printf("%-5s %-10s %s\n", r.outcome, r.name, r.detail)
+		if r.hint != "" {
+			fmt.Printf("%16s %s\n", "fix:", r.hint)
+		}
+		if r.outcome == checkFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.New(msg.T(i18n.CtlDoctorFailed, failed, len(results)))
+	}
+	fmt.Println(msg.T(i18n.CtlDoctorOK, len(results)))
+	return nil
+}
+
+// doctor checks a loaded config against the host: the network it signs
+// for, where the key comes from, the signer socket, and the CLOB.
+func doctor(cfg *config.Config) []checkResult {
+	var results []checkResult
+	add := func(name, outcome, format string, a ...any) {
+		results = append(results, checkResult{name: name, outcome: outcome, detail: fmt.Sprintf(format, a...)})
+	}
+
+	profile := cfg.Profile
+	if profile == "" {
+		profile = "none"
+	}
+	if network, err := signer.NetworkFor(cfg.ChainID); err != nil {
+		add("network", checkFail, "%v", err)
+	} else {
+		add("network", checkOK, "chain %d, profile %s", network.ChainID, profile)
+	}
+
+	switch {
+	case cfg.Signer.KMSKeyID != "" && cfg.LocalStackEndpoint != "":
+		add("key", checkWarn, "KMS key %s via LocalStack %s (development only)", cfg.Signer.KMSKeyID, cfg.LocalStackEndpoint)
+	case cfg.Signer.KMSKeyID != "":
+		add("key", checkOK, "KMS key %s in %s", cfg.Signer.KMSKeyID, cfg.Signer.AWSRegion)
+	default:
+		add("key", checkWarn, "no KMS key; orders can only be signed with signer offline")
+	}
+	if cfg.Profile == config.ProfileProd && cfg.Signer.ProdAddresses == "" {
+		add("key", checkWarn, "no production addresses listed; any key is accepted under prod")
+	}
+
+	socket := cfg.Signer.SocketPath
+	if _, err := os.Stat(filepath.Dir(socket)); err != nil {
+		add("socket", checkWarn, "%s does not exist yet (the systemd unit creates it)", filepath.Dir(socket))
+	} else if _, err := os.Stat(socket); err != nil {
+		add("socket", checkWarn, "signer not running at %s", socket)
+	} else {
+		results = append(results, signerCheck())
+	}
+
+	if cfg.CLOB.URL == "" {
+		add("clob", checkWarn, "no CLOB URL; set CAESAR_CLOB_URL to submit orders")
+	} else {
+		client := &http.Client{Timeout: 5 * time.Second}
+		if resp, err := client.Get(cfg.CLOB.URL); err != nil {
+			add("clob", checkFail, "%s unreachable: %v", cfg.CLOB.URL, err)
+		} else {
+			resp.Body.Close()
+			add("clob", checkOK, "%s reachable", cfg.CLOB.URL)
+		}
+	}
+	return results
+}
+
+// signerCheck asks the running signer for its session status.
+func signerCheck() checkResult {
+	client, ctx, done, err := dialSigner()
+	if err != nil {
+		return checkResult{name: "signer", outcome: checkFail, detail: err.Error()}
+	}
+	defer done()
+	resp, err := client.GetSessionStatus(ctx, &signerv1.GetSessionStatusRequest{})
+	switch {
+	case err != nil:
+		return checkResult{name: "signer", outcome: checkFail, detail: err.Error()}
+	case !resp.Active:
+		return checkResult{name: "signer", outcome: checkWarn, detail: "running, no session activated"}
+	}
+	return checkResult{name: "signer", outcome: checkOK, detail: fmt.Sprintf("session active, %ds left", resp.TtlSeconds)}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const elevateUsage = "usage: caesarctl elevate -scope SCOPE [-scope SCOPE] -for DURATION -reason TEXT | elevate end | elevate hash"
+
+// scopeList collects repeated -scope flags.
+type scopeList []string
+
+func (s *scopeList) String() string     { return strings.Join(*s, ",") }
+func (s *scopeList) Set(v string) error { *s = append(*s, v); return nil }
+
+// runElevate requests or ends an elevation. The passphrase is read from
+// the first line of stdin so it never appears in shell history.
+func runElevate(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "hash":
+			pass, err := readPassphrase()
+			if err != nil {
+				return err
+			}
+			hash, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.DefaultCost)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(hash))
+			return nil
+		case "end":
+			client, ctx, done, err := dialSigner()
+			if err != nil {
+				return err
+			}
+			defer done()
+			if _, err := client.EndElevation(ctx, &signerv1.EndElevationRequest{}); err != nil {
+				return err
+			}
+			fmt.Println(msg.T(i18n.CtlElevationEnded))
+			return nil
+		}
+	}
+
+	fs := flag.NewFlagSet("elevate", flag.ContinueOnError)
+	var scopes scopeList
+	fs.Var(&scopes, "scope", `scope to relax: "approval" or "policy:<rule-id>" (repeatable)`)
+	d := fs.Duration("for", 5*time.Minute, "how long the elevation lasts")
+	reason := fs.String("reason", "", "why the elevation is needed (audited)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(scopes) == 0 || *reason == "" {
+		return errors.New(elevateUsage)
+	}
+
+	pass, err := readPassphrase()
+	if err != nil {
+		return err
+	}
+	client, ctx, done, err := dialSigner()
+	if err != nil {
+		return err
+	}
+	defer done()
+	resp, err := client.ElevateSession(ctx, &signerv1.ElevateSessionRequest{
+		Credential:      pass,
+		Scopes:          scopes,
+		DurationSeconds: int64(d.Seconds()),
+		Reason:          *reason,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(msg.T(i18n.CtlElevationGranted, resp.ElevationId, time.Unix(0, resp.ExpiresAt).Format(time.RFC3339)))
+	return nil
+}
+
+func readPassphrase() (string, error) {
+	fmt.Fprint(os.Stderr, "passphrase: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	pass := strings.TrimRight(line, "\r\n")
+	if pass == "" {
+		return "", errors.New("empty passphrase")
+	}
+	return pass, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/config"
+	"github.com/caesar-terminal/caesar/internal/egress"
+	"github.com/caesar-terminal/caesar/internal/fx"
+)
+
+// homeRate fetches the current USD rate for currency from the configured
+// source, through the egress allowlist and proxy.
+func homeRate(cfg *config.Config, currency string) (fx.Rate, error) {
+	guard, err := egress.Parse(cfg.EgressAllow, cfg.EgressPins)
+	if err != nil {
+		return fx.Rate{}, err
+	}
+	proxies, err := egress.ParseProxies(cfg.Proxy, cfg.ProxyRoutes)
+	if err != nil {
+		return fx.Rate{}, err
+	}
+	src, err := fx.NewSource(cfg.FXSource, egress.NewHTTPClient(guard, proxies, egress.ClassPricing))
+	if err != nil {
+		return fx.Rate{}, err
+	}
+	oracle := fx.NewOracle(src, nil)
+	if currency != fx.USD {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := oracle.Refresh(ctx); err != nil {
+			return fx.Rate{}, err
+		}
+	}
+	return oracle.Rate(currency)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/caesar-terminal/caesar/internal/config"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+)
+
+// runInit walks a new user through the first-run choices, writes the env
+// file (and, for a networked key, a systemd unit for the signer), then
+// runs the doctor checks against the result.
+func runInit(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	out := fs.String("out", "caesar.env", "env file to write")
+	unit := fs.String("unit", "caesar-signer.service", "systemd unit to write")
+	binary := fs.String("binary", "/usr/local/bin/signer", "signer binary the unit runs")
+	force := fs.Bool("force", false, "overwrite existing files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("usage: caesarctl init [-out FILE] [-unit FILE] [-binary PATH] [-force]")
+	}
+	if !*force {
+		for _, p := range []string{*out, *unit} {
+			if _, err := os.Stat(p); err == nil {
+				return fmt.Errorf("%s exists; pass -force to overwrite it", p)
+			}
+		}
+	}
+
+	s, err := askSetup(bufio.NewReader(os.Stdin), os.Stdout)
+	if err != nil {
+		return err
+	}
+	env := s.Env()
+	if err := loadsCleanly(env); err != nil {
+		return fmt.Errorf("generated config does not load: %w", err)
+	}
+	if err := os.WriteFile(*out, env, 0o600); err != nil {
+		return err
+	}
+	fmt.Println(msg.T(i18n.CtlInitWrote, *out))
+
+	if s.KeyBackend == config.KeyBackendKMS {
+		envFile, err := filepath.Abs(*out)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*unit, s.SystemdUnit(*binary, envFile), 0o644); err != nil {
+			return err
+		}
+		fmt.Println(msg.T(i18n.CtlInitWrote, *unit))
+	}
+
+	// loadsCleanly left the new settings in the environment.
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	return reportChecks(doctor(cfg))
+}
+
+// askSetup prompts for each answer until it is valid.
+func askSetup(in *bufio.Reader, out io.Writer) (config.Setup, error) {
+	var s config.Setup
+	var err error
+	ask := func(question, def string, choices ...string) string {
+		if err != nil {
+			return ""
+		}
+		var answer string
+		answer, err = prompt(in, out, question, def, choices)
+		return answer
+	}
+
+	s.Profile = ask("Profile", config.ProfileTestnet, config.ProfileProd, config.ProfileTestnet, config.ProfilePaper)
+	for s.Profile == config.ProfileTestnet && err == nil {
+		if s.CLOBURL = ask("Testnet CLOB URL", ""); s.CLOBURL != "" && s.CLOBURL != config.Profiles[config.ProfileProd]["clob.url"] {
+			break
+		}
+		fmt.Fprintln(out, "the testnet profile needs a non-production CLOB URL")
+	}
+	s.KeyBackend = ask("Key backend", config.KeyBackendKMS, config.KeyBackendKMS, config.KeyBackendOffline)
+	if s.KeyBackend == config.KeyBackendKMS {
+		for s.KMSKeyID == "" && err == nil {
+			s.KMSKeyID = ask("KMS key ID or ARN", "")
+		}
+		s.AWSRegion = ask("AWS region", "us-east-1")
+		s.LocalStack = ask("LocalStack endpoint (empty for AWS)", "")
+	}
+	if s.Profile == config.ProfileProd {
+		s.ProdAddresses = ask("Production key addresses, comma-separated (empty for any)", "")
+	}
+	for err == nil {
+		s.DisplayTimezone = ask("Display time zone", "UTC")
+		if err != nil {
+			break
+		}
+		verr := s.Validate()
+		if verr == nil {
+			break
+		}
+		fmt.Fprintln(out, verr)
+	}
+	return s, err
+}
+
+// prompt reads one answer, returning def for an empty line and asking
+// again while the answer is not one of choices.
+func prompt(in *bufio.Reader, out io.Writer, question, def string, choices []string) (string, error) {
+	label := question
+	if len(choices) > 0 {
+		label += " (" + strings.Join(choices, "/") + ")"
+	}
+	if def != "" {
+		label += " [" + def + "]"
+	}
+	for {
+		fmt.Fprint(out, label+": ")
+		line, err := in.ReadString('\n')
+		if errors.Is(err, io.EOF) && line == "" {
+			return "", io.ErrUnexpectedEOF
+		} else if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			answer = def
+		}
+		if len(choices) == 0 || slices.Contains(choices, answer) {
+			return answer, nil
+		}
+		fmt.Fprintf(out, "choose one of %s\n", strings.Join(choices, ", "))
+	}
+}
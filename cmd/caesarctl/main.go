@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/config"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+	"github.com/caesar-terminal/caesar/internal/report"
+)
+
+// command is a caesarctl subcommand. args excludes the command name.
+type command struct {
+	name  string
+	usage string // i18n message key
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{name: "book", usage: i18n.CtlBookUsage, run: runBook},
+	{name: "policy", usage: i18n.CtlPolicyUsage, run: runPolicy},
+	{name: "approvals", usage: i18n.CtlApprovalsUsage, run: runApprovals},
+	{name: "elevate", usage: i18n.CtlElevateUsage, run: runElevate},
+	{name: "renew", usage: i18n.CtlRenewUsage, run: runRenew},
+	{name: "sessions", usage: i18n.CtlSessionsUsage, run: runSessions},
+	{name: "release", usage: i18n.CtlReleaseUsage, run: runRelease},
+	{name: "recovery", usage: i18n.CtlRecoveryUsage, run: runRecovery},
+	{name: "markets", usage: i18n.CtlMarketsUsage, run: runMarkets},
+	{name: "analytics", usage: i18n.CtlAnalyticsUsage, run: runAnalytics},
+	{name: "offline", usage: i18n.CtlOfflineUsage, run: runOffline},
+	{name: "audit", usage: i18n.CtlAuditUsage, run: runAudit},
+	{name: "clob-auth", usage: i18n.CtlClobAuthUsage, run: runClobAuth},
+	{name: "config", usage: i18n.CtlConfigUsage, run: runConfig},
+	{name: "init", usage: i18n.CtlInitUsage, run: runInit},
+	{name: "doctor", usage: i18n.CtlDoctorUsage, run: runDoctor},
+	{name: "audit-permissions", usage: i18n.CtlAuditPermsUsage, run: runAuditPermissions},
+}
+
+// msg is the message catalog for user-facing output.
+var msg = i18n.New(os.Getenv("CAESAR_LOCALE"))
+
+func main() {
+	args, err := config.ApplyProfileFlag(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if len(args) < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.name == args[0] {
+			if err := c.run(args[1:]); err != nil {
+				fmt.Fprintln(os.Stderr, msg.T(i18n.CtlCommandFailed, c.name, err))
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, msg.T(i18n.CtlUnknownCommand, args[0]))
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, msg.T(i18n.CtlUsage))
+	fmt.Fprintln(os.Stderr, msg.T(i18n.CtlCommands))
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %s\n", msg.T(c.usage))
+	}
+}
+
+// displayLocation resolves an explicit -tz flag, falling back to the
+// configured display time zone.
+func displayLocation(tz string) (*time.Location, error) {
+	if tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -tz: %w", err)
+		}
+		return loc, nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.DisplayLocation(), nil
+}
+
+// reportColumns loads computed export columns from an explicit -columns
+// flag, falling back to the configured file. Columns reading fx.rate get
+// the home currency's current rate (fx.currency names it).
+func reportColumns(path string) (*report.Columns, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		path = cfg.ReportColumns
+	}
+	cols, err := report.Load(path)
+	if err != nil || !cols.Uses("fx.rate") {
+		return cols, err
+	}
+	rate, err := homeRate(cfg, cfg.HomeCurrency)
+	if err != nil {
+		return nil, err
+	}
+	cols.SetVar("fx.rate", rate.PerUSD)
+	cols.SetVar("fx.currency", rate.Currency)
+	fmt.Fprintln(os.Stderr, msg.T(i18n.CtlFXRate, rate.Currency, rate.PerUSD, rate.Currency, rate.Source, rate.At.Format(time.RFC3339)))
+	return cols, nil
+}
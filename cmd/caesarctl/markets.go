@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+)
+
+const marketsUsage = "usage: caesarctl markets paused | pause MARKET -reason TEXT | resume MARKET"
+
+// runMarkets pauses and resumes new orders in individual markets.
+func runMarkets(args []string) error {
+	if len(args) == 0 {
+		return errors.New(marketsUsage)
+	}
+
+	client, ctx, done, err := dialSigner()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	switch args[0] {
+	case "paused":
+		resp, err := client.ListPausedMarkets(ctx, &signerv1.ListPausedMarketsRequest{})
+		if err != nil {
+			return err
+		}
+		if len(resp.Markets) == 0 {
+			fmt.Println(msg.T(i18n.CtlMarketsNonePaused))
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "MARKET\tPAUSED\tBY\tREASON")
+		for _, m := range resp.Markets {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.Market, time.Unix(0, m.PausedAt).Format(time.RFC3339), m.PausedBy, m.Reason)
+		}
+		return w.Flush()
+	case "pause":
+		if len(args) < 2 {
+			return errors.New(marketsUsage)
+		}
+		fs := flag.NewFlagSet("markets pause", flag.ContinueOnError)
+		reason := fs.String("reason", "", "why the market is paused (audited)")
+		if err := fs.Parse(args[2:]); err != nil {
+			return err
+		}
+		if *reason == "" || fs.NArg() > 0 {
+			return errors.New(marketsUsage)
+		}
+		if _, err := client.PauseMarket(ctx, &signerv1.PauseMarketRequest{Market: args[1], Reason: *reason}); err != nil {
+			return err
+		}
+		fmt.Println(msg.T(i18n.CtlMarketPaused, args[1]))
+		return nil
+	case "resume":
+		if len(args) != 2 {
+			return errors.New(marketsUsage)
+		}
+		if _, err := client.ResumeMarket(ctx, &signerv1.ResumeMarketRequest{Market: args[1]}); err != nil {
+			return err
+		}
+		fmt.Println(msg.T(i18n.CtlMarketResumed, args[1]))
+		return nil
+	default:
+		return errors.New(marketsUsage)
+	}
+}
@@ -0,0 +1,75 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// signerMemlock finds the process listening on socket through /proc and
+// returns its soft locked-memory limit in bytes, or -1 for unlimited.
+func signerMemlock(socket string) (limit int64, pid int, err error) {
+	inode, err := socketInode(socket)
+	if err != nil {
+		return 0, 0, err
+	}
+	if pid, err = socketOwner(inode); err != nil {
+		return 0, 0, err
+	}
+	f, err := os.Open(fmt.Sprintf("/proc/%d/limits", pid))
+	if err != nil {
+		return 0, pid, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		rest, ok := strings.CutPrefix(sc.Text(), "Max locked memory")
+		if !ok {
+			continue
+		}
+		soft := strings.Fields(rest)[0]
+		if soft == "unlimited" {
+			return -1, pid, nil
+		}
+		limit, err := strconv.ParseInt(soft, 10, 64)
+		return limit, pid, err
+	}
+	return 0, pid, errors.New("no locked-memory limit reported")
+}
+
+// socketInode returns the inode of the listening Unix socket at path.
+func socketInode(path string) (string, error) {
+	f, err := os.Open("/proc/net/unix")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// Num RefCount Protocol Flags Type St Inode Path
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 8 && fields[7] == path && fields[5] == "01" {
+			return fields[6], nil
+		}
+	}
+	return "", fmt.Errorf("nothing listens on %s", path)
+}
+
+// socketOwner returns a process holding the socket with the given inode.
+// Processes of other users are only visible to root.
+func socketOwner(inode string) (int, error) {
+	target := "socket:[" + inode + "]"
+	fds, _ := filepath.Glob("/proc/[0-9]*/fd/*")
+	for _, fd := range fds {
+		if link, err := os.Readlink(fd); err == nil && link == target {
+			return strconv.Atoi(strings.Split(fd, "/")[2])
+		}
+	}
+	return 0, errors.New("the signer process is not visible to this user")
+}
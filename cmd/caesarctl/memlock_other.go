@@ -0,0 +1,10 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// signerMemlock needs /proc and is unsupported off Linux.
+func signerMemlock(string) (limit int64, pid int, err error) {
+	return 0, 0, errors.New("the locked-memory check needs Linux /proc")
+}
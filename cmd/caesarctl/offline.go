@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+	"github.com/caesar-terminal/caesar/internal/signer"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// The offline workflow for keys that never touch a networked machine:
+//
+//	caesarctl offline export -orders orders.json -out bundle.json
+//	signer offline -bundle bundle.json -key key.hex -limit N -out sigs.json   (air-gapped)
+//	caesarctl offline import -bundle bundle.json -signatures sigs.json -out signed.json
+func runOffline(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: caesarctl offline export -orders FILE -out FILE | offline import -bundle FILE -signatures FILE")
+	}
+	switch args[0] {
+	case "export":
+		return runOfflineExport(args[1:])
+	case "import":
+		return runOfflineImport(args[1:])
+	default:
+		return fmt.Errorf("unknown offline subcommand %q", args[0])
+	}
+}
+
+func runOfflineExport(args []string) error {
+	fs := flag.NewFlagSet("offline export", flag.ContinueOnError)
+	ordersPath := fs.String("orders", "", "unsigned orders (JSON list of PolymarketOrder)")
+	out := fs.String("out", "", "bundle output path")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *ordersPath == "" || *out == "" {
+		return errors.New("-orders and -out are required")
+	}
+
+	data, err := os.ReadFile(*ordersPath)
+	if err != nil {
+		return err
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return fmt.Errorf("parse orders: %w", err)
+	}
+	orders := make([]*signerv1.PolymarketOrder, len(raws))
+	for i, raw := range raws {
+		orders[i] = &signerv1.PolymarketOrder{}
+		if err := protojson.Unmarshal(raw, orders[i]); err != nil {
+			return fmt.Errorf("order %d: %w", i+1, err)
+		}
+	}
+
+	bundle, err := signer.NewOfflineBundle(orders, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(*out, bundle); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, msg.T(i18n.CtlOfflineExported, bundle.ID(), len(orders)))
+	return nil
+}
+
+// offlineSigned is one order of the import output, ready for submission.
+type offlineSigned struct {
+	Order     json.RawMessage `json:"order"`
+	Signature string          `json:"signature"`
+}
+
+func runOfflineImport(args []string) error {
+	fs := flag.NewFlagSet("offline import", flag.ContinueOnError)
+	bundlePath := fs.String("bundle", "", "bundle written by offline export")
+	sigsPath := fs.String("signatures", "", "signatures written by the offline signer")
+	out := fs.String("out", "", "signed orders output path (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *bundlePath == "" || *sigsPath == "" {
+		return errors.New("-bundle and -signatures are required")
+	}
+
+	var bundle signer.OfflineBundle
+	if err := readJSON(*bundlePath, &bundle); err != nil {
+		return err
+	}
+	var sigs signer.OfflineSignatures
+	if err := readJSON(*sigsPath, &sigs); err != nil {
+		return err
+	}
+	signed, err := signer.VerifyOffline(&bundle, &sigs)
+	if err != nil {
+		return err
+	}
+	for _, s := range sigs.Signatures {
+		if s.Error != "" {
+			fmt.Fprintf(os.Stderr, "order %s refused: %s\n", s.Digest, s.Error)
+		}
+	}
+
+	result := make([]offlineSigned, len(signed))
+	for i, s := range signed {
+		raw, err := protojson.Marshal(s.Order)
+		if err != nil {
+			return err
+		}
+		result[i] = offlineSigned{Order: raw, Signature: s.Signature}
+	}
+	if *out == "" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+	} else if err := writeJSON(*out, result); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, msg.T(i18n.CtlOfflineImported, len(signed), len(bundle.Orders), sigs.Signer))
+	return nil
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
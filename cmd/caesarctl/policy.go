@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/i18n"
+	"github.com/caesar-terminal/caesar/internal/policy"
+	"gopkg.in/yaml.v3"
+)
+
+func runPolicy(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: caesarctl policy validate FILE | policy test -policy FILE -orders FILE")
+	}
+	switch args[0] {
+	case "validate":
+		if len(args) != 2 {
+			return errors.New("usage: caesarctl policy validate FILE")
+		}
+		p, err := policy.LoadFile(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println(msg.T(i18n.CtlPolicyValid, args[1], len(p.Rules)))
+		return nil
+	case "test":
+		return runPolicyTest(args[1:])
+	default:
+		return fmt.Errorf("unknown policy subcommand %q", args[0])
+	}
+}
+
+// sampleOrder is one entry of a policy test orders file. Everything other
+// than name and time is flattened into the evaluation environment; an
+// entry looks like
+//
+//	name: late politics buy
+//	time: 2026-03-02T23:30:00Z
+//	order: {side: BUY, price: 0.97, size: 100, value: 97, token_id: "0xabc"}
+//	market: {category: politics}
+//	book: {best_bid: 0.96, best_ask: 0.98, age_seconds: 2}
+type sampleOrder struct {
+	Name   string
+	Time   time.Time
+	Fields map[string]any
+}
+
+func (s *sampleOrder) UnmarshalYAML(node *yaml.Node) error {
+	if err := node.Decode(&s.Fields); err != nil {
+		return err
+	}
+	if name, ok := s.Fields["name"]; ok {
+		s.Name = fmt.Sprint(name)
+		delete(s.Fields, "name")
+	}
+	if at, ok := s.Fields["time"]; ok {
+		switch v := at.(type) {
+		case time.Time:
+			s.Time = v
+		case string:
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return fmt.Errorf("order %q: invalid time: %w", s.Name, err)
+			}
+			s.Time = t
+		default:
+			return fmt.Errorf("order %q: invalid time %v", s.Name, at)
+		}
+		delete(s.Fields, "time")
+	}
+	return nil
+}
+
+func runPolicyTest(args []string) error {
+	fs := flag.NewFlagSet("policy test", flag.ContinueOnError)
+	policyPath := fs.String("policy", "", "policy file (.yaml/.json) or checks file")
+	ordersPath := fs.String("orders", "", "sample orders file (YAML or JSON list)")
+	verbose := fs.Bool("v", false, "list passing rules as well as failures")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *policyPath == "" || *ordersPath == "" {
+		return errors.New("-policy and -orders are required")
+	}
+
+	p, err := policy.LoadFile(*policyPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(*ordersPath)
+	if err != nil {
+		return err
+	}
+	var orders []sampleOrder
+	if err := yaml.Unmarshal(data, &orders); err != nil {
+		return fmt.Errorf("parse orders: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	passed := 0
+	for i, o := range orders {
+		name := o.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		at := o.Time
+		if at.IsZero() {
+			at = time.Now()
+		}
+
+		decisions := p.Evaluate(policy.Flatten(o.Fields), at)
+		ok := true
+		for _, d := range decisions {
+			ok = ok && (d.Passed || d.Shadow)
+		}
+		verdict := "PASS"
+		if !ok {
+			verdict = "REJECT"
+		} else {
+			passed++
+		}
+		fmt.Fprintf(w, "%s\t%s\n", name, verdict)
+		for _, d := range decisions {
+			if d.Passed && !*verbose {
+				continue
+			}
+			result, actual := "pass", d.Actual
+			switch {
+			case d.Err != nil:
+				result, actual = "error", d.Err.Error()
+			case d.Stale && d.Passed:
+				result = "skipped (stale)"
+			case d.Stale:
+				result = "stale"
+			case !d.Passed:
+				result = "fail"
+			}
+			if d.Shadow && !d.Passed {
+				result += " (shadow)"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\twant %s\tgot %s\n", d.RuleID, d.Type, result, d.Threshold, actual)
+		}
+	}
+	w.Flush()
+
+	fmt.Fprintln(os.Stderr, msg.T(i18n.CtlPolicySummary, passed, len(orders)))
+	return nil
+}
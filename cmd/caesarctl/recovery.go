@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+)
+
+const recoveryUsage = "usage: caesarctl recovery [-ack -reason TEXT [-passphrase]]"
+
+// runRecovery shows the signer's report of the last unclean shutdown and,
+// with -ack, acknowledges it so signing resumes.
+func runRecovery(args []string) error {
+	fs := flag.NewFlagSet("recovery", flag.ContinueOnError)
+	ack := fs.Bool("ack", false, "acknowledge the report, resuming signing")
+	reason := fs.String("reason", "", "why signing may resume (audited)")
+	withPass := fs.Bool("passphrase", false, "prompt for the elevation passphrase, when the signer has elevation configured")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || (*ack && *reason == "") || (!*ack && (*reason != "" || *withPass)) {
+		return errors.New(recoveryUsage)
+	}
+
+	var pass string
+	if *withPass {
+		var err error
+		if pass, err = readPassphrase(); err != nil {
+			return err
+		}
+	}
+	client, ctx, done, err := dialSigner()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if *ack {
+		resp, err := client.AcknowledgeRecovery(ctx, &signerv1.AcknowledgeRecoveryRequest{Credential: pass, Reason: *reason})
+		if err != nil {
+			return err
+		}
+		fmt.Println(msg.T(i18n.CtlRecoveryAcknowledged, time.Unix(0, resp.AcknowledgedAt).Format(time.RFC3339)))
+		return nil
+	}
+
+	rep, err := client.GetRecoveryReport(ctx, &signerv1.GetRecoveryReportRequest{})
+	if err != nil {
+		return err
+	}
+	if !rep.UncleanShutdown {
+		fmt.Println(msg.T(i18n.CtlRecoveryClean))
+		return nil
+	}
+	fmt.Println(msg.T(i18n.CtlRecoveryUnclean, time.Unix(0, rep.DetectedAt).Format(time.RFC3339)))
+	switch {
+	case rep.AcknowledgedAt > 0:
+		fmt.Println(msg.T(i18n.CtlRecoveryAckedBy, rep.AcknowledgedBy, time.Unix(0, rep.AcknowledgedAt).Format(time.RFC3339), rep.AcknowledgementReason))
+	case rep.AcknowledgementRequired:
+		fmt.Println(msg.T(i18n.CtlRecoveryPending))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "\nSESSION\tADDRESS\tUSED\tLIMIT\tEXPIRES")
+	for _, l := range rep.Ledgers {
+		id := l.SessionId
+		if id == "" {
+			id = "(default)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", id, l.SessionAddress, l.ValueUsed, l.MaxValueLimit, time.Unix(0, l.ExpiresAt).Format(time.RFC3339))
+	}
+	fmt.Fprintln(w, "\nORDER IN UNKNOWN STATE")
+	for _, o := range rep.UnknownOrders {
+		fmt.Fprintln(w, o)
+	}
+	fmt.Fprintf(w, "\nEXCHANGE\t%s\n", rep.ExchangeReconciliation)
+	return w.Flush()
+}
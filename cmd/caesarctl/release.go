@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+)
+
+const releaseUsage = "usage: caesarctl release -order HASH -reason TEXT [-amount N] [-session ID]"
+
+// runRelease credits the value of a cancelled or partly filled order back
+// to the signer session's limit. The elevation passphrase is read from the
+// first line of stdin.
+func runRelease(args []string) error {
+	fs := flag.NewFlagSet("release", flag.ContinueOnError)
+	order := fs.String("order", "", "order hash (0x-hex EIP-712 digest) of the signed order")
+	amount := fs.String("amount", "", "unfilled value to release, in USDC atomic units (default: all of it)")
+	reason := fs.String("reason", "", "why the value is released (audited)")
+	session := fs.String("session", "", "named session the order was signed in (default: the default session)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *order == "" || *reason == "" || fs.NArg() > 0 {
+		return errors.New(releaseUsage)
+	}
+
+	pass, err := readPassphrase()
+	if err != nil {
+		return err
+	}
+	client, ctx, done, err := dialSigner()
+	if err != nil {
+		return err
+	}
+	defer done()
+	resp, err := client.ReleaseValue(ctx, &signerv1.ReleaseValueRequest{
+		OrderHash:  *order,
+		Amount:     *amount,
+		Reason:     *reason,
+		SessionId:  *session,
+		Credential: pass,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(msg.T(i18n.CtlValueReleased, resp.Released, resp.ValueUsed))
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+)
+
+const renewUsage = "usage: caesarctl renew -for DURATION -reason TEXT [-session ID]"
+
+// runRenew extends the signer session without re-supplying the key. The
+// elevation passphrase is read from the first line of stdin.
+func runRenew(args []string) error {
+	fs := flag.NewFlagSet("renew", flag.ContinueOnError)
+	d := fs.Duration("for", time.Hour, "how long from now the session lasts, up to the session TTL")
+	reason := fs.String("reason", "", "why the session is renewed (audited)")
+	session := fs.String("session", "", "named session to renew (default: the default session)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *reason == "" || fs.NArg() > 0 {
+		return errors.New(renewUsage)
+	}
+
+	pass, err := readPassphrase()
+	if err != nil {
+		return err
+	}
+	client, ctx, done, err := dialSigner()
+	if err != nil {
+		return err
+	}
+	defer done()
+	resp, err := client.RenewSession(ctx, &signerv1.RenewSessionRequest{
+		Credential:      pass,
+		DurationSeconds: int64(d.Seconds()),
+		Reason:          *reason,
+		SessionId:       *session,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(msg.T(i18n.CtlSessionRenewed, time.Unix(0, resp.ExpiresAt).Format(time.RFC3339)))
+	return nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+)
+
+const sessionsUsage = "usage: caesarctl sessions | sessions pause [-session ID] -reason TEXT | sessions resume [-session ID] | sessions tighten [-session ID] [-limit N] [-per-order N] [-max-orders N] [-ttl DURATION] -reason TEXT"
+
+// runSessions lists the default session and every named session with its
+// key, remaining TTL, value used, rolling window headroom and order count,
+// or pauses, resumes or tightens one of them.
+func runSessions(args []string) error {
+	if len(args) > 0 && args[0] == "tighten" {
+		return runSessionTighten(args[1:])
+	}
+	if len(args) > 0 {
+		return runSessionPause(args)
+	}
+	client, ctx, done, err := dialSigner()
+	if err != nil {
+		return err
+	}
+	defer done()
+	resp, err := client.ListSessions(ctx, &signerv1.ListSessionsRequest{})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SESSION\tADDRESS\tTTL\tUSED\tLIMIT\tPER ORDER\tWINDOWS (LEFT/LIMIT)\tORDERS\tPROFILE")
+	for _, s := range resp.Sessions {
+		id := s.SessionId
+		if id == "" {
+			id = "(default)"
+		}
+		st := s.Status
+		if !st.Active {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t-\t-\t-\n", id)
+			continue
+		}
+		ttl := (time.Duration(st.TtlSeconds) * time.Second).String()
+		if st.IdleSeconds > 0 {
+			ttl += fmt.Sprintf(" (idle %s)", time.Duration(st.IdleSeconds)*time.Second)
+		}
+		perOrder := st.MaxOrderValue
+		if perOrder == "" {
+			perOrder = "-"
+		}
+		windows := []string{}
+		for _, v := range st.ValueWindows {
+			windows = append(windows, fmt.Sprintf("%s=%s/%s", time.Duration(v.SpanSeconds)*time.Second, v.Remaining, v.Limit))
+		}
+		if len(windows) == 0 {
+			windows = append(windows, "-")
+		}
+		orders := fmt.Sprint(st.OrdersSigned)
+		if st.MaxOrders > 0 {
+			orders += fmt.Sprintf("/%d", st.MaxOrders)
+		}
+		if st.Paused {
+			id += " (paused)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", id, st.SessionAddress, ttl, st.ValueUsed, st.MaxValueLimit, perOrder, strings.Join(windows, " "), orders, st.ActiveProfile)
+	}
+	return w.Flush()
+}
+
+// runSessionPause freezes or unfreezes signing in one session.
+func runSessionPause(args []string) error {
+	fs := flag.NewFlagSet("sessions "+args[0], flag.ContinueOnError)
+	session := fs.String("session", "", "named session (default: the default session)")
+	reason := fs.String("reason", "", "why signing is paused (audited)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New(sessionsUsage)
+	}
+	name := *session
+	if name == "" {
+		name = "(default)"
+	}
+
+	switch args[0] {
+	case "pause":
+		if *reason == "" {
+			return errors.New(sessionsUsage)
+		}
+		client, ctx, done, err := dialSigner()
+		if err != nil {
+			return err
+		}
+		defer done()
+		if _, err := client.PauseSession(ctx, &signerv1.PauseSessionRequest{SessionId: *session, Reason: *reason}); err != nil {
+			return err
+		}
+		fmt.Println(msg.T(i18n.CtlSessionPaused, name))
+		return nil
+	case "resume":
+		client, ctx, done, err := dialSigner()
+		if err != nil {
+			return err
+		}
+		defer done()
+		if _, err := client.ResumeSession(ctx, &signerv1.ResumeSessionRequest{SessionId: *session}); err != nil {
+			return err
+		}
+		fmt.Println(msg.T(i18n.CtlSessionResumed, name))
+		return nil
+	default:
+		return errors.New(sessionsUsage)
+	}
+}
+
+// runSessionTighten lowers one session's limits or shortens its TTL
+// without ending it.
+func runSessionTighten(args []string) error {
+	fs := flag.NewFlagSet("sessions tighten", flag.ContinueOnError)
+	session := fs.String("session", "", "named session (default: the default session)")
+	limit := fs.String("limit", "", "new cumulative value limit, USDC raw units")
+	perOrder := fs.String("per-order", "", "new per-order cap, USDC raw units")
+	maxOrders := fs.Int64("max-orders", 0, "new cap on orders signed in the session")
+	ttl := fs.Duration("ttl", 0, "expire the session this long from now")
+	reason := fs.String("reason", "", "why the session is tightened (audited)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *reason == "" {
+		return errors.New(sessionsUsage)
+	}
+	name := *session
+	if name == "" {
+		name = "(default)"
+	}
+
+	client, ctx, done, err := dialSigner()
+	if err != nil {
+		return err
+	}
+	defer done()
+	resp, err := client.UpdateSessionPolicy(ctx, &signerv1.UpdateSessionPolicyRequest{
+		SessionId:     *session,
+		MaxValueLimit: *limit,
+		MaxOrderValue: *perOrder,
+		MaxOrders:     *maxOrders,
+		TtlSeconds:    int64(ttl.Seconds()),
+		Reason:        *reason,
+	})
+	if err != nil {
+		return err
+	}
+	perOrderCap, orders := resp.MaxOrderValue, "-"
+	if perOrderCap == "" {
+		perOrderCap = "-"
+	}
+	if resp.MaxOrders > 0 {
+		orders = fmt.Sprint(resp.MaxOrders)
+	}
+	expires := time.Unix(0, resp.ExpiresAt).Format(time.RFC3339)
+	fmt.Println(msg.T(i18n.CtlSessionTightened, name, expires, resp.MaxValueLimit, perOrderCap, orders))
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math/bits"
+	"time"
+)
+
+// histBuckets covers every uint64 nanosecond value: 16 exact buckets below
+// 16ns, then 16 linear sub-buckets per power of two, so a quantile is
+// within about 6% of the true latency in fixed memory however long a soak
+// runs.
+const histBuckets = 61 * 16
+
+type histogram struct {
+	counts [histBuckets]uint64
+	n      uint64
+	max    time.Duration
+}
+
+func bucketOf(ns uint64) int {
+	if ns < 16 {
+		return int(ns)
+	}
+	e := bits.Len64(ns) - 5
+	return (e+1)*16 + int(ns>>e&15)
+}
+
+// bucketMax is the largest value that falls in bucket i.
+func bucketMax(i int) uint64 {
+	if i < 16 {
+		return uint64(i)
+	}
+	e := i/16 - 1
+	return (uint64(16+i%16)<<e + 1<<e) - 1
+}
+
+func (h *histogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	h.counts[bucketOf(uint64(d))]++
+	h.n++
+	if d > h.max {
+		h.max = d
+	}
+}
+
+func (h *histogram) merge(o *histogram) {
+	for i, c := range o.counts {
+		h.counts[i] += c
+	}
+	h.n += o.n
+	if o.max > h.max {
+		h.max = o.max
+	}
+}
+
+// quantile returns the upper bound of the bucket holding the q-th
+// latency, capped at the largest recorded.
+func (h *histogram) quantile(q float64) time.Duration {
+	if h.n == 0 {
+		return 0
+	}
+	rank := uint64(q*float64(h.n-1)) + 1
+	var seen uint64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			if d := time.Duration(bucketMax(i)); d < h.max {
+				return d
+			}
+			return h.max
+		}
+	}
+	return h.max
+}
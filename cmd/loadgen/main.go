@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/awnumar/memguard"
+	"github.com/caesar-terminal/caesar/internal/book"
+	"github.com/caesar-terminal/caesar/internal/config"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/signer"
+	"github.com/caesar-terminal/caesar/internal/stream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// profile is a named set of defaults; flags given on the command line win.
+type profile struct {
+	duration      time.Duration
+	report        time.Duration
+	signWorkers   int
+	signRate      float64
+	subWorkers    int
+	subRate       float64
+	maxErrorRate  float64
+	leakGoroutine int
+	leakHeapMiB   int
+}
+
+var profiles = map[string]profile{
+	// burst finds the saturation point: unthrottled workers for a minute.
+	"burst": {duration: time.Minute, report: 10 * time.Second, signWorkers: 16, subWorkers: 8, maxErrorRate: 0.01, leakGoroutine: 20},
+	// soak holds a steady, modest load for hours, so goroutine or heap
+	// growth stands out from the noise.
+	"soak": {duration: 8 * time.Hour, report: time.Minute, signWorkers: 4, signRate: 20, subWorkers: 4, subRate: 5, maxErrorRate: 0.001, leakGoroutine: 20, leakHeapMiB: 64},
+}
+
+// runID distinguishes a run's order salts from earlier runs' against the
+// same session.
+func runID() uint64 {
+	var b [4]byte
+	rand.Read(b[:])
+	return uint64(binary.BigEndian.Uint32(b[:]))
+}
+
+func main() {
+	defer memguard.Purge()
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "loadgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run drives SignOrder against a signer and book subscriptions through a
+// stream registry, reports p50/p99 latency and error rates every report
+// interval, and fails if the error rate or resource growth over the run
+// exceeds the profile's bounds.
+//
+// Without -local the signer is the running instance on the configured
+// socket, with a session already activated; orders are small BUYs that
+// count against its value limit. With -local a signer is started in this
+// process on a throwaway key. Subscriptions always run in-process, against
+// a registry fed by a synthetic book publisher, as no stream endpoint is
+// served yet.
+func run(args []string) error {
+	fs := flag.NewFlagSet("loadgen", flag.ContinueOnError)
+	profileName := fs.String("profile", "burst", "defaults to start from: burst or soak")
+	local := fs.Bool("local", false, "start a signer in this process on a throwaway key instead of dialing the configured socket")
+	socket := fs.String("socket", "", "signer socket (default: the configured signer socket)")
+	duration := fs.Duration("duration", 0, "how long to run")
+	report := fs.Duration("report", 0, "how often to report")
+	signWorkers := fs.Int("sign-workers", 0, "concurrent SignOrder callers; 0 disables signing")
+	signRate := fs.Float64("sign-rate", 0, "SignOrder calls per second across workers; 0 is unthrottled")
+	subWorkers := fs.Int("subscribe-workers", 0, "concurrent book subscribers; 0 disables subscriptions")
+	subRate := fs.Float64("subscribe-rate", 0, "subscriptions per second across workers; 0 is unthrottled")
+	hold := fs.Int("subscribe-hold", 5, "books read per subscription before closing it")
+	tokens := fs.Int("tokens", 20, "distinct book tokens")
+	publishRate := fs.Float64("publish-rate", 50, "books published per token per second")
+	amount := fs.String("amount", "1000", "maker and taker amount of each order, in atomic units")
+	maxErrorRate := fs.Float64("max-error-rate", 0, "fail when the run's error rate exceeds this fraction")
+	leakGoroutines := fs.Int("leak-goroutines", 0, "fail when this many more goroutines remain after the run than before it")
+	leakHeap := fs.Int("leak-heap", 0, "fail when the heap in use under load grows by more than this many MiB; 0 disables")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	p, ok := profiles[*profileName]
+	if !ok {
+		return fmt.Errorf("unknown profile %q", *profileName)
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["duration"] {
+		*duration = p.duration
+	}
+	if !set["report"] {
+		*report = p.report
+	}
+	if !set["sign-workers"] {
+		*signWorkers = p.signWorkers
+	}
+	if !set["sign-rate"] {
+		*signRate = p.signRate
+	}
+	if !set["subscribe-workers"] {
+		*subWorkers = p.subWorkers
+	}
+	if !set["subscribe-rate"] {
+		*subRate = p.subRate
+	}
+	if !set["max-error-rate"] {
+		*maxErrorRate = p.maxErrorRate
+	}
+	if !set["leak-goroutines"] {
+		*leakGoroutines = p.leakGoroutine
+	}
+	if !set["leak-heap"] {
+		*leakHeap = p.leakHeapMiB
+	}
+	if *duration <= 0 || *report <= 0 || *tokens <= 0 || *publishRate <= 0 || *hold <= 0 {
+		return errors.New("duration, report, tokens, publish-rate and subscribe-hold must be positive")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	var ops []*opStats
+	var client signerv1.SignerServiceClient
+	if *signWorkers > 0 {
+		path := *socket
+		if *local {
+			dir, err := os.MkdirTemp("", "loadgen")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(dir)
+			path = filepath.Join(dir, "signer.sock")
+			stop, err := startLocalSigner(path, *duration)
+			if err != nil {
+				return err
+			}
+			defer stop()
+		} else if path == "" {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			path = cfg.Signer.SocketPath
+		}
+		conn, err := grpc.NewClient("unix://"+path, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		client = signerv1.NewSignerServiceClient(conn)
+	}
+
+	var (
+		reg     *stream.Registry
+		books   *book.Broadcaster
+		tokenID []string
+	)
+	if *subWorkers > 0 {
+		books = book.NewBroadcaster(nil)
+		reg = stream.NewRegistry(stream.Books(books, book.SubscribeOptions{}))
+		defer reg.Close()
+		for i := 0; i < *tokens; i++ {
+			tokenID = append(tokenID, fmt.Sprintf("%d", 1000+i))
+		}
+	}
+
+	runtime.GC()
+	baseline := sample(reg)
+	fmt.Printf("profile %s for %s: %d sign workers (rate %s), %d subscribe workers (rate %s)\n",
+		*profileName, *duration, *signWorkers, rateString(*signRate), *subWorkers, rateString(*subRate))
+	fmt.Printf("baseline: %s\n", baseline)
+
+	loadCtx, stopLoad := context.WithTimeout(ctx, *duration)
+	defer stopLoad()
+	loadCtx = metadata.AppendToOutgoingContext(loadCtx, signer.ClientLabelKey, "loadgen")
+	var wg sync.WaitGroup
+	if client != nil {
+		sign := &signWorkload{client: client, token: "1", amount: *amount, run: runID(), stats: newOpStats("sign")}
+		ops = append(ops, sign.stats)
+		drive(loadCtx, &wg, *signWorkers, *signRate, sign.do)
+	}
+	if reg != nil {
+		sub := &subscribeWorkload{reg: reg, tokens: tokenID, hold: *hold, subscribe: newOpStats("subscribe"), books: newOpStats("book")}
+		ops = append(ops, sub.subscribe, sub.books)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			publishBooks(loadCtx, books, tokenID, *publishRate)
+		}()
+		drive(loadCtx, &wg, *subWorkers, *subRate, sub.do)
+	}
+	if len(ops) == 0 {
+		return errors.New("nothing to run: set -sign-workers or -subscribe-workers")
+	}
+
+	start, last := time.Now(), time.Now()
+	ticker := time.NewTicker(*report)
+	var first, latest resources
+	var peakEnclaves int64
+	peakSubscribers, samples := 0, 0
+	for done := false; !done; {
+		select {
+		case <-loadCtx.Done():
+			done = true
+		case <-ticker.C:
+		}
+		now := time.Now()
+		elapsed, interval := now.Sub(start).Truncate(time.Second), now.Sub(last)
+		last = now
+		if done && interval < *report/10 {
+			break // the run ended on a report; nothing left to show
+		}
+		for _, op := range ops {
+			w, errs := op.flush()
+			fmt.Printf("%8s %-9s %s\n", elapsed, op.name, window(w, errs, interval))
+		}
+		if !done {
+			latest = sample(reg)
+			if samples++; samples == 1 {
+				first = latest
+			}
+			fmt.Printf("%8s %-9s %s\n", elapsed, "runtime", latest)
+			peakEnclaves = max(peakEnclaves, latest.keys.Enclaves)
+			peakSubscribers = max(peakSubscribers, latest.subscribers)
+		}
+	}
+	ticker.Stop()
+	wg.Wait()
+
+	// Give subscriptions and connections a moment to unwind before
+	// comparing against the baseline.
+	time.Sleep(2 * time.Second)
+	runtime.GC()
+	after := sample(reg)
+
+	fmt.Println("summary:")
+	var failures []string
+	for _, op := range ops {
+		errs, kinds := op.errorCounts()
+		n := op.total.n + errs
+		rate := 0.0
+		if n > 0 {
+			rate = float64(errs) / float64(n)
+		}
+		fmt.Printf("  %-9s %d ok, %d errors (%.3f%%) p50=%s p99=%s max=%s %s\n", op.name, op.total.n, errs, 100*rate,
+			op.total.quantile(0.5), op.total.quantile(0.99), op.total.max, strings.Join(kinds, " "))
+		if rate > *maxErrorRate {
+			failures = append(failures, fmt.Sprintf("%s error rate %.3f%% over %.3f%%", op.name, 100*rate, 100**maxErrorRate))
+		}
+	}
+	fmt.Printf("  after run: %s\n", after)
+	// The first sample under load is the heap's working size; growth from
+	// there to the last is what a leak looks like over a soak.
+	if samples > 1 {
+		grown := (int64(latest.heapInuse) - int64(first.heapInuse)) >> 20
+		fmt.Printf("  heap under load grew %dMiB over %d samples\n", grown, samples)
+		if *leakHeap > 0 && grown > int64(*leakHeap) {
+			failures = append(failures, fmt.Sprintf("heap under load grew %dMiB, over %dMiB", grown, *leakHeap))
+		}
+	}
+	if grown := after.goroutines - baseline.goroutines; grown > *leakGoroutines {
+		failures = append(failures, fmt.Sprintf("%d goroutines remain over the baseline", grown))
+	}
+	if after.topics > 0 {
+		failures = append(failures, fmt.Sprintf("%d stream topics still running", after.topics))
+	}
+	// Key material that outlives its use is a security bug: no key may be
+	// open once signing stops, and no session may hold more keys than it
+	// started with.
+	if peakEnclaves > baseline.keys.Enclaves {
+		failures = append(failures, fmt.Sprintf("sealed keys under load reached %d, over the baseline %d", peakEnclaves, baseline.keys.Enclaves))
+	}
+	// Each subscribe worker holds at most one subscription at a time.
+	if peakSubscribers > *subWorkers {
+		failures = append(failures, fmt.Sprintf("subscribers under load reached %d, over %d workers", peakSubscribers, *subWorkers))
+	}
+	if after.keys.Buffers > 0 {
+		failures = append(failures, fmt.Sprintf("%d key buffers still open", after.keys.Buffers))
+	}
+	if grown := after.keys.Enclaves - baseline.keys.Enclaves; grown > 0 {
+		failures = append(failures, fmt.Sprintf("%d more sealed keys than the baseline", grown))
+	}
+	if ctx.Err() != nil {
+		fmt.Println("interrupted")
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// startLocalSigner serves a signer on socket with a fresh random key and a
+// value limit the run cannot exhaust.
+func startLocalSigner(socket string, d time.Duration) (func(), error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	sm := signer.NewSessionManager(d + time.Hour)
+	if err := sm.Activate(context.Background(), key, new(big.Int).Lsh(big.NewInt(1), 100)); err != nil {
+		return nil, err
+	}
+	srv, err := signer.New(socket, sm)
+	if err != nil {
+		sm.Destroy()
+		return nil, err
+	}
+	go srv.Serve()
+	return func() {
+		srv.GracefulStop()
+		sm.Destroy()
+	}, nil
+}
+
+// resources is a point-in-time view of this process, for spotting leaks.
+// With -local it includes the signer and its key material.
+type resources struct {
+	goroutines  int
+	heapInuse   uint64
+	topics      int
+	subscribers int
+	keys        signer.KeyMaterial
+}
+
+func sample(reg *stream.Registry) resources {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	r := resources{goroutines: runtime.NumGoroutine(), heapInuse: m.HeapInuse, keys: signer.LiveKeyMaterial()}
+	if reg != nil {
+		for _, t := range reg.Stats() {
+			r.topics++
+			r.subscribers += t.Subscribers
+		}
+	}
+	return r
+}
+
+func (r resources) String() string {
+	return fmt.Sprintf("goroutines=%d heap=%.1fMiB topics=%d subscribers=%d enclaves=%d buffers=%d",
+		r.goroutines, float64(r.heapInuse)/(1<<20), r.topics, r.subscribers, r.keys.Enclaves, r.keys.Buffers)
+}
+
+func window(h histogram, errs uint64, interval time.Duration) string {
+	return fmt.Sprintf("%7.1f/s ok %5d err p50=%-10s p99=%-10s max=%s",
+		float64(h.n)/interval.Seconds(), errs, h.quantile(0.5), h.quantile(0.99), h.max)
+}
+
+func rateString(r float64) string {
+	if r == 0 {
+		return "unthrottled"
+	}
+	return fmt.Sprintf("%g/s", r)
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/book"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/stream"
+	"google.golang.org/grpc/status"
+)
+
+// opTimeout bounds one operation, so a wedged target shows up as errors
+// rather than a stalled run.
+const opTimeout = 10 * time.Second
+
+// opStats accumulates one operation's outcomes for the current report
+// window and for the whole run.
+type opStats struct {
+	name string
+
+	mu         sync.Mutex
+	window     histogram
+	total      histogram
+	windowErrs uint64
+	errs       map[string]uint64 // by gRPC code or error text
+}
+
+func newOpStats(name string) *opStats {
+	return &opStats{name: name, errs: make(map[string]uint64)}
+}
+
+func (s *opStats) ok(d time.Duration) {
+	s.mu.Lock()
+	s.window.record(d)
+	s.mu.Unlock()
+}
+
+func (s *opStats) fail(err error) {
+	key := err.Error()
+	if st, ok := status.FromError(err); ok {
+		key = st.Code().String()
+	}
+	s.mu.Lock()
+	s.windowErrs++
+	s.errs[key]++
+	s.mu.Unlock()
+}
+
+// flush returns the window's latencies and errors and starts a new
+// window.
+func (s *opStats) flush() (histogram, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, errs := s.window, s.windowErrs
+	s.total.merge(&s.window)
+	s.window, s.windowErrs = histogram{}, 0
+	return w, errs
+}
+
+// errorCounts returns the run's errors, most frequent first.
+func (s *opStats) errorCounts() (total uint64, kinds []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, n := range s.errs {
+		total += n
+		kinds = append(kinds, k)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return s.errs[kinds[i]] > s.errs[kinds[j]] })
+	for i, k := range kinds {
+		kinds[i] = k + "=" + strconv.FormatUint(s.errs[k], 10)
+	}
+	return total, kinds
+}
+
+// drive runs op from concurrency workers until ctx is done, at most rate
+// times a second in total, or as fast as the workers go when rate is 0.
+func drive(ctx context.Context, wg *sync.WaitGroup, concurrency int, rate float64, op func(context.Context)) {
+	var tokens chan struct{}
+	if rate > 0 {
+		tokens = make(chan struct{}, concurrency)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t := time.NewTicker(time.Duration(float64(time.Second) / rate))
+			defer t.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-t.C:
+					select {
+					case tokens <- struct{}{}:
+					default: // every worker is busy; the target is saturated
+					}
+				}
+			}
+		}()
+	}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if tokens != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case <-tokens:
+					}
+				}
+				op(ctx)
+			}
+		}()
+	}
+}
+
+// signWorkload signs small BUY orders. Each order gets a fresh salt so the
+// signer's replay guard never refuses one, and leaves the maker for the
+// signer to fill in from the session key.
+type signWorkload struct {
+	client signerv1.SignerServiceClient
+	token  string
+	amount string
+	run    uint64 // distinguishes this run's salts from earlier runs'
+	stats  *opStats
+
+	mu  sync.Mutex
+	seq uint64
+}
+
+func (w *signWorkload) do(ctx context.Context) {
+	w.mu.Lock()
+	w.seq++
+	salt := strconv.FormatUint(w.run<<32|w.seq, 10)
+	w.mu.Unlock()
+
+	req := &signerv1.SignOrderRequest{Order: &signerv1.PolymarketOrder{
+		TokenId:     w.token,
+		Side:        signerv1.OrderSide_ORDER_SIDE_BUY,
+		MakerAmount: w.amount,
+		TakerAmount: w.amount,
+		Salt:        salt,
+	}}
+	octx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+	start := time.Now()
+	_, err := w.client.SignOrder(octx, req)
+	switch {
+	case ctx.Err() != nil:
+		// The run ended mid-call; not the target's fault.
+	case err != nil:
+		w.stats.fail(err)
+	default:
+		w.stats.ok(time.Since(start))
+	}
+}
+
+// subscribeWorkload churns book subscriptions through a stream.Registry:
+// each operation subscribes to a random token, reads hold books, and
+// closes, so a soak exercises the registry's reference counting and
+// cleanup as much as its fan-out. Book latency is measured from the
+// snapshot's publish time.
+type subscribeWorkload struct {
+	reg       *stream.Registry
+	tokens    []string
+	hold      int
+	subscribe *opStats // subscribe to first book
+	books     *opStats // publish to delivery
+}
+
+func (w *subscribeWorkload) do(ctx context.Context) {
+	octx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+	start := time.Now()
+	sub, err := w.reg.Subscribe(octx, stream.BookTopic(w.tokens[rand.IntN(len(w.tokens))]), 16)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.subscribe.fail(err)
+		}
+		return
+	}
+	defer sub.Close()
+
+	for i := 0; i < w.hold; i++ {
+		v, ok := <-sub.C
+		if !ok {
+			switch err := sub.Err(); {
+			case ctx.Err() != nil:
+			case errors.Is(err, context.DeadlineExceeded):
+				w.subscribe.fail(errors.New("no book within " + opTimeout.String()))
+			default:
+				w.subscribe.fail(err)
+			}
+			return
+		}
+		now := time.Now()
+		if i == 0 {
+			w.subscribe.ok(now.Sub(start))
+		}
+		w.books.ok(now.Sub(v.(book.Snapshot).At))
+	}
+}
+
+// publishBooks publishes a small book for every token rate times a
+// second until ctx is done, standing in for the market data feed.
+func publishBooks(ctx context.Context, b *book.Broadcaster, tokens []string, rate float64) {
+	t := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			for _, tok := range tokens {
+				b.Publish(book.Snapshot{
+					TokenID: tok,
+					At:      time.Now(),
+					Bids:    []book.Level{{Price: 490_000, Size: 100_000_000}, {Price: 480_000, Size: 250_000_000}},
+					Asks:    []book.Level{{Price: 510_000, Size: 100_000_000}, {Price: 520_000, Size: 250_000_000}},
+				})
+			}
+		}
+	}
+}
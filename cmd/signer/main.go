@@ -2,32 +2,461 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"net/http"
+	"net/smtp"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/awnumar/memguard"
+	"github.com/caesar-terminal/caesar/internal/anchor"
 	"github.com/caesar-terminal/caesar/internal/config"
+	"github.com/caesar-terminal/caesar/internal/egress"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+	"github.com/caesar-terminal/caesar/internal/notify"
+	"github.com/caesar-terminal/caesar/internal/policy"
 	"github.com/caesar-terminal/caesar/internal/signer"
 )
 
 func main() {
 	defer memguard.Purge()
 
+	args, err := config.ApplyProfileFlag(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid profile: %v\n", err)
+		os.Exit(2)
+	}
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
 		os.Exit(1)
 	}
+	for _, k := range config.UnknownEnv(os.Environ()) {
+		fmt.Fprintf(os.Stderr, "ignoring unknown setting %s; upgrade old env files with caesarctl config migrate\n", k)
+	}
+	network, err := signer.NetworkFor(cfg.ChainID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid chain id: %v\n", err)
+		os.Exit(1)
+	}
+	keyGuard := signer.ProdKeyGuard(cfg.Profile == config.ProfileProd, strings.Split(cfg.Signer.ProdAddresses, ","))
+	pins, err := signer.ParseKeyPins(cfg.Signer.PinnedAddresses)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid pinned addresses: %v\n", err)
+		os.Exit(1)
+	}
+	guardFor := func(id string) func(string) error {
+		if pin, ok := pins[id]; ok {
+			return signer.KeyGuards(keyGuard, signer.PinnedKeyGuard(pin))
+		}
+		return keyGuard
+	}
 
-	fmt.Printf("Caesar Signer starting (env=%s, socket=%s)\n", cfg.Env, cfg.Signer.SocketPath)
+	msg := i18n.New(cfg.Locale)
+	if len(args) > 0 && args[0] == "offline" {
+		if err := runOffline(cfg, msg, network, guardFor(signer.DefaultSessionID), args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "offline signing failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Println(msg.T(i18n.SignerStarting, cfg.Env, cfg.Signer.SocketPath))
+	if cfg.Profile != "" {
+		fmt.Println(msg.T(i18n.SignerProfile, cfg.Profile, network.ChainID))
+	}
+
+	// The signer holds the trading key, so its only outbound traffic
+	// (alerts) is confined to the egress allowlist when one is set.
+	guard, err := egress.Parse(cfg.EgressAllow, cfg.EgressPins)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid egress config: %v\n", err)
+		os.Exit(1)
+	}
+	proxies, err := egress.ParseProxies(cfg.Proxy, cfg.ProxyRoutes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid proxy config: %v\n", err)
+		os.Exit(1)
+	}
+	var sendMail notify.SendMailFunc
+	if guard != nil {
+		guard.OnBlock = func(host, reason string) {
+			fmt.Fprintf(os.Stderr, "egress blocked host=%s reason=%q\n", host, reason)
+		}
+		sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			host, _, _ := strings.Cut(addr, ":")
+			if err := guard.Check(context.Background(), host); err != nil {
+				return err
+			}
+			return smtp.SendMail(addr, a, from, to, msg)
+		}
+	}
+
+	notifier, err := newNotifier(cfg.Notify, egress.NewHTTPClient(guard, proxies, egress.ClassAlerts), sendMail)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid notification config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// The reaper drops a session's key as soon as its TTL elapses, rather
+	// than at the next call that needs it.
+	reapHook := func(id string) signer.SessionOption {
+		return signer.WithReapHook(func(ev signer.SessionExpiry) {
+			entry, _ := json.Marshal(ev)
+			fmt.Fprintf(os.Stderr, "session %q expired %s\n", id, entry)
+			title := "session expired"
+			if ev.Idle {
+				title = "session expired after sitting idle"
+			}
+			notifier.send(notify.Notification{
+				Kind:     notify.KindSession,
+				Severity: notify.SeverityInfo,
+				Title:    title,
+				Body:     fmt.Sprintf("session=%q address=%s expired=%s", id, ev.Address, ev.ExpiredAt.Format(time.RFC3339)),
+			})
+		})
+	}
+
+	var (
+		journal  *signer.Journal
+		recovery *signer.Recovery
+	)
+	if cfg.Signer.LedgerJournal != "" {
+		if journal, err = signer.OpenJournal(cfg.Signer.LedgerJournal); err != nil {
+			fmt.Fprintf(os.Stderr, "ledger journal: %v\n", err)
+			os.Exit(1)
+		}
+		defer journal.Close()
+		recovery = signer.NewRecovery(journal, cfg.Signer.RecoveryAckRequired, nil)
+		if rep := recovery.Report(); rep.Unclean {
+			for _, e := range rep.Ledgers {
+				fmt.Fprintf(os.Stderr, "session %q was not shut down: %s spent of %s on %s until %s\n",
+					e.Session, e.Used, e.Limit, e.Address, e.ExpiresAt.Format(time.RFC3339))
+			}
+			for _, o := range rep.UnknownOrders {
+				fmt.Fprintf(os.Stderr, "order %s in unknown state\n", o)
+			}
+			fmt.Fprintf(os.Stderr, "exchange %s\n", rep.Exchange)
+			severity, paused := notify.SeverityWarning, ""
+			if rep.AckRequired {
+				severity, paused = notify.SeverityCritical, "; signing paused until acknowledged"
+				fmt.Fprintln(os.Stderr, "signing paused until the recovery report is acknowledged: caesarctl recovery -ack -reason ...")
+			}
+			notifier.send(notify.Notification{
+				Kind:     notify.KindSession,
+				Severity: severity,
+				Title:    "signer recovered from an unclean shutdown" + paused,
+				Body:     fmt.Sprintf("ledgers=%d unknown_orders=%d", len(rep.Ledgers), len(rep.UnknownOrders)),
+			})
+		}
+	}
+	sessionOpts := func(id string) []signer.SessionOption {
+		opts := []signer.SessionOption{signer.WithKeyGuard(guardFor(id)), signer.WithVOffset(byte(cfg.Signer.SignatureV)), reapHook(id)}
+		if journal != nil {
+			opts = append(opts, signer.WithJournal(journal, id))
+		}
+		if cfg.Signer.SessionIdleSec > 0 {
+			opts = append(opts, signer.WithIdleTimeout(time.Duration(cfg.Signer.SessionIdleSec)*time.Second))
+		}
+		return opts
+	}
 
 	ttl := time.Duration(cfg.Signer.SessionTTLSec) * time.Second
-	session := signer.NewSessionManager(ttl)
+	session := signer.NewSessionManager(ttl, sessionOpts(signer.DefaultSessionID)...)
+
+	profiles, err := signer.ParseLimitProfiles(cfg.Signer.LimitProfiles, cfg.DisplayLocation())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid limit profiles: %v\n", err)
+		os.Exit(1)
+	}
+	session.SetLimitProfiles(profiles)
 
-	srv, err := signer.New(cfg.Signer.SocketPath, session)
+	var maxOrderValue *big.Int
+	if cfg.Signer.MaxOrderValue != "" {
+		var ok bool
+		if maxOrderValue, ok = new(big.Int).SetString(cfg.Signer.MaxOrderValue, 10); !ok {
+			fmt.Fprintf(os.Stderr, "invalid max order value: %s\n", cfg.Signer.MaxOrderValue)
+			os.Exit(1)
+		}
+	}
+	if err := session.SetMaxOrderValue(maxOrderValue); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid max order value: %v\n", err)
+		os.Exit(1)
+	}
+	windows, err := signer.ParseValueWindows(cfg.Signer.ValueWindows)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid value windows: %v\n", err)
+		os.Exit(1)
+	}
+	if err := session.SetValueWindows(windows); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid value windows: %v\n", err)
+		os.Exit(1)
+	}
+	if err := session.SetOrderLimits(cfg.Signer.MaxOrdersPerSession, cfg.Signer.MaxSignsPerMinute); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid order limits: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Named sessions share every setting but the key, TTL and limits.
+	sessionTTLs, err := signer.ParseSessions(cfg.Signer.Sessions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid sessions: %v\n", err)
+		os.Exit(1)
+	}
+	for id := range pins {
+		if _, ok := sessionTTLs[id]; !ok && id != signer.DefaultSessionID {
+			fmt.Fprintf(os.Stderr, "invalid pinned addresses: no session %q\n", id)
+			os.Exit(1)
+		}
+	}
+	named := make(map[string]*signer.SessionManager, len(sessionTTLs))
+	for id, d := range sessionTTLs {
+		if d == 0 {
+			d = ttl
+		}
+		named[id] = signer.NewSessionManager(d, sessionOpts(id)...)
+		named[id].SetLimitProfiles(profiles)
+		named[id].SetMaxOrderValue(maxOrderValue)
+		named[id].SetValueWindows(windows)
+		named[id].SetOrderLimits(cfg.Signer.MaxOrdersPerSession, cfg.Signer.MaxSignsPerMinute)
+	}
+
+	detectorCfg := signer.DetectorConfig{Escalate: cfg.Signer.AnomalyEscalate}
+	if cfg.Signer.QuietHours != "" {
+		start, end, err := signer.ParseWindow(cfg.Signer.QuietHours)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid quiet hours: %v\n", err)
+			os.Exit(1)
+		}
+		detectorCfg.QuietStart, detectorCfg.QuietEnd = start, end
+		detectorCfg.QuietLocation = cfg.DisplayLocation()
+	}
+	detector := signer.NewDetector(detectorCfg, func(a signer.Anomaly) {
+		text := msg.T(i18n.SignerAnomaly, a.Kind, a.Market, a.Detail)
+		fmt.Fprintln(os.Stderr, text)
+		notifier.send(notify.Notification{Kind: notify.KindAnomaly, Severity: notify.SeverityWarning, Title: text})
+	})
+
+	canaries := signer.WithCanaries(signer.ParseCanaryTokens(cfg.Signer.CanaryTokens), func(c signer.CanaryTrip) {
+		text := msg.T(i18n.SignerCanaryTrip, c.TokenID)
+		fmt.Fprintf(os.Stderr, "%s (request_id=%s)\n", text, c.RequestID)
+		notifier.send(notify.Notification{
+			Kind:     notify.KindCanary,
+			Severity: notify.SeverityCritical,
+			Title:    text,
+			Body:     "request_id=" + c.RequestID,
+		})
+	})
+
+	var clientMaxNotional *big.Int
+	if cfg.Signer.ClientMaxNotional != "" {
+		var ok bool
+		if clientMaxNotional, ok = new(big.Int).SetString(cfg.Signer.ClientMaxNotional, 10); !ok {
+			fmt.Fprintf(os.Stderr, "invalid client max notional: %s\n", cfg.Signer.ClientMaxNotional)
+			os.Exit(1)
+		}
+	}
+	quotas, err := signer.NewQuotaTracker(cfg.Signer.ClientMaxOrders, clientMaxNotional)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid client max notional: %v\n", err)
+		os.Exit(1)
+	}
+	clientTokens, err := signer.ParseClientTokens(cfg.Signer.ClientTokens)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid client tokens: %v\n", err)
+		os.Exit(1)
+	}
+
+	checks, err := loadPolicy(cfg.Signer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid policy checks: %v\n", err)
+		os.Exit(1)
+	}
+	if b := cfg.Signer.Bundle; b != nil {
+		fmt.Println(msg.T(i18n.SignerBundle, b.IssuedAt.Format(time.RFC3339), len(b.Settings), len(checks.Policy().Rules)))
+	}
+	markets, err := loadMarkets(cfg.Signer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid policy markets: %v\n", err)
+		os.Exit(1)
+	}
+
+	var totp *signer.TOTP
+	if cfg.Signer.ActivationTOTP != "" {
+		totp, err = signer.NewTOTP(cfg.Signer.ActivationTOTP, nil, func(ev signer.SecondFactorEvent) {
+			entry, _ := json.Marshal(ev)
+			fmt.Fprintf(os.Stderr, "audit second_factor %s\n", entry)
+			if !ev.Granted {
+				notifier.send(notify.Notification{
+					Kind:     notify.KindSession,
+					Severity: notify.SeverityWarning,
+					Title:    "activation second factor refused",
+					Body:     fmt.Sprintf("client=%s action=%s detail=%s", ev.Client, ev.Action, ev.Detail),
+				})
+			}
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid activation totp: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var elevator *signer.Elevator
+	if cfg.Signer.ElevationHash != "" {
+		elevator, err = signer.NewElevator([]byte(cfg.Signer.ElevationHash), time.Duration(cfg.Signer.ElevationMaxMin)*time.Minute, nil, func(ev signer.ElevationEvent) {
+			entry, _ := json.Marshal(ev)
+			fmt.Fprintf(os.Stderr, "audit elevation %s\n", entry)
+			if ev.Kind == signer.ElevationGranted {
+				notifier.send(notify.Notification{
+					Kind:     notify.KindSession,
+					Severity: notify.SeverityWarning,
+					Title:    "session elevated: " + strings.Join(ev.Scopes, ", "),
+					Body:     fmt.Sprintf("client=%s reason=%q expires=%s", ev.Client, ev.Reason, ev.ExpiresAt.Format(time.RFC3339)),
+				})
+			}
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid elevation config: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Pausing a market is for breaking news, so everyone hears of it.
+	pauses := signer.NewMarketPauses(nil, func(ev signer.MarketPauseEvent) {
+		entry, _ := json.Marshal(ev)
+		fmt.Fprintf(os.Stderr, "audit market_pause %s\n", entry)
+		title := "market paused: " + ev.Market
+		if ev.Resumed {
+			title = "market resumed: " + ev.Market
+		}
+		notifier.send(notify.Notification{
+			Kind:     notify.KindSession,
+			Severity: notify.SeverityWarning,
+			Title:    title,
+			Body:     fmt.Sprintf("client=%s reason=%q", ev.Client, ev.Reason),
+		})
+	})
+
+	// Decision records are hash-chained; the chain head is timestamped by
+	// an external authority when one is configured.
+	var chain signer.AuditChain
+	var anchorer *anchor.Anchorer
+	if cfg.Signer.AuditTSAURL != "" {
+		tsa := anchor.NewTSA(cfg.Signer.AuditTSAURL, egress.NewHTTPClient(guard, proxies, egress.ClassAudit))
+		anchorer = anchor.NewAnchorer(chain.Head, tsa, nil)
+		anchorer.OnAnchor = func(a anchor.Anchor) {
+			if err := appendJSONLine(cfg.Signer.AuditAnchorFile, a); err != nil {
+				fmt.Fprintf(os.Stderr, "audit anchor not saved: %v\n", err)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "audit anchor seq=%d head=%s at=%s\n", a.Seq, a.Head, a.At.Format(time.RFC3339))
+		}
+		anchorer.OnError = func(err error) {
+			fmt.Fprintf(os.Stderr, "audit anchor failed: %v\n", err)
+		}
+	}
+
+	var wallets signer.WalletVerifier
+	if cfg.Signer.PolygonRPC != "" {
+		wallets = signer.NewRPCWalletVerifier(cfg.Signer.PolygonRPC, egress.NewHTTPClient(guard, proxies, egress.ClassRPC))
+	}
+
+	typedSchemas, err := loadTypedDataSchemas(cfg.Signer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load typed data schemas: %v\n", err)
+		os.Exit(1)
+	}
+
+	salts, err := signer.ParseSaltStrategy(cfg.Signer.SaltStrategy, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid salt strategy: %v\n", err)
+		os.Exit(1)
+	}
+
+	srv, err := signer.New(cfg.Signer.SocketPath, session,
+		signer.WithSessions(named),
+		signer.WithNetwork(network),
+		signer.WithSalts(salts),
+		signer.WithReplayGuard(signer.NewReplayGuard()),
+		signer.WithPermits(cfg.Signer.AllowPermits),
+		signer.WithValueRelease(cfg.Signer.AllowRelease),
+		signer.WithDetector(detector),
+		signer.WithWalletVerifier(wallets),
+		signer.WithTypedDataSchemas(typedSchemas),
+		canaries,
+		signer.WithQuotas(quotas),
+		signer.WithClientTokens(clientTokens),
+		signer.WithAnalytics(signer.NewSessionAnalytics(cfg.DisplayLocation())),
+		signer.WithPolicy(checks, markets),
+		signer.WithApprovals(signer.NewApprovalInbox(time.Duration(cfg.Signer.ApprovalTTLSec)*time.Second, nil), func(a signer.Approval) {
+			notifier.send(notify.Notification{
+				Kind:     notify.KindApproval,
+				Severity: notify.SeverityWarning,
+				Title:    "approval pending: " + a.Summary,
+				Body:     fmt.Sprintf("approval_id=%s client=%s expires=%s", a.ID, a.Client, a.ExpiresAt.Format(time.RFC3339)),
+			})
+		}),
+		signer.WithElevation(elevator),
+		signer.WithActivationTOTP(totp),
+		signer.WithPolicyAudit(func(r signer.PolicyRejection) {
+			entry, _ := json.Marshal(r)
+			kind := "policy_rejection"
+			if r.Shadow {
+				kind = "policy_shadow"
+			}
+			fmt.Fprintf(os.Stderr, "audit %s %s\n", kind, entry)
+		}),
+		signer.WithAudit(func(r signer.AuditRecord) {
+			chain.Log(os.Stderr, r)
+		}),
+		signer.WithReleaseAudit(func(r signer.ValueRelease) {
+			entry, _ := json.Marshal(r)
+			fmt.Fprintf(os.Stderr, "audit value_release %s\n", entry)
+		}),
+		signer.WithRecovery(recovery),
+		signer.WithMarketPauses(pauses),
+		signer.WithSessionPauseAudit(func(ev signer.SessionPauseEvent) {
+			entry, _ := json.Marshal(ev)
+			fmt.Fprintf(os.Stderr, "audit session_pause %s\n", entry)
+			title := "session paused: " + ev.Address
+			if ev.Resumed {
+				title = "session resumed: " + ev.Address
+			}
+			notifier.send(notify.Notification{
+				Kind:     notify.KindSession,
+				Severity: notify.SeverityWarning,
+				Title:    title,
+				Body:     fmt.Sprintf("session=%q client=%s reason=%q", ev.SessionID, ev.Client, ev.Reason),
+			})
+		}),
+		signer.WithSessionPolicyAudit(func(ev signer.SessionPolicyEvent) {
+			entry, _ := json.Marshal(ev)
+			fmt.Fprintf(os.Stderr, "audit session_policy %s\n", entry)
+			notifier.send(notify.Notification{
+				Kind:     notify.KindSession,
+				Severity: notify.SeverityWarning,
+				Title:    "session tightened: " + ev.Address,
+				Body:     fmt.Sprintf("session=%q client=%s reason=%q limit=%s expires=%s", ev.SessionID, ev.Client, ev.Reason, ev.MaxValueLimit, ev.ExpiresAt.Format(time.RFC3339)),
+			})
+		}),
+		signer.WithRecoveryAudit(func(a signer.RecoveryAck) {
+			entry, _ := json.Marshal(a)
+			fmt.Fprintf(os.Stderr, "audit recovery_ack %s\n", entry)
+		}),
+		signer.WithLimitAlert(func(b signer.LimitBreach) {
+			notifier.send(notify.Notification{
+				Kind:     notify.KindLimitExceeded,
+				Severity: notify.SeverityCritical,
+				Title:    "session value limit exceeded",
+				Body:     fmt.Sprintf("client=%s value=%s request_id=%s", b.Client, b.Value, b.RequestID),
+			})
+		}),
+	)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to create signer server: %v\n", err)
 		os.Exit(1)
@@ -36,19 +465,45 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	go session.Run(ctx, time.Second)
+	for _, sm := range named {
+		go sm.Run(ctx, time.Second)
+	}
+	if elevator != nil {
+		go elevator.Run(ctx, time.Second)
+	}
+	if anchorer != nil {
+		go anchorer.Run(ctx, time.Duration(cfg.Signer.AuditAnchorSec)*time.Second)
+	}
+	// A bad edit to the checks file keeps the previous checks in force.
+	go checks.Run(ctx, time.Duration(cfg.Signer.PolicyReloadSec)*time.Second, func(err error) {
+		fmt.Fprintf(os.Stderr, "policy reload failed: %v\n", err)
+	})
+
 	// Run gRPC server in a goroutine so we can wait for shutdown signals.
 	errCh := make(chan error, 1)
 	go func() {
 		errCh <- srv.Serve()
 	}()
 
-	fmt.Println("Signer ready — listening on UDS")
+	fmt.Println(msg.T(i18n.SignerReady))
 
 	select {
 	case <-ctx.Done():
-		fmt.Println("Signer shutting down gracefully...")
+		fmt.Println(msg.T(i18n.SignerShuttingDown))
 		session.Destroy()
+		for _, sm := range named {
+			sm.Destroy()
+		}
 		srv.GracefulStop()
+		// Anchor the final head so the last run's records are covered.
+		if anchorer != nil {
+			anchorCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if _, _, err := anchorer.AnchorNow(anchorCtx); err != nil {
+				fmt.Fprintf(os.Stderr, "audit anchor failed: %v\n", err)
+			}
+			cancel()
+		}
 	case err := <-errCh:
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "signer server error: %v\n", err)
@@ -56,5 +511,138 @@ func main() {
 		}
 	}
 
-	fmt.Println("Signer stopped")
+	fmt.Println(msg.T(i18n.SignerStopped))
+}
+
+// notifier delivers alerts in the background so a slow channel never
+// delays signing. A nil notifier drops everything.
+type notifier struct {
+	router *notify.Router
+}
+
+func (n *notifier) send(note notify.Notification) {
+	if n == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		for _, err := range n.router.Notify(ctx, note) {
+			fmt.Fprintf(os.Stderr, "notification failed: %v\n", err)
+		}
+	}()
+}
+
+// newNotifier builds the configured channels and routes. It returns nil
+// when no routes are configured. Nil client and sendMail use the net/http
+// and net/smtp defaults.
+func newNotifier(cfg config.NotifyConfig, client *http.Client, sendMail notify.SendMailFunc) (*notifier, error) {
+	routes, err := notify.ParseRoutes(cfg.Routes)
+	if err != nil || len(routes) == 0 {
+		return nil, err
+	}
+
+	var channels []notify.Channel
+	if cfg.WebhookURL != "" {
+		channels = append(channels, notify.NewWebhook(cfg.WebhookURL, client))
+	}
+	if cfg.TelegramToken != "" {
+		channels = append(channels, notify.NewTelegram(notify.TelegramAPI, cfg.TelegramToken, cfg.TelegramChatID, client))
+	}
+	if cfg.SMTPAddr != "" {
+		var auth smtp.Auth
+		if cfg.SMTPUser != "" {
+			host, _, _ := strings.Cut(cfg.SMTPAddr, ":")
+			auth = smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPassword, host)
+		}
+		channels = append(channels, notify.NewEmail(cfg.SMTPAddr, auth, cfg.SMTPFrom, strings.Split(cfg.SMTPTo, ","), sendMail))
+	}
+	if cfg.PagerDutyRoutingKey != "" {
+		channels = append(channels, notify.NewPagerDuty(notify.PagerDutyEventsURL, cfg.PagerDutyRoutingKey, client))
+	}
+	if cfg.WebPushVAPIDKey != "" {
+		subs, err := notify.LoadSubscriptions(cfg.WebPushSubs)
+		if err != nil {
+			return nil, err
+		}
+		push, err := notify.NewWebPush(cfg.WebPushVAPIDKey, cfg.WebPushSubject, subs, client)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, push)
+	}
+
+	router, err := notify.NewRouter(channels, routes)
+	if err != nil {
+		return nil, err
+	}
+	return &notifier{router: router}, nil
+}
+
+// appendJSONLine appends v to path as one JSON line, creating the file and
+// its directory if needed.
+func appendJSONLine(path string, v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadPolicy returns the pre-trade policy: the one in the config bundle
+// when the signer is managed by one, so an unsigned policy file cannot
+// relax it, and otherwise the policy checks file.
+func loadPolicy(cfg config.SignerConfig) (*policy.Engine, error) {
+	if cfg.Bundle == nil {
+		return policy.NewEngine(cfg.PolicyChecks)
+	}
+	data, err := cfg.Bundle.PolicyYAML()
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return policy.NewEngine("")
+	}
+	p, err := policy.ParsePolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("config bundle: %w", err)
+	}
+	return policy.NewStaticEngine(p), nil
+}
+
+// loadMarkets returns the policy's market attributes, from the config
+// bundle when the signer is managed by one.
+func loadMarkets(cfg config.SignerConfig) (policy.Markets, error) {
+	if cfg.Bundle == nil {
+		return policy.LoadMarkets(cfg.PolicyMarkets)
+	}
+	return policy.Markets(cfg.Bundle.Markets), nil
+}
+
+// loadTypedDataSchemas returns the schemas SignTypedData may sign, from
+// the config bundle when the signer is managed by one.
+func loadTypedDataSchemas(cfg config.SignerConfig) ([]signer.TypedDataSchema, error) {
+	if cfg.Bundle == nil {
+		return signer.LoadTypedDataSchemas(cfg.TypedDataSchemas)
+	}
+	data, err := cfg.Bundle.TypedDataYAML()
+	if err != nil || data == nil {
+		return nil, err
+	}
+	schemas, err := signer.ParseTypedDataSchemas(data)
+	if err != nil {
+		return nil, fmt.Errorf("config bundle: %w", err)
+	}
+	return schemas, nil
 }
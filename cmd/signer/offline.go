@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/awnumar/memguard"
+	"github.com/caesar-terminal/caesar/internal/config"
+	"github.com/caesar-terminal/caesar/internal/i18n"
+	"github.com/caesar-terminal/caesar/internal/signer"
+)
+
+// runOffline signs a bundle from caesarctl offline export on an
+// air-gapped machine. The key is read from a file and lives only for the
+// run; orders still pass the value limit, policy checks and canaries.
+// Nothing is served and no notification is sent.
+func runOffline(cfg *config.Config, msg *i18n.Catalog, network signer.Network, keyGuard func(string) error, args []string) error {
+	// Bundles carry Polygon digests; see signer.NewOfflineBundle.
+	if network.ChainID != signer.Polygon.ChainID {
+		return fmt.Errorf("offline bundles are signed for chain %d only, not %d", signer.Polygon.ChainID, network.ChainID)
+	}
+	fs := flag.NewFlagSet("offline", flag.ContinueOnError)
+	bundlePath := fs.String("bundle", "", "order bundle from caesarctl offline export")
+	out := fs.String("out", "", "signatures output path")
+	keyPath := fs.String("key", "", "file holding the hex-encoded private key")
+	limit := fs.String("limit", "", "value limit for the bundle (USDC atomic units)")
+	code := fs.String("totp", "", "authenticator code, when CAESAR_SIGNER_ACTIVATION_TOTP is set")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *bundlePath == "" || *out == "" || *keyPath == "" || *limit == "" {
+		return errors.New("-bundle, -out, -key and -limit are required")
+	}
+	maxValue, ok := new(big.Int).SetString(*limit, 10)
+	if !ok {
+		return fmt.Errorf("invalid -limit %q", *limit)
+	}
+
+	data, err := os.ReadFile(*bundlePath)
+	if err != nil {
+		return err
+	}
+	var bundle signer.OfflineBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return fmt.Errorf("parse bundle: %w", err)
+	}
+
+	checks, err := loadPolicy(cfg.Signer)
+	if err != nil {
+		return fmt.Errorf("invalid policy checks: %w", err)
+	}
+	markets, err := loadMarkets(cfg.Signer)
+	if err != nil {
+		return fmt.Errorf("invalid policy markets: %w", err)
+	}
+
+	session := signer.NewSessionManager(time.Hour, signer.WithKeyGuard(keyGuard), signer.WithVOffset(byte(cfg.Signer.SignatureV)))
+	defer session.Destroy()
+	if cfg.Signer.MaxOrderValue != "" {
+		maxOrder, ok := new(big.Int).SetString(cfg.Signer.MaxOrderValue, 10)
+		if !ok {
+			return fmt.Errorf("invalid max order value %q", cfg.Signer.MaxOrderValue)
+		}
+		if err := session.SetMaxOrderValue(maxOrder); err != nil {
+			return fmt.Errorf("invalid max order value: %w", err)
+		}
+	}
+	windows, err := signer.ParseValueWindows(cfg.Signer.ValueWindows)
+	if err != nil {
+		return fmt.Errorf("invalid value windows: %w", err)
+	}
+	if err := session.SetValueWindows(windows); err != nil {
+		return fmt.Errorf("invalid value windows: %w", err)
+	}
+	if err := session.SetOrderLimits(cfg.Signer.MaxOrdersPerSession, cfg.Signer.MaxSignsPerMinute); err != nil {
+		return fmt.Errorf("invalid order limits: %w", err)
+	}
+	if cfg.Signer.ActivationTOTP != "" {
+		totp, err := signer.NewTOTP(cfg.Signer.ActivationTOTP, nil, nil)
+		if err != nil {
+			return fmt.Errorf("invalid activation totp: %w", err)
+		}
+		if err := totp.Verify("offline", "offline-activate", *code); err != nil {
+			return err
+		}
+	}
+	if err := activateFromFile(session, *keyPath, maxValue); err != nil {
+		return err
+	}
+	h := signer.NewHandler(session,
+		signer.WithPolicy(checks, markets),
+		signer.WithReplayGuard(signer.NewReplayGuard()),
+		signer.WithCanaries(signer.ParseCanaryTokens(cfg.Signer.CanaryTokens), nil),
+	)
+
+	sigs, err := signer.SignOffline(context.Background(), h, &bundle)
+	if err != nil {
+		return err
+	}
+	signed := 0
+	for _, s := range sigs.Signatures {
+		if s.Error != "" {
+			fmt.Fprintf(os.Stderr, "order %s refused: %s\n", s.Digest, s.Error)
+			continue
+		}
+		signed++
+	}
+
+	encoded, err := json.MarshalIndent(sigs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, append(encoded, '\n'), 0o600); err != nil {
+		return err
+	}
+	fmt.Println(msg.T(i18n.SignerOfflineDone, signed, len(sigs.Signatures), sigs.Signer))
+	return nil
+}
+
+// activateFromFile activates session with the hex key stored at path,
+// wiping every plaintext copy once it is sealed.
+func activateFromFile(session *signer.SessionManager, path string, maxValue *big.Int) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	defer memguard.WipeBytes(raw)
+
+	text := bytes.TrimPrefix(bytes.TrimSpace(raw), []byte("0x"))
+	key := make([]byte, hex.DecodedLen(len(text)))
+	defer memguard.WipeBytes(key)
+	if _, err := hex.Decode(key, text); err != nil {
+		return fmt.Errorf("read key: %w", err)
+	}
+	return session.Activate(context.Background(), key, maxValue)
+}
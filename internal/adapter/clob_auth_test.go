@@ -0,0 +1,37 @@
+package adapter
+
+import "testing"
+
+func TestL2Headers(t *testing.T) {
+	creds := APICreds{Key: "key", Secret: "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=", Passphrase: "pass"}
+	h, err := L2Headers(creds, "0xabc", 1_000_000, "post", "/order", []byte(`{"hash":"0x123"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		HeaderAddress:    "0xabc",
+		HeaderSignature:  "UXJnbgBb-ypZADY4xN7vf03KSp0nnfeKKo9XR-0U0-c=",
+		HeaderTimestamp:  "1000000",
+		HeaderAPIKey:     "key",
+		HeaderPassphrase: "pass",
+	}
+	for k, v := range want {
+		if got := h[k]; len(got) != 1 || got[0] != v {
+			t.Errorf("%s = %v, want %s", k, got, v)
+		}
+	}
+
+	// Unpadded secrets decode too.
+	creds.Secret = creds.Secret[:len(creds.Secret)-1]
+	if sig, err := CLOBSignature(creds.Secret, 1_000_000, "POST", "/order", []byte(`{"hash":"0x123"}`)); err != nil || sig != want[HeaderSignature] {
+		t.Errorf("unpadded secret: %s, %v", sig, err)
+	}
+	if _, err := CLOBSignature("not base64!", 0, "GET", "/", nil); err == nil {
+		t.Error("expected an error for a malformed secret")
+	}
+
+	l1 := L1Headers("0xabc", "0xsig", 1_000_000, 3)
+	if l1[HeaderNonce][0] != "3" || l1[HeaderSignature][0] != "0xsig" {
+		t.Errorf("L1 headers = %v", l1)
+	}
+}
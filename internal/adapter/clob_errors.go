@@ -0,0 +1,168 @@
+package adapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Typed CLOB rejection reasons. Match them with errors.Is against the
+// *ExchangeError returned by TranslateCLOBError.
+var (
+	ErrInsufficientBalance = errors.New("insufficient balance or allowance")
+	ErrMarketClosed        = errors.New("market closed or not found")
+	ErrInvalidTick         = errors.New("price breaks the market tick size")
+	ErrOrderTooSmall       = errors.New("order size below market minimum")
+	ErrDuplicateOrder      = errors.New("duplicate order")
+	ErrOrderExpired        = errors.New("order expiration invalid or elapsed")
+	ErrInvalidSignature    = errors.New("invalid order signature")
+	ErrNotFilled           = errors.New("fill-or-kill order could not be filled")
+	ErrUnauthorized        = errors.New("exchange credentials rejected")
+	ErrRateLimited         = errors.New("exchange rate limit exceeded")
+	ErrExchangeUnavailable = errors.New("exchange temporarily unavailable")
+	ErrExchangeRejected    = errors.New("order rejected by exchange")
+)
+
+// ErrorDomain is the ErrorInfo domain for translated exchange errors.
+const ErrorDomain = "polymarket.com"
+
+// ExchangeError is a CLOB failure translated into a documented reason. The
+// exchange's original message is kept for logs but is never matched on by
+// callers.
+type ExchangeError struct {
+	Kind       error  // one of the Err* reasons above
+	Reason     string // stable UPPER_SNAKE code, e.g. "INSUFFICIENT_BALANCE"
+	HTTPStatus int
+	Message    string // raw exchange message
+	Retryable  bool
+}
+
+func (e *ExchangeError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("clob: %v", e.Kind)
+	}
+	return fmt.Sprintf("clob: %v: %s", e.Kind, e.Message)
+}
+
+// Unwrap lets errors.Is match the reason sentinel.
+func (e *ExchangeError) Unwrap() error { return e.Kind }
+
+// Temporary reports whether the request may succeed if retried unchanged.
+func (e *ExchangeError) Temporary() bool { return e.Retryable }
+
+// GRPCStatus converts e to a status carrying an ErrorInfo detail, so a gRPC
+// handler can return it directly and clients receive the typed reason.
+func (e *ExchangeError) GRPCStatus() *status.Status {
+	st := status.New(e.code(), e.Error())
+	info := &errdetails.ErrorInfo{
+		Reason:   e.Reason,
+		Domain:   ErrorDomain,
+		Metadata: map[string]string{"http_status": fmt.Sprint(e.HTTPStatus)},
+	}
+	if e.Retryable {
+		info.Metadata["retryable"] = "true"
+	}
+	if withDetails, err := st.WithDetails(info); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+func (e *ExchangeError) code() codes.Code {
+	switch e.Kind {
+	case ErrInsufficientBalance:
+		return codes.FailedPrecondition
+	case ErrMarketClosed:
+		return codes.FailedPrecondition
+	case ErrInvalidTick, ErrOrderTooSmall, ErrOrderExpired, ErrInvalidSignature:
+		return codes.InvalidArgument
+	case ErrDuplicateOrder:
+		return codes.AlreadyExists
+	case ErrNotFilled:
+		return codes.Aborted
+	case ErrUnauthorized:
+		return codes.PermissionDenied
+	case ErrRateLimited:
+		return codes.ResourceExhausted
+	case ErrExchangeUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// clobMessageRules maps substrings of CLOB error messages to reasons. Order
+// matters: the first match wins.
+var clobMessageRules = []struct {
+	substr string
+	kind   error
+	reason string
+}{
+	{"not enough balance", ErrInsufficientBalance, "INSUFFICIENT_BALANCE"},
+	{"allowance", ErrInsufficientBalance, "INSUFFICIENT_BALANCE"},
+	{"tick size", ErrInvalidTick, "INVALID_TICK"},
+	{"lower than the minimum", ErrOrderTooSmall, "ORDER_TOO_SMALL"},
+	{"min size", ErrOrderTooSmall, "ORDER_TOO_SMALL"},
+	{"duplicated", ErrDuplicateOrder, "DUPLICATE_ORDER"},
+	{"expiration", ErrOrderExpired, "ORDER_EXPIRED"},
+	{"expired", ErrOrderExpired, "ORDER_EXPIRED"},
+	{"invalid signature", ErrInvalidSignature, "INVALID_SIGNATURE"},
+	{"signer address", ErrInvalidSignature, "INVALID_SIGNATURE"},
+	{"couldn't be fully filled", ErrNotFilled, "NOT_FILLED"},
+	{"no orders found to match", ErrNotFilled, "NOT_FILLED"},
+	{"market is closed", ErrMarketClosed, "MARKET_CLOSED"},
+	{"market not found", ErrMarketClosed, "MARKET_CLOSED"},
+	{"does not exist", ErrMarketClosed, "MARKET_CLOSED"},
+	{"not accepting orders", ErrMarketClosed, "MARKET_CLOSED"},
+}
+
+// TranslateCLOBError converts a failed CLOB response into an
+// *ExchangeError. body is the raw response body; both the {"error": ...}
+// and {"errorMsg": ...} shapes are understood, and a non-JSON body is used
+// verbatim. Unrecognised 4xx messages become ErrExchangeRejected.
+func TranslateCLOBError(httpStatus int, body []byte) error {
+	msg := clobMessage(body)
+	e := &ExchangeError{HTTPStatus: httpStatus, Message: msg}
+
+	lower := strings.ToLower(msg)
+	for _, rule := range clobMessageRules {
+		if strings.Contains(lower, rule.substr) {
+			e.Kind, e.Reason = rule.kind, rule.reason
+			return e
+		}
+	}
+
+	switch {
+	case httpStatus == http.StatusTooManyRequests:
+		e.Kind, e.Reason, e.Retryable = ErrRateLimited, "RATE_LIMITED", true
+	case httpStatus == http.StatusUnauthorized || httpStatus == http.StatusForbidden:
+		e.Kind, e.Reason = ErrUnauthorized, "UNAUTHORIZED"
+	case httpStatus >= 500:
+		e.Kind, e.Reason, e.Retryable = ErrExchangeUnavailable, "EXCHANGE_UNAVAILABLE", true
+	default:
+		e.Kind, e.Reason = ErrExchangeRejected, "REJECTED"
+	}
+	return e
+}
+
+func clobMessage(body []byte) string {
+	var parsed struct {
+		Error    string `json:"error"`
+		ErrorMsg string `json:"errorMsg"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		if parsed.ErrorMsg != "" {
+			return parsed.ErrorMsg
+		}
+		if parsed.Error != "" {
+			return parsed.Error
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
@@ -0,0 +1,58 @@
+package adapter
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTranslateCLOBError(t *testing.T) {
+	cases := []struct {
+		status    int
+		body      string
+		want      error
+		retryable bool
+	}{
+		{400, `{"error":"not enough balance / allowance"}`, ErrInsufficientBalance, false},
+		{400, `{"errorMsg":"order 0xabc is invalid. Price (0.555) breaks minimum tick size rule: 0.01"}`, ErrInvalidTick, false},
+		{400, `{"error":"the orderbook 123 does not exist"}`, ErrMarketClosed, false},
+		{400, `{"error":"order 0xabc is invalid. Duplicated."}`, ErrDuplicateOrder, false},
+		{400, `{"error":"order couldn't be fully filled, FOK orders are fully filled or killed"}`, ErrNotFilled, false},
+		{429, `Too Many Requests`, ErrRateLimited, true},
+		{502, ``, ErrExchangeUnavailable, true},
+		{401, `{"error":"Unauthorized/Invalid api key"}`, ErrUnauthorized, false},
+		{400, `{"error":"something new"}`, ErrExchangeRejected, false},
+	}
+	for _, c := range cases {
+		err := TranslateCLOBError(c.status, []byte(c.body))
+		if !errors.Is(err, c.want) {
+			t.Errorf("%d %s: got %v, want %v", c.status, c.body, err, c.want)
+			continue
+		}
+		var ee *ExchangeError
+		if !errors.As(err, &ee) || ee.Retryable != c.retryable {
+			t.Errorf("%d %s: retryable = %v, want %v", c.status, c.body, ee.Retryable, c.retryable)
+		}
+	}
+}
+
+func TestExchangeErrorGRPCStatus(t *testing.T) {
+	err := TranslateCLOBError(http.StatusBadRequest, []byte(`{"error":"not enough balance / allowance"}`))
+	st := status.Convert(err)
+	if st.Code() != codes.FailedPrecondition {
+		t.Errorf("code = %v", st.Code())
+	}
+	var info *errdetails.ErrorInfo
+	for _, d := range st.Details() {
+		if ei, ok := d.(*errdetails.ErrorInfo); ok {
+			info = ei
+		}
+	}
+	if info == nil || info.Reason != "INSUFFICIENT_BALANCE" || info.Domain != ErrorDomain {
+		t.Fatalf("unexpected details: %v", st.Details())
+	}
+}
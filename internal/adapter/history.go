@@ -0,0 +1,153 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/candle"
+)
+
+const (
+	// DefaultPolyAPIURL is the Polymarket CLOB API, which serves prices-history.
+	DefaultPolyAPIURL = "https://clob.polymarket.com"
+	// DefaultPolyDataAPIURL is the Polymarket data-api, which serves trades.
+	DefaultPolyDataAPIURL = "https://data-api.polymarket.com"
+)
+
+// Trade is a historical Polymarket trade.
+type Trade struct {
+	TokenID string
+	Side    string
+	Price   int64 // fixed-point, 1_000_000 == 1.00
+	Size    int64 // raw share units
+	At      time.Time
+}
+
+// HistoryClient fetches price history and trades from Polymarket and caches
+// responses for cacheTTL, so the chart pane and strategy warm-up do not
+// refetch identical ranges.
+type HistoryClient struct {
+	clobURL  string
+	dataURL  string
+	client   *http.Client
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedCandles
+}
+
+type cachedCandles struct {
+	candles   []candle.Candle
+	fetchedAt time.Time
+}
+
+// NewHistoryClient creates a client. Empty URLs use the public defaults.
+func NewHistoryClient(clobURL, dataURL string, client *http.Client, cacheTTL time.Duration) *HistoryClient {
+	if clobURL == "" {
+		clobURL = DefaultPolyAPIURL
+	}
+	if dataURL == "" {
+		dataURL = DefaultPolyDataAPIURL
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HistoryClient{
+		clobURL:  strings.TrimRight(clobURL, "/"),
+		dataURL:  strings.TrimRight(dataURL, "/"),
+		client:   client,
+		cacheTTL: cacheTTL,
+		cache:    make(map[string]cachedCandles),
+	}
+}
+
+// GetCandles returns OHLC candles for token between from and to, bucketed
+// at interval. The prices-history endpoint is sampled at the interval's
+// minute fidelity and the samples are aggregated locally.
+func (h *HistoryClient) GetCandles(ctx context.Context, tokenID string, interval time.Duration, from, to time.Time) ([]candle.Candle, error) {
+	key := fmt.Sprintf("%s|%s|%d|%d", tokenID, interval, from.Unix(), to.Unix())
+
+	h.mu.Lock()
+	if c, ok := h.cache[key]; ok && time.Since(c.fetchedAt) < h.cacheTTL {
+		h.mu.Unlock()
+		return append([]candle.Candle(nil), c.candles...), nil
+	}
+	h.mu.Unlock()
+
+	fidelity := int(interval / time.Minute)
+	if fidelity < 1 {
+		fidelity = 1
+	}
+	q := url.Values{
+		"market":   {tokenID},
+		"startTs":  {strconv.FormatInt(from.Unix(), 10)},
+		"endTs":    {strconv.FormatInt(to.Unix(), 10)},
+		"fidelity": {strconv.Itoa(fidelity)},
+	}
+
+	var body struct {
+		History []struct {
+			T int64   `json:"t"`
+			P float64 `json:"p"`
+		} `json:"history"`
+	}
+	if err := getJSON(ctx, h.client, h.clobURL+"/prices-history?"+q.Encode(), &body); err != nil {
+		return nil, fmt.Errorf("polymarket: prices-history: %w", err)
+	}
+
+	points := make([]candle.Point, 0, len(body.History))
+	for _, s := range body.History {
+		points = append(points, candle.Point{At: time.Unix(s.T, 0).UTC(), Price: toFixed(s.P)})
+	}
+	candles := candle.Bucket(points, interval)
+
+	h.mu.Lock()
+	h.cache[key] = cachedCandles{candles: candles, fetchedAt: time.Now()}
+	h.mu.Unlock()
+
+	return append([]candle.Candle(nil), candles...), nil
+}
+
+// GetTrades returns up to limit of the most recent trades for a market
+// condition ID.
+func (h *HistoryClient) GetTrades(ctx context.Context, conditionID string, limit int) ([]Trade, error) {
+	q := url.Values{
+		"market": {conditionID},
+		"limit":  {strconv.Itoa(limit)},
+	}
+
+	var body []struct {
+		Asset     string  `json:"asset"`
+		Side      string  `json:"side"`
+		Price     float64 `json:"price"`
+		Size      float64 `json:"size"`
+		Timestamp int64   `json:"timestamp"`
+	}
+	if err := getJSON(ctx, h.client, h.dataURL+"/trades?"+q.Encode(), &body); err != nil {
+		return nil, fmt.Errorf("polymarket: trades: %w", err)
+	}
+
+	trades := make([]Trade, 0, len(body))
+	for _, t := range body {
+		trades = append(trades, Trade{
+			TokenID: t.Asset,
+			Side:    t.Side,
+			Price:   toFixed(t.Price),
+			Size:    toFixed(t.Size),
+			At:      time.Unix(t.Timestamp, 0).UTC(),
+		})
+	}
+	return trades, nil
+}
+
+// toFixed converts a decimal API value into 6-decimal fixed point.
+func toFixed(v float64) int64 {
+	return int64(math.Round(v * 1_000_000))
+}
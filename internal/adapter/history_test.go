@@ -0,0 +1,56 @@
+package adapter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHistoryClientCandlesCached(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/prices-history" || r.URL.Query().Get("fidelity") != "5" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"history":[{"t":1767268800,"p":0.5},{"t":1767268860,"p":0.55},{"t":1767269100,"p":0.6}]}`))
+	}))
+	defer srv.Close()
+
+	h := NewHistoryClient(srv.URL, srv.URL, srv.Client(), time.Minute)
+	from, to := time.Unix(1767268800, 0), time.Unix(1767270000, 0)
+
+	candles, err := h.GetCandles(context.Background(), "tok", 5*time.Minute, from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(candles) != 2 || candles[0].High != 550_000 || candles[1].Open != 600_000 {
+		t.Fatalf("unexpected candles: %+v", candles)
+	}
+
+	if _, err := h.GetCandles(context.Background(), "tok", 5*time.Minute, from, to); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected cached second call, got %d requests", calls)
+	}
+}
+
+func TestHistoryClientTrades(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"asset":"tok","side":"BUY","price":0.42,"size":12.5,"timestamp":1767268800}]`))
+	}))
+	defer srv.Close()
+
+	h := NewHistoryClient(srv.URL, srv.URL, srv.Client(), time.Minute)
+	trades, err := h.GetTrades(context.Background(), "0xcond", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trades) != 1 || trades[0].Price != 420_000 || trades[0].Size != 12_500_000 || trades[0].Side != "BUY" {
+		t.Errorf("unexpected trades: %+v", trades)
+	}
+}
@@ -0,0 +1,60 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultKalshiAPIURL is the public Kalshi trade API base URL.
+const DefaultKalshiAPIURL = "https://trading-api.kalshi.com/trade-api/v2"
+
+// KalshiReference reads public market prices from the Kalshi REST API.
+type KalshiReference struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewKalshiReference creates a Kalshi reference source. An empty baseURL
+// uses DefaultKalshiAPIURL.
+func NewKalshiReference(baseURL string, client *http.Client) *KalshiReference {
+	if baseURL == "" {
+		baseURL = DefaultKalshiAPIURL
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &KalshiReference{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+// Venue implements ReferenceSource.
+func (k *KalshiReference) Venue() string {
+	return "kalshi"
+}
+
+// Fetch implements ReferenceSource. Kalshi quotes YES prices in cents.
+func (k *KalshiReference) Fetch(ctx context.Context, ticker string) (ReferenceQuote, error) {
+	var body struct {
+		Market struct {
+			YesBid    int64 `json:"yes_bid"`
+			YesAsk    int64 `json:"yes_ask"`
+			LastPrice int64 `json:"last_price"`
+		} `json:"market"`
+	}
+	if err := getJSON(ctx, k.client, k.baseURL+"/markets/"+url.PathEscape(ticker), &body); err != nil {
+		return ReferenceQuote{}, fmt.Errorf("kalshi: fetch market: %w", err)
+	}
+
+	const centsToScale = 10_000
+	return ReferenceQuote{
+		Venue:     k.Venue(),
+		MarketID:  ticker,
+		Bid:       body.Market.YesBid * centsToScale,
+		Ask:       body.Market.YesAsk * centsToScale,
+		Last:      body.Market.LastPrice * centsToScale,
+		FetchedAt: time.Now(),
+	}, nil
+}
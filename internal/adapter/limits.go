@@ -0,0 +1,106 @@
+package adapter
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+// EndpointClass groups CLOB endpoints that share a concurrency limit.
+type EndpointClass string
+
+const (
+	EndpointSubmit   EndpointClass = "submit"
+	EndpointCancel   EndpointClass = "cancel"
+	EndpointMetadata EndpointClass = "metadata"
+)
+
+// ClassifyCLOB maps a CLOB request to its endpoint class: order placement
+// is a submit, any DELETE is a cancel, everything else is metadata.
+func ClassifyCLOB(req *http.Request) EndpointClass {
+	switch {
+	case req.Method == http.MethodDelete:
+		return EndpointCancel
+	case req.Method == http.MethodPost && (req.URL.Path == "/order" || req.URL.Path == "/orders"):
+		return EndpointSubmit
+	case req.Method == http.MethodPost && strings.HasPrefix(req.URL.Path, "/cancel"):
+		return EndpointCancel
+	default:
+		return EndpointMetadata
+	}
+}
+
+// ConcurrencyLimits caps in-flight CLOB requests per endpoint class. Zero
+// means unlimited.
+type ConcurrencyLimits struct {
+	Submit   int
+	Cancel   int
+	Metadata int
+}
+
+func (l ConcurrencyLimits) of(c EndpointClass) int {
+	switch c {
+	case EndpointSubmit:
+		return l.Submit
+	case EndpointCancel:
+		return l.Cancel
+	default:
+		return l.Metadata
+	}
+}
+
+// LimitTransport wraps base (nil means http.DefaultTransport) so no more
+// than the configured number of requests per class are in flight at once;
+// a burst queues locally instead of opening hundreds of sockets and
+// tripping exchange-side protections. A slot is held until the response
+// body is closed and waiting honours the request context.
+func LimitTransport(base http.RoundTripper, limits ConcurrencyLimits) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	sems := make(map[EndpointClass]chan struct{})
+	for _, c := range []EndpointClass{EndpointSubmit, EndpointCancel, EndpointMetadata} {
+		if n := limits.of(c); n > 0 {
+			sems[c] = make(chan struct{}, n)
+		}
+	}
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sem := sems[ClassifyCLOB(req)]
+		if sem == nil {
+			return base.RoundTrip(req)
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		release := func() { <-sem }
+		resp, err := base.RoundTrip(req)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+		return resp, nil
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return fn(req) }
+
+// releaseBody frees a concurrency slot on the first Close.
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+	closed  bool
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	if !b.closed {
+		b.closed = true
+		b.release()
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClassifyCLOB(t *testing.T) {
+	for _, tc := range []struct {
+		method, path string
+		want         EndpointClass
+	}{
+		{http.MethodPost, "/order", EndpointSubmit},
+		{http.MethodPost, "/orders", EndpointSubmit},
+		{http.MethodDelete, "/order", EndpointCancel},
+		{http.MethodPost, "/cancel-market-orders", EndpointCancel},
+		{http.MethodGet, "/book", EndpointMetadata},
+	} {
+		req, _ := http.NewRequest(tc.method, "https://clob.example"+tc.path, nil)
+		if got := ClassifyCLOB(req); got != tc.want {
+			t.Errorf("%s %s = %s, want %s", tc.method, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestLimitTransportCapsInFlight(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{}, 5)
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		if ClassifyCLOB(req) == EndpointSubmit {
+			started <- struct{}{}
+			<-release
+		}
+		inFlight.Add(-1)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+	client := &http.Client{Transport: LimitTransport(base, ConcurrencyLimits{Submit: 2})}
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Post("https://clob.example/order", "application/json", nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	<-started
+	<-started
+
+	// Metadata is unlimited and not blocked behind the queued submits.
+	if resp, err := client.Get("https://clob.example/book"); err != nil {
+		t.Fatal(err)
+	} else {
+		resp.Body.Close()
+	}
+
+	// A queued submit gives up when its context ends.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://clob.example/order", nil)
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+	if p := peak.Load(); p > 3 {
+		t.Errorf("peak in flight = %d, want at most 2 submits plus 1 metadata", p)
+	}
+}
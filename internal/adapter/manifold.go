@@ -0,0 +1,55 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultManifoldAPIURL is the public Manifold Markets API base URL.
+const DefaultManifoldAPIURL = "https://api.manifold.markets/v0"
+
+// ManifoldReference reads market probabilities from the Manifold API.
+// Manifold is an AMM without a book, so only Last is populated.
+type ManifoldReference struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewManifoldReference creates a Manifold reference source. An empty
+// baseURL uses DefaultManifoldAPIURL.
+func NewManifoldReference(baseURL string, client *http.Client) *ManifoldReference {
+	if baseURL == "" {
+		baseURL = DefaultManifoldAPIURL
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &ManifoldReference{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+// Venue implements ReferenceSource.
+func (m *ManifoldReference) Venue() string {
+	return "manifold"
+}
+
+// Fetch implements ReferenceSource.
+func (m *ManifoldReference) Fetch(ctx context.Context, marketID string) (ReferenceQuote, error) {
+	var body struct {
+		Probability float64 `json:"probability"`
+	}
+	if err := getJSON(ctx, m.client, m.baseURL+"/market/"+url.PathEscape(marketID), &body); err != nil {
+		return ReferenceQuote{}, fmt.Errorf("manifold: fetch market: %w", err)
+	}
+
+	return ReferenceQuote{
+		Venue:     m.Venue(),
+		MarketID:  marketID,
+		Last:      int64(math.Round(body.Probability * 1_000_000)),
+		FetchedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,236 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/book"
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+// DefaultGammaAPIURL is the Polymarket Gamma API, which serves market
+// metadata.
+const DefaultGammaAPIURL = "https://gamma-api.polymarket.com"
+
+// metadataFetchTimeout bounds one shared fetch. It runs detached from the
+// callers waiting on it, so none of their deadlines applies.
+const metadataFetchTimeout = 10 * time.Second
+
+var ErrMarketNotFound = errors.New("market not found")
+
+// MarketSource looks up market metadata. Both lookups return every
+// outcome token of the market's condition.
+type MarketSource interface {
+	MarketsByToken(ctx context.Context, tokenID string) ([]book.Market, error)
+	MarketsByCondition(ctx context.Context, conditionID string) ([]book.Market, error)
+}
+
+// GammaMarkets reads market metadata from the Gamma API.
+type GammaMarkets struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewGammaMarkets creates a Gamma market source. An empty baseURL uses
+// DefaultGammaAPIURL.
+func NewGammaMarkets(baseURL string, client *http.Client) *GammaMarkets {
+	if baseURL == "" {
+		baseURL = DefaultGammaAPIURL
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &GammaMarkets{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+// MarketsByToken implements MarketSource.
+func (g *GammaMarkets) MarketsByToken(ctx context.Context, tokenID string) ([]book.Market, error) {
+	return g.markets(ctx, "clob_token_ids", tokenID)
+}
+
+// MarketsByCondition implements MarketSource.
+func (g *GammaMarkets) MarketsByCondition(ctx context.Context, conditionID string) ([]book.Market, error) {
+	return g.markets(ctx, "condition_ids", conditionID)
+}
+
+func (g *GammaMarkets) markets(ctx context.Context, param, id string) ([]book.Market, error) {
+	var body []struct {
+		ConditionID  string  `json:"conditionId"`
+		Question     string  `json:"question"`
+		ClobTokenIDs string  `json:"clobTokenIds"` // a JSON array, encoded as a string
+		TickSize     float64 `json:"orderPriceMinTickSize"`
+		MinSize      float64 `json:"orderMinSize"`
+		Closed       bool    `json:"closed"`
+	}
+	q := url.Values{param: {id}}
+	if err := getJSON(ctx, g.client, g.baseURL+"/markets?"+q.Encode(), &body); err != nil {
+		return nil, fmt.Errorf("gamma: markets: %w", err)
+	}
+	if len(body) == 0 {
+		return nil, fmt.Errorf("gamma: %s: %w", id, ErrMarketNotFound)
+	}
+
+	m := body[0]
+	var tokens []string
+	if err := json.Unmarshal([]byte(m.ClobTokenIDs), &tokens); err != nil {
+		return nil, fmt.Errorf("gamma: %s: clobTokenIds: %w", id, err)
+	}
+	markets := make([]book.Market, 0, len(tokens))
+	for _, token := range tokens {
+		markets = append(markets, book.Market{
+			TokenID:     token,
+			ConditionID: m.ConditionID,
+			Question:    m.Question,
+			TickSize:    toFixed(m.TickSize),
+			MinSize:     toFixed(m.MinSize),
+			Closed:      m.Closed,
+		})
+	}
+	return markets, nil
+}
+
+// MetadataCache puts a MarketSource behind a cache keyed by token and
+// condition ID. Concurrent lookups of the same key share one fetch, so a
+// burst of orders for a new market costs one request rather than one per
+// order. Entries are fresh for ttl; for a further stale they are served
+// while a single background fetch refreshes them, and after that callers
+// wait for a new fetch.
+type MetadataCache struct {
+	source MarketSource
+	ttl    time.Duration
+	stale  time.Duration
+	clock  clock.Clock
+	sink   *book.Cache
+
+	mu       sync.Mutex
+	entries  map[string]metadataEntry
+	inflight map[string]*metadataCall
+}
+
+type metadataEntry struct {
+	markets   []book.Market
+	fetchedAt time.Time
+}
+
+// metadataCall is one fetch shared by every lookup of its key. done is
+// closed once markets and err are set.
+type metadataCall struct {
+	done    chan struct{}
+	markets []book.Market
+	err     error
+}
+
+// NewMetadataCache creates a cache over source. sink, if not nil, is sent
+// every market fetched, so the book cache persists it for pre-trade
+// checks after a restart.
+func NewMetadataCache(source MarketSource, ttl, stale time.Duration, sink *book.Cache, clk clock.Clock) *MetadataCache {
+	return &MetadataCache{
+		source:   source,
+		ttl:      ttl,
+		stale:    stale,
+		clock:    clock.Or(clk),
+		sink:     sink,
+		entries:  make(map[string]metadataEntry),
+		inflight: make(map[string]*metadataCall),
+	}
+}
+
+// Market returns the metadata of one outcome token.
+func (c *MetadataCache) Market(ctx context.Context, tokenID string) (book.Market, error) {
+	markets, err := c.lookup(ctx, "token:"+tokenID, func(ctx context.Context) ([]book.Market, error) {
+		return c.source.MarketsByToken(ctx, tokenID)
+	})
+	if err != nil {
+		return book.Market{}, err
+	}
+	for _, m := range markets {
+		if m.TokenID == tokenID {
+			return m, nil
+		}
+	}
+	return book.Market{}, fmt.Errorf("token %s: %w", tokenID, ErrMarketNotFound)
+}
+
+// Condition returns the metadata of every outcome token of a condition.
+func (c *MetadataCache) Condition(ctx context.Context, conditionID string) ([]book.Market, error) {
+	markets, err := c.lookup(ctx, "condition:"+conditionID, func(ctx context.Context) ([]book.Market, error) {
+		return c.source.MarketsByCondition(ctx, conditionID)
+	})
+	return append([]book.Market(nil), markets...), err
+}
+
+// lookup serves key from the cache, joining or starting a fetch when the
+// entry is missing or stale.
+func (c *MetadataCache) lookup(ctx context.Context, key string, fetch func(context.Context) ([]book.Market, error)) ([]book.Market, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	age := c.clock.Now().Sub(e.fetchedAt)
+	if ok && age < c.ttl {
+		c.mu.Unlock()
+		return e.markets, nil
+	}
+	call := c.startLocked(ctx, key, fetch)
+	c.mu.Unlock()
+
+	if ok && age < c.ttl+c.stale {
+		return e.markets, nil
+	}
+	select {
+	case <-call.done:
+		return call.markets, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// startLocked returns the fetch in flight for key, starting one if there
+// is none. The fetch outlives ctx, whose values it keeps: the caller that
+// started it may give up while others still wait. c.mu must be held.
+func (c *MetadataCache) startLocked(ctx context.Context, key string, fetch func(context.Context) ([]book.Market, error)) *metadataCall {
+	if call, ok := c.inflight[key]; ok {
+		return call
+	}
+	call := &metadataCall{done: make(chan struct{})}
+	c.inflight[key] = call
+
+	go func() {
+		fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), metadataFetchTimeout)
+		defer cancel()
+		markets, err := fetch(fctx)
+
+		c.mu.Lock()
+		delete(c.inflight, key)
+		// A failed refresh keeps the old entry; the next stale read retries.
+		if err == nil {
+			c.storeLocked(key, markets)
+		}
+		c.mu.Unlock()
+
+		call.markets, call.err = markets, err
+		close(call.done)
+	}()
+	return call
+}
+
+// storeLocked caches markets under key and under each market's token and
+// condition, so a lookup by either ID warms the other. c.mu must be held.
+func (c *MetadataCache) storeLocked(key string, markets []book.Market) {
+	e := metadataEntry{markets: markets, fetchedAt: c.clock.Now()}
+	c.entries[key] = e
+	for _, m := range markets {
+		c.entries["token:"+m.TokenID] = e
+		if m.ConditionID != "" {
+			c.entries["condition:"+m.ConditionID] = e
+		}
+		if c.sink != nil {
+			c.sink.SetMarket(m)
+		}
+	}
+}
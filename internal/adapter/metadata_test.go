@@ -0,0 +1,201 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/book"
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+func TestGammaMarkets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/markets" || r.URL.Query().Get("clob_token_ids") != "yes" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`[{"conditionId":"0xcond","question":"Will it rain?","clobTokenIds":"[\"yes\",\"no\"]",` +
+			`"orderPriceMinTickSize":0.01,"orderMinSize":5,"closed":false}]`))
+	}))
+	defer srv.Close()
+
+	g := NewGammaMarkets(srv.URL, srv.Client())
+	markets, err := g.MarketsByToken(context.Background(), "yes")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := book.Market{TokenID: "no", ConditionID: "0xcond", Question: "Will it rain?", TickSize: 10_000, MinSize: 5_000_000}
+	if len(markets) != 2 || markets[0].TokenID != "yes" || markets[1] != want {
+		t.Fatalf("unexpected markets: %+v", markets)
+	}
+
+	if _, err := g.MarketsByCondition(context.Background(), "0xother"); !errors.Is(err, ErrMarketNotFound) {
+		t.Errorf("expected ErrMarketNotFound, got %v", err)
+	}
+}
+
+// fakeMarkets serves a fixed condition, blocking each fetch until release
+// is closed when it is set.
+type fakeMarkets struct {
+	calls   atomic.Int32
+	release chan struct{}
+	err     error
+}
+
+func (f *fakeMarkets) MarketsByToken(ctx context.Context, tokenID string) ([]book.Market, error) {
+	return f.fetch(ctx)
+}
+
+func (f *fakeMarkets) MarketsByCondition(ctx context.Context, conditionID string) ([]book.Market, error) {
+	return f.fetch(ctx)
+}
+
+func (f *fakeMarkets) fetch(ctx context.Context) ([]book.Market, error) {
+	n := f.calls.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []book.Market{
+		{TokenID: "yes", ConditionID: "0xcond", TickSize: 10_000 * int64(n)},
+		{TokenID: "no", ConditionID: "0xcond", TickSize: 10_000 * int64(n)},
+	}, nil
+}
+
+func TestMetadataCacheSharesFetch(t *testing.T) {
+	src := &fakeMarkets{release: make(chan struct{})}
+	c := NewMetadataCache(src, time.Minute, time.Minute, nil, clock.NewFake(time.Unix(1767268800, 0)))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Market(context.Background(), "yes")
+			errs <- err
+		}()
+	}
+	for src.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(src.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := src.calls.Load(); n != 1 {
+		t.Errorf("expected one fetch for a burst, got %d", n)
+	}
+
+	// The token lookup also warmed its condition and sibling token.
+	markets, err := c.Condition(context.Background(), "0xcond")
+	if err != nil || len(markets) != 2 {
+		t.Fatalf("unexpected condition lookup: %+v, %v", markets, err)
+	}
+	if _, err := c.Market(context.Background(), "no"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := src.calls.Load(); n != 1 {
+		t.Errorf("expected cached lookups, got %d fetches", n)
+	}
+}
+
+func TestMetadataCacheServesStaleWhileRefreshing(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1767268800, 0))
+	src := &fakeMarkets{}
+	c := NewMetadataCache(src, time.Minute, time.Minute, nil, clk)
+	ctx := context.Background()
+
+	if m, err := c.Market(ctx, "yes"); err != nil || m.TickSize != 10_000 {
+		t.Fatalf("unexpected first lookup: %+v, %v", m, err)
+	}
+
+	// Stale: served at once, refreshed in the background.
+	src.release = make(chan struct{})
+	clk.Advance(90 * time.Second)
+	if m, err := c.Market(ctx, "yes"); err != nil || m.TickSize != 10_000 {
+		t.Fatalf("expected the stale entry, got %+v, %v", m, err)
+	}
+	close(src.release)
+	waitFor(t, func() bool {
+		m, _ := c.Market(ctx, "yes")
+		return m.TickSize == 20_000
+	})
+
+	// Past stale: callers wait for the fetch.
+	clk.Advance(3 * time.Minute)
+	if m, err := c.Market(ctx, "yes"); err != nil || m.TickSize != 30_000 {
+		t.Fatalf("expected a synchronous refetch, got %+v, %v", m, err)
+	}
+}
+
+func TestMetadataCacheFailedRefreshKeepsEntry(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1767268800, 0))
+	src := &fakeMarkets{}
+	c := NewMetadataCache(src, time.Minute, time.Minute, nil, clk)
+	ctx := context.Background()
+
+	if _, err := c.Market(ctx, "yes"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	src.err = errors.New("gamma down")
+	clk.Advance(90 * time.Second)
+	if _, err := c.Market(ctx, "yes"); err != nil {
+		t.Fatalf("expected the stale entry, got %v", err)
+	}
+	waitFor(t, func() bool { return src.calls.Load() == 2 })
+
+	clk.Advance(time.Hour)
+	if _, err := c.Market(ctx, "yes"); err == nil || err.Error() != "gamma down" {
+		t.Fatalf("expected the fetch error once the entry expired, got %v", err)
+	}
+}
+
+func TestMetadataCacheWaiterCancel(t *testing.T) {
+	src := &fakeMarkets{release: make(chan struct{})}
+	sink := book.NewCache("", 10, time.Hour, nil)
+	c := NewMetadataCache(src, time.Minute, time.Minute, sink, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Market(ctx, "yes"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// The fetch the cancelled caller started still completes for others.
+	close(src.release)
+	if _, err := c.Market(context.Background(), "yes"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := src.calls.Load(); n != 1 {
+		t.Errorf("expected the first fetch to be shared, got %d", n)
+	}
+	if m, ok := sink.Market("no"); !ok || m.ConditionID != "0xcond" {
+		t.Errorf("expected the sink to hold fetched markets, got %+v", m)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,143 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ReferenceQuote is a read-only price for a comparable contract on another
+// venue. Prices are fixed-point with 1_000_000 == 1.00; zero means the
+// venue did not report that field.
+type ReferenceQuote struct {
+	Venue     string
+	MarketID  string
+	Bid       int64
+	Ask       int64
+	Last      int64
+	FetchedAt time.Time
+}
+
+// ReferenceSource fetches comparable prices from a non-Polymarket venue.
+// Implementations are read-only and never place orders.
+type ReferenceSource interface {
+	Venue() string
+	Fetch(ctx context.Context, marketID string) (ReferenceQuote, error)
+}
+
+// ReferenceMapping links a Polymarket token to comparable markets elsewhere,
+// keyed by venue name.
+type ReferenceMapping struct {
+	TokenID string
+	Markets map[string]string // venue → venue market ID
+}
+
+// ReferenceFeed polls reference sources for mapped tokens and keeps the
+// latest quote per venue, for display next to Polymarket books.
+type ReferenceFeed struct {
+	sources map[string]ReferenceSource
+
+	mu       sync.RWMutex
+	mappings []ReferenceMapping
+	latest   map[string]map[string]ReferenceQuote // token → venue → quote
+}
+
+// NewReferenceFeed creates a feed over the given sources.
+func NewReferenceFeed(sources ...ReferenceSource) *ReferenceFeed {
+	f := &ReferenceFeed{
+		sources: make(map[string]ReferenceSource, len(sources)),
+		latest:  make(map[string]map[string]ReferenceQuote),
+	}
+	for _, s := range sources {
+		f.sources[s.Venue()] = s
+	}
+	return f
+}
+
+// SetMappings replaces the set of tokens being tracked.
+func (f *ReferenceFeed) SetMappings(m []ReferenceMapping) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mappings = append([]ReferenceMapping(nil), m...)
+}
+
+// Refresh fetches every mapped market once. Fetch errors are returned
+// together but do not discard quotes fetched successfully.
+func (f *ReferenceFeed) Refresh(ctx context.Context) []error {
+	f.mu.RLock()
+	mappings := f.mappings
+	f.mu.RUnlock()
+
+	var errs []error
+	for _, m := range mappings {
+		for venue, marketID := range m.Markets {
+			src, ok := f.sources[venue]
+			if !ok {
+				continue
+			}
+			q, err := src.Fetch(ctx, marketID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", venue, marketID, err))
+				continue
+			}
+
+			f.mu.Lock()
+			if f.latest[m.TokenID] == nil {
+				f.latest[m.TokenID] = make(map[string]ReferenceQuote)
+			}
+			f.latest[m.TokenID][venue] = q
+			f.mu.Unlock()
+		}
+	}
+	return errs
+}
+
+// Run refreshes every interval until ctx is cancelled.
+func (f *ReferenceFeed) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.Refresh(ctx)
+		}
+	}
+}
+
+// Latest returns the most recent reference quotes for a Polymarket token.
+func (f *ReferenceFeed) Latest(tokenID string) []ReferenceQuote {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	quotes := make([]ReferenceQuote, 0, len(f.latest[tokenID]))
+	for _, q := range f.latest[tokenID] {
+		quotes = append(quotes, q)
+	}
+	return quotes
+}
+
+// getJSON performs a GET request and decodes a JSON response into out.
+func getJSON(ctx context.Context, client *http.Client, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
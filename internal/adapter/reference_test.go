@@ -0,0 +1,52 @@
+package adapter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReferenceFeedRefresh(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/kalshi/markets/PRES-24":
+			w.Write([]byte(`{"market":{"yes_bid":45,"yes_ask":47,"last_price":46}}`))
+		case "/manifold/market/abc":
+			w.Write([]byte(`{"probability":0.615}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	feed := NewReferenceFeed(
+		NewKalshiReference(srv.URL+"/kalshi", srv.Client()),
+		NewManifoldReference(srv.URL+"/manifold/", srv.Client()),
+	)
+	feed.SetMappings([]ReferenceMapping{
+		{TokenID: "tok", Markets: map[string]string{"kalshi": "PRES-24", "manifold": "abc"}},
+		{TokenID: "bad", Markets: map[string]string{"kalshi": "MISSING"}},
+	})
+
+	errs := feed.Refresh(context.Background())
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 fetch error for the missing market, got %v", errs)
+	}
+
+	byVenue := map[string]ReferenceQuote{}
+	for _, q := range feed.Latest("tok") {
+		byVenue[q.Venue] = q
+	}
+
+	k := byVenue["kalshi"]
+	if k.Bid != 450_000 || k.Ask != 470_000 || k.Last != 460_000 {
+		t.Errorf("unexpected kalshi quote: %+v", k)
+	}
+	if m := byVenue["manifold"]; m.Last != 615_000 || m.Bid != 0 {
+		t.Errorf("unexpected manifold quote: %+v", m)
+	}
+	if got := feed.Latest("bad"); len(got) != 0 {
+		t.Errorf("expected no quotes for failed market, got %v", got)
+	}
+}
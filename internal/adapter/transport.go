@@ -0,0 +1,71 @@
+package adapter
+
+import (
+	"cmp"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/egress"
+)
+
+// TransportConfig tunes connection reuse for exchange REST clients. Zero
+// fields take the defaults noted below.
+type TransportConfig struct {
+	MaxIdleConnsPerHost int           // default 16
+	IdleConnTimeout     time.Duration // default 90s
+	KeepAlive           time.Duration // TCP keepalive period; default 30s
+	TLSSessionCacheSize int           // resumable TLS sessions; default 64
+
+	// Egress, if set, restricts which hosts the transport may reach,
+	// through Proxy or directly; the HTTP_PROXY environment is then
+	// ignored.
+	Egress *egress.Guard
+	// Proxy, if set, replaces the HTTP_PROXY environment; see
+	// egress.Proxies.For.
+	Proxy *url.URL
+}
+
+// NewTransport returns an HTTP/2-capable transport that keeps warm
+// connections to the exchange and resumes TLS sessions, so a submit after
+// a quiet period does not pay for a fresh TCP and TLS handshake. Wrap it
+// in metrics.Feed.Transport to watch the connection reuse rate.
+func NewTransport(cfg TransportConfig) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: cmp.Or(cfg.KeepAlive, 30*time.Second),
+	}
+	dial := dialer.DialContext
+	if cfg.Egress != nil {
+		dial = cfg.Egress.DialContext(dial)
+	}
+	// The dialer only sees the proxy, so with a guard the destination is
+	// checked before a request is handed to one.
+	var proxy func(*http.Request) (*url.URL, error)
+	switch {
+	case cfg.Egress != nil && cfg.Proxy != nil:
+		proxy = cfg.Egress.Proxy(cfg.Proxy)
+	case cfg.Egress != nil:
+	case cfg.Proxy != nil:
+		proxy = http.ProxyURL(cfg.Proxy)
+	default:
+		proxy = http.ProxyFromEnvironment
+	}
+	idle := cmp.Or(cfg.MaxIdleConnsPerHost, 16)
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dial,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          4 * idle,
+		MaxIdleConnsPerHost:   idle,
+		IdleConnTimeout:       cmp.Or(cfg.IdleConnTimeout, 90*time.Second),
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		TLSClientConfig: &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			ClientSessionCache: tls.NewLRUClientSessionCache(cmp.Or(cfg.TLSSessionCacheSize, 64)),
+		},
+	}
+}
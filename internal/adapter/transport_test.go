@@ -0,0 +1,78 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/egress"
+)
+
+func TestNewTransportDefaultsAndReuse(t *testing.T) {
+	tr := NewTransport(TransportConfig{IdleConnTimeout: time.Minute})
+	if tr.MaxIdleConnsPerHost != 16 || tr.IdleConnTimeout != time.Minute || !tr.ForceAttemptHTTP2 {
+		t.Errorf("transport = idle/host %d, idle timeout %s, h2 %v", tr.MaxIdleConnsPerHost, tr.IdleConnTimeout, tr.ForceAttemptHTTP2)
+	}
+	if tr.TLSClientConfig.ClientSessionCache == nil {
+		t.Error("TLS session resumption disabled")
+	}
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	defer srv.Close()
+	tr.TLSClientConfig.RootCAs = srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	client := &http.Client{Transport: tr}
+	var reused []bool
+	for range 2 {
+		trace := &httptrace.ClientTrace{GotConn: func(info httptrace.GotConnInfo) { reused = append(reused, info.Reused) }}
+		req, _ := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), http.MethodGet, srv.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	if len(reused) != 2 || reused[0] || !reused[1] {
+		t.Errorf("connection reuse = %v, want [false true]", reused)
+	}
+}
+
+func TestNewTransportGuardsProxiedRequests(t *testing.T) {
+	var proxied []string
+	proxySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.Host)
+		w.Write([]byte("ok"))
+	}))
+	defer proxySrv.Close()
+	proxyURL, _ := url.Parse(proxySrv.URL)
+	t.Setenv("HTTP_PROXY", proxySrv.URL)
+
+	g, err := egress.Parse("127.0.0.1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, cfg := range map[string]TransportConfig{
+		"environment proxy": {Egress: g},
+		"configured proxy":  {Egress: g, Proxy: proxyURL},
+	} {
+		client := &http.Client{Transport: NewTransport(cfg)}
+		resp, err := client.Get("http://evil.example/")
+		if err == nil {
+			resp.Body.Close()
+		}
+		if !errors.Is(err, egress.ErrBlocked) {
+			t.Errorf("%s: expected ErrBlocked, got %v", name, err)
+		}
+	}
+	if len(proxied) != 0 {
+		t.Errorf("the proxy saw %v", proxied)
+	}
+}
@@ -0,0 +1,129 @@
+package anchor
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+// Anchor proves a hash chain head existed by At: Token is a TSA's signed
+// timestamp over the head.
+type Anchor struct {
+	Seq    uint64    `json:"seq"`
+	Head   string    `json:"head"`
+	At     time.Time `json:"at"` // the TSA's generation time
+	TSA    string    `json:"tsa"`
+	Serial string    `json:"serial"`
+	Token  []byte    `json:"token"` // DER TimeStampToken
+}
+
+// Timestamper obtains timestamps over SHA-256 digests. *TSA implements it.
+type Timestamper interface {
+	Timestamp(ctx context.Context, digest [32]byte) (Stamp, error)
+	URL() string
+}
+
+// HeadFunc returns the current sequence number and hex SHA-256 head of a
+// hash chain.
+type HeadFunc func() (seq uint64, head string)
+
+// Anchorer periodically timestamps a hash chain's head so history before
+// the last anchor cannot be rewritten without the rewrite showing.
+type Anchorer struct {
+	head  HeadFunc
+	tsa   Timestamper
+	clock clock.Clock
+
+	// OnAnchor receives each new anchor for storage; OnError each failed
+	// attempt. Both are optional.
+	OnAnchor func(Anchor)
+	OnError  func(error)
+
+	mu      sync.Mutex
+	lastSeq uint64
+}
+
+// NewAnchorer creates an Anchorer for head. A nil clk uses the wall clock.
+func NewAnchorer(head HeadFunc, tsa Timestamper, clk clock.Clock) *Anchorer {
+	return &Anchorer{head: head, tsa: tsa, clock: clock.Or(clk)}
+}
+
+// AnchorNow timestamps the current head unless it is empty or already
+// anchored. It reports whether a new anchor was made.
+func (a *Anchorer) AnchorNow(ctx context.Context) (Anchor, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	seq, head := a.head()
+	if seq == 0 || seq == a.lastSeq {
+		return Anchor{}, false, nil
+	}
+	digest, err := headDigest(head)
+	if err != nil {
+		return Anchor{}, false, err
+	}
+
+	stamp, err := a.tsa.Timestamp(ctx, digest)
+	if err != nil {
+		return Anchor{}, false, fmt.Errorf("anchor seq %d: %w", seq, err)
+	}
+	a.lastSeq = seq
+	anc := Anchor{Seq: seq, Head: head, At: stamp.GenTime, TSA: a.tsa.URL(), Serial: stamp.Serial, Token: stamp.Token}
+	if a.OnAnchor != nil {
+		a.OnAnchor(anc)
+	}
+	return anc, true, nil
+}
+
+// Run anchors every interval until ctx is done. A non-positive interval
+// disables anchoring.
+func (a *Anchorer) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.clock.After(interval):
+			if _, _, err := a.AnchorNow(ctx); err != nil && a.OnError != nil {
+				a.OnError(err)
+			}
+		}
+	}
+}
+
+func headDigest(head string) ([32]byte, error) {
+	var digest [32]byte
+	raw, err := hex.DecodeString(head)
+	if err != nil || len(raw) != len(digest) {
+		return digest, fmt.Errorf("chain head %q is not a SHA-256 hash", head)
+	}
+	copy(digest[:], raw)
+	return digest, nil
+}
+
+// Verify checks that a's token timestamps a's head at a's time. The TSA's
+// signature over the token is not checked here; use
+// `openssl ts -verify -token_in` with the authority's certificate.
+func (a Anchor) Verify() error {
+	digest, err := headDigest(a.Head)
+	if err != nil {
+		return err
+	}
+	info, err := parseToken(a.Token)
+	if err != nil {
+		return err
+	}
+	if !info.covers(digest) {
+		return fmt.Errorf("%w: token does not cover head %s", ErrTimestampMismatch, a.Head)
+	}
+	if !info.GenTime.Equal(a.At) {
+		return fmt.Errorf("%w: token time %s, anchor says %s", ErrTimestampMismatch, info.GenTime, a.At)
+	}
+	return nil
+}
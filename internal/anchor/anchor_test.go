@@ -0,0 +1,65 @@
+package anchor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubTSA struct {
+	calls int
+	err   error
+}
+
+func (s *stubTSA) URL() string { return "https://tsa.test" }
+
+func (s *stubTSA) Timestamp(_ context.Context, digest [32]byte) (Stamp, error) {
+	s.calls++
+	if s.err != nil {
+		return Stamp{}, s.err
+	}
+	return Stamp{GenTime: time.Unix(1700000000, 0), Serial: "1", Token: digest[:]}, nil
+}
+
+func TestAnchorNowSkipsUnchangedHead(t *testing.T) {
+	var seq uint64
+	head := func() (uint64, string) {
+		sum := sha256.Sum256([]byte{byte(seq)})
+		return seq, hex.EncodeToString(sum[:])
+	}
+	tsa := &stubTSA{}
+	var anchors []Anchor
+	a := NewAnchorer(head, tsa, nil)
+	a.OnAnchor = func(anc Anchor) { anchors = append(anchors, anc) }
+
+	// Nothing to anchor before the first record.
+	if _, ok, err := a.AnchorNow(context.Background()); ok || err != nil {
+		t.Fatalf("empty chain anchored: %v %v", ok, err)
+	}
+
+	seq = 3
+	anc, ok, err := a.AnchorNow(context.Background())
+	if !ok || err != nil || anc.Seq != 3 || anc.TSA != "https://tsa.test" {
+		t.Fatalf("anchor = %+v %v %v", anc, ok, err)
+	}
+	if _, ok, _ := a.AnchorNow(context.Background()); ok || tsa.calls != 1 {
+		t.Errorf("unchanged head re-anchored (%d calls)", tsa.calls)
+	}
+
+	// A failed attempt is retried on the next call.
+	seq = 4
+	tsa.err = errors.New("tsa down")
+	if _, ok, err := a.AnchorNow(context.Background()); ok || err == nil {
+		t.Errorf("expected failure, got %v %v", ok, err)
+	}
+	tsa.err = nil
+	if _, ok, err := a.AnchorNow(context.Background()); !ok || err != nil {
+		t.Errorf("retry: %v %v", ok, err)
+	}
+	if len(anchors) != 2 {
+		t.Errorf("anchors = %+v", anchors)
+	}
+}
@@ -0,0 +1,209 @@
+package anchor
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTimestampRejected = errors.New("timestamp request rejected")
+	ErrTimestampMismatch = errors.New("timestamp token does not match the request")
+)
+
+var (
+	oidSHA256     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+)
+
+// ASN.1 structures from RFC 3161 and RFC 5652, reduced to the fields
+// read here; trailing fields are ignored by encoding/asn1.
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type messageImprint struct {
+	HashAlgorithm algorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int
+	CertReq        bool
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []asn1.RawValue `asn1:"optional"` // UTF8Strings
+}
+
+func (s pkiStatusInfo) text() string {
+	parts := make([]string, len(s.StatusString))
+	for i, v := range s.StatusString {
+		parts[i] = string(v.Bytes)
+	}
+	return strings.Join(parts, "; ")
+}
+
+type timeStampResp struct {
+	Status pkiStatusInfo
+	Token  asn1.RawValue `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo struct {
+		EContentType asn1.ObjectIdentifier
+		EContent     []byte `asn1:"explicit,optional,tag:0"`
+	}
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+	Accuracy       struct {
+		Seconds int `asn1:"optional"`
+	} `asn1:"optional"`
+	Ordering bool     `asn1:"optional"`
+	Nonce    *big.Int `asn1:"optional"`
+}
+
+// Stamp is a timestamp token from a TSA.
+type Stamp struct {
+	GenTime time.Time
+	Serial  string
+	Token   []byte // DER TimeStampToken, verifiable with `openssl ts -verify`
+}
+
+// TSA requests RFC 3161 timestamps over HTTP.
+type TSA struct {
+	url    string
+	client *http.Client
+}
+
+// NewTSA creates a client for the timestamp authority at url. A nil
+// client uses http.DefaultClient.
+func NewTSA(url string, client *http.Client) *TSA {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &TSA{url: url, client: client}
+}
+
+// URL returns the authority's endpoint.
+func (t *TSA) URL() string { return t.url }
+
+// Timestamp asks the authority to timestamp a SHA-256 digest. The token
+// is checked to cover digest and the request's nonce; its signature is
+// left to offline verification against the TSA's certificate.
+func (t *TSA) Timestamp(ctx context.Context, digest [32]byte) (Stamp, error) {
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return Stamp{}, err
+	}
+	body, err := asn1.Marshal(timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: algorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+			HashedMessage: digest[:],
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	if err != nil {
+		return Stamp{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return Stamp{}, err
+	}
+	req.Header.Set("Content-Type", "application/timestamp-query")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return Stamp{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Stamp{}, fmt.Errorf("tsa %s: status %d", t.url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Stamp{}, err
+	}
+	return parseResponse(data, digest, nonce)
+}
+
+func parseResponse(data []byte, digest [32]byte, nonce *big.Int) (Stamp, error) {
+	var resp timeStampResp
+	if _, err := asn1.Unmarshal(data, &resp); err != nil {
+		return Stamp{}, fmt.Errorf("parse timestamp response: %w", err)
+	}
+	// 0 granted, 1 granted with modifications.
+	if resp.Status.Status > 1 || len(resp.Token.FullBytes) == 0 {
+		return Stamp{}, fmt.Errorf("%w: status %d %q", ErrTimestampRejected, resp.Status.Status, resp.Status.text())
+	}
+
+	info, err := parseToken(resp.Token.FullBytes)
+	if err != nil {
+		return Stamp{}, err
+	}
+
+	if !info.covers(digest) {
+		return Stamp{}, fmt.Errorf("%w: different digest", ErrTimestampMismatch)
+	}
+	if info.Nonce == nil || info.Nonce.Cmp(nonce) != 0 {
+		return Stamp{}, fmt.Errorf("%w: different nonce", ErrTimestampMismatch)
+	}
+	return Stamp{GenTime: info.GenTime, Serial: info.SerialNumber.String(), Token: resp.Token.FullBytes}, nil
+}
+
+// parseToken extracts the TSTInfo of a DER TimeStampToken.
+func parseToken(token []byte) (tstInfo, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(token, &ci); err != nil {
+		return tstInfo{}, fmt.Errorf("parse timestamp token: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return tstInfo{}, fmt.Errorf("parse timestamp token: content type %v", ci.ContentType)
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return tstInfo{}, fmt.Errorf("parse timestamp token: %w", err)
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return tstInfo{}, fmt.Errorf("parse timestamp token: content type %v", sd.EncapContentInfo.EContentType)
+	}
+	var info tstInfo
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent, &info); err != nil {
+		return tstInfo{}, fmt.Errorf("parse timestamp info: %w", err)
+	}
+	return info, nil
+}
+
+func (info tstInfo) covers(digest [32]byte) bool {
+	imprint := info.MessageImprint
+	return imprint.HashAlgorithm.Algorithm.Equal(oidSHA256) && bytes.Equal(imprint.HashedMessage, digest[:])
+}
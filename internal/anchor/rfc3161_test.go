@@ -0,0 +1,132 @@
+package anchor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tsaResponse builds a granted TimeStampResp for imprint and nonce. The
+// token is unsigned: the client leaves signature checks to offline tools.
+func tsaResponse(t *testing.T, imprint []byte, nonce *big.Int, at time.Time) []byte {
+	t.Helper()
+	info, err := asn1.Marshal(tstInfo{
+		Version:        1,
+		Policy:         asn1.ObjectIdentifier{1, 2, 3, 4, 1},
+		MessageImprint: messageImprint{HashAlgorithm: algorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue}, HashedMessage: imprint},
+		SerialNumber:   big.NewInt(42),
+		GenTime:        at,
+		Nonce:          nonce,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd := signedData{Version: 3, DigestAlgorithms: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true}}
+	sd.EncapContentInfo.EContentType = oidTSTInfo
+	sd.EncapContentInfo.EContent = info
+	content, err := asn1.Marshal(sd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := asn1.Marshal(contentInfo{ContentType: oidSignedData, Content: asn1.RawValue{FullBytes: mustExplicit(t, content)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := asn1.Marshal(timeStampResp{Token: asn1.RawValue{FullBytes: token}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// mustExplicit wraps der in a [0] EXPLICIT tag.
+func mustExplicit(t *testing.T, der []byte) []byte {
+	t.Helper()
+	b, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: der})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// fakeTSA answers timestamp queries; tamper may rewrite the imprint.
+func fakeTSA(t *testing.T, at time.Time, tamper func([]byte) []byte) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/timestamp-query" {
+			http.Error(w, "bad content type", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req timeStampReq
+		if _, err := asn1.Unmarshal(body, &req); err != nil || req.Version != 1 || !req.CertReq {
+			http.Error(w, "bad query", http.StatusBadRequest)
+			return
+		}
+		imprint := req.MessageImprint.HashedMessage
+		if tamper != nil {
+			imprint = tamper(imprint)
+		}
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		w.Write(tsaResponse(t, imprint, req.Nonce, at))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTSATimestamp(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	srv := fakeTSA(t, at, nil)
+	digest := sha256.Sum256([]byte("head"))
+
+	stamp, err := NewTSA(srv.URL, srv.Client()).Timestamp(context.Background(), digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stamp.GenTime.Equal(at) || stamp.Serial != "42" || len(stamp.Token) == 0 {
+		t.Errorf("stamp = %+v", stamp)
+	}
+
+	anc := Anchor{Seq: 1, Head: hex.EncodeToString(digest[:]), At: stamp.GenTime, Token: stamp.Token}
+	if err := anc.Verify(); err != nil {
+		t.Errorf("verify: %v", err)
+	}
+	other := sha256.Sum256([]byte("rewritten"))
+	anc.Head = hex.EncodeToString(other[:])
+	if err := anc.Verify(); !errors.Is(err, ErrTimestampMismatch) {
+		t.Errorf("rewritten head: expected ErrTimestampMismatch, got %v", err)
+	}
+}
+
+func TestTSARejectsForeignToken(t *testing.T) {
+	srv := fakeTSA(t, time.Now(), func(b []byte) []byte {
+		other := sha256.Sum256(b)
+		return other[:]
+	})
+	_, err := NewTSA(srv.URL, srv.Client()).Timestamp(context.Background(), sha256.Sum256([]byte("head")))
+	if !errors.Is(err, ErrTimestampMismatch) {
+		t.Errorf("expected ErrTimestampMismatch, got %v", err)
+	}
+}
+
+func TestParseResponseRejected(t *testing.T) {
+	resp, err := asn1.Marshal(timeStampResp{Status: pkiStatusInfo{
+		Status:       2,
+		StatusString: []asn1.RawValue{{Tag: asn1.TagUTF8String, Bytes: []byte("bad alg")}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseResponse(resp, [32]byte{}, big.NewInt(1)); !errors.Is(err, ErrTimestampRejected) || !strings.Contains(err.Error(), "bad alg") {
+		t.Errorf("expected ErrTimestampRejected, got %v", err)
+	}
+}
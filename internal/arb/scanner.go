@@ -0,0 +1,218 @@
+package arb
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	"github.com/caesar-terminal/caesar/internal/order"
+	"github.com/caesar-terminal/caesar/internal/strategy"
+)
+
+var ErrBudgetExhausted = errors.New("arbitrage sub-budget exhausted")
+
+// Direction is the side of a complementary-outcome arbitrage.
+type Direction int
+
+const (
+	// BuyAll buys every outcome when the asks sum below 1.00.
+	BuyAll Direction = iota + 1
+	// SellAll sells every outcome when the bids sum above 1.00.
+	SellAll
+)
+
+// Mode selects what the scanner does with an opportunity.
+type Mode int
+
+const (
+	ModeAlert Mode = iota
+	ModeExecute
+)
+
+// OutcomeSet is a group of mutually exclusive, collectively exhaustive
+// outcome tokens: YES/NO for a binary market, or every outcome of a
+// NegRisk event. Exactly one token in the set resolves to 1.00.
+type OutcomeSet struct {
+	ID       string
+	TokenIDs []string
+}
+
+// Opportunity is a detected risk-free spread on an OutcomeSet.
+type Opportunity struct {
+	SetID     string
+	Direction Direction
+	Edge      int64 // fixed-point profit per share set (order.PriceScale)
+	Legs      []order.Leg
+	FoundAt   time.Time
+}
+
+// BookSource returns the latest book for a token.
+type BookSource func(tokenID string) (strategy.Book, bool)
+
+// Config controls scanner thresholds and behaviour.
+type Config struct {
+	// MinEdge is the smallest per-set profit worth acting on.
+	MinEdge int64
+	// Size is the share quantity for each leg.
+	Size *big.Int
+	Mode Mode
+	// Budget is the dedicated USDC sub-budget for auto-execution,
+	// separate from the session's overall value limit.
+	Budget *big.Int
+	// Clock stamps opportunities; nil uses the wall clock.
+	Clock clock.Clock
+}
+
+// Scanner checks outcome sets for complementary-price arbitrage.
+type Scanner struct {
+	cfg     Config
+	books   BookSource
+	alert   func(Opportunity)
+	execute func(Opportunity) error
+
+	mu   sync.Mutex
+	used *big.Int
+}
+
+// NewScanner creates a scanner. alert is called for every opportunity;
+// execute is only called in ModeExecute while the sub-budget allows.
+func NewScanner(cfg Config, books BookSource, alert func(Opportunity), execute func(Opportunity) error) *Scanner {
+	cfg.Clock = clock.Or(cfg.Clock)
+	return &Scanner{
+		cfg:     cfg,
+		books:   books,
+		alert:   alert,
+		execute: execute,
+		used:    new(big.Int),
+	}
+}
+
+// Scan evaluates every set once and returns the opportunities found.
+// Sets with any missing or empty book are skipped.
+func (s *Scanner) Scan(sets []OutcomeSet) []Opportunity {
+	var found []Opportunity
+	now := s.cfg.Clock.Now()
+
+	for _, set := range sets {
+		books := make([]strategy.Book, 0, len(set.TokenIDs))
+		for _, id := range set.TokenIDs {
+			b, ok := s.books(id)
+			if !ok {
+				break
+			}
+			books = append(books, b)
+		}
+		if len(books) != len(set.TokenIDs) || len(books) < 2 {
+			continue
+		}
+
+		if opp, ok := s.check(set.ID, books, BuyAll); ok {
+			opp.FoundAt = now
+			found = append(found, opp)
+		}
+		if opp, ok := s.check(set.ID, books, SellAll); ok {
+			opp.FoundAt = now
+			found = append(found, opp)
+		}
+	}
+
+	return found
+}
+
+// Run scans sets every interval until ctx is cancelled, dispatching each
+// opportunity to the alert and (in ModeExecute) execute handlers.
+func (s *Scanner) Run(ctx context.Context, interval time.Duration, sets func() []OutcomeSet) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, opp := range s.Scan(sets()) {
+				s.dispatch(opp)
+			}
+		}
+	}
+}
+
+// BudgetUsed returns the sub-budget consumed by auto-executed opportunities.
+func (s *Scanner) BudgetUsed() *big.Int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return new(big.Int).Set(s.used)
+}
+
+func (s *Scanner) dispatch(opp Opportunity) {
+	if s.alert != nil {
+		s.alert(opp)
+	}
+	if s.cfg.Mode != ModeExecute || s.execute == nil {
+		return
+	}
+	if err := s.reserve(order.BatchNotional(opp.Legs)); err != nil {
+		return
+	}
+	if err := s.execute(opp); err != nil {
+		s.release(order.BatchNotional(opp.Legs))
+	}
+}
+
+// reserve claims notional from the sub-budget before execution.
+func (s *Scanner) reserve(notional *big.Int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := new(big.Int).Add(s.used, notional)
+	if s.cfg.Budget == nil || total.Cmp(s.cfg.Budget) > 0 {
+		return ErrBudgetExhausted
+	}
+	s.used.Set(total)
+	return nil
+}
+
+// release returns notional to the sub-budget after a failed execution.
+func (s *Scanner) release(notional *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.used.Sub(s.used, notional)
+}
+
+func (s *Scanner) check(setID string, books []strategy.Book, dir Direction) (Opportunity, bool) {
+	var sum int64
+	for _, b := range books {
+		p := b.BestAsk
+		if dir == SellAll {
+			p = b.BestBid
+		}
+		if p <= 0 {
+			return Opportunity{}, false
+		}
+		sum += p
+	}
+
+	edge := order.PriceScale - sum
+	side := order.SideBuy
+	if dir == SellAll {
+		edge = sum - order.PriceScale
+		side = order.SideSell
+	}
+	if edge < s.cfg.MinEdge || edge <= 0 {
+		return Opportunity{}, false
+	}
+
+	legs := make([]order.Leg, 0, len(books))
+	for _, b := range books {
+		p := b.BestAsk
+		if dir == SellAll {
+			p = b.BestBid
+		}
+		legs = append(legs, order.NewLeg(b.TokenID, side, p, s.cfg.Size))
+	}
+
+	return Opportunity{SetID: setID, Direction: dir, Edge: edge, Legs: legs}, true
+}
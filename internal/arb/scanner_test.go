@@ -0,0 +1,116 @@
+package arb
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/caesar-terminal/caesar/internal/strategy"
+)
+
+func bookSource(books map[string]strategy.Book) BookSource {
+	return func(id string) (strategy.Book, bool) {
+		b, ok := books[id]
+		return b, ok
+	}
+}
+
+func TestScanBinaryBuyAll(t *testing.T) {
+	books := map[string]strategy.Book{
+		"yes": {TokenID: "yes", BestBid: 440_000, BestAsk: 450_000},
+		"no":  {TokenID: "no", BestBid: 520_000, BestAsk: 530_000},
+	}
+	s := NewScanner(Config{MinEdge: 10_000, Size: big.NewInt(1_000_000)}, bookSource(books), nil, nil)
+
+	opps := s.Scan([]OutcomeSet{{ID: "m1", TokenIDs: []string{"yes", "no"}}})
+	if len(opps) != 1 {
+		t.Fatalf("expected 1 opportunity, got %d", len(opps))
+	}
+	if opps[0].Direction != BuyAll || opps[0].Edge != 20_000 {
+		t.Errorf("unexpected opportunity: dir=%v edge=%d", opps[0].Direction, opps[0].Edge)
+	}
+	if len(opps[0].Legs) != 2 {
+		t.Errorf("expected a leg per outcome, got %d", len(opps[0].Legs))
+	}
+}
+
+func TestScanNegRiskSellAll(t *testing.T) {
+	books := map[string]strategy.Book{
+		"a": {TokenID: "a", BestBid: 400_000, BestAsk: 410_000},
+		"b": {TokenID: "b", BestBid: 350_000, BestAsk: 360_000},
+		"c": {TokenID: "c", BestBid: 300_000, BestAsk: 310_000},
+	}
+	s := NewScanner(Config{MinEdge: 10_000, Size: big.NewInt(1_000_000)}, bookSource(books), nil, nil)
+
+	opps := s.Scan([]OutcomeSet{{ID: "event", TokenIDs: []string{"a", "b", "c"}}})
+	if len(opps) != 1 || opps[0].Direction != SellAll || opps[0].Edge != 50_000 {
+		t.Fatalf("expected SellAll with 0.05 edge, got %+v", opps)
+	}
+}
+
+func TestScanSkipsBelowThresholdAndMissingBooks(t *testing.T) {
+	books := map[string]strategy.Book{
+		"yes": {TokenID: "yes", BestBid: 490_000, BestAsk: 495_000},
+		"no":  {TokenID: "no", BestBid: 500_000, BestAsk: 500_000},
+	}
+	s := NewScanner(Config{MinEdge: 10_000, Size: big.NewInt(1)}, bookSource(books), nil, nil)
+
+	if opps := s.Scan([]OutcomeSet{{ID: "m", TokenIDs: []string{"yes", "no"}}}); len(opps) != 0 {
+		t.Errorf("expected no opportunity below threshold, got %d", len(opps))
+	}
+	if opps := s.Scan([]OutcomeSet{{ID: "m", TokenIDs: []string{"yes", "missing"}}}); len(opps) != 0 {
+		t.Errorf("expected incomplete set to be skipped, got %d", len(opps))
+	}
+}
+
+func TestDispatchRespectsSubBudget(t *testing.T) {
+	books := map[string]strategy.Book{
+		"yes": {TokenID: "yes", BestAsk: 450_000},
+		"no":  {TokenID: "no", BestAsk: 500_000},
+	}
+	var alerts, executed int
+	s := NewScanner(Config{
+		MinEdge: 10_000,
+		Size:    big.NewInt(10_000_000),
+		Mode:    ModeExecute,
+		Budget:  big.NewInt(15_000_000),
+	}, bookSource(books), func(Opportunity) { alerts++ }, func(Opportunity) error {
+		executed++
+		return nil
+	})
+
+	set := []OutcomeSet{{ID: "m", TokenIDs: []string{"yes", "no"}}}
+	// Each execution costs 9.5 USDC; the 15 USDC sub-budget fits one.
+	for i := 0; i < 2; i++ {
+		for _, opp := range s.Scan(set) {
+			s.dispatch(opp)
+		}
+	}
+
+	if alerts != 2 || executed != 1 {
+		t.Errorf("alerts=%d executed=%d, want 2 and 1", alerts, executed)
+	}
+	if used := s.BudgetUsed(); used.Int64() != 9_500_000 {
+		t.Errorf("budget used %s, want 9500000", used)
+	}
+}
+
+func TestDispatchReleasesBudgetOnFailure(t *testing.T) {
+	books := map[string]strategy.Book{
+		"yes": {TokenID: "yes", BestAsk: 450_000},
+		"no":  {TokenID: "no", BestAsk: 500_000},
+	}
+	s := NewScanner(Config{
+		MinEdge: 10_000,
+		Size:    big.NewInt(10_000_000),
+		Mode:    ModeExecute,
+		Budget:  big.NewInt(100_000_000),
+	}, bookSource(books), nil, func(Opportunity) error { return errors.New("rejected") })
+
+	for _, opp := range s.Scan([]OutcomeSet{{ID: "m", TokenIDs: []string{"yes", "no"}}}) {
+		s.dispatch(opp)
+	}
+	if used := s.BudgetUsed(); used.Sign() != 0 {
+		t.Errorf("expected budget released after failure, used=%s", used)
+	}
+}
@@ -0,0 +1,237 @@
+package book
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+// SubscribeOptions tune what a subscriber receives.
+type SubscribeOptions struct {
+	// MaxRate caps updates per second per token; intermediate books are
+	// conflated so the subscriber always gets the latest one. 0 delivers
+	// every update the subscriber keeps up with.
+	MaxRate float64
+	// Depth limits levels per side; 0 keeps the full book.
+	Depth int
+}
+
+// Broadcaster fans book updates out to subscribers. Publishing never
+// blocks on a subscriber: each keeps only the latest book per token until
+// it is delivered, so a slow reader sees fewer, fresher updates rather
+// than a growing backlog.
+type Broadcaster struct {
+	clock clock.Clock
+
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	latest map[string]Snapshot
+}
+
+// NewBroadcaster creates a Broadcaster. A nil clk uses the wall clock.
+func NewBroadcaster(clk clock.Clock) *Broadcaster {
+	return &Broadcaster{
+		clock:  clock.Or(clk),
+		subs:   make(map[*Subscription]struct{}),
+		latest: make(map[string]Snapshot),
+	}
+}
+
+// Subscribe starts delivering updates for tokens, beginning with the
+// latest book already published for each. Close the subscription when
+// done.
+func (b *Broadcaster) Subscribe(tokens []string, opts SubscribeOptions) *Subscription {
+	out := make(chan Snapshot)
+	s := &Subscription{
+		C:       out,
+		out:     out,
+		b:       b,
+		opts:    opts,
+		tokens:  make(map[string]bool, len(tokens)),
+		pending: make(map[string]Snapshot),
+		last:    make(map[string]time.Time),
+		notify:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	if opts.MaxRate > 0 {
+		s.interval = time.Duration(float64(time.Second) / opts.MaxRate)
+	}
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+	s.Add(tokens...)
+	go s.run()
+	return s
+}
+
+// Publish delivers s to every subscriber of its token.
+func (b *Broadcaster) Publish(s Snapshot) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.latest[s.TokenID] = s
+	for sub := range b.subs {
+		sub.offer(s)
+	}
+}
+
+// Subscription is one subscriber's conflated view of the feed.
+type Subscription struct {
+	// C receives books. It is closed after Close.
+	C <-chan Snapshot
+
+	out      chan Snapshot
+	b        *Broadcaster
+	opts     SubscribeOptions
+	interval time.Duration
+	notify   chan struct{}
+	done     chan struct{}
+	once     sync.Once
+
+	onClose func()
+
+	mu      sync.Mutex
+	tokens  map[string]bool
+	pending map[string]Snapshot  // latest undelivered book per token
+	last    map[string]time.Time // last delivery per token
+}
+
+// Close stops delivery and closes C.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.b.mu.Lock()
+		delete(s.b.subs, s)
+		s.b.mu.Unlock()
+		close(s.done)
+		if s.onClose != nil {
+			s.onClose()
+		}
+	})
+}
+
+// Add extends the live subscription with tokens. Each new token's latest
+// published book is delivered right away.
+func (s *Subscription) Add(tokens ...string) {
+	s.b.mu.RLock()
+	s.mu.Lock()
+	added := false
+	for _, t := range tokens {
+		if s.tokens[t] {
+			continue
+		}
+		s.tokens[t] = true
+		if snap, ok := s.b.latest[t]; ok {
+			s.pending[t] = snap
+			added = true
+		}
+	}
+	s.mu.Unlock()
+	s.b.mu.RUnlock()
+
+	if added {
+		s.wake()
+	}
+}
+
+// Remove drops tokens from the live subscription, discarding any
+// undelivered books for them.
+func (s *Subscription) Remove(tokens ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range tokens {
+		delete(s.tokens, t)
+		delete(s.pending, t)
+		delete(s.last, t)
+	}
+}
+
+// Tokens returns the subscribed tokens, sorted.
+func (s *Subscription) Tokens() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, 0, len(s.tokens))
+	for t := range s.tokens {
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// offer replaces any undelivered book for the token with snap.
+func (s *Subscription) offer(snap Snapshot) {
+	s.mu.Lock()
+	if !s.tokens[snap.TokenID] {
+		s.mu.Unlock()
+		return
+	}
+	s.pending[snap.TokenID] = snap
+	s.mu.Unlock()
+	s.wake()
+}
+
+func (s *Subscription) wake() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Subscription) run() {
+	defer close(s.out)
+	for {
+		snap, ok, wait := s.next()
+		if ok {
+			select {
+			case s.out <- snap:
+			case <-s.done:
+				return
+			}
+			continue
+		}
+
+		var timer <-chan time.Time
+		if wait > 0 {
+			timer = s.b.clock.After(wait)
+		}
+		select {
+		case <-s.notify:
+		case <-timer:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// next takes the pending book that has waited longest since its token's
+// last delivery, if one is due. Otherwise it returns how long until the
+// earliest pending book becomes due (0 when nothing is pending).
+func (s *Subscription) next() (snap Snapshot, ok bool, wait time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.b.clock.Now()
+	var pick string
+	var pickDue time.Time
+	for token := range s.pending {
+		due := s.last[token].Add(s.interval)
+		if pick == "" || due.Before(pickDue) || (due.Equal(pickDue) && token < pick) {
+			pick, pickDue = token, due
+		}
+	}
+	if pick == "" {
+		return Snapshot{}, false, 0
+	}
+	if now.Before(pickDue) {
+		return Snapshot{}, false, pickDue.Sub(now)
+	}
+
+	snap = s.pending[pick]
+	delete(s.pending, pick)
+	s.last[pick] = now
+	depth := s.opts.Depth
+	if depth <= 0 {
+		depth = max(len(snap.Bids), len(snap.Asks))
+	}
+	return snap.Truncate(depth), true, 0
+}
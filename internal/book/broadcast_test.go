@@ -0,0 +1,98 @@
+package book
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+func recv(t *testing.T, s *Subscription) Snapshot {
+	t.Helper()
+	select {
+	case snap, ok := <-s.C:
+		if !ok {
+			t.Fatal("subscription closed")
+		}
+		return snap
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for update")
+	}
+	return Snapshot{}
+}
+
+func waitForTimers(t *testing.T, clk *clock.Fake, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for clk.Waiters() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d timers", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBroadcasterConflatesToMaxRate(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	b := NewBroadcaster(clk)
+	ui := b.Subscribe([]string{"a"}, SubscribeOptions{MaxRate: 4, Depth: 1})
+	defer ui.Close()
+
+	book := func(price int64) Snapshot {
+		return Snapshot{TokenID: "a", Bids: []Level{{price, 1}, {price - 10_000, 1}}}
+	}
+	b.Publish(book(500_000))
+	if got := recv(t, ui); got.Bids[0].Price != 500_000 || len(got.Bids) != 1 {
+		t.Fatalf("first update = %+v", got)
+	}
+
+	b.Publish(Snapshot{TokenID: "b"})
+	b.Publish(book(510_000))
+	b.Publish(book(520_000))
+	b.Publish(book(530_000))
+	waitForTimers(t, clk, 1)
+	select {
+	case got := <-ui.C:
+		t.Fatalf("update delivered before the rate interval: %+v", got)
+	default:
+	}
+
+	// Advance until the conflated book is released.
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case got := <-ui.C:
+			if got.Bids[0].Price != 530_000 {
+				t.Errorf("expected the latest conflated book, got %+v", got)
+			}
+			if elapsed := clk.Now().Sub(start); elapsed < 250*time.Millisecond {
+				t.Errorf("delivered after %s, want at least 250ms", elapsed)
+			}
+			return
+		case <-deadline:
+			t.Fatal("timed out waiting for conflated update")
+		case <-time.After(5 * time.Millisecond):
+			clk.Advance(50 * time.Millisecond)
+		}
+	}
+}
+
+func TestBroadcasterFullRateAndClose(t *testing.T) {
+	b := NewBroadcaster(nil)
+	strategy := b.Subscribe([]string{"a"}, SubscribeOptions{})
+
+	for i := int64(1); i <= 3; i++ {
+		b.Publish(Snapshot{TokenID: "a", Asks: []Level{{i, 1}, {i + 1, 1}}})
+		if got := recv(t, strategy); got.Asks[0].Price != i || len(got.Asks) != 2 {
+			t.Errorf("update %d = %+v", i, got)
+		}
+	}
+
+	strategy.Close()
+	strategy.Close()
+	if _, ok := <-strategy.C; ok {
+		t.Error("expected C to be closed")
+	}
+	b.Publish(Snapshot{TokenID: "a"})
+}
@@ -0,0 +1,205 @@
+package book
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+// cacheVersion is bumped whenever the on-disk cache layout changes; files
+// with another version are ignored rather than misread.
+const cacheVersion = 1
+
+// Market is the static metadata pre-trade checks need for a token.
+type Market struct {
+	TokenID     string `json:"token"`
+	ConditionID string `json:"condition"`
+	Question    string `json:"question"`
+	TickSize    int64  `json:"tick"`     // fixed-point, 1_000_000 == 1.00
+	MinSize     int64  `json:"min_size"` // raw share units
+	Closed      bool   `json:"closed"`
+}
+
+// CachedBook is a book as held by the cache. Warm books were restored from
+// disk and have not yet been refreshed by a live update; they are good
+// enough to render and to run pre-trade checks against, but not to price
+// an order.
+type CachedBook struct {
+	Snapshot
+	Warm bool
+}
+
+type cacheFile struct {
+	Version int               `json:"version"`
+	SavedAt time.Time         `json:"saved_at"`
+	Books   []Snapshot        `json:"books"`
+	Markets map[string]Market `json:"markets"`
+}
+
+// Cache keeps the latest book per token plus market metadata and persists
+// them, so a restart can render and run pre-trade checks immediately while
+// live subscriptions warm up.
+type Cache struct {
+	path   string
+	depth  int
+	maxAge time.Duration
+	clock  clock.Clock
+
+	mu      sync.RWMutex
+	books   map[string]CachedBook
+	markets map[string]Market
+}
+
+// NewCache creates a cache persisted at path. Books are stored at most depth
+// levels deep, and restored books older than maxAge are discarded. A nil
+// clk uses the wall clock.
+func NewCache(path string, depth int, maxAge time.Duration, clk clock.Clock) *Cache {
+	return &Cache{
+		path:    path,
+		depth:   depth,
+		maxAge:  maxAge,
+		clock:   clock.Or(clk),
+		books:   make(map[string]CachedBook),
+		markets: make(map[string]Market),
+	}
+}
+
+// Load restores the cache from disk. A missing file, or one written by a
+// different cache version, is not an error: the cache simply starts cold.
+// Restored books are marked Warm. Live updates already received win over
+// restored ones.
+func (c *Cache) Load() error {
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var f cacheFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("book cache %s: %w", c.path, err)
+	}
+	if f.Version != cacheVersion {
+		return nil
+	}
+
+	cutoff := c.clock.Now().Add(-c.maxAge)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range f.Books {
+		if c.maxAge > 0 && s.At.Before(cutoff) {
+			continue
+		}
+		if _, live := c.books[s.TokenID]; live {
+			continue
+		}
+		c.books[s.TokenID] = CachedBook{Snapshot: s, Warm: true}
+	}
+	for id, m := range f.Markets {
+		if _, ok := c.markets[id]; !ok {
+			c.markets[id] = m
+		}
+	}
+	return nil
+}
+
+// Update records a live book, clearing its warm flag.
+func (c *Cache) Update(s Snapshot) {
+	s = s.Truncate(c.depth)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.books[s.TokenID] = CachedBook{Snapshot: s}
+}
+
+// SetMarket records market metadata.
+func (c *Cache) SetMarket(m Market) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.markets[m.TokenID] = m
+}
+
+// Book returns the cached book for token.
+func (c *Cache) Book(tokenID string) (CachedBook, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	b, ok := c.books[tokenID]
+	if ok {
+		b.Snapshot = b.Snapshot.Truncate(c.depth)
+	}
+	return b, ok
+}
+
+// Market returns the cached metadata for token.
+func (c *Cache) Market(tokenID string) (Market, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m, ok := c.markets[tokenID]
+	return m, ok
+}
+
+// Save writes the cache to disk atomically with owner-only permissions.
+func (c *Cache) Save() error {
+	c.mu.RLock()
+	f := cacheFile{
+		Version: cacheVersion,
+		SavedAt: c.clock.Now().UTC(),
+		Books:   make([]Snapshot, 0, len(c.books)),
+		Markets: make(map[string]Market, len(c.markets)),
+	}
+	for _, b := range c.books {
+		f.Books = append(f.Books, b.Snapshot)
+	}
+	for id, m := range c.markets {
+		f.Markets[id] = m
+	}
+	c.mu.RUnlock()
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(c.path, data)
+}
+
+// writeFileAtomic replaces path with data via a temp file in the same
+// directory. The temp file, and so the result, is owner-only.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Run saves every interval and once more when ctx is cancelled.
+func (c *Cache) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return c.Save()
+		case <-ticker.C:
+			if err := c.Save(); err != nil {
+				return err
+			}
+		}
+	}
+}
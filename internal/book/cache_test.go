@@ -0,0 +1,65 @@
+package book
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+func TestCacheRestoresWarmBooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book-cache.json")
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(now)
+
+	c := NewCache(path, 2, time.Hour, clk)
+	c.Update(Snapshot{TokenID: "fresh", At: now, Bids: []Level{{500_000, 10}, {490_000, 5}, {480_000, 1}}})
+	c.Update(Snapshot{TokenID: "old", At: now.Add(-2 * time.Hour)})
+	c.SetMarket(Market{TokenID: "fresh", TickSize: 10_000, MinSize: 5})
+	if err := c.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("cache file mode: %v %v", info, err)
+	}
+
+	restored := NewCache(path, 2, time.Hour, clk)
+	if err := restored.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	b, ok := restored.Book("fresh")
+	if !ok || !b.Warm || len(b.Bids) != 2 {
+		t.Fatalf("restored book = %+v, %v", b, ok)
+	}
+	if _, ok := restored.Book("old"); ok {
+		t.Error("books older than maxAge should be discarded")
+	}
+	if m, ok := restored.Market("fresh"); !ok || m.TickSize != 10_000 {
+		t.Errorf("restored market = %+v, %v", m, ok)
+	}
+
+	restored.Update(Snapshot{TokenID: "fresh", At: now.Add(time.Second)})
+	if b, _ := restored.Book("fresh"); b.Warm {
+		t.Error("live update should clear warm flag")
+	}
+}
+
+func TestCacheLoadMissingOrOtherVersion(t *testing.T) {
+	dir := t.TempDir()
+	c := NewCache(filepath.Join(dir, "missing.json"), 5, time.Hour, nil)
+	if err := c.Load(); err != nil {
+		t.Errorf("missing file should start cold, got %v", err)
+	}
+
+	path := filepath.Join(dir, "v0.json")
+	os.WriteFile(path, []byte(`{"version":0,"books":[{"token":"x"}]}`), 0o600)
+	c = NewCache(path, 5, 0, nil)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Book("x"); ok {
+		t.Error("other cache versions should be ignored")
+	}
+}
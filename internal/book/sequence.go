@@ -0,0 +1,180 @@
+package book
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrBookUnsynced = errors.New("book is out of sync")
+
+// Side selects the side of the book a delta applies to.
+type Side int
+
+const (
+	Bid Side = iota
+	Ask
+)
+
+// Delta changes one price level. Size 0 removes the level. Seq must be
+// exactly one more than the previous message for the token; Hash, when
+// set, is the expected hash of the book after applying it.
+type Delta struct {
+	TokenID string
+	Seq     uint64
+	At      time.Time
+	Side    Side
+	Price   int64
+	Size    int64
+	Hash    string
+}
+
+// Resync reasons reported to OnResync.
+const (
+	ResyncGap  = "gap"
+	ResyncHash = "hash_mismatch"
+)
+
+// FetchFunc returns a full snapshot of token's book and the sequence number
+// it reflects.
+type FetchFunc func(ctx context.Context, tokenID string) (Snapshot, uint64, error)
+
+// Sequencer maintains books from a snapshot-plus-deltas feed. A sequence
+// gap or hash mismatch discards the local book and re-fetches a snapshot,
+// so consumers only ever see books that match the exchange.
+type Sequencer struct {
+	fetch FetchFunc
+	hash  func(Snapshot) string
+
+	// OnResync, if set, is called for every re-fetch with the token and
+	// one of the Resync* reasons.
+	OnResync func(tokenID, reason string)
+
+	mu    sync.Mutex
+	books map[string]*seqBook
+}
+
+type seqBook struct {
+	snap Snapshot
+	seq  uint64
+}
+
+// NewSequencer creates a Sequencer that recovers via fetch. hash computes
+// the exchange's book hash for verification; nil skips hash checks.
+func NewSequencer(fetch FetchFunc, hash func(Snapshot) string) *Sequencer {
+	return &Sequencer{fetch: fetch, hash: hash, books: make(map[string]*seqBook)}
+}
+
+// ApplySnapshot replaces token's book with a full snapshot at seq.
+func (s *Sequencer) ApplySnapshot(snap Snapshot, seq uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.books[snap.TokenID] = &seqBook{snap: snap.clone(), seq: seq}
+}
+
+// Apply applies d and returns the resulting book. Deltas at or below the
+// current sequence are duplicates and leave the book unchanged. On a gap
+// or hash mismatch the book is re-fetched; if that fails the token stays
+// unsynced and ErrBookUnsynced is returned until a snapshot arrives.
+func (s *Sequencer) Apply(ctx context.Context, d Delta) (Snapshot, error) {
+	s.mu.Lock()
+	b, ok := s.books[d.TokenID]
+	switch {
+	case !ok:
+		s.mu.Unlock()
+		return s.resync(ctx, d.TokenID, ResyncGap)
+	case d.Seq <= b.seq:
+		snap := b.snap
+		s.mu.Unlock()
+		return snap, nil
+	case d.Seq != b.seq+1:
+		delete(s.books, d.TokenID)
+		s.mu.Unlock()
+		return s.resync(ctx, d.TokenID, ResyncGap)
+	}
+
+	next := b.snap.clone()
+	next.At = d.At
+	if d.Side == Bid {
+		next.Bids = setLevel(next.Bids, d.Price, d.Size, true)
+	} else {
+		next.Asks = setLevel(next.Asks, d.Price, d.Size, false)
+	}
+	if s.hash != nil && d.Hash != "" && s.hash(next) != d.Hash {
+		delete(s.books, d.TokenID)
+		s.mu.Unlock()
+		return s.resync(ctx, d.TokenID, ResyncHash)
+	}
+	b.snap, b.seq = next, d.Seq
+	s.mu.Unlock()
+	return next, nil
+}
+
+// Book returns token's current book. ok is false while it is unsynced.
+func (s *Sequencer) Book(tokenID string) (Snapshot, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.books[tokenID]
+	if !ok {
+		return Snapshot{}, false
+	}
+	return b.snap, true
+}
+
+func (s *Sequencer) resync(ctx context.Context, tokenID, reason string) (Snapshot, error) {
+	if s.OnResync != nil {
+		s.OnResync(tokenID, reason)
+	}
+	snap, seq, err := s.fetch(ctx, tokenID)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%w: %s: %w", ErrBookUnsynced, tokenID, err)
+	}
+	snap.TokenID = tokenID
+	s.ApplySnapshot(snap, seq)
+	return snap, nil
+}
+
+// setLevel sets or removes the level at price, keeping levels ordered best
+// first: descending for bids, ascending for asks.
+func setLevel(levels []Level, price, size int64, desc bool) []Level {
+	i := sort.Search(len(levels), func(i int) bool {
+		if desc {
+			return levels[i].Price <= price
+		}
+		return levels[i].Price >= price
+	})
+	found := i < len(levels) && levels[i].Price == price
+	switch {
+	case size == 0 && found:
+		return append(levels[:i], levels[i+1:]...)
+	case size == 0:
+		return levels
+	case found:
+		levels[i].Size = size
+		return levels
+	}
+	levels = append(levels, Level{})
+	copy(levels[i+1:], levels[i:])
+	levels[i] = Level{Price: price, Size: size}
+	return levels
+}
+
+// LevelsHash is a canonical SHA-1 of a book's levels: "price:size" pairs,
+// bids then asks, each side best first. Feeds that publish a hash in
+// another form supply their own function to NewSequencer.
+func LevelsHash(s Snapshot) string {
+	h := sha1.New()
+	for _, side := range [][]Level{s.Bids, s.Asks} {
+		for _, l := range side {
+			h.Write([]byte(strconv.FormatInt(l.Price, 10) + ":" + strconv.FormatInt(l.Size, 10) + ","))
+		}
+		h.Write([]byte("|"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
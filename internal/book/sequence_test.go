@@ -0,0 +1,73 @@
+package book
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSequencerAppliesDeltasAndResyncs(t *testing.T) {
+	exchange := Snapshot{TokenID: "a", Bids: []Level{{500_000, 10}}, Asks: []Level{{520_000, 5}}}
+	fetches := 0
+	var fetchErr error
+	var resyncs []string
+	s := NewSequencer(func(_ context.Context, token string) (Snapshot, uint64, error) {
+		fetches++
+		return exchange, 10, fetchErr
+	}, LevelsHash)
+	s.OnResync = func(_, reason string) { resyncs = append(resyncs, reason) }
+	ctx := context.Background()
+
+	// The first delta for an unknown book triggers the initial fetch.
+	if _, err := s.Apply(ctx, Delta{TokenID: "a", Seq: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Apply(ctx, Delta{TokenID: "a", Seq: 11, Side: Bid, Price: 510_000, Size: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Bids) != 2 || got.Bids[0].Price != 510_000 {
+		t.Fatalf("bids = %+v", got.Bids)
+	}
+	want := Snapshot{Bids: []Level{{510_000, 3}, {500_000, 10}}}
+	if got, err = s.Apply(ctx, Delta{TokenID: "a", Seq: 12, Side: Ask, Price: 520_000, Size: 0, Hash: LevelsHash(want)}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Asks) != 0 {
+		t.Fatalf("asks = %+v", got.Asks)
+	}
+	if got, _ = s.Apply(ctx, Delta{TokenID: "a", Seq: 12, Side: Bid, Price: 1, Size: 1}); len(got.Bids) != 2 {
+		t.Errorf("duplicate delta should be ignored, got %+v", got.Bids)
+	}
+	if fetches != 1 {
+		t.Fatalf("fetches = %d", fetches)
+	}
+
+	// A gap discards the local book in favour of a fresh snapshot.
+	if got, err = s.Apply(ctx, Delta{TokenID: "a", Seq: 14, Side: Bid, Price: 1, Size: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Asks) != 1 || len(got.Bids) != 1 {
+		t.Errorf("expected the re-fetched snapshot, got %+v", got)
+	}
+
+	// So does a hash mismatch; while the fetch fails the book is unsynced.
+	fetchErr = errors.New("exchange down")
+	if _, err := s.Apply(ctx, Delta{TokenID: "a", Seq: 11, Side: Bid, Price: 1, Size: 1, Hash: "bogus"}); !errors.Is(err, ErrBookUnsynced) {
+		t.Errorf("expected ErrBookUnsynced, got %v", err)
+	}
+	if _, ok := s.Book("a"); ok {
+		t.Error("unsynced book should not be served")
+	}
+
+	wantReasons := []string{ResyncGap, ResyncGap, ResyncHash}
+	if len(resyncs) != len(wantReasons) {
+		t.Fatalf("resyncs = %v", resyncs)
+	}
+	for i := range wantReasons {
+		if resyncs[i] != wantReasons[i] {
+			t.Errorf("resync %d = %s, want %s", i, resyncs[i], wantReasons[i])
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package book
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Level is a single price level. Price is fixed-point (1_000_000 == 1.00)
+// and Size is in raw share units.
+type Level struct {
+	Price int64 `json:"p"`
+	Size  int64 `json:"s"`
+}
+
+// Snapshot is the state of one token's book at a point in time. Bids are
+// ordered best (highest) first and asks best (lowest) first.
+type Snapshot struct {
+	TokenID string    `json:"token"`
+	At      time.Time `json:"at"`
+	Bids    []Level   `json:"bids"`
+	Asks    []Level   `json:"asks"`
+}
+
+// Truncate returns a copy of s keeping at most depth levels per side.
+func (s Snapshot) Truncate(depth int) Snapshot {
+	out := s
+	if len(out.Bids) > depth {
+		out.Bids = out.Bids[:depth]
+	}
+	if len(out.Asks) > depth {
+		out.Asks = out.Asks[:depth]
+	}
+	out.Bids = append([]Level(nil), out.Bids...)
+	out.Asks = append([]Level(nil), out.Asks...)
+	return out
+}
+
+// clone returns a copy of s that shares no level slices with it.
+func (s Snapshot) clone() Snapshot {
+	return s.Truncate(max(len(s.Bids), len(s.Asks)))
+}
+
+// Source returns the current book for every recorded token.
+type Source func() []Snapshot
+
+// Recorder periodically appends depth-limited snapshots to a JSON-lines
+// file for post-trade analysis.
+type Recorder struct {
+	path     string
+	depth    int
+	interval time.Duration
+	source   Source
+
+	mu sync.Mutex
+}
+
+// NewRecorder creates a recorder writing to path every interval.
+func NewRecorder(path string, depth int, interval time.Duration, source Source) *Recorder {
+	return &Recorder{path: path, depth: depth, interval: interval, source: source}
+}
+
+// Run records until ctx is cancelled.
+func (r *Recorder) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.Record(r.source()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Record appends snaps to the recording file.
+func (r *Recorder) Record(snaps []Snapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, s := range snaps {
+		if err := enc.Encode(s.Truncate(r.depth)); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// ReadWindow returns snapshots for tokenID (all tokens if empty) whose
+// timestamps fall within [from, to].
+func ReadWindow(rd io.Reader, tokenID string, from, to time.Time) ([]Snapshot, error) {
+	var out []Snapshot
+	dec := json.NewDecoder(rd)
+	for {
+		var s Snapshot
+		if err := dec.Decode(&s); err != nil {
+			if errors.Is(err, io.EOF) {
+				return out, nil
+			}
+			return nil, err
+		}
+		if tokenID != "" && s.TokenID != tokenID {
+			continue
+		}
+		if s.At.Before(from) || s.At.After(to) {
+			continue
+		}
+		out = append(out, s)
+	}
+}
+
+// Columns computes extra fields for each exported row from the row's own
+// fields, keyed by header. *report.Columns implements it.
+type Columns interface {
+	Names() []string
+	Row(fields map[string]any) ([]string, error)
+}
+
+// WriteCSV exports snapshots as one row per level, suitable for plotting a
+// heatmap: at, token, side, level, price, size, then any extra columns.
+// Timestamps are rendered in loc (UTC if nil) with an explicit offset.
+func WriteCSV(w io.Writer, snaps []Snapshot, loc *time.Location, extra Columns) error {
+	if loc == nil {
+		loc = time.UTC
+	}
+	header := []string{"at", "token", "side", "level", "price", "size"}
+	if extra != nil {
+		header = append(header, extra.Names()...)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, s := range snaps {
+		at := s.At.In(loc).Format(time.RFC3339Nano)
+		for _, side := range []struct {
+			name   string
+			levels []Level
+		}{{"bid", s.Bids}, {"ask", s.Asks}} {
+			for i, l := range side.levels {
+				price := float64(l.Price) / 1_000_000
+				row := []string{
+					at, s.TokenID, side.name, strconv.Itoa(i),
+					strconv.FormatFloat(price, 'f', 6, 64),
+					strconv.FormatInt(l.Size, 10),
+				}
+				if extra != nil {
+					computed, err := extra.Row(map[string]any{
+						"at": at, "token": s.TokenID, "side": side.name, "level": float64(i),
+						"price": price, "size": float64(l.Size),
+					})
+					if err != nil {
+						return err
+					}
+					row = append(row, computed...)
+				}
+				if err := cw.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
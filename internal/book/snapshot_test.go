@@ -0,0 +1,75 @@
+package book
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordReadWindowAndCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "books.jsonl")
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	r := NewRecorder(path, 1, time.Second, nil)
+	for i := 0; i < 3; i++ {
+		err := r.Record([]Snapshot{{
+			TokenID: "tok",
+			At:      base.Add(time.Duration(i) * time.Minute),
+			Bids:    []Level{{Price: 490_000, Size: 10}, {Price: 480_000, Size: 20}},
+			Asks:    []Level{{Price: 510_000, Size: 5}},
+		}, {
+			TokenID: "other",
+			At:      base.Add(time.Duration(i) * time.Minute),
+		}})
+		if err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+
+	snaps, err := ReadWindow(f, "tok", base.Add(30*time.Second), base.Add(2*time.Minute))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(snaps) != 2 {
+		t.Fatalf("expected 2 snapshots in window, got %d", len(snaps))
+	}
+	if len(snaps[0].Bids) != 1 {
+		t.Errorf("expected depth truncated to 1, got %d bids", len(snaps[0].Bids))
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, snaps, nil, nil); err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || lines[1] != "2026-01-01T12:01:00Z,tok,bid,0,0.490000,10" {
+		t.Errorf("unexpected csv:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteCSV(&buf, snaps[:1], nil, notionalColumn{}); err != nil {
+		t.Fatalf("csv with columns: %v", err)
+	}
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "at,token,side,level,price,size,notional" || lines[1] != "2026-01-01T12:01:00Z,tok,bid,0,0.490000,10,4.9" {
+		t.Errorf("unexpected csv with columns:\n%s", buf.String())
+	}
+}
+
+type notionalColumn struct{}
+
+func (notionalColumn) Names() []string { return []string{"notional"} }
+
+func (notionalColumn) Row(f map[string]any) ([]string, error) {
+	return []string{strconv.FormatFloat(f["price"].(float64)*f["size"].(float64), 'f', -1, 64)}, nil
+}
@@ -0,0 +1,151 @@
+package book
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+)
+
+var ErrUnknownWatchlist = errors.New("unknown watchlist")
+
+// Watchlists are named sets of tokens persisted as a JSON object of name
+// to token IDs. Streams opened with Subscribe follow later Add and Remove
+// calls on their watchlist, so a UI or strategy holds one subscription per
+// list instead of one per market.
+type Watchlists struct {
+	path string
+
+	mu      sync.Mutex
+	lists   map[string][]string
+	streams map[string]map[*Subscription]struct{}
+}
+
+// LoadWatchlists reads watchlists from path. A missing file yields an
+// empty set that Save will create.
+func LoadWatchlists(path string) (*Watchlists, error) {
+	w := &Watchlists{
+		path:    path,
+		lists:   make(map[string][]string),
+		streams: make(map[string]map[*Subscription]struct{}),
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return w, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &w.lists); err != nil {
+		return nil, fmt.Errorf("watchlists %s: %w", path, err)
+	}
+	return w, nil
+}
+
+// Names returns the watchlist names, sorted.
+func (w *Watchlists) Names() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	names := make([]string, 0, len(w.lists))
+	for name := range w.lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tokens returns the tokens on the named watchlist.
+func (w *Watchlists) Tokens(name string) ([]string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	tokens, ok := w.lists[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownWatchlist, name)
+	}
+	return append([]string(nil), tokens...), nil
+}
+
+// Add appends tokens to the named watchlist, creating it if needed, and
+// to every stream open on it.
+func (w *Watchlists) Add(name string, tokens ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	list := w.lists[name]
+	if list == nil {
+		list = []string{}
+	}
+	for _, t := range tokens {
+		if !slices.Contains(list, t) {
+			list = append(list, t)
+		}
+	}
+	w.lists[name] = list
+	for s := range w.streams[name] {
+		s.Add(tokens...)
+	}
+}
+
+// Remove drops tokens from the named watchlist and its open streams.
+func (w *Watchlists) Remove(name string, tokens ...string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	list, ok := w.lists[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownWatchlist, name)
+	}
+	kept := list[:0]
+	for _, t := range list {
+		if !slices.Contains(tokens, t) {
+			kept = append(kept, t)
+		}
+	}
+	w.lists[name] = kept
+	for s := range w.streams[name] {
+		s.Remove(tokens...)
+	}
+	return nil
+}
+
+// Delete removes the named watchlist. Open streams keep their tokens.
+func (w *Watchlists) Delete(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.lists, name)
+	delete(w.streams, name)
+}
+
+// Subscribe opens one stream on b covering the named watchlist.
+func (w *Watchlists) Subscribe(b *Broadcaster, name string, opts SubscribeOptions) (*Subscription, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	tokens, ok := w.lists[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownWatchlist, name)
+	}
+	s := b.Subscribe(tokens, opts)
+	if w.streams[name] == nil {
+		w.streams[name] = make(map[*Subscription]struct{})
+	}
+	w.streams[name][s] = struct{}{}
+	s.onClose = func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.streams[name], s)
+	}
+	return s, nil
+}
+
+// Save writes the watchlists to disk atomically with owner-only
+// permissions.
+func (w *Watchlists) Save() error {
+	w.mu.Lock()
+	data, err := json.MarshalIndent(w.lists, "", "  ")
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(w.path, data)
+}
@@ -0,0 +1,60 @@
+package book
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWatchlistStreamFollowsEdits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watchlists.json")
+	w, err := LoadWatchlists(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Add("elections", "a", "b")
+	if err := w.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBroadcaster(nil)
+	b.Publish(Snapshot{TokenID: "c", Bids: []Level{{1, 1}}})
+	w, err = LoadWatchlists(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Subscribe(b, "sports", SubscribeOptions{}); !errors.Is(err, ErrUnknownWatchlist) {
+		t.Errorf("expected ErrUnknownWatchlist, got %v", err)
+	}
+	stream, err := w.Subscribe(b, "elections", SubscribeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	b.Publish(Snapshot{TokenID: "a"})
+	if got := recv(t, stream); got.TokenID != "a" {
+		t.Errorf("got %+v", got)
+	}
+
+	// Adding a market delivers its latest book without resubscribing.
+	w.Add("elections", "c")
+	if got := recv(t, stream); got.TokenID != "c" {
+		t.Errorf("got %+v", got)
+	}
+	if err := w.Remove("elections", "a"); err != nil {
+		t.Fatal(err)
+	}
+	b.Publish(Snapshot{TokenID: "a"})
+	b.Publish(Snapshot{TokenID: "b"})
+	if got := recv(t, stream); got.TokenID != "b" {
+		t.Errorf("removed market still streamed: %+v", got)
+	}
+	if got, _ := w.Tokens("elections"); !slices.Equal(got, []string{"b", "c"}) {
+		t.Errorf("tokens = %v", got)
+	}
+	if got := stream.Tokens(); !slices.Equal(got, []string{"b", "c"}) {
+		t.Errorf("stream tokens = %v", got)
+	}
+}
@@ -0,0 +1,65 @@
+package candle
+
+import (
+	"sort"
+	"time"
+)
+
+// Candle is an OHLC bar. Prices are fixed-point with 1_000_000 == 1.00 and
+// Volume is in raw share units.
+type Candle struct {
+	Start  time.Time
+	Open   int64
+	High   int64
+	Low    int64
+	Close  int64
+	Volume int64
+}
+
+// Point is a single observed price (a trade or a history sample).
+type Point struct {
+	At    time.Time
+	Price int64
+	Size  int64 // 0 for history samples that carry no volume
+}
+
+// Bucket groups points into candles of the given interval, aligned with
+// time.Truncate. Points need not be sorted. Intervals with no points produce
+// no candle.
+func Bucket(points []Point, interval time.Duration) []Candle {
+	if len(points) == 0 || interval <= 0 {
+		return nil
+	}
+
+	sorted := append([]Point(nil), points...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].At.Before(sorted[j].At) })
+
+	var out []Candle
+	for _, p := range sorted {
+		start := p.At.Truncate(interval)
+		if n := len(out); n > 0 && out[n-1].Start.Equal(start) {
+			out[n-1].add(p)
+			continue
+		}
+		out = append(out, Candle{
+			Start:  start,
+			Open:   p.Price,
+			High:   p.Price,
+			Low:    p.Price,
+			Close:  p.Price,
+			Volume: p.Size,
+		})
+	}
+	return out
+}
+
+func (c *Candle) add(p Point) {
+	if p.Price > c.High {
+		c.High = p.Price
+	}
+	if p.Price < c.Low {
+		c.Low = p.Price
+	}
+	c.Close = p.Price
+	c.Volume += p.Size
+}
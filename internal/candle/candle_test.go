@@ -0,0 +1,33 @@
+package candle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucket(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	points := []Point{
+		{At: base.Add(90 * time.Second), Price: 520_000, Size: 5},
+		{At: base.Add(10 * time.Second), Price: 500_000, Size: 10},
+		{At: base.Add(30 * time.Second), Price: 550_000, Size: 1},
+		{At: base.Add(50 * time.Second), Price: 490_000, Size: 2},
+		{At: base.Add(5 * time.Minute), Price: 600_000, Size: 3},
+	}
+
+	got := Bucket(points, time.Minute)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 candles (empty minutes skipped), got %d", len(got))
+	}
+
+	first := got[0]
+	if !first.Start.Equal(base) || first.Open != 500_000 || first.High != 550_000 || first.Low != 490_000 || first.Close != 490_000 || first.Volume != 13 {
+		t.Errorf("unexpected first candle: %+v", first)
+	}
+	if got[1].Open != 520_000 || got[1].Close != 520_000 {
+		t.Errorf("unexpected second candle: %+v", got[1])
+	}
+	if !got[2].Start.Equal(base.Add(5 * time.Minute)) {
+		t.Errorf("unexpected third candle start: %v", got[2].Start)
+	}
+}
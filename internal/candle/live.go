@@ -0,0 +1,77 @@
+package candle
+
+import (
+	"sync"
+	"time"
+)
+
+// Aggregator folds a live trade feed into fixed-interval candles. It keeps
+// a bounded window of completed candles plus the candle currently forming.
+type Aggregator struct {
+	interval time.Duration
+	max      int
+
+	mu      sync.Mutex
+	closed  []Candle
+	current *Candle
+}
+
+// NewAggregator creates an aggregator for interval (e.g. 1m, 5m) retaining
+// up to max completed candles.
+func NewAggregator(interval time.Duration, max int) *Aggregator {
+	return &Aggregator{interval: interval, max: max}
+}
+
+// Add folds a trade into the forming candle, rolling over when the trade
+// belongs to a later interval. Trades older than the forming candle are
+// ignored so out-of-order delivery cannot rewrite closed bars.
+func (a *Aggregator) Add(p Point) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	start := p.At.Truncate(a.interval)
+	switch {
+	case a.current == nil:
+	case start.Equal(a.current.Start):
+		a.current.add(p)
+		return
+	case start.Before(a.current.Start):
+		return
+	default:
+		a.closed = append(a.closed, *a.current)
+		if len(a.closed) > a.max {
+			a.closed = a.closed[len(a.closed)-a.max:]
+		}
+	}
+	a.current = &Candle{Start: start, Open: p.Price, High: p.Price, Low: p.Price, Close: p.Price, Volume: p.Size}
+}
+
+// Candles returns completed candles followed by the forming one.
+func (a *Aggregator) Candles() []Candle {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	out := append([]Candle(nil), a.closed...)
+	if a.current != nil {
+		out = append(out, *a.current)
+	}
+	return out
+}
+
+// Merge combines fetched history with live candles. Where both cover the
+// same interval the live candle wins, since it was built from the full
+// trade stream rather than sampled history.
+func Merge(history, live []Candle) []Candle {
+	if len(live) == 0 {
+		return append([]Candle(nil), history...)
+	}
+
+	firstLive := live[0].Start
+	out := make([]Candle, 0, len(history)+len(live))
+	for _, c := range history {
+		if c.Start.Before(firstLive) {
+			out = append(out, c)
+		}
+	}
+	return append(out, live...)
+}
@@ -0,0 +1,59 @@
+package candle
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAggregatorRollsOverAndMerges(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	a := NewAggregator(time.Minute, 2)
+
+	a.Add(Point{At: base.Add(5 * time.Second), Price: 500_000, Size: 1})
+	a.Add(Point{At: base.Add(20 * time.Second), Price: 520_000, Size: 1})
+	a.Add(Point{At: base.Add(70 * time.Second), Price: 510_000, Size: 2})
+	a.Add(Point{At: base.Add(30 * time.Second), Price: 900_000, Size: 1}) // late, ignored
+	a.Add(Point{At: base.Add(130 * time.Second), Price: 530_000, Size: 1})
+	a.Add(Point{At: base.Add(190 * time.Second), Price: 540_000, Size: 1})
+
+	live := a.Candles()
+	if len(live) != 3 {
+		t.Fatalf("expected 2 retained closed + 1 forming, got %d", len(live))
+	}
+	if !live[0].Start.Equal(base.Add(time.Minute)) || live[0].Volume != 2 {
+		t.Errorf("unexpected oldest retained candle: %+v", live[0])
+	}
+
+	history := []Candle{
+		{Start: base.Add(-time.Minute), Close: 480_000},
+		{Start: base, Close: 490_000},
+		{Start: base.Add(time.Minute), Close: 1}, // superseded by live
+	}
+	merged := Merge(history, live)
+	if len(merged) != 5 || merged[2].Volume != 2 {
+		t.Errorf("unexpected merge: %+v", merged)
+	}
+}
+
+func TestRender(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	candles := []Candle{
+		{Start: base, Open: 400_000, High: 600_000, Low: 400_000, Close: 600_000},
+		{Start: base.Add(time.Minute), Open: 600_000, High: 600_000, Low: 400_000, Close: 500_000},
+	}
+
+	lines := Render(candles, []Marker{{Label: "entry", Price: 500_000}}, 10, 5)
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 rows, got %d", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "█▒") || !strings.Contains(lines[0], "0.600") {
+		t.Errorf("unexpected top row: %q", lines[0])
+	}
+	if !strings.Contains(lines[2], "entry") {
+		t.Errorf("expected entry marker on middle row, got %q", lines[2])
+	}
+	if !strings.HasPrefix(lines[4], "█│") {
+		t.Errorf("unexpected bottom row: %q", lines[4])
+	}
+}
@@ -0,0 +1,85 @@
+package candle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Marker is a horizontal price line drawn across the chart, such as a
+// position's entry price.
+type Marker struct {
+	Label string
+	Price int64
+}
+
+// Render draws candles as a text chart height rows tall, one column per
+// candle (the most recent candles that fit in width). Bodies are drawn with
+// '█' for up candles and '▒' for down candles, wicks with '│', and markers
+// as '─' lines. A price axis is appended to the right of each row.
+func Render(candles []Candle, markers []Marker, width, height int) []string {
+	if width <= 0 || height <= 1 || len(candles) == 0 {
+		return nil
+	}
+	if len(candles) > width {
+		candles = candles[len(candles)-width:]
+	}
+
+	lo, hi := candles[0].Low, candles[0].High
+	for _, c := range candles {
+		lo = min(lo, c.Low)
+		hi = max(hi, c.High)
+	}
+	for _, m := range markers {
+		lo = min(lo, m.Price)
+		hi = max(hi, m.Price)
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+
+	row := func(price int64) int {
+		return int(int64(height-1) * (hi - price) / (hi - lo))
+	}
+
+	grid := make([][]rune, height)
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat(" ", len(candles)))
+	}
+
+	for _, m := range markers {
+		r := row(m.Price)
+		for x := range grid[r] {
+			grid[r][x] = '─'
+		}
+	}
+
+	for x, c := range candles {
+		for r := row(c.High); r <= row(c.Low); r++ {
+			grid[r][x] = '│'
+		}
+		body := '█'
+		if c.Close < c.Open {
+			body = '▒'
+		}
+		top, bottom := row(max(c.Open, c.Close)), row(min(c.Open, c.Close))
+		for r := top; r <= bottom; r++ {
+			grid[r][x] = body
+		}
+	}
+
+	labels := make(map[int]string, len(markers))
+	for _, m := range markers {
+		labels[row(m.Price)] = m.Label
+	}
+
+	lines := make([]string, height)
+	for r := range grid {
+		price := hi - (hi-lo)*int64(r)/int64(height-1)
+		line := fmt.Sprintf("%s ┤ %.3f", string(grid[r]), float64(price)/1_000_000)
+		if l, ok := labels[r]; ok {
+			line += " " + l
+		}
+		lines[r] = line
+	}
+	return lines
+}
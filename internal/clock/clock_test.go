@@ -0,0 +1,47 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeAdvanceFiresDueTimers(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+	short := f.After(time.Second)
+	long := f.After(time.Minute)
+
+	f.Advance(30 * time.Second)
+	select {
+	case got := <-short:
+		if !got.Equal(start.Add(30 * time.Second)) {
+			t.Errorf("fired at %v", got)
+		}
+	default:
+		t.Fatal("short timer should have fired")
+	}
+	select {
+	case <-long:
+		t.Fatal("long timer fired early")
+	default:
+	}
+	if f.Waiters() != 1 {
+		t.Errorf("waiters = %d, want 1", f.Waiters())
+	}
+
+	f.Set(start.Add(time.Hour))
+	select {
+	case <-long:
+	default:
+		t.Fatal("long timer should have fired")
+	}
+}
+
+func TestFakeAfterNonPositiveFiresImmediately(t *testing.T) {
+	f := NewFake(time.Unix(0, 0))
+	select {
+	case <-f.After(0):
+	default:
+		t.Fatal("expected immediate fire")
+	}
+}
@@ -0,0 +1,385 @@
+package config
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// BundleVersion is the config bundle format this build understands.
+const BundleVersion = 1
+
+var (
+	ErrBundleSignature   = errors.New("config bundle signature is invalid")
+	ErrBundleRequired    = errors.New("a config bundle is required in a managed deployment")
+	ErrBundleSetting     = errors.New("setting cannot be managed by a config bundle")
+	ErrBundleKeyAccess   = errors.New("bundle key must belong to root and be writable by no one else")
+	ErrBundleStateAccess = errors.New("bundle state must belong to root and be writable by no one else")
+	ErrBundleRollback    = errors.New("config bundle is older than one already accepted")
+)
+
+// BundleKey is the base64 Ed25519 public key config bundles are signed
+// with, when compiled in:
+//
+//	go build -ldflags "-X github.com/caesar-terminal/caesar/internal/config.BundleKey=<key>"
+//
+// Empty, the key is read from BundleKeyPath instead. Neither can be set
+// from the environment or a config file, which are what a bundle guards
+// against.
+var BundleKey string
+
+// BundleKeyPath is where the bundle key is installed when none is
+// compiled in. It and its directory must belong to root and be writable by
+// no one else, so the account the signer runs as can neither swap the key
+// nor remove it. While a key is present the deployment is managed.
+var BundleKeyPath = "/etc/caesar/bundle.pub"
+
+// BundleStatePath records the newest bundle root has accepted with
+// AcceptBundle. Once it exists the signer refuses to start without a
+// bundle, even if the key is gone, and refuses any bundle issued before
+// it, so an older bundle with looser limits cannot be put back. Like the
+// key, it and its directory must belong to root and be writable by no one
+// else, or the signer's account could delete or rewind it.
+var BundleStatePath = "/etc/caesar/bundle.state"
+
+// bundleOwner is the UID the bundle key and state must belong to. Tests
+// run as whoever they are.
+var bundleOwner uint32
+
+// BundleSettings are the signer settings a config bundle manages, by
+// their key under signer. They are the risk controls: when a bundle key
+// is configured, each is taken from the bundle or, if the bundle leaves it
+// out, from the profile's default; CAESAR_ variables for them are ignored.
+var BundleSettings = []string{
+	"session_ttl_sec",
+	"session_idle_sec",
+	"sessions",
+	"max_order_value",
+	"value_windows",
+	"max_orders_per_session",
+	"max_signs_per_minute",
+	"limit_profiles",
+	"canary_tokens",
+	"client_max_orders",
+	"client_max_notional",
+	"client_tokens",
+	"prod_addresses",
+	"pinned_addresses",
+	"allow_permits",
+	"allow_release",
+	"quiet_hours",
+	"anomaly_escalate",
+	"elevation_hash",
+	"elevation_max_min",
+	"salt_strategy",
+	"signature_v",
+}
+
+// Bundle is a signed set of risk settings and policy for managed
+// deployments, written as YAML:
+//
+//	version: 1
+//	issued_at: 2026-10-01T09:00:00Z
+//	settings:
+//	  max_order_value: "250000000"
+//	  value_windows: "1h=500000000;24h=2000000000"
+//	policy:
+//	  version: 1
+//	  rules:
+//	    - {id: max-notional, type: limit, field: order.value, max: 500}
+//	markets:
+//	  "0xabc": {category: politics}
+//	typed_data:
+//	  schemas:
+//	    - {name: clob-auth, type: "ClobAuth(...)", domain: {name: ClobAuthDomain, version: "1", chain_id: 137}}
+//
+// The policy, markets and typed data schemas take the place of the
+// policy checks, policy markets and typed data schemas files, which a
+// managed signer ignores; left out, there are none.
+//
+// The signature is a detached Ed25519 signature over the file's exact
+// bytes, base64-encoded in FILE.sig, made offline with the admin key (see
+// SignBundle). Someone able to edit files on the host can therefore
+// neither loosen a setting nor drop a rule without it being refused.
+type Bundle struct {
+	Version   int                       `yaml:"version"`
+	IssuedAt  time.Time                 `yaml:"issued_at"`
+	Settings  map[string]string         `yaml:"settings"`
+	Policy    yaml.Node                 `yaml:"policy"`
+	Markets   map[string]map[string]any `yaml:"markets"`
+	TypedData yaml.Node                 `yaml:"typed_data"`
+}
+
+// PolicyYAML returns the bundle's policy re-encoded as YAML, or nil when
+// it has none.
+func (b *Bundle) PolicyYAML() ([]byte, error) {
+	if b.Policy.IsZero() {
+		return nil, nil
+	}
+	return yaml.Marshal(&b.Policy)
+}
+
+// TypedDataYAML returns the bundle's typed data schema list re-encoded as
+// YAML, or nil when it has none.
+func (b *Bundle) TypedDataYAML() ([]byte, error) {
+	if b.TypedData.IsZero() {
+		return nil, nil
+	}
+	return yaml.Marshal(&b.TypedData)
+}
+
+// ParseBundleKey decodes a base64 Ed25519 public key.
+func ParseBundleKey(s string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid bundle key: want a base64 %d-byte Ed25519 public key", ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// SignBundle returns the contents of the .sig file for bundle data.
+func SignBundle(data []byte, key ed25519.PrivateKey) []byte {
+	return []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)) + "\n")
+}
+
+// LoadBundle reads the bundle at path and its signature at path.sig, and
+// parses it only once the signature verifies under key.
+func LoadBundle(path string, key ed25519.PublicKey) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config bundle: %w", err)
+	}
+	encoded, err := os.ReadFile(path + ".sig")
+	if err != nil {
+		return nil, fmt.Errorf("read config bundle signature: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil || !ed25519.Verify(key, data, sig) {
+		return nil, fmt.Errorf("%s: %w", path, ErrBundleSignature)
+	}
+	return ParseBundle(data)
+}
+
+// ParseBundle decodes and checks a bundle without verifying it.
+func ParseBundle(data []byte) (*Bundle, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var b Bundle
+	if err := dec.Decode(&b); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decode config bundle: %w", err)
+	}
+	if b.Version != BundleVersion {
+		return nil, fmt.Errorf("unsupported config bundle version %d (want %d)", b.Version, BundleVersion)
+	}
+	if b.IssuedAt.IsZero() {
+		return nil, errors.New("config bundle has no issued_at")
+	}
+	var unknown []string
+	for k := range b.Settings {
+		if !isBundleSetting(k) {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%w: %s", ErrBundleSetting, strings.Join(unknown, ", "))
+	}
+	return &b, nil
+}
+
+func isBundleSetting(key string) bool {
+	for _, k := range BundleSettings {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// bundleKey returns the key bundles must be signed with, or nil when the
+// deployment is not managed.
+func bundleKey() (ed25519.PublicKey, error) {
+	if BundleKey != "" {
+		return ParseBundleKey(BundleKey)
+	}
+	if _, err := os.Stat(BundleKeyPath); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err := rootOnly(BundleKeyPath, filepath.Dir(BundleKeyPath)); err != nil {
+		if errors.Is(err, errNotRootOnly) {
+			return nil, fmt.Errorf("%s: %w", BundleKeyPath, ErrBundleKeyAccess)
+		}
+		return nil, fmt.Errorf("bundle key: %w", err)
+	}
+	data, err := os.ReadFile(BundleKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("bundle key: %w", err)
+	}
+	return ParseBundleKey(string(data))
+}
+
+var errNotRootOnly = errors.New("not root only")
+
+// rootOnly returns errNotRootOnly unless every path belongs to
+// bundleOwner and is writable by no one else. Empty paths are skipped.
+func rootOnly(paths ...string) error {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if uid, ok := fileOwner(fi); (ok && uid != bundleOwner) || fi.Mode().Perm()&0o022 != 0 {
+			return errNotRootOnly
+		}
+	}
+	return nil
+}
+
+// bundleState is what BundleStatePath holds.
+type bundleState struct {
+	IssuedAt time.Time `json:"issued_at"`
+}
+
+// readBundleState returns the recorded state, or nil if none was
+// recorded. The state and its directory must be root's alone; while the
+// deployment is managed the directory must be even without a state, so
+// the signer's account cannot have removed one.
+func readBundleState(managed bool) (*bundleState, error) {
+	path := BundleStatePath
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		if !managed {
+			return nil, nil
+		}
+		path = ""
+	}
+	if err := rootOnly(path, filepath.Dir(BundleStatePath)); errors.Is(err, errNotRootOnly) {
+		return nil, fmt.Errorf("%s: %w", BundleStatePath, ErrBundleStateAccess)
+	} else if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("bundle state: %w", err)
+	}
+	data, err := os.ReadFile(BundleStatePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read bundle state: %w", err)
+	}
+	var st bundleState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parse bundle state %s: %w", BundleStatePath, err)
+	}
+	return &st, nil
+}
+
+// AcceptBundle verifies the bundle at path against the bundle key and
+// records it at BundleStatePath, which keeps the deployment managed from
+// then on and bundles issued before it out. Only root can write the
+// state: caesarctl config bundle-accept calls it when a bundle is
+// installed, and the signer only reads it.
+func AcceptBundle(path string) (*Bundle, error) {
+	key, err := bundleKey()
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("no bundle key at %s", BundleKeyPath)
+	}
+	b, err := LoadBundle(path, key)
+	if err != nil {
+		return nil, err
+	}
+	return b, RecordBundle(b)
+}
+
+// RecordBundle notes b at BundleStatePath as accepted, unless a bundle
+// issued later already is. It refuses a bundle issued before that one.
+func RecordBundle(b *Bundle) error {
+	st, err := readBundleState(true)
+	if err != nil {
+		return err
+	}
+	if st != nil && b.IssuedAt.Before(st.IssuedAt) {
+		return fmt.Errorf("bundle issued %s: %w (issued %s)", b.IssuedAt.Format(time.RFC3339), ErrBundleRollback, st.IssuedAt.Format(time.RFC3339))
+	}
+	if st != nil && !b.IssuedAt.After(st.IssuedAt) {
+		return nil
+	}
+	data, err := json.Marshal(bundleState{IssuedAt: b.IssuedAt})
+	if err != nil {
+		return err
+	}
+	tmp := BundleStatePath + ".tmp"
+	// Readable by the signer's account, which checks bundles against it.
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("record bundle state: %w", err)
+	}
+	if err := os.Rename(tmp, BundleStatePath); err != nil {
+		return fmt.Errorf("record bundle state: %w", err)
+	}
+	return nil
+}
+
+// applyBundle loads the bundle configured in v, if the deployment is
+// managed, and overrides every managed setting in v with the bundle's
+// value or the default in defaults.
+func applyBundle(v, defaults *viper.Viper) (*Bundle, error) {
+	key, err := bundleKey()
+	if err != nil {
+		return nil, err
+	}
+	st, err := readBundleState(key != nil)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		// Removing the key must not quietly hand the risk settings back
+		// to the environment.
+		if st != nil {
+			return nil, fmt.Errorf("%w: %s records a managed deployment, but there is no bundle key at %s", ErrBundleRequired, BundleStatePath, BundleKeyPath)
+		}
+		return nil, nil
+	}
+	path := v.GetString("signer.bundle_path")
+	if path == "" {
+		return nil, ErrBundleRequired
+	}
+	b, err := LoadBundle(path, key)
+	if err != nil {
+		return nil, err
+	}
+	if st != nil && b.IssuedAt.Before(st.IssuedAt) {
+		return nil, fmt.Errorf("%s issued %s: %w (issued %s)", path, b.IssuedAt.Format(time.RFC3339), ErrBundleRollback, st.IssuedAt.Format(time.RFC3339))
+	}
+	for _, k := range BundleSettings {
+		if value, ok := b.Settings[k]; ok {
+			v.Set("signer."+k, value)
+			continue
+		}
+		// Setting nil would not mask the environment, so an unset
+		// default becomes the empty string.
+		value := defaults.Get("signer." + k)
+		if value == nil {
+			value = ""
+		}
+		v.Set("signer."+k, value)
+	}
+	return b, nil
+}
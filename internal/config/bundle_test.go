@@ -0,0 +1,255 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const testBundle = `version: 1
+issued_at: 2026-10-01T09:00:00Z
+settings:
+  max_order_value: "250000000"
+policy:
+  version: 1
+  rules:
+    - {id: max-notional, type: limit, field: order.value, max: 500}
+markets:
+  "0xabc": {category: politics}
+typed_data:
+  schemas:
+    - {name: hello, type: "Hello(string text)", domain: {name: Example, version: "1", chain_id: 137}}
+`
+
+// managed makes the deployment managed under a new key, with its state in
+// a temporary directory, and returns the private key.
+func managed(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setBundleVars(t, base64.StdEncoding.EncodeToString(pub), filepath.Join(t.TempDir(), "bundle.pub"))
+	return priv
+}
+
+func setBundleVars(t *testing.T, key, keyPath string) {
+	t.Helper()
+	oldKey, oldPath, oldState, oldOwner := BundleKey, BundleKeyPath, BundleStatePath, bundleOwner
+	t.Cleanup(func() { BundleKey, BundleKeyPath, BundleStatePath, bundleOwner = oldKey, oldPath, oldState, oldOwner })
+	BundleKey, BundleKeyPath, BundleStatePath = key, keyPath, filepath.Join(t.TempDir(), "bundle.state")
+	bundleOwner = uint32(os.Getuid())
+}
+
+// writeBundle signs data with a new managed key and points the
+// environment at it.
+func writeBundle(t *testing.T, data string) string {
+	t.Helper()
+	priv := managed(t)
+	path := filepath.Join(t.TempDir(), "bundle.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".sig", SignBundle([]byte(data), priv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CAESAR_SIGNER_BUNDLE_PATH", path)
+	return path
+}
+
+func TestLoadBundleOverridesEnv(t *testing.T) {
+	writeBundle(t, testBundle)
+	t.Setenv("CAESAR_SIGNER_MAX_ORDER_VALUE", "999999999999")
+	t.Setenv("CAESAR_SIGNER_SESSION_TTL_SEC", "86400")
+	t.Setenv("CAESAR_SIGNER_ALLOW_PERMITS", "true")
+	t.Setenv("CAESAR_SIGNER_ELEVATION_HASH", "$2a$10$attackerpassphrasehash")
+	t.Setenv("CAESAR_SIGNER_SIGNATURE_V", "0")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Signer.MaxOrderValue != "250000000" {
+		t.Errorf("max order value %q, want the bundle's", cfg.Signer.MaxOrderValue)
+	}
+	// Settings the bundle leaves out keep their defaults, not the env.
+	if cfg.Signer.SessionTTLSec != 3600 || cfg.Signer.AllowPermits {
+		t.Errorf("ttl %d permits %v, want the defaults", cfg.Signer.SessionTTLSec, cfg.Signer.AllowPermits)
+	}
+	if cfg.Signer.ElevationHash != "" || cfg.Signer.SignatureV != 27 {
+		t.Errorf("elevation hash %q, v %d: want the defaults", cfg.Signer.ElevationHash, cfg.Signer.SignatureV)
+	}
+	if cfg.Signer.Bundle == nil || cfg.Signer.Bundle.IssuedAt.IsZero() {
+		t.Fatalf("bundle %+v", cfg.Signer.Bundle)
+	}
+	if data, err := cfg.Signer.Bundle.PolicyYAML(); err != nil || len(data) == 0 {
+		t.Errorf("policy %q: %v", data, err)
+	}
+	if cfg.Signer.Bundle.Markets["0xabc"]["category"] != "politics" {
+		t.Errorf("markets %v", cfg.Signer.Bundle.Markets)
+	}
+	if data, err := cfg.Signer.Bundle.TypedDataYAML(); err != nil || len(data) == 0 {
+		t.Errorf("typed data %q: %v", data, err)
+	}
+}
+
+func TestLoadBundleRejected(t *testing.T) {
+	path := writeBundle(t, testBundle)
+	if err := os.WriteFile(path, []byte(testBundle+"  value_windows: \"\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(); !errors.Is(err, ErrBundleSignature) {
+		t.Errorf("tampered bundle: expected ErrBundleSignature, got %v", err)
+	}
+
+	writeBundle(t, "version: 1\nissued_at: 2026-10-01T09:00:00Z\nsettings:\n  kms_key_id: other\n")
+	if _, err := Load(); !errors.Is(err, ErrBundleSetting) {
+		t.Errorf("unmanaged setting: expected ErrBundleSetting, got %v", err)
+	}
+
+	path = writeBundle(t, testBundle)
+	t.Setenv("CAESAR_SIGNER_BUNDLE_PATH", path+".missing")
+	if _, err := Load(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("no bundle: expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestBundleKeyFile(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, "bundle.pub")
+	setBundleVars(t, "", keyPath)
+	t.Setenv("CAESAR_SIGNER_BUNDLE_PATH", filepath.Join(dir, "missing.yaml"))
+
+	// No key: not managed.
+	if cfg, err := Load(); err != nil || cfg.Signer.Bundle != nil {
+		t.Fatalf("unmanaged: %+v, %v", cfg, err)
+	}
+	if err := os.WriteFile(keyPath, []byte(base64.StdEncoding.EncodeToString(pub)+"\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(keyPath, 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(); !errors.Is(err, ErrBundleKeyAccess) {
+		t.Errorf("writable key: expected ErrBundleKeyAccess, got %v", err)
+	}
+	if err := os.Chmod(keyPath, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(); errors.Is(err, ErrBundleKeyAccess) || err == nil {
+		t.Errorf("root-owned key with no bundle: expected a load error, got %v", err)
+	}
+	if runtime.GOOS != "linux" {
+		return // only the permission bits are checked
+	}
+	bundleOwner++
+	if _, err := Load(); !errors.Is(err, ErrBundleKeyAccess) {
+		t.Errorf("key owned by another account: expected ErrBundleKeyAccess, got %v", err)
+	}
+}
+
+func TestBundleStateAccess(t *testing.T) {
+	path := writeBundle(t, testBundle)
+	dir := filepath.Dir(BundleStatePath)
+	if err := os.Chmod(dir, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	// With no state yet, a directory the signer could write to would let
+	// it remove the state unnoticed.
+	if _, err := Load(); !errors.Is(err, ErrBundleStateAccess) {
+		t.Errorf("writable state directory: expected ErrBundleStateAccess, got %v", err)
+	}
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AcceptBundle(path); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if err := os.Chmod(BundleStatePath, 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(); !errors.Is(err, ErrBundleStateAccess) {
+		t.Errorf("writable state: expected ErrBundleStateAccess, got %v", err)
+	}
+	if err := os.Chmod(BundleStatePath, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "linux" {
+		return // only the permission bits are checked
+	}
+	bundleOwner++
+	if _, err := Load(); !errors.Is(err, ErrBundleStateAccess) {
+		t.Errorf("state owned by another account: expected ErrBundleStateAccess, got %v", err)
+	}
+}
+
+func TestManagedDeploymentStaysManaged(t *testing.T) {
+	writeBundle(t, testBundle)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordBundle(cfg.Signer.Bundle); err != nil {
+		t.Fatal(err)
+	}
+	// The key disappears; the recorded state still demands a bundle.
+	BundleKey = ""
+	if _, err := Load(); !errors.Is(err, ErrBundleRequired) {
+		t.Errorf("key removed: expected ErrBundleRequired, got %v", err)
+	}
+}
+
+func TestBundleRollback(t *testing.T) {
+	path := writeBundle(t, testBundle)
+	priv := managed(t)
+	sign := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path+".sig", SignBundle([]byte(data), priv), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newer := strings.Replace(testBundle, "2026-10-01T09:00:00Z", "2026-10-02T09:00:00Z", 1)
+	sign(newer)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordBundle(cfg.Signer.Bundle); err != nil {
+		t.Fatal(err)
+	}
+
+	// The older bundle is validly signed, but was superseded.
+	sign(testBundle)
+	if _, err := Load(); !errors.Is(err, ErrBundleRollback) {
+		t.Errorf("older bundle: expected ErrBundleRollback, got %v", err)
+	}
+	if _, err := AcceptBundle(path); !errors.Is(err, ErrBundleRollback) {
+		t.Errorf("accepting the older bundle: expected ErrBundleRollback, got %v", err)
+	}
+	sign(newer)
+	if _, err := Load(); err != nil {
+		t.Errorf("same bundle again: %v", err)
+	}
+
+	sign(strings.Replace(testBundle, "issued_at: 2026-10-01T09:00:00Z\n", "", 1))
+	if _, err := Load(); err == nil {
+		t.Error("a bundle without issued_at loaded")
+	}
+}
@@ -1,27 +1,187 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config holds all application configuration.
 type Config struct {
+	// Profile is the named environment the rest of the settings default
+	// from (see Profiles); empty uses the built-in defaults alone.
+	Profile string `mapstructure:"profile"`
+	// ChainID is the network orders are signed for.
+	ChainID            uint64 `mapstructure:"chain_id"`
 	Env                string `mapstructure:"env"`
 	LocalStackEndpoint string `mapstructure:"localstack_endpoint"`
-	Signer             SignerConfig
-	DB                 DBConfig
-	Redis              RedisConfig
+	// DisplayTimezone is the IANA zone used for rendering and exports.
+	// Timestamps are always stored and compared in UTC internally.
+	DisplayTimezone string `mapstructure:"display_timezone"`
+	// Locale selects the message catalog for user-facing output (e.g. "es").
+	Locale string `mapstructure:"locale"`
+	// HomeCurrency is the ISO 4217 code reports convert USD amounts into,
+	// at rates from FXSource ("ecb" or "coinbase") refreshed every
+	// FXRefreshSec seconds.
+	HomeCurrency string `mapstructure:"home_currency"`
+	FXSource     string `mapstructure:"fx_source"`
+	FXRefreshSec int    `mapstructure:"fx_refresh_sec"`
+	// MetricsAddr serves Prometheus feed metrics at /metrics; empty
+	// disables the endpoint.
+	MetricsAddr string `mapstructure:"metrics_addr"`
+	// ReportColumns is a file of computed columns appended to exports
+	// (see report.Columns); empty adds none.
+	ReportColumns string `mapstructure:"report_columns"`
+	// EgressAllow lists the hostnames, "*.domain" wildcards and CIDR
+	// ranges outbound connections may reach; EgressPins fixes hosts to
+	// addresses ("host=ip,ip;host2=ip"). Both empty leaves egress open.
+	EgressAllow string `mapstructure:"egress_allow"`
+	EgressPins  string `mapstructure:"egress_pins"`
+	// Proxy routes outbound connections through an http(s):// or
+	// socks5(h):// proxy; ProxyRoutes overrides it per connection class
+	// ("stream=socks5h://127.0.0.1:9050;alerts=direct").
+	Proxy       string `mapstructure:"proxy"`
+	ProxyRoutes string `mapstructure:"proxy_routes"`
+
+	Signer SignerConfig
+	CLOB   CLOBConfig
+	Kalshi KalshiConfig
+	Notify NotifyConfig
+	DB     DBConfig
+	Redis  RedisConfig
 }
 
 // SignerConfig holds signer-specific settings.
 type SignerConfig struct {
 	SocketPath    string `mapstructure:"socket_path"`
 	SessionTTLSec int    `mapstructure:"session_ttl_sec"`
-	KMSKeyID      string `mapstructure:"kms_key_id"`
-	AWSRegion     string `mapstructure:"aws_region"`
+	// Destroy a session after this long without an order signed,
+	// whatever is left of its TTL; 0 disables it.
+	SessionIdleSec int `mapstructure:"session_idle_sec"`
+	// Named sessions served alongside the default one, "id" or "id=ttl",
+	// comma-separated; each holds its own key and limits.
+	Sessions string `mapstructure:"sessions"`
+	// Write-ahead journal of every session's value ledger, so a crash
+	// cannot reset a limit; empty disables it.
+	LedgerJournal string `mapstructure:"ledger_journal"`
+	// Pause signing after an unclean shutdown until the recovery report
+	// has been acknowledged; needs the ledger journal to detect one.
+	RecoveryAckRequired bool `mapstructure:"recovery_ack_required"`
+	// Largest value any one order may carry (USDC atomic units); empty
+	// means no per-order cap.
+	MaxOrderValue string `mapstructure:"max_order_value"`
+	// Rolling value limits alongside the session total, "span=limit"
+	// semicolon-separated, e.g. "1h=500000000;24h=2000000000".
+	ValueWindows string `mapstructure:"value_windows"`
+	// Most orders a session may sign, and most a minute; zero means
+	// uncapped.
+	MaxOrdersPerSession int64  `mapstructure:"max_orders_per_session"`
+	MaxSignsPerMinute   int    `mapstructure:"max_signs_per_minute"`
+	KMSKeyID            string `mapstructure:"kms_key_id"`
+	AWSRegion           string `mapstructure:"aws_region"`
+	LimitProfiles       string `mapstructure:"limit_profiles"`
+	QuietHours          string `mapstructure:"quiet_hours"`
+	AnomalyEscalate     bool   `mapstructure:"anomaly_escalate"`
+	CanaryTokens        string `mapstructure:"canary_tokens"`
+	// Per-client caps within a session; 0 / empty means unlimited.
+	ClientMaxOrders   int64  `mapstructure:"client_max_orders"`
+	ClientMaxNotional string `mapstructure:"client_max_notional"`
+	// Client labels with their own quota, "label=sha256" comma-separated,
+	// each with the SHA-256 of the token its client must send; every
+	// other caller shares its UID's quota.
+	ClientTokens string `mapstructure:"client_tokens"`
+	// Pre-trade check expressions, re-read when the file changes.
+	PolicyChecks    string `mapstructure:"policy_checks"`
+	PolicyMarkets   string `mapstructure:"policy_markets"`
+	PolicyReloadSec int    `mapstructure:"policy_reload_sec"`
+	ApprovalTTLSec  int    `mapstructure:"approval_ttl_sec"`
+	// Base32 TOTP secret whose codes ImportSession and offline
+	// activation require as a second factor; empty disables it.
+	ActivationTOTP string `mapstructure:"activation_totp"`
+	// bcrypt hash of the ElevateSession passphrase; empty disables it.
+	ElevationHash   string `mapstructure:"elevation_hash"`
+	ElevationMaxMin int    `mapstructure:"elevation_max_min"`
+	// RFC 3161 timestamp authority anchoring the audit chain head; empty
+	// disables anchoring.
+	AuditTSAURL     string `mapstructure:"audit_tsa_url"`
+	AuditAnchorSec  int    `mapstructure:"audit_anchor_sec"`
+	AuditAnchorFile string `mapstructure:"audit_anchor_file"`
+	// Polygon JSON-RPC endpoint for EIP-1271 signature checks; empty
+	// refuses contract-wallet orders.
+	PolygonRPC string `mapstructure:"polygon_rpc"`
+	// EIP-712 schemas SignTypedData may sign; empty disables the RPC.
+	TypedDataSchemas string `mapstructure:"typed_data_schemas"`
+	// Comma-separated addresses of the production keys. They only
+	// activate under the prod profile, and under prod nothing else does.
+	ProdAddresses string `mapstructure:"prod_addresses"`
+	// Addresses the session keys must derive, "address" for the default
+	// session or "session=address", comma-separated; a key from any other
+	// keystore is refused at activation.
+	PinnedAddresses string `mapstructure:"pinned_addresses"`
+	// How salts are generated for orders submitted without one: random,
+	// time, or client (none; the order's own salt is used).
+	SaltStrategy string `mapstructure:"salt_strategy"`
+	// AllowPermits enables SignPermit (EIP-2612 permits and ERC-1155
+	// approvals for the exchange contracts).
+	AllowPermits bool `mapstructure:"allow_permits"`
+	// AllowRelease enables ReleaseValue, which credits a signed order's
+	// value back to the limit on the operator's word; it needs elevation.
+	AllowRelease bool `mapstructure:"allow_release"`
+	// SignatureV is the v of returned signatures for recovery ID 0: 27
+	// for the CTF Exchange, or 0 for tools that expect the bare ID.
+	SignatureV int `mapstructure:"signature_v"`
+	// BundlePath is the signed config bundle a managed deployment (see
+	// BundleKey) requires, which then alone supplies the risk settings
+	// (see BundleSettings) and policy.
+	BundlePath string `mapstructure:"bundle_path"`
+	// Bundle is the verified bundle in force, or nil.
+	Bundle *Bundle
+}
+
+// CLOBConfig tunes the outbound CLOB REST client.
+type CLOBConfig struct {
+	URL   string `mapstructure:"url"`
+	WSURL string `mapstructure:"ws_url"`
+	// In-flight request caps per endpoint class; 0 means unlimited.
+	MaxSubmits  int `mapstructure:"max_submits"`
+	MaxCancels  int `mapstructure:"max_cancels"`
+	MaxMetadata int `mapstructure:"max_metadata"`
+	// Connection pool tuning; see adapter.TransportConfig.
+	MaxIdleConnsPerHost int `mapstructure:"max_idle_conns_per_host"`
+	IdleTimeoutSec      int `mapstructure:"idle_timeout_sec"`
+	KeepAliveSec        int `mapstructure:"keepalive_sec"`
+	TLSSessionCache     int `mapstructure:"tls_session_cache"`
+}
+
+// KalshiConfig holds Kalshi API endpoints and credentials.
+type KalshiConfig struct {
+	APIURL    string `mapstructure:"api_url"`
+	WSURL     string `mapstructure:"ws_url"`
+	APIKey    string `mapstructure:"api_key"`
+	APISecret string `mapstructure:"api_secret"`
+}
+
+// NotifyConfig holds outbound notification channels and routing. A
+// channel is enabled when its credentials are set; Routes decides which
+// event kinds reach it.
+type NotifyConfig struct {
+	Routes              string `mapstructure:"routes"`
+	WebhookURL          string `mapstructure:"webhook_url"`
+	TelegramToken       string `mapstructure:"telegram_token"`
+	TelegramChatID      string `mapstructure:"telegram_chat_id"`
+	SMTPAddr            string `mapstructure:"smtp_addr"`
+	SMTPUser            string `mapstructure:"smtp_user"`
+	SMTPPassword        string `mapstructure:"smtp_password"`
+	SMTPFrom            string `mapstructure:"smtp_from"`
+	SMTPTo              string `mapstructure:"smtp_to"`
+	PagerDutyRoutingKey string `mapstructure:"pagerduty_routing_key"`
+	WebPushVAPIDKey     string `mapstructure:"webpush_vapid_key"`
+	WebPushSubject      string `mapstructure:"webpush_subject"`
+	WebPushSubs         string `mapstructure:"webpush_subscriptions"`
 }
 
 // DBConfig holds PostgreSQL connection settings.
@@ -47,6 +207,86 @@ type RedisConfig struct {
 	DB       int    `mapstructure:"db"`
 }
 
+// DisplayLocation returns the configured display time zone. Load has
+// already validated it, so an error here falls back to UTC.
+func (c *Config) DisplayLocation() *time.Location {
+	loc, err := time.LoadLocation(c.DisplayTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Profile names.
+const (
+	ProfileProd    = "prod"
+	ProfileTestnet = "testnet"
+	ProfilePaper   = "paper"
+)
+
+// Profiles bundle the network, endpoints, limits and storage paths of each
+// environment. They replace the built-in defaults; CAESAR_ variables still
+// override them.
+var Profiles = map[string]map[string]any{
+	ProfileProd: {
+		"env":                      "production",
+		"chain_id":                 137,
+		"clob.url":                 "https://clob.polymarket.com",
+		"signer.polygon_rpc":       "https://polygon-rpc.com",
+		"signer.session_ttl_sec":   3600,
+		"signer.socket_path":       "/var/run/caesar/signer.sock",
+		"signer.audit_anchor_file": "/var/lib/caesar/audit-anchors.jsonl",
+		"db.dbname":                "caesar",
+	},
+	ProfileTestnet: {
+		"env":                      "testnet",
+		"chain_id":                 80002,
+		"clob.url":                 "",
+		"clob.ws_url":              "",
+		"signer.polygon_rpc":       "https://rpc-amoy.polygon.technology",
+		"signer.session_ttl_sec":   28800,
+		"signer.socket_path":       "/var/run/caesar/testnet/signer.sock",
+		"signer.audit_anchor_file": "/var/lib/caesar/testnet/audit-anchors.jsonl",
+		"db.dbname":                "caesar_testnet",
+	},
+	// Paper trading reads mainnet markets but never submits, so its
+	// client caps keep a stray session from signing much.
+	ProfilePaper: {
+		"env":                        "paper",
+		"chain_id":                   137,
+		"clob.url":                   "https://clob.polymarket.com",
+		"signer.session_ttl_sec":     28800,
+		"signer.client_max_notional": "1000000000",
+		"signer.socket_path":         "/var/run/caesar/paper/signer.sock",
+		"signer.audit_anchor_file":   "/var/lib/caesar/paper/audit-anchors.jsonl",
+		"db.dbname":                  "caesar_paper",
+	},
+}
+
+// ApplyProfileFlag strips a leading --profile NAME (or -profile,
+// --profile=NAME) from args and exports it as CAESAR_PROFILE, so every
+// later Load in the process uses it. It returns the remaining args.
+func ApplyProfileFlag(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return args, nil
+	}
+	name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+	if !strings.HasPrefix(args[0], "-") || name != "profile" {
+		return args, nil
+	}
+	rest := args[1:]
+	if !hasValue {
+		if len(rest) == 0 {
+			return nil, errors.New("--profile needs a value")
+		}
+		value, rest = rest[0], rest[1:]
+	}
+	if _, ok := Profiles[value]; !ok {
+		return nil, fmt.Errorf("unknown profile %q", value)
+	}
+	return rest, os.Setenv("CAESAR_PROFILE", value)
+}
+
 // Load reads configuration from environment variables prefixed with CAESAR_.
 func Load() (*Config, error) {
 	v := viper.New()
@@ -54,13 +294,178 @@ func Load() (*Config, error) {
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
+	profile := v.GetString("profile")
+	if err := setDefaults(v, profile); err != nil {
+		return nil, err
+	}
+	// Bundle-managed settings fall back to the defaults alone, never to
+	// the environment.
+	defaults := viper.New()
+	if err := setDefaults(defaults, profile); err != nil {
+		return nil, err
+	}
+	bundle, err := applyBundle(v, defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{Profile: profile}
+	cfg.ChainID = v.GetUint64("chain_id")
+
+	cfg.Env = v.GetString("env")
+	cfg.LocalStackEndpoint = v.GetString("localstack_endpoint")
+	cfg.DisplayTimezone = v.GetString("display_timezone")
+	cfg.Locale = v.GetString("locale")
+	cfg.HomeCurrency = strings.ToUpper(v.GetString("home_currency"))
+	cfg.FXSource = v.GetString("fx_source")
+	cfg.FXRefreshSec = v.GetInt("fx_refresh_sec")
+	cfg.MetricsAddr = v.GetString("metrics_addr")
+	cfg.ReportColumns = v.GetString("report_columns")
+	cfg.EgressAllow = v.GetString("egress_allow")
+	cfg.EgressPins = v.GetString("egress_pins")
+	cfg.Proxy = v.GetString("proxy")
+	cfg.ProxyRoutes = v.GetString("proxy_routes")
+	if _, err := time.LoadLocation(cfg.DisplayTimezone); err != nil {
+		return nil, fmt.Errorf("invalid display_timezone %q: %w", cfg.DisplayTimezone, err)
+	}
+
+	cfg.Signer = SignerConfig{
+		SocketPath:          v.GetString("signer.socket_path"),
+		SessionTTLSec:       v.GetInt("signer.session_ttl_sec"),
+		SessionIdleSec:      v.GetInt("signer.session_idle_sec"),
+		Sessions:            v.GetString("signer.sessions"),
+		LedgerJournal:       v.GetString("signer.ledger_journal"),
+		RecoveryAckRequired: v.GetBool("signer.recovery_ack_required"),
+		MaxOrderValue:       v.GetString("signer.max_order_value"),
+		ValueWindows:        v.GetString("signer.value_windows"),
+		MaxOrdersPerSession: v.GetInt64("signer.max_orders_per_session"),
+		MaxSignsPerMinute:   v.GetInt("signer.max_signs_per_minute"),
+		KMSKeyID:            v.GetString("signer.kms_key_id"),
+		AWSRegion:           v.GetString("signer.aws_region"),
+		LimitProfiles:       v.GetString("signer.limit_profiles"),
+		QuietHours:          v.GetString("signer.quiet_hours"),
+		AnomalyEscalate:     v.GetBool("signer.anomaly_escalate"),
+		CanaryTokens:        v.GetString("signer.canary_tokens"),
+		ClientMaxOrders:     v.GetInt64("signer.client_max_orders"),
+		ClientMaxNotional:   v.GetString("signer.client_max_notional"),
+		ClientTokens:        v.GetString("signer.client_tokens"),
+		PolicyChecks:        v.GetString("signer.policy_checks"),
+		PolicyMarkets:       v.GetString("signer.policy_markets"),
+		PolicyReloadSec:     v.GetInt("signer.policy_reload_sec"),
+		ApprovalTTLSec:      v.GetInt("signer.approval_ttl_sec"),
+		ActivationTOTP:      v.GetString("signer.activation_totp"),
+		ElevationHash:       v.GetString("signer.elevation_hash"),
+		ElevationMaxMin:     v.GetInt("signer.elevation_max_min"),
+		AuditTSAURL:         v.GetString("signer.audit_tsa_url"),
+		AuditAnchorSec:      v.GetInt("signer.audit_anchor_sec"),
+		AuditAnchorFile:     v.GetString("signer.audit_anchor_file"),
+		PolygonRPC:          v.GetString("signer.polygon_rpc"),
+		TypedDataSchemas:    v.GetString("signer.typed_data_schemas"),
+		ProdAddresses:       v.GetString("signer.prod_addresses"),
+		PinnedAddresses:     v.GetString("signer.pinned_addresses"),
+		SaltStrategy:        v.GetString("signer.salt_strategy"),
+		AllowPermits:        v.GetBool("signer.allow_permits"),
+		AllowRelease:        v.GetBool("signer.allow_release"),
+		SignatureV:          v.GetInt("signer.signature_v"),
+		BundlePath:          v.GetString("signer.bundle_path"),
+		Bundle:              bundle,
+	}
+	if cfg.Signer.SignatureV != 27 && cfg.Signer.SignatureV != 0 {
+		return nil, fmt.Errorf("invalid signer.signature_v %d: want 27 or 0", cfg.Signer.SignatureV)
+	}
+	if cfg.Signer.AllowRelease && cfg.Signer.ElevationHash == "" {
+		return nil, errors.New("signer.allow_release needs signer.elevation_hash: every release re-authenticates")
+	}
+
+	cfg.CLOB = CLOBConfig{
+		URL:         v.GetString("clob.url"),
+		WSURL:       v.GetString("clob.ws_url"),
+		MaxSubmits:  v.GetInt("clob.max_submits"),
+		MaxCancels:  v.GetInt("clob.max_cancels"),
+		MaxMetadata: v.GetInt("clob.max_metadata"),
+
+		MaxIdleConnsPerHost: v.GetInt("clob.max_idle_conns_per_host"),
+		IdleTimeoutSec:      v.GetInt("clob.idle_timeout_sec"),
+		KeepAliveSec:        v.GetInt("clob.keepalive_sec"),
+		TLSSessionCache:     v.GetInt("clob.tls_session_cache"),
+	}
+
+	cfg.Kalshi = KalshiConfig{
+		APIURL:    v.GetString("kalshi.api_url"),
+		WSURL:     v.GetString("kalshi.ws_url"),
+		APIKey:    v.GetString("kalshi.api_key"),
+		APISecret: v.GetString("kalshi.api_secret"),
+	}
+
+	cfg.Notify = NotifyConfig{
+		Routes:              v.GetString("notify.routes"),
+		WebhookURL:          v.GetString("notify.webhook_url"),
+		TelegramToken:       v.GetString("notify.telegram_token"),
+		TelegramChatID:      v.GetString("notify.telegram_chat_id"),
+		SMTPAddr:            v.GetString("notify.smtp_addr"),
+		SMTPUser:            v.GetString("notify.smtp_user"),
+		SMTPPassword:        v.GetString("notify.smtp_password"),
+		SMTPFrom:            v.GetString("notify.smtp_from"),
+		SMTPTo:              v.GetString("notify.smtp_to"),
+		PagerDutyRoutingKey: v.GetString("notify.pagerduty_routing_key"),
+		WebPushVAPIDKey:     v.GetString("notify.webpush_vapid_key"),
+		WebPushSubject:      v.GetString("notify.webpush_subject"),
+		WebPushSubs:         v.GetString("notify.webpush_subscriptions"),
+	}
+
+	cfg.DB = DBConfig{
+		Host:     v.GetString("db.host"),
+		Port:     v.GetInt("db.port"),
+		User:     v.GetString("db.user"),
+		Password: v.GetString("db.password"),
+		DBName:   v.GetString("db.dbname"),
+		SSLMode:  v.GetString("db.sslmode"),
+	}
+
+	cfg.Redis = RedisConfig{
+		Addr:     v.GetString("redis.addr"),
+		Password: v.GetString("redis.password"),
+		DB:       v.GetInt("redis.db"),
+	}
+
+	if err := cfg.checkProfile(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// setDefaults sets the built-in defaults and those of profile on v.
+func setDefaults(v *viper.Viper, profile string) error {
 	// Defaults
 	v.SetDefault("env", "development")
+	v.SetDefault("display_timezone", "UTC")
+	v.SetDefault("locale", "en")
+	v.SetDefault("home_currency", "USD")
+	v.SetDefault("fx_source", "ecb")
+	v.SetDefault("fx_refresh_sec", 3600)
 
 	// Signer defaults
 	v.SetDefault("signer.socket_path", "/var/run/caesar/signer.sock")
 	v.SetDefault("signer.session_ttl_sec", 3600)
 	v.SetDefault("signer.aws_region", "us-east-1")
+	v.SetDefault("signer.policy_reload_sec", 5)
+	v.SetDefault("signer.approval_ttl_sec", 300)
+	v.SetDefault("signer.elevation_max_min", 15)
+	v.SetDefault("signer.audit_anchor_sec", 3600)
+	v.SetDefault("signer.audit_anchor_file", "/var/lib/caesar/audit-anchors.jsonl")
+	v.SetDefault("signer.salt_strategy", "random")
+	v.SetDefault("signer.signature_v", 27)
+	v.SetDefault("signer.recovery_ack_required", true)
+	v.SetDefault("signer.bundle_path", "/etc/caesar/bundle.yaml")
+
+	// CLOB defaults
+	v.SetDefault("clob.max_submits", 4)
+	v.SetDefault("clob.max_cancels", 8)
+	v.SetDefault("clob.max_metadata", 16)
+	v.SetDefault("clob.max_idle_conns_per_host", 16)
+	v.SetDefault("clob.idle_timeout_sec", 90)
+	v.SetDefault("clob.keepalive_sec", 30)
+	v.SetDefault("clob.tls_session_cache", 64)
 
 	// DB defaults
 	v.SetDefault("db.host", "localhost")
@@ -75,32 +480,35 @@ func Load() (*Config, error) {
 	v.SetDefault("redis.password", "")
 	v.SetDefault("redis.db", 0)
 
-	cfg := &Config{}
+	v.SetDefault("chain_id", 137)
+	v.SetDefault("clob.url", "https://clob.polymarket.com")
+	v.SetDefault("clob.ws_url", "wss://ws-subscriptions-clob.polymarket.com/ws/market")
+	v.SetDefault("kalshi.api_url", "https://trading-api.kalshi.com/trade-api/v2")
+	v.SetDefault("kalshi.ws_url", "wss://trading-api.kalshi.com/trade-api/ws/v2")
 
-	cfg.Env = v.GetString("env")
-	cfg.LocalStackEndpoint = v.GetString("localstack_endpoint")
-
-	cfg.Signer = SignerConfig{
-		SocketPath:    v.GetString("signer.socket_path"),
-		SessionTTLSec: v.GetInt("signer.session_ttl_sec"),
-		KMSKeyID:      v.GetString("signer.kms_key_id"),
-		AWSRegion:     v.GetString("signer.aws_region"),
-	}
-
-	cfg.DB = DBConfig{
-		Host:     v.GetString("db.host"),
-		Port:     v.GetInt("db.port"),
-		User:     v.GetString("db.user"),
-		Password: v.GetString("db.password"),
-		DBName:   v.GetString("db.dbname"),
-		SSLMode:  v.GetString("db.sslmode"),
+	if profile != "" {
+		bundle, ok := Profiles[profile]
+		if !ok {
+			return fmt.Errorf("unknown profile %q", profile)
+		}
+		for key, value := range bundle {
+			v.SetDefault(key, value)
+		}
 	}
+	return nil
+}
 
-	cfg.Redis = RedisConfig{
-		Addr:     v.GetString("redis.addr"),
-		Password: v.GetString("redis.password"),
-		DB:       v.GetInt("redis.db"),
+// checkProfile refuses settings that would point a non-prod profile at
+// production: the mainnet chain for testnet, or the production CLOB.
+func (c *Config) checkProfile() error {
+	switch c.Profile {
+	case ProfileTestnet:
+		if c.ChainID == 137 {
+			return errors.New("testnet profile cannot sign for chain 137")
+		}
+		if c.CLOB.URL == "" || c.CLOB.URL == Profiles[ProfileProd]["clob.url"] {
+			return errors.New("testnet profile needs CAESAR_CLOB_URL set to a non-production CLOB")
+		}
 	}
-
-	return cfg, nil
+	return nil
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoadDefaults(t *testing.T) {
@@ -19,6 +20,10 @@ func TestLoadDefaults(t *testing.T) {
 		t.Errorf("unexpected socket path: %s", cfg.Signer.SocketPath)
 	}
 
+	if cfg.CLOB.MaxSubmits != 4 {
+		t.Errorf("expected 4 concurrent submits, got %d", cfg.CLOB.MaxSubmits)
+	}
+
 	if cfg.DB.Port != 5432 {
 		t.Errorf("expected db port 5432, got %d", cfg.DB.Port)
 	}
@@ -26,6 +31,32 @@ func TestLoadDefaults(t *testing.T) {
 	if cfg.Redis.Addr != "localhost:6379" {
 		t.Errorf("expected redis addr localhost:6379, got %s", cfg.Redis.Addr)
 	}
+
+	if cfg.DisplayLocation() != time.UTC {
+		t.Errorf("expected UTC display location, got %s", cfg.DisplayLocation())
+	}
+
+	if cfg.Signer.SignatureV != 27 {
+		t.Errorf("expected signature v 27, got %d", cfg.Signer.SignatureV)
+	}
+}
+
+func TestLoadInvalidTimezone(t *testing.T) {
+	os.Setenv("CAESAR_DISPLAY_TIMEZONE", "Mars/Olympus_Mons")
+	defer os.Unsetenv("CAESAR_DISPLAY_TIMEZONE")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for invalid display timezone")
+	}
+}
+
+func TestLoadInvalidSignatureV(t *testing.T) {
+	os.Setenv("CAESAR_SIGNER_SIGNATURE_V", "1")
+	defer os.Unsetenv("CAESAR_SIGNER_SIGNATURE_V")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for signature v 1")
+	}
 }
 
 func TestLoadFromEnv(t *testing.T) {
@@ -63,3 +94,79 @@ func TestDBDSN(t *testing.T) {
 		t.Errorf("unexpected DSN:\ngot:  %s\nwant: %s", cfg.DSN(), expected)
 	}
 }
+
+func TestLoadProfile(t *testing.T) {
+	t.Setenv("CAESAR_PROFILE", ProfilePaper)
+	t.Setenv("CAESAR_DB_DBNAME", "override")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Profile != ProfilePaper || cfg.Env != "paper" || cfg.ChainID != 137 {
+		t.Errorf("profile = %s env = %s chain = %d", cfg.Profile, cfg.Env, cfg.ChainID)
+	}
+	if cfg.Signer.SocketPath != "/var/run/caesar/paper/signer.sock" {
+		t.Errorf("unexpected socket path: %s", cfg.Signer.SocketPath)
+	}
+	if cfg.DB.DBName != "override" {
+		t.Errorf("environment should override the profile, got dbname %s", cfg.DB.DBName)
+	}
+
+	t.Setenv("CAESAR_PROFILE", "staging")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+}
+
+func TestLoadTestnetRefusesProduction(t *testing.T) {
+	t.Setenv("CAESAR_PROFILE", ProfileTestnet)
+	if _, err := Load(); err == nil {
+		t.Error("testnet without a CLOB URL should not load")
+	}
+
+	t.Setenv("CAESAR_CLOB_URL", "https://clob.polymarket.com")
+	if _, err := Load(); err == nil {
+		t.Error("testnet against the production CLOB should not load")
+	}
+
+	t.Setenv("CAESAR_CLOB_URL", "http://localhost:8080")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ChainID != 80002 {
+		t.Errorf("expected chain 80002, got %d", cfg.ChainID)
+	}
+
+	t.Setenv("CAESAR_CHAIN_ID", "137")
+	if _, err := Load(); err == nil {
+		t.Error("testnet on chain 137 should not load")
+	}
+}
+
+func TestApplyProfileFlag(t *testing.T) {
+	t.Setenv("CAESAR_PROFILE", "")
+
+	for _, args := range [][]string{
+		{"--profile", "testnet", "book", "x"},
+		{"-profile=testnet", "book", "x"},
+	} {
+		rest, err := ApplyProfileFlag(args)
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		if len(rest) != 2 || rest[0] != "book" || os.Getenv("CAESAR_PROFILE") != ProfileTestnet {
+			t.Errorf("%v: rest = %v, profile = %s", args, rest, os.Getenv("CAESAR_PROFILE"))
+		}
+	}
+
+	if rest, err := ApplyProfileFlag([]string{"book", "--profile", "prod"}); err != nil || len(rest) != 3 {
+		t.Errorf("only a leading flag is a profile: %v, %v", rest, err)
+	}
+	for _, args := range [][]string{{"--profile"}, {"--profile", "staging"}} {
+		if _, err := ApplyProfileFlag(args); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+}
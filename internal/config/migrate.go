@@ -0,0 +1,213 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Change retires a configuration variable.
+type Change struct {
+	Key string
+	// Replacement is the variable Key was renamed to; empty if it was
+	// removed outright.
+	Replacement string
+	// Note explains the change to whoever upgrades an old file.
+	Note string
+}
+
+// Changes lists every retired variable, oldest first. Add an entry
+// whenever a variable is renamed or removed, so config migrate can
+// upgrade files written for earlier releases.
+var Changes = []Change{
+	{Key: "CAESAR_POLY_API_URL", Replacement: "CAESAR_CLOB_URL", Note: "the CLOB URL now belongs to the profile's endpoints"},
+	{Key: "CAESAR_POLY_WS_URL", Replacement: "CAESAR_CLOB_WS_URL", Note: "the CLOB market stream now belongs to the profile's endpoints"},
+	{Key: "CAESAR_SIGNER_BUNDLE_KEY", Note: "the bundle key is compiled in or installed root-owned at /etc/caesar/bundle.pub, out of reach of the environment"},
+}
+
+// Migration actions.
+const (
+	MigrateRenamed = "renamed"
+	MigrateRemoved = "removed"
+	MigrateUnknown = "unknown"
+)
+
+// MigrationNote explains one line config migrate changed.
+type MigrationNote struct {
+	Line   int
+	Key    string
+	Action string
+	Detail string
+}
+
+// EnvKeys returns every CAESAR_ variable Load reads, sorted.
+func EnvKeys() []string {
+	var keys []string
+	t := reflect.TypeOf(Config{})
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if tag := f.Tag.Get("mapstructure"); tag != "" {
+			keys = append(keys, envKey(tag))
+			continue
+		}
+		if f.Type.Kind() != reflect.Struct {
+			continue
+		}
+		for j := range f.Type.NumField() {
+			if tag := f.Type.Field(j).Tag.Get("mapstructure"); tag != "" {
+				keys = append(keys, envKey(strings.ToLower(f.Name)+"."+tag))
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func envKey(key string) string {
+	return "CAESAR_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// UnknownEnv returns the CAESAR_ variables in environ (as from
+// os.Environ) that Load ignores, sorted.
+func UnknownEnv(environ []string) []string {
+	known := make(map[string]bool)
+	for _, k := range EnvKeys() {
+		known[k] = true
+	}
+	var unknown []string
+	for _, kv := range environ {
+		k, _, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(k, "CAESAR_") && !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// MigrateEnv upgrades an env file (KEY=VALUE lines, as in .env.example)
+// to the current schema. Renamed variables take their new name; removed
+// and unknown CAESAR_ variables are commented out, never deleted.
+// Comments, blank lines and other variables are kept as they are.
+func MigrateEnv(data []byte) ([]byte, []MigrationNote, error) {
+	return migrateEnv(data, Changes)
+}
+
+func migrateEnv(data []byte, changes []Change) ([]byte, []MigrationNote, error) {
+	retired := make(map[string]Change, len(changes))
+	for _, c := range changes {
+		retired[c.Key] = c
+	}
+	known := make(map[string]bool)
+	keys := EnvKeys()
+	for _, k := range keys {
+		known[k] = true
+	}
+
+	type line struct {
+		text, prefix, key, value string
+	}
+	var lines []line
+	set := make(map[string]int) // variable → line of its last non-empty value
+	empty := make(map[string][]int)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		l := line{text: sc.Text()}
+		body := strings.TrimSpace(l.text)
+		if body != "" && !strings.HasPrefix(body, "#") {
+			if rest, ok := strings.CutPrefix(body, "export "); ok {
+				l.prefix, body = "export ", strings.TrimSpace(rest)
+			}
+			if k, v, ok := strings.Cut(body, "="); ok {
+				l.key, l.value = strings.TrimSpace(k), v
+				if strings.TrimSpace(v) != "" {
+					set[l.key] = len(lines) + 1
+				} else {
+					empty[l.key] = append(empty[l.key], len(lines)+1)
+				}
+			}
+		}
+		lines = append(lines, l)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// An empty placeholder for a variable a retired one is renamed to
+	// would shadow or be shadowed by the renamed line; drop it.
+	placeholders := make(map[int]string)
+	for i, l := range lines {
+		if c, ok := retired[l.key]; ok && c.Replacement != "" && set[c.Replacement] == 0 {
+			for _, n := range empty[c.Replacement] {
+				placeholders[n] = fmt.Sprintf("empty; line %d sets it as %s", i+1, l.key)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	var notes []MigrationNote
+	comment := func(n int, l line, action, detail string) {
+		fmt.Fprintf(&out, "# %s (config migrate: %s; %s)\n", strings.TrimSpace(l.text), action, detail)
+		notes = append(notes, MigrationNote{Line: n, Key: l.key, Action: action, Detail: detail})
+	}
+	for i, l := range lines {
+		n := i + 1
+		c, isRetired := retired[l.key]
+		switch {
+		case l.key == "" || !strings.HasPrefix(l.key, "CAESAR_"):
+			out.WriteString(l.text + "\n")
+		case placeholders[n] != "":
+			comment(n, l, MigrateRemoved, placeholders[n])
+		case isRetired && c.Replacement == "":
+			comment(n, l, MigrateRemoved, c.Note)
+		case isRetired && set[c.Replacement] != 0:
+			comment(n, l, MigrateRemoved, fmt.Sprintf("superseded by %s on line %d; %s", c.Replacement, set[c.Replacement], c.Note))
+		case isRetired:
+			fmt.Fprintf(&out, "%s%s=%s\n", l.prefix, c.Replacement, l.value)
+			notes = append(notes, MigrationNote{Line: n, Key: l.key, Action: MigrateRenamed, Detail: fmt.Sprintf("now %s; %s", c.Replacement, c.Note)})
+		case !known[l.key]:
+			detail := "not a setting of this release"
+			if guess := closestKey(l.key, keys); guess != "" {
+				detail += "; did you mean " + guess + "?"
+			}
+			comment(n, l, MigrateUnknown, detail)
+		default:
+			out.WriteString(l.text + "\n")
+		}
+	}
+	return out.Bytes(), notes, nil
+}
+
+// closestKey returns the key within a few edits of key, if any.
+func closestKey(key string, keys []string) string {
+	best, bestDist := "", 4
+	for _, k := range keys {
+		if d := editDistance(key, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEnvKeys(t *testing.T) {
+	keys := EnvKeys()
+	for _, k := range []string{"CAESAR_PROFILE", "CAESAR_SIGNER_SOCKET_PATH", "CAESAR_CLOB_URL", "CAESAR_DB_DBNAME", "CAESAR_KALSHI_API_KEY"} {
+		if !slices.Contains(keys, k) {
+			t.Errorf("missing %s", k)
+		}
+	}
+	for _, c := range Changes {
+		if slices.Contains(keys, c.Key) {
+			t.Errorf("retired %s is still read", c.Key)
+		}
+		if c.Replacement != "" && !slices.Contains(keys, c.Replacement) {
+			t.Errorf("%s is renamed to %s, which is not read", c.Key, c.Replacement)
+		}
+	}
+
+	unknown := UnknownEnv([]string{"CAESAR_ENV=x", "CAESAR_POLY_API_URL=y", "HOME=/root", "CAESAR_NOPE"})
+	if !slices.Equal(unknown, []string{"CAESAR_NOPE", "CAESAR_POLY_API_URL"}) {
+		t.Errorf("unknown = %v", unknown)
+	}
+}
+
+func TestMigrateEnv(t *testing.T) {
+	changes := []Change{
+		{Key: "CAESAR_OLD_SOCKET", Replacement: "CAESAR_SIGNER_SOCKET_PATH", Note: "moved under signer"},
+		{Key: "CAESAR_OLD_URL", Replacement: "CAESAR_CLOB_URL", Note: "moved under clob"},
+		{Key: "CAESAR_OLD_FLAG", Note: "always on now"},
+	}
+	in := strings.Join([]string{
+		"# comment",
+		"CAESAR_CLOB_URL=",
+		"export CAESAR_OLD_SOCKET=/tmp/s.sock",
+		"CAESAR_OLD_URL=http://clob",
+		"CAESAR_OLD_FLAG=true",
+		"CAESAR_SIGNER_SESION_TTL_SEC=60",
+		"CAESAR_ENV=dev",
+		"OTHER=1",
+		"",
+	}, "\n")
+
+	out, notes, err := migrateEnv([]byte(in), changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"# comment",
+		"# CAESAR_CLOB_URL= (config migrate: removed; empty; line 4 sets it as CAESAR_OLD_URL)",
+		"export CAESAR_SIGNER_SOCKET_PATH=/tmp/s.sock",
+		"CAESAR_CLOB_URL=http://clob",
+		"# CAESAR_OLD_FLAG=true (config migrate: removed; always on now)",
+		"# CAESAR_SIGNER_SESION_TTL_SEC=60 (config migrate: unknown; not a setting of this release; did you mean CAESAR_SIGNER_SESSION_TTL_SEC?)",
+		"CAESAR_ENV=dev",
+		"OTHER=1",
+		"",
+	}, "\n")
+	if string(out) != want {
+		t.Errorf("migrated:\n%s\nwant:\n%s", out, want)
+	}
+
+	var got []string
+	for _, n := range notes {
+		got = append(got, n.Key+" "+n.Action)
+	}
+	if !slices.Equal(got, []string{
+		"CAESAR_CLOB_URL removed",
+		"CAESAR_OLD_SOCKET renamed",
+		"CAESAR_OLD_URL renamed",
+		"CAESAR_OLD_FLAG removed",
+		"CAESAR_SIGNER_SESION_TTL_SEC unknown",
+	}) {
+		t.Errorf("notes = %v", got)
+	}
+
+	// A renamed variable whose replacement is already set is dropped.
+	out, _, _ = migrateEnv([]byte("CAESAR_CLOB_URL=http://new\nCAESAR_OLD_URL=http://old\n"), changes)
+	if !strings.HasPrefix(string(out), "CAESAR_CLOB_URL=http://new\n# CAESAR_OLD_URL=http://old (config migrate: removed; superseded by CAESAR_CLOB_URL on line 1") {
+		t.Errorf("superseded:\n%s", out)
+	}
+}
+
+func TestEnvExampleIsCurrent(t *testing.T) {
+	data, err := os.ReadFile("../../.env.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, notes, err := MigrateEnv(data); err != nil || len(notes) != 0 {
+		t.Errorf(".env.example needs migrating: %v %v", notes, err)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build linux
+
+package config
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileOwner returns the UID that owns the file info describes.
+func fileOwner(info fs.FileInfo) (uid uint32, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return st.Uid, true
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package config
+
+import "io/fs"
+
+// fileOwner is unsupported off Linux; only the permission bits of the
+// bundle key and state are checked there.
+func fileOwner(fs.FileInfo) (uid uint32, ok bool) {
+	return 0, false
+}
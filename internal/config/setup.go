@@ -0,0 +1,139 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Key backends offered by caesarctl init. KMS keeps the signing key
+// wrapped by an AWS KMS key on the signer host; offline keeps it on an
+// air-gapped machine that only ever runs signer offline.
+const (
+	KeyBackendKMS     = "kms"
+	KeyBackendOffline = "offline"
+)
+
+// Setup is the set of answers caesarctl init collects on first run.
+type Setup struct {
+	Profile         string
+	CLOBURL         string
+	KeyBackend      string
+	KMSKeyID        string
+	AWSRegion       string
+	LocalStack      string
+	ProdAddresses   string
+	DisplayTimezone string
+}
+
+// Validate reports the first answer a written config would fail to load
+// or run with.
+func (s Setup) Validate() error {
+	if _, ok := Profiles[s.Profile]; !ok {
+		return fmt.Errorf("unknown profile %q", s.Profile)
+	}
+	if s.Profile == ProfileTestnet && (s.CLOBURL == "" || s.CLOBURL == Profiles[ProfileProd]["clob.url"]) {
+		return errors.New("the testnet profile needs a non-production CLOB URL")
+	}
+	switch s.KeyBackend {
+	case KeyBackendKMS:
+		if s.KMSKeyID == "" {
+			return errors.New("the kms key backend needs a KMS key ID")
+		}
+	case KeyBackendOffline:
+	default:
+		return fmt.Errorf("unknown key backend %q", s.KeyBackend)
+	}
+	if _, err := time.LoadLocation(s.DisplayTimezone); err != nil {
+		return fmt.Errorf("invalid display time zone %q: %w", s.DisplayTimezone, err)
+	}
+	return nil
+}
+
+// Env renders the answers as an env file. Everything the profile already
+// supplies is left to it; a commented block of common optional settings
+// follows, ready to uncomment.
+func (s Setup) Env() []byte {
+	var b strings.Builder
+	b.WriteString("# Caesar configuration written by caesarctl init.\n")
+	b.WriteString("# Every CAESAR_ variable is described in .env.example; upgrade this\n")
+	b.WriteString("# file after an update with caesarctl config migrate -w.\n\n")
+	fmt.Fprintf(&b, "CAESAR_PROFILE=%s\n", s.Profile)
+	fmt.Fprintf(&b, "CAESAR_DISPLAY_TIMEZONE=%s\n", s.DisplayTimezone)
+	if s.CLOBURL != "" {
+		fmt.Fprintf(&b, "CAESAR_CLOB_URL=%s\n", s.CLOBURL)
+	}
+	if s.KeyBackend == KeyBackendKMS {
+		b.WriteString("\n# Signing key, wrapped by AWS KMS.\n")
+		fmt.Fprintf(&b, "CAESAR_SIGNER_KMS_KEY_ID=%s\n", s.KMSKeyID)
+		if s.AWSRegion != "" {
+			fmt.Fprintf(&b, "CAESAR_SIGNER_AWS_REGION=%s\n", s.AWSRegion)
+		}
+		if s.LocalStack != "" {
+			fmt.Fprintf(&b, "CAESAR_LOCALSTACK_ENDPOINT=%s\n", s.LocalStack)
+		}
+	} else {
+		b.WriteString("\n# Signing key held offline; sign bundles with signer offline.\n")
+	}
+	if s.ProdAddresses != "" {
+		b.WriteString("\n# Production keys: refused under any other profile.\n")
+		fmt.Fprintf(&b, "CAESAR_SIGNER_PROD_ADDRESSES=%s\n", s.ProdAddresses)
+	}
+	b.WriteString(setupExamples)
+	return []byte(b.String())
+}
+
+const setupExamples = `
+# Examples (uncomment to enable):
+# A quarter of the session limit overnight, in the display time zone.
+#CAESAR_SIGNER_LIMIT_PROFILES=night=22:00-07:00/25
+# Flag any signing in these hours as an anomaly.
+#CAESAR_SIGNER_QUIET_HOURS=01:00-06:00
+# Refuse outbound connections to any other host.
+#CAESAR_EGRESS_ALLOW=*.polymarket.com,polygon-rpc.com
+# Alerts to a chat webhook.
+#CAESAR_NOTIFY_WEBHOOK_URL=https://hooks.slack.com/services/...
+`
+
+// SystemdUnit renders a service unit running the signer binary with the
+// env file. The socket and audit anchor directories of the profile are
+// created by systemd; the unit raises the memlock limit so the key can
+// stay locked in memory.
+func (s Setup) SystemdUnit(binary, envFile string) []byte {
+	socket, _ := Profiles[s.Profile]["signer.socket_path"].(string)
+	anchors, _ := Profiles[s.Profile]["signer.audit_anchor_file"].(string)
+
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=Caesar signer (%s)\n", s.Profile)
+	b.WriteString("After=network-online.target\nWants=network-online.target\n\n")
+	b.WriteString("[Service]\n")
+	b.WriteString("User=caesar\nGroup=caesar\n")
+	fmt.Fprintf(&b, "EnvironmentFile=%s\n", envFile)
+	fmt.Fprintf(&b, "ExecStart=%s\n", binary)
+	b.WriteString("Restart=on-failure\n")
+	if dir, ok := unitDir(socket, "/var/run/", "/run/"); ok {
+		fmt.Fprintf(&b, "RuntimeDirectory=%s\nRuntimeDirectoryMode=0750\n", dir)
+	}
+	if dir, ok := unitDir(anchors, "/var/lib/"); ok {
+		fmt.Fprintf(&b, "StateDirectory=%s\n", dir)
+	}
+	b.WriteString("LimitMEMLOCK=infinity\nLimitCORE=0\n")
+	b.WriteString("NoNewPrivileges=yes\nProtectSystem=strict\nProtectHome=yes\nPrivateTmp=yes\n\n")
+	b.WriteString("[Install]\nWantedBy=multi-user.target\n")
+	return []byte(b.String())
+}
+
+// unitDir returns the directory of file relative to the first matching
+// root, as systemd's RuntimeDirectory and StateDirectory expect.
+func unitDir(file string, roots ...string) (string, bool) {
+	dir := path.Dir(file)
+	for _, root := range roots {
+		if rel, ok := strings.CutPrefix(dir+"/", root); ok && rel != "" {
+			return strings.TrimSuffix(rel, "/"), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetupValidate(t *testing.T) {
+	good := Setup{Profile: ProfilePaper, KeyBackend: KeyBackendOffline, DisplayTimezone: "UTC"}
+	if err := good.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, mutate := range map[string]func(*Setup){
+		"profile":        func(s *Setup) { s.Profile = "staging" },
+		"backend":        func(s *Setup) { s.KeyBackend = "yubikey" },
+		"kms key":        func(s *Setup) { s.KeyBackend = KeyBackendKMS },
+		"timezone":       func(s *Setup) { s.DisplayTimezone = "Mars/Base" },
+		"testnet clob":   func(s *Setup) { s.Profile = ProfileTestnet },
+		"production url": func(s *Setup) { s.Profile, s.CLOBURL = ProfileTestnet, "https://clob.polymarket.com" },
+	} {
+		s := good
+		mutate(&s)
+		if err := s.Validate(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestSetupEnvLoads(t *testing.T) {
+	s := Setup{
+		Profile:         ProfileTestnet,
+		CLOBURL:         "https://clob.amoy.example",
+		KeyBackend:      KeyBackendKMS,
+		KMSKeyID:        "alias/caesar",
+		AWSRegion:       "eu-west-1",
+		DisplayTimezone: "Europe/Berlin",
+	}
+	env := s.Env()
+	if _, notes, err := MigrateEnv(env); err != nil || len(notes) != 0 {
+		t.Errorf("generated env needs migrating: %v %v", notes, err)
+	}
+	for _, line := range strings.Split(string(env), "\n") {
+		if k, v, ok := strings.Cut(line, "="); ok && strings.HasPrefix(k, "CAESAR_") {
+			t.Setenv(k, v)
+		}
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("generated env does not load: %v", err)
+	}
+	if cfg.ChainID != 80002 || cfg.Signer.KMSKeyID != "alias/caesar" || cfg.Signer.AWSRegion != "eu-west-1" || cfg.DisplayTimezone != "Europe/Berlin" {
+		t.Errorf("unexpected config: chain %d key %s region %s tz %s", cfg.ChainID, cfg.Signer.KMSKeyID, cfg.Signer.AWSRegion, cfg.DisplayTimezone)
+	}
+}
+
+func TestSetupSystemdUnit(t *testing.T) {
+	unit := string(Setup{Profile: ProfileProd}.SystemdUnit("/usr/local/bin/signer", "/etc/caesar/caesar.env"))
+	for _, want := range []string{
+		"EnvironmentFile=/etc/caesar/caesar.env\n",
+		"ExecStart=/usr/local/bin/signer\n",
+		"RuntimeDirectory=caesar\n",
+		"StateDirectory=caesar\n",
+		"LimitMEMLOCK=infinity\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit is missing %q:\n%s", want, unit)
+		}
+	}
+	if unit := string(Setup{Profile: ProfilePaper}.SystemdUnit("signer", "env")); !strings.Contains(unit, "RuntimeDirectory=caesar/paper\n") {
+		t.Errorf("paper unit should use the profile's socket directory:\n%s", unit)
+	}
+}
@@ -0,0 +1,217 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+var ErrBlocked = errors.New("egress blocked")
+
+// DialFunc matches net.Dialer.DialContext.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Resolver looks up a host's addresses. *net.Resolver implements it.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// Guard restricts outbound connections to an allowlist of hostnames and
+// IP ranges. Names are resolved by the guard itself and the checked
+// address is the one dialled, so a rebinding DNS answer cannot slip past.
+// Pinned hosts skip DNS entirely.
+type Guard struct {
+	hosts []string // exact names or "*.suffix"
+	nets  []netip.Prefix
+	pins  map[string][]netip.Addr
+
+	// Resolver looks up allowed names; nil uses net.DefaultResolver.
+	Resolver Resolver
+	// OnBlock, if set, is called for every refused connection with the
+	// requested host and why it was refused.
+	OnBlock func(host, reason string)
+}
+
+// Parse builds a Guard from an allowlist of hostnames, "*.domain"
+// wildcards and CIDR ranges, comma-separated, and pins of the form
+// "host=ip,ip;host2=ip". A pinned host is implicitly allowed. It returns
+// nil when both are empty, meaning egress is unrestricted.
+func Parse(allow, pins string) (*Guard, error) {
+	if strings.TrimSpace(allow) == "" && strings.TrimSpace(pins) == "" {
+		return nil, nil
+	}
+	g := &Guard{pins: make(map[string][]netip.Addr)}
+	for _, entry := range splitList(allow) {
+		if strings.Contains(entry, "/") {
+			p, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("egress allow %q: %w", entry, err)
+			}
+			g.nets = append(g.nets, p.Masked())
+			continue
+		}
+		if a, err := netip.ParseAddr(entry); err == nil {
+			g.nets = append(g.nets, netip.PrefixFrom(a, a.BitLen()))
+			continue
+		}
+		g.hosts = append(g.hosts, strings.ToLower(entry))
+	}
+	for _, entry := range strings.Split(pins, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, ips, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("egress pin %q: missing '='", entry)
+		}
+		host = strings.ToLower(strings.TrimSpace(host))
+		for _, ip := range splitList(ips) {
+			a, err := netip.ParseAddr(ip)
+			if err != nil {
+				return nil, fmt.Errorf("egress pin %q: %w", entry, err)
+			}
+			g.pins[host] = append(g.pins[host], a)
+		}
+		if len(g.pins[host]) == 0 {
+			return nil, fmt.Errorf("egress pin %q: no addresses", entry)
+		}
+	}
+	return g, nil
+}
+
+// AllowedHost reports whether host matches the allowlist or is pinned.
+func (g *Guard) AllowedHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if _, ok := g.pins[host]; ok {
+		return true
+	}
+	for _, h := range g.hosts {
+		if suffix, ok := strings.CutPrefix(h, "*"); ok {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+		} else if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Guard) inNets(a netip.Addr) bool {
+	a = a.Unmap()
+	for _, p := range g.nets {
+		if p.Contains(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// addrs returns the addresses host may be dialled at.
+func (g *Guard) addrs(ctx context.Context, host string) ([]netip.Addr, error) {
+	if a, err := netip.ParseAddr(host); err == nil {
+		if !g.inNets(a) {
+			return nil, g.block(host, "address not in allowed ranges")
+		}
+		return []netip.Addr{a}, nil
+	}
+	if pinned, ok := g.pins[strings.ToLower(host)]; ok {
+		return pinned, nil
+	}
+	if !g.AllowedHost(host) {
+		return nil, g.block(host, "host not allowed")
+	}
+	var r Resolver = net.DefaultResolver
+	if g.Resolver != nil {
+		r = g.Resolver
+	}
+	resolved, err := r.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	// An allowed public name resolving to an internal address is a DNS
+	// attack or a misconfiguration; internal targets must be listed by
+	// range.
+	var out []netip.Addr
+	for _, a := range resolved {
+		a = a.Unmap()
+		if internal(a) && !g.inNets(a) {
+			continue
+		}
+		out = append(out, a)
+	}
+	if len(out) == 0 {
+		return nil, g.block(host, "resolved only to internal addresses")
+	}
+	return out, nil
+}
+
+func internal(a netip.Addr) bool {
+	return a.IsLoopback() || a.IsPrivate() || a.IsLinkLocalUnicast() || a.IsUnspecified()
+}
+
+func (g *Guard) block(host, reason string) error {
+	if g.OnBlock != nil {
+		g.OnBlock(host, reason)
+	}
+	return fmt.Errorf("%w: %s: %s", ErrBlocked, host, reason)
+}
+
+// Check reports whether host may be reached, logging a refusal through
+// OnBlock, for clients like net/smtp that dial on their own.
+func (g *Guard) Check(ctx context.Context, host string) error {
+	_, err := g.addrs(ctx, host)
+	return err
+}
+
+// CheckAddr reports whether host:port may be reached, logging a refusal
+// through OnBlock, for connections a proxy opens on the caller's behalf.
+func (g *Guard) CheckAddr(ctx context.Context, addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return g.block(addr, "malformed address")
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return g.block(host, "invalid port "+port)
+	}
+	return g.Check(ctx, host)
+}
+
+// DialContext wraps dial so it only connects to allowed destinations,
+// trying each permitted address of the host in turn.
+func (g *Guard) DialContext(dial DialFunc) DialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := g.addrs(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var errs []error
+		for _, a := range addrs {
+			conn, err := dial(ctx, network, net.JoinHostPort(a.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	}
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
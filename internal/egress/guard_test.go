@@ -0,0 +1,111 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+type fakeResolver map[string][]netip.Addr
+
+func (f fakeResolver) LookupNetIP(_ context.Context, _, host string) ([]netip.Addr, error) {
+	if a, ok := f[host]; ok {
+		return a, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+func TestGuardAllowsListedHostsOnly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+
+	g, err := Parse("*.polymarket.example, hooks.example, 10.0.0.0/8", "pinned.example=127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loopback := []netip.Addr{netip.MustParseAddr("127.0.0.1")}
+	g.Resolver = fakeResolver{"clob.polymarket.example": loopback, "hooks.example": {netip.MustParseAddr("93.184.216.34")}}
+	var blocked []string
+	g.OnBlock = func(host, reason string) { blocked = append(blocked, host+": "+reason) }
+	client := NewHTTPClient(g, nil, ClassAlerts)
+
+	get := func(host string) error {
+		resp, err := client.Get("http://" + net.JoinHostPort(host, port))
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	if err := get("pinned.example"); err != nil {
+		t.Errorf("pinned host: %v", err)
+	}
+	for _, host := range []string{"evil.example", "127.0.0.1", "clob.polymarket.example"} {
+		if err := get(host); !errors.Is(err, ErrBlocked) {
+			t.Errorf("%s: expected ErrBlocked, got %v", host, err)
+		}
+	}
+	if len(blocked) != 3 || !strings.Contains(blocked[2], "internal") {
+		t.Errorf("blocked = %v", blocked)
+	}
+
+	if err := g.Check(context.Background(), "smtp.evil.example"); !errors.Is(err, ErrBlocked) || len(blocked) != 4 {
+		t.Errorf("Check = %v, blocked = %v", err, blocked)
+	}
+	if !g.AllowedHost("gamma.polymarket.example") || g.AllowedHost("polymarket.example.evil") {
+		t.Error("wildcard matching is wrong")
+	}
+}
+
+func TestGuardIgnoresEnvironmentProxy(t *testing.T) {
+	// The proxy is on an allowed address; if the client used it, the
+	// guard would only ever check the proxy and pass every destination.
+	var proxied []string
+	proxySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.Host)
+		w.Write([]byte("ok"))
+	}))
+	defer proxySrv.Close()
+	t.Setenv("HTTP_PROXY", proxySrv.URL)
+	t.Setenv("HTTPS_PROXY", proxySrv.URL)
+
+	g, err := Parse("127.0.0.1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewHTTPClient(g, nil, ClassAlerts)
+	for _, url := range []string{"http://evil.example/", "https://evil.example/"} {
+		resp, err := client.Get(url)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if !errors.Is(err, ErrBlocked) {
+			t.Errorf("%s: expected ErrBlocked, got %v", url, err)
+		}
+	}
+	if len(proxied) != 0 {
+		t.Errorf("requests went through the environment proxy: %v", proxied)
+	}
+}
+
+func TestParse(t *testing.T) {
+	if g, err := Parse("", ""); g != nil || err != nil {
+		t.Errorf("empty config should disable the guard, got %v, %v", g, err)
+	}
+	for _, tc := range [][2]string{
+		{"10.0.0.0/33", ""},
+		{"", "host"},
+		{"", "host=not-an-ip"},
+	} {
+		if _, err := Parse(tc[0], tc[1]); err == nil {
+			t.Errorf("Parse(%q, %q) accepted", tc[0], tc[1])
+		}
+	}
+}
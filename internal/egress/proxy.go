@@ -0,0 +1,261 @@
+package egress
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// Outbound connection classes that can be routed through different
+// proxies.
+const (
+	ClassExchange = "exchange" // CLOB and Gamma REST
+	ClassStream   = "stream"   // market and user WebSockets
+	ClassRPC      = "rpc"      // Polygon JSON-RPC
+	ClassAlerts   = "alerts"   // webhook, chat and push notifications
+	ClassAudit    = "audit"    // RFC 3161 timestamping of the audit chain
+	ClassPricing  = "pricing"  // FX reference rates for reporting
+)
+
+// direct in a route spec bypasses the default proxy for a class.
+const direct = "direct"
+
+// Proxies maps connection classes to an HTTP, HTTPS or SOCKS5 proxy.
+// Classes without a proxy connect directly.
+type Proxies struct {
+	def     *url.URL
+	byClass map[string]*url.URL // nil value: direct
+}
+
+// ParseProxies builds a Proxies from a default proxy URL and per-class
+// overrides of the form "stream=socks5h://127.0.0.1:9050;alerts=direct".
+// Supported schemes are http, https, socks5 and socks5h. It returns nil
+// when both are empty.
+func ParseProxies(def, routes string) (*Proxies, error) {
+	if strings.TrimSpace(def) == "" && strings.TrimSpace(routes) == "" {
+		return nil, nil
+	}
+	p := &Proxies{byClass: make(map[string]*url.URL)}
+	var err error
+	if p.def, err = parseProxyURL(def); err != nil {
+		return nil, err
+	}
+	for _, entry := range strings.Split(routes, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		class, raw, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("proxy route %q: missing '='", entry)
+		}
+		class = strings.TrimSpace(class)
+		switch class {
+		case ClassExchange, ClassStream, ClassRPC, ClassAlerts, ClassAudit, ClassPricing:
+		default:
+			return nil, fmt.Errorf("proxy route %q: unknown class %q", entry, class)
+		}
+		if p.byClass[class], err = parseProxyURL(raw); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func parseProxyURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == direct {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("proxy %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("proxy %q: unsupported scheme %q", raw, u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy %q: missing host", raw)
+	}
+	return u, nil
+}
+
+// For returns the proxy for class, or nil to connect directly.
+func (p *Proxies) For(class string) *url.URL {
+	if p == nil {
+		return nil
+	}
+	if u, ok := p.byClass[class]; ok {
+		return u
+	}
+	return p.def
+}
+
+// Dialer returns a dial function for raw connections of class, such as
+// WebSockets, that tunnels through the class's proxy: HTTP CONNECT for
+// http and https proxies, SOCKS5 otherwise. dial opens the connection to
+// the proxy, or to the destination without one. g, if set, must allow
+// both the proxy and the destination, which is checked before the proxy
+// is asked to connect to it.
+func (p *Proxies) Dialer(class string, g *Guard, dial DialFunc) DialFunc {
+	forward := dial
+	if g != nil {
+		forward = g.DialContext(dial)
+	}
+	u := p.For(class)
+	var tunnel DialFunc
+	switch {
+	case u == nil:
+		return forward
+	case u.Scheme == "http" || u.Scheme == "https":
+		tunnel = connectDialer(u, forward)
+	default:
+		tunnel = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			d, err := proxy.FromURL(u, dialFunc(forward))
+			if err != nil {
+				return nil, err
+			}
+			return d.(proxy.ContextDialer).DialContext(ctx, network, addr)
+		}
+	}
+	if g == nil {
+		return tunnel
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if err := g.CheckAddr(ctx, addr); err != nil {
+			return nil, err
+		}
+		return tunnel(ctx, network, addr)
+	}
+}
+
+// Proxy returns a Transport.Proxy function sending every request through
+// u once g allows its destination. The transport only dials the proxy, so
+// without this the guard would never see where requests go.
+func (g *Guard) Proxy(u *url.URL) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if err := g.CheckAddr(req.Context(), requestAddr(req.URL)); err != nil {
+			return nil, err
+		}
+		return u, nil
+	}
+}
+
+// requestAddr returns the host:port a request URL is for.
+func requestAddr(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return net.JoinHostPort(u.Hostname(), port)
+	}
+	if u.Scheme == "https" || u.Scheme == "wss" {
+		return net.JoinHostPort(u.Hostname(), "443")
+	}
+	return net.JoinHostPort(u.Hostname(), "80")
+}
+
+// dialFunc adapts a DialFunc to the x/net/proxy forwarding interfaces.
+type dialFunc DialFunc
+
+func (f dialFunc) Dial(network, addr string) (net.Conn, error) {
+	return f(context.Background(), network, addr)
+}
+
+func (f dialFunc) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return f(ctx, network, addr)
+}
+
+// connectDialer opens an HTTP CONNECT tunnel to addr through the proxy
+// at u.
+func connectDialer(u *url.URL, forward DialFunc) DialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := forward(ctx, "tcp", proxyAddr(u))
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme == "https" {
+			tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12})
+			if err := tlsConn.HandshakeContext(ctx); err != nil {
+				conn.Close()
+				return nil, fmt.Errorf("proxy %s: %w", u.Host, err)
+			}
+			conn = tlsConn
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+			defer conn.SetDeadline(time.Time{})
+		}
+
+		req := &http.Request{
+			Method: http.MethodConnect,
+			URL:    &url.URL{Opaque: addr},
+			Host:   addr,
+			Header: make(http.Header),
+		}
+		if u.User != nil {
+			pass, _ := u.User.Password()
+			cred := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + pass))
+			req.Header.Set("Proxy-Authorization", "Basic "+cred)
+		}
+		if err := req.Write(conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		// The client speaks first on the tunnel (TLS or the WebSocket
+		// upgrade), so nothing past the response can be buffered here.
+		resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			conn.Close()
+			return nil, fmt.Errorf("proxy %s: CONNECT %s: %s", u.Host, addr, resp.Status)
+		}
+		return conn, nil
+	}
+}
+
+func proxyAddr(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	if u.Scheme == "https" {
+		return net.JoinHostPort(u.Hostname(), "443")
+	}
+	return net.JoinHostPort(u.Hostname(), "80")
+}
+
+// NewHTTPClient returns an HTTP client for class that connects through
+// the class's proxy and only to destinations g allows. Either may be nil;
+// without Proxies the standard HTTP_PROXY environment applies, unless g is
+// set: the guard would only see the proxy's address, so a guarded client
+// ignores the environment and connects directly. A guarded client sends a
+// request to its class's proxy only once g allows the destination.
+func NewHTTPClient(g *Guard, p *Proxies, class string) *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if p != nil || g != nil {
+		t.Proxy = nil
+		if u := p.For(class); u != nil {
+			if g != nil {
+				t.Proxy = g.Proxy(u)
+			} else {
+				t.Proxy = http.ProxyURL(u)
+			}
+		}
+	}
+	if g != nil {
+		t.DialContext = g.DialContext((&net.Dialer{}).DialContext)
+	}
+	return &http.Client{Transport: t}
+}
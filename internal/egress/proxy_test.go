@@ -0,0 +1,142 @@
+package egress
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseProxiesRoutesByClass(t *testing.T) {
+	p, err := ParseProxies("http://corp:3128", "stream=socks5h://127.0.0.1:9050; alerts=direct")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := p.For(ClassExchange); u == nil || u.Host != "corp:3128" {
+		t.Errorf("exchange proxy = %v", u)
+	}
+	if u := p.For(ClassStream); u == nil || u.Scheme != "socks5h" {
+		t.Errorf("stream proxy = %v", u)
+	}
+	if u := p.For(ClassAlerts); u != nil {
+		t.Errorf("alerts should bypass the proxy, got %v", u)
+	}
+	for _, bad := range [][2]string{{"ftp://x", ""}, {"", "video=http://x"}, {"", "stream"}} {
+		if _, err := ParseProxies(bad[0], bad[1]); err == nil {
+			t.Errorf("ParseProxies(%q, %q) accepted", bad[0], bad[1])
+		}
+	}
+}
+
+func TestConnectDialerTunnels(t *testing.T) {
+	target, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+	go func() {
+		conn, err := target.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		io.WriteString(conn, "echo "+line)
+	}()
+
+	var auth, connectTo string
+	proxySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, connectTo = r.Header.Get("Proxy-Authorization"), r.Host
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go io.Copy(upstream, conn)
+		io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	defer proxySrv.Close()
+
+	p, err := ParseProxies("", "stream=http://user:pw@"+strings.TrimPrefix(proxySrv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dial := p.Dialer(ClassStream, nil, (&net.Dialer{}).DialContext)
+	conn, err := dial(context.Background(), "tcp", target.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "hello\n")
+	got, _ := bufio.NewReader(conn).ReadString('\n')
+	if got != "echo hello\n" {
+		t.Errorf("tunnel returned %q", got)
+	}
+	if connectTo != target.Addr().String() || auth != "Basic dXNlcjpwdw==" {
+		t.Errorf("CONNECT %s with auth %q", connectTo, auth)
+	}
+}
+
+func TestGuardChecksProxiedDestinations(t *testing.T) {
+	// The stub proxy answers plain requests itself and records CONNECTs
+	// without tunnelling them.
+	var proxied []string
+	proxySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.Method+" "+r.Host)
+		if r.Method == http.MethodConnect {
+			http.Error(w, "no tunnels here", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer proxySrv.Close()
+
+	// The proxy is allowed; only allowed.example is allowed behind it.
+	g, err := Parse("127.0.0.1", "allowed.example=127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blocked []string
+	g.OnBlock = func(host, reason string) { blocked = append(blocked, host) }
+	p, err := ParseProxies(proxySrv.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewHTTPClient(g, p, ClassExchange)
+	resp, err := client.Get("http://evil.example/")
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrBlocked) {
+		t.Errorf("proxied request to a disallowed host: expected ErrBlocked, got %v", err)
+	}
+	resp, err = client.Get("http://allowed.example/")
+	if err != nil {
+		t.Fatalf("proxied request to an allowed host: %v", err)
+	}
+	resp.Body.Close()
+
+	dial := p.Dialer(ClassStream, g, (&net.Dialer{}).DialContext)
+	if _, err := dial(context.Background(), "tcp", "evil.example:443"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("tunnel to a disallowed host: expected ErrBlocked, got %v", err)
+	}
+	if _, err := dial(context.Background(), "tcp", "allowed.example:0"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("tunnel to port 0: expected ErrBlocked, got %v", err)
+	}
+
+	if len(proxied) != 1 || proxied[0] != "GET allowed.example" {
+		t.Errorf("the proxy saw %v, want only the allowed request", proxied)
+	}
+	if len(blocked) != 3 || blocked[0] != "evil.example" {
+		t.Errorf("blocked = %v", blocked)
+	}
+}
@@ -0,0 +1,110 @@
+package eip712
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Domain is an EIP-712 signing domain. Unset fields are left out of the
+// domain type, as wallets do.
+type Domain struct {
+	Name              string
+	Version           string
+	ChainID           uint64
+	VerifyingContract string
+}
+
+// Separator returns the domain separator: hashStruct of d as an
+// EIP712Domain holding only its set fields, in the standard order.
+func (d Domain) Separator() ([32]byte, error) {
+	td := &TypedData{Types: map[string][]TypedField{"EIP712Domain": nil}, Domain: map[string]any{}}
+	add := func(name, typ string, v any) {
+		td.Types["EIP712Domain"] = append(td.Types["EIP712Domain"], TypedField{Name: name, Type: typ})
+		td.Domain[name] = v
+	}
+	if d.Name != "" {
+		add("name", "string", d.Name)
+	}
+	if d.Version != "" {
+		add("version", "string", d.Version)
+	}
+	if d.ChainID != 0 {
+		add("chainId", "uint256", fmt.Sprint(d.ChainID))
+	}
+	if d.VerifyingContract != "" {
+		add("verifyingContract", "address", d.VerifyingContract)
+	}
+	return td.Separator()
+}
+
+// Keccak256 hashes the concatenation of parts.
+func Keccak256(parts ...[]byte) [32]byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
+
+// TypeHash returns keccak256 of an encodeType string.
+func TypeHash(encodedType string) [32]byte {
+	return Keccak256([]byte(encodedType))
+}
+
+// Digest returns the bytes actually signed:
+// keccak256(0x1901 ‖ domainSeparator ‖ hashStruct(message)).
+func Digest(separator, structHash [32]byte) [32]byte {
+	return Keccak256([]byte{0x19, 0x01}, separator[:], structHash[:])
+}
+
+// EncodeAddress left-pads a 0x-prefixed 20-byte hex address to 32 bytes.
+func EncodeAddress(addr string) ([]byte, error) {
+	raw, ok := strings.CutPrefix(addr, "0x")
+	if !ok {
+		raw, ok = strings.CutPrefix(addr, "0X")
+	}
+	b, err := hex.DecodeString(raw)
+	if !ok || err != nil || len(b) != 20 {
+		return nil, fmt.Errorf("%q is not a 0x-prefixed 20-byte address", addr)
+	}
+	out := make([]byte, 32)
+	copy(out[12:], b)
+	return out, nil
+}
+
+// EncodeUintString parses a decimal or 0x-hex uint256. Empty is zero.
+func EncodeUintString(s string) ([]byte, error) {
+	if s == "" {
+		return EncodeUint(new(big.Int)), nil
+	}
+	n, ok := new(big.Int), false
+	if hexDigits, isHex := strings.CutPrefix(s, "0x"); isHex {
+		n, ok = n.SetString(hexDigits, 16)
+	} else {
+		n, ok = n.SetString(s, 10)
+	}
+	if !ok || n.Sign() < 0 || n.BitLen() > 256 {
+		return nil, fmt.Errorf("%q is not a uint256", s)
+	}
+	return EncodeUint(n), nil
+}
+
+// EncodeUint encodes a non-negative n of at most 256 bits as one word.
+func EncodeUint(n *big.Int) []byte {
+	return n.FillBytes(make([]byte, 32))
+}
+
+// EncodeBool encodes b as one word.
+func EncodeBool(b bool) []byte {
+	word := make([]byte, 32)
+	if b {
+		word[31] = 1
+	}
+	return word
+}
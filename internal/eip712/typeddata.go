@@ -0,0 +1,292 @@
+package eip712
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidTypedData = errors.New("invalid typed data")
+
+// TypedData is an EIP-712 payload in its eth_signTypedData_v4 JSON form.
+type TypedData struct {
+	Types       map[string][]TypedField `json:"types"`
+	PrimaryType string                  `json:"primaryType"`
+	Domain      map[string]any          `json:"domain"`
+	Message     map[string]any          `json:"message"`
+}
+
+// TypedField is one member of a struct type.
+type TypedField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Parse decodes a JSON payload. Numbers are kept exact.
+func Parse(data []byte) (*TypedData, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var td TypedData
+	if err := dec.Decode(&td); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTypedData, err)
+	}
+	if _, ok := td.Types["EIP712Domain"]; !ok {
+		return nil, fmt.Errorf("%w: types has no EIP712Domain", ErrInvalidTypedData)
+	}
+	if _, ok := td.Types[td.PrimaryType]; !ok || td.PrimaryType == "EIP712Domain" {
+		return nil, fmt.Errorf("%w: primaryType %q is not a message type", ErrInvalidTypedData, td.PrimaryType)
+	}
+	return &td, nil
+}
+
+// Separator returns the hashStruct of the payload's domain.
+func (td *TypedData) Separator() ([32]byte, error) {
+	return td.HashStruct("EIP712Domain", td.Domain)
+}
+
+// Digest returns keccak256(0x1901 ‖ domainSeparator ‖ hashStruct(message)).
+func (td *TypedData) Digest() ([32]byte, error) {
+	sep, err := td.Separator()
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("domain: %w", err)
+	}
+	structHash, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("message: %w", err)
+	}
+	return Digest(sep, structHash), nil
+}
+
+// EncodeType returns the EIP-712 type string of typ: its own definition
+// followed by the definitions of every struct it references, sorted.
+func (td *TypedData) EncodeType(typ string) (string, error) {
+	deps := map[string]bool{}
+	if err := td.dependencies(typ, deps); err != nil {
+		return "", err
+	}
+	delete(deps, typ)
+	order := []string{typ}
+	for dep := range deps {
+		order = append(order, dep)
+	}
+	slices.Sort(order[1:])
+
+	var b strings.Builder
+	for _, name := range order {
+		b.WriteString(name)
+		b.WriteByte('(')
+		for i, f := range td.Types[name] {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(f.Type + " " + f.Name)
+		}
+		b.WriteByte(')')
+	}
+	return b.String(), nil
+}
+
+func (td *TypedData) dependencies(typ string, seen map[string]bool) error {
+	if seen[typ] {
+		return nil
+	}
+	fields, ok := td.Types[typ]
+	if !ok {
+		return fmt.Errorf("%w: undefined type %q", ErrInvalidTypedData, typ)
+	}
+	seen[typ] = true
+	for _, f := range fields {
+		base := arrayBase(f.Type)
+		if _, isStruct := td.Types[base]; isStruct {
+			if err := td.dependencies(base, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// HashStruct returns Keccak256(typeHash ‖ encodeData(data)) for typ.
+func (td *TypedData) HashStruct(typ string, data map[string]any) ([32]byte, error) {
+	enc, err := td.EncodeType(typ)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	typeHash := TypeHash(enc)
+	parts := [][]byte{typeHash[:]}
+	for _, f := range td.Types[typ] {
+		v, ok := data[f.Name]
+		if !ok {
+			return [32]byte{}, fmt.Errorf("%w: %s.%s is missing", ErrInvalidTypedData, typ, f.Name)
+		}
+		word, err := td.encodeValue(f.Type, v)
+		if err != nil {
+			return [32]byte{}, fmt.Errorf("%s.%s: %w", typ, f.Name, err)
+		}
+		parts = append(parts, word)
+	}
+	return Keccak256(parts...), nil
+}
+
+// encodeValue returns the 32-byte encoding of v as typ.
+func (td *TypedData) encodeValue(typ string, v any) ([]byte, error) {
+	if base := arrayBase(typ); base != typ {
+		items, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s wants an array, got %T", ErrInvalidTypedData, typ, v)
+		}
+		if n := typ[len(base)+1 : len(typ)-1]; n != "" && n != strconv.Itoa(len(items)) {
+			return nil, fmt.Errorf("%w: %s has %d elements", ErrInvalidTypedData, typ, len(items))
+		}
+		var parts [][]byte
+		for _, item := range items {
+			word, err := td.encodeValue(base, item)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, word)
+		}
+		h := Keccak256(parts...)
+		return h[:], nil
+	}
+	if _, ok := td.Types[typ]; ok {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s wants an object, got %T", ErrInvalidTypedData, typ, v)
+		}
+		h, err := td.HashStruct(typ, m)
+		return h[:], err
+	}
+
+	switch {
+	case typ == "string":
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: string wants a string, got %T", ErrInvalidTypedData, v)
+		}
+		h := Keccak256([]byte(s))
+		return h[:], nil
+	case typ == "bytes":
+		b, err := typedBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		h := Keccak256(b)
+		return h[:], nil
+	case typ == "bool":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: bool wants true or false, got %T", ErrInvalidTypedData, v)
+		}
+		return EncodeBool(b), nil
+	case typ == "address":
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: address wants a string, got %T", ErrInvalidTypedData, v)
+		}
+		return EncodeAddress(s)
+	case strings.HasPrefix(typ, "bytes"):
+		n, err := strconv.Atoi(typ[len("bytes"):])
+		if err != nil || n < 1 || n > 32 {
+			return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidTypedData, typ)
+		}
+		b, err := typedBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != n {
+			return nil, fmt.Errorf("%w: %s wants %d bytes, got %d", ErrInvalidTypedData, typ, n, len(b))
+		}
+		word := make([]byte, 32)
+		copy(word, b)
+		return word, nil
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		return encodeInteger(typ, v)
+	}
+	return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidTypedData, typ)
+}
+
+// arrayBase strips one trailing [] or [n] from typ.
+func arrayBase(typ string) string {
+	if !strings.HasSuffix(typ, "]") {
+		return typ
+	}
+	if i := strings.LastIndexByte(typ, '['); i > 0 {
+		return typ[:i]
+	}
+	return typ
+}
+
+func typedBytes(v any) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: bytes want a 0x-hex string, got %T", ErrInvalidTypedData, v)
+	}
+	raw, ok := strings.CutPrefix(s, "0x")
+	b, err := hex.DecodeString(raw)
+	if !ok || err != nil {
+		return nil, fmt.Errorf("%w: %q is not 0x-hex", ErrInvalidTypedData, s)
+	}
+	return b, nil
+}
+
+// encodeInteger encodes a uintN or intN given as a JSON number or a
+// decimal or 0x-hex string; negative values are two's complement.
+func encodeInteger(typ string, v any) ([]byte, error) {
+	signed := strings.HasPrefix(typ, "int")
+	bits, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(typ, "u"), "int"))
+	if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidTypedData, typ)
+	}
+	var s string
+	switch x := v.(type) {
+	case json.Number:
+		s = x.String()
+	case string:
+		s = x
+	default:
+		return nil, fmt.Errorf("%w: %s wants a number, got %T", ErrInvalidTypedData, typ, v)
+	}
+	n, ok := new(big.Int), false
+	if digits, isHex := strings.CutPrefix(s, "0x"); isHex {
+		n, ok = n.SetString(digits, 16)
+	} else {
+		n, ok = n.SetString(s, 10)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not an integer", ErrInvalidTypedData, s)
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	if signed {
+		limit.Rsh(limit, 1)
+		if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return nil, fmt.Errorf("%w: %s out of range for %s", ErrInvalidTypedData, s, typ)
+		}
+		if n.Sign() < 0 {
+			n.Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+	} else if n.Sign() < 0 || n.Cmp(limit) >= 0 {
+		return nil, fmt.Errorf("%w: %s out of range for %s", ErrInvalidTypedData, s, typ)
+	}
+	return EncodeUint(n), nil
+}
+
+// MessageDigest returns the digest of message as typ, one of td's types,
+// signed on domain d.
+func (td *TypedData) MessageDigest(d Domain, typ string, message map[string]any) ([32]byte, error) {
+	sep, err := d.Separator()
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("domain: %w", err)
+	}
+	structHash, err := td.HashStruct(typ, message)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return Digest(sep, structHash), nil
+}
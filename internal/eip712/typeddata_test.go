@@ -0,0 +1,104 @@
+package eip712
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// mailTypedData is the example from the EIP-712 specification.
+const mailTypedData = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Person": [
+      {"name": "name", "type": "string"},
+      {"name": "wallet", "type": "address"}
+    ],
+    "Mail": [
+      {"name": "from", "type": "Person"},
+      {"name": "to", "type": "Person"},
+      {"name": "contents", "type": "string"}
+    ]
+  },
+  "primaryType": "Mail",
+  "domain": {
+    "name": "Ether Mail",
+    "version": "1",
+    "chainId": 1,
+    "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+  },
+  "message": {
+    "from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+    "to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+    "contents": "Hello, Bob!"
+  }
+}`
+
+func TestTypedDataDigest(t *testing.T) {
+	td, err := Parse([]byte(mailTypedData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc, _ := td.EncodeType("Mail"); enc != "Mail(Person from,Person to,string contents)Person(string name,address wallet)" {
+		t.Errorf("encodeType = %s", enc)
+	}
+	sep, err := td.Separator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(sep[:]); got != "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f" {
+		t.Errorf("separator = %s", got)
+	}
+	digest, err := td.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(digest[:]); got != "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2" {
+		t.Errorf("digest = %s", got)
+	}
+
+	// A Domain hashes the same as the payload's EIP712Domain.
+	d := Domain{Name: "Ether Mail", Version: "1", ChainID: 1, VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"}
+	if got, err := d.Separator(); err != nil || got != sep {
+		t.Errorf("Domain.Separator = %x, %v; want %x", got, err, sep)
+	}
+}
+
+func TestTypedDataInvalid(t *testing.T) {
+	for name, src := range map[string]string{
+		"no domain type": `{"types": {"Mail": []}, "primaryType": "Mail"}`,
+		"domain primary": `{"types": {"EIP712Domain": []}, "primaryType": "EIP712Domain"}`,
+		"not json":       `{"types":`,
+	} {
+		if _, err := Parse([]byte(src)); !errors.Is(err, ErrInvalidTypedData) {
+			t.Errorf("%s: expected ErrInvalidTypedData, got %v", name, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		typ string
+		v   any
+	}{
+		{"uint8", "256"},
+		{"uint256", "-1"},
+		{"int8", "128"},
+		{"int8", "-129"},
+		{"uint7", "1"},
+		{"uint256", "1.5"},
+		{"bytes4", "0x0102"},
+		{"address", "0x1234"},
+		{"bool", "true"},
+	} {
+		if _, err := (&TypedData{}).encodeValue(tc.typ, tc.v); err == nil {
+			t.Errorf("%s %v: expected an error", tc.typ, tc.v)
+		}
+	}
+	if word, err := encodeInteger("int8", "-1"); err != nil || hex.EncodeToString(word) != "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" {
+		t.Errorf("int8 -1 = %x, %v", word, err)
+	}
+}
@@ -0,0 +1,110 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+var ErrNoRate = errors.New("no fx rate")
+
+// USD is the currency every rate is quoted against.
+const USD = "USD"
+
+// Rate is the price of one US dollar in Currency.
+type Rate struct {
+	Currency string    `json:"currency"`
+	PerUSD   float64   `json:"per_usd"`
+	At       time.Time `json:"at"`      // as of, per the source; the fetch for live rates
+	Fetched  time.Time `json:"fetched"` // when the oracle fetched it
+	Source   string    `json:"source"`
+}
+
+// Convert returns usd in r's currency.
+func (r Rate) Convert(usd float64) float64 { return usd * r.PerUSD }
+
+// Source fetches current USD reference rates. A rate with a zero At is
+// taken to be as of the fetch.
+type Source interface {
+	Name() string
+	Fetch(ctx context.Context) ([]Rate, error)
+}
+
+// Oracle caches a Source's rates and refreshes them on a schedule, so
+// reports can convert USD amounts into the operator's home currency and
+// say when the rate was taken.
+type Oracle struct {
+	src   Source
+	clock clock.Clock
+
+	// OnError receives each failed refresh; optional. The previous rates
+	// stay in use.
+	OnError func(error)
+
+	mu    sync.RWMutex
+	rates map[string]Rate
+}
+
+// NewOracle creates an Oracle over src. A nil clk uses the wall clock.
+func NewOracle(src Source, clk clock.Clock) *Oracle {
+	return &Oracle{src: src, clock: clock.Or(clk), rates: make(map[string]Rate)}
+}
+
+// Refresh fetches the source's current rates.
+func (o *Oracle) Refresh(ctx context.Context) error {
+	rates, err := o.src.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("fx %s: %w", o.src.Name(), err)
+	}
+	now := o.clock.Now()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, r := range rates {
+		r.Currency = strings.ToUpper(r.Currency)
+		r.Fetched, r.Source = now, o.src.Name()
+		if r.At.IsZero() {
+			r.At = now
+		}
+		o.rates[r.Currency] = r
+	}
+	return nil
+}
+
+// Run refreshes every interval until ctx is done. A non-positive interval
+// disables refreshing.
+func (o *Oracle) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.clock.After(interval):
+			if err := o.Refresh(ctx); err != nil && o.OnError != nil {
+				o.OnError(err)
+			}
+		}
+	}
+}
+
+// Rate returns the latest rate for currency. USD is always 1.
+func (o *Oracle) Rate(currency string) (Rate, error) {
+	currency = strings.ToUpper(currency)
+	if currency == USD {
+		now := o.clock.Now()
+		return Rate{Currency: USD, PerUSD: 1, At: now, Fetched: now, Source: "identity"}, nil
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	r, ok := o.rates[currency]
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: USD→%s from %s", ErrNoRate, currency, o.src.Name())
+	}
+	return r, nil
+}
@@ -0,0 +1,80 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+type fakeSource struct {
+	rates []Rate
+	err   error
+	calls int
+}
+
+func (*fakeSource) Name() string { return "fake" }
+
+func (s *fakeSource) Fetch(context.Context) ([]Rate, error) {
+	s.calls++
+	return s.rates, s.err
+}
+
+func TestOracleRate(t *testing.T) {
+	start := time.Date(2026, 3, 2, 17, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	asOf := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	src := &fakeSource{rates: []Rate{{Currency: "eur", PerUSD: 0.8, At: asOf}, {Currency: "JPY", PerUSD: 150}}}
+	o := NewOracle(src, clk)
+
+	if _, err := o.Rate("EUR"); !errors.Is(err, ErrNoRate) {
+		t.Errorf("before refresh: expected ErrNoRate, got %v", err)
+	}
+	if r, err := o.Rate("usd"); err != nil || r.Convert(12.5) != 12.5 {
+		t.Errorf("USD = %+v, %v", r, err)
+	}
+	if err := o.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	eur, err := o.Rate("eur")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eur.Convert(10) != 8 || !eur.At.Equal(asOf) || !eur.Fetched.Equal(start) || eur.Source != "fake" {
+		t.Errorf("EUR = %+v", eur)
+	}
+	// A live rate is as of its fetch.
+	if jpy, _ := o.Rate("JPY"); !jpy.At.Equal(start) {
+		t.Errorf("JPY as of %s", jpy.At)
+	}
+
+	// A failed refresh keeps the last good rates.
+	src.err = errors.New("down")
+	if err := o.Refresh(context.Background()); err == nil {
+		t.Error("expected refresh error")
+	}
+	if _, err := o.Rate("EUR"); err != nil {
+		t.Errorf("rate lost after failed refresh: %v", err)
+	}
+}
+
+func TestOracleRun(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC))
+	src := &fakeSource{err: errors.New("down")}
+	o := NewOracle(src, clk)
+	failed := make(chan error, 1)
+	o.OnError = func(err error) { failed <- err }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go o.Run(ctx, time.Hour)
+	for clk.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clk.Advance(time.Hour)
+	if err := <-failed; err == nil || src.calls != 1 {
+		t.Errorf("refresh error %v after %d calls", err, src.calls)
+	}
+}
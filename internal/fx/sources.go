@@ -0,0 +1,162 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Default endpoints.
+const (
+	ECBURL      = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+	CoinbaseURL = "https://api.coinbase.com/v2/exchange-rates?currency=USD"
+)
+
+// NewSource returns the named source ("ecb" or "coinbase") at its default
+// endpoint. A nil client uses http.DefaultClient.
+func NewSource(name string, client *http.Client) (Source, error) {
+	switch name {
+	case "ecb":
+		return NewECB(ECBURL, client), nil
+	case "coinbase":
+		return NewCoinbase(CoinbaseURL, client), nil
+	}
+	return nil, fmt.Errorf("unknown fx source %q (want ecb or coinbase)", name)
+}
+
+// ECB reads the European Central Bank's daily euro reference rates and
+// crosses them through EUR/USD. Rates are published once per working day.
+type ECB struct {
+	url    string
+	client *http.Client
+}
+
+// NewECB creates an ECB source reading url. A nil client uses
+// http.DefaultClient.
+func NewECB(url string, client *http.Client) *ECB {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &ECB{url: url, client: client}
+}
+
+// Name implements Source.
+func (*ECB) Name() string { return "ecb" }
+
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// Fetch implements Source.
+func (e *ECB) Fetch(ctx context.Context) ([]Rate, error) {
+	data, err := get(ctx, e.client, e.url)
+	if err != nil {
+		return nil, err
+	}
+	var env ecbEnvelope
+	if err := xml.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	if len(env.Days) == 0 {
+		return nil, fmt.Errorf("no rates published")
+	}
+	day := env.Days[0]
+	at, err := time.Parse(time.DateOnly, day.Time)
+	if err != nil {
+		return nil, fmt.Errorf("rate date %q: %w", day.Time, err)
+	}
+	perEUR := make(map[string]float64, len(day.Rates))
+	for _, r := range day.Rates {
+		v, err := strconv.ParseFloat(r.Rate, 64)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("rate %s=%q is not a positive number", r.Currency, r.Rate)
+		}
+		perEUR[r.Currency] = v
+	}
+	usd, ok := perEUR[USD]
+	if !ok {
+		return nil, fmt.Errorf("no EUR/USD rate to cross through")
+	}
+	rates := []Rate{{Currency: "EUR", PerUSD: 1 / usd, At: at}}
+	for cur, v := range perEUR {
+		if cur != USD {
+			rates = append(rates, Rate{Currency: cur, PerUSD: v / usd, At: at})
+		}
+	}
+	return rates, nil
+}
+
+// Coinbase reads Coinbase's USD exchange rates. They are live, so each
+// is as of the fetch.
+type Coinbase struct {
+	url    string
+	client *http.Client
+}
+
+// NewCoinbase creates a Coinbase source reading url. A nil client uses
+// http.DefaultClient.
+func NewCoinbase(url string, client *http.Client) *Coinbase {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Coinbase{url: url, client: client}
+}
+
+// Name implements Source.
+func (*Coinbase) Name() string { return "coinbase" }
+
+// Fetch implements Source.
+func (c *Coinbase) Fetch(ctx context.Context) ([]Rate, error) {
+	data, err := get(ctx, c.client, c.url)
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		Data struct {
+			Currency string            `json:"currency"`
+			Rates    map[string]string `json:"rates"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	if body.Data.Currency != USD {
+		return nil, fmt.Errorf("rates quoted in %q, want USD", body.Data.Currency)
+	}
+	rates := make([]Rate, 0, len(body.Data.Rates))
+	for cur, raw := range body.Data.Rates {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 {
+			continue // not every listed asset has a usable rate
+		}
+		rates = append(rates, Rate{Currency: cur, PerUSD: v})
+	}
+	return rates, nil
+}
+
+func get(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: status %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
@@ -0,0 +1,79 @@
+package fx
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func serve(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func byCurrency(rates []Rate) map[string]Rate {
+	m := make(map[string]Rate, len(rates))
+	for _, r := range rates {
+		m[r.Currency] = r
+	}
+	return m
+}
+
+func TestECBFetch(t *testing.T) {
+	srv := serve(t, `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2026-03-02">
+			<Cube currency="USD" rate="1.25"/>
+			<Cube currency="GBP" rate="0.85"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`)
+	rates, err := NewECB(srv.URL, srv.Client()).Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := byCurrency(rates)
+	if len(m) != 2 || m["EUR"].PerUSD != 0.8 || math.Abs(m["GBP"].PerUSD-0.68) > 1e-12 {
+		t.Errorf("rates = %+v", m)
+	}
+	if !m["EUR"].At.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("as of %s", m["EUR"].At)
+	}
+}
+
+func TestCoinbaseFetch(t *testing.T) {
+	srv := serve(t, `{"data":{"currency":"USD","rates":{"EUR":"0.92","JPY":"149.5","XYZ":""}}}`)
+	rates, err := NewCoinbase(srv.URL, srv.Client()).Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := byCurrency(rates)
+	if len(m) != 2 || m["EUR"].PerUSD != 0.92 || m["JPY"].PerUSD != 149.5 {
+		t.Errorf("rates = %+v", m)
+	}
+
+	srv = serve(t, `{"data":{"currency":"EUR","rates":{"USD":"1.08"}}}`)
+	if _, err := NewCoinbase(srv.URL, srv.Client()).Fetch(context.Background()); err == nil {
+		t.Error("expected an error for rates not quoted in USD")
+	}
+}
+
+func TestNewSource(t *testing.T) {
+	for _, name := range []string{"ecb", "coinbase"} {
+		if src, err := NewSource(name, nil); err != nil || src.Name() != name {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if _, err := NewSource("oanda", nil); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+}
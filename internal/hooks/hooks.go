@@ -0,0 +1,173 @@
+package hooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body.
+const SignatureHeader = "X-Caesar-Signature"
+
+// maxEventBytes bounds the webhook body size.
+const maxEventBytes = 64 << 10
+
+// Event is an inbound news/announcement notification.
+type Event struct {
+	Source   string    `json:"source"`
+	Headline string    `json:"headline"`
+	Body     string    `json:"body"`
+	At       time.Time `json:"at"`
+}
+
+// Action is what a matching rule does.
+type Action int
+
+const (
+	ActionHalt Action = iota + 1
+	ActionResume
+)
+
+// Rule maps events to actions on market groups. An event matches when its
+// source equals Source (empty matches any) and its headline or body
+// contains any of Keywords (case-insensitive).
+type Rule struct {
+	Name     string
+	Source   string
+	Keywords []string
+	Action   Action
+	Groups   []string
+}
+
+func (r Rule) matches(e Event) bool {
+	if r.Source != "" && !strings.EqualFold(r.Source, e.Source) {
+		return false
+	}
+	text := strings.ToLower(e.Headline + "\n" + e.Body)
+	for _, k := range r.Keywords {
+		if strings.Contains(text, strings.ToLower(k)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Halter applies trading halts to market groups.
+type Halter interface {
+	Halt(group, reason string)
+	Resume(group string)
+}
+
+// Handler is the webhook-in endpoint. Requests must be POSTs signed with
+// the shared secret; unsigned or mis-signed requests are rejected before
+// the body is parsed.
+type Handler struct {
+	secret []byte
+	rules  []Rule
+	halter Halter
+}
+
+// NewHandler creates a webhook handler evaluating rules against each event.
+func NewHandler(secret []byte, rules []Rule, halter Halter) *Handler {
+	return &Handler{secret: secret, rules: rules, halter: halter}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxEventBytes+1))
+	if err != nil || len(body) > maxEventBytes {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	if !h.validSignature(body, r.Header.Get(SignatureHeader)) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var e Event
+	if err := json.Unmarshal(body, &e); err != nil {
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+
+	matched := h.Dispatch(e)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{"matched": matched})
+}
+
+// Dispatch evaluates e against every rule, applies the actions of those
+// that match, and returns the names of the matched rules.
+func (h *Handler) Dispatch(e Event) []string {
+	matched := []string{}
+	for _, rule := range h.rules {
+		if !rule.matches(e) {
+			continue
+		}
+		matched = append(matched, rule.Name)
+		for _, g := range rule.Groups {
+			switch rule.Action {
+			case ActionHalt:
+				h.halter.Halt(g, rule.Name+": "+e.Headline)
+			case ActionResume:
+				h.halter.Resume(g)
+			}
+		}
+	}
+	return matched
+}
+
+func (h *Handler) validSignature(body []byte, sig string) bool {
+	want, err := hex.DecodeString(sig)
+	if err != nil || len(h.secret) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), want)
+}
+
+// HaltRegistry is an in-memory Halter consulted by pre-trade checks.
+type HaltRegistry struct {
+	mu     sync.RWMutex
+	halted map[string]string // group → reason
+}
+
+// NewHaltRegistry creates an empty registry.
+func NewHaltRegistry() *HaltRegistry {
+	return &HaltRegistry{halted: make(map[string]string)}
+}
+
+// Halt implements Halter.
+func (r *HaltRegistry) Halt(group, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.halted[group] = reason
+}
+
+// Resume implements Halter.
+func (r *HaltRegistry) Resume(group string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.halted, group)
+}
+
+// IsHalted reports whether group is halted and why.
+func (r *HaltRegistry) IsHalted(group string) (bool, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	reason, ok := r.halted[group]
+	return ok, reason
+}
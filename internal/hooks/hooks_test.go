@@ -0,0 +1,68 @@
+package hooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHandlerHaltsMatchingGroups(t *testing.T) {
+	secret := []byte("s3cret")
+	reg := NewHaltRegistry()
+	h := NewHandler(secret, []Rule{
+		{Name: "fed", Source: "reuters", Keywords: []string{"FOMC"}, Action: ActionHalt, Groups: []string{"rates"}},
+		{Name: "other", Keywords: []string{"election"}, Action: ActionHalt, Groups: []string{"politics"}},
+	}, reg)
+
+	body := []byte(`{"source":"Reuters","headline":"FOMC statement released early"}`)
+	req := httptest.NewRequest(http.MethodPost, "/hooks/news", bytes.NewReader(body))
+	req.Header.Set(SignatureHeader, sign(secret, body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if halted, reason := reg.IsHalted("rates"); !halted || reason == "" {
+		t.Errorf("expected rates halted with reason, got %v %q", halted, reason)
+	}
+	if halted, _ := reg.IsHalted("politics"); halted {
+		t.Errorf("politics should not be halted")
+	}
+
+	h.rules = append(h.rules, Rule{Name: "clear", Keywords: []string{"all clear"}, Action: ActionResume, Groups: []string{"rates"}})
+	h.Dispatch(Event{Headline: "All clear on rates"})
+	if halted, _ := reg.IsHalted("rates"); halted {
+		t.Errorf("expected rates resumed")
+	}
+}
+
+func TestHandlerRejectsBadSignature(t *testing.T) {
+	reg := NewHaltRegistry()
+	h := NewHandler([]byte("s3cret"), []Rule{
+		{Name: "any", Keywords: []string{"x"}, Action: ActionHalt, Groups: []string{"g"}},
+	}, reg)
+
+	body := []byte(`{"headline":"x"}`)
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set(SignatureHeader, sign([]byte("wrong"), body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+	if halted, _ := reg.IsHalted("g"); halted {
+		t.Errorf("unsigned event must not trigger actions")
+	}
+}
@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultLocale is used when no locale is configured or the configured one
+// has no catalog.
+const DefaultLocale = "en"
+
+// Catalog resolves message keys to localized, formatted strings.
+type Catalog struct {
+	locale   string
+	messages map[string]string
+}
+
+// New returns the catalog for locale. Region suffixes are ignored
+// ("es-MX" → "es") and unknown locales fall back to DefaultLocale.
+func New(locale string) *Catalog {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalogs[lang]; !ok {
+		lang = DefaultLocale
+	}
+	return &Catalog{locale: lang, messages: catalogs[lang]}
+}
+
+// Locale returns the resolved locale of the catalog.
+func (c *Catalog) Locale() string {
+	return c.locale
+}
+
+// T formats the message for key with args. Keys missing from the locale
+// fall back to DefaultLocale, then to the key itself so a missing
+// translation is visible rather than silent.
+func (c *Catalog) T(key string, args ...any) string {
+	msg, ok := c.messages[key]
+	if !ok {
+		if msg, ok = catalogs[DefaultLocale][key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Locales lists the available locales.
+func Locales() []string {
+	out := make([]string, 0, len(catalogs))
+	for l := range catalogs {
+		out = append(out, l)
+	}
+	sort.Strings(out)
+	return out
+}
+
+var catalogs = map[string]map[string]string{
+	"en": en,
+	"es": es,
+}
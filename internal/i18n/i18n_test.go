@@ -0,0 +1,30 @@
+package i18n
+
+import "testing"
+
+func TestCatalogsCoverEnglishKeys(t *testing.T) {
+	for _, locale := range Locales() {
+		for key := range catalogs[locale] {
+			if _, ok := en[key]; !ok {
+				t.Errorf("%s: key %q missing from en catalog", locale, key)
+			}
+		}
+		for key := range en {
+			if _, ok := catalogs[locale][key]; !ok {
+				t.Errorf("%s: missing translation for %q", locale, key)
+			}
+		}
+	}
+}
+
+func TestCatalogResolution(t *testing.T) {
+	if got := New("es-MX").T(CtlBookExported, 3); got != "3 instantáneas exportadas" {
+		t.Errorf("unexpected es message: %q", got)
+	}
+	if got := New("fr").Locale(); got != DefaultLocale {
+		t.Errorf("unknown locale should fall back to %s, got %s", DefaultLocale, got)
+	}
+	if got := New("en").T("no.such.key"); got != "no.such.key" {
+		t.Errorf("missing key should render as itself, got %q", got)
+	}
+}
@@ -0,0 +1,219 @@
+package i18n
+
+// Message keys. Every key must exist in the "en" catalog; other locales may
+// lag behind and fall back to English.
+const (
+	CaesarStarting     = "caesar.starting"
+	CaesarShuttingDown = "caesar.shutting_down"
+
+	SignerStarting     = "signer.starting"
+	SignerReady        = "signer.ready"
+	SignerProfile      = "signer.profile"
+	SignerShuttingDown = "signer.shutting_down"
+	SignerStopped      = "signer.stopped"
+	SignerAnomaly      = "signer.anomaly"
+	SignerCanaryTrip   = "signer.canary_trip"
+	SignerOfflineDone  = "signer.offline_done"
+	SignerBundle       = "signer.bundle"
+
+	CtlUsage                = "ctl.usage"
+	CtlCommands             = "ctl.commands"
+	CtlUnknownCommand       = "ctl.unknown_command"
+	CtlCommandFailed        = "ctl.command_failed"
+	CtlBookUsage            = "ctl.book.usage"
+	CtlBookExported         = "ctl.book.exported"
+	CtlPolicyUsage          = "ctl.policy.usage"
+	CtlPolicyValid          = "ctl.policy.valid"
+	CtlPolicySummary        = "ctl.policy.summary"
+	CtlApprovalsUsage       = "ctl.approvals.usage"
+	CtlApprovalsNone        = "ctl.approvals.none"
+	CtlApprovalsSet         = "ctl.approvals.set"
+	CtlElevateUsage         = "ctl.elevate.usage"
+	CtlElevationGranted     = "ctl.elevate.granted"
+	CtlElevationEnded       = "ctl.elevate.ended"
+	CtlRenewUsage           = "ctl.renew.usage"
+	CtlSessionRenewed       = "ctl.renew.renewed"
+	CtlSessionsUsage        = "ctl.sessions.usage"
+	CtlSessionPaused        = "ctl.sessions.paused"
+	CtlSessionResumed       = "ctl.sessions.resumed"
+	CtlSessionTightened     = "ctl.sessions.tightened"
+	CtlReleaseUsage         = "ctl.release.usage"
+	CtlValueReleased        = "ctl.release.released"
+	CtlRecoveryUsage        = "ctl.recovery.usage"
+	CtlRecoveryClean        = "ctl.recovery.clean"
+	CtlRecoveryUnclean      = "ctl.recovery.unclean"
+	CtlRecoveryPending      = "ctl.recovery.pending"
+	CtlRecoveryAckedBy      = "ctl.recovery.acked_by"
+	CtlRecoveryAcknowledged = "ctl.recovery.acknowledged"
+	CtlMarketsUsage         = "ctl.markets.usage"
+	CtlMarketsNonePaused    = "ctl.markets.none_paused"
+	CtlMarketPaused         = "ctl.markets.paused"
+	CtlMarketResumed        = "ctl.markets.resumed"
+	CtlAnalyticsUsage       = "ctl.analytics.usage"
+	CtlAnalyticsSince       = "ctl.analytics.since"
+	CtlOfflineUsage         = "ctl.offline.usage"
+	CtlOfflineExported      = "ctl.offline.exported"
+	CtlOfflineImported      = "ctl.offline.imported"
+	CtlAuditUsage           = "ctl.audit.usage"
+	CtlAuditReplayOK        = "ctl.audit.replay_ok"
+	CtlAuditVerified        = "ctl.audit.verified"
+	CtlAuditExported        = "ctl.audit.exported"
+	CtlFXRate               = "ctl.fx.rate"
+	CtlClobAuthUsage        = "ctl.clob_auth.usage"
+	CtlConfigUsage          = "ctl.config.usage"
+	CtlConfigMigrated       = "ctl.config.migrated"
+	CtlConfigCurrent        = "ctl.config.current"
+	CtlBundleKeygen         = "ctl.config.bundle_keygen"
+	CtlBundleSigned         = "ctl.config.bundle_signed"
+	CtlBundleAccepted       = "ctl.config.bundle_accepted"
+	CtlInitUsage            = "ctl.init.usage"
+	CtlInitWrote            = "ctl.init.wrote"
+	CtlDoctorUsage          = "ctl.doctor.usage"
+	CtlDoctorOK             = "ctl.doctor.ok"
+	CtlDoctorFailed         = "ctl.doctor.failed"
+	CtlAuditPermsUsage      = "ctl.audit_permissions.usage"
+)
+
+var en = map[string]string{
+	CaesarStarting:     "Caesar Trading Terminal starting (env=%s)",
+	CaesarShuttingDown: "Caesar shutting down",
+
+	SignerStarting:     "Caesar Signer starting (env=%s, socket=%s)",
+	SignerProfile:      "Profile %s: signing for chain %d",
+	SignerReady:        "Signer ready — listening on UDS",
+	SignerShuttingDown: "Signer shutting down gracefully...",
+	SignerStopped:      "Signer stopped",
+	SignerAnomaly:      "signer anomaly: kind=%s market=%s detail=%q",
+	SignerCanaryTrip:   "signer ALERT: canary token %s signed; session destroyed",
+	SignerOfflineDone:  "signed %d of %d orders as %s",
+	SignerBundle:       "Config bundle issued %s: %d settings managed, %d policy rules",
+
+	CtlUsage:                "usage: caesarctl [--profile NAME] <command> [flags]",
+	CtlCommands:             "commands:",
+	CtlUnknownCommand:       "caesarctl: unknown command %q",
+	CtlCommandFailed:        "caesarctl %s: %v",
+	CtlBookUsage:            "book export  export recorded book snapshots around a fill",
+	CtlBookExported:         "exported %d snapshots",
+	CtlPolicyUsage:          "policy       validate a risk policy or dry-run sample orders against it",
+	CtlPolicyValid:          "policy %s is valid (%d rules)",
+	CtlPolicySummary:        "%d of %d orders pass",
+	CtlApprovalsUsage:       "approvals    list, approve, or deny pending signer approvals",
+	CtlApprovalsNone:        "no pending approvals",
+	CtlApprovalsSet:         "approval %s: %s",
+	CtlElevateUsage:         "elevate      temporarily relax the approval threshold or policy rules",
+	CtlElevationGranted:     "elevation %s active until %s",
+	CtlElevationEnded:       "elevation ended",
+	CtlRenewUsage:           "renew        extend the signer session after re-authenticating",
+	CtlSessionRenewed:       "session active until %s",
+	CtlSessionsUsage:        "sessions     list the signer's sessions and their limits, or pause, resume or tighten one",
+	CtlSessionPaused:        "session %s is paused; signing is refused until it is resumed",
+	CtlSessionResumed:       "session %s can sign again",
+	CtlSessionTightened:     "session %s now expires %s; limit %s, per order %s, orders %s",
+	CtlReleaseUsage:         "release      credit back the value of a cancelled or unfilled order",
+	CtlValueReleased:        "released %s; %s used",
+	CtlRecoveryUsage:        "recovery     show the report of the last unclean shutdown and acknowledge it",
+	CtlRecoveryClean:        "the last shutdown was clean",
+	CtlRecoveryUnclean:      "unclean shutdown detected at %s",
+	CtlRecoveryPending:      "signing is paused until this report is acknowledged",
+	CtlRecoveryAckedBy:      "acknowledged by %s at %s: %s",
+	CtlRecoveryAcknowledged: "recovery acknowledged at %s; signing resumed",
+	CtlMarketsUsage:         "markets      pause or resume new orders in one market",
+	CtlMarketsNonePaused:    "no markets are paused",
+	CtlMarketPaused:         "new orders in %s are refused until it is resumed",
+	CtlMarketResumed:        "orders in %s are accepted again",
+	CtlAnalyticsUsage:       "analytics    summarize signing activity since the session was activated",
+	CtlAnalyticsSince:       "session activity since %s",
+	CtlOfflineUsage:         "offline      export orders for an air-gapped signer and import its signatures",
+	CtlOfflineExported:      "bundle %s: %d orders",
+	CtlOfflineImported:      "%d of %d orders signed by %s",
+	CtlAuditUsage:           "audit        replay, verify or export a signer audit log",
+	CtlAuditReplayOK:        "%d decisions replayed; all match",
+	CtlAuditVerified:        "%d records chained; %d anchors verified (check token signatures with openssl ts -verify)",
+	CtlAuditExported:        "exported %d decisions",
+	CtlFXRate:               "amounts in %s at 1 USD = %.6g %s (%s, as of %s)",
+	CtlClobAuthUsage:        "clob-auth    sign CLOB L1 authentication headers with the session key",
+	CtlConfigUsage:          "config       upgrade an env file, or sign or accept a config bundle",
+	CtlConfigMigrated:       "%s migrated (%d changes); original kept as %s",
+	CtlConfigCurrent:        "%s is already current",
+	CtlBundleKeygen:         "private key written to %s; keep it offline\ninstall the public key at %s, owned by root and mode 0644:\n%s",
+	CtlBundleSigned:         "%s signed; copy %s alongside it",
+	CtlBundleAccepted:       "%s issued %s accepted; older bundles are refused (recorded in %s)",
+	CtlInitUsage:            "init         set up a config, key backend and signer unit interactively",
+	CtlInitWrote:            "wrote %s",
+	CtlDoctorUsage:          "doctor       check the config against this host",
+	CtlDoctorOK:             "all %d checks passed",
+	CtlDoctorFailed:         "%d of %d checks failed",
+	CtlAuditPermsUsage:      "audit-permissions [FILE...]  check file modes, listeners, memlock and caller access",
+}
+
+var es = map[string]string{
+	CaesarStarting:     "Iniciando Caesar Trading Terminal (entorno=%s)",
+	CaesarShuttingDown: "Caesar se está cerrando",
+
+	SignerStarting:     "Iniciando Caesar Signer (entorno=%s, socket=%s)",
+	SignerProfile:      "Perfil %s: firmando para la cadena %d",
+	SignerReady:        "Signer listo — escuchando en UDS",
+	SignerShuttingDown: "Signer cerrándose de forma ordenada...",
+	SignerStopped:      "Signer detenido",
+	SignerAnomaly:      "anomalía del signer: tipo=%s mercado=%s detalle=%q",
+	SignerCanaryTrip:   "ALERTA del signer: se firmó el token canario %s; sesión destruida",
+	SignerOfflineDone:  "%d de %d órdenes firmadas como %s",
+	SignerBundle:       "Paquete de configuración emitido %s: %d ajustes gestionados, %d reglas de política",
+
+	CtlUsage:                "uso: caesarctl [--profile NOMBRE] <comando> [opciones]",
+	CtlCommands:             "comandos:",
+	CtlUnknownCommand:       "caesarctl: comando desconocido %q",
+	CtlCommandFailed:        "caesarctl %s: %v",
+	CtlBookUsage:            "book export  exporta instantáneas del libro alrededor de una ejecución",
+	CtlBookExported:         "%d instantáneas exportadas",
+	CtlPolicyUsage:          "policy       valida una política de riesgo o evalúa órdenes de ejemplo en seco",
+	CtlPolicyValid:          "la política %s es válida (%d reglas)",
+	CtlPolicySummary:        "%d de %d órdenes aprobadas",
+	CtlApprovalsUsage:       "approvals    lista, aprueba o rechaza aprobaciones pendientes del signer",
+	CtlApprovalsNone:        "no hay aprobaciones pendientes",
+	CtlApprovalsSet:         "aprobación %s: %s",
+	CtlElevateUsage:         "elevate      relaja temporalmente el umbral de aprobación o reglas de política",
+	CtlElevationGranted:     "elevación %s activa hasta %s",
+	CtlElevationEnded:       "elevación finalizada",
+	CtlRenewUsage:           "renew        extiende la sesión del signer tras volver a autenticarse",
+	CtlSessionRenewed:       "sesión activa hasta %s",
+	CtlSessionsUsage:        "sessions     lista las sesiones del signer y sus límites, o pausa, reanuda o restringe una",
+	CtlSessionPaused:        "la sesión %s está en pausa; la firma se rechaza hasta reanudarla",
+	CtlSessionResumed:       "la sesión %s puede firmar de nuevo",
+	CtlSessionTightened:     "la sesión %s expira ahora %s; límite %s, por orden %s, órdenes %s",
+	CtlReleaseUsage:         "release      devuelve el valor de una orden cancelada o no ejecutada",
+	CtlValueReleased:        "liberado %s; %s usado",
+	CtlRecoveryUsage:        "recovery     muestra el informe del último apagado no limpio y lo confirma",
+	CtlRecoveryClean:        "el último apagado fue limpio",
+	CtlRecoveryUnclean:      "apagado no limpio detectado a las %s",
+	CtlRecoveryPending:      "la firma está en pausa hasta confirmar este informe",
+	CtlRecoveryAckedBy:      "confirmado por %s a las %s: %s",
+	CtlRecoveryAcknowledged: "recuperación confirmada a las %s; firma reanudada",
+	CtlMarketsUsage:         "markets      pausa o reanuda las órdenes nuevas en un mercado",
+	CtlMarketsNonePaused:    "no hay mercados en pausa",
+	CtlMarketPaused:         "las órdenes nuevas en %s se rechazan hasta reanudarlo",
+	CtlMarketResumed:        "las órdenes en %s se aceptan de nuevo",
+	CtlAnalyticsUsage:       "analytics    resume la actividad de firma desde que se activó la sesión",
+	CtlAnalyticsSince:       "actividad de la sesión desde %s",
+	CtlOfflineUsage:         "offline      exporta órdenes para un signer aislado e importa sus firmas",
+	CtlOfflineExported:      "paquete %s: %d órdenes",
+	CtlOfflineImported:      "%d de %d órdenes firmadas por %s",
+	CtlAuditUsage:           "audit        reproduce, verifica o exporta un registro de auditoría del signer",
+	CtlAuditReplayOK:        "%d decisiones reproducidas; todas coinciden",
+	CtlAuditVerified:        "%d registros encadenados; %d anclajes verificados (verifique las firmas con openssl ts -verify)",
+	CtlAuditExported:        "%d decisiones exportadas",
+	CtlFXRate:               "importes en %s a 1 USD = %.6g %s (%s, a fecha de %s)",
+	CtlClobAuthUsage:        "clob-auth    firma las cabeceras de autenticación L1 del CLOB con la clave de sesión",
+	CtlConfigUsage:          "config       actualiza un archivo env, o firma o acepta un paquete de configuración",
+	CtlConfigMigrated:       "%s migrado (%d cambios); original guardado como %s",
+	CtlConfigCurrent:        "%s ya está al día",
+	CtlBundleKeygen:         "clave privada escrita en %s; guárdala fuera de línea\ninstala la clave pública en %s, propiedad de root y con modo 0644:\n%s",
+	CtlBundleSigned:         "%s firmado; copia %s junto a él",
+	CtlBundleAccepted:       "%s emitido %s aceptado; se rechazan paquetes anteriores (registrado en %s)",
+	CtlInitUsage:            "init         configurar interactivamente config, clave y unidad del firmador",
+	CtlInitWrote:            "escrito %s",
+	CtlDoctorUsage:          "doctor       comprobar la configuración en este equipo",
+	CtlDoctorOK:             "las %d comprobaciones pasaron",
+	CtlDoctorFailed:         "%d de %d comprobaciones fallaron",
+	CtlAuditPermsUsage:      "audit-permissions [ARCHIVO...]  revisar permisos, escuchas, memlock y acceso de clientes",
+}
@@ -0,0 +1,259 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+// ContentType is the Prometheus text exposition format served by Feed.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Feed tracks exchange connectivity for SLO alerting: WebSocket uptime and
+// reconnects, how long since each subscribed market's book last changed,
+// and REST error rates. It serves them in the Prometheus text format, so a
+// feed that is connected but silently stale still shows up as an ageing
+// book.
+type Feed struct {
+	clock clock.Clock
+
+	mu         sync.Mutex
+	observing  bool // set by the first SetConnected
+	connected  bool
+	lastChange time.Time
+	upTime     time.Duration
+	observed   time.Duration
+	reconnects uint64
+	lastBook   map[string]time.Time
+	restTotal  map[string]uint64
+	restErrors map[string]uint64
+	resyncs    map[string]uint64 // by reason
+	conns      map[string]*connStats
+	everUp     bool
+}
+
+// NewFeed creates an empty Feed. A nil clk uses the wall clock.
+func NewFeed(clk clock.Clock) *Feed {
+	return &Feed{
+		clock:      clock.Or(clk),
+		lastBook:   make(map[string]time.Time),
+		restTotal:  make(map[string]uint64),
+		restErrors: make(map[string]uint64),
+		resyncs:    make(map[string]uint64),
+		conns:      make(map[string]*connStats),
+	}
+}
+
+// SetConnected records a WebSocket state change. Every connect after the
+// first counts as a reconnect.
+func (f *Feed) SetConnected(up bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.accrueLocked(f.clock.Now())
+	if up && !f.connected {
+		if f.everUp {
+			f.reconnects++
+		}
+		f.everUp = true
+	}
+	f.connected = up
+	f.observing = true
+}
+
+// BookUpdated records a book change for token at the given time.
+func (f *Feed) BookUpdated(token string, at time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if at.After(f.lastBook[token]) {
+		f.lastBook[token] = at
+	}
+}
+
+// Subscribed starts tracking token's book age from now, so a market that
+// never receives an update still reports a growing age.
+func (f *Feed) Subscribed(token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.lastBook[token]; !ok {
+		f.lastBook[token] = f.clock.Now()
+	}
+}
+
+// Unsubscribed stops reporting token's book age.
+func (f *Feed) Unsubscribed(token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.lastBook, token)
+}
+
+// BookResynced records a book re-fetched after a sequence gap or hash
+// mismatch. Pass it as book.Sequencer's OnResync.
+func (f *Feed) BookResynced(_, reason string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resyncs[reason]++
+}
+
+// RESTResult records one REST call to host; err marks it failed.
+func (f *Feed) RESTResult(host string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.restTotal[host]++
+	if err != nil {
+		f.restErrors[host]++
+	}
+}
+
+// connStats counts how requests to a host obtained their connection.
+type connStats struct {
+	reused uint64
+	fresh  uint64
+	dial   time.Duration // total wait for fresh connections
+}
+
+// ConnResult records how a REST request to host got its connection: a
+// reused idle one, or a fresh dial that took wait (TCP, TLS and any
+// queueing). A falling reuse rate means submits are paying for cold
+// handshakes.
+func (f *Feed) ConnResult(host string, reused bool, wait time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c := f.conns[host]
+	if c == nil {
+		c = &connStats{}
+		f.conns[host] = c
+	}
+	if reused {
+		c.reused++
+		return
+	}
+	c.fresh++
+	c.dial += wait
+}
+
+// accrueLocked adds the time since the last state change to the uptime
+// counters.
+func (f *Feed) accrueLocked(now time.Time) {
+	if f.observing {
+		d := now.Sub(f.lastChange)
+		f.observed += d
+		if f.connected {
+			f.upTime += d
+		}
+	}
+	f.lastChange = now
+}
+
+// WriteTo writes all metrics in the Prometheus text format.
+func (f *Feed) WriteTo(w io.Writer) (int64, error) {
+	f.mu.Lock()
+	now := f.clock.Now()
+	f.accrueLocked(now)
+	var b strings.Builder
+
+	connected := 0
+	if f.connected {
+		connected = 1
+	}
+	ratio := 0.0
+	if f.observed > 0 {
+		ratio = float64(f.upTime) / float64(f.observed)
+	} else if f.connected {
+		ratio = 1
+	}
+	writeMetric(&b, "caesar_feed_ws_connected", "gauge", "Whether the market data WebSocket is connected.")
+	fmt.Fprintf(&b, "caesar_feed_ws_connected %d\n", connected)
+	writeMetric(&b, "caesar_feed_ws_uptime_ratio", "gauge", "Fraction of observed time the WebSocket was connected.")
+	fmt.Fprintf(&b, "caesar_feed_ws_uptime_ratio %g\n", ratio)
+	writeMetric(&b, "caesar_feed_ws_reconnects_total", "counter", "WebSocket reconnects since start.")
+	fmt.Fprintf(&b, "caesar_feed_ws_reconnects_total %d\n", f.reconnects)
+
+	writeMetric(&b, "caesar_feed_book_age_seconds", "gauge", "Seconds since each subscribed market's book last updated.")
+	for _, token := range sortedKeys(f.lastBook) {
+		fmt.Fprintf(&b, "caesar_feed_book_age_seconds{token_id=%q} %g\n", token, now.Sub(f.lastBook[token]).Seconds())
+	}
+
+	writeMetric(&b, "caesar_feed_book_resyncs_total", "counter", "Books re-fetched after a sequence gap or hash mismatch, by reason.")
+	for _, reason := range sortedKeys(f.resyncs) {
+		fmt.Fprintf(&b, "caesar_feed_book_resyncs_total{reason=%q} %d\n", reason, f.resyncs[reason])
+	}
+
+	writeMetric(&b, "caesar_feed_rest_requests_total", "counter", "REST requests by host.")
+	for _, host := range sortedKeys(f.restTotal) {
+		fmt.Fprintf(&b, "caesar_feed_rest_requests_total{host=%q} %d\n", host, f.restTotal[host])
+	}
+	writeMetric(&b, "caesar_feed_rest_errors_total", "counter", "Failed REST requests by host (transport errors, 429 and 5xx).")
+	for _, host := range sortedKeys(f.restTotal) {
+		fmt.Fprintf(&b, "caesar_feed_rest_errors_total{host=%q} %d\n", host, f.restErrors[host])
+	}
+
+	writeMetric(&b, "caesar_feed_rest_conns_total", "counter", "Connections used by REST requests, by host and whether an idle one was reused.")
+	for _, host := range sortedKeys(f.conns) {
+		fmt.Fprintf(&b, "caesar_feed_rest_conns_total{host=%q,reused=\"true\"} %d\n", host, f.conns[host].reused)
+		fmt.Fprintf(&b, "caesar_feed_rest_conns_total{host=%q,reused=\"false\"} %d\n", host, f.conns[host].fresh)
+	}
+	writeMetric(&b, "caesar_feed_rest_dial_seconds_total", "counter", "Time spent establishing fresh REST connections, by host.")
+	for _, host := range sortedKeys(f.conns) {
+		fmt.Fprintf(&b, "caesar_feed_rest_dial_seconds_total{host=%q} %g\n", host, f.conns[host].dial.Seconds())
+	}
+	f.mu.Unlock()
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP serves the metrics for scraping.
+func (f *Feed) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	f.WriteTo(w)
+}
+
+// Transport wraps base (nil means http.DefaultTransport) so every request
+// made through it, and the connection it used, is counted in f by host.
+// Pass it as an http.Client's Transport to the adapters.
+func (f *Feed) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var getConn time.Time
+		trace := &httptrace.ClientTrace{
+			GetConn: func(string) { getConn = f.clock.Now() },
+			GotConn: func(info httptrace.GotConnInfo) {
+				f.ConnResult(req.URL.Host, info.Reused, f.clock.Now().Sub(getConn))
+			},
+		}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+		resp, err := base.RoundTrip(req)
+		failed := err
+		if err == nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500) {
+			failed = fmt.Errorf("http %d", resp.StatusCode)
+		}
+		f.RESTResult(req.URL.Host, failed)
+		return resp, err
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return fn(req) }
+
+func writeMetric(b *strings.Builder, name, typ, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
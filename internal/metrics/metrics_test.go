@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+func scrape(t *testing.T, f *Feed) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	f.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("content type = %q", ct)
+	}
+	return rec.Body.String()
+}
+
+func TestFeedUptimeAndBookAge(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	f := NewFeed(clk)
+
+	f.SetConnected(true)
+	f.Subscribed("quiet")
+	f.Subscribed("busy")
+	clk.Advance(30 * time.Second)
+	f.BookUpdated("busy", clk.Now())
+	f.BookResynced("busy", "gap")
+	f.SetConnected(false)
+	clk.Advance(10 * time.Second)
+	f.SetConnected(true)
+	clk.Advance(40 * time.Second)
+
+	out := scrape(t, f)
+	for _, want := range []string{
+		"caesar_feed_ws_connected 1\n",
+		"caesar_feed_ws_uptime_ratio 0.875\n",
+		"caesar_feed_ws_reconnects_total 1\n",
+		`caesar_feed_book_age_seconds{token_id="busy"} 50` + "\n",
+		`caesar_feed_book_age_seconds{token_id="quiet"} 80` + "\n",
+		`caesar_feed_book_resyncs_total{reason="gap"} 1` + "\n",
+		"# TYPE caesar_feed_ws_reconnects_total counter\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	f.Unsubscribed("quiet")
+	if strings.Contains(scrape(t, f), `token_id="quiet"`) {
+		t.Error("unsubscribed market still reported")
+	}
+}
+
+func TestFeedTransportCountsErrors(t *testing.T) {
+	codes := []int{http.StatusOK, http.StatusTooManyRequests, http.StatusNotFound, http.StatusBadGateway}
+	i := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(codes[i])
+		i++
+	}))
+	defer srv.Close()
+
+	f := NewFeed(nil)
+	client := &http.Client{Transport: f.Transport(nil)}
+	for range codes {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	out := scrape(t, f)
+	for _, want := range []string{
+		`caesar_feed_rest_requests_total{host="` + host + `"} 4`,
+		`caesar_feed_rest_errors_total{host="` + host + `"} 2`,
+		`caesar_feed_rest_conns_total{host="` + host + `",reused="false"} 1`,
+		`caesar_feed_rest_conns_total{host="` + host + `",reused="true"} 3`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+// Subscription kinds accounted by Traffic.
+const (
+	TrafficWS   = "ws"   // exchange WebSocket subscriptions
+	TrafficGRPC = "grpc" // local gRPC stream subscribers
+)
+
+// rateWindow is the time constant of the exponentially weighted rates:
+// roughly the last minute of traffic.
+const rateWindow = time.Minute
+
+// SubscriptionTraffic is one subscription's usage.
+type SubscriptionTraffic struct {
+	Kind     string
+	ID       string
+	Messages uint64
+	Bytes    uint64
+	MsgRate  float64 // messages per second, recent
+	ByteRate float64 // bytes per second, recent
+}
+
+type trafficKey struct{ kind, id string }
+
+type trafficStats struct {
+	messages, bytes   uint64
+	msgRate, byteRate float64
+	last              time.Time
+}
+
+// decay ages the rates to now.
+func (s *trafficStats) decay(now time.Time) {
+	if dt := now.Sub(s.last); dt > 0 {
+		f := math.Exp(-dt.Seconds() / rateWindow.Seconds())
+		s.msgRate *= f
+		s.byteRate *= f
+	}
+	s.last = now
+}
+
+// Traffic accounts bytes and messages per subscription, so a user on a
+// metered or constrained link can see which market stream or local
+// subscriber is using it.
+type Traffic struct {
+	clock clock.Clock
+
+	mu   sync.Mutex
+	subs map[trafficKey]*trafficStats
+}
+
+// NewTraffic creates an empty Traffic. A nil clk uses the wall clock.
+func NewTraffic(clk clock.Clock) *Traffic {
+	return &Traffic{clock: clock.Or(clk), subs: make(map[trafficKey]*trafficStats)}
+}
+
+// Record counts one message of n bytes on subscription id of kind.
+func (t *Traffic) Record(kind, id string, n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.clock.Now()
+	k := trafficKey{kind, id}
+	s := t.subs[k]
+	if s == nil {
+		s = &trafficStats{last: now}
+		t.subs[k] = s
+	}
+	s.decay(now)
+	s.messages++
+	s.bytes += uint64(n)
+	s.msgRate += 1 / rateWindow.Seconds()
+	s.byteRate += float64(n) / rateWindow.Seconds()
+}
+
+// Remove stops reporting a closed subscription.
+func (t *Traffic) Remove(kind, id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.subs, trafficKey{kind, id})
+}
+
+// Snapshot returns every subscription's usage, heaviest recent byte rate
+// first.
+func (t *Traffic) Snapshot() []SubscriptionTraffic {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.clock.Now()
+	out := make([]SubscriptionTraffic, 0, len(t.subs))
+	for k, s := range t.subs {
+		s.decay(now)
+		out = append(out, SubscriptionTraffic{
+			Kind:     k.kind,
+			ID:       k.id,
+			Messages: s.messages,
+			Bytes:    s.bytes,
+			MsgRate:  s.msgRate,
+			ByteRate: s.byteRate,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ByteRate != out[j].ByteRate {
+			return out[i].ByteRate > out[j].ByteRate
+		}
+		return out[i].Kind+out[i].ID < out[j].Kind+out[j].ID
+	})
+	return out
+}
+
+// WriteTo writes per-subscription counters in the Prometheus text format.
+func (t *Traffic) WriteTo(w io.Writer) (int64, error) {
+	snap := t.Snapshot()
+	sort.Slice(snap, func(i, j int) bool {
+		if snap[i].Kind != snap[j].Kind {
+			return snap[i].Kind < snap[j].Kind
+		}
+		return snap[i].ID < snap[j].ID
+	})
+	var b strings.Builder
+	writeMetric(&b, "caesar_subscription_messages_total", "counter", "Messages per subscription, by kind (ws, grpc).")
+	for _, s := range snap {
+		fmt.Fprintf(&b, "caesar_subscription_messages_total{kind=%q,id=%q} %d\n", s.Kind, s.ID, s.Messages)
+	}
+	writeMetric(&b, "caesar_subscription_bytes_total", "counter", "Bytes per subscription, by kind (ws, grpc).")
+	for _, s := range snap {
+		fmt.Fprintf(&b, "caesar_subscription_bytes_total{kind=%q,id=%q} %d\n", s.Kind, s.ID, s.Bytes)
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Handler serves the concatenated metrics of every source for scraping.
+func Handler(sources ...io.WriterTo) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		for _, s := range sources {
+			if _, err := s.WriteTo(w); err != nil {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+func TestTrafficAccountsPerSubscription(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	tr := NewTraffic(clk)
+	for range 60 {
+		tr.Record(TrafficWS, "market:tok", 1000)
+		clk.Advance(time.Second)
+	}
+	tr.Record(TrafficGRPC, "tui-1", 200)
+
+	snap := tr.Snapshot()
+	if len(snap) != 2 || snap[0].ID != "market:tok" {
+		t.Fatalf("snapshot = %+v", snap)
+	}
+	ws := snap[0]
+	if ws.Messages != 60 || ws.Bytes != 60_000 {
+		t.Errorf("ws totals = %d msgs %d bytes", ws.Messages, ws.Bytes)
+	}
+	// A steady 1 msg/s over one time constant converges to ~63% of it.
+	if math.Abs(ws.MsgRate-(1-math.Exp(-1))) > 0.02 {
+		t.Errorf("ws msg rate = %f", ws.MsgRate)
+	}
+
+	clk.Advance(10 * time.Minute)
+	if r := tr.Snapshot()[0].ByteRate; r > 1 {
+		t.Errorf("idle subscription still reports %f B/s", r)
+	}
+
+	rec := httptest.NewRecorder()
+	Handler(NewFeed(clk), tr).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	out := rec.Body.String()
+	for _, want := range []string{
+		"caesar_feed_ws_connected 0\n",
+		`caesar_subscription_bytes_total{kind="grpc",id="tui-1"} 200` + "\n",
+		`caesar_subscription_messages_total{kind="ws",id="market:tok"} 60` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	tr.Remove(TrafficGRPC, "tui-1")
+	if len(tr.Snapshot()) != 1 {
+		t.Error("removed subscription still reported")
+	}
+}
@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+)
+
+// TelegramAPI is the default Telegram Bot API base URL.
+const TelegramAPI = "https://api.telegram.org"
+
+// Telegram sends notifications to a chat via a bot.
+type Telegram struct {
+	baseURL string
+	token   string
+	chatID  string
+	client  *http.Client
+}
+
+// NewTelegram creates a Telegram channel. baseURL is normally TelegramAPI.
+func NewTelegram(baseURL, token, chatID string, client *http.Client) *Telegram {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Telegram{baseURL: strings.TrimRight(baseURL, "/"), token: token, chatID: chatID, client: client}
+}
+
+// Name implements Channel.
+func (t *Telegram) Name() string { return "telegram" }
+
+// Send implements Channel.
+func (t *Telegram) Send(ctx context.Context, n Notification) error {
+	url := fmt.Sprintf("%s/bot%s/sendMessage", t.baseURL, t.token)
+	err := postJSON(ctx, t.client, url, map[string]string{
+		"chat_id": t.chatID,
+		"text":    n.text(),
+	})
+	if err != nil {
+		// Keep the bot token out of error strings.
+		return fmt.Errorf("telegram sendMessage: %s", strings.ReplaceAll(err.Error(), t.token, "<token>"))
+	}
+	return nil
+}
+
+// SendMailFunc matches smtp.SendMail; replaced in tests.
+type SendMailFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// Email sends notifications over SMTP.
+type Email struct {
+	addr     string
+	auth     smtp.Auth
+	from     string
+	to       []string
+	sendMail SendMailFunc
+}
+
+// NewEmail creates an SMTP channel. auth may be nil for unauthenticated
+// relays; sendMail defaults to smtp.SendMail.
+func NewEmail(addr string, auth smtp.Auth, from string, to []string, sendMail SendMailFunc) *Email {
+	if sendMail == nil {
+		sendMail = smtp.SendMail
+	}
+	return &Email{addr: addr, auth: auth, from: from, to: to, sendMail: sendMail}
+}
+
+// Name implements Channel.
+func (e *Email) Name() string { return "email" }
+
+// Send implements Channel.
+func (e *Email) Send(_ context.Context, n Notification) error {
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(n.Title)
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", e.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&msg, "Subject: [caesar %s] %s\r\n", n.Severity, subject)
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(n.text(), "\n", "\r\n"))
+	msg.WriteString("\r\n")
+	return e.sendMail(e.addr, e.auth, e.from, e.to, []byte(msg.String()))
+}
+
+// PagerDutyEventsURL is the PagerDuty Events API v2 enqueue endpoint.
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty triggers incidents via the Events API v2.
+type PagerDuty struct {
+	url        string
+	routingKey string
+	client     *http.Client
+}
+
+// NewPagerDuty creates a PagerDuty channel. url is normally
+// PagerDutyEventsURL.
+func NewPagerDuty(url, routingKey string, client *http.Client) *PagerDuty {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &PagerDuty{url: url, routingKey: routingKey, client: client}
+}
+
+// Name implements Channel.
+func (p *PagerDuty) Name() string { return "pagerduty" }
+
+// Send implements Channel. Notifications of the same kind and title share
+// a dedup key so repeated alerts collapse into one incident.
+func (p *PagerDuty) Send(ctx context.Context, n Notification) error {
+	severity := string(n.Severity)
+	if severity == "" {
+		severity = string(SeverityWarning)
+	}
+	return postJSON(ctx, p.client, p.url, map[string]any{
+		"routing_key":  p.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    fmt.Sprintf("caesar:%s:%s", n.Kind, n.Title),
+		"payload": map[string]any{
+			"summary":        n.Title,
+			"source":         "caesar",
+			"severity":       severity,
+			"timestamp":      n.At,
+			"component":      "signer",
+			"class":          string(n.Kind),
+			"custom_details": map[string]string{"body": n.Body},
+		},
+	})
+}
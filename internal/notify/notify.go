@@ -0,0 +1,223 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrUnknownChannel is returned when a route names a channel that was not
+// registered with the router.
+var ErrUnknownChannel = errors.New("unknown notification channel")
+
+// Kind classifies a notification for routing.
+type Kind string
+
+const (
+	KindLimitExceeded Kind = "limit_exceeded"
+	KindFill          Kind = "fill"
+	KindAnomaly       Kind = "anomaly"
+	KindCanary        Kind = "canary"
+	KindSession       Kind = "session"
+	KindApproval      Kind = "approval"
+	KindPosition      Kind = "position"
+)
+
+// Severity is the urgency of a notification. Channels that support it
+// (PagerDuty) map it onto their own levels; the rest include it in text.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Notification is an outbound alert.
+type Notification struct {
+	Kind     Kind
+	Severity Severity
+	Title    string
+	Body     string
+	At       time.Time
+}
+
+// text renders n as plain text for chat and email channels.
+func (n Notification) text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s", strings.ToUpper(string(n.Severity)), n.Title)
+	if n.Body != "" {
+		b.WriteString("\n")
+		b.WriteString(n.Body)
+	}
+	return b.String()
+}
+
+// Channel delivers notifications to one destination.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, n Notification) error
+}
+
+// Route sends notifications of the listed kinds to the named channels. A
+// route with no kinds matches every notification.
+type Route struct {
+	Kinds    []Kind
+	Channels []string
+}
+
+func (r Route) matches(k Kind) bool {
+	if len(r.Kinds) == 0 {
+		return true
+	}
+	for _, rk := range r.Kinds {
+		if rk == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Router fans notifications out to channels according to routes.
+type Router struct {
+	channels map[string]Channel
+	routes   []Route
+}
+
+// NewRouter creates a router. Every channel named by a route must be
+// among channels.
+func NewRouter(channels []Channel, routes []Route) (*Router, error) {
+	r := &Router{channels: make(map[string]Channel, len(channels))}
+	for _, c := range channels {
+		r.channels[c.Name()] = c
+	}
+	for _, route := range routes {
+		for _, name := range route.Channels {
+			if _, ok := r.channels[name]; !ok {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownChannel, name)
+			}
+		}
+	}
+	r.routes = append([]Route(nil), routes...)
+	return r, nil
+}
+
+// Notify delivers n to every channel whose route matches its kind. Each
+// channel receives n at most once. Delivery errors are returned together
+// and do not stop delivery to the remaining channels.
+func (r *Router) Notify(ctx context.Context, n Notification) []error {
+	if n.At.IsZero() {
+		n.At = time.Now().UTC()
+	}
+	sent := make(map[string]bool)
+	var errs []error
+	for _, route := range r.routes {
+		if !route.matches(n.Kind) {
+			continue
+		}
+		for _, name := range route.Channels {
+			if sent[name] {
+				continue
+			}
+			sent[name] = true
+			if err := r.channels[name].Send(ctx, n); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+	}
+	return errs
+}
+
+// ParseRoutes parses a route spec of semicolon-separated entries
+// "kind[,kind...]=channel[,channel...]". The kind "*" matches everything.
+// e.g. "limit_exceeded,canary=pagerduty;fill=telegram,email".
+func ParseRoutes(spec string) ([]Route, error) {
+	var routes []Route
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kinds, channels, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("route %q: missing '='", entry)
+		}
+		var route Route
+		for _, k := range splitList(kinds) {
+			if k != "*" {
+				route.Kinds = append(route.Kinds, Kind(k))
+			}
+		}
+		route.Channels = splitList(channels)
+		if len(route.Channels) == 0 {
+			return nil, fmt.Errorf("route %q: no channels", entry)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// postJSON POSTs body as JSON and treats any non-2xx status as an error.
+func postJSON(ctx context.Context, client *http.Client, url string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("POST %s: status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// Webhook posts notifications as JSON to an arbitrary URL.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook creates a generic JSON webhook channel.
+func NewWebhook(url string, client *http.Client) *Webhook {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Webhook{url: url, client: client}
+}
+
+// Name implements Channel.
+func (w *Webhook) Name() string { return "webhook" }
+
+// Send implements Channel.
+func (w *Webhook) Send(ctx context.Context, n Notification) error {
+	return postJSON(ctx, w.client, w.url, map[string]any{
+		"kind":     n.Kind,
+		"severity": n.Severity,
+		"title":    n.Title,
+		"body":     n.Body,
+		"at":       n.At,
+	})
+}
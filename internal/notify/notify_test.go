@@ -0,0 +1,287 @@
+package notify
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+)
+
+type recordingChannel struct {
+	name string
+	got  []Notification
+	err  error
+}
+
+func (c *recordingChannel) Name() string { return c.name }
+
+func (c *recordingChannel) Send(_ context.Context, n Notification) error {
+	c.got = append(c.got, n)
+	return c.err
+}
+
+func TestRouterRoutesByKind(t *testing.T) {
+	pd := &recordingChannel{name: "pagerduty"}
+	tg := &recordingChannel{name: "telegram"}
+	routes, err := ParseRoutes("limit_exceeded,canary=pagerduty; fill=telegram; *=telegram")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRouter([]Channel{pd, tg}, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.Notify(context.Background(), Notification{Kind: KindLimitExceeded, Title: "limit"})
+	r.Notify(context.Background(), Notification{Kind: KindFill, Title: "fill"})
+
+	if len(pd.got) != 1 || pd.got[0].Kind != KindLimitExceeded {
+		t.Errorf("pagerduty got %+v", pd.got)
+	}
+	// The catch-all also matches limit_exceeded; fill must not be sent twice.
+	if len(tg.got) != 2 {
+		t.Fatalf("telegram got %d notifications, want 2", len(tg.got))
+	}
+	if tg.got[0].At.IsZero() {
+		t.Error("expected At to be stamped")
+	}
+}
+
+func TestRouterCollectsErrors(t *testing.T) {
+	bad := &recordingChannel{name: "email", err: errors.New("relay down")}
+	good := &recordingChannel{name: "telegram"}
+	r, err := NewRouter([]Channel{bad, good}, []Route{{Channels: []string{"email", "telegram"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := r.Notify(context.Background(), Notification{Kind: KindAnomaly})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "email") {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if len(good.got) != 1 {
+		t.Error("delivery should continue past a failing channel")
+	}
+}
+
+func TestNewRouterUnknownChannel(t *testing.T) {
+	_, err := NewRouter(nil, []Route{{Channels: []string{"sms"}}})
+	if !errors.Is(err, ErrUnknownChannel) {
+		t.Fatalf("expected ErrUnknownChannel, got %v", err)
+	}
+}
+
+func TestParseRoutesInvalid(t *testing.T) {
+	for _, spec := range []string{"fill", "fill="} {
+		if _, err := ParseRoutes(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestTelegramSend(t *testing.T) {
+	var path string
+	var body map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer srv.Close()
+
+	tg := NewTelegram(srv.URL, "123:abc", "42", srv.Client())
+	err := tg.Send(context.Background(), Notification{Severity: SeverityInfo, Title: "filled", Body: "10 @ 0.55"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/bot123:abc/sendMessage" {
+		t.Errorf("path = %s", path)
+	}
+	if body["chat_id"] != "42" || !strings.Contains(body["text"], "filled") {
+		t.Errorf("body = %v", body)
+	}
+}
+
+func TestTelegramErrorRedactsToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	err := NewTelegram(srv.URL, "123:abc", "42", srv.Client()).Send(context.Background(), Notification{})
+	if err == nil || strings.Contains(err.Error(), "123:abc") {
+		t.Fatalf("expected redacted error, got %v", err)
+	}
+}
+
+func TestPagerDutySend(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	pd := NewPagerDuty(srv.URL, "rk", srv.Client())
+	err := pd.Send(context.Background(), Notification{Kind: KindLimitExceeded, Severity: SeverityCritical, Title: "limit hit"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body["routing_key"] != "rk" || body["event_action"] != "trigger" {
+		t.Errorf("body = %v", body)
+	}
+	payload := body["payload"].(map[string]any)
+	if payload["severity"] != "critical" || payload["summary"] != "limit hit" {
+		t.Errorf("payload = %v", payload)
+	}
+}
+
+func TestEmailSend(t *testing.T) {
+	var gotTo []string
+	var gotMsg string
+	send := func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotTo, gotMsg = to, string(msg)
+		return nil
+	}
+	e := NewEmail("smtp:587", nil, "caesar@example.com", []string{"ops@example.com"}, send)
+	err := e.Send(context.Background(), Notification{Severity: SeverityWarning, Title: "anomaly\r\nBcc: x@evil", Body: "rate spike"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotTo) != 1 || gotTo[0] != "ops@example.com" {
+		t.Errorf("to = %v", gotTo)
+	}
+	headers, _, _ := strings.Cut(gotMsg, "\r\n\r\n")
+	if strings.Contains(headers, "\r\nBcc:") {
+		t.Error("title must not inject headers")
+	}
+	if !strings.Contains(gotMsg, "rate spike") {
+		t.Errorf("message missing body: %q", gotMsg)
+	}
+}
+
+func TestWebPushSendEncryptsAndSigns(t *testing.T) {
+	// Browser side: a subscription key pair and auth secret.
+	uaKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authSecret := make([]byte, 16)
+	rand.Read(authSecret)
+
+	vapidPriv, vapidPub, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	var authHeader, urgency string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader, urgency = r.Header.Get("Authorization"), r.Header.Get("Urgency")
+		body, _ := io.ReadAll(r.Body)
+		// RFC 8188 header: salt(16) | rs(4) | idlen(1) | keyid.
+		salt, idlen := body[:16], int(body[20])
+		asPub, ciphertext := body[21:21+idlen], body[21+idlen:]
+		pub, err := ecdh.P256().NewPublicKey(asPub)
+		if err != nil {
+			t.Errorf("bad sender key: %v", err)
+			return
+		}
+		secret, _ := uaKey.ECDH(pub)
+		cek, nonce, _ := webPushKeys(secret, authSecret, uaKey.PublicKey().Bytes(), asPub, salt)
+		block, _ := aes.NewCipher(cek)
+		gcm, _ := cipher.NewGCM(block)
+		plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+		if err != nil || plain[len(plain)-1] != 0x02 {
+			t.Errorf("decrypt: %v", err)
+			return
+		}
+		json.Unmarshal(plain[:len(plain)-1], &got)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	var sub Subscription
+	sub.Endpoint = srv.URL + "/push/abc"
+	sub.Keys.P256dh = base64.RawURLEncoding.EncodeToString(uaKey.PublicKey().Bytes())
+	sub.Keys.Auth = base64.RawURLEncoding.EncodeToString(authSecret)
+
+	wp, err := NewWebPush(vapidPriv, "mailto:desk@example.com", []Subscription{sub}, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wp.PublicKey() != vapidPub {
+		t.Errorf("public key mismatch")
+	}
+
+	err = wp.Send(context.Background(), Notification{Kind: KindLimitExceeded, Severity: SeverityCritical, Title: "limit hit"})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if got["title"] != "limit hit" || got["kind"] != string(KindLimitExceeded) {
+		t.Errorf("unexpected payload: %v", got)
+	}
+	if urgency != "high" {
+		t.Errorf("expected high urgency for critical, got %q", urgency)
+	}
+
+	// Verify the VAPID JWT against the advertised public key.
+	token, key, ok := strings.Cut(strings.TrimPrefix(authHeader, "vapid t="), ", k=")
+	if !ok || key != vapidPub {
+		t.Fatalf("unexpected Authorization: %q", authHeader)
+	}
+	dot := strings.LastIndex(token, ".")
+	sig, _ := base64.RawURLEncoding.DecodeString(token[dot+1:])
+	digest := sha256.Sum256([]byte(token[:dot]))
+	if !ecdsa.Verify(&wp.key.PublicKey, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+		t.Error("VAPID signature does not verify")
+	}
+	claims, _ := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[1])
+	if !strings.Contains(string(claims), `"aud":"`+srv.URL+`"`) {
+		t.Errorf("unexpected claims: %s", claims)
+	}
+}
+
+func TestWebPushDropsGoneSubscriptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer srv.Close()
+
+	uaKey, _ := ecdh.P256().GenerateKey(rand.Reader)
+	var sub Subscription
+	sub.Endpoint = srv.URL + "/push/gone"
+	sub.Keys.P256dh = base64.RawURLEncoding.EncodeToString(uaKey.PublicKey().Bytes())
+	sub.Keys.Auth = base64.RawURLEncoding.EncodeToString(make([]byte, 16))
+
+	priv, _, _ := GenerateVAPIDKeys()
+	wp, err := NewWebPush(priv, "mailto:desk@example.com", []Subscription{sub}, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wp.Send(context.Background(), Notification{Title: "x"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if n := len(wp.Subscriptions()); n != 0 {
+		t.Errorf("expected gone subscription to be dropped, have %d", n)
+	}
+
+	if _, err := NewWebPush("short", "", nil, nil); !errors.Is(err, ErrInvalidVAPIDKey) {
+		t.Errorf("expected ErrInvalidVAPIDKey, got %v", err)
+	}
+	sub.Keys.Auth = "bad"
+	if err := wp.Subscribe(sub); !errors.Is(err, ErrInvalidSubscription) {
+		t.Errorf("expected ErrInvalidSubscription, got %v", err)
+	}
+}
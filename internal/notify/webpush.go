@@ -0,0 +1,354 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+var (
+	ErrInvalidVAPIDKey     = errors.New("invalid VAPID private key")
+	ErrInvalidSubscription = errors.New("invalid push subscription")
+)
+
+// Subscription is a browser PushSubscription as serialised by
+// PushSubscription.toJSON() in the dashboard.
+type Subscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// webPushTTL is how long a push service holds a message for an offline
+// browser. Alerts older than this are not worth showing.
+const webPushTTL = 12 * time.Hour
+
+// WebPush delivers notifications to subscribed browsers using the Web Push
+// protocol (RFC 8030) with aes128gcm payload encryption (RFC 8291) and
+// VAPID authentication (RFC 8292). Subscriptions the push service reports
+// as gone are dropped.
+type WebPush struct {
+	key     *ecdsa.PrivateKey
+	pub     []byte // uncompressed P-256 point
+	subject string // mailto: or https: contact for the push service
+	client  *http.Client
+	now     func() time.Time
+
+	mu   sync.Mutex
+	subs map[string]Subscription
+}
+
+// NewWebPush creates a Web Push channel. vapidPrivateKey is the base64url
+// encoded 32-byte P-256 scalar, as printed by GenerateVAPIDKeys or the
+// usual web-push tooling.
+func NewWebPush(vapidPrivateKey, subject string, subs []Subscription, client *http.Client) (*WebPush, error) {
+	d, err := base64.RawURLEncoding.DecodeString(vapidPrivateKey)
+	if err != nil || len(d) != 32 {
+		return nil, ErrInvalidVAPIDKey
+	}
+	ecdhKey, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, ErrInvalidVAPIDKey
+	}
+	pub := ecdhKey.PublicKey().Bytes()
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(pub[1:33]),
+			Y:     new(big.Int).SetBytes(pub[33:]),
+		},
+		D: new(big.Int).SetBytes(d),
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	w := &WebPush{key: key, pub: pub, subject: subject, client: client, now: time.Now, subs: make(map[string]Subscription)}
+	for _, s := range subs {
+		if err := w.Subscribe(s); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// GenerateVAPIDKeys returns a new base64url private key for NewWebPush and
+// the matching public key to hand to pushManager.subscribe in the browser.
+func GenerateVAPIDKeys() (privateKey, publicKey string, err error) {
+	k, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(k.Bytes()),
+		base64.RawURLEncoding.EncodeToString(k.PublicKey().Bytes()), nil
+}
+
+// LoadSubscriptions reads a JSON array of subscriptions. An empty path
+// yields none.
+func LoadSubscriptions(path string) ([]Subscription, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read push subscriptions: %w", err)
+	}
+	var subs []Subscription
+	if err := json.Unmarshal(data, &subs); err != nil {
+		return nil, fmt.Errorf("parse push subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// PublicKey returns the base64url VAPID public key (applicationServerKey).
+func (w *WebPush) PublicKey() string { return base64.RawURLEncoding.EncodeToString(w.pub) }
+
+// Subscribe adds or replaces a subscription, keyed by endpoint.
+func (w *WebPush) Subscribe(s Subscription) error {
+	u, err := url.Parse(s.Endpoint)
+	if err != nil || u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+		return fmt.Errorf("%w: endpoint %q", ErrInvalidSubscription, s.Endpoint)
+	}
+	if _, _, err := s.keys(); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.subs[s.Endpoint] = s
+	w.mu.Unlock()
+	return nil
+}
+
+// Unsubscribe removes the subscription for endpoint.
+func (w *WebPush) Unsubscribe(endpoint string) {
+	w.mu.Lock()
+	delete(w.subs, endpoint)
+	w.mu.Unlock()
+}
+
+// Subscriptions returns the current subscriptions.
+func (w *WebPush) Subscriptions() []Subscription {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	out := make([]Subscription, 0, len(w.subs))
+	for _, s := range w.subs {
+		out = append(out, s)
+	}
+	return out
+}
+
+// Name implements Channel.
+func (w *WebPush) Name() string { return "webpush" }
+
+// Send implements Channel. The payload is the JSON the dashboard's service
+// worker passes to showNotification.
+func (w *WebPush) Send(ctx context.Context, n Notification) error {
+	payload, err := json.Marshal(map[string]any{
+		"kind":     n.Kind,
+		"severity": n.Severity,
+		"title":    n.Title,
+		"body":     n.Body,
+		"at":       n.At,
+	})
+	if err != nil {
+		return err
+	}
+	urgency := "normal"
+	if n.Severity == SeverityCritical {
+		urgency = "high"
+	}
+
+	var errs []error
+	for _, s := range w.Subscriptions() {
+		if err := w.push(ctx, s, payload, urgency); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (w *WebPush) push(ctx context.Context, s Subscription, payload []byte, urgency string) error {
+	body, err := encryptPayload(s, payload)
+	if err != nil {
+		return err
+	}
+	auth, err := w.vapidAuth(s.Endpoint)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", fmt.Sprint(int(webPushTTL.Seconds())))
+	req.Header.Set("Urgency", urgency)
+	req.Header.Set("Authorization", auth)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webpush %s: %w", endpointHost(s.Endpoint), err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		// The browser unsubscribed or the subscription expired.
+		w.Unsubscribe(s.Endpoint)
+		return nil
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("webpush %s: status %d", endpointHost(s.Endpoint), resp.StatusCode)
+	}
+	return nil
+}
+
+// vapidAuth builds the RFC 8292 Authorization header: an ES256 JWT scoped
+// to the push service origin, plus the public key.
+func (w *WebPush) vapidAuth(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]any{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": w.now().Add(webPushTTL).Unix(),
+		"sub": w.subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := header + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, sig, err := ecdsa.Sign(rand.Reader, w.key, digest[:])
+	if err != nil {
+		return "", err
+	}
+	raw := make([]byte, 64)
+	r.FillBytes(raw[:32])
+	sig.FillBytes(raw[32:])
+	return fmt.Sprintf("vapid t=%s.%s, k=%s", signingInput, enc.EncodeToString(raw), w.PublicKey()), nil
+}
+
+func (s Subscription) keys() (*ecdh.PublicKey, []byte, error) {
+	p256dh, err := decodeBase64URL(s.Keys.P256dh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: p256dh: %v", ErrInvalidSubscription, err)
+	}
+	pub, err := ecdh.P256().NewPublicKey(p256dh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: p256dh: %v", ErrInvalidSubscription, err)
+	}
+	auth, err := decodeBase64URL(s.Keys.Auth)
+	if err != nil || len(auth) != 16 {
+		return nil, nil, fmt.Errorf("%w: auth secret", ErrInvalidSubscription)
+	}
+	return pub, auth, nil
+}
+
+// webPushRecordSize is the aes128gcm record size; payloads are a single
+// record, so it only has to exceed the payload.
+const webPushRecordSize = 4096
+
+// encryptPayload encrypts payload for s as a single aes128gcm record
+// (RFC 8188) keyed per RFC 8291.
+func encryptPayload(s Subscription, payload []byte) ([]byte, error) {
+	uaPub, authSecret, err := s.keys()
+	if err != nil {
+		return nil, err
+	}
+	if len(payload)+1+aes.BlockSize > webPushRecordSize {
+		return nil, fmt.Errorf("webpush payload too large: %d bytes", len(payload))
+	}
+	asKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := asKey.ECDH(uaPub)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	cek, nonce, err := webPushKeys(secret, authSecret, uaPub.Bytes(), asKey.PublicKey().Bytes(), salt)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 marks the last (and only) record.
+	ciphertext := gcm.Seal(nil, nonce, append(append([]byte(nil), payload...), 0x02), nil)
+
+	asPub := asKey.PublicKey().Bytes()
+	out := make([]byte, 0, 16+4+1+len(asPub)+len(ciphertext))
+	out = append(out, salt...)
+	out = binary.BigEndian.AppendUint32(out, webPushRecordSize)
+	out = append(out, byte(len(asPub)))
+	out = append(out, asPub...)
+	return append(out, ciphertext...), nil
+}
+
+// webPushKeys derives the content-encryption key and nonce (RFC 8291 §3.4).
+func webPushKeys(ecdhSecret, authSecret, uaPub, asPub, salt []byte) (cek, nonce []byte, err error) {
+	keyInfo := append(append([]byte("WebPush: info\x00"), uaPub...), asPub...)
+	ikm := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ecdhSecret, authSecret, keyInfo), ikm); err != nil {
+		return nil, nil, err
+	}
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	cek = make([]byte, 16)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, []byte("Content-Encoding: aes128gcm\x00")), cek); err != nil {
+		return nil, nil, err
+	}
+	nonce = make([]byte, 12)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, []byte("Content-Encoding: nonce\x00")), nonce); err != nil {
+		return nil, nil, err
+	}
+	return cek, nonce, nil
+}
+
+// decodeBase64URL accepts both padded and unpadded base64url, as browsers
+// differ.
+func decodeBase64URL(s string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
+
+func endpointHost(endpoint string) string {
+	if u, err := url.Parse(endpoint); err == nil {
+		return u.Host
+	}
+	return "endpoint"
+}
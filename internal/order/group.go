@@ -0,0 +1,86 @@
+package order
+
+import (
+	"math/big"
+	"sync"
+)
+
+// Group tracks a set of orders placed together (e.g. a ladder) so they can
+// be cancelled and reported on as a unit.
+type Group struct {
+	mu     sync.RWMutex
+	id     string
+	size   map[string]*big.Int // order ID → original size
+	filled map[string]*big.Int // order ID → cumulative filled size
+}
+
+// NewGroup creates an empty group with the given identifier.
+func NewGroup(id string) *Group {
+	return &Group{
+		id:     id,
+		size:   make(map[string]*big.Int),
+		filled: make(map[string]*big.Int),
+	}
+}
+
+// ID returns the group identifier.
+func (g *Group) ID() string {
+	return g.id
+}
+
+// Add registers an order ID with its original size.
+func (g *Group) Add(orderID string, size *big.Int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.size[orderID] = new(big.Int).Set(size)
+	g.filled[orderID] = new(big.Int)
+}
+
+// RecordFill sets the cumulative filled size for an order, capped at its
+// original size.
+func (g *Group) RecordFill(orderID string, filled *big.Int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	size, ok := g.size[orderID]
+	if !ok {
+		return ErrUnknownGroupMember
+	}
+	if filled.Cmp(size) > 0 {
+		filled = size
+	}
+	g.filled[orderID] = new(big.Int).Set(filled)
+	return nil
+}
+
+// Open returns the IDs of orders that are not fully filled — the set a
+// "cancel group" action must cancel.
+func (g *Group) Open() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var ids []string
+	for id, size := range g.size {
+		if g.filled[id].Cmp(size) < 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// FillRate returns filled size over total size across the group, in [0, 1].
+func (g *Group) FillRate() float64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	total, filled := new(big.Int), new(big.Int)
+	for id, size := range g.size {
+		total.Add(total, size)
+		filled.Add(filled, g.filled[id])
+	}
+	if total.Sign() == 0 {
+		return 0
+	}
+	rate, _ := new(big.Rat).SetFrac(filled, total).Float64()
+	return rate
+}
@@ -0,0 +1,199 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// PriceScale is the fixed-point scale for prices: 1_000_000 == 1.00 USDC.
+// It matches the 6-decimal USDC atomic units used for maker/taker amounts.
+const PriceScale = 1_000_000
+
+var (
+	ErrInvalidLadder      = errors.New("invalid ladder spec")
+	ErrBatchExceedsLimit  = errors.New("ladder batch exceeds remaining value limit")
+	ErrUnknownGroupMember = errors.New("order is not a member of this group")
+)
+
+// Side is the direction of an order.
+type Side int
+
+const (
+	SideBuy Side = iota + 1
+	SideSell
+)
+
+// Sizing selects how the total ladder size is distributed across rungs.
+type Sizing int
+
+const (
+	// SizingLinear weights rungs 1, 2, …, N moving away from StartPrice.
+	SizingLinear Sizing = iota
+	// SizingGeometric weights rungs 1, r, r², … moving away from StartPrice.
+	SizingGeometric
+)
+
+// LadderSpec describes N orders spread between two prices.
+// Prices are fixed-point (PriceScale) and sizes are raw share units.
+type LadderSpec struct {
+	TokenID    string
+	Side       Side
+	StartPrice int64    // first rung, nearest the touch
+	EndPrice   int64    // last rung
+	Rungs      int      // number of orders, >= 1
+	TotalSize  *big.Int // total shares across all rungs
+	Sizing     Sizing
+	Ratio      float64 // geometric ratio; ignored for SizingLinear
+	TickSize   int64   // price increment rungs are rounded to (e.g. 10_000 for 0.01)
+}
+
+// Leg is a single order produced by a ladder.
+type Leg struct {
+	TokenID     string
+	Side        Side
+	Price       int64    // fixed-point (PriceScale)
+	Size        *big.Int // shares
+	MakerAmount *big.Int // what the maker gives up (USDC for BUY, shares for SELL)
+	TakerAmount *big.Int // what the maker receives (shares for BUY, USDC for SELL)
+}
+
+// Notional returns the USDC value of the leg in atomic units.
+func (l Leg) Notional() *big.Int {
+	if l.Side == SideBuy {
+		return new(big.Int).Set(l.MakerAmount)
+	}
+	return new(big.Int).Set(l.TakerAmount)
+}
+
+// BuildLadder expands spec into its legs. Rung prices are evenly spaced
+// between StartPrice and EndPrice and snapped to TickSize; any rounding
+// remainder in size is assigned to the last rung so the total is exact.
+func BuildLadder(spec LadderSpec) ([]Leg, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+
+	weights := spec.weights()
+	sum := new(big.Float)
+	for _, w := range weights {
+		sum.Add(sum, w)
+	}
+
+	legs := make([]Leg, 0, spec.Rungs)
+	allocated := new(big.Int)
+	for i := 0; i < spec.Rungs; i++ {
+		price := spec.rungPrice(i)
+
+		var size *big.Int
+		if i == spec.Rungs-1 {
+			size = new(big.Int).Sub(spec.TotalSize, allocated)
+		} else {
+			share := new(big.Float).Mul(new(big.Float).SetInt(spec.TotalSize), weights[i])
+			share.Quo(share, sum)
+			size, _ = share.Int(nil)
+		}
+		allocated.Add(allocated, size)
+
+		if size.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: rung %d has zero size", ErrInvalidLadder, i)
+		}
+
+		legs = append(legs, NewLeg(spec.TokenID, spec.Side, price, size))
+	}
+
+	return legs, nil
+}
+
+// BatchNotional returns the total USDC value of legs.
+func BatchNotional(legs []Leg) *big.Int {
+	total := new(big.Int)
+	for _, l := range legs {
+		total.Add(total, l.Notional())
+	}
+	return total
+}
+
+// ValidateBatch checks that the combined notional of legs fits within the
+// remaining session value limit. The ladder is accepted or rejected as a
+// whole so a partially-placed ladder never consumes budget.
+func ValidateBatch(legs []Leg, remaining *big.Int) error {
+	if BatchNotional(legs).Cmp(remaining) > 0 {
+		return ErrBatchExceedsLimit
+	}
+	return nil
+}
+
+// NewLeg builds a leg for size shares at price, deriving maker and taker
+// amounts from the side.
+func NewLeg(tokenID string, side Side, price int64, size *big.Int) Leg {
+	usdc := new(big.Int).Mul(size, big.NewInt(price))
+	usdc.Quo(usdc, big.NewInt(PriceScale))
+
+	leg := Leg{
+		TokenID: tokenID,
+		Side:    side,
+		Price:   price,
+		Size:    size,
+	}
+	if side == SideBuy {
+		leg.MakerAmount, leg.TakerAmount = usdc, new(big.Int).Set(size)
+	} else {
+		leg.MakerAmount, leg.TakerAmount = new(big.Int).Set(size), usdc
+	}
+	return leg
+}
+
+func (s LadderSpec) validate() error {
+	switch {
+	case s.Side != SideBuy && s.Side != SideSell:
+		return fmt.Errorf("%w: unknown side", ErrInvalidLadder)
+	case s.Rungs < 1:
+		return fmt.Errorf("%w: rungs must be >= 1", ErrInvalidLadder)
+	case s.TotalSize == nil || s.TotalSize.Sign() <= 0:
+		return fmt.Errorf("%w: total size must be positive", ErrInvalidLadder)
+	case s.TickSize <= 0:
+		return fmt.Errorf("%w: tick size must be positive", ErrInvalidLadder)
+	case !validPrice(s.StartPrice) || !validPrice(s.EndPrice):
+		return fmt.Errorf("%w: prices must be within (0, 1)", ErrInvalidLadder)
+	case s.Sizing == SizingGeometric && s.Ratio <= 0:
+		return fmt.Errorf("%w: geometric ratio must be positive", ErrInvalidLadder)
+	case s.Sizing != SizingLinear && s.Sizing != SizingGeometric:
+		return fmt.Errorf("%w: unknown sizing", ErrInvalidLadder)
+	}
+	return nil
+}
+
+func (s LadderSpec) weights() []*big.Float {
+	w := make([]*big.Float, s.Rungs)
+	for i := range w {
+		switch s.Sizing {
+		case SizingGeometric:
+			f := 1.0
+			for j := 0; j < i; j++ {
+				f *= s.Ratio
+			}
+			w[i] = big.NewFloat(f)
+		default:
+			w[i] = big.NewFloat(float64(i + 1))
+		}
+	}
+	return w
+}
+
+// rungPrice returns the tick-snapped price of rung i.
+func (s LadderSpec) rungPrice(i int) int64 {
+	if s.Rungs == 1 {
+		return snap(s.StartPrice, s.TickSize)
+	}
+	step := float64(s.EndPrice-s.StartPrice) / float64(s.Rungs-1)
+	return snap(s.StartPrice+int64(step*float64(i)), s.TickSize)
+}
+
+func snap(price, tick int64) int64 {
+	return (price + tick/2) / tick * tick
+}
+
+func validPrice(p int64) bool {
+	return p > 0 && p < PriceScale
+}
@@ -0,0 +1,137 @@
+package order
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"testing"
+)
+
+func TestBuildLadderLinear(t *testing.T) {
+	legs, err := BuildLadder(LadderSpec{
+		TokenID:    "123",
+		Side:       SideBuy,
+		StartPrice: 500_000,
+		EndPrice:   460_000,
+		Rungs:      5,
+		TotalSize:  big.NewInt(150_000_000),
+		Sizing:     SizingLinear,
+		TickSize:   10_000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantPrices := []int64{500_000, 490_000, 480_000, 470_000, 460_000}
+	wantSizes := []int64{10_000_000, 20_000_000, 30_000_000, 40_000_000, 50_000_000}
+	for i, l := range legs {
+		if l.Price != wantPrices[i] {
+			t.Errorf("rung %d: price %d, want %d", i, l.Price, wantPrices[i])
+		}
+		if l.Size.Int64() != wantSizes[i] {
+			t.Errorf("rung %d: size %s, want %d", i, l.Size, wantSizes[i])
+		}
+		if l.TakerAmount.Cmp(l.Size) != 0 {
+			t.Errorf("rung %d: BUY taker amount should equal size", i)
+		}
+	}
+
+	// 10*0.50 + 20*0.49 + 30*0.48 + 40*0.47 + 50*0.46 = 71.0 USDC
+	if got := BatchNotional(legs).Int64(); got != 71_000_000 {
+		t.Errorf("batch notional %d, want 71000000", got)
+	}
+}
+
+func TestBuildLadderGeometricPreservesTotal(t *testing.T) {
+	total := big.NewInt(100_000_001)
+	legs, err := BuildLadder(LadderSpec{
+		Side:       SideSell,
+		StartPrice: 600_000,
+		EndPrice:   700_000,
+		Rungs:      4,
+		TotalSize:  total,
+		Sizing:     SizingGeometric,
+		Ratio:      2,
+		TickSize:   1_000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sum := new(big.Int)
+	for i, l := range legs {
+		sum.Add(sum, l.Size)
+		if i > 0 && l.Size.Cmp(legs[i-1].Size) <= 0 {
+			t.Errorf("rung %d: geometric sizes should increase", i)
+		}
+		if l.MakerAmount.Cmp(l.Size) != 0 {
+			t.Errorf("rung %d: SELL maker amount should equal size", i)
+		}
+	}
+	if sum.Cmp(total) != 0 {
+		t.Errorf("sizes sum to %s, want %s", sum, total)
+	}
+}
+
+func TestBuildLadderInvalid(t *testing.T) {
+	specs := map[string]LadderSpec{
+		"no rungs":     {Side: SideBuy, StartPrice: 1, EndPrice: 2, TotalSize: big.NewInt(10), TickSize: 1},
+		"price >= 1":   {Side: SideBuy, StartPrice: PriceScale, EndPrice: 2, Rungs: 2, TotalSize: big.NewInt(10), TickSize: 1},
+		"no size":      {Side: SideBuy, StartPrice: 1, EndPrice: 2, Rungs: 2, TickSize: 1},
+		"bad ratio":    {Side: SideBuy, StartPrice: 1, EndPrice: 2, Rungs: 2, TotalSize: big.NewInt(10), TickSize: 1, Sizing: SizingGeometric},
+		"unknown side": {StartPrice: 1, EndPrice: 2, Rungs: 2, TotalSize: big.NewInt(10), TickSize: 1},
+	}
+	for name, spec := range specs {
+		if _, err := BuildLadder(spec); !errors.Is(err, ErrInvalidLadder) {
+			t.Errorf("%s: expected ErrInvalidLadder, got %v", name, err)
+		}
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	legs, err := BuildLadder(LadderSpec{
+		Side:       SideBuy,
+		StartPrice: 500_000,
+		EndPrice:   500_000,
+		Rungs:      2,
+		TotalSize:  big.NewInt(30_000_000),
+		TickSize:   10_000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 30 shares at 0.50 = 15 USDC.
+	if err := ValidateBatch(legs, big.NewInt(15_000_000)); err != nil {
+		t.Errorf("expected batch to fit exactly, got %v", err)
+	}
+	if err := ValidateBatch(legs, big.NewInt(14_999_999)); !errors.Is(err, ErrBatchExceedsLimit) {
+		t.Errorf("expected ErrBatchExceedsLimit, got %v", err)
+	}
+}
+
+func TestGroupFillRate(t *testing.T) {
+	g := NewGroup("ladder-1")
+	g.Add("a", big.NewInt(100))
+	g.Add("b", big.NewInt(300))
+
+	if err := g.RecordFill("a", big.NewInt(100)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.RecordFill("b", big.NewInt(100)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.RecordFill("c", big.NewInt(1)); !errors.Is(err, ErrUnknownGroupMember) {
+		t.Errorf("expected ErrUnknownGroupMember, got %v", err)
+	}
+
+	if rate := g.FillRate(); rate != 0.5 {
+		t.Errorf("fill rate %v, want 0.5", rate)
+	}
+
+	open := g.Open()
+	sort.Strings(open)
+	if len(open) != 1 || open[0] != "b" {
+		t.Errorf("open orders %v, want [b]", open)
+	}
+}
@@ -0,0 +1,210 @@
+package order
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+var ErrInvalidTransition = errors.New("invalid order state transition")
+
+// State is an order's position in its lifecycle.
+type State int
+
+const (
+	StateSigned State = iota + 1
+	StateSubmitted
+	StateOpen
+	StatePartiallyFilled
+	StateFilled
+	StateCancelled
+	StateRejected
+)
+
+func (s State) String() string {
+	switch s {
+	case StateSigned:
+		return "signed"
+	case StateSubmitted:
+		return "submitted"
+	case StateOpen:
+		return "open"
+	case StatePartiallyFilled:
+		return "partially_filled"
+	case StateFilled:
+		return "filled"
+	case StateCancelled:
+		return "cancelled"
+	case StateRejected:
+		return "rejected"
+	default:
+		return "unknown"
+	}
+}
+
+// Terminal reports whether no further transitions are possible.
+func (s State) Terminal() bool {
+	return s == StateFilled || s == StateCancelled || s == StateRejected
+}
+
+// Record is a point-in-time view of a tracked order.
+type Record struct {
+	ID        string
+	TokenID   string
+	Side      Side
+	Price     int64
+	Size      *big.Int
+	Filled    *big.Int
+	State     State
+	UpdatedAt time.Time
+}
+
+func (r *Record) clone() Record {
+	c := *r
+	c.Size = new(big.Int).Set(r.Size)
+	c.Filled = new(big.Int).Set(r.Filled)
+	return c
+}
+
+// Store tracks order lifecycle state in memory and serves bulk lookups
+// from a single consistent snapshot, so callers like the TUI and
+// reconciliation jobs avoid N round trips and never see a half-applied
+// update across orders.
+type Store struct {
+	mu       sync.RWMutex
+	orders   map[string]*Record
+	byMarket map[string]map[string]struct{}
+	clock    clock.Clock
+}
+
+// StoreOption configures a Store.
+type StoreOption func(*Store)
+
+// WithClock sets the time source for UpdatedAt stamps. Defaults to the
+// wall clock.
+func WithClock(c clock.Clock) StoreOption {
+	return func(s *Store) {
+		s.clock = clock.Or(c)
+	}
+}
+
+// NewStore creates an empty order store.
+func NewStore(opts ...StoreOption) *Store {
+	s := &Store{
+		orders:   make(map[string]*Record),
+		byMarket: make(map[string]map[string]struct{}),
+		clock:    clock.Real{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Track registers a newly signed order.
+func (s *Store) Track(id string, leg Leg) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.orders[id] = &Record{
+		ID:        id,
+		TokenID:   leg.TokenID,
+		Side:      leg.Side,
+		Price:     leg.Price,
+		Size:      new(big.Int).Set(leg.Size),
+		Filled:    new(big.Int),
+		State:     StateSigned,
+		UpdatedAt: s.clock.Now(),
+	}
+	if s.byMarket[leg.TokenID] == nil {
+		s.byMarket[leg.TokenID] = make(map[string]struct{})
+	}
+	s.byMarket[leg.TokenID][id] = struct{}{}
+}
+
+// Transition moves an order to state. Terminal orders cannot change.
+func (s *Store) Transition(id string, state State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.orders[id]
+	if !ok || r.State.Terminal() {
+		return ErrInvalidTransition
+	}
+	// Lifecycle only moves forward, except that any live order may be
+	// cancelled or rejected.
+	if state < r.State && state != StateCancelled && state != StateRejected {
+		return ErrInvalidTransition
+	}
+	r.State = state
+	r.UpdatedAt = s.clock.Now()
+	return nil
+}
+
+// RecordFill sets the cumulative filled size and derives the fill state.
+func (s *Store) RecordFill(id string, filled *big.Int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.orders[id]
+	if !ok || r.State.Terminal() {
+		return ErrInvalidTransition
+	}
+	r.Filled.Set(filled)
+	if r.Filled.Cmp(r.Size) >= 0 {
+		r.Filled.Set(r.Size)
+		r.State = StateFilled
+	} else if r.Filled.Sign() > 0 {
+		r.State = StatePartiallyFilled
+	}
+	r.UpdatedAt = s.clock.Now()
+	return nil
+}
+
+// GetOrders returns the records for ids in request order; unknown IDs are
+// omitted. All records come from the same snapshot.
+func (s *Store) GetOrders(ids ...string) []Record {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]Record, 0, len(ids))
+	for _, id := range ids {
+		if r, ok := s.orders[id]; ok {
+			out = append(out, r.clone())
+		}
+	}
+	return out
+}
+
+// GetOrdersByMarket returns every tracked order for tokenID, sorted by ID.
+func (s *Store) GetOrdersByMarket(tokenID string) []Record {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]Record, 0, len(s.byMarket[tokenID]))
+	for id := range s.byMarket[tokenID] {
+		out = append(out, s.orders[id].clone())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Live returns every order that is not terminal, sorted by ID. These are
+// the orders that can still fill.
+func (s *Store) Live() []Record {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []Record
+	for _, r := range s.orders {
+		if !r.State.Terminal() {
+			out = append(out, r.clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
@@ -0,0 +1,75 @@
+package order
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+func TestStoreLifecycleAndBulkQueries(t *testing.T) {
+	s := NewStore()
+	s.Track("o1", NewLeg("tok", SideBuy, 500_000, big.NewInt(100)))
+	s.Track("o2", NewLeg("tok", SideBuy, 490_000, big.NewInt(100)))
+	s.Track("o3", NewLeg("other", SideSell, 600_000, big.NewInt(50)))
+
+	if err := s.Transition("o1", StateOpen); err != nil {
+		t.Fatalf("transition: %v", err)
+	}
+	if err := s.RecordFill("o1", big.NewInt(40)); err != nil {
+		t.Fatalf("fill: %v", err)
+	}
+	if err := s.RecordFill("o2", big.NewInt(500)); err != nil {
+		t.Fatalf("fill: %v", err)
+	}
+	if err := s.Transition("o2", StateCancelled); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected filled order to be terminal, got %v", err)
+	}
+	if err := s.Transition("o3", StateCancelled); err != nil {
+		t.Errorf("expected cancel from signed, got %v", err)
+	}
+
+	got := s.GetOrders("o2", "missing", "o1")
+	if len(got) != 2 || got[0].ID != "o2" || got[1].ID != "o1" {
+		t.Fatalf("unexpected GetOrders result: %+v", got)
+	}
+	if got[0].State != StateFilled || got[0].Filled.Int64() != 100 {
+		t.Errorf("o2: state=%v filled=%s", got[0].State, got[0].Filled)
+	}
+	if got[1].State != StatePartiallyFilled {
+		t.Errorf("o1: state=%v, want partially_filled", got[1].State)
+	}
+
+	// Snapshots are copies; mutating them does not affect the store.
+	got[1].Filled.SetInt64(0)
+	if again := s.GetOrders("o1"); again[0].Filled.Int64() != 40 {
+		t.Errorf("store mutated through snapshot")
+	}
+
+	market := s.GetOrdersByMarket("tok")
+	if len(market) != 2 || market[0].ID != "o1" || market[1].ID != "o2" {
+		t.Errorf("unexpected GetOrdersByMarket result: %+v", market)
+	}
+	// o2 filled and o3 was cancelled; only o1 can still fill.
+	if live := s.Live(); len(live) != 1 || live[0].ID != "o1" {
+		t.Errorf("unexpected Live result: %+v", live)
+	}
+}
+
+func TestStoreStampsWithClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	s := NewStore(WithClock(clk))
+	s.Track("o1", NewLeg("tok", SideBuy, 500_000, big.NewInt(10)))
+
+	clk.Advance(time.Minute)
+	if err := s.Transition("o1", StateOpen); err != nil {
+		t.Fatal(err)
+	}
+	got := s.GetOrders("o1")
+	if len(got) != 1 || !got[0].UpdatedAt.Equal(start.Add(time.Minute)) {
+		t.Errorf("UpdatedAt = %v, want %v", got[0].UpdatedAt, start.Add(time.Minute))
+	}
+}
@@ -0,0 +1,221 @@
+package order
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+// Trade sources, for Ledger.Duplicates.
+const (
+	SourceWS   = "ws"
+	SourceREST = "rest"
+)
+
+// Trade is one execution of a tracked order, as reported by the user
+// WebSocket channel or by REST reconciliation.
+type Trade struct {
+	ID      string // exchange trade ID; the dedup key
+	OrderID string
+	TokenID string
+	Side    Side
+	Price   int64    // fixed-point (PriceScale)
+	Size    *big.Int // raw share units
+	At      time.Time
+	Source  string // SourceWS or SourceREST
+}
+
+// Position is the net holding in one token built from applied trades.
+type Position struct {
+	TokenID  string
+	Shares   *big.Int  // raw share units
+	Cost     *big.Int  // USDC cost basis of Shares, atomic units
+	Realized *big.Int  // USDC realized PnL, atomic units
+	OpenedAt time.Time // when Shares last went from none to some; zero when flat
+}
+
+// Ledger applies trades to positions exactly once. The same fill commonly
+// arrives twice — live over the WebSocket, then again when REST
+// reconciliation backfills after a reconnect — so every trade is keyed by
+// its exchange ID and replays are dropped before they touch positions or
+// PnL.
+type Ledger struct {
+	clock     clock.Clock
+	retention time.Duration
+
+	mu         sync.Mutex
+	seen       map[string]time.Time // trade ID → execution time
+	positions  map[string]*Position
+	filled     map[string]*big.Int // order ID → cumulative filled size
+	duplicates map[string]uint64   // by source
+}
+
+// LedgerOption configures a Ledger.
+type LedgerOption func(*Ledger)
+
+// WithLedgerClock sets the time source used for retention. Defaults to
+// the wall clock.
+func WithLedgerClock(c clock.Clock) LedgerOption {
+	return func(l *Ledger) {
+		l.clock = clock.Or(c)
+	}
+}
+
+// WithTradeRetention bounds memory by forgetting trade IDs executed more
+// than d ago. Trades older than that are then ignored outright, so d must
+// exceed the REST reconciliation look-back. Zero (the default) keeps every
+// ID.
+func WithTradeRetention(d time.Duration) LedgerOption {
+	return func(l *Ledger) {
+		l.retention = d
+	}
+}
+
+// NewLedger creates an empty ledger.
+func NewLedger(opts ...LedgerOption) *Ledger {
+	l := &Ledger{
+		clock:      clock.Real{},
+		seen:       make(map[string]time.Time),
+		positions:  make(map[string]*Position),
+		filled:     make(map[string]*big.Int),
+		duplicates: make(map[string]uint64),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Apply books t and reports whether it was new. A trade whose ID has
+// already been applied, or that falls outside the retention window, is
+// counted as a duplicate and changes nothing.
+func (l *Ledger) Apply(t Trade) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.retention > 0 {
+		horizon := l.clock.Now().Add(-l.retention)
+		for id, at := range l.seen {
+			if at.Before(horizon) {
+				delete(l.seen, id)
+			}
+		}
+		if t.At.Before(horizon) {
+			l.duplicates[t.Source]++
+			return false
+		}
+	}
+	if _, ok := l.seen[t.ID]; ok {
+		l.duplicates[t.Source]++
+		return false
+	}
+	l.seen[t.ID] = t.At
+
+	if t.OrderID != "" {
+		if l.filled[t.OrderID] == nil {
+			l.filled[t.OrderID] = new(big.Int)
+		}
+		l.filled[t.OrderID].Add(l.filled[t.OrderID], t.Size)
+	}
+	l.position(t.TokenID).apply(t)
+	return true
+}
+
+func (l *Ledger) position(tokenID string) *Position {
+	p, ok := l.positions[tokenID]
+	if !ok {
+		p = &Position{TokenID: tokenID, Shares: new(big.Int), Cost: new(big.Int), Realized: new(big.Int)}
+		l.positions[tokenID] = p
+	}
+	return p
+}
+
+// apply adds a buy at cost or closes shares at their average cost,
+// realizing the difference. Sells beyond the held size only reduce
+// Shares; there is no short cost basis.
+func (p *Position) apply(t Trade) {
+	notional := new(big.Int).Mul(t.Size, big.NewInt(t.Price))
+	notional.Quo(notional, big.NewInt(PriceScale))
+	if t.Side == SideBuy {
+		if p.Shares.Sign() <= 0 && new(big.Int).Add(p.Shares, t.Size).Sign() > 0 {
+			p.OpenedAt = t.At
+		}
+		p.Shares.Add(p.Shares, t.Size)
+		p.Cost.Add(p.Cost, notional)
+		return
+	}
+	if p.Shares.Sign() > 0 {
+		closed := t.Size
+		if closed.Cmp(p.Shares) > 0 {
+			closed = p.Shares
+		}
+		basis := new(big.Int).Mul(p.Cost, closed)
+		basis.Quo(basis, p.Shares)
+		proceeds := notional
+		if closed != t.Size {
+			proceeds = new(big.Int).Mul(closed, big.NewInt(t.Price))
+			proceeds.Quo(proceeds, big.NewInt(PriceScale))
+		}
+		p.Realized.Add(p.Realized, proceeds.Sub(proceeds, basis))
+		p.Cost.Sub(p.Cost, basis)
+	}
+	p.Shares.Sub(p.Shares, t.Size)
+	if p.Shares.Sign() <= 0 {
+		p.OpenedAt = time.Time{}
+	}
+}
+
+func (p *Position) clone() Position {
+	return Position{
+		TokenID:  p.TokenID,
+		Shares:   new(big.Int).Set(p.Shares),
+		Cost:     new(big.Int).Set(p.Cost),
+		Realized: new(big.Int).Set(p.Realized),
+		OpenedAt: p.OpenedAt,
+	}
+}
+
+// Position returns the position in tokenID; ok is false when no trade has
+// touched it.
+func (l *Ledger) Position(tokenID string) (Position, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	p, ok := l.positions[tokenID]
+	if !ok {
+		return Position{}, false
+	}
+	return p.clone(), true
+}
+
+// Positions returns every position, sorted by token ID.
+func (l *Ledger) Positions() []Position {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]Position, 0, len(l.positions))
+	for _, p := range l.positions {
+		out = append(out, p.clone())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TokenID < out[j].TokenID })
+	return out
+}
+
+// Filled returns the cumulative size applied to orderID, suitable for
+// Store.RecordFill.
+func (l *Ledger) Filled(orderID string) *big.Int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if f, ok := l.filled[orderID]; ok {
+		return new(big.Int).Set(f)
+	}
+	return new(big.Int)
+}
+
+// Duplicates returns how many trades from source were dropped as replays.
+func (l *Ledger) Duplicates(source string) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.duplicates[source]
+}
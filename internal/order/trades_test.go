@@ -0,0 +1,82 @@
+package order
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+func TestLedgerDedupsAcrossSources(t *testing.T) {
+	l := NewLedger()
+	buy := Trade{ID: "t1", OrderID: "o1", TokenID: "tok", Side: SideBuy, Price: 400_000, Size: big.NewInt(10_000_000), Source: SourceWS}
+	if !l.Apply(buy) {
+		t.Fatal("first trade rejected")
+	}
+	// Reconciliation after a reconnect returns the same fill over REST.
+	replay := buy
+	replay.Source = SourceREST
+	if l.Apply(replay) {
+		t.Error("replayed trade applied twice")
+	}
+	l.Apply(Trade{ID: "t2", OrderID: "o1", TokenID: "tok", Side: SideBuy, Price: 600_000, Size: big.NewInt(10_000_000), Source: SourceREST})
+	l.Apply(Trade{ID: "t3", TokenID: "tok", Side: SideSell, Price: 700_000, Size: big.NewInt(5_000_000), Source: SourceWS})
+
+	p, ok := l.Position("tok")
+	if !ok {
+		t.Fatal("no position")
+	}
+	// 20 shares at an average of 0.50, then 5 sold at 0.70.
+	if p.Shares.Int64() != 15_000_000 || p.Cost.Int64() != 7_500_000 || p.Realized.Int64() != 1_000_000 {
+		t.Errorf("position = shares %s cost %s realized %s", p.Shares, p.Cost, p.Realized)
+	}
+	if got := l.Filled("o1").Int64(); got != 20_000_000 {
+		t.Errorf("filled = %d", got)
+	}
+	if l.Duplicates(SourceREST) != 1 || l.Duplicates(SourceWS) != 0 {
+		t.Errorf("duplicates rest=%d ws=%d", l.Duplicates(SourceREST), l.Duplicates(SourceWS))
+	}
+}
+
+func TestLedgerRetention(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	l := NewLedger(WithLedgerClock(clk), WithTradeRetention(time.Hour))
+
+	old := Trade{ID: "t1", TokenID: "tok", Side: SideBuy, Price: 500_000, Size: big.NewInt(1_000_000), At: start}
+	l.Apply(old)
+	clk.Advance(2 * time.Hour)
+	if l.Apply(old) {
+		t.Error("trade older than retention applied after its ID was forgotten")
+	}
+	if p, _ := l.Position("tok"); p.Shares.Int64() != 1_000_000 {
+		t.Errorf("shares = %s", p.Shares)
+	}
+	if len(l.seen) != 0 {
+		t.Errorf("expired IDs retained: %v", l.seen)
+	}
+}
+
+func TestLedgerPositionOpenedAt(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLedger()
+	trade := func(id string, side Side, size int64, at time.Duration) {
+		l.Apply(Trade{ID: id, TokenID: "tok", Side: side, Price: 500_000, Size: big.NewInt(size), At: start.Add(at)})
+	}
+
+	trade("t1", SideBuy, 10, 0)
+	trade("t2", SideBuy, 10, time.Hour)
+	trade("t3", SideSell, 5, 2*time.Hour)
+	if p, _ := l.Position("tok"); !p.OpenedAt.Equal(start) {
+		t.Errorf("adding to a position moved OpenedAt to %v", p.OpenedAt)
+	}
+	trade("t4", SideSell, 15, 3*time.Hour)
+	if p, _ := l.Position("tok"); !p.OpenedAt.IsZero() {
+		t.Errorf("flat position kept OpenedAt %v", p.OpenedAt)
+	}
+	trade("t5", SideBuy, 1, 4*time.Hour)
+	if p, _ := l.Position("tok"); !p.OpenedAt.Equal(start.Add(4 * time.Hour)) {
+		t.Errorf("reopened position OpenedAt = %v", p.OpenedAt)
+	}
+}
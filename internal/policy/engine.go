@@ -0,0 +1,245 @@
+package policy
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrRejected      = errors.New("order rejected by policy check")
+	ErrDuplicateName = errors.New("duplicate rule name")
+	ErrStaleData     = errors.New("book data too old")
+)
+
+// Check is one named pre-trade expression. An order passes a check when
+// the expression evaluates to true.
+type Check struct {
+	Name string
+	Expr *Expr
+}
+
+// Violation reports the rule an order failed. Err is set when the rule
+// could not be evaluated (e.g. a field was missing), in which case the
+// rule fails closed. Stale is set when the rule refused to judge the order
+// against an outdated book. Shadow is set for a rule that is not enforced.
+type Violation struct {
+	Rule      string
+	Type      RuleType
+	Threshold string
+	Actual    string
+	Err       error
+	Stale     bool
+	Shadow    bool
+}
+
+func (v *Violation) Error() string {
+	switch {
+	case v.Err != nil:
+		return fmt.Sprintf("rule %s: %v", v.Rule, v.Err)
+	case v.Stale:
+		return fmt.Sprintf("rule %s: %v: want %s, got %s", v.Rule, ErrStaleData, v.Threshold, v.Actual)
+	}
+	return fmt.Sprintf("rule %s failed: want %s, got %s", v.Rule, v.Threshold, v.Actual)
+}
+
+// Unwrap lets errors.Is match ErrRejected, and ErrStaleData for stale
+// rejections.
+func (v *Violation) Unwrap() []error {
+	if v.Stale {
+		return []error{ErrRejected, ErrStaleData}
+	}
+	return []error{ErrRejected}
+}
+
+// ParseChecks parses a checks file. Each non-blank line has the form
+//
+//	name = expression
+//
+// and # starts a comment line. Names must be unique.
+func ParseChecks(src string) ([]Check, error) {
+	var checks []Check
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(strings.NewReader(src))
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, body, ok := strings.Cut(text, "=")
+		name, body = strings.TrimSpace(name), strings.TrimSpace(body)
+		if !ok || name == "" || body == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("line %d: %w: want name = expression", line, ErrSyntax)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("line %d: %w: %s", line, ErrDuplicateName, name)
+		}
+		expr, err := Compile(body)
+		if err != nil {
+			return nil, fmt.Errorf("line %d (%s): %w", line, name, err)
+		}
+		seen[name] = true
+		checks = append(checks, Check{Name: name, Expr: expr})
+	}
+	return checks, sc.Err()
+}
+
+// Engine holds the policy loaded from a file and swaps in a new one when
+// the file changes. A file that fails to parse or validate leaves the
+// previous policy in force.
+//
+// Files ending in .yaml, .yml or .json are versioned policies (see
+// Policy); anything else is a checks file (see ParseChecks).
+type Engine struct {
+	path string
+
+	mu      sync.RWMutex
+	policy  *Policy
+	modTime time.Time
+	size    int64
+}
+
+// NewEngine loads the policy at path. An empty path yields an engine with
+// no rules, which passes every order.
+func NewEngine(path string) (*Engine, error) {
+	e := &Engine{path: path, policy: &Policy{Version: SchemaVersion}}
+	if path == "" {
+		return e, nil
+	}
+	if _, err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// NewStaticEngine returns an engine that enforces p and never reloads,
+// for a policy that does not come from a file of its own.
+func NewStaticEngine(p *Policy) *Engine {
+	return &Engine{policy: p}
+}
+
+// Reload re-reads the file if it changed since the last load and reports
+// whether a new policy was installed.
+func (e *Engine) Reload() (bool, error) {
+	if e.path == "" {
+		return false, nil
+	}
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return false, fmt.Errorf("stat policy: %w", err)
+	}
+	e.mu.RLock()
+	unchanged := info.ModTime().Equal(e.modTime) && info.Size() == e.size
+	e.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	p, err := LoadFile(e.path)
+	if err != nil {
+		return false, err
+	}
+
+	e.mu.Lock()
+	e.policy, e.modTime, e.size = p, info.ModTime(), info.Size()
+	e.mu.Unlock()
+	return true, nil
+}
+
+// Run polls the file every interval until ctx is done, calling onErr for
+// each failed reload. A non-positive interval disables reloading.
+func (e *Engine) Run(ctx context.Context, interval time.Duration, onErr func(error)) {
+	if e.path == "" || interval <= 0 {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if _, err := e.Reload(); err != nil && onErr != nil {
+				onErr(err)
+			}
+		}
+	}
+}
+
+// Policy returns the policy currently in force. Callers must not modify
+// it.
+func (e *Engine) Policy() *Policy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.policy
+}
+
+// Evaluate checks env against the current policy at now and returns a
+// *Violation for the first rule it does not pass, skipping waived rules.
+func (e *Engine) Evaluate(env Env, now time.Time, waive ...string) error {
+	return e.Policy().Check(env, now, waive...)
+}
+
+// Shadowed returns what the current policy's shadow rules would have
+// rejected env for at now.
+func (e *Engine) Shadowed(env Env, now time.Time) []*Violation {
+	return e.Policy().Shadowed(env, now)
+}
+
+// LoadFile reads a policy or checks file, choosing the format by
+// extension.
+func LoadFile(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		p, err := ParsePolicy(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		return p, nil
+	}
+	checks, err := ParseChecks(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return checksPolicy(checks), nil
+}
+
+// Markets maps token IDs to attributes exposed to checks as market.<key>,
+// e.g. {"0xabc": {"category": "politics"}}.
+type Markets map[string]map[string]any
+
+// LoadMarkets reads a Markets JSON file. An empty path yields nil.
+func LoadMarkets(path string) (Markets, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy markets: %w", err)
+	}
+	var m Markets
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse policy markets: %w", err)
+	}
+	return m, nil
+}
+
+// AddTo copies the attributes of tokenID into env under the market.
+// prefix. Unknown tokens add nothing, so checks on market fields fail
+// closed for them.
+func (m Markets) AddTo(env Env, tokenID string) {
+	for k, v := range m[tokenID] {
+		env["market."+k] = v
+	}
+}
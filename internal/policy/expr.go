@@ -0,0 +1,671 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrSyntax       = errors.New("policy expression syntax error")
+	ErrUnknownField = errors.New("unknown field")
+	ErrType         = errors.New("type mismatch")
+)
+
+// Env supplies field values to an expression. Values must be float64,
+// string, or bool; keys are dotted paths such as "order.price".
+type Env map[string]any
+
+// Expr is a compiled policy expression, e.g.
+//
+//	order.price < 0.95 && market.category != "politics"
+//
+// The language has number, string ('…' or "…"), and boolean literals,
+// dotted field names, arithmetic (+ - * /, with + also joining strings),
+// comparisons (== != < <= > >=), membership (x in ["a", "b"]), !, &&, ||,
+// parentheses, and the functions split(s, sep, n) (the nth sep-separated
+// part of s, "" past the end) and round(x, places). && and ||
+// short-circuit.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Compile parses src.
+func Compile(src string) (*Expr, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrSyntax, p.peek().text)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source text.
+func (e *Expr) String() string { return e.src }
+
+// Fields returns the field names the expression references.
+func (e *Expr) Fields() []string {
+	seen := make(map[string]bool)
+	var out []string
+	walk(e.root, func(n node) {
+		if f, ok := n.(fieldNode); ok && !seen[string(f)] {
+			seen[string(f)] = true
+			out = append(out, string(f))
+		}
+	})
+	return out
+}
+
+// Eval evaluates the expression against env. It is an error for the
+// expression to reference a field missing from env or to yield a
+// non-boolean result.
+func (e *Expr) Eval(env Env) (bool, error) {
+	v, err := e.Value(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: expression yields %T, want bool", ErrType, v)
+	}
+	return b, nil
+}
+
+// Value evaluates the expression against env, returning a float64,
+// string, or bool.
+func (e *Expr) Value(env Env) (any, error) {
+	return e.root.eval(env)
+}
+
+// ─── lexer ───────────────────────────────────────────────────────────────
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokComma
+)
+
+type token struct {
+	kind tokKind
+	text string
+}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			toks = append(toks, token{tokLParen, "("})
+			i++
+		case c == ')':
+			toks = append(toks, token{tokRParen, ")"})
+			i++
+		case c == '[':
+			toks = append(toks, token{tokLBracket, "["})
+			i++
+		case c == ']':
+			toks = append(toks, token{tokRBracket, "]"})
+			i++
+		case c == ',':
+			toks = append(toks, token{tokComma, ","})
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("%w: unterminated string", ErrSyntax)
+			}
+			toks = append(toks, token{tokString, src[i+1 : j]})
+			i = j + 1
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			j := i
+			for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] == '.' || src[j] == '_') {
+				j++
+			}
+			toks = append(toks, token{tokNumber, src[i:j]})
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] == '.' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			toks = append(toks, token{tokIdent, src[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, cand := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/"} {
+				if strings.HasPrefix(src[i:], cand) {
+					op = cand
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("%w: unexpected character %q", ErrSyntax, c)
+			}
+			toks = append(toks, token{tokOp, op})
+			i += len(op)
+		}
+	}
+	return append(toks, token{tokEOF, ""}), nil
+}
+
+// ─── parser ──────────────────────────────────────────────────────────────
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) acceptOp(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseCompare()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("&&") {
+		right, err := p.parseCompare()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseCompare() (node, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokOp {
+		switch t.text {
+		case "==", "!=", "<", "<=", ">", ">=":
+			p.next()
+			right, err := p.parseSum()
+			if err != nil {
+				return nil, err
+			}
+			return compareNode{op: t.text, left: left, right: right}, nil
+		}
+	}
+	if t := p.peek(); t.kind == tokIdent && t.text == "in" {
+		p.next()
+		list, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return inNode{value: left, list: list}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseSum() (node, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.kind == tokOp && (t.text == "+" || t.text == "-"); t = p.peek() {
+		p.next()
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = arithNode{op: t.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseProduct() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.kind == tokOp && (t.text == "*" || t.text == "/"); t = p.peek() {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = arithNode{op: t.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseList() ([]node, error) {
+	if p.next().kind != tokLBracket {
+		return nil, fmt.Errorf("%w: expected [ after in", ErrSyntax)
+	}
+	var list []node
+	if p.peek().kind == tokRBracket {
+		p.next()
+		return list, nil
+	}
+	for {
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, n)
+		switch p.next().kind {
+		case tokComma:
+		case tokRBracket:
+			return list, nil
+		default:
+			return nil, fmt.Errorf("%w: expected , or ] in list", ErrSyntax)
+		}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.acceptOp("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	if p.acceptOp("-") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return arithNode{op: "-", left: literalNode{0.0}, right: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(t.text, "_", ""), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad number %q", ErrSyntax, t.text)
+		}
+		return literalNode{f}, nil
+	case tokString:
+		return literalNode{t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return literalNode{true}, nil
+		case "false":
+			return literalNode{false}, nil
+		}
+		if p.peek().kind == tokLParen {
+			return p.parseCall(t.text)
+		}
+		return fieldNode(t.text), nil
+	case tokLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokRParen {
+			return nil, fmt.Errorf("%w: missing )", ErrSyntax)
+		}
+		return n, nil
+	case tokEOF:
+		return nil, fmt.Errorf("%w: unexpected end of expression", ErrSyntax)
+	default:
+		return nil, fmt.Errorf("%w: unexpected %q", ErrSyntax, t.text)
+	}
+}
+
+func (p *parser) parseCall(name string) (node, error) {
+	arity, ok := functions[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown function %s", ErrSyntax, name)
+	}
+	p.next() // (
+	var args []node
+	for p.peek().kind != tokRParen {
+		if len(args) > 0 && p.next().kind != tokComma {
+			return nil, fmt.Errorf("%w: expected , or ) in call to %s", ErrSyntax, name)
+		}
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.next()
+	if len(args) != arity {
+		return nil, fmt.Errorf("%w: %s takes %d arguments, got %d", ErrSyntax, name, arity, len(args))
+	}
+	return callNode{name: name, args: args}, nil
+}
+
+// ─── evaluation ──────────────────────────────────────────────────────────
+
+type node interface {
+	eval(env Env) (any, error)
+}
+
+type literalNode struct{ v any }
+
+func (n literalNode) eval(Env) (any, error) { return n.v, nil }
+
+type fieldNode string
+
+func (n fieldNode) eval(env Env) (any, error) {
+	v, ok := env[string(n)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownField, string(n))
+	}
+	switch x := v.(type) {
+	case int64:
+		return float64(x), nil
+	case int:
+		return float64(x), nil
+	}
+	return v, nil
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(env Env) (any, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%w: ! applied to %T", ErrType, v)
+	}
+	return !b, nil
+}
+
+type logicNode struct {
+	and         bool
+	left, right node
+}
+
+func (n logicNode) eval(env Env) (any, error) {
+	l, err := evalBool(n.left, env)
+	if err != nil {
+		return nil, err
+	}
+	if n.and && !l {
+		return false, nil
+	}
+	if !n.and && l {
+		return true, nil
+	}
+	return evalBool(n.right, env)
+}
+
+func evalBool(n node, env Env) (bool, error) {
+	v, err := n.eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: logical operand is %T", ErrType, v)
+	}
+	return b, nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(env Env) (any, error) {
+	l, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return compare(n.op, l, r)
+}
+
+func compare(op string, l, r any) (bool, error) {
+	switch lv := l.(type) {
+	case float64:
+		rv, ok := r.(float64)
+		if !ok {
+			return false, fmt.Errorf("%w: cannot compare number with %T", ErrType, r)
+		}
+		switch op {
+		case "==":
+			return lv == rv, nil
+		case "!=":
+			return lv != rv, nil
+		case "<":
+			return lv < rv, nil
+		case "<=":
+			return lv <= rv, nil
+		case ">":
+			return lv > rv, nil
+		case ">=":
+			return lv >= rv, nil
+		}
+	case string:
+		rv, ok := r.(string)
+		if !ok {
+			return false, fmt.Errorf("%w: cannot compare string with %T", ErrType, r)
+		}
+		switch op {
+		case "==":
+			return lv == rv, nil
+		case "!=":
+			return lv != rv, nil
+		case "<":
+			return lv < rv, nil
+		case "<=":
+			return lv <= rv, nil
+		case ">":
+			return lv > rv, nil
+		case ">=":
+			return lv >= rv, nil
+		}
+	case bool:
+		rv, ok := r.(bool)
+		if !ok {
+			return false, fmt.Errorf("%w: cannot compare bool with %T", ErrType, r)
+		}
+		switch op {
+		case "==":
+			return lv == rv, nil
+		case "!=":
+			return lv != rv, nil
+		}
+		return false, fmt.Errorf("%w: %s not defined for bool", ErrType, op)
+	}
+	return false, fmt.Errorf("%w: unsupported operand %T", ErrType, l)
+}
+
+type arithNode struct {
+	op          string
+	left, right node
+}
+
+func (n arithNode) eval(env Env) (any, error) {
+	l, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if ls, ok := l.(string); ok && n.op == "+" {
+		rs, ok := r.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot add string and %T", ErrType, r)
+		}
+		return ls + rs, nil
+	}
+	lv, lok := l.(float64)
+	rv, rok := r.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%w: %s applied to %T and %T", ErrType, n.op, l, r)
+	}
+	switch n.op {
+	case "+":
+		return lv + rv, nil
+	case "-":
+		return lv - rv, nil
+	case "*":
+		return lv * rv, nil
+	}
+	if rv == 0 {
+		return nil, errors.New("division by zero")
+	}
+	return lv / rv, nil
+}
+
+// functions maps each built-in function to its arity.
+var functions = map[string]int{"split": 3, "round": 2}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+func (n callNode) eval(env Env) (any, error) {
+	args := make([]any, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	switch n.name {
+	case "split":
+		s, sok := args[0].(string)
+		sep, pok := args[1].(string)
+		i, iok := args[2].(float64)
+		if !sok || !pok || !iok {
+			return nil, fmt.Errorf("%w: split(string, string, number)", ErrType)
+		}
+		parts := strings.Split(s, sep)
+		if i < 0 || int(i) >= len(parts) {
+			return "", nil
+		}
+		return parts[int(i)], nil
+	default: // round
+		x, xok := args[0].(float64)
+		places, pok := args[1].(float64)
+		if !xok || !pok {
+			return nil, fmt.Errorf("%w: round(number, number)", ErrType)
+		}
+		scale := math.Pow(10, places)
+		return math.Round(x*scale) / scale, nil
+	}
+}
+
+type inNode struct {
+	value node
+	list  []node
+}
+
+func (n inNode) eval(env Env) (any, error) {
+	v, err := n.value.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range n.list {
+		iv, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		eq, err := compare("==", v, iv)
+		if err != nil {
+			return nil, err
+		}
+		if eq {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func walk(n node, fn func(node)) {
+	fn(n)
+	switch x := n.(type) {
+	case notNode:
+		walk(x.operand, fn)
+	case logicNode:
+		walk(x.left, fn)
+		walk(x.right, fn)
+	case compareNode:
+		walk(x.left, fn)
+		walk(x.right, fn)
+	case arithNode:
+		walk(x.left, fn)
+		walk(x.right, fn)
+	case callNode:
+		for _, arg := range x.args {
+			walk(arg, fn)
+		}
+	case inNode:
+		walk(x.value, fn)
+		for _, item := range x.list {
+			walk(item, fn)
+		}
+	}
+}
@@ -0,0 +1,445 @@
+package policy
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the policy file version this build understands.
+const SchemaVersion = 1
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported policy version")
+	ErrInvalidRule        = errors.New("invalid rule")
+)
+
+// RuleType selects how a rule is evaluated.
+type RuleType string
+
+const (
+	// RuleLimit caps a numeric field at Max.
+	RuleLimit RuleType = "limit"
+	// RuleBand keeps a numeric field within [Min, Max].
+	RuleBand RuleType = "band"
+	// RuleAllowlist requires a field to be one of Values.
+	RuleAllowlist RuleType = "allowlist"
+	// RuleDenylist rejects a field that is one of Values.
+	RuleDenylist RuleType = "denylist"
+	// RuleSchedule allows orders only inside Window on Days in Timezone.
+	RuleSchedule RuleType = "schedule"
+	// RuleExpr requires Expr to evaluate to true.
+	RuleExpr RuleType = "expr"
+)
+
+// Policy is a versioned set of risk rules, written as YAML or JSON:
+//
+//	version: 1
+//	rules:
+//	  - id: price-band
+//	    type: band
+//	    field: order.price
+//	    min: 0.02
+//	    max: 0.95
+//	  - id: trading-hours
+//	    type: schedule
+//	    window: "08:00-22:00"
+//	    days: [mon, tue, wed, thu, fri]
+//	    timezone: America/New_York
+//	  - id: no-politics
+//	    type: expr
+//	    expr: market.category != "politics"
+//
+// Rules are evaluated in file order; an order must pass all of them.
+//
+// A rule marked shadow: true is evaluated but never enforced: an order it
+// fails is still allowed, and the failure is only reported, so a stricter
+// rule can be trialled against real flow before it blocks anything.
+//
+// A rule that reads book.* fields is only as good as the book behind it.
+// MaxBookAge (seconds, overridable per rule) bounds how old that book may
+// be; past it the rule fails as stale, or is skipped if its OnStale is
+// "skip".
+type Policy struct {
+	Version    int      `yaml:"version" json:"version"`
+	MaxBookAge *float64 `yaml:"max_book_age,omitempty" json:"max_book_age,omitempty"`
+	Rules      []Rule   `yaml:"rules" json:"rules"`
+}
+
+// OnStale values.
+const (
+	StaleReject = "reject"
+	StaleSkip   = "skip"
+)
+
+// Rule is one entry of a Policy. Which fields apply depends on Type.
+type Rule struct {
+	ID          string   `yaml:"id" json:"id"`
+	Type        RuleType `yaml:"type" json:"type"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Field       string   `yaml:"field,omitempty" json:"field,omitempty"`
+	Min         *float64 `yaml:"min,omitempty" json:"min,omitempty"`
+	Max         *float64 `yaml:"max,omitempty" json:"max,omitempty"`
+	Values      []string `yaml:"values,omitempty" json:"values,omitempty"`
+	Window      string   `yaml:"window,omitempty" json:"window,omitempty"`
+	Days        []string `yaml:"days,omitempty" json:"days,omitempty"`
+	Timezone    string   `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+	Expr        string   `yaml:"expr,omitempty" json:"expr,omitempty"`
+	MaxBookAge  *float64 `yaml:"max_book_age,omitempty" json:"max_book_age,omitempty"`
+	OnStale     string   `yaml:"on_stale,omitempty" json:"on_stale,omitempty"`
+	Shadow      bool     `yaml:"shadow,omitempty" json:"shadow,omitempty"`
+
+	compiled   *Expr
+	usesBook   bool
+	maxAge     float64 // effective MaxBookAge; 0 = unchecked
+	loc        *time.Location
+	start, end int // minutes after midnight
+	days       map[time.Weekday]bool
+}
+
+// Decision is the outcome of one rule for one order. Threshold and Actual
+// are human-readable renderings of what the rule required and what the
+// order had.
+type Decision struct {
+	RuleID    string
+	Type      RuleType
+	Passed    bool
+	Threshold string
+	Actual    string
+	Err       error // the rule could not be evaluated; Passed is false
+	Stale     bool  // the book the rule reads was too old to trust
+	Shadow    bool  // the rule is not enforced; a failure only reports
+}
+
+// ParsePolicy decodes and validates a policy. JSON is accepted as a subset
+// of YAML. Unknown keys are rejected so typos do not silently disable a
+// rule.
+func ParsePolicy(data []byte) (*Policy, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var p Policy
+	if err := dec.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decode policy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks the policy and prepares its rules for evaluation. It
+// reports every problem found, not just the first.
+func (p *Policy) Validate() error {
+	var errs []error
+	if p.Version != SchemaVersion {
+		errs = append(errs, fmt.Errorf("%w: %d (want %d)", ErrUnsupportedVersion, p.Version, SchemaVersion))
+	}
+	seen := make(map[string]bool)
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		label := fmt.Sprintf("rule %d", i+1)
+		if r.ID != "" {
+			label = fmt.Sprintf("rule %q", r.ID)
+		}
+		if r.ID == "" {
+			errs = append(errs, fmt.Errorf("%s: %w: id is required", label, ErrInvalidRule))
+		} else if seen[r.ID] {
+			errs = append(errs, fmt.Errorf("%s: %w", label, ErrDuplicateName))
+		}
+		seen[r.ID] = true
+		for _, err := range r.prepare() {
+			errs = append(errs, fmt.Errorf("%s: %w: %w", label, ErrInvalidRule, err))
+		}
+		r.maxAge = 0
+		if age := cmp.Or(r.MaxBookAge, p.MaxBookAge); age != nil && r.usesBook {
+			r.maxAge = *age
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Rule) prepare() []error {
+	var errs []error
+	needField := func() {
+		if r.Field == "" {
+			errs = append(errs, errors.New("field is required"))
+		}
+	}
+	switch r.Type {
+	case RuleLimit:
+		needField()
+		if r.Max == nil {
+			errs = append(errs, errors.New("max is required"))
+		}
+	case RuleBand:
+		needField()
+		if r.Min == nil || r.Max == nil {
+			errs = append(errs, errors.New("min and max are required"))
+		} else if *r.Min > *r.Max {
+			errs = append(errs, fmt.Errorf("min %v exceeds max %v", *r.Min, *r.Max))
+		}
+	case RuleAllowlist, RuleDenylist:
+		needField()
+		if len(r.Values) == 0 {
+			errs = append(errs, errors.New("values are required"))
+		}
+	case RuleSchedule:
+		start, end, err := parseWindow(r.Window)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		r.start, r.end = start, end
+		if r.loc, err = time.LoadLocation(r.Timezone); err != nil {
+			errs = append(errs, fmt.Errorf("timezone: %v", err))
+		}
+		r.days = nil
+		for _, d := range r.Days {
+			wd, ok := weekdays[strings.ToLower(d)]
+			if !ok {
+				errs = append(errs, fmt.Errorf("unknown day %q", d))
+				continue
+			}
+			if r.days == nil {
+				r.days = make(map[time.Weekday]bool)
+			}
+			r.days[wd] = true
+		}
+	case RuleExpr:
+		e, err := Compile(r.Expr)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		r.compiled = e
+	case "":
+		errs = append(errs, errors.New("type is required"))
+	default:
+		errs = append(errs, fmt.Errorf("unknown type %q", r.Type))
+	}
+
+	r.usesBook = strings.HasPrefix(r.Field, bookPrefix)
+	if r.compiled != nil {
+		for _, f := range r.compiled.Fields() {
+			r.usesBook = r.usesBook || strings.HasPrefix(f, bookPrefix)
+		}
+	}
+	if r.MaxBookAge != nil && *r.MaxBookAge <= 0 {
+		errs = append(errs, errors.New("max_book_age must be positive"))
+	}
+	switch r.OnStale {
+	case "", StaleReject, StaleSkip:
+	default:
+		errs = append(errs, fmt.Errorf("unknown on_stale %q", r.OnStale))
+	}
+	return errs
+}
+
+// bookPrefix marks fields derived from the live order book. BookAgeField
+// holds how old that book is, in seconds.
+const (
+	bookPrefix   = "book."
+	BookAgeField = "book.age_seconds"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseWindow parses "HH:MM-HH:MM" into minutes after midnight. An end
+// before the start wraps past midnight.
+func parseWindow(s string) (start, end int, err error) {
+	a, b, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("window %q: want HH:MM-HH:MM", s)
+	}
+	if start, err = parseClock(a); err != nil {
+		return 0, 0, fmt.Errorf("window %q: %v", s, err)
+	}
+	if end, err = parseClock(b); err != nil {
+		return 0, 0, fmt.Errorf("window %q: %v", s, err)
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("bad time %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Evaluate runs every rule against env at now and returns one Decision
+// per rule, in order.
+func (p *Policy) Evaluate(env Env, now time.Time) []Decision {
+	out := make([]Decision, 0, len(p.Rules))
+	for i := range p.Rules {
+		out = append(out, p.Rules[i].evaluate(env, now))
+	}
+	return out
+}
+
+// Check returns a *Violation for the first rule env does not pass, or nil.
+// Rules whose IDs are listed in waive are skipped, as are shadow rules.
+func (p *Policy) Check(env Env, now time.Time, waive ...string) error {
+	for i := range p.Rules {
+		if p.Rules[i].Shadow || slices.Contains(waive, p.Rules[i].ID) {
+			continue
+		}
+		if d := p.Rules[i].evaluate(env, now); !d.Passed {
+			return d.violation()
+		}
+	}
+	return nil
+}
+
+// Shadowed returns a *Violation for every shadow rule env does not pass,
+// in order: what those rules would have blocked had they been enforced.
+func (p *Policy) Shadowed(env Env, now time.Time) []*Violation {
+	var out []*Violation
+	for i := range p.Rules {
+		if !p.Rules[i].Shadow {
+			continue
+		}
+		if d := p.Rules[i].evaluate(env, now); !d.Passed {
+			out = append(out, d.violation())
+		}
+	}
+	return out
+}
+
+func (d Decision) violation() *Violation {
+	return &Violation{Rule: d.RuleID, Type: d.Type, Threshold: d.Threshold, Actual: d.Actual, Err: d.Err, Stale: d.Stale, Shadow: d.Shadow}
+}
+
+func (r *Rule) evaluate(env Env, now time.Time) Decision {
+	d := Decision{RuleID: r.ID, Type: r.Type, Shadow: r.Shadow}
+	if r.maxAge > 0 {
+		d.Threshold = "book age <= " + formatNumber(r.maxAge) + "s"
+		age, err := numberField(env, BookAgeField)
+		switch {
+		case err != nil:
+			d.Stale, d.Actual = true, "no book"
+		case age > r.maxAge:
+			d.Stale, d.Actual = true, "book age "+formatNumber(age)+"s"
+		}
+		if d.Stale {
+			d.Passed = r.OnStale == StaleSkip
+			return d
+		}
+	}
+	switch r.Type {
+	case RuleLimit, RuleBand:
+		if r.Type == RuleLimit {
+			d.Threshold = "<= " + formatNumber(*r.Max)
+		} else {
+			d.Threshold = fmt.Sprintf("[%s, %s]", formatNumber(*r.Min), formatNumber(*r.Max))
+		}
+		v, err := numberField(env, r.Field)
+		if err != nil {
+			d.Err = err
+			return d
+		}
+		d.Actual = r.Field + " = " + formatNumber(v)
+		d.Passed = v <= *r.Max && (r.Min == nil || v >= *r.Min)
+	case RuleAllowlist, RuleDenylist:
+		verb := "in"
+		if r.Type == RuleDenylist {
+			verb = "not in"
+		}
+		d.Threshold = fmt.Sprintf("%s [%s]", verb, strings.Join(r.Values, ", "))
+		v, ok := env[r.Field]
+		if !ok {
+			d.Err = fmt.Errorf("%w: %s", ErrUnknownField, r.Field)
+			return d
+		}
+		s := fmt.Sprint(v)
+		d.Actual = r.Field + " = " + s
+		listed := false
+		for _, allowed := range r.Values {
+			if s == allowed {
+				listed = true
+				break
+			}
+		}
+		d.Passed = listed == (r.Type == RuleAllowlist)
+	case RuleSchedule:
+		d.Threshold = r.Window + " " + r.Timezone
+		if len(r.Days) > 0 {
+			d.Threshold += " " + strings.Join(r.Days, ",")
+		}
+		local := now.In(r.loc)
+		d.Actual = local.Format("Mon 15:04 MST")
+		m := local.Hour()*60 + local.Minute()
+		inWindow := m >= r.start && m < r.end
+		if r.end <= r.start {
+			inWindow = m >= r.start || m < r.end
+		}
+		d.Passed = inWindow && (r.days == nil || r.days[local.Weekday()])
+	case RuleExpr:
+		d.Threshold = r.Expr
+		ok, err := r.compiled.Eval(env)
+		if err != nil {
+			d.Err = err
+			return d
+		}
+		d.Actual = strconv.FormatBool(ok)
+		d.Passed = ok
+	}
+	return d
+}
+
+func numberField(env Env, field string) (float64, error) {
+	v, ok := env[field]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownField, field)
+	}
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	}
+	return 0, fmt.Errorf("%w: %s is %T, want number", ErrType, field, v)
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// checksPolicy wraps the rules of a checks file as expr rules.
+func checksPolicy(checks []Check) *Policy {
+	p := &Policy{Version: SchemaVersion}
+	for _, c := range checks {
+		p.Rules = append(p.Rules, Rule{ID: c.Name, Type: RuleExpr, Expr: c.Expr.String(), compiled: c.Expr})
+	}
+	return p
+}
+
+// Flatten converts nested maps into a dotted-key Env, so a sample order
+// written as {"order": {"price": 0.5}} yields order.price = 0.5.
+func Flatten(m map[string]any) Env {
+	env := Env{}
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for k, v := range m {
+			if nested, ok := v.(map[string]any); ok {
+				walk(prefix+k+".", nested)
+				continue
+			}
+			env[prefix+k] = v
+		}
+	}
+	walk("", m)
+	return env
+}
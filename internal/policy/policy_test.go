@@ -0,0 +1,421 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExprEval(t *testing.T) {
+	env := Env{
+		"order.price":     0.62,
+		"order.side":      "BUY",
+		"order.size":      int64(150),
+		"market.category": "sports",
+		"market.closed":   false,
+	}
+	cases := []struct {
+		src  string
+		want bool
+	}{
+		{`order.price < 0.95 && market.category != "politics"`, true},
+		{`order.price >= 0.95 || market.category == 'politics'`, false},
+		{`!(order.side == "SELL") && order.size <= 1_000`, true},
+		{`market.category in ["sports", "crypto"]`, true},
+		{`order.side in ["SELL"]`, false},
+		{`market.closed == false && !market.closed`, true},
+		{`false && missing.field > 1`, false}, // short-circuits
+		{`order.price * order.size > 90 && order.size - 50 * 2 == 50`, true},
+		{`-order.price < 0 && (1 + 2) / 4 == 0.75`, true},
+	}
+	for _, c := range cases {
+		e, err := Compile(c.src)
+		if err != nil {
+			t.Errorf("compile %q: %v", c.src, err)
+			continue
+		}
+		got, err := e.Eval(env)
+		if err != nil {
+			t.Errorf("eval %q: %v", c.src, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%q = %v, want %v", c.src, got, c.want)
+		}
+	}
+}
+
+func TestExprValue(t *testing.T) {
+	env := Env{"order.price": 0.625, "order.size": int64(3), "client": "momo-eu-7"}
+	cases := []struct {
+		src  string
+		want any
+	}{
+		{`order.price * order.size`, 1.875},
+		{`round(order.price * order.size / 0.5, 2)`, 3.75},
+		{`split(client, "-", 0)`, "momo"},
+		{`split(client, "-", 5)`, ""},
+		{`split(client, "-", 1) + "/" + split(client, "-", 2)`, "eu/7"},
+	}
+	for _, c := range cases {
+		e, err := Compile(c.src)
+		if err != nil {
+			t.Errorf("compile %q: %v", c.src, err)
+			continue
+		}
+		if got, err := e.Value(env); err != nil || got != c.want {
+			t.Errorf("%q = %v, %v; want %v", c.src, got, err, c.want)
+		}
+	}
+}
+
+func TestExprErrors(t *testing.T) {
+	for _, src := range []string{`order.price <`, `(a == 1`, `a == "x`, `a # b`, `a in "x"`, ``, `nope(a)`, `round(a)`, `split(a, "-" 1)`} {
+		if _, err := Compile(src); !errors.Is(err, ErrSyntax) {
+			t.Errorf("compile %q: expected ErrSyntax, got %v", src, err)
+		}
+	}
+
+	env := Env{"order.price": 0.5, "order.side": "BUY"}
+	evalErr := func(src string) error {
+		e, err := Compile(src)
+		if err != nil {
+			t.Fatalf("compile %q: %v", src, err)
+		}
+		_, err = e.Eval(env)
+		return err
+	}
+	if err := evalErr(`market.category == "x"`); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("expected ErrUnknownField, got %v", err)
+	}
+	if err := evalErr(`order.side < 1`); !errors.Is(err, ErrType) {
+		t.Errorf("expected ErrType for mixed comparison, got %v", err)
+	}
+	if err := evalErr(`order.price`); !errors.Is(err, ErrType) {
+		t.Errorf("expected ErrType for non-bool result, got %v", err)
+	}
+	if err := evalErr(`order.side * 2 > 1`); !errors.Is(err, ErrType) {
+		t.Errorf("expected ErrType for arithmetic on a string, got %v", err)
+	}
+	if err := evalErr(`order.price / 0 > 1`); err == nil {
+		t.Error("expected an error dividing by zero")
+	}
+}
+
+func TestParseChecks(t *testing.T) {
+	checks, err := ParseChecks(`
+# price band
+max_price = order.price < 0.95
+
+no_politics = market.category != "politics"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 2 || checks[0].Name != "max_price" || checks[1].Name != "no_politics" {
+		t.Fatalf("unexpected checks: %+v", checks)
+	}
+
+	if _, err := ParseChecks("a = x > 1\na = x < 2"); !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("expected ErrDuplicateName, got %v", err)
+	}
+	if _, err := ParseChecks("just an expression"); !errors.Is(err, ErrSyntax) {
+		t.Errorf("expected ErrSyntax, got %v", err)
+	}
+}
+
+func TestEngineEvaluateAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checks")
+	write := func(src string, mtime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	base := time.Now().Add(-time.Hour)
+	write("max_price = order.price < 0.95", base)
+
+	e, err := NewEngine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := Env{"order.price": 0.97}
+	var v *Violation
+	if err := e.Evaluate(env, time.Now()); !errors.As(err, &v) || v.Rule != "max_price" || !errors.Is(err, ErrRejected) {
+		t.Fatalf("expected max_price violation, got %v", err)
+	}
+
+	// Loosen the band; the engine picks it up without restarting.
+	write("max_price = order.price < 0.99", base.Add(time.Second))
+	if changed, err := e.Reload(); err != nil || !changed {
+		t.Fatalf("reload: changed=%v err=%v", changed, err)
+	}
+	if err := e.Evaluate(env, time.Now()); err != nil {
+		t.Errorf("expected pass after reload, got %v", err)
+	}
+
+	// A broken edit keeps the previous checks.
+	write("max_price = order.price <", base.Add(2*time.Second))
+	if _, err := e.Reload(); !errors.Is(err, ErrSyntax) {
+		t.Errorf("expected ErrSyntax on bad reload, got %v", err)
+	}
+	if err := e.Evaluate(env, time.Now()); err != nil {
+		t.Errorf("expected previous checks to stay in force, got %v", err)
+	}
+
+	// Missing fields fail closed.
+	if err := e.Evaluate(Env{}, time.Now()); !errors.As(err, &v) || !errors.Is(v.Err, ErrUnknownField) {
+		t.Errorf("expected fail-closed violation, got %v", err)
+	}
+}
+
+func TestMarketsAddTo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "markets.json")
+	if err := os.WriteFile(path, []byte(`{"0xabc": {"category": "politics", "max_size": 500}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadMarkets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := Env{}
+	m.AddTo(env, "0xabc")
+	if env["market.category"] != "politics" || env["market.max_size"] != 500.0 {
+		t.Errorf("unexpected env: %v", env)
+	}
+}
+
+const samplePolicy = `
+version: 1
+rules:
+  - id: price-band
+    type: band
+    field: order.price
+    min: 0.02
+    max: 0.95
+  - id: max-notional
+    type: limit
+    field: order.value
+    max: 500
+  - id: sides
+    type: allowlist
+    field: order.side
+    values: [BUY]
+  - id: trading-hours
+    type: schedule
+    window: "22:00-06:00"
+    days: [mon]
+    timezone: UTC
+  - id: no-politics
+    type: expr
+    expr: market.category != "politics"
+`
+
+func TestParsePolicyEvaluate(t *testing.T) {
+	p, err := ParsePolicy([]byte(samplePolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	monNight := time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC)
+	env := Flatten(map[string]any{
+		"order":  map[string]any{"price": 0.5, "value": 100, "side": "BUY"},
+		"market": map[string]any{"category": "sports"},
+	})
+	if err := p.Check(env, monNight); err != nil {
+		t.Fatalf("expected pass, got %v", err)
+	}
+
+	// Outside the overnight window.
+	var v *Violation
+	if err := p.Check(env, monNight.Add(8*time.Hour)); !errors.As(err, &v) || v.Rule != "trading-hours" {
+		t.Errorf("expected trading-hours violation, got %v", err)
+	}
+
+	env["order.price"] = 0.97
+	env["order.value"] = 970
+	decisions := p.Evaluate(env, monNight)
+	if len(decisions) != 5 {
+		t.Fatalf("expected 5 decisions, got %d", len(decisions))
+	}
+	if d := decisions[0]; d.Passed || d.Threshold != "[0.02, 0.95]" || d.Actual != "order.price = 0.97" {
+		t.Errorf("unexpected band decision: %+v", d)
+	}
+	if d := decisions[1]; d.Passed || d.Threshold != "<= 500" {
+		t.Errorf("unexpected limit decision: %+v", d)
+	}
+	if !decisions[2].Passed || !decisions[3].Passed || !decisions[4].Passed {
+		t.Errorf("unexpected decisions: %+v", decisions)
+	}
+	if err := p.Check(env, monNight); !errors.As(err, &v) || v.Rule != "price-band" {
+		t.Errorf("expected first failing rule to be reported, got %v", err)
+	}
+	if err := p.Check(env, monNight, "price-band"); !errors.As(err, &v) || v.Rule != decisions[1].RuleID {
+		t.Errorf("expected waived rule to be skipped, got %v", err)
+	}
+}
+
+func TestPolicyStaleBook(t *testing.T) {
+	p, err := ParsePolicy([]byte(`
+version: 1
+max_book_age: 5
+rules:
+  - id: inside-ask
+    type: expr
+    expr: order.price <= book.best_ask
+  - id: spread
+    type: limit
+    field: book.spread
+    max: 0.1
+    on_stale: skip
+  - id: max-notional
+    type: limit
+    field: order.value
+    max: 500
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	env := Env{"order.price": 0.5, "order.value": 100.0, "book.best_ask": 0.51, "book.spread": 0.01, BookAgeField: 2.0}
+	if err := p.Check(env, now); err != nil {
+		t.Fatalf("fresh book: %v", err)
+	}
+
+	env[BookAgeField] = 30.0
+	var v *Violation
+	err = p.Check(env, now)
+	if !errors.As(err, &v) || v.Rule != "inside-ask" || !v.Stale || !errors.Is(err, ErrStaleData) || !errors.Is(err, ErrRejected) {
+		t.Fatalf("expected stale inside-ask violation, got %v", err)
+	}
+	if v.Actual != "book age 30s" || v.Threshold != "book age <= 5s" {
+		t.Errorf("unexpected violation %+v", v)
+	}
+
+	// on_stale: skip lets the order through that rule; rules not reading
+	// the book are unaffected.
+	d := p.Evaluate(env, now)
+	if !d[1].Stale || !d[1].Passed || d[2].Stale || !d[2].Passed {
+		t.Errorf("unexpected decisions %+v", d)
+	}
+
+	delete(env, BookAgeField)
+	if err := p.Check(env, now, "inside-ask"); err != nil {
+		t.Errorf("waived stale rule: %v", err)
+	}
+	if err := p.Check(env, now); !errors.As(err, &v) || !v.Stale || v.Actual != "no book" {
+		t.Errorf("missing book should be stale, got %v", err)
+	}
+
+	if _, err := ParsePolicy([]byte("version: 1\nrules:\n  - {id: x, type: limit, field: book.mid, max: 1, on_stale: maybe}\n")); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("expected ErrInvalidRule for bad on_stale, got %v", err)
+	}
+}
+
+func TestPolicyShadowRules(t *testing.T) {
+	p, err := ParsePolicy([]byte(`
+version: 1
+rules:
+  - id: max-notional
+    type: limit
+    field: order.value
+    max: 500
+  - id: tighter-notional
+    type: limit
+    field: order.value
+    max: 100
+    shadow: true
+  - id: no-politics
+    type: denylist
+    field: market.category
+    values: [politics]
+    shadow: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	env := Env{"order.value": 250.0, "market.category": "sports"}
+
+	// Shadow rules never reject; they only report.
+	if err := p.Check(env, now); err != nil {
+		t.Fatalf("shadow rule enforced: %v", err)
+	}
+	shadowed := p.Shadowed(env, now)
+	if len(shadowed) != 1 || shadowed[0].Rule != "tighter-notional" || !shadowed[0].Shadow {
+		t.Fatalf("unexpected shadow violations %+v", shadowed)
+	}
+	if d := p.Evaluate(env, now); d[0].Shadow || !d[1].Shadow || d[1].Passed {
+		t.Errorf("unexpected decisions %+v", d)
+	}
+
+	// An enforced failure is still reported by Check alone.
+	env["order.value"] = 900.0
+	var v *Violation
+	if err := p.Check(env, now); !errors.As(err, &v) || v.Rule != "max-notional" || v.Shadow {
+		t.Errorf("expected max-notional violation, got %v", err)
+	}
+
+	env["order.value"] = 50.0
+	if shadowed := p.Shadowed(env, now); len(shadowed) != 0 {
+		t.Errorf("expected no shadow violations, got %+v", shadowed)
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	_, err := ParsePolicy([]byte(`
+version: 2
+rules:
+  - type: limit
+    field: order.value
+  - id: band
+    type: band
+    field: order.price
+    min: 0.9
+    max: 0.1
+  - id: band
+    type: schedule
+    window: "9-17"
+    timezone: Mars/Olympus
+    days: [funday]
+  - id: bad-expr
+    type: expr
+    expr: "order.price <"
+`))
+	for _, want := range []error{ErrUnsupportedVersion, ErrInvalidRule, ErrDuplicateName, ErrSyntax} {
+		if !errors.Is(err, want) {
+			t.Errorf("expected %v in %v", want, err)
+		}
+	}
+	for _, substr := range []string{"max is required", "id is required", "exceeds max", "funday", "timezone", "9-17"} {
+		if err == nil || !strings.Contains(err.Error(), substr) {
+			t.Errorf("expected %q in %v", substr, err)
+		}
+	}
+
+	if _, err := ParsePolicy([]byte("version: 1\nrules:\n  - id: x\n    type: limit\n    feild: a\n")); err == nil {
+		t.Error("expected unknown key to be rejected")
+	}
+	if _, err := ParsePolicy([]byte(`{"version": 1, "rules": [{"id": "x", "type": "limit", "field": "order.value", "max": 10}]}`)); err != nil {
+		t.Errorf("expected JSON policy to parse, got %v", err)
+	}
+}
+
+func TestEngineLoadsPolicyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(samplePolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewEngine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(e.Policy().Rules); n != 5 {
+		t.Errorf("expected 5 rules, got %d", n)
+	}
+}
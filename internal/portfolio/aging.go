@@ -0,0 +1,126 @@
+package portfolio
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/order"
+	"github.com/caesar-terminal/caesar/internal/policy"
+)
+
+// ResolutionField is the policy market attribute holding a market's
+// expected resolution time, as an RFC 3339 string.
+const ResolutionField = "end_date"
+
+// AgingConfig sets when a held position is flagged.
+type AgingConfig struct {
+	// MaxAge flags positions held longer than this; zero disables.
+	MaxAge time.Duration
+	// ResolutionWindow flags positions whose market resolves within this
+	// long; zero disables.
+	ResolutionWindow time.Duration
+}
+
+// PositionAge is one line of the inventory aging report.
+type PositionAge struct {
+	TokenID  string
+	Shares   *big.Int
+	OpenedAt time.Time
+	Age      time.Duration
+	// Resolves is the market's expected resolution time; zero if unknown.
+	Resolves time.Time
+
+	Stale          bool // held longer than MaxAge
+	NearResolution bool // resolves within ResolutionWindow, or is overdue
+}
+
+// Aging reports how long each held position has been open, oldest first.
+// Flat positions and those with no opening time are left out. resolves
+// maps token IDs to resolution times (see ResolutionDates).
+func Aging(positions []order.Position, resolves map[string]time.Time, cfg AgingConfig, now time.Time) []PositionAge {
+	var report []PositionAge
+	for _, p := range positions {
+		if p.Shares.Sign() <= 0 || p.OpenedAt.IsZero() {
+			continue
+		}
+		a := PositionAge{
+			TokenID:  p.TokenID,
+			Shares:   new(big.Int).Set(p.Shares),
+			OpenedAt: p.OpenedAt,
+			Age:      now.Sub(p.OpenedAt),
+			Resolves: resolves[p.TokenID],
+		}
+		a.Stale = cfg.MaxAge > 0 && a.Age > cfg.MaxAge
+		a.NearResolution = cfg.ResolutionWindow > 0 && !a.Resolves.IsZero() && a.Resolves.Sub(now) <= cfg.ResolutionWindow
+		report = append(report, a)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if !report[i].OpenedAt.Equal(report[j].OpenedAt) {
+			return report[i].OpenedAt.Before(report[j].OpenedAt)
+		}
+		return report[i].TokenID < report[j].TokenID
+	})
+	return report
+}
+
+// ResolutionDates reads each market's ResolutionField. Markets without
+// one, or with a value that is not RFC 3339, are skipped.
+func ResolutionDates(markets policy.Markets) map[string]time.Time {
+	dates := make(map[string]time.Time)
+	for token, attrs := range markets {
+		s, _ := attrs[ResolutionField].(string)
+		if at, err := time.Parse(time.RFC3339, s); err == nil {
+			dates[token] = at
+		}
+	}
+	return dates
+}
+
+// AgingAlerts remembers which flags have been raised so a forgotten
+// position nags once per condition rather than on every check. Closing
+// and reopening a position starts it afresh.
+type AgingAlerts struct {
+	mu     sync.Mutex
+	raised map[string]agingRaised
+}
+
+type agingRaised struct {
+	openedAt             time.Time
+	stale, nearResolving bool
+}
+
+// NewAgingAlerts creates an empty alert tracker.
+func NewAgingAlerts() *AgingAlerts {
+	return &AgingAlerts{raised: make(map[string]agingRaised)}
+}
+
+// Due returns the entries of report with a flag not yet alerted on, and
+// marks them alerted. Positions missing from report are forgotten.
+func (a *AgingAlerts) Due(report []PositionAge) []PositionAge {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	seen := make(map[string]bool, len(report))
+	var due []PositionAge
+	for _, p := range report {
+		seen[p.TokenID] = true
+		r := a.raised[p.TokenID]
+		if !r.openedAt.Equal(p.OpenedAt) {
+			r = agingRaised{openedAt: p.OpenedAt}
+		}
+		if (p.Stale && !r.stale) || (p.NearResolution && !r.nearResolving) {
+			due = append(due, p)
+		}
+		r.stale = r.stale || p.Stale
+		r.nearResolving = r.nearResolving || p.NearResolution
+		a.raised[p.TokenID] = r
+	}
+	for token := range a.raised {
+		if !seen[token] {
+			delete(a.raised, token)
+		}
+	}
+	return due
+}
@@ -0,0 +1,56 @@
+package portfolio
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/order"
+	"github.com/caesar-terminal/caesar/internal/policy"
+)
+
+func TestAging(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	positions := []order.Position{
+		{TokenID: "fresh", Shares: big.NewInt(10), OpenedAt: now.Add(-time.Hour)},
+		{TokenID: "old", Shares: big.NewInt(10), OpenedAt: now.Add(-10 * 24 * time.Hour)},
+		{TokenID: "flat", Shares: big.NewInt(0)},
+		{TokenID: "resolving", Shares: big.NewInt(10), OpenedAt: now.Add(-2 * time.Hour)},
+	}
+	resolves := ResolutionDates(policy.Markets{
+		"resolving": {ResolutionField: "2026-03-02T00:00:00Z"},
+		"fresh":     {ResolutionField: "not a date"},
+	})
+	cfg := AgingConfig{MaxAge: 7 * 24 * time.Hour, ResolutionWindow: 24 * time.Hour}
+
+	report := Aging(positions, resolves, cfg, now)
+	if len(report) != 3 || report[0].TokenID != "old" || report[1].TokenID != "resolving" || report[2].TokenID != "fresh" {
+		t.Fatalf("report = %+v", report)
+	}
+	if !report[0].Stale || report[0].NearResolution || report[0].Age != 10*24*time.Hour {
+		t.Errorf("old: %+v", report[0])
+	}
+	if report[1].Stale || !report[1].NearResolution {
+		t.Errorf("resolving: %+v", report[1])
+	}
+	if report[2].Stale || report[2].NearResolution || !report[2].Resolves.IsZero() {
+		t.Errorf("fresh: %+v", report[2])
+	}
+
+	alerts := NewAgingAlerts()
+	if due := alerts.Due(report); len(due) != 2 {
+		t.Errorf("first check: %d due, want 2", len(due))
+	}
+	if due := alerts.Due(report); len(due) != 0 {
+		t.Errorf("repeat check alerted again: %+v", due)
+	}
+
+	// The other two age past MaxAge; the old one was closed and reopened,
+	// so it is stale again only when it is old again.
+	later := now.Add(8 * 24 * time.Hour)
+	positions[1].OpenedAt = later.Add(-time.Minute)
+	due := alerts.Due(Aging(positions, resolves, cfg, later))
+	if len(due) != 2 || due[0].TokenID != "resolving" || due[1].TokenID != "fresh" {
+		t.Errorf("later check: %+v", due)
+	}
+}
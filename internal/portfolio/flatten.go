@@ -0,0 +1,158 @@
+package portfolio
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	"github.com/caesar-terminal/caesar/internal/order"
+)
+
+// Executor carries out a flatten against the exchange.
+type Executor interface {
+	Cancel(ctx context.Context, orderIDs []string) error
+	Submit(ctx context.Context, legs []order.Leg) error
+}
+
+// FlattenConfig schedules the end-of-day flatten.
+type FlattenConfig struct {
+	// At is the time of day to flatten, as an offset from midnight in
+	// Location (UTC if nil).
+	At       time.Duration
+	Location *time.Location
+	// Close also submits orders closing the positions in Markets. Every
+	// open order is cancelled regardless.
+	Close   bool
+	Markets []string
+	// MaxSlippageBps and TickSize set closing limit prices as in
+	// RebalanceConfig.
+	MaxSlippageBps int64
+	TickSize       int64
+}
+
+// FlattenReport records everything one flatten did.
+type FlattenReport struct {
+	At        time.Time
+	Cancelled []string // order IDs
+	CancelErr error
+	// Closing are the closing orders; Submitted says whether they were
+	// sent, CloseErr why not (a refused approval or a submit failure).
+	Closing   []order.Leg
+	Submitted bool
+	CloseErr  error
+	Skipped   []FlattenSkip
+}
+
+// FlattenSkip is a selected position no closing order was built for.
+type FlattenSkip struct {
+	TokenID string
+	Reason  string
+}
+
+// Flattener cancels every open order at a set time each day and,
+// optionally, closes positions in selected markets.
+type Flattener struct {
+	cfg    FlattenConfig
+	orders *order.Store
+	ledger *order.Ledger
+	exec   Executor
+	clock  clock.Clock
+
+	// Mark returns the price closing orders are priced from. Positions
+	// without a mark are skipped.
+	Mark func(tokenID string) (int64, bool)
+	// Approve, if set, must accept the closing orders before they are
+	// submitted; an error leaves the positions open.
+	Approve func(ctx context.Context, legs []order.Leg) error
+	// OnReport receives the report of each flatten Run performs.
+	OnReport func(FlattenReport)
+}
+
+// NewFlattener creates a flattener over the order store and ledger. A
+// nil clock uses the wall clock.
+func NewFlattener(cfg FlattenConfig, orders *order.Store, ledger *order.Ledger, exec Executor, c clock.Clock) *Flattener {
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+	return &Flattener{cfg: cfg, orders: orders, ledger: ledger, exec: exec, clock: clock.Or(c)}
+}
+
+// Run flattens at the configured time every day until ctx is done.
+func (f *Flattener) Run(ctx context.Context) {
+	for {
+		now := f.clock.Now()
+		select {
+		case <-ctx.Done():
+			return
+		case <-f.clock.After(f.Next(now).Sub(now)):
+			r := f.Flatten(ctx)
+			if f.OnReport != nil {
+				f.OnReport(r)
+			}
+		}
+	}
+}
+
+// Next returns the first flatten time after now.
+func (f *Flattener) Next(now time.Time) time.Time {
+	local := now.In(f.cfg.Location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, f.cfg.Location).Add(f.cfg.At)
+	if !next.After(now) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, f.cfg.Location).Add(f.cfg.At)
+	}
+	return next
+}
+
+// Flatten runs one flatten now. Closing orders are only built once the
+// cancels have gone through, so a resting sell cannot double up with
+// its replacement.
+func (f *Flattener) Flatten(ctx context.Context) FlattenReport {
+	r := FlattenReport{At: f.clock.Now()}
+	for _, rec := range f.orders.Live() {
+		r.Cancelled = append(r.Cancelled, rec.ID)
+	}
+	if len(r.Cancelled) > 0 {
+		if r.CancelErr = f.exec.Cancel(ctx, r.Cancelled); r.CancelErr != nil {
+			return r
+		}
+	}
+	if !f.cfg.Close {
+		return r
+	}
+
+	positions := make(map[string]order.Position)
+	for _, p := range f.ledger.Positions() {
+		positions[p.TokenID] = p
+	}
+	markets := append([]string(nil), f.cfg.Markets...)
+	sort.Strings(markets)
+	for _, token := range markets {
+		p, ok := positions[token]
+		if !ok || p.Shares.Sign() <= 0 {
+			continue
+		}
+		var mark int64
+		if f.Mark != nil {
+			mark, ok = f.Mark(token)
+		}
+		if !ok || mark <= 0 {
+			r.Skipped = append(r.Skipped, FlattenSkip{TokenID: token, Reason: "no mark price"})
+			continue
+		}
+		price := limitPrice(mark, order.SideSell, RebalanceConfig{MaxSlippageBps: f.cfg.MaxSlippageBps, TickSize: f.cfg.TickSize})
+		r.Closing = append(r.Closing, order.NewLeg(token, order.SideSell, price, p.Shares))
+	}
+	if len(r.Closing) == 0 {
+		return r
+	}
+	if f.Approve != nil {
+		if r.CloseErr = f.Approve(ctx, r.Closing); r.CloseErr != nil {
+			return r
+		}
+	}
+	if r.CloseErr = f.exec.Submit(ctx, r.Closing); r.CloseErr == nil {
+		r.Submitted = true
+	}
+	return r
+}
@@ -0,0 +1,121 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	"github.com/caesar-terminal/caesar/internal/order"
+)
+
+type fakeExecutor struct {
+	cancelled []string
+	submitted []order.Leg
+	cancelErr error
+}
+
+func (e *fakeExecutor) Cancel(_ context.Context, ids []string) error {
+	if e.cancelErr != nil {
+		return e.cancelErr
+	}
+	e.cancelled = append(e.cancelled, ids...)
+	return nil
+}
+
+func (e *fakeExecutor) Submit(_ context.Context, legs []order.Leg) error {
+	e.submitted = append(e.submitted, legs...)
+	return nil
+}
+
+func flattenFixture() (*order.Store, *order.Ledger) {
+	store := order.NewStore()
+	store.Track("o1", order.NewLeg("a", order.SideBuy, 400_000, big.NewInt(10)))
+	store.Track("o2", order.NewLeg("b", order.SideSell, 600_000, big.NewInt(10)))
+	store.Track("done", order.NewLeg("a", order.SideBuy, 400_000, big.NewInt(10)))
+	_ = store.Transition("done", order.StateCancelled)
+
+	ledger := order.NewLedger()
+	ledger.Apply(order.Trade{ID: "t1", TokenID: "a", Side: order.SideBuy, Price: 500_000, Size: big.NewInt(100)})
+	ledger.Apply(order.Trade{ID: "t2", TokenID: "b", Side: order.SideBuy, Price: 500_000, Size: big.NewInt(50)})
+	ledger.Apply(order.Trade{ID: "t3", TokenID: "c", Side: order.SideBuy, Price: 500_000, Size: big.NewInt(20)})
+	return store, ledger
+}
+
+func TestFlatten(t *testing.T) {
+	store, ledger := flattenFixture()
+	exec := &fakeExecutor{}
+	cfg := FlattenConfig{Close: true, Markets: []string{"a", "c", "missing"}, MaxSlippageBps: 100, TickSize: 1_000}
+	f := NewFlattener(cfg, store, ledger, exec, nil)
+	f.Mark = func(token string) (int64, bool) { return 500_000, token == "a" }
+	var approved []order.Leg
+	f.Approve = func(_ context.Context, legs []order.Leg) error { approved = legs; return nil }
+
+	r := f.Flatten(context.Background())
+	if !slices.Equal(r.Cancelled, []string{"o1", "o2"}) || !slices.Equal(exec.cancelled, r.Cancelled) {
+		t.Errorf("cancelled = %v, executor saw %v", r.Cancelled, exec.cancelled)
+	}
+	// b is not selected, c has no mark and missing has no position.
+	if len(r.Closing) != 1 || r.Closing[0].TokenID != "a" || r.Closing[0].Side != order.SideSell || r.Closing[0].Size.Int64() != 100 || r.Closing[0].Price != 495_000 {
+		t.Errorf("closing = %+v", r.Closing)
+	}
+	if len(r.Skipped) != 1 || r.Skipped[0].TokenID != "c" {
+		t.Errorf("skipped = %+v", r.Skipped)
+	}
+	if !r.Submitted || len(approved) != 1 || len(exec.submitted) != 1 {
+		t.Errorf("submitted=%v approved=%d sent=%d", r.Submitted, len(approved), len(exec.submitted))
+	}
+
+	// A refused approval leaves positions open; a failed cancel stops
+	// before any closing order is built.
+	exec = &fakeExecutor{}
+	f = NewFlattener(cfg, store, ledger, exec, nil)
+	f.Mark = func(string) (int64, bool) { return 500_000, true }
+	refused := errors.New("operator declined")
+	f.Approve = func(context.Context, []order.Leg) error { return refused }
+	if r := f.Flatten(context.Background()); r.Submitted || !errors.Is(r.CloseErr, refused) || len(exec.submitted) != 0 {
+		t.Errorf("refused approval: %+v", r)
+	}
+	exec.cancelErr = errors.New("exchange down")
+	if r := f.Flatten(context.Background()); r.CancelErr == nil || r.Closing != nil {
+		t.Errorf("failed cancel: %+v", r)
+	}
+}
+
+func TestFlattenerSchedule(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	store, ledger := flattenFixture()
+	clk := clock.NewFake(time.Date(2026, 3, 2, 20, 0, 0, 0, time.UTC)) // 15:00 in New York
+	f := NewFlattener(FlattenConfig{At: 16 * time.Hour, Location: ny}, store, ledger, &fakeExecutor{}, clk)
+
+	if next := f.Next(clk.Now()); !next.Equal(time.Date(2026, 3, 2, 21, 0, 0, 0, time.UTC)) {
+		t.Errorf("next = %v", next)
+	}
+	if next := f.Next(time.Date(2026, 3, 2, 21, 0, 0, 0, time.UTC)); !next.Equal(time.Date(2026, 3, 3, 21, 0, 0, 0, time.UTC)) {
+		t.Errorf("next after a run = %v", next)
+	}
+
+	reports := make(chan FlattenReport, 1)
+	f.OnReport = func(r FlattenReport) { reports <- r }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.Run(ctx)
+	for clk.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clk.Advance(time.Hour)
+	select {
+	case r := <-reports:
+		if len(r.Cancelled) != 2 || r.Closing != nil {
+			t.Errorf("report = %+v", r)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no flatten at the scheduled time")
+	}
+}
@@ -0,0 +1,142 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/caesar-terminal/caesar/internal/order"
+)
+
+var ErrInsufficientCollateral = errors.New("orders could over-commit collateral if they all fill")
+
+// MarketMargin is the worst-case collateral one market's resting orders
+// need if every one of them fills.
+type MarketMargin struct {
+	TokenID string
+	// Position is the shares held, net of trades applied so far.
+	Position *big.Int
+	// BuyNotional is the USDC paid if every resting buy fills.
+	BuyNotional *big.Int
+	// SellShares is the shares delivered if every resting sell fills.
+	SellShares *big.Int
+	// Required is the USDC the market can consume: BuyNotional plus the
+	// sell shares beyond Position, which must first be minted from
+	// complete sets at 1 USDC each.
+	Required *big.Int
+}
+
+// Margin combines positions and resting orders into the collateral they
+// could consume across every market at once. Quoting many markets
+// simultaneously commits the same USDC to every quote; Margin counts it
+// once per order so the total can be checked against the balance.
+type Margin struct {
+	markets map[string]*MarketMargin
+}
+
+// NewMargin builds the margin view from ledger positions and live orders
+// (order.Store.Live). Only the unfilled remainder of each order counts;
+// terminal orders are ignored.
+func NewMargin(positions []order.Position, resting []order.Record) *Margin {
+	m := &Margin{markets: make(map[string]*MarketMargin)}
+	for _, p := range positions {
+		if p.Shares.Sign() > 0 {
+			m.market(p.TokenID).Position.Set(p.Shares)
+		}
+	}
+	for _, r := range resting {
+		if r.State.Terminal() {
+			continue
+		}
+		remaining := new(big.Int).Sub(r.Size, r.Filled)
+		if remaining.Sign() > 0 {
+			m.add(r.TokenID, r.Side, r.Price, remaining)
+		}
+	}
+	return m
+}
+
+func (m *Margin) market(tokenID string) *MarketMargin {
+	mm, ok := m.markets[tokenID]
+	if !ok {
+		mm = &MarketMargin{
+			TokenID:     tokenID,
+			Position:    new(big.Int),
+			BuyNotional: new(big.Int),
+			SellShares:  new(big.Int),
+		}
+		m.markets[tokenID] = mm
+	}
+	return mm
+}
+
+func (m *Margin) add(tokenID string, side order.Side, price int64, size *big.Int) {
+	mm := m.market(tokenID)
+	if side == order.SideBuy {
+		mm.BuyNotional.Add(mm.BuyNotional, order.NewLeg(tokenID, side, price, size).Notional())
+	} else {
+		mm.SellShares.Add(mm.SellShares, size)
+	}
+}
+
+func (mm *MarketMargin) required() *big.Int {
+	req := new(big.Int).Set(mm.BuyNotional)
+	if short := new(big.Int).Sub(mm.SellShares, mm.Position); short.Sign() > 0 {
+		req.Add(req, short)
+	}
+	return req
+}
+
+func (mm *MarketMargin) clone() *MarketMargin {
+	return &MarketMargin{
+		TokenID:     mm.TokenID,
+		Position:    new(big.Int).Set(mm.Position),
+		BuyNotional: new(big.Int).Set(mm.BuyNotional),
+		SellShares:  new(big.Int).Set(mm.SellShares),
+	}
+}
+
+// Markets returns the per-market view, sorted by token ID. Markets with
+// a position but no resting orders are included with a zero requirement.
+func (m *Margin) Markets() []MarketMargin {
+	out := make([]MarketMargin, 0, len(m.markets))
+	for _, mm := range m.markets {
+		c := mm.clone()
+		c.Required = mm.required()
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TokenID < out[j].TokenID })
+	return out
+}
+
+// Required returns the aggregate worst-case collateral across markets.
+func (m *Margin) Required() *big.Int {
+	total := new(big.Int)
+	for _, mm := range m.markets {
+		total.Add(total, mm.required())
+	}
+	return total
+}
+
+// With returns the margin after legs are placed as well. m is unchanged.
+func (m *Margin) With(legs []order.Leg) *Margin {
+	next := &Margin{markets: make(map[string]*MarketMargin, len(m.markets))}
+	for id, mm := range m.markets {
+		next.markets[id] = mm.clone()
+	}
+	for _, l := range legs {
+		next.add(l.TokenID, l.Side, l.Price, l.Size)
+	}
+	return next
+}
+
+// Afford checks that legs can be placed on top of the resting orders
+// without the worst case exceeding collateral, the USDC balance. It is
+// all or nothing, like order.ValidateBatch for the session value limit.
+func (m *Margin) Afford(legs []order.Leg, collateral *big.Int) error {
+	if need := m.With(legs).Required(); need.Cmp(collateral) > 0 {
+		return fmt.Errorf("%w: need %s, have %s", ErrInsufficientCollateral, need, collateral)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package portfolio
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/caesar-terminal/caesar/internal/order"
+)
+
+func TestMargin(t *testing.T) {
+	store := order.NewStore()
+	store.Track("b1", order.NewLeg("a", order.SideBuy, 400_000, big.NewInt(100_000_000))) // 40 USDC
+	store.Track("b2", order.NewLeg("b", order.SideBuy, 500_000, big.NewInt(100_000_000))) // 50 USDC
+	store.Track("s1", order.NewLeg("a", order.SideSell, 600_000, big.NewInt(80_000_000)))
+	store.Track("s2", order.NewLeg("c", order.SideSell, 700_000, big.NewInt(30_000_000)))
+	store.Track("gone", order.NewLeg("a", order.SideBuy, 450_000, big.NewInt(100_000_000)))
+	_ = store.RecordFill("b2", big.NewInt(40_000_000)) // 60 shares left: 30 USDC
+	_ = store.Transition("gone", order.StateCancelled)
+
+	positions := []order.Position{
+		{TokenID: "a", Shares: big.NewInt(50_000_000)},
+		{TokenID: "c", Shares: big.NewInt(50_000_000)},
+	}
+	m := NewMargin(positions, store.Live())
+
+	// a: 40 USDC of buys plus 30 sell shares beyond the position to mint.
+	// b: 30 USDC of buys. c: the sell is covered by the position.
+	want := map[string]int64{"a": 70_000_000, "b": 30_000_000, "c": 0}
+	markets := m.Markets()
+	if len(markets) != len(want) {
+		t.Fatalf("markets = %+v", markets)
+	}
+	for _, mm := range markets {
+		if mm.Required.Int64() != want[mm.TokenID] {
+			t.Errorf("%s: required = %s, want %d", mm.TokenID, mm.Required, want[mm.TokenID])
+		}
+	}
+	if got := m.Required(); got.Int64() != 100_000_000 {
+		t.Errorf("aggregate = %s, want 100 USDC", got)
+	}
+
+	// Selling the rest of c's position is free; a 20 USDC buy is not.
+	legs := []order.Leg{
+		order.NewLeg("c", order.SideSell, 700_000, big.NewInt(20_000_000)),
+		order.NewLeg("d", order.SideBuy, 200_000, big.NewInt(100_000_000)),
+	}
+	if err := m.Afford(legs, big.NewInt(120_000_000)); err != nil {
+		t.Errorf("exact fit: %v", err)
+	}
+	if err := m.Afford(legs, big.NewInt(119_999_999)); !errors.Is(err, ErrInsufficientCollateral) {
+		t.Errorf("expected ErrInsufficientCollateral, got %v", err)
+	}
+	if got := m.Required(); got.Int64() != 100_000_000 {
+		t.Errorf("Afford changed the margin: %s", got)
+	}
+}
+
+func TestPlanChecksRestingMargin(t *testing.T) {
+	holdings := []Holding{{TokenID: "a", Shares: big.NewInt(0), Mark: 500_000}}
+	resting := NewMargin(nil, []order.Record{{
+		TokenID: "b", Side: order.SideBuy, Price: 500_000,
+		Size: big.NewInt(100_000_000), Filled: new(big.Int), State: order.StateOpen,
+	}})
+	cfg := RebalanceConfig{Cash: big.NewInt(100_000_000), TickSize: 1_000, Resting: resting}
+
+	// Half the cash is already quoted in b, so buying 60 USDC of a could
+	// over-commit it.
+	if _, err := Plan(holdings, map[string]float64{"a": 0.6}, cfg, big.NewInt(1_000_000_000)); !errors.Is(err, ErrInsufficientCollateral) {
+		t.Errorf("expected ErrInsufficientCollateral, got %v", err)
+	}
+	if _, err := Plan(holdings, map[string]float64{"a": 0.4}, cfg, big.NewInt(1_000_000_000)); err != nil {
+		t.Errorf("within margin: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/caesar-terminal/caesar/internal/order"
+)
+
+var (
+	ErrInvalidWeights = errors.New("target weights must be non-negative and sum to at most 1")
+	ErrMissingMark    = errors.New("no mark price for target market")
+)
+
+// Holding is a current position marked at the latest price.
+type Holding struct {
+	TokenID string
+	Shares  *big.Int // raw share units
+	Mark    int64    // fixed-point price (order.PriceScale)
+}
+
+// Value returns the USDC value of the holding in atomic units.
+func (h Holding) Value() *big.Int {
+	v := new(big.Int).Mul(h.Shares, big.NewInt(h.Mark))
+	return v.Quo(v, big.NewInt(order.PriceScale))
+}
+
+// RebalanceConfig bounds the order set produced by Plan.
+type RebalanceConfig struct {
+	// Cash is uncommitted USDC counted towards portfolio value.
+	Cash *big.Int
+	// MaxSlippageBps caps how far limit prices may sit from the mark.
+	MaxSlippageBps int64
+	// TickSize is the price increment limit prices are rounded to.
+	TickSize int64
+	// MinTradeNotional skips adjustments smaller than this USDC amount.
+	MinTradeNotional *big.Int
+	// Resting, when set, is the margin of orders already resting; the plan
+	// must then fit within Cash alongside them (see Margin.Afford), so Cash
+	// is the whole balance, including what those orders commit.
+	Resting *Margin
+}
+
+// Plan computes the orders that move holdings toward targets, a map of
+// token ID to weight of total portfolio value (holdings plus cash).
+// Markets absent from targets are left untouched. Sells are ordered before
+// buys so freed collateral is available, and the combined buy notional is
+// validated against remaining so the plan is approved or rejected whole.
+//
+// Plan only computes the order set; execution happens after the caller
+// (operator approval flow) accepts it.
+func Plan(holdings []Holding, targets map[string]float64, cfg RebalanceConfig, remaining *big.Int) ([]order.Leg, error) {
+	if err := validateWeights(targets); err != nil {
+		return nil, err
+	}
+
+	byToken := make(map[string]Holding, len(holdings))
+	nav := new(big.Int)
+	if cfg.Cash != nil {
+		nav.Set(cfg.Cash)
+	}
+	for _, h := range holdings {
+		byToken[h.TokenID] = h
+		nav.Add(nav, h.Value())
+	}
+
+	tokens := make([]string, 0, len(targets))
+	for t := range targets {
+		tokens = append(tokens, t)
+	}
+	sort.Strings(tokens)
+
+	var sells, buys []order.Leg
+	for _, token := range tokens {
+		h, ok := byToken[token]
+		if !ok || h.Mark <= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrMissingMark, token)
+		}
+
+		target := new(big.Float).Mul(new(big.Float).SetInt(nav), big.NewFloat(targets[token]))
+		targetValue, _ := target.Int(nil)
+		delta := new(big.Int).Sub(targetValue, h.Value())
+
+		if cfg.MinTradeNotional != nil && new(big.Int).Abs(delta).Cmp(cfg.MinTradeNotional) < 0 {
+			continue
+		}
+
+		// Convert the USDC delta into shares at the mark.
+		shares := new(big.Int).Mul(new(big.Int).Abs(delta), big.NewInt(order.PriceScale))
+		shares.Quo(shares, big.NewInt(h.Mark))
+		if shares.Sign() == 0 {
+			continue
+		}
+
+		if delta.Sign() > 0 {
+			buys = append(buys, order.NewLeg(token, order.SideBuy, limitPrice(h.Mark, order.SideBuy, cfg), shares))
+		} else {
+			if shares.Cmp(h.Shares) > 0 {
+				shares.Set(h.Shares)
+			}
+			sells = append(sells, order.NewLeg(token, order.SideSell, limitPrice(h.Mark, order.SideSell, cfg), shares))
+		}
+	}
+
+	if err := order.ValidateBatch(buys, remaining); err != nil {
+		return nil, err
+	}
+
+	legs := append(sells, buys...)
+	if cfg.Resting != nil {
+		cash := cfg.Cash
+		if cash == nil {
+			cash = new(big.Int)
+		}
+		if err := cfg.Resting.Afford(legs, cash); err != nil {
+			return nil, err
+		}
+	}
+	return legs, nil
+}
+
+// limitPrice offsets mark by the slippage budget in the adverse direction,
+// rounding toward the mark so the cap is never exceeded.
+func limitPrice(mark int64, side order.Side, cfg RebalanceConfig) int64 {
+	offset := mark * cfg.MaxSlippageBps / 10_000
+	tick := cfg.TickSize
+	if tick <= 0 {
+		tick = 1
+	}
+
+	if side == order.SideBuy {
+		p := (mark + offset) / tick * tick
+		if p >= order.PriceScale {
+			p = order.PriceScale - tick
+		}
+		return p
+	}
+
+	p := (mark - offset + tick - 1) / tick * tick
+	if p <= 0 {
+		p = tick
+	}
+	return p
+}
+
+func validateWeights(targets map[string]float64) error {
+	var sum float64
+	for _, w := range targets {
+		if w < 0 {
+			return ErrInvalidWeights
+		}
+		sum += w
+	}
+	if sum > 1 {
+		return ErrInvalidWeights
+	}
+	return nil
+}
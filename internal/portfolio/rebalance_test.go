@@ -0,0 +1,79 @@
+package portfolio
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/caesar-terminal/caesar/internal/order"
+)
+
+func TestPlanRebalance(t *testing.T) {
+	holdings := []Holding{
+		{TokenID: "a", Shares: big.NewInt(200_000_000), Mark: 500_000}, // 100 USDC
+		{TokenID: "b", Shares: big.NewInt(0), Mark: 250_000},           // 0 USDC
+	}
+	cfg := RebalanceConfig{
+		Cash:           big.NewInt(100_000_000), // NAV = 200 USDC
+		MaxSlippageBps: 100,
+		TickSize:       1_000,
+	}
+
+	legs, err := Plan(holdings, map[string]float64{"a": 0.25, "b": 0.25}, cfg, big.NewInt(1_000_000_000))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(legs) != 2 {
+		t.Fatalf("expected 2 legs, got %d", len(legs))
+	}
+
+	sell, buy := legs[0], legs[1]
+	if sell.TokenID != "a" || sell.Side != order.SideSell {
+		t.Fatalf("expected sell of a first, got %+v", sell)
+	}
+	// a: 100 → 50 USDC, sell 100 shares at 0.50 less 1% = 0.495.
+	if sell.Size.Int64() != 100_000_000 || sell.Price != 495_000 {
+		t.Errorf("sell leg size=%s price=%d", sell.Size, sell.Price)
+	}
+
+	if buy.TokenID != "b" || buy.Side != order.SideBuy {
+		t.Fatalf("expected buy of b second, got %+v", buy)
+	}
+	// b: 0 → 50 USDC, buy 200 shares at 0.25 plus 1% = 0.2525 → 0.252 on a 0.001 tick.
+	if buy.Size.Int64() != 200_000_000 || buy.Price != 252_000 {
+		t.Errorf("buy leg size=%s price=%d", buy.Size, buy.Price)
+	}
+}
+
+func TestPlanRespectsLimitAndInputs(t *testing.T) {
+	holdings := []Holding{{TokenID: "a", Shares: big.NewInt(0), Mark: 500_000}}
+	cfg := RebalanceConfig{Cash: big.NewInt(100_000_000), TickSize: 1_000}
+
+	if _, err := Plan(holdings, map[string]float64{"a": 1}, cfg, big.NewInt(1_000_000)); !errors.Is(err, order.ErrBatchExceedsLimit) {
+		t.Errorf("expected ErrBatchExceedsLimit, got %v", err)
+	}
+	if _, err := Plan(holdings, map[string]float64{"a": 0.8, "b": 0.3}, cfg, big.NewInt(0)); !errors.Is(err, ErrInvalidWeights) {
+		t.Errorf("expected ErrInvalidWeights, got %v", err)
+	}
+	if _, err := Plan(holdings, map[string]float64{"z": 0.1}, cfg, big.NewInt(0)); !errors.Is(err, ErrMissingMark) {
+		t.Errorf("expected ErrMissingMark, got %v", err)
+	}
+}
+
+func TestPlanSkipsDust(t *testing.T) {
+	holdings := []Holding{{TokenID: "a", Shares: big.NewInt(100_000_000), Mark: 500_000}}
+	cfg := RebalanceConfig{
+		Cash:             big.NewInt(50_000_000),
+		TickSize:         1_000,
+		MinTradeNotional: big.NewInt(5_000_000),
+	}
+
+	// NAV 100 USDC; target 48 vs 50 held is a 2 USDC move, below the 5 USDC floor.
+	legs, err := Plan(holdings, map[string]float64{"a": 0.48}, cfg, big.NewInt(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(legs) != 0 {
+		t.Errorf("expected dust adjustment to be skipped, got %d legs", len(legs))
+	}
+}
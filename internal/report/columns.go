@@ -0,0 +1,182 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/caesar-terminal/caesar/internal/policy"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the columns file version this build understands.
+const SchemaVersion = 1
+
+var ErrInvalidColumns = errors.New("invalid report columns")
+
+// Columns are computed fields appended to an export, written as YAML or
+// JSON:
+//
+//	version: 1
+//	vars:
+//	  fx.eur_per_usd: 0.92
+//	columns:
+//	  - name: notional_eur
+//	    expr: round(price * size * fx.eur_per_usd, 2)
+//	  - name: strategy
+//	    expr: split(client, "-", 0)
+//
+// Expressions use the policy expression language over the export's own
+// fields, named after its header, plus Vars. A column may use the columns
+// before it.
+type Columns struct {
+	Version int            `yaml:"version" json:"version"`
+	Vars    map[string]any `yaml:"vars,omitempty" json:"vars,omitempty"`
+	Columns []Column       `yaml:"columns" json:"columns"`
+}
+
+// Column is one computed field.
+type Column struct {
+	Name string `yaml:"name" json:"name"`
+	Expr string `yaml:"expr" json:"expr"`
+
+	compiled *policy.Expr
+}
+
+// Parse decodes and validates a columns file. Unknown keys are rejected.
+func Parse(data []byte) (*Columns, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var c Columns
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decode report columns: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Load reads and parses the columns file at path. An empty path means no
+// computed columns.
+func Load(path string) (*Columns, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read report columns: %w", err)
+	}
+	return Parse(data)
+}
+
+// Validate checks the file and compiles its expressions, reporting every
+// problem found.
+func (c *Columns) Validate() error {
+	var errs []error
+	if c.Version != SchemaVersion {
+		errs = append(errs, fmt.Errorf("%w: version %d (want %d)", ErrInvalidColumns, c.Version, SchemaVersion))
+	}
+	for name, v := range c.Vars {
+		switch x := v.(type) {
+		case int:
+			c.Vars[name] = float64(x)
+		case float64, string, bool:
+		default:
+			errs = append(errs, fmt.Errorf("%w: var %s is %T, want a number, string or bool", ErrInvalidColumns, name, v))
+		}
+	}
+	seen := make(map[string]bool)
+	for i := range c.Columns {
+		col := &c.Columns[i]
+		switch {
+		case col.Name == "":
+			errs = append(errs, fmt.Errorf("%w: column %d has no name", ErrInvalidColumns, i+1))
+			continue
+		case seen[col.Name]:
+			errs = append(errs, fmt.Errorf("%w: duplicate column %s", ErrInvalidColumns, col.Name))
+		}
+		seen[col.Name] = true
+		e, err := policy.Compile(col.Expr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: column %s: %v", ErrInvalidColumns, col.Name, err))
+			continue
+		}
+		col.compiled = e
+	}
+	return errors.Join(errs...)
+}
+
+// Uses reports whether any column reads field.
+func (c *Columns) Uses(field string) bool {
+	if c == nil {
+		return false
+	}
+	for _, col := range c.Columns {
+		if slices.Contains(col.compiled.Fields(), field) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetVar sets a var from outside the file, such as a live FX rate.
+func (c *Columns) SetVar(name string, v any) {
+	if c.Vars == nil {
+		c.Vars = make(map[string]any)
+	}
+	c.Vars[name] = v
+}
+
+// Names returns the column headers in order.
+func (c *Columns) Names() []string {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, len(c.Columns))
+	for i, col := range c.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// Row evaluates every column against one export row's fields. Fields take
+// precedence over vars of the same name.
+func (c *Columns) Row(fields map[string]any) ([]string, error) {
+	if c == nil {
+		return nil, nil
+	}
+	env := make(policy.Env, len(c.Vars)+len(fields)+len(c.Columns))
+	for k, v := range c.Vars {
+		env[k] = v
+	}
+	for k, v := range fields {
+		env[k] = v
+	}
+	row := make([]string, len(c.Columns))
+	for i, col := range c.Columns {
+		v, err := col.compiled.Value(env)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", col.Name, err)
+		}
+		env[col.Name] = v
+		row[i] = format(v)
+	}
+	return row, nil
+}
+
+func format(v any) string {
+	switch x := v.(type) {
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case string:
+		return x
+	}
+	return fmt.Sprint(v)
+}
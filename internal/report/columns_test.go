@@ -0,0 +1,74 @@
+package report
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestColumnsRow(t *testing.T) {
+	cols, err := Parse([]byte(`
+version: 1
+vars:
+  fx.eur_per_usd: 0.9
+  desk: london
+columns:
+  - name: notional_eur
+    expr: round(price * size * fx.eur_per_usd, 2)
+  - name: strategy
+    expr: split(client, "-", 0)
+  - name: large
+    expr: notional_eur > 100
+  - name: book
+    expr: desk + "/" + strategy
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cols.Names(); !slices.Equal(got, []string{"notional_eur", "strategy", "large", "book"}) {
+		t.Errorf("names = %v", got)
+	}
+	row, err := cols.Row(map[string]any{"price": 0.55, "size": 250.0, "client": "momo-eu-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"123.75", "momo", "true", "london/momo"}; !slices.Equal(row, want) {
+		t.Errorf("row = %v, want %v", row, want)
+	}
+
+	if _, err := cols.Row(map[string]any{"price": 0.55}); err == nil || !strings.Contains(err.Error(), "notional_eur") {
+		t.Errorf("missing field: expected an error naming the column, got %v", err)
+	}
+
+	if !cols.Uses("client") || cols.Uses("fx.rate") {
+		t.Error("Uses does not match the columns' fields")
+	}
+	cols.SetVar("fx.eur_per_usd", 1.0)
+	if row, _ := cols.Row(map[string]any{"price": 0.55, "size": 250.0, "client": "momo-eu-1"}); row[0] != "137.5" {
+		t.Errorf("after SetVar, notional_eur = %s", row[0])
+	}
+
+	var none *Columns
+	if row, err := none.Row(map[string]any{}); row != nil || err != nil || none.Names() != nil {
+		t.Error("nil columns should add nothing")
+	}
+}
+
+func TestParseColumnsInvalid(t *testing.T) {
+	for name, src := range map[string]string{
+		"version":     "version: 2\ncolumns: []",
+		"unknown key": "version: 1\ncolums: []",
+		"no name":     "version: 1\ncolumns: [{expr: price}]",
+		"duplicate":   "version: 1\ncolumns: [{name: a, expr: price}, {name: a, expr: size}]",
+		"syntax":      "version: 1\ncolumns: [{name: a, expr: 'price *'}]",
+		"var type":    "version: 1\nvars: {fx: [1, 2]}\ncolumns: []",
+	} {
+		_, err := Parse([]byte(src))
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		} else if name != "unknown key" && !errors.Is(err, ErrInvalidColumns) {
+			t.Errorf("%s: expected ErrInvalidColumns, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+// ErrBudgetExhausted wraps the last error when a retry was denied because
+// the shared retry budget is depleted.
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+// Class groups operations that share a retry policy.
+type Class string
+
+const (
+	ClassSubmit Class = "submit"
+	ClassCancel Class = "cancel"
+	ClassFetch  Class = "fetch"
+)
+
+// Policy controls retries for one operation class.
+type Policy struct {
+	// MaxAttempts includes the first try; 1 disables retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; each further retry
+	// multiplies it by Multiplier, capped at MaxDelay.
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	Multiplier float64
+	// Jitter randomises each delay by ±Jitter (0–1) so clients that fail
+	// together do not retry in lockstep.
+	Jitter float64
+	// MaxElapsed stops retrying once this much time has passed since the
+	// first attempt. Zero means no cap.
+	MaxElapsed time.Duration
+}
+
+// DefaultPolicies are conservative per-class defaults. Submits retry least:
+// a timed-out submit may have reached the book, and resubmitting a signed
+// order is only safe because the exchange rejects duplicates.
+func DefaultPolicies() map[Class]Policy {
+	return map[Class]Policy{
+		ClassSubmit: {MaxAttempts: 2, BaseDelay: 100 * time.Millisecond, MaxDelay: 500 * time.Millisecond, Multiplier: 2, Jitter: 0.2, MaxElapsed: 2 * time.Second},
+		ClassCancel: {MaxAttempts: 5, BaseDelay: 50 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2, Jitter: 0.2, MaxElapsed: 5 * time.Second},
+		ClassFetch:  {MaxAttempts: 4, BaseDelay: 200 * time.Millisecond, MaxDelay: 5 * time.Second, Multiplier: 2, Jitter: 0.5, MaxElapsed: 30 * time.Second},
+	}
+}
+
+// backoff returns the un-jittered delay before retry n (1-based).
+func (p Policy) backoff(n int) time.Duration {
+	mult := p.Multiplier
+	if mult < 1 {
+		mult = 1
+	}
+	d := float64(p.BaseDelay) * math.Pow(mult, float64(n-1))
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	return time.Duration(d)
+}
+
+// Temporary is implemented by errors that are safe to retry, such as
+// adapter.ExchangeError for rate limits and 5xx responses.
+type Temporary interface {
+	Temporary() bool
+}
+
+// IsRetryable reports whether err, or any error it wraps, is temporary.
+// Context cancellation is never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var t Temporary
+	return errors.As(err, &t) && t.Temporary()
+}
+
+// Budget caps retries across all callers sharing it, so a degraded
+// exchange sees a bounded amount of extra load. It follows gRPC's retry
+// throttling: each failure costs one token, each success refunds Ratio
+// tokens, and retries are allowed only while more than half the tokens
+// remain.
+type Budget struct {
+	mu     sync.Mutex
+	max    float64
+	ratio  float64
+	tokens float64
+}
+
+// NewBudget creates a full budget of maxTokens.
+func NewBudget(maxTokens, ratio float64) *Budget {
+	return &Budget{max: maxTokens, ratio: ratio, tokens: maxTokens}
+}
+
+func (b *Budget) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.max, b.tokens+b.ratio)
+}
+
+// onFailure records a failure and reports whether a retry is allowed.
+func (b *Budget) onFailure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Max(0, b.tokens-1)
+	return b.tokens > b.max/2
+}
+
+// Stats counts outcomes for one class.
+type Stats struct {
+	Calls        int64 // Do invocations
+	Retries      int64 // attempts after the first
+	Exhausted    int64 // calls that failed after using every attempt or MaxElapsed
+	BudgetDenied int64 // retries refused by the budget
+}
+
+type counters struct {
+	calls, retries, exhausted, budgetDenied atomic.Int64
+}
+
+// Retrier runs operations under per-class policies.
+type Retrier struct {
+	policies map[Class]Policy
+	budget   *Budget
+	clock    clock.Clock
+	rand     func() float64
+
+	mu    sync.Mutex
+	stats map[Class]*counters
+}
+
+// Option configures a Retrier.
+type Option func(*Retrier)
+
+// WithBudget shares a retry budget across every class.
+func WithBudget(b *Budget) Option {
+	return func(r *Retrier) { r.budget = b }
+}
+
+// WithClock sets the time source for backoff sleeps.
+func WithClock(c clock.Clock) Option {
+	return func(r *Retrier) { r.clock = clock.Or(c) }
+}
+
+// NewRetrier creates a Retrier. Classes missing from policies run once
+// without retries.
+func NewRetrier(policies map[Class]Policy, opts ...Option) *Retrier {
+	r := &Retrier{
+		policies: policies,
+		clock:    clock.Real{},
+		rand:     rand.Float64,
+		stats:    make(map[Class]*counters),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Do calls fn until it succeeds, returns a non-retryable error, or the
+// class policy or budget stops it. The last error is returned.
+func (r *Retrier) Do(ctx context.Context, class Class, fn func(ctx context.Context) error) error {
+	p, ok := r.policies[class]
+	if !ok || p.MaxAttempts < 1 {
+		p = Policy{MaxAttempts: 1}
+	}
+	c := r.counters(class)
+	c.calls.Add(1)
+	start := r.clock.Now()
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			if r.budget != nil {
+				r.budget.onSuccess()
+			}
+			return nil
+		}
+		if !IsRetryable(err) {
+			return err
+		}
+		if r.budget != nil && !r.budget.onFailure() {
+			c.budgetDenied.Add(1)
+			return errors.Join(ErrBudgetExhausted, err)
+		}
+
+		delay := r.jitter(p.backoff(attempt), p.Jitter)
+		elapsed := r.clock.Now().Sub(start)
+		if attempt >= p.MaxAttempts || (p.MaxElapsed > 0 && elapsed+delay > p.MaxElapsed) {
+			c.exhausted.Add(1)
+			return err
+		}
+
+		select {
+		case <-r.clock.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+		c.retries.Add(1)
+	}
+}
+
+// Stats returns the counters for class.
+func (r *Retrier) Stats(class Class) Stats {
+	c := r.counters(class)
+	return Stats{
+		Calls:        c.calls.Load(),
+		Retries:      c.retries.Load(),
+		Exhausted:    c.exhausted.Load(),
+		BudgetDenied: c.budgetDenied.Load(),
+	}
+}
+
+func (r *Retrier) counters(class Class) *counters {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.stats[class]
+	if !ok {
+		c = &counters{}
+		r.stats[class] = c
+	}
+	return c
+}
+
+func (r *Retrier) jitter(d time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 || d <= 0 {
+		return d
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+	// Uniform in [d*(1-jitter), d*(1+jitter)).
+	f := 1 - jitter + 2*jitter*r.rand()
+	return time.Duration(float64(d) * f)
+}
@@ -0,0 +1,137 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+type tempErr struct{ temp bool }
+
+func (e tempErr) Error() string   { return "temp" }
+func (e tempErr) Temporary() bool { return e.temp }
+
+// autoAdvance fires pending timers on clk until ctx is done.
+func autoAdvance(ctx context.Context, clk *clock.Fake) {
+	go func() {
+		for ctx.Err() == nil {
+			if clk.Waiters() > 0 {
+				clk.Advance(time.Hour)
+			}
+			time.Sleep(100 * time.Microsecond)
+		}
+	}()
+}
+
+func newTestRetrier(t *testing.T, p Policy, opts ...Option) *Retrier {
+	t.Helper()
+	clk := clock.NewFake(time.Unix(0, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	autoAdvance(ctx, clk)
+	r := NewRetrier(map[Class]Policy{ClassFetch: p}, append(opts, WithClock(clk))...)
+	r.rand = func() float64 { return 0.5 }
+	return r
+}
+
+func TestDoRetriesTemporaryErrors(t *testing.T) {
+	r := newTestRetrier(t, Policy{MaxAttempts: 4, BaseDelay: time.Millisecond, Multiplier: 2})
+	calls := 0
+	err := r.Do(context.Background(), ClassFetch, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return tempErr{temp: true}
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+	if s := r.Stats(ClassFetch); s.Calls != 1 || s.Retries != 2 || s.Exhausted != 0 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestDoStopsOnPermanentError(t *testing.T) {
+	r := newTestRetrier(t, Policy{MaxAttempts: 4, BaseDelay: time.Millisecond})
+	calls := 0
+	perm := errors.New("bad tick")
+	if err := r.Do(context.Background(), ClassFetch, func(context.Context) error {
+		calls++
+		return perm
+	}); !errors.Is(err, perm) || calls != 1 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+}
+
+func TestDoExhaustsAttempts(t *testing.T) {
+	r := newTestRetrier(t, Policy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+	calls := 0
+	err := r.Do(context.Background(), ClassFetch, func(context.Context) error {
+		calls++
+		return tempErr{temp: true}
+	})
+	if err == nil || calls != 3 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+	if s := r.Stats(ClassFetch); s.Exhausted != 1 || s.Retries != 2 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestDoHonorsMaxElapsed(t *testing.T) {
+	r := newTestRetrier(t, Policy{MaxAttempts: 10, BaseDelay: time.Second, MaxElapsed: 1500 * time.Millisecond})
+	calls := 0
+	r.Do(context.Background(), ClassFetch, func(context.Context) error {
+		calls++
+		return tempErr{temp: true}
+	})
+	// The first retry fits within 1.5s; the fake clock then jumps an hour,
+	// so no further retry is attempted.
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestBudgetDeniesRetries(t *testing.T) {
+	budget := NewBudget(4, 0.5)
+	r := newTestRetrier(t, Policy{MaxAttempts: 10, BaseDelay: time.Millisecond}, WithBudget(budget))
+	err := r.Do(context.Background(), ClassFetch, func(context.Context) error {
+		return tempErr{temp: true}
+	})
+	if !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("expected ErrBudgetExhausted, got %v", err)
+	}
+	if s := r.Stats(ClassFetch); s.BudgetDenied != 1 || s.Retries != 1 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestBackoffAndJitter(t *testing.T) {
+	p := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2}
+	for n, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: time.Second} {
+		if got := p.backoff(n); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", n, got, want)
+		}
+	}
+	r := NewRetrier(nil)
+	r.rand = func() float64 { return 0 }
+	if got := r.jitter(time.Second, 0.2); got != 800*time.Millisecond {
+		t.Errorf("min jitter = %v", got)
+	}
+}
+
+func TestUnknownClassRunsOnce(t *testing.T) {
+	r := NewRetrier(nil)
+	calls := 0
+	r.Do(context.Background(), ClassSubmit, func(context.Context) error {
+		calls++
+		return tempErr{temp: true}
+	})
+	if calls != 1 {
+		t.Errorf("calls = %d", calls)
+	}
+}
@@ -0,0 +1,141 @@
+package signer
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+var ErrRequestStale = errors.New("request not_after elapsed before signing")
+
+// admissionGate orders concurrent signing requests when the signer is
+// backed up: higher priority first, FIFO within a priority, and requests
+// whose not-after deadline passes while queued are dropped rather than
+// signed late.
+type admissionGate struct {
+	mu      sync.Mutex
+	clock   clock.Clock
+	slots   int
+	seq     uint64
+	waiters waiterHeap
+}
+
+type waiter struct {
+	priority int32
+	notAfter time.Time // zero = no deadline
+	seq      uint64
+	ready    chan struct{}
+	index    int
+}
+
+func newAdmissionGate(slots int, clk clock.Clock) *admissionGate {
+	return &admissionGate{slots: slots, clock: clock.Or(clk)}
+}
+
+// Acquire blocks until the request may proceed. It returns ErrRequestStale
+// if notAfter passes first, or ctx.Err() if ctx is cancelled.
+func (g *admissionGate) Acquire(ctx context.Context, priority int32, notAfter time.Time) error {
+	if !notAfter.IsZero() && !g.clock.Now().Before(notAfter) {
+		return ErrRequestStale
+	}
+
+	g.mu.Lock()
+	if g.slots > 0 && len(g.waiters) == 0 {
+		g.slots--
+		g.mu.Unlock()
+		return nil
+	}
+	w := &waiter{priority: priority, notAfter: notAfter, seq: g.seq, ready: make(chan struct{})}
+	g.seq++
+	heap.Push(&g.waiters, w)
+	g.mu.Unlock()
+
+	var expired <-chan time.Time
+	if !notAfter.IsZero() {
+		expired = g.clock.After(notAfter.Sub(g.clock.Now()))
+	}
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-expired:
+		return g.abandon(w, ErrRequestStale)
+	case <-ctx.Done():
+		return g.abandon(w, ctx.Err())
+	}
+}
+
+// Release frees a slot and hands it to the best waiting request.
+func (g *admissionGate) Release() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.clock.Now()
+	for len(g.waiters) > 0 {
+		w := heap.Pop(&g.waiters).(*waiter)
+		if !w.notAfter.IsZero() && !now.Before(w.notAfter) {
+			// Its own timer will report it stale.
+			continue
+		}
+		close(w.ready)
+		return
+	}
+	g.slots++
+}
+
+// abandon removes w from the queue. If w was granted a slot concurrently,
+// the slot is passed on and the request still fails with err.
+func (g *admissionGate) abandon(w *waiter, err error) error {
+	g.mu.Lock()
+	if w.index >= 0 {
+		heap.Remove(&g.waiters, w.index)
+		g.mu.Unlock()
+		return err
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		g.Release()
+	default:
+	}
+	return err
+}
+
+// waiterHeap is a max-heap on priority, then min-heap on arrival order.
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() any {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*h = old[:n-1]
+	return w
+}
@@ -0,0 +1,90 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+func TestAdmissionGatePriorityOrder(t *testing.T) {
+	g := newAdmissionGate(1, nil)
+	if err := g.Acquire(context.Background(), 0, time.Time{}); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []int32
+	var wg sync.WaitGroup
+	for i, p := range []int32{1, 5, 3} {
+		wg.Add(1)
+		go func(p int32) {
+			defer wg.Done()
+			if err := g.Acquire(context.Background(), p, time.Time{}); err != nil {
+				t.Errorf("acquire %d: %v", p, err)
+				return
+			}
+			mu.Lock()
+			order = append(order, p)
+			mu.Unlock()
+			g.Release()
+		}(p)
+		// Make arrival order deterministic.
+		waitForWaiters(t, g, i+1)
+	}
+
+	g.Release()
+	wg.Wait()
+
+	want := []int32{5, 3, 1}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("admission order %v, want %v", order, want)
+		}
+	}
+}
+
+func TestAdmissionGateDropsStaleRequests(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	g := newAdmissionGate(1, clk)
+	if err := g.Acquire(context.Background(), 0, clk.Now().Add(-time.Second)); !errors.Is(err, ErrRequestStale) {
+		t.Fatalf("expected already-stale request rejected, got %v", err)
+	}
+
+	if err := g.Acquire(context.Background(), 0, time.Time{}); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	errCh := make(chan error, 1)
+	go func() { errCh <- g.Acquire(context.Background(), 10, clk.Now().Add(time.Minute)) }()
+	for clk.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clk.Advance(time.Minute)
+	if err := <-errCh; !errors.Is(err, ErrRequestStale) {
+		t.Errorf("expected queued request to go stale, got %v", err)
+	}
+
+	// The slot is still held by the first request and is returned intact.
+	g.Release()
+	if err := g.Acquire(context.Background(), 0, clk.Now().Add(time.Second)); err != nil {
+		t.Errorf("expected slot available after release, got %v", err)
+	}
+}
+
+func waitForWaiters(t *testing.T, g *admissionGate, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		got := len(g.waiters)
+		g.mu.Unlock()
+		if got >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d waiters", n)
+}
@@ -0,0 +1,111 @@
+package signer
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrInvalidAmount  = errors.New("invalid amount")
+	ErrNegativeAmount = errors.New("amount must not be negative")
+	ErrAmountOverflow = errors.New("amount exceeds maximum magnitude")
+)
+
+// MaxAmountBits bounds every amount the signer accounts for. 2^128 USDC
+// atomic units is far beyond any real order or limit, so anything larger
+// is treated as malformed input rather than carried through the ledgers.
+const MaxAmountBits = 128
+
+// Amount is an immutable, non-negative USDC atomic-unit value. It never
+// shares its backing big.Int with callers: constructors copy their input
+// and BigInt returns a copy, so mutating a big.Int after handing it to the
+// signer cannot change a limit or ledger. The zero value is 0.
+type Amount struct {
+	v *big.Int
+}
+
+// NewAmount copies v into an Amount. nil is treated as zero.
+func NewAmount(v *big.Int) (Amount, error) {
+	if v == nil {
+		return Amount{}, nil
+	}
+	if v.Sign() < 0 {
+		return Amount{}, ErrNegativeAmount
+	}
+	if v.BitLen() > MaxAmountBits {
+		return Amount{}, ErrAmountOverflow
+	}
+	return Amount{v: new(big.Int).Set(v)}, nil
+}
+
+// ParseAmount parses a base-10 integer string.
+func ParseAmount(s string) (Amount, error) {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Amount{}, ErrInvalidAmount
+	}
+	return NewAmount(v)
+}
+
+// AmountOf returns n as an Amount; negative n yields zero.
+func AmountOf(n int64) Amount {
+	if n <= 0 {
+		return Amount{}
+	}
+	return Amount{v: big.NewInt(n)}
+}
+
+func (a Amount) big() *big.Int {
+	if a.v == nil {
+		return new(big.Int)
+	}
+	return a.v
+}
+
+// Add returns a + b, or ErrAmountOverflow if the sum exceeds MaxAmountBits.
+func (a Amount) Add(b Amount) (Amount, error) {
+	sum := new(big.Int).Add(a.big(), b.big())
+	if sum.BitLen() > MaxAmountBits {
+		return Amount{}, ErrAmountOverflow
+	}
+	return Amount{v: sum}, nil
+}
+
+// Sub returns a - b, floored at zero.
+func (a Amount) Sub(b Amount) Amount {
+	diff := new(big.Int).Sub(a.big(), b.big())
+	if diff.Sign() <= 0 {
+		return Amount{}
+	}
+	return Amount{v: diff}
+}
+
+// MulDiv returns a * num / den, truncated. den must be positive.
+func (a Amount) MulDiv(num, den int64) Amount {
+	r := new(big.Int).Mul(a.big(), big.NewInt(num))
+	r.Quo(r, big.NewInt(den))
+	if r.Sign() <= 0 {
+		return Amount{}
+	}
+	return Amount{v: r}
+}
+
+// Cmp compares a and b like big.Int.Cmp.
+func (a Amount) Cmp(b Amount) int {
+	return a.big().Cmp(b.big())
+}
+
+// IsZero reports whether a is 0.
+func (a Amount) IsZero() bool {
+	return a.v == nil || a.v.Sign() == 0
+}
+
+// BigInt returns a copy of a as a big.Int.
+func (a Amount) BigInt() *big.Int {
+	return new(big.Int).Set(a.big())
+}
+
+// String returns a in base 10.
+func (a Amount) String() string {
+	return a.big().String()
+}
@@ -0,0 +1,73 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestAmountBounds(t *testing.T) {
+	if _, err := NewAmount(big.NewInt(-1)); !errors.Is(err, ErrNegativeAmount) {
+		t.Errorf("expected ErrNegativeAmount, got %v", err)
+	}
+	huge := new(big.Int).Lsh(big.NewInt(1), MaxAmountBits)
+	if _, err := NewAmount(huge); !errors.Is(err, ErrAmountOverflow) {
+		t.Errorf("expected ErrAmountOverflow, got %v", err)
+	}
+	if _, err := ParseAmount("1.5"); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("expected ErrInvalidAmount, got %v", err)
+	}
+
+	max, err := NewAmount(new(big.Int).Sub(huge, big.NewInt(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := max.Add(AmountOf(1)); !errors.Is(err, ErrAmountOverflow) {
+		t.Errorf("expected overflow on add, got %v", err)
+	}
+	if got := AmountOf(5).Sub(AmountOf(7)); !got.IsZero() {
+		t.Errorf("Sub should floor at zero, got %s", got)
+	}
+}
+
+func TestAmountDoesNotAlias(t *testing.T) {
+	src := big.NewInt(100)
+	a, _ := NewAmount(src)
+	src.SetInt64(1)
+	if a.String() != "100" {
+		t.Errorf("mutating input changed amount to %s", a)
+	}
+	out := a.BigInt()
+	out.SetInt64(7)
+	if a.String() != "100" {
+		t.Errorf("mutating output changed amount to %s", a)
+	}
+}
+
+func TestSessionLedgerIgnoresCallerMutation(t *testing.T) {
+	sm := NewSessionManager(time.Hour)
+	limit := big.NewInt(1000)
+	if err := sm.Activate(context.Background(), testKey(), limit); err != nil {
+		t.Fatal(err)
+	}
+	defer sm.Destroy()
+	limit.SetInt64(1_000_000)
+
+	value := big.NewInt(600)
+	if _, err := sm.Sign(context.Background(), [32]byte{}, value); err != nil {
+		t.Fatal(err)
+	}
+	value.SetInt64(0)
+
+	if _, err := sm.Sign(context.Background(), [32]byte{}, big.NewInt(500)); !errors.Is(err, ErrValueLimitExceeded) {
+		t.Errorf("expected ErrValueLimitExceeded, got %v", err)
+	}
+	if _, err := sm.Sign(context.Background(), [32]byte{}, big.NewInt(-100)); !errors.Is(err, ErrNegativeAmount) {
+		t.Errorf("expected ErrNegativeAmount, got %v", err)
+	}
+	if _, _, max, used, _ := sm.Status(); max != "1000" || used != "600" {
+		t.Errorf("status = %s/%s, want 1000/600", max, used)
+	}
+}
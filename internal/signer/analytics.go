@@ -0,0 +1,229 @@
+package signer
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// MarketActivity is one market's share of a session.
+type MarketActivity struct {
+	TokenID  string
+	Signed   int64
+	Notional Amount // raw USDC units signed
+	Rejected int64
+}
+
+// RejectionBucket counts rejections of one reason within one hour.
+type RejectionBucket struct {
+	Hour   time.Time
+	Reason string
+	Count  int64
+}
+
+// HourActivity counts orders signed in one hour of the day.
+type HourActivity struct {
+	Hour   int
+	Signed int64
+}
+
+// ShadowHit counts orders a shadow policy rule would have rejected.
+type ShadowHit struct {
+	Rule  string
+	Count int64
+}
+
+// AnalyticsReport summarizes a session for end-of-day review.
+type AnalyticsReport struct {
+	Since        time.Time
+	Markets      []MarketActivity  // most notional first
+	Rejections   []RejectionBucket // by hour, then reason
+	BusiestHours []HourActivity    // most orders first; idle hours omitted
+	ShadowHits   []ShadowHit       // by rule ID
+}
+
+type rejectionKey struct {
+	hour   time.Time
+	reason string
+}
+
+// SessionAnalytics tallies SignOrder outcomes for the current session.
+// Like QuotaTracker it resets whenever a new session is activated.
+type SessionAnalytics struct {
+	loc *time.Location
+
+	mu         sync.Mutex
+	epoch      uint64
+	markets    map[string]*MarketActivity
+	rejections map[rejectionKey]int64
+	hours      [24]int64
+	shadow     map[string]int64
+}
+
+// NewSessionAnalytics creates an empty tally. Busiest hours are reported
+// in loc; nil means UTC.
+func NewSessionAnalytics(loc *time.Location) *SessionAnalytics {
+	if loc == nil {
+		loc = time.UTC
+	}
+	a := &SessionAnalytics{loc: loc}
+	a.resetLocked(0)
+	return a
+}
+
+// RecordSigned counts a signed order of value on token.
+func (a *SessionAnalytics) RecordSigned(epoch uint64, token string, value *big.Int, at time.Time) {
+	v, err := NewAmount(value)
+	if err != nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.currentLocked(epoch) {
+		return
+	}
+	m := a.marketLocked(token)
+	m.Signed++
+	if sum, err := m.Notional.Add(v); err == nil {
+		m.Notional = sum
+	}
+	a.hours[at.In(a.loc).Hour()]++
+}
+
+// RecordRejected counts an order on token refused for reason.
+func (a *SessionAnalytics) RecordRejected(epoch uint64, token, reason string, at time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.currentLocked(epoch) {
+		return
+	}
+	a.marketLocked(token).Rejected++
+	a.rejections[rejectionKey{hour: at.UTC().Truncate(time.Hour), reason: reason}]++
+}
+
+// RecordShadowHit counts an order the shadow policy rule would have
+// rejected.
+func (a *SessionAnalytics) RecordShadowHit(epoch uint64, rule string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.currentLocked(epoch) {
+		return
+	}
+	a.shadow[rule]++
+}
+
+// Report returns the tally for the session identified by epoch, which
+// started at since.
+func (a *SessionAnalytics) Report(epoch uint64, since time.Time) AnalyticsReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	r := AnalyticsReport{Since: since}
+	if epoch != a.epoch {
+		return r
+	}
+
+	for _, m := range a.markets {
+		r.Markets = append(r.Markets, *m)
+	}
+	sort.Slice(r.Markets, func(i, j int) bool {
+		if c := r.Markets[i].Notional.Cmp(r.Markets[j].Notional); c != 0 {
+			return c > 0
+		}
+		return r.Markets[i].TokenID < r.Markets[j].TokenID
+	})
+
+	for k, n := range a.rejections {
+		r.Rejections = append(r.Rejections, RejectionBucket{Hour: k.hour, Reason: k.reason, Count: n})
+	}
+	sort.Slice(r.Rejections, func(i, j int) bool {
+		if !r.Rejections[i].Hour.Equal(r.Rejections[j].Hour) {
+			return r.Rejections[i].Hour.Before(r.Rejections[j].Hour)
+		}
+		return r.Rejections[i].Reason < r.Rejections[j].Reason
+	})
+
+	for h, n := range a.hours {
+		if n > 0 {
+			r.BusiestHours = append(r.BusiestHours, HourActivity{Hour: h, Signed: n})
+		}
+	}
+	sort.SliceStable(r.BusiestHours, func(i, j int) bool { return r.BusiestHours[i].Signed > r.BusiestHours[j].Signed })
+
+	for rule, n := range a.shadow {
+		r.ShadowHits = append(r.ShadowHits, ShadowHit{Rule: rule, Count: n})
+	}
+	sort.Slice(r.ShadowHits, func(i, j int) bool { return r.ShadowHits[i].Rule < r.ShadowHits[j].Rule })
+	return r
+}
+
+// currentLocked resets the tally for a newer epoch and reports whether
+// epoch is the one being tallied.
+func (a *SessionAnalytics) currentLocked(epoch uint64) bool {
+	if epoch > a.epoch {
+		a.resetLocked(epoch)
+	}
+	return epoch == a.epoch
+}
+
+func (a *SessionAnalytics) resetLocked(epoch uint64) {
+	a.epoch = epoch
+	a.markets = make(map[string]*MarketActivity)
+	a.rejections = make(map[rejectionKey]int64)
+	a.hours = [24]int64{}
+	a.shadow = make(map[string]int64)
+}
+
+func (a *SessionAnalytics) marketLocked(token string) *MarketActivity {
+	m, ok := a.markets[token]
+	if !ok {
+		m = &MarketActivity{TokenID: token}
+		a.markets[token] = m
+	}
+	return m
+}
+
+// rejectionReason names why SignOrder refused a request: the ErrorInfo
+// reason when the status carries one, otherwise its code.
+func rejectionReason(err error) string {
+	st := status.Convert(err)
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Reason != "" {
+			return info.Reason
+		}
+	}
+	return st.Code().String()
+}
+
+func analyticsProto(r AnalyticsReport) *signerv1.GetSessionAnalyticsResponse {
+	resp := &signerv1.GetSessionAnalyticsResponse{}
+	if !r.Since.IsZero() {
+		resp.Since = r.Since.UnixNano()
+	}
+	for _, m := range r.Markets {
+		resp.Markets = append(resp.Markets, &signerv1.MarketActivity{
+			TokenId:  m.TokenID,
+			Signed:   m.Signed,
+			Notional: m.Notional.String(),
+			Rejected: m.Rejected,
+		})
+	}
+	for _, b := range r.Rejections {
+		resp.Rejections = append(resp.Rejections, &signerv1.RejectionBucket{
+			Hour:   b.Hour.UnixNano(),
+			Reason: b.Reason,
+			Count:  b.Count,
+		})
+	}
+	for _, h := range r.BusiestHours {
+		resp.BusiestHours = append(resp.BusiestHours, &signerv1.HourActivity{Hour: int32(h.Hour), Signed: h.Signed})
+	}
+	for _, s := range r.ShadowHits {
+		resp.ShadowHits = append(resp.ShadowHits, &signerv1.ShadowHit{RuleId: s.Rule, Count: s.Count})
+	}
+	return resp
+}
@@ -0,0 +1,75 @@
+package signer
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+)
+
+func TestSessionAnalyticsReport(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 5, 14, 30, 0, 0, time.UTC))
+	sm := NewSessionManager(24*time.Hour, WithClock(clk))
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(300)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	defer sm.Destroy()
+	h := NewHandler(sm, WithAnalytics(NewSessionAnalytics(nil)))
+
+	sign := func(token, maker string) {
+		h.SignOrder(context.Background(), &signerv1.SignOrderRequest{
+			Order: &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: token, MakerAmount: maker},
+		})
+	}
+	sign("1", "100")
+	sign("2", "50")
+	clk.Advance(time.Hour)
+	sign("1", "100")
+	sign("2", "100") // exceeds the 300 session limit
+	sign("2", "-1")  // invalid amount
+
+	resp, err := h.GetSessionAnalytics(context.Background(), &signerv1.GetSessionAnalyticsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Since != time.Date(2026, 1, 5, 14, 30, 0, 0, time.UTC).UnixNano() {
+		t.Errorf("since = %d", resp.Since)
+	}
+	if len(resp.Markets) != 2 {
+		t.Fatalf("markets = %+v", resp.Markets)
+	}
+	if m := resp.Markets[0]; m.TokenId != "1" || m.Signed != 2 || m.Notional != "200" || m.Rejected != 0 {
+		t.Errorf("market a = %+v", m)
+	}
+	if m := resp.Markets[1]; m.TokenId != "2" || m.Signed != 1 || m.Notional != "50" || m.Rejected != 2 {
+		t.Errorf("market b = %+v", m)
+	}
+
+	hour := time.Date(2026, 1, 5, 15, 0, 0, 0, time.UTC).UnixNano()
+	if len(resp.Rejections) != 2 {
+		t.Fatalf("rejections = %+v", resp.Rejections)
+	}
+	for _, b := range resp.Rejections {
+		if b.Hour != hour || b.Count != 1 {
+			t.Errorf("unexpected bucket %+v", b)
+		}
+	}
+	if resp.Rejections[0].Reason != "InvalidArgument" || resp.Rejections[1].Reason != "ResourceExhausted" {
+		t.Errorf("reasons = %+v", resp.Rejections)
+	}
+	if len(resp.BusiestHours) != 2 || resp.BusiestHours[0].Hour != 14 || resp.BusiestHours[0].Signed != 2 {
+		t.Errorf("busiest hours = %+v", resp.BusiestHours)
+	}
+
+	// A new session starts a fresh tally.
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(300)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	resp, _ = h.GetSessionAnalytics(context.Background(), &signerv1.GetSessionAnalyticsRequest{})
+	if len(resp.Markets) != 0 || len(resp.Rejections) != 0 {
+		t.Errorf("expected reset after activation, got %+v", resp)
+	}
+}
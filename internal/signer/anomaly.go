@@ -0,0 +1,183 @@
+package signer
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// AnomalyKind classifies an unusual signing pattern.
+type AnomalyKind string
+
+const (
+	AnomalyRateSpike    AnomalyKind = "rate_spike"
+	AnomalyNewMarket    AnomalyKind = "new_market"
+	AnomalyAbnormalSize AnomalyKind = "abnormal_size"
+	AnomalyOddHours     AnomalyKind = "odd_hours"
+)
+
+// Anomaly describes a single flagged observation. It never contains key
+// material or signatures, so it is safe to log.
+type Anomaly struct {
+	Kind   AnomalyKind
+	Market string
+	Detail string
+	At     time.Time
+}
+
+// DetectorConfig tunes the anomaly detector. Zero values fall back to the
+// defaults applied by NewDetector.
+type DetectorConfig struct {
+	// RateSpikeFactor flags the last minute when its request count exceeds
+	// this multiple of the trailing per-minute baseline.
+	RateSpikeFactor float64
+	// RateSpikeMin is the minimum per-minute count before a spike is flagged.
+	RateSpikeMin int
+	// Baseline is the trailing window used for the rate baseline.
+	Baseline time.Duration
+	// SizeFactor flags orders larger than this multiple of the mean size.
+	SizeFactor int64
+	// Warmup is the number of observations before new-market and size
+	// checks start firing.
+	Warmup int
+	// QuietStart/QuietEnd bound the odd-hours window as offsets from
+	// midnight in QuietLocation (UTC if nil). Equal values disable the check.
+	QuietStart    time.Duration
+	QuietEnd      time.Duration
+	QuietLocation *time.Location
+	// Escalate switches the detector into approval-required mode on the
+	// first anomaly, so every further order is refused until cleared.
+	Escalate bool
+}
+
+// Detector is a lightweight, in-memory anomaly detector for SignOrder
+// traffic.
+type Detector struct {
+	cfg     DetectorConfig
+	onAlert func(Anomaly)
+
+	mu        sync.Mutex
+	times     []time.Time
+	markets   map[string]struct{}
+	count     int64
+	sizeTotal *big.Int
+	escalated bool
+}
+
+// NewDetector creates a detector. onAlert, if non-nil, is called for every
+// anomaly outside the detector's lock.
+func NewDetector(cfg DetectorConfig, onAlert func(Anomaly)) *Detector {
+	if cfg.RateSpikeFactor <= 0 {
+		cfg.RateSpikeFactor = 5
+	}
+	if cfg.RateSpikeMin <= 0 {
+		cfg.RateSpikeMin = 30
+	}
+	if cfg.Baseline <= 0 {
+		cfg.Baseline = time.Hour
+	}
+	if cfg.SizeFactor <= 0 {
+		cfg.SizeFactor = 10
+	}
+	if cfg.Warmup <= 0 {
+		cfg.Warmup = 20
+	}
+	return &Detector{
+		cfg:       cfg,
+		onAlert:   onAlert,
+		markets:   make(map[string]struct{}),
+		sizeTotal: new(big.Int),
+	}
+}
+
+// Observe records a signing request and returns any anomalies it triggers.
+func (d *Detector) Observe(market string, value *big.Int, at time.Time) []Anomaly {
+	d.mu.Lock()
+	anomalies := d.observeLocked(market, value, at)
+	if len(anomalies) > 0 && d.cfg.Escalate {
+		d.escalated = true
+	}
+	d.mu.Unlock()
+
+	if d.onAlert != nil {
+		for _, a := range anomalies {
+			d.onAlert(a)
+		}
+	}
+	return anomalies
+}
+
+// Escalated reports whether the detector has switched to approval-required
+// mode.
+func (d *Detector) Escalated() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.escalated
+}
+
+// ClearEscalation returns the detector to normal mode after operator review.
+func (d *Detector) ClearEscalation() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.escalated = false
+}
+
+func (d *Detector) observeLocked(market string, value *big.Int, at time.Time) []Anomaly {
+	var out []Anomaly
+	flag := func(kind AnomalyKind, detail string) {
+		out = append(out, Anomaly{Kind: kind, Market: market, Detail: detail, At: at})
+	}
+
+	warm := d.count >= int64(d.cfg.Warmup)
+
+	// Rate spike: requests in the last minute vs the trailing baseline.
+	cutoff := at.Add(-d.cfg.Baseline)
+	i := 0
+	for i < len(d.times) && d.times[i].Before(cutoff) {
+		i++
+	}
+	d.times = append(d.times[i:], at)
+
+	var lastMinute int
+	for _, t := range d.times {
+		if at.Sub(t) < time.Minute {
+			lastMinute++
+		}
+	}
+	older := len(d.times) - lastMinute
+	minutes := d.cfg.Baseline.Minutes() - 1
+	baseline := float64(older) / minutes
+	if lastMinute >= d.cfg.RateSpikeMin && float64(lastMinute) > d.cfg.RateSpikeFactor*baseline {
+		flag(AnomalyRateSpike, fmt.Sprintf("%d requests in last minute vs baseline %.1f/min", lastMinute, baseline))
+	}
+
+	// Brand-new market once the session has an established footprint.
+	if _, seen := d.markets[market]; !seen {
+		if warm {
+			flag(AnomalyNewMarket, "first order in market this session")
+		}
+		d.markets[market] = struct{}{}
+	}
+
+	// Abnormal size relative to the running mean.
+	if warm {
+		threshold := new(big.Int).Quo(d.sizeTotal, big.NewInt(d.count))
+		threshold.Mul(threshold, big.NewInt(d.cfg.SizeFactor))
+		if threshold.Sign() > 0 && value.Cmp(threshold) > 0 {
+			flag(AnomalyAbnormalSize, fmt.Sprintf("value %s exceeds %dx session mean", value, d.cfg.SizeFactor))
+		}
+	}
+	d.sizeTotal.Add(d.sizeTotal, value)
+	d.count++
+
+	// Signing during configured quiet hours.
+	if d.cfg.QuietStart != d.cfg.QuietEnd {
+		quiet := LimitProfile{Start: d.cfg.QuietStart, End: d.cfg.QuietEnd, Location: d.cfg.QuietLocation}
+		if quiet.Active(at) {
+			flag(AnomalyOddHours, "order signed during quiet hours")
+		}
+	}
+
+	return out
+}
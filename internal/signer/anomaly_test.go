@@ -0,0 +1,68 @@
+package signer
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func kinds(as []Anomaly) map[AnomalyKind]bool {
+	m := make(map[AnomalyKind]bool)
+	for _, a := range as {
+		m[a.Kind] = true
+	}
+	return m
+}
+
+func TestDetectorNewMarketAndAbnormalSize(t *testing.T) {
+	d := NewDetector(DetectorConfig{Warmup: 3, SizeFactor: 5, RateSpikeMin: 1000}, nil)
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		if got := d.Observe("m1", big.NewInt(100), at.Add(time.Duration(i)*time.Minute)); len(got) != 0 {
+			t.Fatalf("unexpected anomalies during warmup: %v", got)
+		}
+	}
+
+	got := kinds(d.Observe("m2", big.NewInt(501), at.Add(5*time.Minute)))
+	if !got[AnomalyNewMarket] || !got[AnomalyAbnormalSize] {
+		t.Errorf("expected new market and abnormal size, got %v", got)
+	}
+}
+
+func TestDetectorRateSpike(t *testing.T) {
+	d := NewDetector(DetectorConfig{RateSpikeMin: 10, RateSpikeFactor: 3, Baseline: 10 * time.Minute}, nil)
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var last []Anomaly
+	for i := 0; i < 10; i++ {
+		last = d.Observe("m", big.NewInt(1), at.Add(time.Duration(i)*time.Second))
+	}
+	if !kinds(last)[AnomalyRateSpike] {
+		t.Errorf("expected rate spike on 10th request within a minute, got %v", last)
+	}
+}
+
+func TestDetectorOddHoursEscalates(t *testing.T) {
+	var alerts int
+	d := NewDetector(DetectorConfig{
+		QuietStart: 0,
+		QuietEnd:   6 * time.Hour,
+		Escalate:   true,
+	}, func(Anomaly) { alerts++ })
+
+	d.Observe("m", big.NewInt(1), time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	if d.Escalated() {
+		t.Fatalf("should not escalate during trading hours")
+	}
+
+	d.Observe("m", big.NewInt(1), time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC))
+	if !d.Escalated() || alerts != 1 {
+		t.Errorf("expected escalation and one alert, escalated=%v alerts=%d", d.Escalated(), alerts)
+	}
+
+	d.ClearEscalation()
+	if d.Escalated() {
+		t.Errorf("expected escalation cleared")
+	}
+}
@@ -0,0 +1,260 @@
+package signer
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ApprovalRequiredReason is the ErrorInfo reason of orders held for
+// approval; its metadata carries approval_id and expires_at.
+const ApprovalRequiredReason = "APPROVAL_REQUIRED"
+
+var (
+	ErrApprovalNotFound = errors.New("approval not found")
+	ErrApprovalPending  = errors.New("approval still pending")
+	ErrApprovalExpired  = errors.New("approval expired")
+	ErrApprovalResolved = errors.New("approval already resolved")
+	ErrApprovalMismatch = errors.New("approval does not cover this request")
+	ErrApprovalDenied   = errors.New("approval denied")
+	ErrSelfApproval     = errors.New("requester cannot respond to its own approval")
+)
+
+// ApprovalKind classifies what an approval unlocks.
+type ApprovalKind string
+
+const (
+	// ApprovalLargeOrder covers one order above a limit profile's
+	// ApprovalAbove threshold.
+	ApprovalLargeOrder ApprovalKind = "large_order"
+	// ApprovalLimitRaise and ApprovalTreasury are reserved for operator
+	// actions that are not yet exposed over RPC.
+	ApprovalLimitRaise ApprovalKind = "limit_raise"
+	ApprovalTreasury   ApprovalKind = "treasury"
+)
+
+// ApprovalState is the lifecycle of an approval.
+type ApprovalState string
+
+const (
+	ApprovalPending  ApprovalState = "pending"
+	ApprovalApproved ApprovalState = "approved"
+	ApprovalDenied   ApprovalState = "denied"
+	ApprovalUsed     ApprovalState = "used"
+	ApprovalExpired  ApprovalState = "expired"
+)
+
+// Approval is one request for a human decision.
+type Approval struct {
+	ID          string
+	Kind        ApprovalKind
+	State       ApprovalState
+	Client      string
+	Summary     string
+	TokenID     string
+	Value       string // raw USDC units
+	RequestedAt time.Time
+	ExpiresAt   time.Time
+	Note        string // responder's note
+
+	fingerprint string
+}
+
+// ApprovalInbox holds approvals until they are answered, used, or expire.
+// An approval is bound to the fingerprint of the request that raised it
+// and can be used exactly once.
+type ApprovalInbox struct {
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu        sync.Mutex
+	approvals map[string]*Approval
+}
+
+// NewApprovalInbox creates an inbox whose approvals expire ttl after they
+// are requested. A nil clk uses the real clock.
+func NewApprovalInbox(ttl time.Duration, clk clock.Clock) *ApprovalInbox {
+	return &ApprovalInbox{ttl: ttl, clock: clock.Or(clk), approvals: make(map[string]*Approval)}
+}
+
+// Request files a new approval, or returns the live one already filed for
+// the same fingerprint so retries do not flood the inbox. created reports
+// whether a new approval was filed.
+func (in *ApprovalInbox) Request(a Approval, fingerprint string) (approval Approval, created bool) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	now := in.clock.Now()
+	in.expireLocked(now)
+	for _, existing := range in.approvals {
+		if existing.fingerprint == fingerprint &&
+			(existing.State == ApprovalPending || existing.State == ApprovalApproved) {
+			return *existing, false
+		}
+	}
+
+	b := make([]byte, 8)
+	rand.Read(b)
+	a.ID = hex.EncodeToString(b)
+	a.State = ApprovalPending
+	a.RequestedAt = now
+	a.ExpiresAt = now.Add(in.ttl)
+	a.fingerprint = fingerprint
+	in.approvals[a.ID] = &a
+	return a, true
+}
+
+// Pending returns approvals awaiting a decision, soonest expiry first.
+func (in *ApprovalInbox) Pending() []Approval {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.expireLocked(in.clock.Now())
+	var out []Approval
+	for _, a := range in.approvals {
+		if a.State == ApprovalPending {
+			out = append(out, *a)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ExpiresAt.Before(out[j].ExpiresAt) })
+	return out
+}
+
+// Respond approves or denies a pending approval on behalf of responder.
+func (in *ApprovalInbox) Respond(id, responder string, approve bool, note string) (Approval, error) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.expireLocked(in.clock.Now())
+	a, ok := in.approvals[id]
+	switch {
+	case !ok:
+		return Approval{}, ErrApprovalNotFound
+	case a.State == ApprovalExpired:
+		return *a, ErrApprovalExpired
+	case a.State != ApprovalPending:
+		return *a, ErrApprovalResolved
+	case a.Client == responder:
+		return *a, ErrSelfApproval
+	}
+	a.State, a.Note = ApprovalDenied, note
+	if approve {
+		a.State = ApprovalApproved
+	}
+	return *a, nil
+}
+
+// Claim marks an approved approval as used by the request with the given
+// fingerprint. Call Unclaim if the request then fails, so it can be
+// retried under the same approval.
+func (in *ApprovalInbox) Claim(id, fingerprint string) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.expireLocked(in.clock.Now())
+	a, ok := in.approvals[id]
+	switch {
+	case !ok:
+		return ErrApprovalNotFound
+	case a.fingerprint != fingerprint:
+		return ErrApprovalMismatch
+	case a.State == ApprovalExpired:
+		return ErrApprovalExpired
+	case a.State == ApprovalDenied:
+		return ErrApprovalDenied
+	case a.State == ApprovalPending:
+		return ErrApprovalPending
+	case a.State != ApprovalApproved:
+		return ErrApprovalResolved
+	}
+	a.State = ApprovalUsed
+	return nil
+}
+
+// Unclaim returns a claimed approval to the approved state.
+func (in *ApprovalInbox) Unclaim(id string) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if a, ok := in.approvals[id]; ok && a.State == ApprovalUsed {
+		a.State = ApprovalApproved
+	}
+}
+
+// expireLocked expires stale approvals. Resolved and expired approvals
+// are kept for another ttl so late responses get a precise error, then
+// forgotten.
+func (in *ApprovalInbox) expireLocked(now time.Time) {
+	for id, a := range in.approvals {
+		switch {
+		case now.Before(a.ExpiresAt):
+		case a.State == ApprovalPending || a.State == ApprovalApproved:
+			a.State = ApprovalExpired
+		case !now.Before(a.ExpiresAt.Add(in.ttl)):
+			delete(in.approvals, id)
+		}
+	}
+}
+
+// orderFingerprint binds an approval to the exact order it was raised for.
+func orderFingerprint(o *signerv1.PolymarketOrder) string {
+	data, _ := proto.MarshalOptions{Deterministic: true}.Marshal(o)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// approvalRequiredStatus tells the caller which approval to wait for.
+func approvalRequiredStatus(a Approval, profile string) error {
+	st := status.New(codes.FailedPrecondition,
+		fmt.Sprintf("order requires approval under limit profile %q; approval %s pending", profile, a.ID))
+	info := &errdetails.ErrorInfo{
+		Reason: ApprovalRequiredReason,
+		Domain: ErrorDomain,
+		Metadata: map[string]string{
+			"approval_id": a.ID,
+			"expires_at":  a.ExpiresAt.UTC().Format(time.RFC3339),
+		},
+	}
+	if withDetails, err := st.WithDetails(info); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+func approvalStatus(err error) error {
+	switch {
+	case errors.Is(err, ErrApprovalNotFound):
+		return status.Errorf(codes.NotFound, "%v", err)
+	case errors.Is(err, ErrApprovalDenied), errors.Is(err, ErrSelfApproval):
+		return status.Errorf(codes.PermissionDenied, "%v", err)
+	case errors.Is(err, ErrApprovalExpired), errors.Is(err, ErrApprovalResolved),
+		errors.Is(err, ErrApprovalMismatch), errors.Is(err, ErrApprovalPending):
+		return status.Errorf(codes.FailedPrecondition, "%v", err)
+	default:
+		return status.Errorf(codes.Internal, "approval: %v", err)
+	}
+}
+
+func approvalProto(a Approval) *signerv1.PendingApproval {
+	return &signerv1.PendingApproval{
+		ApprovalId:  a.ID,
+		Kind:        string(a.Kind),
+		Client:      a.Client,
+		Summary:     a.Summary,
+		TokenId:     a.TokenID,
+		Value:       a.Value,
+		RequestedAt: a.RequestedAt.UnixNano(),
+		ExpiresAt:   a.ExpiresAt.UnixNano(),
+	}
+}
@@ -0,0 +1,121 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestApprovalInboxLifecycle(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	in := NewApprovalInbox(5*time.Minute, clk)
+
+	a, created := in.Request(Approval{Kind: ApprovalLargeOrder, Client: "uid:1/mm"}, "fp1")
+	if !created || a.State != ApprovalPending {
+		t.Fatalf("unexpected approval: %+v", a)
+	}
+	if again, created := in.Request(Approval{Client: "uid:1/mm"}, "fp1"); created || again.ID != a.ID {
+		t.Errorf("retry should return the existing approval")
+	}
+
+	if err := in.Claim(a.ID, "fp1"); !errors.Is(err, ErrApprovalPending) {
+		t.Errorf("expected ErrApprovalPending, got %v", err)
+	}
+	if _, err := in.Respond(a.ID, "uid:1/mm", true, ""); !errors.Is(err, ErrSelfApproval) {
+		t.Errorf("expected ErrSelfApproval, got %v", err)
+	}
+	if _, err := in.Respond(a.ID, "uid:1/desk", true, "ok"); err != nil {
+		t.Fatalf("respond: %v", err)
+	}
+	if len(in.Pending()) != 0 {
+		t.Errorf("approved approval should leave the pending list")
+	}
+
+	if err := in.Claim(a.ID, "other"); !errors.Is(err, ErrApprovalMismatch) {
+		t.Errorf("expected ErrApprovalMismatch, got %v", err)
+	}
+	if err := in.Claim(a.ID, "fp1"); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if err := in.Claim(a.ID, "fp1"); !errors.Is(err, ErrApprovalResolved) {
+		t.Errorf("approval must be single-use, got %v", err)
+	}
+	in.Unclaim(a.ID)
+	if err := in.Claim(a.ID, "fp1"); err != nil {
+		t.Errorf("unclaimed approval should be usable again, got %v", err)
+	}
+
+	b, _ := in.Request(Approval{Client: "uid:1/mm"}, "fp2")
+	clk.Advance(5 * time.Minute)
+	if len(in.Pending()) != 0 {
+		t.Errorf("expected approval to expire")
+	}
+	if _, err := in.Respond(b.ID, "uid:1/desk", true, ""); !errors.Is(err, ErrApprovalExpired) {
+		t.Errorf("expected ErrApprovalExpired, got %v", err)
+	}
+}
+
+func TestSignOrderHeldForApproval(t *testing.T) {
+	sm := NewSessionManager(time.Hour)
+	sm.SetLimitProfiles([]LimitProfile{{Name: "always", End: 24 * time.Hour, LimitPct: 100, ApprovalAbove: big.NewInt(50)}})
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	defer sm.Destroy()
+
+	var raised []Approval
+	h := NewHandler(sm, WithApprovals(NewApprovalInbox(time.Minute, nil), func(a Approval) {
+		raised = append(raised, a)
+	}))
+	strategy := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ClientLabelKey, "mm"))
+	desk := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ClientLabelKey, "desk"))
+	order := &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "60"}
+
+	_, err := h.SignOrder(strategy, &signerv1.SignOrderRequest{Order: order})
+	st := status.Convert(err)
+	if st.Code() != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+	var approvalID string
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Reason == ApprovalRequiredReason {
+			approvalID = info.Metadata["approval_id"]
+		}
+	}
+	if approvalID == "" || len(raised) != 1 || raised[0].ID != approvalID {
+		t.Fatalf("expected approval id in details and one alert, got %q %v", approvalID, raised)
+	}
+
+	list, err := h.ListPendingApprovals(desk, &signerv1.ListPendingApprovalsRequest{})
+	if err != nil || len(list.Approvals) != 1 || list.Approvals[0].Value != "60" {
+		t.Fatalf("unexpected pending list: %v %v", list, err)
+	}
+	if _, err := h.RespondToApproval(strategy, &signerv1.RespondToApprovalRequest{ApprovalId: approvalID, Approve: true}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected self-approval to be refused, got %v", err)
+	}
+	if resp, err := h.RespondToApproval(desk, &signerv1.RespondToApprovalRequest{ApprovalId: approvalID, Approve: true}); err != nil || resp.State != "approved" {
+		t.Fatalf("respond: %v %v", resp, err)
+	}
+
+	// A different order cannot ride on the approval.
+	other := &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "70"}
+	meta := &signerv1.RequestMetadata{ApprovalId: approvalID}
+	if _, err := h.SignOrder(strategy, &signerv1.SignOrderRequest{Order: other, Metadata: meta}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected mismatch rejection, got %v", err)
+	}
+	if _, err := h.SignOrder(strategy, &signerv1.SignOrderRequest{Order: order, Metadata: meta}); err != nil {
+		t.Fatalf("approved order: %v", err)
+	}
+	if _, err := h.SignOrder(strategy, &signerv1.SignOrderRequest{Order: order, Metadata: meta}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected approval to be single-use, got %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+package signer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Outcomes of an audited SignOrder call.
+const (
+	DecisionSigned   = "signed"
+	DecisionRejected = "rejected"
+)
+
+// AuditLinePrefix starts each decision record in the signer's log.
+const AuditLinePrefix = "audit decision "
+
+// AuditRecord is one SignOrder decision together with the session state
+// it was made against, enough to re-run the decision later.
+type AuditRecord struct {
+	At        time.Time       `json:"at"`
+	RequestID string          `json:"request_id"`
+	Client    string          `json:"client"`
+	Quota     string          `json:"quota,omitempty"`      // identity the client's quota is kept under
+	SessionID string          `json:"session_id,omitempty"` // empty for the default session
+	Epoch     uint64          `json:"epoch"`
+	Session   time.Time       `json:"session"` // when the session was activated
+	Active    bool            `json:"active"`
+	Limit     string          `json:"limit"` // session value limit
+	Used      string          `json:"used"`  // value used before this order
+	Order     json.RawMessage `json:"order"` // protojson PolymarketOrder
+	Decision  string          `json:"decision"`
+	Code      string          `json:"code,omitempty"` // gRPC code of a rejection
+	Reason    string          `json:"reason,omitempty"`
+
+	// Hash chain links, set by AuditChain.Append.
+	Seq  uint64 `json:"seq,omitempty"`
+	Prev string `json:"prev,omitempty"`
+	Hash string `json:"hash,omitempty"`
+}
+
+// WithAudit calls onDecision with a record of every SignOrder outcome.
+func WithAudit(onDecision func(AuditRecord)) Option {
+	return func(h *Handler) {
+		h.onAudit = onDecision
+	}
+}
+
+func newAuditRecord(ctx context.Context, o *signerv1.PolymarketOrder, epoch uint64, session time.Time, active bool, limit, used string, at time.Time, err error) AuditRecord {
+	raw, _ := protojson.Marshal(o)
+	r := AuditRecord{
+		At:        at,
+		RequestID: RequestID(ctx),
+		Client:    ClientID(ctx),
+		Quota:     quotaClient(ctx),
+		Epoch:     epoch,
+		Session:   session,
+		Active:    active,
+		Limit:     limit,
+		Used:      used,
+		Order:     raw,
+		Decision:  DecisionSigned,
+	}
+	if err != nil {
+		st := status.Convert(err)
+		r.Decision, r.Code, r.Reason = DecisionRejected, st.Code().String(), st.Message()
+	}
+	return r
+}
+
+// ReadAuditLog returns the decision records in a signer log, skipping
+// every other line. Lines may be bare JSON records or carry the
+// AuditLinePrefix the signer writes.
+func ReadAuditLog(r io.Reader) ([]AuditRecord, error) {
+	var records []AuditRecord
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		text := bytes.TrimSpace(sc.Bytes())
+		if rest, ok := bytes.CutPrefix(text, []byte(AuditLinePrefix)); ok {
+			text = rest
+		} else if !bytes.HasPrefix(text, []byte("{")) {
+			continue
+		}
+		var rec AuditRecord
+		if err := json.Unmarshal(text, &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, sc.Err()
+}
+
+// ReplayMismatch is a recorded decision the replay did not reproduce.
+type ReplayMismatch struct {
+	Index     int // position in the log
+	RequestID string
+	Field     string // "decision", "code" or "used"
+	Recorded  string
+	Replayed  string
+}
+
+// replayClientKey carries a recorded client identity into a replayed
+// SignOrder call.
+type replayClientKey struct{}
+
+// replayClient is a recorded client identity and the quota it signed
+// under.
+type replayClient struct {
+	id, quota string
+}
+
+// replayWallets accepts every signature: the wallets in a log are not
+// controlled by the replay key.
+type replayWallets struct{}
+
+func (replayWallets) IsValidSignature(context.Context, string, [32]byte, []byte) error { return nil }
+
+// replayTTL outlives any log: sessions in a replay end when the log says
+// they did, not by expiry.
+const replayTTL = 100 * 365 * 24 * time.Hour
+
+// Replay re-runs records through a fresh handler built with opts, on a
+// simulated clock and ledger, and returns every record whose outcome or
+// value-used differs from what was recorded. A mismatch means the log was
+// edited (records removed, reordered or altered) or the limit and policy
+// logic now decides differently.
+//
+// The replay signs with a throwaway key, so orders are re-pointed at its
+// address, and contract wallets are taken to accept it. Decisions that
+// depended on state outside the log — approvals, elevations, live book
+// data, a wallet's on-chain verdict — may not reproduce.
+//
+// Named sessions are independent, so each one's records are replayed on a
+// handler of their own.
+func Replay(ctx context.Context, records []AuditRecord, opts ...Option) ([]ReplayMismatch, error) {
+	var ids []string
+	bySession := make(map[string][]int)
+	for i, r := range records {
+		if _, ok := bySession[r.SessionID]; !ok {
+			ids = append(ids, r.SessionID)
+		}
+		bySession[r.SessionID] = append(bySession[r.SessionID], i)
+	}
+	var mismatches []ReplayMismatch
+	for _, id := range ids {
+		m, err := replayRecords(ctx, records, bySession[id], opts)
+		if err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, m...)
+	}
+	sort.SliceStable(mismatches, func(i, j int) bool { return mismatches[i].Index < mismatches[j].Index })
+	return mismatches, nil
+}
+
+// replayRecords replays the records at indices, which belong to one
+// session.
+func replayRecords(ctx context.Context, records []AuditRecord, indices []int, opts []Option) ([]ReplayMismatch, error) {
+	clk := clock.NewFake(records[indices[0]].At)
+	sm := NewSessionManager(replayTTL, WithClock(clk))
+	defer sm.Destroy()
+	h := NewHandler(sm, append([]Option{WithWalletVerifier(replayWallets{})}, opts...)...)
+
+	// A session is identified by its epoch and activation time: epochs
+	// restart from zero when the signer does.
+	type sessionID struct {
+		epoch uint64
+		at    time.Time
+	}
+	var mismatches []ReplayMismatch
+	var current sessionID
+	for _, i := range indices {
+		r := records[i]
+		if r.At.After(clk.Now()) {
+			clk.Set(r.At)
+		}
+		mismatch := func(field, recorded, replayed string) {
+			mismatches = append(mismatches, ReplayMismatch{Index: i, RequestID: r.RequestID, Field: field, Recorded: recorded, Replayed: replayed})
+		}
+
+		order := &signerv1.PolymarketOrder{}
+		if err := protojson.Unmarshal(r.Order, order); err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+
+		// Follow the recorded session lifecycle: a new epoch is a fresh
+		// activation whose ledger starts where the record says it did.
+		active, _, _, used, addr := sm.Status()
+		switch id := (sessionID{r.Epoch, r.Session}); {
+		case !r.Active:
+			sm.Destroy()
+		case !active || !id.at.Equal(current.at) || id.epoch != current.epoch:
+			var err error
+			if addr, err = replaySession(ctx, sm, r); err != nil {
+				return nil, fmt.Errorf("record %d: %w", i, err)
+			}
+			current = id
+		case used != r.Used:
+			mismatch("used", r.Used, used)
+		}
+		// The replay key stands in for the recorded one: it makes EOA
+		// orders and signs proxy and Safe orders for the recorded funder.
+		switch order.SignatureType {
+		case signerv1.SignatureType_SIGNATURE_TYPE_UNSPECIFIED, signerv1.SignatureType_SIGNATURE_TYPE_EOA:
+			order.Maker, order.Signer = addr, ""
+		case signerv1.SignatureType_SIGNATURE_TYPE_POLY_1271:
+		default:
+			order.Signer = addr
+		}
+
+		// Records from before quotas were kept per authenticated
+		// identity name only the client.
+		quota := r.Quota
+		if quota == "" {
+			quota = r.Client
+		}
+		rctx := context.WithValue(ctx, replayClientKey{}, replayClient{id: r.Client, quota: quota})
+		_, err := h.SignOrder(rctx, &signerv1.SignOrderRequest{Order: order})
+		decision, code := DecisionSigned, ""
+		if err != nil {
+			decision, code = DecisionRejected, status.Code(err).String()
+		}
+		switch {
+		case decision != r.Decision:
+			mismatch("decision", r.Decision, decision)
+		case code != r.Code:
+			mismatch("code", r.Code, code)
+		}
+	}
+	return mismatches, nil
+}
+
+// replaySession activates sm with a throwaway key and the limit and
+// value used recorded in r, and returns the session's address.
+func replaySession(ctx context.Context, sm *SessionManager, r AuditRecord) (string, error) {
+	limit, err := ParseAmount(r.Limit)
+	if err != nil {
+		return "", fmt.Errorf("limit: %w", err)
+	}
+	used, err := ParseAmount(r.Used)
+	if err != nil {
+		return "", fmt.Errorf("used: %w", err)
+	}
+	key := make([]byte, 32)
+	key[31] = 1
+	if err := sm.Activate(ctx, key, limit.BigInt()); err != nil {
+		return "", err
+	}
+	if !used.IsZero() {
+		if _, err := sm.Sign(ctx, [32]byte{}, used.BigInt()); err != nil {
+			return "", fmt.Errorf("seed used: %w", err)
+		}
+	}
+	_, _, _, _, addr := sm.Status()
+	return addr, nil
+}
@@ -0,0 +1,123 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+)
+
+// auditedRun signs orders of the given maker amounts against a session
+// limit of 600 and returns the audit records.
+func auditedRun(t *testing.T, amounts ...string) []AuditRecord {
+	t.Helper()
+	var records []AuditRecord
+	h := NewHandler(activeSession(t, 600), WithAudit(func(r AuditRecord) { records = append(records, r) }))
+	for _, amount := range amounts {
+		h.SignOrder(context.Background(), &signerv1.SignOrderRequest{
+			Order: &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: amount},
+		})
+	}
+	return records
+}
+
+func TestReplayReproducesDecisions(t *testing.T) {
+	records := auditedRun(t, "400", "300", "100", "50")
+	if len(records) != 4 || records[1].Decision != DecisionRejected || records[3].Used != "500" {
+		t.Fatalf("records = %+v", records)
+	}
+
+	mismatches, err := Replay(context.Background(), records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 0 {
+		t.Errorf("unexpected mismatches: %+v", mismatches)
+	}
+}
+
+func TestReplayDetectsTampering(t *testing.T) {
+	records := auditedRun(t, "400", "300", "100", "50")
+
+	// A removed signature leaves a gap in the ledger.
+	removed := append(append([]AuditRecord(nil), records[:2]...), records[3])
+	mismatches, _ := Replay(context.Background(), removed)
+	if len(mismatches) != 1 || mismatches[0].Field != "used" || mismatches[0].Index != 2 {
+		t.Errorf("removed record: %+v", mismatches)
+	}
+
+	// A rejection rewritten as signed.
+	edited := append([]AuditRecord(nil), records...)
+	edited[1].Decision, edited[1].Code = DecisionSigned, ""
+	mismatches, _ = Replay(context.Background(), edited)
+	if len(mismatches) != 1 || mismatches[0].Field != "decision" || mismatches[0].Recorded != DecisionSigned {
+		t.Errorf("edited record: %+v", mismatches)
+	}
+}
+
+func TestReplayDetectsLogicChange(t *testing.T) {
+	records := auditedRun(t, "100", "100")
+
+	// A one-order quota now refuses the second order.
+	quotas, err := NewQuotaTracker(1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mismatches, err := Replay(context.Background(), records, WithQuotas(quotas))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 1 || mismatches[0].Index != 1 || mismatches[0].Replayed != DecisionRejected {
+		t.Errorf("mismatches = %+v", mismatches)
+	}
+}
+
+func TestReplaySeparatesSessions(t *testing.T) {
+	main := auditedRun(t, "400", "300", "100")
+	arb := auditedRun(t, "500", "50")
+	var records []AuditRecord
+	for i := 0; i < len(main) || i < len(arb); i++ {
+		if i < len(main) {
+			records = append(records, main[i])
+		}
+		if i < len(arb) {
+			r := arb[i]
+			r.SessionID = "arb"
+			records = append(records, r)
+		}
+	}
+
+	mismatches, err := Replay(context.Background(), records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 0 {
+		t.Errorf("unexpected mismatches: %+v", mismatches)
+	}
+
+	// Indices still refer to the interleaved log.
+	records[3].Decision = DecisionRejected
+	mismatches, _ = Replay(context.Background(), records)
+	if len(mismatches) != 1 || mismatches[0].Index != 3 {
+		t.Errorf("mismatches = %+v", mismatches)
+	}
+}
+
+func TestReadAuditLog(t *testing.T) {
+	records := auditedRun(t, "100")
+	line, _ := json.Marshal(records[0])
+	log := "Caesar Signer starting\n" +
+		AuditLinePrefix + string(line) + "\n" +
+		`audit policy_rejection {"rule":"x"}` + "\n" +
+		string(line) + "\n"
+
+	got, err := ReadAuditLog(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].RequestID != records[0].RequestID || !got[1].At.Equal(records[0].At) {
+		t.Errorf("read %+v", got)
+	}
+}
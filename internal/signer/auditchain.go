@@ -0,0 +1,91 @@
+package signer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var ErrAuditChainBroken = errors.New("audit chain broken")
+
+// AuditChain links audit records into a hash chain: each record carries
+// the hash of the one before it, so removing, reordering or editing a
+// record breaks every later link. Anchoring the head externally bounds
+// how much history a host-level attacker could rewrite unnoticed.
+type AuditChain struct {
+	mu   sync.Mutex
+	seq  uint64
+	head string
+}
+
+// Append assigns r the next sequence number and links it to the chain,
+// returning the record to log.
+func (c *AuditChain) Append(r AuditRecord) AuditRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.appendLocked(r)
+}
+
+// Log appends r and writes it to w as one AuditLinePrefix line, under the
+// chain's lock so lines land in chain order.
+func (c *AuditChain) Log(w io.Writer, r AuditRecord) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, err := json.Marshal(c.appendLocked(r))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", AuditLinePrefix, entry)
+	return err
+}
+
+func (c *AuditChain) appendLocked(r AuditRecord) AuditRecord {
+	c.seq++
+	r.Seq, r.Prev = c.seq, c.head
+	r.Hash = auditHash(r)
+	c.head = r.Hash
+	return r
+}
+
+// Head returns the sequence number and hash of the last appended record;
+// zero and "" before the first.
+func (c *AuditChain) Head() (uint64, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.seq, c.head
+}
+
+// auditHash is SHA-256 over the record with its own hash cleared. Prev is
+// part of the record, which chains it.
+func auditHash(r AuditRecord) string {
+	r.Hash = ""
+	data, _ := json.Marshal(r)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyAuditChain checks that records form one unbroken chain from a
+// chain start (sequence 1) onwards. Chains restart with each signer run,
+// so a log spanning restarts holds several.
+func VerifyAuditChain(records []AuditRecord) error {
+	var prev AuditRecord
+	for i, r := range records {
+		switch {
+		case r.Seq == 1:
+			if r.Prev != "" {
+				return fmt.Errorf("%w: record %d starts a chain but links to %s", ErrAuditChainBroken, i+1, r.Prev)
+			}
+		case i == 0 || r.Seq != prev.Seq+1 || r.Prev != prev.Hash:
+			return fmt.Errorf("%w: record %d (seq %d) does not follow the record before it", ErrAuditChainBroken, i+1, r.Seq)
+		}
+		if r.Hash != auditHash(r) {
+			return fmt.Errorf("%w: record %d (seq %d) was altered", ErrAuditChainBroken, i+1, r.Seq)
+		}
+		prev = r
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package signer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func chainedRun(t *testing.T, amounts ...string) []AuditRecord {
+	t.Helper()
+	var chain AuditChain
+	records := auditedRun(t, amounts...)
+	for i := range records {
+		records[i] = chain.Append(records[i])
+	}
+	if seq, head := chain.Head(); seq != uint64(len(records)) || head != records[len(records)-1].Hash {
+		t.Fatalf("head = %d %s", seq, head)
+	}
+	return records
+}
+
+func TestAuditChainVerifies(t *testing.T) {
+	first := chainedRun(t, "100", "200")
+	// A signer restart begins a new chain in the same log.
+	second := chainedRun(t, "300")
+	if err := VerifyAuditChain(append(first, second...)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuditChainDetectsTampering(t *testing.T) {
+	records := chainedRun(t, "100", "200", "300")
+
+	edited := append([]AuditRecord(nil), records...)
+	edited[1].Decision = DecisionRejected
+	removed := []AuditRecord{records[0], records[2]}
+	swapped := []AuditRecord{records[0], records[2], records[1]}
+	truncatedHead := records[1:]
+
+	for name, log := range map[string][]AuditRecord{
+		"edited": edited, "removed": removed, "swapped": swapped, "truncated head": truncatedHead,
+	} {
+		if err := VerifyAuditChain(log); !errors.Is(err, ErrAuditChainBroken) {
+			t.Errorf("%s: expected ErrAuditChainBroken, got %v", name, err)
+		}
+	}
+}
+
+func TestAuditChainLog(t *testing.T) {
+	var chain AuditChain
+	var buf strings.Builder
+	for _, r := range auditedRun(t, "100", "200") {
+		if err := chain.Log(&buf, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records, err := ReadAuditLog(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("read %d records", len(records))
+	}
+	if err := VerifyAuditChain(records); err != nil {
+		t.Errorf("chain read back from the log: %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package signer
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxBatchOrders caps the orders in one SignOrders request, bounding how
+// long a batch holds the session lock.
+const MaxBatchOrders = 50
+
+func (h *Handler) signOrders(ctx context.Context, req *signerv1.SignOrdersRequest) (*signerv1.SignOrdersResponse, error) {
+	switch n := len(req.Orders); {
+	case n == 0:
+		return nil, status.Errorf(codes.InvalidArgument, "orders are required")
+	case n > MaxBatchOrders:
+		return nil, status.Errorf(codes.InvalidArgument, "%d orders exceeds the batch limit of %d", n, MaxBatchOrders)
+	}
+	if req.Metadata.GetApprovalId() != "" {
+		return nil, status.Errorf(codes.InvalidArgument, "approvals are not supported for batches; sign the approved order with SignOrder")
+	}
+
+	_, _, _, _, addr := h.session.Status()
+	client, quota, epoch := ClientID(ctx), quotaClient(ctx), h.session.Epoch()
+	var elevated []string
+	if h.elevator != nil {
+		elevated = h.elevator.Scopes()
+	}
+
+	// Every order must pass before any is signed.
+	checked := make([]*orderCheck, len(req.Orders))
+	digests := make([][32]byte, len(req.Orders))
+	values := make([]*big.Int, len(req.Orders))
+	total := new(big.Int)
+	for i, o := range req.Orders {
+		if o.GetOrder() == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "order %d: order is required", i)
+		}
+		if o.Metadata != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "order %d: metadata is set on the batch, not its orders", i)
+		}
+		if o.SessionId != "" {
+			return nil, status.Errorf(codes.InvalidArgument, "order %d: session_id is set on the batch, not its orders", i)
+		}
+		c, err := h.checkOrder(ctx, o, addr, elevated)
+		if err != nil {
+			return nil, batchStatus(i, err)
+		}
+		checked[i], digests[i], values[i] = c, c.digest, c.value.BigInt()
+		total.Add(total, values[i])
+	}
+
+	if h.quotas != nil {
+		if err := h.quotas.Reserve(epoch, quota, total); err != nil {
+			return nil, status.Errorf(codes.ResourceExhausted, "client quota exceeded for %s", quota)
+		}
+	}
+
+	var priority int32
+	var notAfter time.Time
+	if m := req.Metadata; m != nil {
+		priority = m.Priority
+		if m.NotAfter > 0 {
+			notAfter = time.Unix(0, m.NotAfter)
+		}
+	}
+	if err := h.gate.Acquire(ctx, priority, notAfter); err != nil {
+		if h.quotas != nil {
+			h.quotas.Release(epoch, quota, total)
+		}
+		if errors.Is(err, ErrRequestStale) {
+			return nil, status.Errorf(codes.DeadlineExceeded, "request not_after elapsed before signing")
+		}
+		return nil, status.FromContextError(err).Err()
+	}
+	orders := make([]*signerv1.PolymarketOrder, len(checked))
+	for i, c := range checked {
+		orders[i] = c.order
+	}
+	if h.replays != nil {
+		if err := h.replays.Claim(epoch, orders...); err != nil {
+			h.gate.Release()
+			if h.quotas != nil {
+				h.quotas.Release(epoch, quota, total)
+			}
+			return nil, status.Errorf(codes.AlreadyExists, "%v", err)
+		}
+	}
+	var batchRelied []string
+	sigs, err := h.session.SignBatch(ctx, digests, values)
+	if errors.Is(err, ErrApprovalRequired) && slices.Contains(elevated, ScopeApproval) {
+		sigs, err = h.session.SignBatchApproved(ctx, digests, values)
+		batchRelied = append(batchRelied, ScopeApproval)
+	}
+	h.gate.Release()
+	if err != nil {
+		if h.quotas != nil {
+			h.quotas.Release(epoch, quota, total)
+		}
+		if h.replays != nil {
+			h.replays.Release(epoch, orders...)
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, status.FromContextError(err).Err()
+		}
+		// The windows, order count and signing rate pace the session
+		// without breaching its value limit, so they raise no alert.
+		if st := orderLimitStatus(err); st != nil {
+			return nil, st
+		}
+		switch err {
+		case ErrNoActiveSession:
+			return nil, status.Errorf(codes.FailedPrecondition, "no active session")
+		case ErrSessionExpired:
+			return nil, status.Errorf(codes.FailedPrecondition, "session expired")
+		case ErrValueLimitExceeded:
+			if h.onLimit != nil {
+				h.onLimit(LimitBreach{Client: client, Value: total, RequestID: RequestID(ctx)})
+			}
+			return nil, status.Errorf(codes.ResourceExhausted, "cumulative value limit exceeded")
+		case ErrOrderValueExceeded:
+			return nil, orderValueExceededStatus(h.session.MaxOrderValue())
+		case ErrApprovalRequired:
+			return nil, status.Errorf(codes.FailedPrecondition, "an order requires approval under limit profile %q; sign it alone with SignOrder", h.session.ActiveProfile())
+		case ErrHandoffPending:
+			return nil, status.Errorf(codes.FailedPrecondition, "session is being handed off")
+		case ErrSessionPaused:
+			return nil, sessionPausedStatus()
+		default:
+			return nil, status.Errorf(codes.Internal, "signing failed: %v", err)
+		}
+	}
+
+	// As for SignOrder, a contract wallet's verdict comes after the batch
+	// has counted against the limits; one rejection withholds them all.
+	for i, c := range checked {
+		if !c.contractWallet {
+			continue
+		}
+		if err := h.wallets.IsValidSignature(ctx, c.order.Maker, c.digest, sigs[i]); err != nil {
+			if errors.Is(err, ErrWalletRejected) {
+				return nil, status.Errorf(codes.FailedPrecondition, "order %d: %v", i, err)
+			}
+			return nil, status.Errorf(codes.Unavailable, "order %d: verify signature with wallet %s: %v", i, c.order.Maker, err)
+		}
+	}
+
+	for _, c := range checked {
+		for _, scope := range c.relied {
+			h.elevator.recordUse(scope, client, RequestID(ctx))
+		}
+		h.recordShadowHits(ctx, epoch, c)
+	}
+	for _, scope := range batchRelied {
+		h.elevator.recordUse(scope, client, RequestID(ctx))
+	}
+
+	signedAt := h.session.Clock().Now().UnixNano()
+	resp := &signerv1.SignOrdersResponse{Orders: make([]*signerv1.SignOrderResponse, len(checked))}
+	for i, c := range checked {
+		resp.Orders[i] = &signerv1.SignOrderResponse{
+			Signature:     "0x" + hex.EncodeToString(sigs[i]),
+			SignerAddress: addr,
+			SignedAt:      signedAt,
+			RequestId:     RequestID(ctx),
+			Order:         c.order,
+		}
+	}
+	return resp, nil
+}
+
+// batchStatus prefixes a per-order check failure with the order's index,
+// keeping its code and details.
+func batchStatus(i int, err error) error {
+	p := status.Convert(err).Proto()
+	p.Message = fmt.Sprintf("order %d: %s", i, p.Message)
+	return status.ErrorProto(p)
+}
@@ -0,0 +1,93 @@
+package signer
+
+import (
+	"context"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func batchOrders(amounts ...string) []*signerv1.SignOrderRequest {
+	reqs := make([]*signerv1.SignOrderRequest, len(amounts))
+	for i, amt := range amounts {
+		reqs[i] = &signerv1.SignOrderRequest{Order: &signerv1.PolymarketOrder{
+			Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: amt, Salt: strconv.Itoa(i + 1),
+		}}
+	}
+	return reqs
+}
+
+func TestSignOrders(t *testing.T) {
+	sm := activeSession(t, 1_000)
+	var records []AuditRecord
+	h := NewHandler(sm, WithAudit(func(r AuditRecord) { records = append(records, r) }))
+
+	resp, err := h.SignOrders(context.Background(), &signerv1.SignOrdersRequest{Orders: batchOrders("100", "200", "300")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Orders) != 3 {
+		t.Fatalf("got %d results", len(resp.Orders))
+	}
+	for i, r := range resp.Orders {
+		digest, _ := OrderDigest(r.Order)
+		sig, _ := hex.DecodeString(r.Signature[2:])
+		if addr, err := recoverAddress(digest, sig); err != nil || addr != testMaker {
+			t.Errorf("order %d: signature recovers to %s, %v", i, addr, err)
+		}
+	}
+	if _, _, _, used, _ := sm.Status(); used != "600" {
+		t.Errorf("value used = %s, want 600", used)
+	}
+	if len(records) != 3 || records[2].Decision != DecisionSigned {
+		t.Errorf("audit records = %+v", records)
+	}
+
+	// 600 + 500 exceeds the limit, though each order alone would fit.
+	_, err = h.SignOrders(context.Background(), &signerv1.SignOrdersRequest{Orders: batchOrders("250", "250")})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("over limit: expected ResourceExhausted, got %v", err)
+	}
+	if _, _, _, used, _ := sm.Status(); used != "600" {
+		t.Errorf("a refused batch spent limit: used = %s", used)
+	}
+	if len(records) != 5 || records[4].Decision != DecisionRejected {
+		t.Errorf("refused batch should audit each order as rejected: %+v", records[3:])
+	}
+}
+
+func TestSignOrdersRejectsWhole(t *testing.T) {
+	sm := activeSession(t, 1_000)
+	h := NewHandler(sm)
+
+	orders := batchOrders("100", "bogus", "100")
+	_, err := h.SignOrders(context.Background(), &signerv1.SignOrdersRequest{Orders: orders})
+	if status.Code(err) != codes.InvalidArgument || !strings.HasPrefix(status.Convert(err).Message(), "order 1:") {
+		t.Errorf("invalid order: expected InvalidArgument naming order 1, got %v", err)
+	}
+	if _, _, _, used, _ := sm.Status(); used != "0" {
+		t.Errorf("a refused batch spent limit: used = %s", used)
+	}
+
+	orders = batchOrders("100")
+	orders[0].Metadata = &signerv1.RequestMetadata{Priority: 1}
+	if _, err := h.SignOrders(context.Background(), &signerv1.SignOrdersRequest{Orders: orders}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("per-order metadata: expected InvalidArgument, got %v", err)
+	}
+
+	amounts := make([]string, MaxBatchOrders+1)
+	for i := range amounts {
+		amounts[i] = "1"
+	}
+	if _, err := h.SignOrders(context.Background(), &signerv1.SignOrdersRequest{Orders: batchOrders(amounts...)}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("oversized batch: expected InvalidArgument, got %v", err)
+	}
+	if _, err := h.SignOrders(context.Background(), &signerv1.SignOrdersRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("empty batch: expected InvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package signer
+
+import "strings"
+
+// CanaryTrip records a signing attempt against a honeytoken market. It
+// carries only the token ID so it is safe to log.
+type CanaryTrip struct {
+	TokenID   string
+	RequestID string
+}
+
+// canarySet holds token IDs that no legitimate strategy would ever trade.
+type canarySet map[string]struct{}
+
+// ParseCanaryTokens parses a comma-separated list of canary token IDs.
+func ParseCanaryTokens(spec string) []string {
+	var tokens []string
+	for _, t := range strings.Split(spec, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+func newCanarySet(tokens []string) canarySet {
+	set := make(canarySet, len(tokens))
+	for _, t := range tokens {
+		set[t] = struct{}{}
+	}
+	return set
+}
+
+func (c canarySet) contains(tokenID string) bool {
+	_, ok := c[tokenID]
+	return ok
+}
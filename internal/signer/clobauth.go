@@ -0,0 +1,68 @@
+package signer
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"strconv"
+
+	"github.com/caesar-terminal/caesar/internal/eip712"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ClobAuthMessage is the fixed statement a ClobAuth payload attests to.
+const ClobAuthMessage = "This message attests that I control the given wallet"
+
+// ClobAuthDomain returns the EIP-712 domain of CLOB L1 authentication on
+// chainID. It has no verifying contract.
+func ClobAuthDomain(chainID uint64) eip712.Domain {
+	return eip712.Domain{Name: "ClobAuthDomain", Version: "1", ChainID: chainID}
+}
+
+// ClobAuthDigest returns the digest the CLOB expects signed by address to
+// create or derive API credentials: ClobAuth(address address,string
+// timestamp,uint256 nonce,string message) on ClobAuthDomain.
+func ClobAuthDigest(chainID uint64, address string, timestamp int64, nonce uint64) ([32]byte, error) {
+	td := &eip712.TypedData{Types: map[string][]eip712.TypedField{"ClobAuth": {
+		{Name: "address", Type: "address"},
+		{Name: "timestamp", Type: "string"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "message", Type: "string"},
+	}}}
+	return td.MessageDigest(ClobAuthDomain(chainID), "ClobAuth", map[string]any{
+		"address":   address,
+		"timestamp": strconv.FormatInt(timestamp, 10),
+		"nonce":     strconv.FormatUint(nonce, 10),
+		"message":   ClobAuthMessage,
+	})
+}
+
+// SignClobAuth signs a ClobAuth attestation for the session address on
+// the exchange's chain. Like SignTypedData it spends no value limit.
+func (h *Handler) SignClobAuth(ctx context.Context, req *signerv1.SignClobAuthRequest) (*signerv1.SignClobAuthResponse, error) {
+	_, _, _, _, addr := h.session.Status()
+	if addr == "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "no active session")
+	}
+	ts := req.Timestamp
+	if ts == 0 {
+		ts = h.session.Clock().Now().Unix()
+	}
+	digest, err := ClobAuthDigest(h.network.ChainID, addr, ts, req.Nonce)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "clob auth digest: %v", err)
+	}
+	sig, err := h.session.Sign(ctx, digest, new(big.Int))
+	if err != nil {
+		return nil, valuelessSignStatus(err)
+	}
+	return &signerv1.SignClobAuthResponse{
+		Signature: "0x" + hex.EncodeToString(sig),
+		Address:   addr,
+		Timestamp: ts,
+		Nonce:     req.Nonce,
+		RequestId: RequestID(ctx),
+	}, nil
+}
@@ -0,0 +1,75 @@
+package signer
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/eip712"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClobAuthDigest(t *testing.T) {
+	// The same payload in its eth_signTypedData_v4 form, as the CLOB
+	// clients build it.
+	td, err := eip712.Parse([]byte(`{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"}
+    ],
+    "ClobAuth": [
+      {"name": "address", "type": "address"},
+      {"name": "timestamp", "type": "string"},
+      {"name": "nonce", "type": "uint256"},
+      {"name": "message", "type": "string"}
+    ]
+  },
+  "primaryType": "ClobAuth",
+  "domain": {"name": "ClobAuthDomain", "version": "1", "chainId": 137},
+  "message": {
+    "address": "` + testMaker + `",
+    "timestamp": "1700000000",
+    "nonce": 7,
+    "message": "This message attests that I control the given wallet"
+  }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := td.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ClobAuthDigest(137, testMaker, 1_700_000_000, 7); err != nil || got != want {
+		t.Errorf("ClobAuthDigest = %x, %v; want %x", got, err, want)
+	}
+}
+
+func TestSignClobAuth(t *testing.T) {
+	if _, err := NewHandler(NewSessionManager(time.Hour)).SignClobAuth(context.Background(), &signerv1.SignClobAuthRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("no session: expected FailedPrecondition, got %v", err)
+	}
+
+	sm := activeSession(t, 1_000)
+	resp, err := NewHandler(sm).SignClobAuth(context.Background(), &signerv1.SignClobAuthRequest{Nonce: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Address != testMaker || resp.Nonce != 2 || resp.Timestamp == 0 {
+		t.Errorf("response = %+v", resp)
+	}
+	digest, _ := ClobAuthDigest(137, testMaker, resp.Timestamp, 2)
+	sig, _ := hex.DecodeString(strings.TrimPrefix(resp.Signature, "0x"))
+	if addr, err := recoverAddress(digest, sig); err != nil || addr != testMaker {
+		t.Errorf("signature recovers to %s, %v", addr, err)
+	}
+	if _, _, _, used, _ := sm.Status(); used != "0" {
+		t.Errorf("value used = %s, auth must not consume limit", used)
+	}
+}
@@ -0,0 +1,132 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/caesar-terminal/caesar/internal/eip712"
+)
+
+var ErrWalletRejected = errors.New("wallet rejected the signature")
+
+// eip1271Magic is the isValidSignature(bytes32,bytes) selector, which a
+// contract wallet returns for a signature it accepts.
+var eip1271Magic = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+// WalletVerifier asks a contract wallet whether it accepts a signature
+// over digest, returning ErrWalletRejected if it does not.
+type WalletVerifier interface {
+	IsValidSignature(ctx context.Context, wallet string, digest [32]byte, sig []byte) error
+}
+
+// WithWalletVerifier enables EIP-1271 orders: each signature is checked
+// against the maker's isValidSignature before it is returned.
+func WithWalletVerifier(v WalletVerifier) Option {
+	return func(h *Handler) {
+		h.wallets = v
+	}
+}
+
+// RPCWalletVerifier calls isValidSignature through a Polygon JSON-RPC
+// node's eth_call.
+type RPCWalletVerifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewRPCWalletVerifier creates a verifier using the node at url. A nil
+// client uses http.DefaultClient.
+func NewRPCWalletVerifier(url string, client *http.Client) *RPCWalletVerifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &RPCWalletVerifier{url: url, client: client}
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	Result string `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// IsValidSignature runs isValidSignature(digest, sig) on wallet at the
+// latest block. A revert counts as a rejection, as it does on chain.
+func (v *RPCWalletVerifier) IsValidSignature(ctx context.Context, wallet string, digest [32]byte, sig []byte) error {
+	if _, err := eip712.EncodeAddress(wallet); err != nil {
+		return err
+	}
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "eth_call",
+		Params: []any{
+			map[string]string{"to": wallet, "data": "0x" + hex.EncodeToString(isValidSignatureCall(digest, sig))},
+			"latest",
+		},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("polygon rpc: status %d", resp.StatusCode)
+	}
+	var out rpcResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&out); err != nil {
+		return fmt.Errorf("polygon rpc: %w", err)
+	}
+	if out.Error != nil {
+		// Nodes report reverts as errors; code 3 carries revert data.
+		if out.Error.Code == 3 || strings.Contains(out.Error.Message, "revert") {
+			return fmt.Errorf("%w: %s reverted: %s", ErrWalletRejected, wallet, out.Error.Message)
+		}
+		return fmt.Errorf("polygon rpc: %s (code %d)", out.Error.Message, out.Error.Code)
+	}
+	ret, err := hex.DecodeString(strings.TrimPrefix(out.Result, "0x"))
+	if err != nil {
+		return fmt.Errorf("polygon rpc: result %q: %w", out.Result, err)
+	}
+	// The bytes4 return value is left-aligned in its 32-byte word.
+	if len(ret) < 32 || !bytes.Equal(ret[:4], eip1271Magic[:]) {
+		return fmt.Errorf("%w: %s returned 0x%x", ErrWalletRejected, wallet, ret)
+	}
+	return nil
+}
+
+// isValidSignatureCall ABI-encodes isValidSignature(bytes32,bytes).
+func isValidSignatureCall(digest [32]byte, sig []byte) []byte {
+	padded := (len(sig) + 31) / 32 * 32
+	data := make([]byte, 4+32*3+padded)
+	copy(data, eip1271Magic[:])
+	copy(data[4:], digest[:])
+	data[4+63] = 0x40 // offset of the bytes argument
+	binary.BigEndian.PutUint64(data[4+88:], uint64(len(sig)))
+	copy(data[4+96:], sig)
+	return data
+}
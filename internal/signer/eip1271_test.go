@@ -0,0 +1,113 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testWallet = "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"
+
+// fakeWalletNode answers eth_call for a contract wallet at testWallet that
+// accepts signatures from owner, and reverts for any other address.
+func fakeWalletNode(t *testing.T, owner string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		var call struct{ To, Data string }
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "eth_call" || len(req.Params) != 2 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		json.Unmarshal(req.Params[0], &call)
+		if !strings.EqualFold(call.To, testWallet) {
+			json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "error": map[string]any{"code": 3, "message": "execution reverted"}})
+			return
+		}
+		data, _ := hex.DecodeString(strings.TrimPrefix(call.Data, "0x"))
+		var digest [32]byte
+		copy(digest[:], data[4:36])
+		ret := make([]byte, 32)
+		if addr, err := recoverAddress(digest, data[100:165]); err == nil && addr == owner && bytes.Equal(data[:4], eip1271Magic[:]) {
+			copy(ret, eip1271Magic[:])
+		} else {
+			copy(ret, []byte{0xff, 0xff, 0xff, 0xff})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": "0x" + hex.EncodeToString(ret)})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestIsValidSignatureCall(t *testing.T) {
+	var digest [32]byte
+	digest[31] = 0xaa
+	data := isValidSignatureCall(digest, bytes.Repeat([]byte{1}, 65))
+	want := "1626ba7e" +
+		strings.Repeat("0", 62) + "aa" +
+		strings.Repeat("0", 62) + "40" +
+		strings.Repeat("0", 62) + "41" +
+		strings.Repeat("01", 65) + strings.Repeat("0", 62)
+	if got := hex.EncodeToString(data); got != want {
+		t.Errorf("calldata\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestRPCWalletVerifier(t *testing.T) {
+	srv := fakeWalletNode(t, testMaker)
+	v := NewRPCWalletVerifier(srv.URL, srv.Client())
+	digest := [32]byte{1}
+	sig, err := signDigest(testKey(), digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.IsValidSignature(context.Background(), testWallet, digest, sig); err != nil {
+		t.Errorf("owner signature: %v", err)
+	}
+	if err := v.IsValidSignature(context.Background(), testWallet, [32]byte{2}, sig); !errors.Is(err, ErrWalletRejected) {
+		t.Errorf("signature over another digest: expected ErrWalletRejected, got %v", err)
+	}
+	if err := v.IsValidSignature(context.Background(), testMaker, digest, sig); !errors.Is(err, ErrWalletRejected) {
+		t.Errorf("reverting wallet: expected ErrWalletRejected, got %v", err)
+	}
+}
+
+func TestSignOrderContractWallet(t *testing.T) {
+	order := &signerv1.PolymarketOrder{Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "100", SignatureType: signerv1.SignatureType_SIGNATURE_TYPE_POLY_1271}
+	req := &signerv1.SignOrderRequest{Order: order, Funder: testWallet}
+
+	if _, err := NewHandler(activeSession(t, 1_000_000)).SignOrder(context.Background(), req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("without a verifier: expected FailedPrecondition, got %v", err)
+	}
+
+	srv := fakeWalletNode(t, testMaker)
+	h := NewHandler(activeSession(t, 1_000_000), WithWalletVerifier(NewRPCWalletVerifier(srv.URL, srv.Client())))
+	resp, err := h.SignOrder(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Order.Maker != testWallet || resp.Order.Signer != "" {
+		t.Errorf("signed order maker %s signer %s", resp.Order.Maker, resp.Order.Signer)
+	}
+
+	// A wallet the key does not own withholds the signature.
+	srv = fakeWalletNode(t, "0x0000000000000000000000000000000000000001")
+	h = NewHandler(activeSession(t, 1_000_000), WithWalletVerifier(NewRPCWalletVerifier(srv.URL, srv.Client())))
+	if _, err := h.SignOrder(context.Background(), req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("foreign wallet: expected FailedPrecondition, got %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/caesar-terminal/caesar/internal/eip712"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrInvalidOrder = errors.New("invalid order")
+	ErrUnknownChain = errors.New("no exchange domains for chain")
+)
+
+// Polymarket exchange domains on Polygon. Orders on neg-risk markets are
+// settled by a separate exchange contract and must be signed for it.
+var (
+	CTFExchange = eip712.Domain{
+		Name:              "Polymarket CTF Exchange",
+		Version:           "1",
+		ChainID:           137,
+		VerifyingContract: "0x4bFb41d5B3570DeFd03C39a9A4D8dE6Bd8B8982E",
+	}
+	NegRiskCTFExchange = eip712.Domain{
+		Name:              "Polymarket CTF Exchange",
+		Version:           "1",
+		ChainID:           137,
+		VerifyingContract: "0xC5d563A36AE78145C45a50134d48A1215220f80a",
+	}
+	// The same exchanges on the Amoy testnet.
+	AmoyCTFExchange = eip712.Domain{
+		Name:              "Polymarket CTF Exchange",
+		Version:           "1",
+		ChainID:           80002,
+		VerifyingContract: "0xdFE02Eb6733538f8Ea35D585af8DE5958AD99E40",
+	}
+	AmoyNegRiskCTFExchange = eip712.Domain{
+		Name:              "Polymarket CTF Exchange",
+		Version:           "1",
+		ChainID:           80002,
+		VerifyingContract: "0xd91E80cF2E7be2e162c6513ceD06f1dD0dA35296",
+	}
+)
+
+// Network is a chain orders can be signed for, with its exchange domains
+// and the NegRiskAdapter that converts multi-outcome positions.
+type Network struct {
+	ChainID         uint64
+	Exchange        eip712.Domain
+	NegRiskExchange eip712.Domain
+	NegRiskAdapter  string
+}
+
+var (
+	Polygon = Network{
+		ChainID:         137,
+		Exchange:        CTFExchange,
+		NegRiskExchange: NegRiskCTFExchange,
+		NegRiskAdapter:  "0xd91E80cF2E7be2e162c6513ceD06f1dD0dA35296",
+	}
+	Amoy = Network{
+		ChainID:         80002,
+		Exchange:        AmoyCTFExchange,
+		NegRiskExchange: AmoyNegRiskCTFExchange,
+		NegRiskAdapter:  "0xd91E80cF2E7be2e162c6513ceD06f1dD0dA35296",
+	}
+)
+
+// NetworkFor returns the network with the given chain ID.
+func NetworkFor(chainID uint64) (Network, error) {
+	for _, n := range []Network{Polygon, Amoy} {
+		if n.ChainID == chainID {
+			return n, nil
+		}
+	}
+	return Network{}, fmt.Errorf("%w: %d", ErrUnknownChain, chainID)
+}
+
+// OrderDomain returns the exchange domain o must be signed for on n.
+func (n Network) OrderDomain(o *signerv1.PolymarketOrder) eip712.Domain {
+	if o.NegRisk {
+		return n.NegRiskExchange
+	}
+	return n.Exchange
+}
+
+// OrderDigest is OrderDigest on n.
+func (n Network) OrderDigest(o *signerv1.PolymarketOrder) ([32]byte, error) {
+	sep, err := n.OrderDomain(o).Separator()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	structHash, err := OrderStructHash(o)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return eip712.Digest(sep, structHash), nil
+}
+
+var orderTypeHash = eip712.TypeHash("Order(uint256 salt,address maker,address signer,address taker,uint256 tokenId,uint256 makerAmount,uint256 takerAmount,uint256 expiration,uint256 nonce,uint256 feeRateBps,uint8 side,uint8 signatureType)")
+
+// OrderDomain returns the Polygon exchange domain o must be signed for.
+func OrderDomain(o *signerv1.PolymarketOrder) eip712.Domain {
+	return Polygon.OrderDomain(o)
+}
+
+// OrderStructHash returns the EIP-712 hashStruct of o as the exchange's
+// Order type. An empty signer defaults to the maker, which only EOA orders
+// allow; an empty taker is the zero address (a public order).
+func OrderStructHash(o *signerv1.PolymarketOrder) ([32]byte, error) {
+	var side uint64
+	switch o.Side {
+	case signerv1.OrderSide_ORDER_SIDE_BUY:
+		side = 0
+	case signerv1.OrderSide_ORDER_SIDE_SELL:
+		side = 1
+	default:
+		return [32]byte{}, fmt.Errorf("%w: side is required", ErrInvalidOrder)
+	}
+	// The proto enum reserves 0 for unspecified, which means EOA.
+	var sigType uint64
+	switch o.SignatureType {
+	case signerv1.SignatureType_SIGNATURE_TYPE_UNSPECIFIED, signerv1.SignatureType_SIGNATURE_TYPE_EOA:
+	case signerv1.SignatureType_SIGNATURE_TYPE_POLY_PROXY, signerv1.SignatureType_SIGNATURE_TYPE_POLY_GNOSIS_SAFE:
+		if o.Signer == "" {
+			return [32]byte{}, fmt.Errorf("%w: signer is required for %s orders", ErrInvalidOrder, o.SignatureType)
+		}
+		sigType = uint64(o.SignatureType) - 1
+	case signerv1.SignatureType_SIGNATURE_TYPE_POLY_1271:
+		sigType = uint64(o.SignatureType) - 1
+	default:
+		return [32]byte{}, fmt.Errorf("%w: unknown signature type %d", ErrInvalidOrder, o.SignatureType)
+	}
+
+	signer := orderSigner(o)
+	taker := o.Taker
+	if taker == "" {
+		taker = "0x0000000000000000000000000000000000000000"
+	}
+
+	fields := [][]byte{orderTypeHash[:]}
+	for _, f := range []struct {
+		name, value string
+		addr        bool
+	}{
+		{"salt", o.Salt, false},
+		{"maker", o.Maker, true},
+		{"signer", signer, true},
+		{"taker", taker, true},
+		{"token_id", o.TokenId, false},
+		{"maker_amount", o.MakerAmount, false},
+		{"taker_amount", o.TakerAmount, false},
+	} {
+		var enc []byte
+		var err error
+		if f.addr {
+			enc, err = eip712.EncodeAddress(f.value)
+		} else {
+			enc, err = eip712.EncodeUintString(f.value)
+		}
+		if err != nil {
+			return [32]byte{}, fmt.Errorf("%w: %s: %v", ErrInvalidOrder, f.name, err)
+		}
+		fields = append(fields, enc)
+	}
+	for _, n := range []uint64{o.Expiration, o.Nonce, uint64(o.FeeRateBps), side, sigType} {
+		fields = append(fields, eip712.EncodeUint(new(big.Int).SetUint64(n)))
+	}
+	return eip712.Keccak256(fields...), nil
+}
+
+// prepareOrder returns a copy of o with maker and signer filled in for
+// signing by the key at sessionAddr. For EOA orders the key is the maker;
+// for proxy-wallet and Safe orders funder holds the funds and becomes the
+// maker, and the key signs as the owner. EIP-1271 orders are made and
+// signed by the funder.
+func prepareOrder(o *signerv1.PolymarketOrder, funder, sessionAddr string) (*signerv1.PolymarketOrder, error) {
+	o = proto.Clone(o).(*signerv1.PolymarketOrder)
+	if funder != "" {
+		if o.Maker != "" && !strings.EqualFold(o.Maker, funder) {
+			return nil, fmt.Errorf("%w: maker %s conflicts with funder %s", ErrInvalidOrder, o.Maker, funder)
+		}
+		o.Maker = funder
+	}
+	switch o.SignatureType {
+	case signerv1.SignatureType_SIGNATURE_TYPE_UNSPECIFIED, signerv1.SignatureType_SIGNATURE_TYPE_EOA:
+		if o.Maker == "" {
+			o.Maker = sessionAddr
+		}
+		if funder != "" && sessionAddr != "" && !strings.EqualFold(funder, sessionAddr) {
+			return nil, fmt.Errorf("%w: EOA orders are funded by the signing key; use a proxy or Safe signature type for funder %s", ErrInvalidOrder, funder)
+		}
+		if o.Signer != "" && !strings.EqualFold(o.Signer, o.Maker) {
+			return nil, fmt.Errorf("%w: EOA orders are signed by their maker; use a proxy or Safe signature type to sign for %s", ErrInvalidOrder, o.Maker)
+		}
+	case signerv1.SignatureType_SIGNATURE_TYPE_POLY_PROXY, signerv1.SignatureType_SIGNATURE_TYPE_POLY_GNOSIS_SAFE:
+		if o.Maker == "" {
+			return nil, fmt.Errorf("%w: %s orders need a funder (the wallet holding the funds)", ErrInvalidOrder, o.SignatureType)
+		}
+		if o.Signer == "" {
+			o.Signer = sessionAddr
+		}
+		if strings.EqualFold(o.Maker, o.Signer) {
+			return nil, fmt.Errorf("%w: %s orders are funded by a wallet other than the signing key", ErrInvalidOrder, o.SignatureType)
+		}
+	case signerv1.SignatureType_SIGNATURE_TYPE_POLY_1271:
+		// The contract wallet signs for itself; the session key only
+		// produces the signature it validates.
+		if o.Maker == "" {
+			return nil, fmt.Errorf("%w: %s orders need a funder (the contract wallet)", ErrInvalidOrder, o.SignatureType)
+		}
+		if o.Signer != "" && !strings.EqualFold(o.Signer, o.Maker) {
+			return nil, fmt.Errorf("%w: %s orders are signed by the wallet %s", ErrInvalidOrder, o.SignatureType, o.Maker)
+		}
+	}
+	return o, nil
+}
+
+// orderSigner returns the address that must sign o.
+func orderSigner(o *signerv1.PolymarketOrder) string {
+	if o.Signer != "" {
+		return o.Signer
+	}
+	return o.Maker
+}
+
+// OrderDigest returns the EIP-712 digest of o on Polygon — the 32 bytes
+// actually signed: keccak256(0x1901 ‖ domainSeparator ‖ hashStruct(order)).
+func OrderDigest(o *signerv1.PolymarketOrder) ([32]byte, error) {
+	return Polygon.OrderDigest(o)
+}
@@ -0,0 +1,294 @@
+package signer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/caesar-terminal/caesar/internal/eip712"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"google.golang.org/protobuf/proto"
+)
+
+// testMaker is a well-formed maker address for test orders.
+const testMaker = "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"
+
+// testKey returns the private key 1, whose address is testMaker.
+func testKey() []byte {
+	key := make([]byte, 32)
+	key[31] = 1
+	return key
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestSignDigestRFC6979Vector(t *testing.T) {
+	// Widely published secp256k1 vector: key 1, SHA-256("Satoshi Nakamoto").
+	digest := sha256.Sum256([]byte("Satoshi Nakamoto"))
+	sig, err := signDigest(testKey(), digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(sig[:64]); got != "934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d8"+
+		"2442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5" {
+		t.Errorf("r‖s = %s", got)
+	}
+	if sig[64] != 27 && sig[64] != 28 {
+		t.Errorf("v = %d", sig[64])
+	}
+	if _, err := signDigest(make([]byte, 32), digest); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("zero key: %v", err)
+	}
+}
+
+func TestEIP712SpecExample(t *testing.T) {
+	// The Ether Mail example from the EIP-712 specification.
+	d := eip712.Domain{Name: "Ether Mail", Version: "1", ChainID: 1, VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"}
+	sep, err := d.Separator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(sep[:]); got != "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f" {
+		t.Errorf("domain separator = %s", got)
+	}
+
+	var digest [32]byte
+	copy(digest[:], mustHex(t, "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"))
+	key := eip712.Keccak256([]byte("cow"))
+	sig, err := signDigest(key[:], digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562" + "1c"
+	if got := hex.EncodeToString(sig); got != want {
+		t.Errorf("signature = %s", got)
+	}
+}
+
+func TestOrderDigest(t *testing.T) {
+	o := &signerv1.PolymarketOrder{
+		Salt:        "479249096354",
+		Maker:       testMaker,
+		TokenId:     "71321045679252212594626385532706912750332728571942532289631379312455583992563",
+		MakerAmount: "100000000",
+		TakerAmount: "50000000",
+		Side:        signerv1.OrderSide_ORDER_SIDE_BUY,
+	}
+	digest, err := OrderDigest(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clone := func() *signerv1.PolymarketOrder { return proto.Clone(o).(*signerv1.PolymarketOrder) }
+
+	// Signer defaults to the maker and taker to the zero address.
+	explicit := clone()
+	explicit.Signer = testMaker
+	explicit.Taker = "0x0000000000000000000000000000000000000000"
+	if d, _ := OrderDigest(explicit); d != digest {
+		t.Error("defaults changed the digest")
+	}
+	// The exchange and the order fields are covered by the digest.
+	negRisk := clone()
+	negRisk.NegRisk = true
+	sell := clone()
+	sell.Side = signerv1.OrderSide_ORDER_SIDE_SELL
+	for name, other := range map[string]*signerv1.PolymarketOrder{"neg risk": negRisk, "side": sell} {
+		if d, _ := OrderDigest(other); d == digest {
+			t.Errorf("%s not covered by the digest", name)
+		}
+	}
+
+	for name, bad := range map[string]func(*signerv1.PolymarketOrder){
+		"side":     func(o *signerv1.PolymarketOrder) { o.Side = signerv1.OrderSide_ORDER_SIDE_UNSPECIFIED },
+		"maker":    func(o *signerv1.PolymarketOrder) { o.Maker = "0x1234" },
+		"token_id": func(o *signerv1.PolymarketOrder) { o.TokenId = "yes" },
+		"amount":   func(o *signerv1.PolymarketOrder) { o.TakerAmount = new(big.Int).Lsh(big.NewInt(1), 256).String() },
+		"signer": func(o *signerv1.PolymarketOrder) {
+			o.SignatureType = signerv1.SignatureType_SIGNATURE_TYPE_POLY_GNOSIS_SAFE
+		},
+	} {
+		o := clone()
+		bad(o)
+		if _, err := OrderDigest(o); !errors.Is(err, ErrInvalidOrder) {
+			t.Errorf("%s: expected ErrInvalidOrder, got %v", name, err)
+		}
+	}
+}
+
+func TestNetworkDomains(t *testing.T) {
+	if n, err := NetworkFor(137); err != nil || n != Polygon {
+		t.Errorf("chain 137 = %+v, %v", n, err)
+	}
+	if _, err := NetworkFor(1); !errors.Is(err, ErrUnknownChain) {
+		t.Errorf("expected ErrUnknownChain, got %v", err)
+	}
+
+	o := &signerv1.PolymarketOrder{
+		Salt:        "1",
+		Maker:       testMaker,
+		TokenId:     "1",
+		MakerAmount: "100",
+		TakerAmount: "50",
+		Side:        signerv1.OrderSide_ORDER_SIDE_BUY,
+	}
+	mainnet, _ := OrderDigest(o)
+	if d, _ := Polygon.OrderDigest(o); d != mainnet {
+		t.Error("OrderDigest should sign for Polygon")
+	}
+	amoy, err := Amoy.OrderDigest(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if amoy == mainnet {
+		t.Error("an Amoy order must not be valid on Polygon")
+	}
+	o.NegRisk = true
+	if Amoy.OrderDomain(o) != AmoyNegRiskCTFExchange {
+		t.Error("neg-risk orders use the neg-risk exchange")
+	}
+}
+
+func TestKeyAddress(t *testing.T) {
+	two := make([]byte, 32)
+	two[31] = 2
+	if got, err := keyAddress(testKey()); err != nil || got != testMaker {
+		t.Errorf("key 1 address = %s, %v", got, err)
+	}
+	if got, _ := keyAddress(two); got != "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF" {
+		t.Errorf("key 2 address = %s", got)
+	}
+	n := secp256k1.Params().N.Bytes()
+	if _, err := keyAddress(n); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("key n: %v", err)
+	}
+}
+
+func TestPrepareOrder(t *testing.T) {
+	const safe = "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"
+	eoa := signerv1.SignatureType_SIGNATURE_TYPE_EOA
+	gnosis := signerv1.SignatureType_SIGNATURE_TYPE_POLY_GNOSIS_SAFE
+	wallet1271 := signerv1.SignatureType_SIGNATURE_TYPE_POLY_1271
+
+	for name, tc := range map[string]struct {
+		order         *signerv1.PolymarketOrder
+		funder        string
+		maker, signer string
+	}{
+		"eoa default":      {&signerv1.PolymarketOrder{}, "", testMaker, ""},
+		"eoa explicit":     {&signerv1.PolymarketOrder{Maker: testMaker, Signer: testMaker, SignatureType: eoa}, "", testMaker, testMaker},
+		"safe funder":      {&signerv1.PolymarketOrder{SignatureType: gnosis}, safe, safe, testMaker},
+		"safe maker":       {&signerv1.PolymarketOrder{Maker: safe, SignatureType: gnosis}, "", safe, testMaker},
+		"safe both agreed": {&signerv1.PolymarketOrder{Maker: safe, SignatureType: gnosis}, safe, safe, testMaker},
+		"1271 funder":      {&signerv1.PolymarketOrder{SignatureType: wallet1271}, safe, safe, ""},
+	} {
+		got, err := prepareOrder(tc.order, tc.funder, testMaker)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if got.Maker != tc.maker || got.Signer != tc.signer {
+			t.Errorf("%s: maker %q signer %q, want %q %q", name, got.Maker, got.Signer, tc.maker, tc.signer)
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		order  *signerv1.PolymarketOrder
+		funder string
+	}{
+		"eoa funder":       {&signerv1.PolymarketOrder{SignatureType: eoa}, safe},
+		"eoa other signer": {&signerv1.PolymarketOrder{Maker: testMaker, Signer: safe}, ""},
+		"safe no funder":   {&signerv1.PolymarketOrder{SignatureType: gnosis}, ""},
+		"safe self funded": {&signerv1.PolymarketOrder{SignatureType: gnosis}, testMaker},
+		"funder conflict":  {&signerv1.PolymarketOrder{Maker: testMaker, SignatureType: gnosis}, safe},
+		"1271 no funder":   {&signerv1.PolymarketOrder{SignatureType: wallet1271}, ""},
+		"1271 key signer":  {&signerv1.PolymarketOrder{Signer: testMaker, SignatureType: wallet1271}, safe},
+	} {
+		if _, err := prepareOrder(tc.order, tc.funder, testMaker); !errors.Is(err, ErrInvalidOrder) {
+			t.Errorf("%s: expected ErrInvalidOrder, got %v", name, err)
+		}
+	}
+}
+
+// TestOrderGoldenVectors checks the exchange's hand-rolled Order encoding
+// against the order vectors of the shared EIP-712 corpus.
+func TestOrderGoldenVectors(t *testing.T) {
+	data, err := os.ReadFile("../eip712/testdata/vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []struct {
+		Name      string `json:"name"`
+		TypedData struct {
+			PrimaryType string          `json:"primaryType"`
+			Domain      map[string]any  `json:"domain"`
+			Message     json.RawMessage `json:"message"`
+		} `json:"typedData"`
+		StructHash string `json:"structHash"`
+		Digest     string `json:"digest"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	uint64Of := func(s string) uint64 {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	checked := 0
+	for _, v := range vectors {
+		if v.TypedData.PrimaryType != "Order" {
+			continue
+		}
+		var m map[string]string
+		if err := json.Unmarshal(v.TypedData.Message, &m); err != nil {
+			t.Fatalf("%s: %v", v.Name, err)
+		}
+		network, err := NetworkFor(uint64(v.TypedData.Domain["chainId"].(float64)))
+		if err != nil {
+			t.Fatalf("%s: %v", v.Name, err)
+		}
+		o := &signerv1.PolymarketOrder{
+			Salt:          m["salt"],
+			Maker:         m["maker"],
+			Signer:        m["signer"],
+			Taker:         m["taker"],
+			TokenId:       m["tokenId"],
+			MakerAmount:   m["makerAmount"],
+			TakerAmount:   m["takerAmount"],
+			Expiration:    uint64Of(m["expiration"]),
+			Nonce:         uint64Of(m["nonce"]),
+			FeeRateBps:    uint32(uint64Of(m["feeRateBps"])),
+			Side:          signerv1.OrderSide(uint64Of(m["side"]) + 1),
+			SignatureType: signerv1.SignatureType(uint64Of(m["signatureType"]) + 1),
+			NegRisk:       v.TypedData.Domain["verifyingContract"] == network.NegRiskExchange.VerifyingContract,
+		}
+		if h, err := OrderStructHash(o); err != nil || "0x"+hex.EncodeToString(h[:]) != v.StructHash {
+			t.Errorf("%s: struct hash = %x, %v", v.Name, h, err)
+		}
+		if d, err := network.OrderDigest(o); err != nil || "0x"+hex.EncodeToString(d[:]) != v.Digest {
+			t.Errorf("%s: digest = %x, %v", v.Name, d, err)
+		}
+		checked++
+	}
+	if checked == 0 {
+		t.Fatal("no order vectors in the corpus")
+	}
+}
@@ -0,0 +1,336 @@
+package signer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrElevationDisabled = errors.New("elevation is not configured")
+	ErrElevationDenied   = errors.New("elevation credential rejected")
+	ErrElevationLocked   = errors.New("too many failed elevation attempts")
+	ErrInvalidElevation  = errors.New("invalid elevation request")
+	ErrNoElevation       = errors.New("no active elevation")
+)
+
+// Elevation scopes. A policy rule is relaxed with "policy:<rule-id>".
+const (
+	ScopeApproval     = "approval"
+	ScopePolicyPrefix = "policy:"
+)
+
+// Failed credentials lock elevation out for elevationLockout after
+// elevationMaxFailures consecutive attempts.
+const (
+	elevationMaxFailures = 3
+	elevationLockout     = time.Minute
+)
+
+// ElevationEventKind is what happened to an elevation.
+type ElevationEventKind string
+
+const (
+	ElevationGranted ElevationEventKind = "granted"
+	ElevationDenied  ElevationEventKind = "denied"
+	ElevationUsed    ElevationEventKind = "used"
+	ElevationEnded   ElevationEventKind = "ended"
+	ElevationExpired ElevationEventKind = "expired"
+
+	// ElevationReauthenticated records a credential accepted by
+	// Reauthenticate; Scopes names the action it was for.
+	ElevationReauthenticated ElevationEventKind = "reauthenticated"
+)
+
+// ElevationEvent is an audit record of the elevation lifecycle. Every
+// grant, refused attempt, order that needed the relaxation, and revert is
+// reported.
+type ElevationEvent struct {
+	Kind      ElevationEventKind
+	ID        string
+	Client    string
+	Scopes    []string
+	Reason    string
+	ExpiresAt time.Time
+	RequestID string // for ElevationUsed
+	Detail    string
+}
+
+// Elevation is a time-boxed relaxation of specific checks.
+type Elevation struct {
+	ID        string
+	Client    string
+	Scopes    []string
+	Reason    string
+	GrantedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Elevator grants "sudo mode": after the operator re-authenticates with
+// the elevation credential, the listed scopes are relaxed until the
+// elevation expires or is ended. Only one elevation is active at a time.
+type Elevator struct {
+	hash    []byte
+	max     time.Duration
+	clock   clock.Clock
+	onEvent func(ElevationEvent)
+
+	mu          sync.Mutex
+	active      *Elevation
+	failures    int
+	lockedUntil time.Time
+}
+
+// NewElevator creates an Elevator that accepts credentials matching the
+// bcrypt hash and grants elevations of at most maxDuration. onEvent may be
+// nil; a nil clk uses the real clock.
+func NewElevator(credentialHash []byte, maxDuration time.Duration, clk clock.Clock, onEvent func(ElevationEvent)) (*Elevator, error) {
+	if _, err := bcrypt.Cost(credentialHash); err != nil {
+		return nil, fmt.Errorf("elevation credential hash: %w", err)
+	}
+	if maxDuration <= 0 {
+		return nil, fmt.Errorf("%w: max duration must be positive", ErrInvalidElevation)
+	}
+	if onEvent == nil {
+		onEvent = func(ElevationEvent) {}
+	}
+	return &Elevator{hash: credentialHash, max: maxDuration, clock: clock.Or(clk), onEvent: onEvent}, nil
+}
+
+// Elevate verifies credential and, if it matches, relaxes scopes for d,
+// replacing any active elevation.
+func (e *Elevator) Elevate(client, credential string, scopes []string, d time.Duration, reason string) (Elevation, error) {
+	if err := validateScopes(scopes); err != nil {
+		return Elevation{}, err
+	}
+	if d <= 0 || d > e.max {
+		return Elevation{}, fmt.Errorf("%w: duration must be in (0, %s]", ErrInvalidElevation, e.max)
+	}
+	if strings.TrimSpace(reason) == "" {
+		return Elevation{}, fmt.Errorf("%w: reason is required", ErrInvalidElevation)
+	}
+
+	e.mu.Lock()
+	now := e.clock.Now()
+	if err := e.authenticateLocked(credential, now); err != nil {
+		e.mu.Unlock()
+		e.onEvent(ElevationEvent{Kind: ElevationDenied, Client: client, Scopes: scopes, Reason: reason, Detail: deniedDetail(err)})
+		return Elevation{}, err
+	}
+
+	b := make([]byte, 8)
+	rand.Read(b)
+	el := Elevation{
+		ID:        hex.EncodeToString(b),
+		Client:    client,
+		Scopes:    append([]string(nil), scopes...),
+		Reason:    reason,
+		GrantedAt: now,
+		ExpiresAt: now.Add(d),
+	}
+	replaced := e.active
+	e.active = &el
+	e.mu.Unlock()
+
+	if replaced != nil {
+		e.onEvent(endEvent(ElevationEnded, replaced, "replaced by "+el.ID))
+	}
+	e.onEvent(ElevationEvent{Kind: ElevationGranted, ID: el.ID, Client: client, Scopes: el.Scopes, Reason: reason, ExpiresAt: el.ExpiresAt})
+	return el, nil
+}
+
+// Reauthenticate verifies credential for an action other than elevation,
+// such as renewing the session, sharing the failure lockout with Elevate.
+// The attempt is audited under action.
+func (e *Elevator) Reauthenticate(client, credential, action, reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("%w: reason is required", ErrInvalidElevation)
+	}
+	e.mu.Lock()
+	err := e.authenticateLocked(credential, e.clock.Now())
+	e.mu.Unlock()
+
+	ev := ElevationEvent{Kind: ElevationReauthenticated, Client: client, Scopes: []string{action}, Reason: reason}
+	if err != nil {
+		ev.Kind, ev.Detail = ElevationDenied, deniedDetail(err)
+	}
+	e.onEvent(ev)
+	return err
+}
+
+// authenticateLocked checks credential against the hash, locking out
+// further attempts after elevationMaxFailures in a row. Caller must hold
+// e.mu.
+func (e *Elevator) authenticateLocked(credential string, now time.Time) error {
+	if now.Before(e.lockedUntil) {
+		return ErrElevationLocked
+	}
+	if bcrypt.CompareHashAndPassword(e.hash, []byte(credential)) != nil {
+		e.failures++
+		if e.failures >= elevationMaxFailures {
+			e.failures = 0
+			e.lockedUntil = now.Add(elevationLockout)
+		}
+		return ErrElevationDenied
+	}
+	e.failures = 0
+	return nil
+}
+
+func deniedDetail(err error) string {
+	if errors.Is(err, ErrElevationLocked) {
+		return "locked out"
+	}
+	return "bad credential"
+}
+
+// End reverts the active elevation early.
+func (e *Elevator) End(client string) error {
+	e.mu.Lock()
+	el := e.expireLocked()
+	ended := e.active
+	e.active = nil
+	e.mu.Unlock()
+
+	if el != nil {
+		e.onEvent(*el)
+	}
+	if ended == nil {
+		return ErrNoElevation
+	}
+	e.onEvent(endEvent(ElevationEnded, ended, "ended by "+client))
+	return nil
+}
+
+// Scopes returns the currently relaxed scopes, or nil when no elevation is
+// active.
+func (e *Elevator) Scopes() []string {
+	e.mu.Lock()
+	expired := e.expireLocked()
+	var scopes []string
+	if e.active != nil {
+		scopes = e.active.Scopes
+	}
+	e.mu.Unlock()
+
+	if expired != nil {
+		e.onEvent(*expired)
+	}
+	return scopes
+}
+
+// Active returns the active elevation, if any.
+func (e *Elevator) Active() (Elevation, bool) {
+	e.mu.Lock()
+	expired := e.expireLocked()
+	var el Elevation
+	ok := e.active != nil
+	if ok {
+		el = *e.active
+	}
+	e.mu.Unlock()
+
+	if expired != nil {
+		e.onEvent(*expired)
+	}
+	return el, ok
+}
+
+// Run reverts an expired elevation within interval of its expiry, so the
+// expired event is recorded even when no order arrives. It returns when ctx
+// is done.
+func (e *Elevator) Run(ctx context.Context, interval time.Duration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.clock.After(interval):
+			e.Active()
+		}
+	}
+}
+
+// recordUse reports an order that was signed only because scope was
+// relaxed.
+func (e *Elevator) recordUse(scope, client, requestID string) {
+	el, ok := e.Active()
+	if !ok {
+		return
+	}
+	e.onEvent(ElevationEvent{
+		Kind:      ElevationUsed,
+		ID:        el.ID,
+		Client:    client,
+		Scopes:    []string{scope},
+		Reason:    el.Reason,
+		ExpiresAt: el.ExpiresAt,
+		RequestID: requestID,
+	})
+}
+
+// expireLocked clears an elevation past its expiry and returns the event
+// to report once the lock is released.
+func (e *Elevator) expireLocked() *ElevationEvent {
+	if e.active == nil || e.clock.Now().Before(e.active.ExpiresAt) {
+		return nil
+	}
+	ev := endEvent(ElevationExpired, e.active, "")
+	e.active = nil
+	return &ev
+}
+
+func endEvent(kind ElevationEventKind, el *Elevation, detail string) ElevationEvent {
+	return ElevationEvent{Kind: kind, ID: el.ID, Client: el.Client, Scopes: el.Scopes, Reason: el.Reason, ExpiresAt: el.ExpiresAt, Detail: detail}
+}
+
+func validateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidElevation)
+	}
+	for _, s := range scopes {
+		if s == ScopeApproval {
+			continue
+		}
+		if rule, ok := strings.CutPrefix(s, ScopePolicyPrefix); ok && rule != "" {
+			continue
+		}
+		return fmt.Errorf("%w: unknown scope %q", ErrInvalidElevation, s)
+	}
+	return nil
+}
+
+// waivedRules returns the policy rule IDs relaxed by scopes.
+func waivedRules(scopes []string) []string {
+	var rules []string
+	for _, s := range scopes {
+		if rule, ok := strings.CutPrefix(s, ScopePolicyPrefix); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func elevationStatus(err error) error {
+	switch {
+	case errors.Is(err, ErrElevationDenied):
+		return status.Errorf(codes.Unauthenticated, "%v", err)
+	case errors.Is(err, ErrElevationLocked):
+		return status.Errorf(codes.ResourceExhausted, "%v", err)
+	case errors.Is(err, ErrInvalidElevation):
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	case errors.Is(err, ErrNoElevation):
+		return status.Errorf(codes.FailedPrecondition, "%v", err)
+	default:
+		return status.Errorf(codes.Internal, "elevation: %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/policy"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func testElevator(t *testing.T, clk clock.Clock, onEvent func(ElevationEvent)) *Elevator {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("open sesame"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewElevator(hash, 15*time.Minute, clk, onEvent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestElevatorGrantExpireAndLockout(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	var events []ElevationEventKind
+	e := testElevator(t, clk, func(ev ElevationEvent) { events = append(events, ev.Kind) })
+
+	if _, err := e.Elevate("uid:1", "open sesame", []string{"policy:"}, time.Minute, "x"); !errors.Is(err, ErrInvalidElevation) {
+		t.Errorf("empty rule scope: expected ErrInvalidElevation, got %v", err)
+	}
+	if _, err := e.Elevate("uid:1", "open sesame", []string{ScopeApproval}, time.Hour, "x"); !errors.Is(err, ErrInvalidElevation) {
+		t.Errorf("duration above max: expected ErrInvalidElevation, got %v", err)
+	}
+
+	el, err := e.Elevate("uid:1", "open sesame", []string{ScopeApproval}, 10*time.Minute, "one-off block trade")
+	if err != nil {
+		t.Fatalf("elevate: %v", err)
+	}
+	if !el.ExpiresAt.Equal(clk.Now().Add(10 * time.Minute)) {
+		t.Errorf("unexpected expiry %v", el.ExpiresAt)
+	}
+	if got := e.Scopes(); len(got) != 1 || got[0] != ScopeApproval {
+		t.Errorf("unexpected scopes %v", got)
+	}
+	clk.Advance(10 * time.Minute)
+	if got := e.Scopes(); got != nil {
+		t.Errorf("elevation should have reverted, got %v", got)
+	}
+	if err := e.End("uid:1"); !errors.Is(err, ErrNoElevation) {
+		t.Errorf("expected ErrNoElevation, got %v", err)
+	}
+
+	for i := 0; i < elevationMaxFailures; i++ {
+		if _, err := e.Elevate("uid:1", "guess", []string{ScopeApproval}, time.Minute, "x"); !errors.Is(err, ErrElevationDenied) {
+			t.Fatalf("attempt %d: expected ErrElevationDenied, got %v", i, err)
+		}
+	}
+	if _, err := e.Elevate("uid:1", "open sesame", []string{ScopeApproval}, time.Minute, "x"); !errors.Is(err, ErrElevationLocked) {
+		t.Errorf("expected lockout, got %v", err)
+	}
+	clk.Advance(elevationLockout)
+	if _, err := e.Elevate("uid:1", "open sesame", []string{ScopeApproval}, time.Minute, "x"); err != nil {
+		t.Errorf("lockout should lift: %v", err)
+	}
+
+	want := []ElevationEventKind{ElevationGranted, ElevationExpired, ElevationDenied, ElevationDenied, ElevationDenied, ElevationDenied, ElevationGranted}
+	if len(events) != len(want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d = %s, want %s", i, events[i], want[i])
+		}
+	}
+}
+
+func TestSignOrderElevationRelaxesPolicyAndApproval(t *testing.T) {
+	sm := NewSessionManager(time.Hour)
+	sm.SetLimitProfiles([]LimitProfile{{Name: "always", End: 24 * time.Hour, LimitPct: 100, ApprovalAbove: big.NewInt(150_000_000)}})
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1_000_000_000)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	t.Cleanup(sm.Destroy)
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	src := "version: 1\nrules:\n  - id: max-notional\n    type: limit\n    field: order.value\n    max: 100\n"
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	engine, err := policy.NewEngine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var used []ElevationEvent
+	e := testElevator(t, nil, func(ev ElevationEvent) {
+		if ev.Kind == ElevationUsed {
+			used = append(used, ev)
+		}
+	})
+	h := NewHandler(sm, WithPolicy(engine, nil), WithElevation(e))
+
+	sign := func(maker string) error {
+		_, err := h.SignOrder(context.Background(), &signerv1.SignOrderRequest{
+			Order: &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: maker, TakerAmount: maker},
+		})
+		return err
+	}
+
+	if err := sign("200000000"); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("before elevation: expected FailedPrecondition, got %v", err)
+	}
+
+	_, err = h.ElevateSession(context.Background(), &signerv1.ElevateSessionRequest{
+		Credential: "wrong", Scopes: []string{"policy:max-notional"}, DurationSeconds: 60, Reason: "block trade",
+	})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("bad credential: expected Unauthenticated, got %v", err)
+	}
+	if _, err := h.ElevateSession(context.Background(), &signerv1.ElevateSessionRequest{
+		Credential: "open sesame", Scopes: []string{"policy:max-notional"}, DurationSeconds: 60, Reason: "block trade",
+	}); err != nil {
+		t.Fatalf("elevate: %v", err)
+	}
+
+	// The policy is relaxed but the approval threshold still applies.
+	if err := sign("200000000"); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("approval scope not elevated: expected FailedPrecondition, got %v", err)
+	}
+	if err := sign("120000000"); err != nil {
+		t.Errorf("relaxed policy: unexpected error %v", err)
+	}
+	if err := sign("50000000"); err != nil {
+		t.Errorf("passing order: unexpected error %v", err)
+	}
+
+	if _, err := h.ElevateSession(context.Background(), &signerv1.ElevateSessionRequest{
+		Credential: "open sesame", Scopes: []string{"policy:max-notional", ScopeApproval}, DurationSeconds: 60, Reason: "block trade",
+	}); err != nil {
+		t.Fatalf("elevate: %v", err)
+	}
+	if err := sign("200000000"); err != nil {
+		t.Errorf("fully elevated: unexpected error %v", err)
+	}
+
+	if _, err := h.EndElevation(context.Background(), &signerv1.EndElevationRequest{}); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	if err := sign("120000000"); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("after end: expected FailedPrecondition, got %v", err)
+	}
+
+	// Only orders that needed a relaxed scope are recorded as uses.
+	if len(used) != 3 {
+		t.Fatalf("expected 3 recorded uses, got %+v", used)
+	}
+	if used[0].Scopes[0] != "policy:max-notional" || used[2].Scopes[0] != ScopeApproval {
+		t.Errorf("unexpected uses %+v", used)
+	}
+}
+
+func TestRenewSession(t *testing.T) {
+	sm := activeSession(t, 1_000_000)
+	if _, err := NewHandler(sm).RenewSession(context.Background(), &signerv1.RenewSessionRequest{
+		Credential: "open sesame", DurationSeconds: 60, Reason: "long day",
+	}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("without elevation: expected FailedPrecondition, got %v", err)
+	}
+
+	var events []ElevationEvent
+	e := testElevator(t, nil, func(ev ElevationEvent) { events = append(events, ev) })
+	h := NewHandler(sm, WithElevation(e))
+	renew := func(credential string, seconds int64, reason string) (*signerv1.RenewSessionResponse, error) {
+		return h.RenewSession(context.Background(), &signerv1.RenewSessionRequest{Credential: credential, DurationSeconds: seconds, Reason: reason})
+	}
+
+	if _, err := renew("open sesame", 60, ""); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("no reason: expected InvalidArgument, got %v", err)
+	}
+	if _, err := renew("open sesame", 2*3600, "long day"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("beyond the TTL: expected InvalidArgument, got %v", err)
+	}
+	if _, err := renew("guess", 3600, "long day"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("bad credential: expected Unauthenticated, got %v", err)
+	}
+	resp, err := renew("open sesame", 3600, "long day")
+	if err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	if resp.TtlRemainingSeconds < 3599 || resp.TtlRemainingSeconds > 3600 {
+		t.Errorf("ttl remaining %d, want about 3600", resp.TtlRemainingSeconds)
+	}
+
+	// Renewal failures count towards the elevation lockout.
+	for i := 0; i < elevationMaxFailures; i++ {
+		renew("guess", 3600, "long day")
+	}
+	if _, err := e.Elevate("uid:1", "open sesame", []string{ScopeApproval}, time.Minute, "x"); !errors.Is(err, ErrElevationLocked) {
+		t.Errorf("expected ErrElevationLocked, got %v", err)
+	}
+	if _, err := renew("open sesame", 3600, "long day"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("locked out: expected ResourceExhausted, got %v", err)
+	}
+
+	var kinds []ElevationEventKind
+	for _, ev := range events {
+		kinds = append(kinds, ev.Kind)
+	}
+	want := []ElevationEventKind{ElevationReauthenticated, ElevationDenied, ElevationReauthenticated, ElevationDenied, ElevationDenied, ElevationDenied, ElevationDenied, ElevationDenied}
+	if len(kinds) != len(want) {
+		t.Fatalf("events %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("events %v, want %v", kinds, want)
+		}
+	}
+	if ev := events[2]; ev.Scopes[0] != "renew-session" || ev.Reason != "long day" {
+		t.Errorf("unexpected renewal event %+v", ev)
+	}
+}
@@ -2,68 +2,891 @@ package signer
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"math/big"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/caesar-terminal/caesar/internal/eip712"
 	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/policy"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 // Handler implements the SignerServiceServer interface.
 type Handler struct {
 	signerv1.UnimplementedSignerServiceServer
-	session *SessionManager
+	session   *SessionManager
+	detector  *Detector
+	canaries  canarySet
+	onCanary  func(CanaryTrip)
+	quotas    *QuotaTracker
+	gate      *admissionGate
+	onLimit   func(LimitBreach)
+	onRelease func(ValueRelease)
+	recovery  *Recovery
+	pauses    *MarketPauses
+	onPause   func(SessionPauseEvent)
+	onTighten func(SessionPolicyEvent)
+	totp      *TOTP
+	onAck     func(RecoveryAck)
+	policy    *policy.Engine
+	markets   policy.Markets
+	books     BookSource
+	onPolicy  func(PolicyRejection)
+
+	approvals  *ApprovalInbox
+	onApproval func(Approval)
+
+	elevator  *Elevator
+	analytics *SessionAnalytics
+	onAudit   func(AuditRecord)
+	wallets   WalletVerifier
+
+	typedSchemas []TypedDataSchema
+	network      Network
+	salts        SaltStrategy
+	replays      *ReplayGuard
+	permits      bool
+	releases     bool
+	limits       RequestLimits // applied by the Server's interceptor
+	clientTokens ClientTokens  // likewise
+
+	id       string                     // the session's ID; empty for the default
+	named    map[string]*SessionManager // set by WithSessions
+	sessions map[string]*SessionManager // every session, by ID, once served
+}
+
+// LimitBreach describes an order refused by the session value limit.
+type LimitBreach struct {
+	Client    string
+	Value     *big.Int
+	RequestID string
+}
+
+// Option configures optional Handler dependencies.
+type Option func(*Handler)
+
+// WithDetector enables anomaly detection on SignOrder traffic.
+func WithDetector(d *Detector) Option {
+	return func(h *Handler) {
+		h.detector = d
+	}
+}
+
+// WithCanaries configures honeytoken token IDs. Any SignOrder for one of
+// them destroys the active session (kill switch) and calls onTrip.
+func WithCanaries(tokens []string, onTrip func(CanaryTrip)) Option {
+	return func(h *Handler) {
+		h.canaries = newCanarySet(tokens)
+		h.onCanary = onTrip
+	}
+}
+
+// WithQuotas enables per-client order-count and notional caps.
+func WithQuotas(q *QuotaTracker) Option {
+	return func(h *Handler) {
+		h.quotas = q
+	}
+}
+
+// WithLimitAlert calls onExceeded whenever an order is refused because it
+// would exceed the session's cumulative value limit.
+func WithLimitAlert(onExceeded func(LimitBreach)) Option {
+	return func(h *Handler) {
+		h.onLimit = onExceeded
+	}
+}
+
+// WithPolicy evaluates the engine's checks against every order before it
+// is signed. markets supplies the market.* fields; it may be nil.
+func WithPolicy(e *policy.Engine, markets policy.Markets) Option {
+	return func(h *Handler) {
+		h.policy = e
+		h.markets = markets
+	}
+}
+
+// WithBooks exposes the latest book per token to policy checks as book.*
+// fields, so rules can compare orders against the market and refuse to
+// trust a book older than their max_book_age.
+func WithBooks(books BookSource) Option {
+	return func(h *Handler) {
+		h.books = books
+	}
+}
+
+// WithPolicyAudit calls onReject for every order a policy rule refuses,
+// with the same explanation returned to the caller, and for every shadow
+// rule an order fails, with Shadow set.
+func WithPolicyAudit(onReject func(PolicyRejection)) Option {
+	return func(h *Handler) {
+		h.onPolicy = onReject
+	}
+}
+
+// WithApprovals holds orders above a limit profile's approval threshold
+// in inbox instead of refusing them outright, and calls onRequest for each
+// new approval so operators can be alerted.
+func WithApprovals(inbox *ApprovalInbox, onRequest func(Approval)) Option {
+	return func(h *Handler) {
+		h.approvals = inbox
+		h.onApproval = onRequest
+	}
+}
+
+// WithElevation enables ElevateSession: while an elevation is active,
+// the policy rules and approval threshold it relaxes are skipped.
+func WithElevation(e *Elevator) Option {
+	return func(h *Handler) {
+		h.elevator = e
+	}
+}
+
+// WithAnalytics tallies every SignOrder outcome for GetSessionAnalytics.
+func WithAnalytics(a *SessionAnalytics) Option {
+	return func(h *Handler) {
+		h.analytics = a
+	}
+}
+
+// WithNetwork signs orders for n's exchanges. Defaults to Polygon.
+func WithNetwork(n Network) Option {
+	return func(h *Handler) {
+		h.network = n
+	}
+}
+
+// WithSalts fills in the salt of orders submitted without one.
+func WithSalts(s SaltStrategy) Option {
+	return func(h *Handler) {
+		h.salts = s
+	}
+}
+
+// WithReplayGuard refuses to sign an order whose token ID and salt were
+// already signed this session.
+func WithReplayGuard(g *ReplayGuard) Option {
+	return func(h *Handler) {
+		h.replays = g
+	}
+}
+
+// WithPermits enables SignPermit. It is separate from every order
+// setting: a permit moves funds without an order ever being placed.
+func WithPermits(allow bool) Option {
+	return func(h *Handler) {
+		h.permits = allow
+	}
 }
 
 // NewHandler creates a Handler wired to the given SessionManager.
-func NewHandler(session *SessionManager) *Handler {
-	return &Handler{session: session}
+func NewHandler(session *SessionManager, opts ...Option) *Handler {
+	h := &Handler{session: session, gate: newAdmissionGate(1, session.Clock()), network: Polygon, limits: DefaultRequestLimits}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // SignOrder signs a Polymarket order using EIP-712 typed data.
 // Delegates to the SessionManager which enforces TTL and value limits.
-func (h *Handler) SignOrder(_ context.Context, req *signerv1.SignOrderRequest) (*signerv1.SignOrderResponse, error) {
+func (h *Handler) SignOrder(ctx context.Context, req *signerv1.SignOrderRequest) (*signerv1.SignOrderResponse, error) {
+	if (h.analytics == nil && h.onAudit == nil) || req.Order == nil {
+		return h.signOrder(ctx, req)
+	}
+	snap := h.snapshot()
+	resp, err := h.signOrder(ctx, req)
+	h.observe(ctx, req, snap, h.session.Clock().Now(), err)
+	return resp, err
+}
+
+// SignOrders signs a batch of orders under one enclave open. Every order
+// is recorded in analytics and the audit log with the batch's outcome.
+func (h *Handler) SignOrders(ctx context.Context, req *signerv1.SignOrdersRequest) (*signerv1.SignOrdersResponse, error) {
+	if h.analytics == nil && h.onAudit == nil {
+		return h.signOrders(ctx, req)
+	}
+	snap := h.snapshot()
+	resp, err := h.signOrders(ctx, req)
+	now := h.session.Clock().Now()
+	for _, o := range req.Orders {
+		if o.GetOrder() != nil {
+			h.observe(ctx, o, snap, now, err)
+		}
+	}
+	return resp, err
+}
+
+// sessionSnapshot is the session state a request was signed against.
+type sessionSnapshot struct {
+	epoch       uint64
+	activatedAt time.Time
+	active      bool
+	limit, used string
+	addr        string
+}
+
+func (h *Handler) snapshot() sessionSnapshot {
+	active, _, limit, used, addr := h.session.Status()
+	return sessionSnapshot{
+		epoch:       h.session.Epoch(),
+		activatedAt: h.session.ActivatedAt(),
+		active:      active,
+		limit:       limit,
+		used:        used,
+		addr:        addr,
+	}
+}
+
+// observe feeds one order's outcome to analytics and the audit log.
+func (h *Handler) observe(ctx context.Context, req *signerv1.SignOrderRequest, snap sessionSnapshot, now time.Time, err error) {
+	if h.analytics != nil {
+		if err != nil {
+			h.analytics.RecordRejected(snap.epoch, req.Order.TokenId, rejectionReason(err), now)
+		} else if value, perr := ParseAmount(req.Order.MakerAmount); perr == nil {
+			h.analytics.RecordSigned(snap.epoch, req.Order.TokenId, value.BigInt(), now)
+		}
+	}
+	if h.onAudit != nil {
+		// Audit the order as it was (or would have been) signed, so replay
+		// does not need the request's funder.
+		order := req.Order
+		if prepared, perr := prepareOrder(req.Order, req.Funder, snap.addr); perr == nil {
+			order = prepared
+		}
+		r := newAuditRecord(ctx, order, snap.epoch, snap.activatedAt, snap.active, snap.limit, snap.used, now, err)
+		r.SessionID = h.id
+		h.onAudit(r)
+	}
+}
+
+func (h *Handler) signOrder(ctx context.Context, req *signerv1.SignOrderRequest) (*signerv1.SignOrderResponse, error) {
 	if req.Order == nil {
 		return nil, status.Errorf(codes.InvalidArgument, "order is required")
 	}
 
-	// Parse the maker amount as the order value for limit tracking.
-	orderValue := new(big.Int)
-	if _, ok := orderValue.SetString(req.Order.MakerAmount, 10); !ok {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid maker_amount: %s", req.Order.MakerAmount)
+	// Scopes relaxed by an elevation are only waived once the order has
+	// failed them, so each signed order that needed the elevation is
+	// recorded.
+	_, _, _, _, addr := h.session.Status()
+	client, quota, epoch := ClientID(ctx), quotaClient(ctx), h.session.Epoch()
+	var elevated []string
+	if h.elevator != nil {
+		elevated = h.elevator.Scopes()
+	}
+	checked, err := h.checkOrder(ctx, req, addr, elevated)
+	if err != nil {
+		return nil, err
+	}
+	req = &signerv1.SignOrderRequest{Domain: req.Domain, Order: checked.order, Metadata: req.Metadata}
+	value, orderValue, digest := checked.value, checked.value.BigInt(), checked.digest
+	contractWallet, relied := checked.contractWallet, checked.relied
+
+	// An approved order skips the approval threshold. The approval is
+	// single-use and only covers the identical order.
+	var approvalID, fingerprint string
+	if h.approvals != nil {
+		fingerprint = checked.fingerprint()
+		if approvalID = req.Metadata.GetApprovalId(); approvalID != "" {
+			if err := h.approvals.Claim(approvalID, fingerprint); err != nil {
+				return nil, approvalStatus(err)
+			}
+		}
 	}
 
-	sig, err := h.session.Sign(orderValue)
+	// Per-client quotas partition the shared session between strategies.
+	if h.quotas != nil {
+		if err := h.quotas.Reserve(epoch, quota, orderValue); err != nil {
+			if approvalID != "" {
+				h.approvals.Unclaim(approvalID)
+			}
+			return nil, status.Errorf(codes.ResourceExhausted, "client quota exceeded for %s", quota)
+		}
+	}
+
+	// Queue behind other signing requests by priority; drop the request if
+	// its not_after passes while waiting.
+	var priority int32
+	var notAfter time.Time
+	if m := req.Metadata; m != nil {
+		priority = m.Priority
+		if m.NotAfter > 0 {
+			notAfter = time.Unix(0, m.NotAfter)
+		}
+	}
+	if err := h.gate.Acquire(ctx, priority, notAfter); err != nil {
+		if approvalID != "" {
+			h.approvals.Unclaim(approvalID)
+		}
+		if h.quotas != nil {
+			h.quotas.Release(epoch, quota, orderValue)
+		}
+		if errors.Is(err, ErrRequestStale) {
+			return nil, status.Errorf(codes.DeadlineExceeded, "request not_after elapsed before signing")
+		}
+		return nil, status.FromContextError(err).Err()
+	}
+	if h.replays != nil {
+		if err := h.replays.Claim(epoch, req.Order); err != nil {
+			h.gate.Release()
+			if approvalID != "" {
+				h.approvals.Unclaim(approvalID)
+			}
+			if h.quotas != nil {
+				h.quotas.Release(epoch, quota, orderValue)
+			}
+			return nil, status.Errorf(codes.AlreadyExists, "%v", err)
+		}
+	}
+	var sig []byte
+	if approvalID != "" {
+		sig, err = h.session.SignApproved(ctx, digest, orderValue)
+	} else {
+		sig, err = h.session.Sign(ctx, digest, orderValue)
+		if errors.Is(err, ErrApprovalRequired) && slices.Contains(elevated, ScopeApproval) {
+			sig, err = h.session.SignApproved(ctx, digest, orderValue)
+			relied = append(relied, ScopeApproval)
+		}
+	}
+	h.gate.Release()
 	if err != nil {
+		if approvalID != "" {
+			h.approvals.Unclaim(approvalID)
+		}
+		if h.quotas != nil {
+			h.quotas.Release(epoch, quota, orderValue)
+		}
+		if h.replays != nil {
+			h.replays.Release(epoch, req.Order)
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, status.FromContextError(err).Err()
+		}
+		// The windows, order count and signing rate pace the session
+		// without breaching its value limit, so they raise no alert.
+		if st := orderLimitStatus(err); st != nil {
+			return nil, st
+		}
 		switch err {
 		case ErrNoActiveSession:
 			return nil, status.Errorf(codes.FailedPrecondition, "no active session")
 		case ErrSessionExpired:
 			return nil, status.Errorf(codes.FailedPrecondition, "session expired")
 		case ErrValueLimitExceeded:
+			if h.onLimit != nil {
+				h.onLimit(LimitBreach{Client: client, Value: orderValue, RequestID: RequestID(ctx)})
+			}
 			return nil, status.Errorf(codes.ResourceExhausted, "cumulative value limit exceeded")
+		case ErrOrderValueExceeded:
+			return nil, orderValueExceededStatus(h.session.MaxOrderValue())
+		case ErrApprovalRequired:
+			if h.approvals != nil {
+				return nil, approvalRequiredStatus(h.requestApproval(ctx, req.Order, client, value, fingerprint), h.session.ActiveProfile())
+			}
+			return nil, status.Errorf(codes.FailedPrecondition, "order requires approval under limit profile %q", h.session.ActiveProfile())
+		case ErrHandoffPending:
+			return nil, status.Errorf(codes.FailedPrecondition, "session is being handed off")
+		case ErrSessionPaused:
+			return nil, sessionPausedStatus()
 		default:
 			return nil, status.Errorf(codes.Internal, "signing failed: %v", err)
 		}
 	}
 
-	_, _, _, _, addr := h.session.Status()
+	// The wallet's verdict is only known once the signature exists, so a
+	// rejected signature has already counted against the limits; it is
+	// withheld all the same.
+	if contractWallet {
+		if err := h.wallets.IsValidSignature(ctx, req.Order.Maker, digest, sig); err != nil {
+			if errors.Is(err, ErrWalletRejected) {
+				return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+			}
+			return nil, status.Errorf(codes.Unavailable, "verify signature with wallet %s: %v", req.Order.Maker, err)
+		}
+	}
+
+	for _, scope := range relied {
+		h.elevator.recordUse(scope, client, RequestID(ctx))
+	}
+	h.recordShadowHits(ctx, epoch, checked)
 
 	return &signerv1.SignOrderResponse{
-		Signature:     string(sig),
+		Signature:     "0x" + hex.EncodeToString(sig),
 		SignerAddress: addr,
+		SignedAt:      h.session.Clock().Now().UnixNano(),
+		RequestId:     RequestID(ctx),
+		Order:         req.Order,
 	}, nil
 }
 
+// orderCheck is an order that passed every check that does not spend
+// limit, prepared for signing.
+type orderCheck struct {
+	order          *signerv1.PolymarketOrder
+	value          Amount
+	digest         [32]byte
+	contractWallet bool
+	relied         []string            // elevation scopes the order needed
+	shadowed       []*policy.Violation // shadow rules the order fails, reported once signed
+	saltGenerated  bool
+}
+
+// fingerprint identifies the order an approval covers. A generated salt
+// differs on every attempt, so it is left out.
+func (c *orderCheck) fingerprint() string {
+	if !c.saltGenerated {
+		return orderFingerprint(c.order)
+	}
+	o := proto.Clone(c.order).(*signerv1.PolymarketOrder)
+	o.Salt = ""
+	return orderFingerprint(o)
+}
+
+// checkOrder runs the checks SignOrder and SignOrders share, up to but
+// not including approvals, quotas and the session limit. addr is the
+// session address and elevated the scopes the active elevation relaxes.
+func (h *Handler) checkOrder(ctx context.Context, req *signerv1.SignOrderRequest, addr string, elevated []string) (*orderCheck, error) {
+	// A canary order means the client is compromised or probing: kill
+	// every session before anything else is evaluated.
+	if h.canaries.contains(req.Order.TokenId) {
+		h.destroySessions()
+		if h.onCanary != nil {
+			h.onCanary(CanaryTrip{TokenID: req.Order.TokenId, RequestID: RequestID(ctx)})
+		}
+		return nil, status.Errorf(codes.PermissionDenied, "order rejected; session destroyed")
+	}
+	if h.pauses != nil {
+		if mp, ok := h.pauses.check(req.Order.ConditionId, req.Order.TokenId); ok {
+			return nil, marketPausedStatus(mp)
+		}
+	}
+
+	// Proxy-wallet and Safe orders are made by the funder and signed by
+	// the session key; fill in whichever the client left out.
+	order, err := prepareOrder(req.Order, req.Funder, addr)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	// A missing salt is filled in before the digest, so the signature
+	// covers it and the response carries it back to the client.
+	var saltGenerated bool
+	if order.Salt == "" && h.salts != nil {
+		if order.Salt, err = h.salts(); err != nil {
+			return nil, status.Errorf(codes.Internal, "generate salt: %v", err)
+		}
+		saltGenerated = true
+	}
+
+	// Parse the maker amount as the order value for limit tracking.
+	// Negative or out-of-range values never reach the ledgers.
+	value, err := ParseAmount(order.MakerAmount)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid maker_amount %q: %v", order.MakerAmount, err)
+	}
+
+	// The digest is what the key signs; an order that cannot be encoded
+	// as the exchange's typed data is refused before any checks run.
+	digest, err := h.network.OrderDigest(order)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	// A signature from any key other than the order's signer would be
+	// rejected by the exchange, so catch the mismatch here. A contract
+	// wallet decides for itself, once the signature exists.
+	contractWallet := order.SignatureType == signerv1.SignatureType_SIGNATURE_TYPE_POLY_1271
+	if contractWallet && h.wallets == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "EIP-1271 orders need a Polygon RPC endpoint to verify signatures against the wallet")
+	}
+	if !contractWallet && addr != "" && !strings.EqualFold(orderSigner(order), addr) {
+		return nil, status.Errorf(codes.InvalidArgument, "order signer %s does not match session key %s", orderSigner(order), addr)
+	}
+
+	// Anomaly escalation refuses the order that triggered it as well as
+	// every order after it, until an operator clears the detector.
+	if h.detector != nil {
+		h.detector.Observe(order.TokenId, value.BigInt(), h.session.Clock().Now())
+		if h.detector.Escalated() {
+			return nil, status.Errorf(codes.FailedPrecondition, "approval required: anomalous signing pattern detected")
+		}
+	}
+
+	// User-defined pre-trade checks; a check that cannot be evaluated
+	// rejects the order.
+	var relied []string
+	var shadowed []*policy.Violation
+	if h.policy != nil {
+		now := h.session.Clock().Now()
+		env := orderEnv(order, ClientID(ctx), h.markets, h.books, now)
+		err := h.policy.Evaluate(env, now)
+		var v *policy.Violation
+		if errors.As(err, &v) && slices.Contains(elevated, ScopePolicyPrefix+v.Rule) {
+			if err = h.policy.Evaluate(env, now, waivedRules(elevated)...); err == nil {
+				relied = append(relied, ScopePolicyPrefix+v.Rule)
+			}
+		}
+		if err != nil {
+			if h.onPolicy != nil {
+				h.onPolicy(newPolicyRejection(err, order, ClientID(ctx), RequestID(ctx)))
+			}
+			return nil, policyStatus(err)
+		}
+		// Shadow rules are being trialled: what they would have blocked is
+		// reported once the order is signed, and the order goes through.
+		shadowed = h.policy.Shadowed(env, now)
+	}
+	return &orderCheck{order: order, value: value, digest: digest, contractWallet: contractWallet, relied: relied, shadowed: shadowed, saltGenerated: saltGenerated}, nil
+}
+
+// recordShadowHits reports the shadow rules a signed order failed. Orders
+// refused later, by a quota or the session limit, never reach it, so the
+// counts say what the rules would have blocked among orders that went out.
+func (h *Handler) recordShadowHits(ctx context.Context, epoch uint64, c *orderCheck) {
+	for _, v := range c.shadowed {
+		if h.onPolicy != nil {
+			h.onPolicy(newPolicyRejection(v, c.order, ClientID(ctx), RequestID(ctx)))
+		}
+		if h.analytics != nil {
+			h.analytics.RecordShadowHit(epoch, v.Rule)
+		}
+	}
+}
+
+func (h *Handler) requestApproval(ctx context.Context, o *signerv1.PolymarketOrder, client string, value Amount, fingerprint string) Approval {
+	a, created := h.approvals.Request(Approval{
+		Kind:    ApprovalLargeOrder,
+		Client:  client,
+		Summary: fmt.Sprintf("order of %s USDC units on %s (request %s)", value, o.TokenId, RequestID(ctx)),
+		TokenID: o.TokenId,
+		Value:   value.String(),
+	}, fingerprint)
+	if created && h.onApproval != nil {
+		h.onApproval(a)
+	}
+	return a
+}
+
+// ListPendingApprovals returns approvals awaiting a decision.
+func (h *Handler) ListPendingApprovals(_ context.Context, _ *signerv1.ListPendingApprovalsRequest) (*signerv1.ListPendingApprovalsResponse, error) {
+	if h.approvals == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "approvals are not enabled")
+	}
+	resp := &signerv1.ListPendingApprovalsResponse{}
+	for _, a := range h.approvals.Pending() {
+		resp.Approvals = append(resp.Approvals, approvalProto(a))
+	}
+	return resp, nil
+}
+
+// RespondToApproval approves or denies a pending approval.
+func (h *Handler) RespondToApproval(ctx context.Context, req *signerv1.RespondToApprovalRequest) (*signerv1.RespondToApprovalResponse, error) {
+	if h.approvals == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "approvals are not enabled")
+	}
+	a, err := h.approvals.Respond(req.ApprovalId, ClientID(ctx), req.Approve, req.Note)
+	if err != nil {
+		return nil, approvalStatus(err)
+	}
+	return &signerv1.RespondToApprovalResponse{State: string(a.State)}, nil
+}
+
+// GetSessionAnalytics summarizes activity since the session was activated.
+func (h *Handler) GetSessionAnalytics(_ context.Context, _ *signerv1.GetSessionAnalyticsRequest) (*signerv1.GetSessionAnalyticsResponse, error) {
+	if h.analytics == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "session analytics are not enabled")
+	}
+	return analyticsProto(h.analytics.Report(h.session.Epoch(), h.session.ActivatedAt())), nil
+}
+
+// ElevateSession re-authenticates the caller with the elevation
+// credential and relaxes the requested scopes for a limited time.
+func (h *Handler) ElevateSession(ctx context.Context, req *signerv1.ElevateSessionRequest) (*signerv1.ElevateSessionResponse, error) {
+	if h.elevator == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", ErrElevationDisabled)
+	}
+	el, err := h.elevator.Elevate(ClientID(ctx), req.Credential, req.Scopes, time.Duration(req.DurationSeconds)*time.Second, req.Reason)
+	if err != nil {
+		return nil, elevationStatus(err)
+	}
+	return &signerv1.ElevateSessionResponse{ElevationId: el.ID, ExpiresAt: el.ExpiresAt.UnixNano()}, nil
+}
+
+// EndElevation reverts the active elevation before it expires.
+func (h *Handler) EndElevation(ctx context.Context, _ *signerv1.EndElevationRequest) (*signerv1.EndElevationResponse, error) {
+	if h.elevator == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", ErrElevationDisabled)
+	}
+	if err := h.elevator.End(ClientID(ctx)); err != nil {
+		return nil, elevationStatus(err)
+	}
+	return &signerv1.EndElevationResponse{}, nil
+}
+
+// RenewSession extends the active session after the operator
+// re-authenticates, so a long trading day does not mean handling the raw
+// key again.
+func (h *Handler) RenewSession(ctx context.Context, req *signerv1.RenewSessionRequest) (*signerv1.RenewSessionResponse, error) {
+	if h.elevator == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", ErrElevationDisabled)
+	}
+	if err := h.elevator.Reauthenticate(ClientID(ctx), req.Credential, "renew-session", req.Reason); err != nil {
+		return nil, elevationStatus(err)
+	}
+	expiresAt, err := h.session.Renew(ctx, time.Duration(req.DurationSeconds)*time.Second)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrInvalidRenewal):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	case errors.Is(err, ErrNoActiveSession), errors.Is(err, ErrSessionExpired), errors.Is(err, ErrHandoffPending):
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	default:
+		return nil, status.FromContextError(err).Err()
+	}
+	remaining := expiresAt.Sub(h.session.Clock().Now())
+	return &signerv1.RenewSessionResponse{ExpiresAt: expiresAt.UnixNano(), TtlRemainingSeconds: int64(remaining.Seconds())}, nil
+}
+
+// ComputeOrderHash returns the EIP-712 hashes of an order without
+// signing it. It touches no ledger, so clients can check their own
+// encoding before spending limit; maker and signer are filled in from the
+// funder and the session key as SignOrder would.
+func (h *Handler) ComputeOrderHash(_ context.Context, req *signerv1.ComputeOrderHashRequest) (*signerv1.ComputeOrderHashResponse, error) {
+	if req.Order == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "order is required")
+	}
+	_, _, _, _, addr := h.session.Status()
+	order, err := prepareOrder(req.Order, req.Funder, addr)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	domain := h.network.OrderDomain(order)
+	separator, err := domain.Separator()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "domain separator: %v", err)
+	}
+	structHash, err := OrderStructHash(order)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	digest := eip712.Digest(separator, structHash)
+
+	return &signerv1.ComputeOrderHashResponse{
+		Domain: &signerv1.EIP712Domain{
+			Name:              domain.Name,
+			Version:           domain.Version,
+			ChainId:           int64(domain.ChainID),
+			VerifyingContract: domain.VerifyingContract,
+		},
+		DomainSeparator: "0x" + hex.EncodeToString(separator[:]),
+		StructHash:      "0x" + hex.EncodeToString(structHash[:]),
+		Digest:          "0x" + hex.EncodeToString(digest[:]),
+		Order:           order,
+	}, nil
+}
+
+// SignTypedData signs an EIP-712 payload that matches a whitelisted
+// schema, such as a CLOB API key derivation. It spends no value limit;
+// exchange orders are refused so they cannot sidestep SignOrder's checks.
+func (h *Handler) SignTypedData(ctx context.Context, req *signerv1.SignTypedDataRequest) (*signerv1.SignTypedDataResponse, error) {
+	if len(h.typedSchemas) == 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "typed data signing is disabled: no schemas configured")
+	}
+	td, err := eip712.Parse([]byte(req.TypedData))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	schema, err := matchTypedData(h.typedSchemas, td)
+	if err != nil {
+		if errors.Is(err, ErrTypedDataNotAllowed) {
+			return nil, status.Errorf(codes.PermissionDenied, "%v", err)
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	digest, err := td.Digest()
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	sig, err := h.session.Sign(ctx, digest, new(big.Int))
+	if err != nil {
+		return nil, valuelessSignStatus(err)
+	}
+	_, _, _, _, addr := h.session.Status()
+
+	return &signerv1.SignTypedDataResponse{
+		Signature:     "0x" + hex.EncodeToString(sig),
+		SignerAddress: addr,
+		Digest:        "0x" + hex.EncodeToString(digest[:]),
+		Schema:        schema.Name,
+		RequestId:     RequestID(ctx),
+	}, nil
+}
+
+// valuelessSignStatus maps a failed zero-value Sign, as for payloads
+// other than orders, to a gRPC status.
+func valuelessSignStatus(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	switch err {
+	case ErrNoActiveSession:
+		return status.Errorf(codes.FailedPrecondition, "no active session")
+	case ErrSessionExpired:
+		return status.Errorf(codes.FailedPrecondition, "session expired")
+	case ErrHandoffPending:
+		return status.Errorf(codes.FailedPrecondition, "session is being handed off")
+	case ErrSessionPaused:
+		return sessionPausedStatus()
+	default:
+		return status.Errorf(codes.Internal, "signing failed: %v", err)
+	}
+}
+
+// VerifySignature recovers the address behind a stored order signature,
+// so audit tooling can check signatures without its own keccak and
+// secp256k1. A signature that recovers to no key is reported invalid
+// rather than as an error.
+func (h *Handler) VerifySignature(_ context.Context, req *signerv1.VerifySignatureRequest) (*signerv1.VerifySignatureResponse, error) {
+	if req.Order == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "order is required")
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "signature is not hex: %v", err)
+	}
+	digest, err := h.network.OrderDigest(req.Order)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	expected := req.ExpectedAddress
+	if expected == "" {
+		expected = orderSigner(req.Order)
+	}
+
+	resp := &signerv1.VerifySignatureResponse{Digest: "0x" + hex.EncodeToString(digest[:])}
+	if addr, err := recoverAddress(digest, sig); err == nil {
+		resp.RecoveredAddress = addr
+		resp.Valid = strings.EqualFold(addr, expected)
+	}
+	return resp, nil
+}
+
 // GetSessionStatus returns the current session key status.
 func (h *Handler) GetSessionStatus(_ context.Context, _ *signerv1.GetSessionStatusRequest) (*signerv1.GetSessionStatusResponse, error) {
-	active, ttl, maxLimit, used, addr := h.session.Status()
+	resp := sessionStatus(h.session)
+	resp.RecoveryPending = h.recovery != nil && h.recovery.Pending()
+	return resp, nil
+}
 
-	return &signerv1.GetSessionStatusResponse{
+func sessionStatus(sm *SessionManager) *signerv1.GetSessionStatusResponse {
+	active, ttl, maxLimit, used, addr := sm.Status()
+	resp := &signerv1.GetSessionStatusResponse{
 		Active:         active,
 		TtlSeconds:     ttl,
 		MaxValueLimit:  maxLimit,
 		ValueUsed:      used,
 		SessionAddress: addr,
+		ActiveProfile:  sm.ActiveProfile(),
+		MaxOrderValue:  sm.MaxOrderValue(),
+		ValueWindows:   windowsProto(sm.ValueWindows()),
+	}
+	resp.OrdersSigned, resp.MaxOrders = sm.OrderCount()
+	resp.MaxSignsPerMinute = int32(sm.SignRate())
+	resp.IdleSeconds = int64(sm.IdleRemaining().Seconds())
+	if p, ok := sm.Paused(); ok {
+		resp.Paused, resp.PauseReason, resp.PausedAt = true, p.Reason, p.At.UnixNano()
+	}
+	return resp
+}
+
+func windowsProto(windows []WindowStatus) []*signerv1.ValueWindowStatus {
+	var out []*signerv1.ValueWindowStatus
+	for _, w := range windows {
+		out = append(out, &signerv1.ValueWindowStatus{
+			SpanSeconds: int64(w.Span / time.Second),
+			Limit:       w.Limit,
+			Used:        w.Used,
+			Remaining:   w.Remaining,
+		})
+	}
+	return out
+}
+
+// PrepareImport returns a one-time public key for receiving a session.
+func (h *Handler) PrepareImport(_ context.Context, _ *signerv1.PrepareImportRequest) (*signerv1.PrepareImportResponse, error) {
+	pub, err := h.session.PrepareImport()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "generate import key: %v", err)
+	}
+	return &signerv1.PrepareImportResponse{PublicKey: pub}, nil
+}
+
+// ExportSession re-encrypts the active session to the destination key.
+func (h *Handler) ExportSession(ctx context.Context, req *signerv1.ExportSessionRequest) (*signerv1.ExportSessionResponse, error) {
+	bundle, err := h.session.Export(ctx, req.DestinationPublicKey)
+	if err != nil {
+		return nil, handoffStatus(err)
+	}
+	return &signerv1.ExportSessionResponse{Bundle: bundle}, nil
+}
+
+// ImportSession activates a session from an exported bundle.
+func (h *Handler) ImportSession(ctx context.Context, req *signerv1.ImportSessionRequest) (*signerv1.ImportSessionResponse, error) {
+	if h.totp != nil {
+		if err := h.totp.Verify(ClientID(ctx), "import-session", req.SecondFactor); err != nil {
+			return nil, secondFactorStatus(err)
+		}
+	}
+	id, err := h.session.Import(ctx, req.Bundle)
+	if err != nil {
+		return nil, handoffStatus(err)
+	}
+	_, ttl, maxLimit, used, _ := h.session.Status()
+	return &signerv1.ImportSessionResponse{
+		HandoffId:     id,
+		TtlSeconds:    ttl,
+		MaxValueLimit: maxLimit,
+		ValueUsed:     used,
 	}, nil
 }
+
+// ConfirmExport destroys the source session after a successful import.
+func (h *Handler) ConfirmExport(_ context.Context, req *signerv1.ConfirmExportRequest) (*signerv1.ConfirmExportResponse, error) {
+	if err := h.session.ConfirmExport(req.HandoffId); err != nil {
+		return nil, handoffStatus(err)
+	}
+	return &signerv1.ConfirmExportResponse{}, nil
+}
+
+// AbortExport resumes signing on a session whose export was abandoned.
+func (h *Handler) AbortExport(_ context.Context, _ *signerv1.AbortExportRequest) (*signerv1.AbortExportResponse, error) {
+	if err := h.session.AbortExport(); err != nil {
+		return nil, handoffStatus(err)
+	}
+	return &signerv1.AbortExportResponse{}, nil
+}
+
+// handoffStatus maps session handoff errors to gRPC status codes.
+func handoffStatus(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, ErrNoActiveSession), errors.Is(err, ErrSessionExpired),
+		errors.Is(err, ErrHandoffPending), errors.Is(err, ErrNoHandoff),
+		errors.Is(err, ErrNoImportKey), errors.Is(err, ErrSessionActive):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrSessionPaused):
+		return sessionPausedStatus()
+	case errors.Is(err, ErrHandoffMismatch):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, ErrInvalidBundle), errors.Is(err, ErrInvalidImportKey):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "session handoff: %v", err)
+	}
+}
@@ -0,0 +1,406 @@
+package signer
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/book"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/policy"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func activeSession(t *testing.T, limit int64) *SessionManager {
+	t.Helper()
+	sm := NewSessionManager(time.Hour)
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(limit)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	t.Cleanup(sm.Destroy)
+	return sm
+}
+
+func TestSignOrderCanaryDestroysSession(t *testing.T) {
+	sm := activeSession(t, 1_000_000)
+
+	var tripped []CanaryTrip
+	h := NewHandler(sm, WithCanaries([]string{"canary-1"}, func(c CanaryTrip) {
+		tripped = append(tripped, c)
+	}))
+
+	_, err := h.SignOrder(context.Background(), &signerv1.SignOrderRequest{
+		Order: &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "canary-1", MakerAmount: "1"},
+	})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied, got %v", err)
+	}
+	if len(tripped) != 1 || tripped[0].TokenID != "canary-1" {
+		t.Errorf("expected one canary trip, got %v", tripped)
+	}
+	if active, _, _, _, _ := sm.Status(); active {
+		t.Errorf("expected session destroyed after canary trip")
+	}
+}
+
+func TestSignOrderNonCanarySigns(t *testing.T) {
+	sm := activeSession(t, 1_000_000)
+	h := NewHandler(sm, WithCanaries(ParseCanaryTokens(" canary-1 , ,canary-2"), nil))
+
+	order := &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "100"}
+	resp, err := h.SignOrder(context.Background(), &signerv1.SignOrderRequest{Order: order})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	digest, _ := OrderDigest(order)
+	want, _ := signDigest(testKey(), digest)
+	if resp.Signature != "0x"+hex.EncodeToString(want) {
+		t.Errorf("signature = %s, want the EIP-712 signature of the order", resp.Signature)
+	}
+}
+
+func TestSignOrderPropagatesDeadline(t *testing.T) {
+	sm := activeSession(t, 1_000_000)
+	h := NewHandler(sm)
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	_, err := h.SignOrder(ctx, &signerv1.SignOrderRequest{
+		Order: &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "100"},
+	})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestSignOrderPolicyChecks(t *testing.T) {
+	sm := activeSession(t, 1_000_000_000)
+	path := filepath.Join(t.TempDir(), "checks")
+	if err := os.WriteFile(path, []byte(`max_price = order.price < 0.95 && market.category != "politics"`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	engine, err := policy.NewEngine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const sports, election, unknown = "1", "2", "3"
+	markets := policy.Markets{sports: {"category": "sports"}, election: {"category": "politics"}}
+	h := NewHandler(sm, WithPolicy(engine, markets))
+
+	sign := func(token, maker, taker string) error {
+		_, err := h.SignOrder(context.Background(), &signerv1.SignOrderRequest{
+			Order: &signerv1.PolymarketOrder{
+				Maker:       testMaker,
+				TokenId:     token,
+				Side:        signerv1.OrderSide_ORDER_SIDE_BUY,
+				MakerAmount: maker,
+				TakerAmount: taker,
+			},
+		})
+		return err
+	}
+
+	if err := sign(sports, "50000000", "100000000"); err != nil {
+		t.Errorf("0.50 sports buy: unexpected error %v", err)
+	}
+	if err := sign(sports, "96000000", "100000000"); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("0.96 buy: expected FailedPrecondition, got %v", err)
+	}
+	if err := sign(election, "50000000", "100000000"); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("politics market: expected FailedPrecondition, got %v", err)
+	}
+	if err := sign(unknown, "50000000", "100000000"); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("market without metadata: expected fail-closed, got %v", err)
+	}
+}
+
+func TestSignOrderPolicyRejectionExplained(t *testing.T) {
+	sm := activeSession(t, 1_000_000_000)
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	src := "version: 1\nrules:\n  - id: max-notional\n    type: limit\n    field: order.value\n    max: 100\n"
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	engine, err := policy.NewEngine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var audited []PolicyRejection
+	h := NewHandler(sm, WithPolicy(engine, nil), WithPolicyAudit(func(r PolicyRejection) {
+		audited = append(audited, r)
+	}))
+
+	_, err = h.SignOrder(context.Background(), &signerv1.SignOrderRequest{
+		Order: &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "250000000", TakerAmount: "500000000"},
+	})
+	st := status.Convert(err)
+	if st.Code() != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+	var info *errdetails.ErrorInfo
+	var failure *errdetails.PreconditionFailure
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.PreconditionFailure:
+			failure = d
+		}
+	}
+	if info == nil || info.Reason != PolicyViolationReason || info.Metadata["rule_id"] != "max-notional" ||
+		info.Metadata["threshold"] != "<= 100" || info.Metadata["actual"] != "order.value = 250" {
+		t.Errorf("unexpected ErrorInfo: %v", info)
+	}
+	if failure == nil || len(failure.Violations) != 1 || failure.Violations[0].Subject != "max-notional" {
+		t.Errorf("unexpected PreconditionFailure: %v", failure)
+	}
+	if len(audited) != 1 || audited[0].Rule != "max-notional" || audited[0].Actual != "order.value = 250" {
+		t.Errorf("unexpected audit entries: %+v", audited)
+	}
+}
+
+func TestSignOrderShadowRuleReportsOnly(t *testing.T) {
+	sm := activeSession(t, 1_000_000_000)
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	src := "version: 1\nrules:\n  - id: tighter-notional\n    type: limit\n    field: order.value\n    max: 100\n    shadow: true\n"
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	engine, err := policy.NewEngine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var audited []PolicyRejection
+	h := NewHandler(sm, WithPolicy(engine, nil), WithAnalytics(NewSessionAnalytics(nil)), WithPolicyAudit(func(r PolicyRejection) {
+		audited = append(audited, r)
+	}))
+
+	for _, maker := range []string{"250000000", "50000000", "150000000"} {
+		_, err := h.SignOrder(context.Background(), &signerv1.SignOrderRequest{
+			Order: &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: maker, TakerAmount: "500000000"},
+		})
+		if err != nil {
+			t.Fatalf("shadow rule refused an order: %v", err)
+		}
+	}
+	// An order the session limit refuses was never going out, so it is not
+	// one the shadow rule would have blocked.
+	_, err = h.SignOrder(context.Background(), &signerv1.SignOrderRequest{
+		Order: &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "900000000", TakerAmount: "1000000000"},
+	})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected the session limit to refuse the order, got %v", err)
+	}
+	if len(audited) != 2 || !audited[0].Shadow || audited[0].Rule != "tighter-notional" || audited[0].Actual != "order.value = 250" {
+		t.Errorf("unexpected audit entries: %+v", audited)
+	}
+	resp, err := h.GetSessionAnalytics(context.Background(), &signerv1.GetSessionAnalyticsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.ShadowHits) != 1 || resp.ShadowHits[0].RuleId != "tighter-notional" || resp.ShadowHits[0].Count != 2 {
+		t.Errorf("shadow hits = %+v", resp.ShadowHits)
+	}
+}
+
+func TestSignOrderOverPerOrderCap(t *testing.T) {
+	sm := activeSession(t, 1_000_000_000)
+	if err := sm.SetMaxOrderValue(big.NewInt(100_000_000)); err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(sm)
+
+	_, err := h.SignOrder(context.Background(), &signerv1.SignOrderRequest{
+		Order: &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "250000000", TakerAmount: "500000000"},
+	})
+	st := status.Convert(err)
+	if st.Code() != codes.OutOfRange {
+		t.Fatalf("expected OutOfRange, got %v", err)
+	}
+	var info *errdetails.ErrorInfo
+	for _, d := range st.Details() {
+		if d, ok := d.(*errdetails.ErrorInfo); ok {
+			info = d
+		}
+	}
+	if info == nil || info.Reason != OrderValueExceededReason || info.Metadata["max_order_value"] != "100000000" {
+		t.Errorf("unexpected ErrorInfo: %v", info)
+	}
+	if resp, _ := h.GetSessionStatus(context.Background(), &signerv1.GetSessionStatusRequest{}); resp.ValueUsed != "0" || resp.MaxOrderValue != "100000000" {
+		t.Errorf("status %+v", resp)
+	}
+}
+
+func TestSignOrderStaleBookTagged(t *testing.T) {
+	sm := activeSession(t, 1_000_000_000)
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	src := "version: 1\nmax_book_age: 10\nrules:\n  - id: price-vs-ask\n    type: expr\n    expr: order.price <= book.best_ask\n"
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	engine, err := policy.NewEngine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := sm.Clock().Now()
+	books := book.NewCache(filepath.Join(t.TempDir(), "books.json"), 5, 0, nil)
+	const fresh, old, unknown = "1", "2", "3"
+	books.Update(book.Snapshot{TokenID: fresh, At: now, Asks: []book.Level{{Price: 520_000, Size: 10}}})
+	books.Update(book.Snapshot{TokenID: old, At: now.Add(-time.Minute), Asks: []book.Level{{Price: 520_000, Size: 10}}})
+	var audited []PolicyRejection
+	h := NewHandler(sm, WithPolicy(engine, nil), WithBooks(books), WithPolicyAudit(func(r PolicyRejection) {
+		audited = append(audited, r)
+	}))
+
+	sign := func(token string) error {
+		_, err := h.SignOrder(context.Background(), &signerv1.SignOrderRequest{
+			Order: &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: token, MakerAmount: "50000000", TakerAmount: "100000000"},
+		})
+		return err
+	}
+	if err := sign(fresh); err != nil {
+		t.Fatalf("fresh book: %v", err)
+	}
+	for _, token := range []string{old, unknown} {
+		err := sign(token)
+		if got := rejectionReason(err); got != StaleDataReason {
+			t.Errorf("%s: reason = %s (%v)", token, got, err)
+		}
+	}
+	if len(audited) != 2 || !audited[0].Stale || audited[0].Rule != "price-vs-ask" {
+		t.Errorf("unexpected audit entries: %+v", audited)
+	}
+}
+
+func TestSignOrderRejectsForeignSigner(t *testing.T) {
+	h := NewHandler(activeSession(t, 1_000_000))
+
+	_, err := h.SignOrder(context.Background(), &signerv1.SignOrderRequest{
+		Order: &signerv1.PolymarketOrder{Maker: "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF", Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "100"},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestComputeOrderHashMatchesSignOrder(t *testing.T) {
+	sm := activeSession(t, 1_000_000)
+	h := NewHandler(sm)
+	order := &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_SELL, TokenId: "1", MakerAmount: "100", NegRisk: true}
+
+	resp, err := h.ComputeOrderHash(context.Background(), &signerv1.ComputeOrderHashRequest{Order: order})
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, _ := OrderDigest(order)
+	if resp.Digest != "0x"+hex.EncodeToString(digest[:]) {
+		t.Errorf("digest = %s", resp.Digest)
+	}
+	if resp.Domain.VerifyingContract != NegRiskCTFExchange.VerifyingContract {
+		t.Errorf("domain = %v, want the neg-risk exchange", resp.Domain)
+	}
+	if _, _, _, used, _ := sm.Status(); used != "0" {
+		t.Errorf("value used = %s, hashing must not consume limit", used)
+	}
+
+	signed, err := h.SignOrder(context.Background(), &signerv1.SignOrderRequest{Order: order})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, _ := hex.DecodeString(signed.Signature[2:])
+	if addr, err := recoverAddress(digest, sig); err != nil || addr != testMaker {
+		t.Errorf("SignOrder did not sign the computed digest: %s, %v", addr, err)
+	}
+
+	order.Side = signerv1.OrderSide_ORDER_SIDE_UNSPECIFIED
+	if _, err := h.ComputeOrderHash(context.Background(), &signerv1.ComputeOrderHashRequest{Order: order}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestSignOrderWithFunder(t *testing.T) {
+	h := NewHandler(activeSession(t, 1_000_000))
+	const proxy = "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"
+	order := &signerv1.PolymarketOrder{Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "100", SignatureType: signerv1.SignatureType_SIGNATURE_TYPE_POLY_PROXY}
+
+	hashed, err := h.ComputeOrderHash(context.Background(), &signerv1.ComputeOrderHashRequest{Order: order, Funder: proxy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := h.SignOrder(context.Background(), &signerv1.SignOrderRequest{Order: order, Funder: proxy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Order.Maker != proxy || resp.Order.Signer != testMaker {
+		t.Errorf("signed order maker %s signer %s, want %s signed by %s", resp.Order.Maker, resp.Order.Signer, proxy, testMaker)
+	}
+	if order.Maker != "" || order.Signer != "" {
+		t.Error("request order was modified")
+	}
+	digest, _ := OrderDigest(resp.Order)
+	if hashed.Digest != "0x"+hex.EncodeToString(digest[:]) {
+		t.Errorf("ComputeOrderHash digest %s differs from the signed order's", hashed.Digest)
+	}
+	sig, _ := hex.DecodeString(resp.Signature[2:])
+	if addr, err := recoverAddress(digest, sig); err != nil || addr != testMaker {
+		t.Errorf("recovered %s, %v", addr, err)
+	}
+
+	// A proxy order needs to know whose funds it trades.
+	if _, err := h.SignOrder(context.Background(), &signerv1.SignOrderRequest{Order: order}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("proxy order without funder: expected InvalidArgument, got %v", err)
+	}
+	// An EOA order cannot be made by anyone but the key.
+	eoa := &signerv1.PolymarketOrder{Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "100"}
+	if _, err := h.SignOrder(context.Background(), &signerv1.SignOrderRequest{Order: eoa, Funder: proxy}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("EOA order with funder: expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	h := NewHandler(activeSession(t, 1_000_000))
+	signed, err := h.SignOrder(context.Background(), &signerv1.SignOrderRequest{
+		Order: &signerv1.PolymarketOrder{Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "100"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verification needs no session.
+	verifier := NewHandler(NewSessionManager(time.Hour))
+	resp, err := verifier.VerifySignature(context.Background(), &signerv1.VerifySignatureRequest{Order: signed.Order, Signature: signed.Signature})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Valid || resp.RecoveredAddress != testMaker {
+		t.Errorf("own signature: valid=%v recovered=%s", resp.Valid, resp.RecoveredAddress)
+	}
+
+	other := "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"
+	resp, _ = verifier.VerifySignature(context.Background(), &signerv1.VerifySignatureRequest{Order: signed.Order, Signature: signed.Signature, ExpectedAddress: other})
+	if resp.Valid || resp.RecoveredAddress != testMaker {
+		t.Errorf("other expected address: valid=%v recovered=%s", resp.Valid, resp.RecoveredAddress)
+	}
+
+	tampered := proto.Clone(signed.Order).(*signerv1.PolymarketOrder)
+	tampered.MakerAmount = "101"
+	resp, _ = verifier.VerifySignature(context.Background(), &signerv1.VerifySignatureRequest{Order: tampered, Signature: signed.Signature})
+	if resp.Valid {
+		t.Error("tampered order verified")
+	}
+	resp, _ = verifier.VerifySignature(context.Background(), &signerv1.VerifySignatureRequest{Order: signed.Order, Signature: "0x00"})
+	if resp == nil || resp.Valid || resp.RecoveredAddress != "" {
+		t.Errorf("short signature: %v", resp)
+	}
+
+	if _, err := verifier.VerifySignature(context.Background(), &signerv1.VerifySignatureRequest{Order: signed.Order, Signature: "0xzz"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("non-hex signature: expected InvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,427 @@
+package signer
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/awnumar/memguard"
+)
+
+var (
+	ErrHandoffPending   = errors.New("session is being handed off to another host")
+	ErrNoHandoff        = errors.New("no session handoff in progress")
+	ErrHandoffMismatch  = errors.New("handoff id does not match the pending export")
+	ErrNoImportKey      = errors.New("no import key prepared")
+	ErrSessionActive    = errors.New("a session is already active")
+	ErrInvalidBundle    = errors.New("invalid session bundle")
+	ErrInvalidImportKey = errors.New("invalid import public key")
+)
+
+// Handoff bundle layout:
+//
+//	version (1) ‖ ephemeral X25519 public key (32) ‖ nonce (12) ‖ AES-GCM ciphertext
+//
+// The AEAD key is SHA-256(label ‖ shared secret ‖ ephemeral pub ‖ destination
+// pub) and the destination public key is bound as additional data, so a
+// bundle opens only with the private key PrepareImport generated. Version
+// 2 added the session's tightened caps, 3 its rolling window spend and 4
+// its order count.
+const (
+	bundleVersion = 4
+	handoffLabel  = "caesar-session-handoff-v1"
+	handoffIDLen  = 16
+)
+
+// pendingHandoff is an export awaiting confirmation from the destination.
+type pendingHandoff struct {
+	id string
+}
+
+// PrepareImport generates a fresh X25519 key pair for receiving a session
+// and returns its public key. The private key stays in memory only and is
+// consumed by the next successful Import.
+func (sm *SessionManager) PrepareImport() ([]byte, error) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.importKey = priv
+	return priv.PublicKey().Bytes(), nil
+}
+
+// Export re-encrypts the active session key, its limit ledger, its rolling
+// window spend, the orders it has signed and any caps UpdateSessionPolicy
+// tightened to the destination's public key. The source session is
+// frozen — Sign returns ErrHandoffPending — until ConfirmExport destroys it
+// or AbortExport resumes it, so the same limit is never spent on two
+// hosts. A paused session is refused with ErrSessionPaused: the import
+// would start unpaused.
+func (sm *SessionManager) Export(ctx context.Context, destPublicKey []byte) ([]byte, error) {
+	dest, err := ecdh.X25519().NewPublicKey(destPublicKey)
+	if err != nil {
+		return nil, ErrInvalidImportKey
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.key == nil {
+		return nil, ErrNoActiveSession
+	}
+	if sm.isExpired() {
+		sm.destroyLocked()
+		return nil, ErrSessionExpired
+	}
+	if sm.handoff != nil {
+		return nil, ErrHandoffPending
+	}
+	if sm.paused != nil {
+		return nil, ErrSessionPaused
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	key, ok := sm.key.(*EnclaveSigner)
+	if !ok {
+		return nil, ErrKeyNotExportable
+	}
+
+	id := make([]byte, handoffIDLen)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	buf, err := key.open()
+	if err != nil {
+		return nil, err
+	}
+	plaintext := encodeHandoff(handoffState{
+		id:        id,
+		expiresAt: sm.expiresAt,
+		maxLimit:  sm.maxValueLimit,
+		used:      sm.valueUsed,
+		clamp:     sm.clamp,
+		orders:    sm.ordersSigned,
+		windows:   sm.windows,
+		key:       buf.Bytes(),
+	})
+	closeBuffer(buf)
+	defer memguard.WipeBytes(plaintext)
+
+	bundle, err := sealBundle(dest, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	sm.handoff = &pendingHandoff{id: hex.EncodeToString(id)}
+	return bundle, nil
+}
+
+// Import opens a bundle produced by Export using the prepared import key
+// and activates the session it carries with the source's expiry, value
+// ledger, window spend, order count and tightened caps. It returns the
+// handoff ID, which the operator passes to ConfirmExport on the source to
+// destroy the original session.
+func (sm *SessionManager) Import(ctx context.Context, bundle []byte) (string, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if sm.importKey == nil {
+		return "", ErrNoImportKey
+	}
+	if sm.key != nil && !sm.isExpired() {
+		return "", ErrSessionActive
+	}
+
+	plaintext, err := openBundle(sm.importKey, bundle)
+	if err != nil {
+		return "", err
+	}
+	defer memguard.WipeBytes(plaintext)
+
+	st, err := decodeHandoff(plaintext)
+	if err != nil {
+		return "", err
+	}
+	if !sm.clock.Now().Before(st.expiresAt) {
+		return "", ErrSessionExpired
+	}
+	signer, err := NewEnclaveSigner(st.key)
+	if err != nil {
+		return "", err
+	}
+	if sm.keyGuard != nil {
+		if err := sm.keyGuard(signer.Address()); err != nil {
+			signer.Destroy()
+			return "", err
+		}
+	}
+
+	expiresAt, maxLimit, used, err := sm.openLedgerLocked(signer.Address(), st.expiresAt, st.maxLimit, st.used)
+	if err != nil {
+		signer.Destroy()
+		return "", err
+	}
+
+	// An expired session may still hold its key.
+	destroyKey(sm.key)
+	sm.key = signer
+	sm.activatedAt = sm.clock.Now()
+	sm.lastActive = sm.activatedAt
+	sm.expiresAt = expiresAt
+	sm.maxValueLimit = maxLimit
+	sm.valueUsed = used
+	sm.releasable = releaseLedger{}
+	sm.restoreWindowsLocked(st.windows)
+	sm.resetOrderLimitsLocked()
+	sm.ordersSigned = st.orders
+	sm.handoff = nil
+	sm.importKey = nil
+	sm.paused = nil
+	sm.clamp = st.clamp
+	sm.epoch++
+
+	return hex.EncodeToString(st.id), nil
+}
+
+// ConfirmExport destroys the exported session once the destination has
+// imported it. The ID is only recoverable by decrypting the bundle, so it
+// doubles as proof that the import happened; it is compared in constant
+// time so it cannot be guessed a byte at a time.
+func (sm *SessionManager) ConfirmExport(handoffID string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.handoff == nil {
+		return ErrNoHandoff
+	}
+	if subtle.ConstantTimeCompare([]byte(handoffID), []byte(sm.handoff.id)) != 1 {
+		return ErrHandoffMismatch
+	}
+	sm.destroyLocked()
+	return nil
+}
+
+// AbortExport unfreezes a session whose export was never imported.
+func (sm *SessionManager) AbortExport() error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.handoff == nil {
+		return ErrNoHandoff
+	}
+	sm.handoff = nil
+	return nil
+}
+
+func sealBundle(dest *ecdh.PublicKey, plaintext []byte) ([]byte, error) {
+	eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := handoffAEAD(eph, dest, eph.PublicKey().Bytes(), dest.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := []byte{bundleVersion}
+	out = append(out, eph.PublicKey().Bytes()...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, dest.Bytes()), nil
+}
+
+func openBundle(priv *ecdh.PrivateKey, bundle []byte) ([]byte, error) {
+	const pubLen = 32
+	if len(bundle) < 1+pubLen || bundle[0] != bundleVersion {
+		return nil, ErrInvalidBundle
+	}
+	eph, err := ecdh.X25519().NewPublicKey(bundle[1 : 1+pubLen])
+	if err != nil {
+		return nil, ErrInvalidBundle
+	}
+	aead, err := handoffAEAD(priv, eph, eph.Bytes(), priv.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	rest := bundle[1+pubLen:]
+	if len(rest) < aead.NonceSize() {
+		return nil, ErrInvalidBundle
+	}
+	nonce, ciphertext := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, priv.PublicKey().Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w: decryption failed", ErrInvalidBundle)
+	}
+	return plaintext, nil
+}
+
+// handoffAEAD derives the bundle cipher from an X25519 exchange between
+// priv and peer, bound to the ephemeral and destination public keys.
+func handoffAEAD(priv *ecdh.PrivateKey, peer *ecdh.PublicKey, ephPub, destPub []byte) (cipher.AEAD, error) {
+	shared, err := priv.ECDH(peer)
+	if err != nil {
+		return nil, err
+	}
+	defer memguard.WipeBytes(shared)
+
+	h := sha256.New()
+	h.Write([]byte(handoffLabel))
+	h.Write(shared)
+	h.Write(ephPub)
+	h.Write(destPub)
+	key := h.Sum(nil)
+	defer memguard.WipeBytes(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// handoffState is the session state a handoff bundle carries. Of each
+// window only the span and buckets travel; the destination's own limits
+// apply.
+type handoffState struct {
+	id             []byte
+	expiresAt      time.Time
+	maxLimit, used Amount
+	clamp          sessionClamp
+	orders         int64 // signed so far, against the session's order cap
+	windows        []windowLedger
+	key            []byte
+}
+
+// encodeHandoff serializes the session as
+//
+//	id ‖ expiresAt (unix nanos) ‖ maxLimit ‖ used ‖ clamp.maxOrderValue ‖
+//	clamp.maxOrders ‖ orders ‖ window count ‖ windows ‖ key
+//
+// where each amount is a 4-byte length and its big-endian bytes, and each
+// window is its span (nanos) ‖ bucket count ‖ buckets, a bucket being its
+// start (unix nanos) ‖ value.
+func encodeHandoff(st handoffState) []byte {
+	appendAmount := func(out []byte, a Amount) []byte {
+		b := a.BigInt().Bytes()
+		out = binary.BigEndian.AppendUint32(out, uint32(len(b)))
+		return append(out, b...)
+	}
+	out := append([]byte(nil), st.id...)
+	out = binary.BigEndian.AppendUint64(out, uint64(st.expiresAt.UnixNano()))
+	out = appendAmount(out, st.maxLimit)
+	out = appendAmount(out, st.used)
+	out = appendAmount(out, st.clamp.maxOrderValue)
+	out = binary.BigEndian.AppendUint64(out, uint64(st.clamp.maxOrders))
+	out = binary.BigEndian.AppendUint64(out, uint64(st.orders))
+	out = binary.BigEndian.AppendUint32(out, uint32(len(st.windows)))
+	for _, w := range st.windows {
+		out = binary.BigEndian.AppendUint64(out, uint64(w.span))
+		out = binary.BigEndian.AppendUint32(out, uint32(len(w.buckets)))
+		for _, b := range w.buckets {
+			out = binary.BigEndian.AppendUint64(out, uint64(b.start.UnixNano()))
+			out = appendAmount(out, b.value)
+		}
+	}
+	return append(out, st.key...)
+}
+
+// handoffReader consumes an encoded handoff, failing sticky on the first
+// field that runs past the end.
+type handoffReader struct {
+	b  []byte
+	ok bool
+}
+
+func (r *handoffReader) bytes(n uint64) []byte {
+	if !r.ok || uint64(len(r.b)) < n {
+		r.ok = false
+		return nil
+	}
+	out := r.b[:n]
+	r.b = r.b[n:]
+	return out
+}
+
+func (r *handoffReader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *handoffReader) int64() int64 {
+	b := r.bytes(8)
+	if b == nil {
+		return 0
+	}
+	v := binary.BigEndian.Uint64(b)
+	if v > math.MaxInt64 {
+		r.ok = false
+	}
+	return int64(v)
+}
+
+func (r *handoffReader) amount() Amount {
+	b := r.bytes(uint64(r.uint32()))
+	if b == nil {
+		return Amount{}
+	}
+	v, err := NewAmount(new(big.Int).SetBytes(b))
+	if err != nil {
+		r.ok = false
+	}
+	return v
+}
+
+func decodeHandoff(b []byte) (handoffState, error) {
+	r := &handoffReader{b: b, ok: true}
+	var st handoffState
+	st.id = r.bytes(handoffIDLen)
+	st.expiresAt = time.Unix(0, r.int64())
+	st.maxLimit = r.amount()
+	st.used = r.amount()
+	st.clamp.maxOrderValue = r.amount()
+	st.clamp.maxOrders = r.int64()
+	st.orders = r.int64()
+	// Every window takes at least 12 bytes, which bounds the count by
+	// what is left before anything is allocated for it.
+	if n := uint64(r.uint32()); r.ok && n <= uint64(len(r.b))/12 {
+		st.windows = make([]windowLedger, n)
+		for i := range st.windows {
+			w := &st.windows[i]
+			w.span = time.Duration(r.int64())
+			for range r.uint32() {
+				if !r.ok {
+					break
+				}
+				start := time.Unix(0, r.int64())
+				w.buckets = append(w.buckets, windowBucket{start: start, value: r.amount()})
+			}
+		}
+	} else {
+		r.ok = false
+	}
+	if !r.ok || len(r.b) == 0 {
+		return handoffState{}, ErrInvalidBundle
+	}
+	st.key = r.b
+	return st, nil
+}
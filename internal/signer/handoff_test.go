@@ -0,0 +1,230 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestHandoffRoundTrip(t *testing.T) {
+	src := activeSession(t, 1_000_000)
+	if _, err := src.Sign(context.Background(), [32]byte{}, big.NewInt(250_000)); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	dst := NewSessionManager(time.Hour)
+	t.Cleanup(dst.Destroy)
+	pub, err := dst.PrepareImport()
+	if err != nil {
+		t.Fatalf("prepare import: %v", err)
+	}
+
+	bundle, err := src.Export(context.Background(), pub)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if _, err := src.Sign(context.Background(), [32]byte{}, big.NewInt(1)); !errors.Is(err, ErrHandoffPending) {
+		t.Errorf("source should be frozen during handoff, got %v", err)
+	}
+
+	id, err := dst.Import(context.Background(), bundle)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	active, ttl, maxLimit, used, _ := dst.Status()
+	if !active || maxLimit != "1000000" || used != "250000" {
+		t.Errorf("imported status = %v %s %s", active, maxLimit, used)
+	}
+	if ttl <= 0 || ttl > 3600 {
+		t.Errorf("imported ttl = %d, want source expiry carried over", ttl)
+	}
+	if _, err := dst.Sign(context.Background(), [32]byte{}, big.NewInt(800_000)); !errors.Is(err, ErrValueLimitExceeded) {
+		t.Errorf("ledger should carry over, got %v", err)
+	}
+
+	if err := src.ConfirmExport("deadbeef"); !errors.Is(err, ErrHandoffMismatch) {
+		t.Errorf("expected ErrHandoffMismatch, got %v", err)
+	}
+	if err := src.ConfirmExport(id); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if active, _, _, _, _ := src.Status(); active {
+		t.Error("source session should be destroyed after confirm")
+	}
+}
+
+func TestHandoffCarriesTightenedCaps(t *testing.T) {
+	src := activeSession(t, 1_000_000)
+	if _, err := src.Tighten(context.Background(), SessionTightening{MaxOrderValue: big.NewInt(2000), MaxOrders: 1}); err != nil {
+		t.Fatalf("tighten: %v", err)
+	}
+
+	dst := NewSessionManager(time.Hour)
+	t.Cleanup(dst.Destroy)
+	pub, err := dst.PrepareImport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := src.Export(context.Background(), pub)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if _, err := dst.Import(context.Background(), bundle); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	if _, err := dst.Sign(context.Background(), [32]byte{}, big.NewInt(2001)); !errors.Is(err, ErrOrderValueExceeded) {
+		t.Errorf("per-order cap should carry over, got %v", err)
+	}
+	if _, err := dst.Sign(context.Background(), [32]byte{}, big.NewInt(2000)); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if _, err := dst.Sign(context.Background(), [32]byte{1}, big.NewInt(1)); !errors.Is(err, ErrOrderCountExceeded) {
+		t.Errorf("order cap should carry over, got %v", err)
+	}
+}
+
+func TestHandoffCarriesOrderCount(t *testing.T) {
+	src := activeSession(t, 1_000_000)
+	if err := src.SetOrderLimits(2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.Sign(context.Background(), [32]byte{}, big.NewInt(1)); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	dst := NewSessionManager(time.Hour)
+	t.Cleanup(dst.Destroy)
+	if err := dst.SetOrderLimits(2, 0); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := dst.PrepareImport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := src.Export(context.Background(), pub)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if _, err := dst.Import(context.Background(), bundle); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	if signed, max := dst.OrderCount(); signed != 1 || max != 2 {
+		t.Errorf("order count = %d of %d, want 1 of 2", signed, max)
+	}
+	if _, err := dst.Sign(context.Background(), [32]byte{1}, big.NewInt(1)); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if _, err := dst.Sign(context.Background(), [32]byte{2}, big.NewInt(1)); !errors.Is(err, ErrOrderCountExceeded) {
+		t.Errorf("order count should carry over, got %v", err)
+	}
+}
+
+func TestHandoffCarriesWindowSpend(t *testing.T) {
+	src := activeSession(t, 1_000_000)
+	if err := src.SetValueWindows([]ValueWindow{{Span: time.Hour, Limit: big.NewInt(500)}, {Span: 24 * time.Hour, Limit: big.NewInt(2000)}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.Sign(context.Background(), [32]byte{}, big.NewInt(500)); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	// The destination has no 12h window at the source; the source's daily
+	// window stands in for it.
+	dst := NewSessionManager(time.Hour)
+	t.Cleanup(dst.Destroy)
+	if err := dst.SetValueWindows([]ValueWindow{{Span: time.Hour, Limit: big.NewInt(500)}, {Span: 12 * time.Hour, Limit: big.NewInt(1500)}}); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := dst.PrepareImport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := src.Export(context.Background(), pub)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if _, err := dst.Import(context.Background(), bundle); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	if _, err := dst.Sign(context.Background(), [32]byte{1}, big.NewInt(1)); !errors.Is(err, ErrWindowLimitExceeded) {
+		t.Errorf("the hourly window was full before the handoff, got %v", err)
+	}
+	for _, w := range dst.ValueWindows() {
+		if w.Used != "500" {
+			t.Errorf("%s window used %s after import, want 500", w.Span, w.Used)
+		}
+	}
+}
+
+func TestDecodeHandoffRejectsTruncated(t *testing.T) {
+	w, err := newWindowLedger(ValueWindow{Span: time.Hour, Limit: big.NewInt(500)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.add(time.Unix(1_700_000_000, 0), Amount{})
+	enc := encodeHandoff(handoffState{
+		id:        make([]byte, handoffIDLen),
+		expiresAt: time.Unix(1_700_003_600, 0),
+		windows:   []windowLedger{w},
+		key:       []byte{1},
+	})
+	if st, err := decodeHandoff(enc); err != nil || len(st.windows) != 1 || len(st.windows[0].buckets) != 1 || st.windows[0].span != time.Hour {
+		t.Fatalf("round trip: %+v, %v", st, err)
+	}
+	// Without the key every prefix is missing a field.
+	for n := range len(enc) - 1 {
+		if _, err := decodeHandoff(enc[:n]); !errors.Is(err, ErrInvalidBundle) {
+			t.Errorf("%d of %d bytes: expected ErrInvalidBundle, got %v", n, len(enc), err)
+		}
+	}
+}
+
+func TestHandoffRejectsOtherKey(t *testing.T) {
+	src := activeSession(t, 1_000_000)
+	intended := NewSessionManager(time.Hour)
+	pub, _ := intended.PrepareImport()
+	bundle, err := src.Export(context.Background(), pub)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	other := NewSessionManager(time.Hour)
+	if _, err := other.Import(context.Background(), bundle); !errors.Is(err, ErrNoImportKey) {
+		t.Errorf("expected ErrNoImportKey, got %v", err)
+	}
+	other.PrepareImport()
+	if _, err := other.Import(context.Background(), bundle); !errors.Is(err, ErrInvalidBundle) {
+		t.Errorf("expected ErrInvalidBundle, got %v", err)
+	}
+
+	bundle[len(bundle)-1] ^= 0xff
+	if _, err := intended.Import(context.Background(), bundle); !errors.Is(err, ErrInvalidBundle) {
+		t.Errorf("tampered bundle: expected ErrInvalidBundle, got %v", err)
+	}
+}
+
+func TestAbortExportResumesSigning(t *testing.T) {
+	src := activeSession(t, 1_000_000)
+	dst := NewSessionManager(time.Hour)
+	pub, _ := dst.PrepareImport()
+	if _, err := src.Export(context.Background(), pub); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if _, err := src.Export(context.Background(), pub); !errors.Is(err, ErrHandoffPending) {
+		t.Errorf("second export: expected ErrHandoffPending, got %v", err)
+	}
+	if err := src.AbortExport(); err != nil {
+		t.Fatalf("abort: %v", err)
+	}
+	if _, err := src.Sign(context.Background(), [32]byte{}, big.NewInt(1)); err != nil {
+		t.Errorf("sign after abort: %v", err)
+	}
+	if err := src.AbortExport(); !errors.Is(err, ErrNoHandoff) {
+		t.Errorf("expected ErrNoHandoff, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package signer
+
+import "time"
+
+// WithIdleTimeout destroys the session once d passes without an order
+// being signed, however much of its TTL is left, so a key activated on a
+// terminal that was then walked away from does not stay signable for the
+// whole TTL. Signatures that commit no value, such as CLOB auth refreshes,
+// do not count; activating, importing or renewing the session does. Zero,
+// the default, disables it.
+func WithIdleTimeout(d time.Duration) SessionOption {
+	return func(sm *SessionManager) {
+		sm.idleTimeout = d
+	}
+}
+
+// IdleRemaining returns how long the active session may go without an
+// order before it is destroyed, or zero without an idle timeout or an
+// active session.
+func (sm *SessionManager) IdleRemaining() time.Duration {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	if sm.key == nil || sm.idleTimeout <= 0 || sm.isExpired() {
+		return 0
+	}
+	return sm.lastActive.Add(sm.idleTimeout).Sub(sm.clock.Now())
+}
+
+// deadlineLocked returns when the session ends, the earlier of its expiry
+// and its idle deadline, and whether it is the idle deadline. Caller must
+// hold sm.mu.
+func (sm *SessionManager) deadlineLocked() (deadline time.Time, idle bool) {
+	if sm.idleTimeout > 0 {
+		if d := sm.lastActive.Add(sm.idleTimeout); d.Before(sm.expiresAt) {
+			return d, true
+		}
+	}
+	return sm.expiresAt, false
+}
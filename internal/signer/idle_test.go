@@ -0,0 +1,71 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+func TestSessionIdleTimeout(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	var reaped []SessionExpiry
+	sm := NewSessionManager(time.Hour, WithClock(clk), WithIdleTimeout(10*time.Minute), WithReapHook(func(ev SessionExpiry) { reaped = append(reaped, ev) }))
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	defer sm.Destroy()
+
+	// Each signature restarts the idle clock.
+	for range 3 {
+		clk.Advance(9 * time.Minute)
+		if _, err := sm.Sign(context.Background(), [32]byte{}, big.NewInt(1)); err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+	}
+	if got := sm.IdleRemaining(); got != 10*time.Minute {
+		t.Errorf("idle remaining %s, want 10m", got)
+	}
+
+	// A valueless signature, like a CLOB auth refresh, is not activity.
+	clk.Advance(5 * time.Minute)
+	if _, err := sm.Sign(context.Background(), [32]byte{}, new(big.Int)); err != nil {
+		t.Fatalf("valueless sign: %v", err)
+	}
+	if got := sm.IdleRemaining(); got != 5*time.Minute {
+		t.Errorf("idle remaining %s after a valueless signature, want 5m", got)
+	}
+
+	clk.Advance(5*time.Minute + time.Nanosecond)
+	if active, _, _, _, _ := sm.Status(); active {
+		t.Error("idle session still active")
+	}
+	if !sm.Reap() {
+		t.Fatal("idle session not reaped")
+	}
+	if len(reaped) != 1 || !reaped[0].Idle || !reaped[0].ExpiredAt.Equal(start.Add(37*time.Minute)) {
+		t.Errorf("reaped %+v", reaped)
+	}
+	if _, err := sm.Sign(context.Background(), [32]byte{}, big.NewInt(1)); !errors.Is(err, ErrNoActiveSession) {
+		t.Errorf("expected ErrNoActiveSession, got %v", err)
+	}
+}
+
+func TestSessionIdleTimeoutPastTTL(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	var reaped []SessionExpiry
+	sm := NewSessionManager(time.Hour, WithClock(clk), WithIdleTimeout(2*time.Hour), WithReapHook(func(ev SessionExpiry) { reaped = append(reaped, ev) }))
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	defer sm.Destroy()
+
+	clk.Advance(time.Hour + time.Nanosecond)
+	if !sm.Reap() || len(reaped) != 1 || reaped[0].Idle {
+		t.Errorf("the TTL should end the session first: %+v", reaped)
+	}
+}
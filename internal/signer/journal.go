@@ -0,0 +1,258 @@
+package signer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrJournalCorrupt = errors.New("ledger journal corrupt")
+
+// Journal entry kinds.
+const (
+	JournalOpen    = "open"    // a session was activated or imported
+	JournalCommit  = "commit"  // value was committed by a signature
+	JournalRenew   = "renew"   // the session's expiry moved
+	JournalTighten = "tighten" // the session's limit was lowered or its expiry brought forward
+	JournalRelease = "release" // value was credited back for an unfilled order
+	JournalClose   = "close"   // the session ended and its ledger is settled
+)
+
+// JournalEntry is one line of the ledger journal. Every entry carries the
+// session's whole ledger after the change, so the last entry of a session
+// is its state.
+type JournalEntry struct {
+	Kind      string    `json:"kind"`
+	Session   string    `json:"session"`
+	Address   string    `json:"address,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	Limit     string    `json:"limit,omitempty"`
+	Used      string    `json:"used,omitempty"`
+	Orders    []string  `json:"orders,omitempty"` // 0x-hex digests signed by a commit
+	At        time.Time `json:"at"`
+}
+
+// recoveredOrderLimit bounds how many of a recovered session's signed
+// orders the journal keeps, the most recent first to go unreconciled.
+const recoveredOrderLimit = 1000
+
+// Journal is a write-ahead log of session ledgers. A session appends to it,
+// and waits for the append to reach disk, before it hands out a signature,
+// so after a crash the value each session spent is known exactly: a
+// restarted signer that activates the same key resumes the ledger instead
+// of granting the limit again.
+type Journal struct {
+	mu        sync.Mutex
+	path      string
+	f         *os.File
+	recovered map[journalKey]JournalEntry // ledgers a crash left open, until their key reopens
+}
+
+// journalKey identifies one key's ledger in a session. A session can be
+// activated with different keys over time; each keeps its own ledger, so
+// activating another key under the session name settles nothing.
+type journalKey struct {
+	session, address string
+}
+
+// OpenJournal replays the journal at path, creating it if needed, and
+// compacts it to the last entry of each session a crash left open. A torn
+// final line is the append a crash interrupted; its signature was never
+// handed out, so it is dropped. Any other unreadable line is
+// ErrJournalCorrupt.
+func OpenJournal(path string) (*Journal, error) {
+	j := &Journal{path: path, recovered: make(map[journalKey]JournalEntry)}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var e JournalEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			if i == len(lines)-1 {
+				break // torn: the file does not end in a newline
+			}
+			return nil, fmt.Errorf("%w: line %d: %v", ErrJournalCorrupt, i+1, err)
+		}
+		k := journalKey{e.Session, e.Address}
+		if e.Kind == JournalClose {
+			j.settle(k)
+			continue
+		}
+		// Carry the ledger's signed orders forward from its open, so the
+		// compacted entry still names them. The previous entry is replaced,
+		// so its slice can be appended to in place.
+		if e.Kind != JournalOpen {
+			e.Orders = append(j.recovered[k].Orders, e.Orders...)
+		}
+		if n := len(e.Orders); n > 2*recoveredOrderLimit {
+			e.Orders = append([]string(nil), e.Orders[n-recoveredOrderLimit:]...)
+		}
+		j.recovered[k] = e
+	}
+	for k, e := range j.recovered {
+		if n := len(e.Orders); n > recoveredOrderLimit {
+			e.Orders = e.Orders[n-recoveredOrderLimit:]
+			j.recovered[k] = e
+		}
+	}
+	if err := j.compact(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// compact rewrites the journal with only the recovered sessions' entries
+// and reopens it for appending.
+func (j *Journal) compact() error {
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return err
+	}
+	entries := j.sortedRecovered()
+
+	tmp := j.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return err
+	}
+	if err := syncDir(filepath.Dir(j.path)); err != nil {
+		return err
+	}
+	j.f, err = os.OpenFile(j.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	return err
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// append writes e and syncs it to disk.
+func (j *Journal) append(e JournalEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return os.ErrClosed
+	}
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := j.f.Sync(); err != nil {
+		return err
+	}
+	// The key's ledger has moved on from whatever the crash left.
+	j.settle(journalKey{e.Session, e.Address})
+	return nil
+}
+
+// settle forgets the recovered ledger k. A close from before closes named
+// their key has no address and settles the whole session. Caller must hold
+// j.mu, or be OpenJournal.
+func (j *Journal) settle(k journalKey) {
+	if k.address != "" {
+		delete(j.recovered, k)
+		return
+	}
+	for r := range j.recovered {
+		if r.session == k.session {
+			delete(j.recovered, r)
+		}
+	}
+}
+
+// Recovered returns the last entry of every key's ledger the journal was
+// left with open, by a crash rather than a shutdown, and that has not been
+// reopened with the same key since.
+func (j *Journal) Recovered() []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.sortedRecovered()
+}
+
+// sortedRecovered returns the recovered entries by session and address.
+// Caller must hold j.mu, or be OpenJournal.
+func (j *Journal) sortedRecovered() []JournalEntry {
+	out := make([]JournalEntry, 0, len(j.recovered))
+	for _, e := range j.recovered {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(a, b int) bool {
+		if out[a].Session != out[b].Session {
+			return out[a].Session < out[b].Session
+		}
+		return out[a].Address < out[b].Address
+	})
+	return out
+}
+
+// recoveredLedger returns the ledger session was left with by a crash
+// while holding address's key, if any.
+func (j *Journal) recoveredLedger(session, address string) (expiresAt time.Time, limit, used Amount, ok bool, err error) {
+	j.mu.Lock()
+	e, found := j.recovered[journalKey{session, address}]
+	j.mu.Unlock()
+	if !found {
+		return time.Time{}, Amount{}, Amount{}, false, nil
+	}
+	if limit, err = ParseAmount(e.Limit); err != nil {
+		return time.Time{}, Amount{}, Amount{}, false, fmt.Errorf("%w: session %q limit: %v", ErrJournalCorrupt, session, err)
+	}
+	if used, err = ParseAmount(e.Used); err != nil {
+		return time.Time{}, Amount{}, Amount{}, false, fmt.Errorf("%w: session %q used: %v", ErrJournalCorrupt, session, err)
+	}
+	return e.ExpiresAt, limit, used, true, nil
+}
+
+// Close closes the journal file. Sessions journaling to it fail to sign
+// afterwards.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return nil
+	}
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
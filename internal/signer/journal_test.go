@@ -0,0 +1,208 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+func TestJournalResumesAfterCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.journal")
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm := NewSessionManager(time.Hour, WithClock(clk), WithJournal(j, "arb"))
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if _, err := sm.Sign(context.Background(), [32]byte{1}, big.NewInt(400)); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	// Crash: the journal goes away mid-append, with the session open.
+	j.Close()
+	if _, err := sm.Sign(context.Background(), [32]byte{2}, big.NewInt(1)); err == nil {
+		t.Error("signed without journaling the spend")
+	}
+	if _, _, _, used, _ := sm.Status(); used != "400" {
+		t.Errorf("unjournaled spend committed: used %s", used)
+	}
+	sm.Destroy()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"kind":"commit","session":"arb","us`)
+	f.Close()
+
+	clk.Advance(10 * time.Minute)
+	j, err = OpenJournal(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if got := j.Recovered(); len(got) != 1 || got[0].Session != "arb" || got[0].Used != "400" {
+		t.Fatalf("recovered %+v", got)
+	}
+	sm = NewSessionManager(time.Hour, WithClock(clk), WithJournal(j, "arb"))
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(5000)); err != nil {
+		t.Fatalf("reactivate: %v", err)
+	}
+	active, ttl, limit, used, _ := sm.Status()
+	if !active || ttl != 50*60 || limit != "1000" || used != "400" {
+		t.Errorf("resumed: active=%v ttl=%d limit=%s used=%s", active, ttl, limit, used)
+	}
+	if _, err := sm.Sign(context.Background(), [32]byte{3}, big.NewInt(700)); !errors.Is(err, ErrValueLimitExceeded) {
+		t.Errorf("expected ErrValueLimitExceeded, got %v", err)
+	}
+	if len(j.Recovered()) != 0 {
+		t.Error("a resumed session is still recovered")
+	}
+
+	// A shutdown settles the ledger, so the next run starts fresh.
+	sm.Destroy()
+	j.Close()
+	if j, err = OpenJournal(path); err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if got := j.Recovered(); len(got) != 0 {
+		t.Errorf("recovered after shutdown: %+v", got)
+	}
+	sm = NewSessionManager(time.Hour, WithClock(clk), WithJournal(j, "arb"))
+	defer sm.Destroy()
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(5000)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, limit, used, _ := sm.Status(); limit != "5000" || used != "0" {
+		t.Errorf("fresh session: limit=%s used=%s", limit, used)
+	}
+}
+
+// crashedJournal returns a journal left with session "arb" open on
+// testKey, having spent 400 of 1000.
+func crashedJournal(t *testing.T, clk clock.Clock) *Journal {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ledger.journal")
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm := NewSessionManager(time.Hour, WithClock(clk), WithJournal(j, "arb"))
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.Sign(context.Background(), [32]byte{1}, big.NewInt(400)); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+	sm.Destroy()
+
+	if j, err = OpenJournal(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { j.Close() })
+	return j
+}
+
+func TestJournalIgnoresOtherLedgers(t *testing.T) {
+	other := make([]byte, 32)
+	other[31] = 2
+	cases := []struct {
+		name    string
+		session string
+		key     []byte
+		advance time.Duration
+	}{
+		{"another session", "momentum", testKey(), 0},
+		{"another key", "arb", other, 0},
+		{"expired ledger", "arb", testKey(), time.Hour},
+	}
+	for _, c := range cases {
+		clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+		j := crashedJournal(t, clk)
+		clk.Advance(c.advance)
+		sm := NewSessionManager(time.Hour, WithClock(clk), WithJournal(j, c.session))
+		if err := sm.Activate(context.Background(), c.key, big.NewInt(1000)); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if _, _, _, used, _ := sm.Status(); used != "0" {
+			t.Errorf("%s: used %s, want a fresh ledger", c.name, used)
+		}
+		sm.Destroy()
+	}
+}
+
+func TestJournalKeepsLedgerAcrossOtherKeys(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	j := crashedJournal(t, clk)
+	other := make([]byte, 32)
+	other[31] = 2
+
+	// A throwaway key opened and closed under the same session name
+	// settles only its own ledger.
+	sm := NewSessionManager(time.Hour, WithClock(clk), WithJournal(j, "arb"))
+	if err := sm.Activate(context.Background(), other, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+	sm.Destroy()
+	if got := j.Recovered(); len(got) != 1 || got[0].Address != testMaker {
+		t.Fatalf("recovered %+v", got)
+	}
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(5000)); err != nil {
+		t.Fatal(err)
+	}
+	defer sm.Destroy()
+	if _, _, limit, used, _ := sm.Status(); limit != "1000" || used != "400" {
+		t.Errorf("resumed: limit=%s used=%s, want the crashed ledger", limit, used)
+	}
+}
+
+func TestJournalResumesImport(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	j := crashedJournal(t, clk)
+
+	// A bundle exported before the crash knows nothing of what was spent
+	// since.
+	src := NewSessionManager(time.Hour)
+	if err := src.Activate(context.Background(), testKey(), big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Destroy()
+	if _, err := src.Sign(context.Background(), [32]byte{1}, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	dst := NewSessionManager(time.Hour, WithClock(clk), WithJournal(j, "arb"))
+	defer dst.Destroy()
+	pub, err := dst.PrepareImport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := src.Export(context.Background(), pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dst.Import(context.Background(), bundle); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if _, ttl, limit, used, _ := dst.Status(); ttl != 3600 || limit != "1000" || used != "400" {
+		t.Errorf("imported: ttl=%d limit=%s used=%s", ttl, limit, used)
+	}
+}
+
+func TestJournalCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.journal")
+	if err := os.WriteFile(path, []byte("{\"kind\":\"open\"\n{\"kind\":\"close\"}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenJournal(path); !errors.Is(err, ErrJournalCorrupt) {
+		t.Errorf("expected ErrJournalCorrupt, got %v", err)
+	}
+}
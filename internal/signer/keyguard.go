@@ -0,0 +1,93 @@
+package signer
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrProdKey    = errors.New("production key refused outside the prod profile")
+	ErrNotProdKey = errors.New("key is not a listed production key")
+	ErrKeyPin     = errors.New("key does not derive the pinned address")
+)
+
+// ProdKeyGuard returns a WithKeyGuard check that keeps production keys
+// and other environments apart. Outside prod, the listed production
+// addresses are refused, so a prod key cannot be activated against a
+// testnet or paper deployment by mistake. Under prod, with addresses
+// listed, every other key is refused.
+func ProdKeyGuard(prod bool, addresses []string) func(address string) error {
+	listed := make(map[string]bool, len(addresses))
+	for _, a := range addresses {
+		if a = strings.TrimSpace(a); a != "" {
+			listed[strings.ToLower(a)] = true
+		}
+	}
+	return func(address string) error {
+		isProd := listed[strings.ToLower(address)]
+		switch {
+		case !prod && isProd:
+			return fmt.Errorf("%w: %s", ErrProdKey, address)
+		case prod && len(listed) > 0 && !isProd:
+			return fmt.Errorf("%w: %s", ErrNotProdKey, address)
+		}
+		return nil
+	}
+}
+
+// ParseKeyPins parses the addresses sessions are pinned to: a
+// comma-separated list of "address", pinning the default session, or
+// "session=address".
+func ParseKeyPins(s string) (map[string]string, error) {
+	pins := make(map[string]string)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, address, ok := strings.Cut(entry, "=")
+		if !ok {
+			id, address = DefaultSessionID, entry
+		}
+		id, address = strings.TrimSpace(id), strings.TrimSpace(address)
+		raw, err := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+		if err != nil || len(raw) != 20 || !strings.HasPrefix(address, "0x") {
+			return nil, fmt.Errorf("pin %q: want a 0x-prefixed 20-byte address", entry)
+		}
+		if _, dup := pins[id]; dup {
+			return nil, fmt.Errorf("session %q pinned twice", id)
+		}
+		pins[id] = address
+	}
+	return pins, nil
+}
+
+// PinnedKeyGuard returns a WithKeyGuard check that refuses every key but
+// the one deriving address, so activating from the wrong keystore file
+// fails before anything is signed with it.
+func PinnedKeyGuard(address string) func(address string) error {
+	return func(got string) error {
+		if !strings.EqualFold(got, address) {
+			return fmt.Errorf("%w: pinned %s, key derives %s", ErrKeyPin, address, got)
+		}
+		return nil
+	}
+}
+
+// KeyGuards returns a WithKeyGuard check passing only keys that every
+// non-nil guard passes.
+func KeyGuards(guards ...func(address string) error) func(address string) error {
+	return func(address string) error {
+		for _, guard := range guards {
+			if guard == nil {
+				continue
+			}
+			if err := guard(address); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,91 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProdKeyGuard(t *testing.T) {
+	prodKeys := []string{strings.ToLower(testMaker), " "}
+	other := "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"
+
+	for _, tc := range []struct {
+		name    string
+		prod    bool
+		keys    []string
+		address string
+		want    error
+	}{
+		{"prod key under prod", true, prodKeys, testMaker, nil},
+		{"prod key outside prod", false, prodKeys, testMaker, ErrProdKey},
+		{"other key outside prod", false, prodKeys, other, nil},
+		{"other key under prod", true, prodKeys, other, ErrNotProdKey},
+		{"nothing listed", true, nil, other, nil},
+	} {
+		if err := ProdKeyGuard(tc.prod, tc.keys)(tc.address); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestKeyGuardRefusesActivateAndImport(t *testing.T) {
+	guard := WithKeyGuard(ProdKeyGuard(false, []string{testMaker}))
+
+	sm := NewSessionManager(time.Hour, guard)
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); !errors.Is(err, ErrProdKey) {
+		t.Fatalf("activate: expected ErrProdKey, got %v", err)
+	}
+	if active, _, _, _, _ := sm.Status(); active {
+		t.Error("refused key must not activate a session")
+	}
+
+	src := activeSession(t, 1_000_000)
+	dst := NewSessionManager(time.Hour, guard)
+	t.Cleanup(dst.Destroy)
+	pub, err := dst.PrepareImport()
+	if err != nil {
+		t.Fatalf("prepare import: %v", err)
+	}
+	bundle, err := src.Export(context.Background(), pub)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if _, err := dst.Import(context.Background(), bundle); !errors.Is(err, ErrProdKey) {
+		t.Fatalf("import: expected ErrProdKey, got %v", err)
+	}
+	if active, _, _, _, _ := dst.Status(); active {
+		t.Error("refused key must not be imported")
+	}
+}
+
+func TestPinnedKeyGuard(t *testing.T) {
+	pins, err := ParseKeyPins(strings.ToLower(testMaker) + ", mm=0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(pins) != 2 || pins["mm"] != "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF" {
+		t.Fatalf("pins %v", pins)
+	}
+	for _, bad := range []string{"0x1234", "mm=" + testMaker + ",mm=" + testMaker, "2B5AD5c4795c026514f8317c7a215E218DcCD6cF"} {
+		if _, err := ParseKeyPins(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+
+	sm := NewSessionManager(time.Hour, WithKeyGuard(KeyGuards(ProdKeyGuard(false, nil), PinnedKeyGuard(pins["mm"]))))
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); !errors.Is(err, ErrKeyPin) {
+		t.Fatalf("wrong key: expected ErrKeyPin, got %v", err)
+	}
+	if active, _, _, _, _ := sm.Status(); active {
+		t.Error("session activated with the wrong key")
+	}
+
+	sm = NewSessionManager(time.Hour, WithKeyGuard(PinnedKeyGuard(pins[DefaultSessionID])))
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); err != nil {
+		t.Errorf("pinned key: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package signer
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/awnumar/memguard"
+)
+
+var (
+	ErrKeyNotExportable = errors.New("session key cannot leave its signer")
+	ErrKeyDestroyed     = errors.New("session key destroyed")
+)
+
+// Key material counters, process-wide: a key that outlives its session is
+// a security bug, not just a leak, so long runs assert these stay flat.
+var (
+	liveEnclaves atomic.Int64
+	openBuffers  atomic.Int64
+)
+
+// KeyMaterial counts the key material a process holds.
+type KeyMaterial struct {
+	Enclaves int64 // EnclaveSigners not yet destroyed
+	Buffers  int64 // LockedBuffers holding an opened key
+}
+
+// LiveKeyMaterial returns the key material currently held. Buffers is
+// zero whenever no signature or export is in flight, and Enclaves is at
+// most one per active session.
+func LiveKeyMaterial() KeyMaterial {
+	return KeyMaterial{Enclaves: liveEnclaves.Load(), Buffers: openBuffers.Load()}
+}
+
+// Signer holds a secp256k1 key and signs digests with it. SessionManager
+// decides whether a digest may be signed at all — TTL, limits, profiles —
+// and a Signer only does the cryptography, so an in-memory key, a
+// hardware token or a remote KMS can back a session without touching
+// limit logic.
+type Signer interface {
+	// Address returns the EIP-55 address of the key.
+	Address() string
+	// SignDigest returns the 65-byte r ‖ s ‖ v signature of digest. The
+	// session normalizes it (see NormalizeSignature), so a high s or v of
+	// 0 or 1 is accepted.
+	SignDigest(digest [32]byte) ([]byte, error)
+}
+
+// BatchSigner is a Signer that can sign several digests in one operation,
+// such as one enclave open or one round trip. SignBatch uses it when the
+// session's Signer provides it.
+type BatchSigner interface {
+	Signer
+	SignDigests(digests [][32]byte) ([][]byte, error)
+}
+
+// EnclaveSigner keeps a raw key sealed in a memguard Enclave and opens it
+// only for the duration of a signature. It is the only Signer whose key
+// can be handed off to another host.
+type EnclaveSigner struct {
+	mu      sync.Mutex
+	enclave *memguard.Enclave // nil once destroyed
+	address string
+}
+
+// NewEnclaveSigner seals keyBytes, a 32-byte secp256k1 private key, and
+// wipes it. The caller MUST still zero any other copy of the key.
+func NewEnclaveSigner(keyBytes []byte) (*EnclaveSigner, error) {
+	// Derive the address before sealing: NewEnclave wipes keyBytes.
+	address, err := keyAddress(keyBytes)
+	if err != nil {
+		return nil, err
+	}
+	liveEnclaves.Add(1)
+	return &EnclaveSigner{enclave: memguard.NewEnclave(keyBytes), address: address}, nil
+}
+
+// Destroy drops the sealed key; later signatures fail with
+// ErrKeyDestroyed. A session destroys its EnclaveSigner when it ends or is
+// replaced. Destroy is idempotent.
+func (s *EnclaveSigner) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.enclave != nil {
+		s.enclave = nil
+		liveEnclaves.Add(-1)
+	}
+}
+
+// open unseals the key into a LockedBuffer, which the caller MUST release
+// with closeBuffer.
+func (s *EnclaveSigner) open() (*memguard.LockedBuffer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.enclave == nil {
+		return nil, ErrKeyDestroyed
+	}
+	buf, err := s.enclave.Open()
+	if err != nil {
+		return nil, err
+	}
+	openBuffers.Add(1)
+	return buf, nil
+}
+
+// closeBuffer wipes and frees a buffer returned by open.
+func closeBuffer(buf *memguard.LockedBuffer) {
+	buf.Destroy()
+	openBuffers.Add(-1)
+}
+
+// Address returns the EIP-55 address of the sealed key.
+func (s *EnclaveSigner) Address() string {
+	return s.address
+}
+
+// SignDigest signs one digest.
+func (s *EnclaveSigner) SignDigest(digest [32]byte) ([]byte, error) {
+	sigs, err := s.SignDigests([][32]byte{digest})
+	if err != nil {
+		return nil, err
+	}
+	return sigs[0], nil
+}
+
+// SignDigests signs every digest under one enclave open and destroys the
+// locked buffer before returning.
+func (s *EnclaveSigner) SignDigests(digests [][32]byte) ([][]byte, error) {
+	buf, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	defer closeBuffer(buf)
+	sigs := make([][]byte, len(digests))
+	for i, digest := range digests {
+		if sigs[i], err = signDigest(buf.Bytes(), digest); err != nil {
+			return nil, err
+		}
+	}
+	return sigs, nil
+}
+
+// destroyKey destroys key if it is an EnclaveSigner. Other Signers belong
+// to whoever passed them to ActivateSigner.
+func destroyKey(key Signer) {
+	if k, ok := key.(*EnclaveSigner); ok {
+		k.Destroy()
+	}
+}
+
+// signDigests signs with key in one operation when it supports batches.
+func signDigests(key Signer, digests [][32]byte) ([][]byte, error) {
+	if b, ok := key.(BatchSigner); ok {
+		return b.SignDigests(digests)
+	}
+	sigs := make([][]byte, len(digests))
+	for i, digest := range digests {
+		var err error
+		if sigs[i], err = key.SignDigest(digest); err != nil {
+			return nil, err
+		}
+	}
+	return sigs, nil
+}
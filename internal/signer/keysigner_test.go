@@ -0,0 +1,185 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+// remoteSigner stands in for a hardware or KMS backend: it signs one
+// digest per call and counts them.
+type remoteSigner struct {
+	key   []byte
+	calls int
+}
+
+func (r *remoteSigner) Address() string {
+	addr, _ := keyAddress(r.key)
+	return addr
+}
+
+func (r *remoteSigner) SignDigest(digest [32]byte) ([]byte, error) {
+	r.calls++
+	return signDigest(r.key, digest)
+}
+
+// malleableSigner returns high-s signatures in the raw v convention, as
+// some backends do.
+type malleableSigner struct{ remoteSigner }
+
+func (m *malleableSigner) SignDigest(digest [32]byte) ([]byte, error) {
+	sig, err := m.remoteSigner.SignDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	return malleate(sig), nil
+}
+
+func TestEnclaveSigner(t *testing.T) {
+	key := testKey()
+	s, err := NewEnclaveSigner(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Address() != testMaker {
+		t.Errorf("address = %s, want %s", s.Address(), testMaker)
+	}
+	for _, b := range key {
+		if b != 0 {
+			t.Fatal("NewEnclaveSigner must wipe the key bytes")
+		}
+	}
+
+	digests := [][32]byte{{1}, {2}}
+	sigs, err := s.SignDigests(digests)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, d := range digests {
+		one, err := s.SignDigest(d)
+		if err != nil || string(one) != string(sigs[i]) {
+			t.Errorf("digest %d: batch and single signatures differ (%v)", i, err)
+		}
+		if addr, _ := recoverAddress(d, sigs[i]); addr != testMaker {
+			t.Errorf("digest %d recovers to %s", i, addr)
+		}
+	}
+
+	if _, err := NewEnclaveSigner(make([]byte, 32)); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("zero key: expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestKeyMaterialReleased(t *testing.T) {
+	base := LiveKeyMaterial()
+	live := func(stage string, enclaves int64) {
+		t.Helper()
+		if got := LiveKeyMaterial(); got.Enclaves != base.Enclaves+enclaves || got.Buffers != base.Buffers {
+			t.Errorf("%s: %+v, want %d more enclaves than %+v", stage, got, enclaves, base)
+		}
+	}
+
+	s, err := NewEnclaveSigner(testKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	live("new signer", 1)
+	s.Destroy()
+	s.Destroy()
+	live("destroyed signer", 0)
+	if _, err := s.SignDigest([32]byte{}); !errors.Is(err, ErrKeyDestroyed) {
+		t.Errorf("expected ErrKeyDestroyed, got %v", err)
+	}
+
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	refuse := errors.New("refused")
+	guarded := false
+	sm := NewSessionManager(time.Hour, WithClock(clk), WithKeyGuard(func(string) error {
+		if guarded {
+			return refuse
+		}
+		return nil
+	}))
+	for i := 0; i < 3; i++ {
+		if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sm.Sign(context.Background(), [32]byte{byte(i)}, big.NewInt(1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	live("reactivated session", 1)
+	guarded = true
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); !errors.Is(err, refuse) {
+		t.Fatalf("expected the guard to refuse, got %v", err)
+	}
+	live("refused activation", 1)
+
+	clk.Advance(time.Hour + time.Second)
+	sm.Reap()
+	live("reaped session", 0)
+	sm.Destroy()
+	live("destroyed session", 0)
+}
+
+func TestActivateSigner(t *testing.T) {
+	sm := NewSessionManager(time.Hour)
+	remote := &remoteSigner{key: testKey()}
+	if err := sm.ActivateSigner(context.Background(), remote, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	if active, _, _, _, addr := sm.Status(); !active || addr != testMaker {
+		t.Fatalf("status active=%v address=%s", active, addr)
+	}
+
+	sigs, err := sm.SignBatch(context.Background(), [][32]byte{{1}, {2}}, []*big.Int{big.NewInt(40), big.NewInt(40)})
+	if err != nil || len(sigs) != 2 || remote.calls != 2 {
+		t.Fatalf("batch: %d signatures, %d calls, %v", len(sigs), remote.calls, err)
+	}
+	// The limit is the session's business; the backend is never asked.
+	if _, err := sm.Sign(context.Background(), [32]byte{3}, big.NewInt(40)); !errors.Is(err, ErrValueLimitExceeded) {
+		t.Errorf("expected ErrValueLimitExceeded, got %v", err)
+	}
+	if remote.calls != 2 {
+		t.Errorf("backend called %d times, want 2", remote.calls)
+	}
+
+	dest, err := NewSessionManager(time.Hour).PrepareImport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.Export(context.Background(), dest); !errors.Is(err, ErrKeyNotExportable) {
+		t.Errorf("expected ErrKeyNotExportable, got %v", err)
+	}
+
+	guarded := NewSessionManager(time.Hour, WithKeyGuard(ProdKeyGuard(false, []string{testMaker})))
+	if err := guarded.ActivateSigner(context.Background(), remote, big.NewInt(100)); !errors.Is(err, ErrProdKey) {
+		t.Errorf("expected ErrProdKey, got %v", err)
+	}
+}
+
+func TestSessionNormalizesSignatures(t *testing.T) {
+	digest := [32]byte{1}
+	want, err := signDigest(testKey(), digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, offset := range []byte{VOffsetEthereum, VOffsetRaw} {
+		sm := NewSessionManager(time.Hour, WithVOffset(offset))
+		if err := sm.ActivateSigner(context.Background(), &malleableSigner{remoteSigner{key: testKey()}}, big.NewInt(100)); err != nil {
+			t.Fatal(err)
+		}
+		sig, err := sm.Sign(context.Background(), digest, big.NewInt(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(sig[:64]) != string(want[:64]) || sig[64] != want[64]-27+offset {
+			t.Errorf("offset %d: got %x, want low-s %x", offset, sig, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+)
+
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+const (
+	// CurrencyUSDC is the only currency the signer accounts in.
+	CurrencyUSDC = "USDC"
+	// AtomicDecimals is the scale of USDC and of outcome-token shares on
+	// Polymarket: one unit is 10^-6.
+	AtomicDecimals = 6
+	// maxDecimals bounds the scale a client may send; beyond it no
+	// amount could be both exact and meaningful.
+	maxDecimals = 36
+)
+
+// MoneyToAmount converts m to USDC atomic units. m must be in USDC and
+// exact at six decimals: 1.5 USDC may arrive as units 15 with decimals 1,
+// but 1.0000005 is refused rather than rounded.
+func MoneyToAmount(m *signerv1.Money) (Amount, error) {
+	if m == nil {
+		return Amount{}, fmt.Errorf("%w: no money", ErrInvalidAmount)
+	}
+	if m.Currency != CurrencyUSDC {
+		return Amount{}, fmt.Errorf("%w %q", ErrUnsupportedCurrency, m.Currency)
+	}
+	v, err := rescale(m.Units, m.Decimals)
+	if err != nil {
+		return Amount{}, err
+	}
+	return NewAmount(v)
+}
+
+// AmountToMoney returns a as USDC at six decimals.
+func AmountToMoney(a Amount) (*signerv1.Money, error) {
+	v := a.big()
+	if !v.IsUint64() {
+		return nil, ErrAmountOverflow
+	}
+	return &signerv1.Money{Units: v.Uint64(), Decimals: AtomicDecimals, Currency: CurrencyUSDC}, nil
+}
+
+// QuantityToUnits converts q to raw share units.
+func QuantityToUnits(q *signerv1.Quantity) (uint64, error) {
+	if q == nil {
+		return 0, fmt.Errorf("%w: no quantity", ErrInvalidAmount)
+	}
+	v, err := rescale(q.Units, q.Decimals)
+	if err != nil {
+		return 0, err
+	}
+	if !v.IsUint64() {
+		return 0, ErrAmountOverflow
+	}
+	return v.Uint64(), nil
+}
+
+// UnitsToQuantity returns raw share units as a Quantity at six decimals.
+func UnitsToQuantity(units uint64) *signerv1.Quantity {
+	return &signerv1.Quantity{Units: units, Decimals: AtomicDecimals}
+}
+
+// rescale returns units / 10^decimals in atomic units, refusing values
+// that are not exact at six decimals.
+func rescale(units uint64, decimals uint32) (*big.Int, error) {
+	if decimals > maxDecimals {
+		return nil, fmt.Errorf("%w: %d decimals", ErrInvalidAmount, decimals)
+	}
+	v := new(big.Int).SetUint64(units)
+	if decimals <= AtomicDecimals {
+		return v.Mul(v, pow10(AtomicDecimals-decimals)), nil
+	}
+	q, r := new(big.Int).QuoRem(v, pow10(decimals-AtomicDecimals), new(big.Int))
+	if r.Sign() != 0 {
+		return nil, fmt.Errorf("%w: %d/10^%d is finer than 10^-%d", ErrInvalidAmount, units, decimals, AtomicDecimals)
+	}
+	return q, nil
+}
+
+func pow10(n uint32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,112 @@
+package signer
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+)
+
+func TestMoneyToAmount(t *testing.T) {
+	for _, tc := range []struct {
+		units    uint64
+		decimals uint32
+		currency string
+		want     string
+		err      error
+	}{
+		{0, 0, CurrencyUSDC, "0", nil},
+		{1, 0, CurrencyUSDC, "1000000", nil},
+		{15, 1, CurrencyUSDC, "1500000", nil},
+		{150, 2, CurrencyUSDC, "1500000", nil},
+		{1_500_000, 6, CurrencyUSDC, "1500000", nil},
+		{1_500_000_000, 9, CurrencyUSDC, "1500000", nil},
+		{1_500_000_500, 9, CurrencyUSDC, "", ErrInvalidAmount},
+		{1, 7, CurrencyUSDC, "", ErrInvalidAmount},
+		{0, 36, CurrencyUSDC, "0", nil},
+		{1, 37, CurrencyUSDC, "", ErrInvalidAmount},
+		{math.MaxUint64, 0, CurrencyUSDC, "18446744073709551615000000", nil},
+		{math.MaxUint64, 6, CurrencyUSDC, "18446744073709551615", nil},
+		{1, 6, "USDC.e", "", ErrUnsupportedCurrency},
+		{1, 6, "", "", ErrUnsupportedCurrency},
+		{1, 6, "usdc", "", ErrUnsupportedCurrency},
+	} {
+		m := &signerv1.Money{Units: tc.units, Decimals: tc.decimals, Currency: tc.currency}
+		a, err := MoneyToAmount(m)
+		if tc.err != nil {
+			if !errors.Is(err, tc.err) {
+				t.Errorf("%v: expected %v, got %v", m, tc.err, err)
+			}
+			continue
+		}
+		if err != nil || a.String() != tc.want {
+			t.Errorf("%v: got %s, %v; want %s", m, a, err, tc.want)
+		}
+	}
+	if _, err := MoneyToAmount(nil); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("nil: expected ErrInvalidAmount, got %v", err)
+	}
+}
+
+func TestAmountToMoney(t *testing.T) {
+	for _, v := range []uint64{0, 1, 1_500_000, math.MaxUint64} {
+		a, err := NewAmount(new(big.Int).SetUint64(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := AmountToMoney(a)
+		if err != nil || m.Units != v || m.Decimals != AtomicDecimals || m.Currency != CurrencyUSDC {
+			t.Fatalf("%d: got %v, %v", v, m, err)
+		}
+		back, err := MoneyToAmount(m)
+		if err != nil || back.String() != new(big.Int).SetUint64(v).String() {
+			t.Errorf("%d: round trip gave %s, %v", v, back, err)
+		}
+	}
+
+	a, err := NewAmount(new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AmountToMoney(a); !errors.Is(err, ErrAmountOverflow) {
+		t.Errorf("2^64: expected ErrAmountOverflow, got %v", err)
+	}
+}
+
+func TestQuantityUnits(t *testing.T) {
+	for _, tc := range []struct {
+		units    uint64
+		decimals uint32
+		want     uint64
+		err      error
+	}{
+		{5, 0, 5_000_000, nil},
+		{125, 1, 12_500_000, nil},
+		{12_500_000, 6, 12_500_000, nil},
+		{12_500_000_000, 9, 12_500_000, nil},
+		{1, 9, 0, ErrInvalidAmount},
+		{math.MaxUint64, 6, math.MaxUint64, nil},
+		{math.MaxUint64, 5, 0, ErrAmountOverflow},
+		{1, 40, 0, ErrInvalidAmount},
+	} {
+		q := &signerv1.Quantity{Units: tc.units, Decimals: tc.decimals}
+		got, err := QuantityToUnits(q)
+		if tc.err != nil {
+			if !errors.Is(err, tc.err) {
+				t.Errorf("%v: expected %v, got %v", q, tc.err, err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("%v: got %d, %v; want %d", q, got, err, tc.want)
+		}
+		if back, err := QuantityToUnits(UnitsToQuantity(got)); err != nil || back != got {
+			t.Errorf("%d: round trip gave %d, %v", got, back, err)
+		}
+	}
+	if _, err := QuantityToUnits(nil); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("nil: expected ErrInvalidAmount, got %v", err)
+	}
+}
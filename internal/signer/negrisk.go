@@ -0,0 +1,154 @@
+package signer
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/caesar-terminal/caesar/internal/eip712"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NegRiskAdapter function selectors.
+var (
+	convertPositionsSelector = selector("convertPositions(bytes32,uint256,uint256)")
+	splitPositionSelector    = selector("splitPosition(bytes32,uint256)")
+	mergePositionsSelector   = selector("mergePositions(bytes32,uint256)")
+)
+
+func selector(signature string) []byte {
+	h := eip712.Keccak256([]byte(signature))
+	return h[:4]
+}
+
+// ConvertCalldata returns the calldata of NegRiskAdapter.convertPositions,
+// turning amount NO positions of each question in indexSet into YES
+// positions of the market's other questions.
+func ConvertCalldata(marketID string, indexSet, amount *big.Int) ([]byte, error) {
+	id, err := parseBytes32(marketID)
+	if err != nil {
+		return nil, fmt.Errorf("market ID: %w", err)
+	}
+	if indexSet.Sign() <= 0 || indexSet.BitLen() > 256 {
+		return nil, fmt.Errorf("index set %s is not a non-empty uint256 bitmask", indexSet)
+	}
+	return abiCall(convertPositionsSelector, id, eip712.EncodeUint(indexSet), eip712.EncodeUint(amount)), nil
+}
+
+// SplitCalldata returns the calldata of NegRiskAdapter.splitPosition,
+// turning amount of collateral into YES and NO positions of conditionID.
+func SplitCalldata(conditionID string, amount *big.Int) ([]byte, error) {
+	id, err := parseBytes32(conditionID)
+	if err != nil {
+		return nil, fmt.Errorf("condition ID: %w", err)
+	}
+	return abiCall(splitPositionSelector, id, eip712.EncodeUint(amount)), nil
+}
+
+// MergeCalldata returns the calldata of NegRiskAdapter.mergePositions,
+// turning amount of YES and NO positions of conditionID back into
+// collateral.
+func MergeCalldata(conditionID string, amount *big.Int) ([]byte, error) {
+	id, err := parseBytes32(conditionID)
+	if err != nil {
+		return nil, fmt.Errorf("condition ID: %w", err)
+	}
+	return abiCall(mergePositionsSelector, id, eip712.EncodeUint(amount)), nil
+}
+
+func abiCall(sel []byte, words ...[]byte) []byte {
+	data := append([]byte(nil), sel...)
+	for _, w := range words {
+		data = append(data, w...)
+	}
+	return data
+}
+
+// parseBytes32 decodes a 0x-prefixed 32-byte hex value.
+func parseBytes32(s string) ([]byte, error) {
+	raw, ok := strings.CutPrefix(s, "0x")
+	b, err := hex.DecodeString(raw)
+	if !ok || err != nil || len(b) != 32 {
+		return nil, fmt.Errorf("%q is not a 0x-prefixed bytes32", s)
+	}
+	return b, nil
+}
+
+// SignConversion signs a Safe transaction calling the network's
+// NegRiskAdapter, so multi-outcome strategies can convert, split or merge
+// positions with the session key. The call always goes to the adapter and
+// moves only the Safe's own positions; a split spends collateral and
+// counts against the value limit like an order.
+func (h *Handler) SignConversion(ctx context.Context, req *signerv1.SignConversionRequest) (*signerv1.SignConversionResponse, error) {
+	_, _, _, _, addr := h.session.Status()
+	if addr == "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "no active session")
+	}
+	if h.network.NegRiskAdapter == "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "no NegRiskAdapter on chain %d", h.network.ChainID)
+	}
+	if req.Safe == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "safe is required")
+	}
+	nonce, ok := new(big.Int).SetString(req.Nonce, 10)
+	if !ok || nonce.Sign() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid nonce %q", req.Nonce)
+	}
+	amount, ok := new(big.Int).SetString(req.Amount, 10)
+	if !ok || amount.Sign() <= 0 || amount.BitLen() > 256 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid amount %q", req.Amount)
+	}
+
+	var data []byte
+	var err error
+	value := new(big.Int)
+	switch req.Kind {
+	case signerv1.ConversionKind_CONVERSION_KIND_CONVERT:
+		indexSet, ok := new(big.Int).SetString(req.IndexSet, 10)
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid index set %q", req.IndexSet)
+		}
+		data, err = ConvertCalldata(req.MarketId, indexSet, amount)
+	case signerv1.ConversionKind_CONVERSION_KIND_SPLIT:
+		data, err = SplitCalldata(req.ConditionId, amount)
+		value = amount
+	case signerv1.ConversionKind_CONVERSION_KIND_MERGE:
+		data, err = MergeCalldata(req.ConditionId, amount)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "conversion kind is required")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	digest, err := safeTxDigest(h.network.ChainID, req.Safe, h.network.NegRiskAdapter, data, nonce)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	sig, err := h.session.Sign(ctx, digest, value)
+	switch {
+	case errors.Is(err, ErrValueLimitExceeded):
+		return nil, status.Errorf(codes.ResourceExhausted, "cumulative value limit exceeded")
+	case orderLimitStatus(err) != nil:
+		return nil, orderLimitStatus(err)
+	case errors.Is(err, ErrOrderValueExceeded):
+		return nil, orderValueExceededStatus(h.session.MaxOrderValue())
+	case errors.Is(err, ErrApprovalRequired):
+		return nil, status.Errorf(codes.FailedPrecondition, "split requires approval under limit profile %q", h.session.ActiveProfile())
+	case err != nil:
+		return nil, valuelessSignStatus(err)
+	}
+	return &signerv1.SignConversionResponse{
+		Signature: "0x" + hex.EncodeToString(sig),
+		Owner:     addr,
+		Digest:    "0x" + hex.EncodeToString(digest[:]),
+		Adapter:   h.network.NegRiskAdapter,
+		Data:      "0x" + hex.EncodeToString(data),
+		RequestId: RequestID(ctx),
+	}, nil
+}
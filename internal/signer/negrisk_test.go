@@ -0,0 +1,116 @@
+package signer
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testMarketID = "0xc0ffee" + strings.Repeat("00", 29)
+
+func TestNegRiskCalldata(t *testing.T) {
+	id := strings.TrimPrefix(testMarketID, "0x")
+	for name, tc := range map[string]struct {
+		build func() ([]byte, error)
+		want  string
+	}{
+		"convert": {func() ([]byte, error) { return ConvertCalldata(testMarketID, big.NewInt(5), big.NewInt(1_000_000)) },
+			"c64748c4" + id + word(5) + word(1_000_000)},
+		"split": {func() ([]byte, error) { return SplitCalldata(testMarketID, big.NewInt(7)) },
+			"a3d7da1d" + id + word(7)},
+		"merge": {func() ([]byte, error) { return MergeCalldata(testMarketID, big.NewInt(7)) },
+			"b10c5c17" + id + word(7)},
+	} {
+		data, err := tc.build()
+		if err != nil || hex.EncodeToString(data) != tc.want {
+			t.Errorf("%s: got %x, %v; want %s", name, data, err, tc.want)
+		}
+	}
+
+	if _, err := ConvertCalldata(testMarketID, new(big.Int), big.NewInt(1)); err == nil {
+		t.Error("expected an error for an empty index set")
+	}
+	if _, err := SplitCalldata("0xc0ffee", big.NewInt(1)); err == nil {
+		t.Error("expected an error for a short condition ID")
+	}
+}
+
+// word renders n as one hex-encoded ABI word.
+func word(n int64) string {
+	return hex.EncodeToString(big.NewInt(n).FillBytes(make([]byte, 32)))
+}
+
+func TestSignConversion(t *testing.T) {
+	sm := NewSessionManager(time.Hour)
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1_000)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(sm.Destroy)
+	h := NewHandler(sm)
+	convert := func() *signerv1.SignConversionRequest {
+		return &signerv1.SignConversionRequest{
+			Kind:     signerv1.ConversionKind_CONVERSION_KIND_CONVERT,
+			Safe:     testSafe,
+			MarketId: testMarketID,
+			IndexSet: "3",
+			Amount:   "500",
+			Nonce:    "4",
+		}
+	}
+
+	resp, err := h.SignConversion(context.Background(), convert())
+	if err != nil {
+		t.Fatalf("sign conversion: %v", err)
+	}
+	data, _ := ConvertCalldata(testMarketID, big.NewInt(3), big.NewInt(500))
+	digest, _ := safeTxDigest(137, testSafe, Polygon.NegRiskAdapter, data, big.NewInt(4))
+	if addr, err := recoverAddress(digest, mustHex(t, resp.Signature[2:])); err != nil || addr != testMaker || resp.Owner != testMaker {
+		t.Errorf("signature recovers to %s, %v", addr, err)
+	}
+	if resp.Adapter != Polygon.NegRiskAdapter || resp.Data != "0x"+hex.EncodeToString(data) {
+		t.Errorf("unexpected call %s %s", resp.Adapter, resp.Data)
+	}
+	if _, _, _, used, _ := sm.Status(); used != "0" {
+		t.Errorf("conversions must not spend the value limit, used = %s", used)
+	}
+
+	// A split spends collateral, so it counts against the limit.
+	split := &signerv1.SignConversionRequest{
+		Kind:        signerv1.ConversionKind_CONVERSION_KIND_SPLIT,
+		Safe:        testSafe,
+		ConditionId: testMarketID,
+		Amount:      "800",
+		Nonce:       "5",
+	}
+	if _, err := h.SignConversion(context.Background(), split); err != nil {
+		t.Fatalf("sign split: %v", err)
+	}
+	if _, _, _, used, _ := sm.Status(); used != "800" {
+		t.Errorf("split must spend the value limit, used = %s", used)
+	}
+	if _, err := h.SignConversion(context.Background(), split); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("second split: expected ResourceExhausted, got %v", err)
+	}
+
+	for name, bad := range map[string]func(*signerv1.SignConversionRequest){
+		"no safe":       func(r *signerv1.SignConversionRequest) { r.Safe = "" },
+		"no kind":       func(r *signerv1.SignConversionRequest) { r.Kind = signerv1.ConversionKind_CONVERSION_KIND_UNSPECIFIED },
+		"zero amount":   func(r *signerv1.SignConversionRequest) { r.Amount = "0" },
+		"bad nonce":     func(r *signerv1.SignConversionRequest) { r.Nonce = "x" },
+		"empty set":     func(r *signerv1.SignConversionRequest) { r.IndexSet = "0" },
+		"bad market ID": func(r *signerv1.SignConversionRequest) { r.MarketId = "0x01" },
+	} {
+		req := convert()
+		bad(req)
+		if _, err := h.SignConversion(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument, got %v", name, err)
+		}
+	}
+}
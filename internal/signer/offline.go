@@ -0,0 +1,192 @@
+package signer
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/eip712"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var ErrBundleMismatch = errors.New("offline bundle mismatch")
+
+// offlineVersion is the format version of bundle and signature files.
+const offlineVersion = 1
+
+// OfflineBundle is a file of unsigned orders carried to an air-gapped
+// signer. Each order travels with its EIP-712 digest so the offline side
+// can confirm it computes the same bytes before signing them.
+type OfflineBundle struct {
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	Orders    []OfflineOrder `json:"orders"`
+}
+
+// OfflineOrder is one order of a bundle.
+type OfflineOrder struct {
+	Digest string          `json:"digest"` // 0x-hex EIP-712 digest
+	Order  json.RawMessage `json:"order"`  // protojson PolymarketOrder
+}
+
+// OfflineSignatures is the offline signer's answer to a bundle, carried
+// back for submission.
+type OfflineSignatures struct {
+	Version    int                `json:"version"`
+	Bundle     string             `json:"bundle"` // ID of the signed bundle
+	Signer     string             `json:"signer"`
+	Signatures []OfflineSignature `json:"signatures"`
+}
+
+// OfflineSignature answers one order: a signature, or why the offline
+// signer refused it.
+type OfflineSignature struct {
+	Digest    string `json:"digest"`
+	Signature string `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SignedOrder is an order ready for submission.
+type SignedOrder struct {
+	Order     *signerv1.PolymarketOrder
+	Signature string
+}
+
+// NewOfflineBundle packages orders for offline signing.
+func NewOfflineBundle(orders []*signerv1.PolymarketOrder, now time.Time) (*OfflineBundle, error) {
+	b := &OfflineBundle{Version: offlineVersion, CreatedAt: now.UTC()}
+	for i, o := range orders {
+		digest, err := OrderDigest(o)
+		if err != nil {
+			return nil, fmt.Errorf("order %d: %w", i+1, err)
+		}
+		raw, err := protojson.Marshal(o)
+		if err != nil {
+			return nil, fmt.Errorf("order %d: %w", i+1, err)
+		}
+		b.Orders = append(b.Orders, OfflineOrder{Digest: "0x" + hex.EncodeToString(digest[:]), Order: raw})
+	}
+	return b, nil
+}
+
+// ID identifies the bundle by the digests it carries, binding a
+// signatures file to the bundle it answers.
+func (b *OfflineBundle) ID() string {
+	parts := make([][]byte, len(b.Orders))
+	for i, o := range b.Orders {
+		parts[i] = []byte(o.Digest)
+	}
+	id := eip712.Keccak256(parts...)
+	return hex.EncodeToString(id[:8])
+}
+
+// Decode returns the bundle's orders, failing with ErrBundleMismatch if
+// any order does not hash to the digest recorded next to it.
+func (b *OfflineBundle) Decode() ([]*signerv1.PolymarketOrder, error) {
+	if b.Version != offlineVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", b.Version)
+	}
+	orders := make([]*signerv1.PolymarketOrder, len(b.Orders))
+	for i, entry := range b.Orders {
+		o := &signerv1.PolymarketOrder{}
+		if err := protojson.Unmarshal(entry.Order, o); err != nil {
+			return nil, fmt.Errorf("order %d: %w", i+1, err)
+		}
+		digest, err := OrderDigest(o)
+		if err != nil {
+			return nil, fmt.Errorf("order %d: %w", i+1, err)
+		}
+		if !strings.EqualFold(entry.Digest, "0x"+hex.EncodeToString(digest[:])) {
+			return nil, fmt.Errorf("%w: order %d does not hash to %s", ErrBundleMismatch, i+1, entry.Digest)
+		}
+		orders[i] = o
+	}
+	return orders, nil
+}
+
+// SignOffline signs every order of b through h, so the offline signer
+// applies the same value limit, policy and canary checks as the online
+// one. An order h refuses is answered with the reason and does not stop
+// the rest of the bundle.
+func SignOffline(ctx context.Context, h *Handler, b *OfflineBundle) (*OfflineSignatures, error) {
+	orders, err := b.Decode()
+	if err != nil {
+		return nil, err
+	}
+	_, _, _, _, addr := h.session.Status()
+	out := &OfflineSignatures{Version: offlineVersion, Bundle: b.ID(), Signer: addr}
+	for i, o := range orders {
+		sig := OfflineSignature{Digest: b.Orders[i].Digest}
+		resp, err := h.SignOrder(ctx, &signerv1.SignOrderRequest{Order: o})
+		if err != nil {
+			sig.Error = status.Convert(err).Message()
+		} else {
+			sig.Signature = resp.Signature
+		}
+		out.Signatures = append(out.Signatures, sig)
+	}
+	return out, nil
+}
+
+// VerifyOffline matches signatures returned by the offline signer to the
+// orders of b and returns the signed ones. Every signature must answer an
+// order of b and recover to that order's signer.
+func VerifyOffline(b *OfflineBundle, sigs *OfflineSignatures) ([]SignedOrder, error) {
+	if sigs.Version != offlineVersion {
+		return nil, fmt.Errorf("unsupported signatures version %d", sigs.Version)
+	}
+	if sigs.Bundle != b.ID() {
+		return nil, fmt.Errorf("%w: signatures answer bundle %s, not %s", ErrBundleMismatch, sigs.Bundle, b.ID())
+	}
+	orders, err := b.Decode()
+	if err != nil {
+		return nil, err
+	}
+	byDigest := make(map[string]*signerv1.PolymarketOrder, len(orders))
+	for i, o := range orders {
+		byDigest[strings.ToLower(b.Orders[i].Digest)] = o
+	}
+
+	var signed []SignedOrder
+	for _, s := range sigs.Signatures {
+		if s.Error != "" {
+			continue
+		}
+		o, ok := byDigest[strings.ToLower(s.Digest)]
+		if !ok {
+			return nil, fmt.Errorf("%w: no order with digest %s", ErrBundleMismatch, s.Digest)
+		}
+		delete(byDigest, strings.ToLower(s.Digest))
+
+		var digest [32]byte
+		raw, err := hex.DecodeString(strings.TrimPrefix(s.Digest, "0x"))
+		if err != nil || len(raw) != len(digest) {
+			return nil, fmt.Errorf("%w: digest %s", ErrInvalidSignature, s.Digest)
+		}
+		copy(digest[:], raw)
+		sig, err := hex.DecodeString(strings.TrimPrefix(s.Signature, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: order %s: %v", ErrInvalidSignature, s.Digest, err)
+		}
+		addr, err := recoverAddress(digest, sig)
+		if err != nil {
+			return nil, fmt.Errorf("order %s: %w", s.Digest, err)
+		}
+		if !strings.EqualFold(addr, orderSigner(o)) {
+			return nil, fmt.Errorf("%w: order %s signed by %s, not %s", ErrInvalidSignature, s.Digest, addr, orderSigner(o))
+		}
+		// The offline signer may use another v convention; the exchange
+		// takes 27/28 and a low s only.
+		if sig, err = NormalizeSignature(sig, VOffsetEthereum); err != nil {
+			return nil, fmt.Errorf("order %s: %w", s.Digest, err)
+		}
+		signed = append(signed, SignedOrder{Order: o, Signature: "0x" + hex.EncodeToString(sig)})
+	}
+	return signed, nil
+}
@@ -0,0 +1,97 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/eip712"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+)
+
+func offlineOrders() []*signerv1.PolymarketOrder {
+	return []*signerv1.PolymarketOrder{
+		{Salt: "1", Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "400", TakerAmount: "800"},
+		{Salt: "2", Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_SELL, TokenId: "2", MakerAmount: "700", TakerAmount: "1400"},
+	}
+}
+
+func TestOfflineRoundTrip(t *testing.T) {
+	bundle, err := NewOfflineBundle(offlineOrders(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The offline session's limit covers the first order only.
+	h := NewHandler(activeSession(t, 500))
+	sigs, err := SignOffline(context.Background(), h, bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sigs.Signer != testMaker || len(sigs.Signatures) != 2 {
+		t.Fatalf("signatures = %+v", sigs)
+	}
+	if sigs.Signatures[0].Signature == "" || sigs.Signatures[1].Error == "" {
+		t.Errorf("expected first signed and second refused, got %+v", sigs.Signatures)
+	}
+
+	signed, err := VerifyOffline(bundle, sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signed) != 1 || signed[0].Order.Salt != "1" {
+		t.Errorf("signed = %+v", signed)
+	}
+}
+
+func TestOfflineRejectsTampering(t *testing.T) {
+	bundle, err := NewOfflineBundle(offlineOrders(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(activeSession(t, 1_000_000))
+
+	// An order edited after export no longer matches its digest.
+	edited := *bundle
+	edited.Orders = append([]OfflineOrder(nil), bundle.Orders...)
+	edited.Orders[0].Order = []byte(`{"salt":"1","maker":"` + testMaker + `","side":"ORDER_SIDE_BUY","tokenId":"1","makerAmount":"40000"}`)
+	if _, err := SignOffline(context.Background(), h, &edited); !errors.Is(err, ErrBundleMismatch) {
+		t.Errorf("edited order: expected ErrBundleMismatch, got %v", err)
+	}
+
+	sigs, err := SignOffline(context.Background(), h, bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Signatures swapped between orders recover to the wrong address.
+	swapped := *sigs
+	swapped.Signatures = []OfflineSignature{
+		{Digest: sigs.Signatures[0].Digest, Signature: sigs.Signatures[1].Signature},
+	}
+	if _, err := VerifyOffline(bundle, &swapped); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("swapped signature: expected ErrInvalidSignature, got %v", err)
+	}
+
+	// Signatures for a different bundle are refused outright.
+	other, _ := NewOfflineBundle(offlineOrders()[:1], time.Now())
+	if _, err := VerifyOffline(other, sigs); !errors.Is(err, ErrBundleMismatch) {
+		t.Errorf("other bundle: expected ErrBundleMismatch, got %v", err)
+	}
+}
+
+func TestRecoverAddress(t *testing.T) {
+	digest := eip712.Keccak256([]byte("recover"))
+	sig, err := signDigest(testKey(), digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr, err := recoverAddress(digest, sig); err != nil || addr != testMaker {
+		t.Errorf("recovered %s, %v", addr, err)
+	}
+	sig[64] = 30
+	if _, err := recoverAddress(digest, sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("bad v: %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrMarketPaused    = errors.New("market is paused")
+	ErrMarketNotPaused = errors.New("market is not paused")
+)
+
+// MarketPausedReason is the ErrorInfo reason of orders refused because
+// their market is paused.
+const MarketPausedReason = "MARKET_PAUSED"
+
+// MarketPause is a market an operator has stopped new orders in.
+type MarketPause struct {
+	// Market is the condition ID, pausing every outcome, or one outcome's
+	// token ID.
+	Market string    `json:"market"`
+	Client string    `json:"client"`
+	Reason string    `json:"reason"`
+	At     time.Time `json:"at"`
+}
+
+// MarketPauseEvent is an audit record of a pause or resume.
+type MarketPauseEvent struct {
+	MarketPause
+	Resumed bool `json:"resumed,omitempty"`
+}
+
+// MarketPauses stops new orders in individual markets, for reacting to
+// news on one event without halting every session. Only order signing is
+// refused: cancels are authenticated with API credentials rather than
+// signed, so they keep working, as do the rest of the signer's RPCs.
+// Pauses are held in memory and shared by every session they are given
+// to.
+type MarketPauses struct {
+	mu      sync.Mutex
+	paused  map[string]MarketPause
+	clock   clock.Clock
+	onEvent func(MarketPauseEvent)
+}
+
+// NewMarketPauses creates an empty set of pauses. onEvent may be nil; a
+// nil clk uses the real clock.
+func NewMarketPauses(clk clock.Clock, onEvent func(MarketPauseEvent)) *MarketPauses {
+	return &MarketPauses{paused: make(map[string]MarketPause), clock: clock.Or(clk), onEvent: onEvent}
+}
+
+// Pause stops new orders in market. Pausing a paused market replaces its
+// reason.
+func (p *MarketPauses) Pause(market, client, reason string) MarketPause {
+	mp := MarketPause{Market: normalizeMarket(market), Client: client, Reason: reason, At: p.clock.Now()}
+	p.mu.Lock()
+	p.paused[mp.Market] = mp
+	p.mu.Unlock()
+	if p.onEvent != nil {
+		p.onEvent(MarketPauseEvent{MarketPause: mp})
+	}
+	return mp
+}
+
+// Resume lets orders into market again.
+func (p *MarketPauses) Resume(market, client string) error {
+	market = normalizeMarket(market)
+	p.mu.Lock()
+	mp, ok := p.paused[market]
+	delete(p.paused, market)
+	p.mu.Unlock()
+	if !ok {
+		return ErrMarketNotPaused
+	}
+	if p.onEvent != nil {
+		p.onEvent(MarketPauseEvent{MarketPause: MarketPause{Market: market, Client: client, Reason: mp.Reason, At: p.clock.Now()}, Resumed: true})
+	}
+	return nil
+}
+
+// Paused returns every paused market, ordered by market.
+func (p *MarketPauses) Paused() []MarketPause {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]MarketPause, 0, len(p.paused))
+	for _, mp := range p.paused {
+		out = append(out, mp)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Market < out[b].Market })
+	return out
+}
+
+// check returns the pause covering an order in conditionID's tokenID
+// outcome, if any.
+func (p *MarketPauses) check(conditionID, tokenID string) (MarketPause, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.paused) == 0 {
+		return MarketPause{}, false
+	}
+	if conditionID != "" {
+		if mp, ok := p.paused[normalizeMarket(conditionID)]; ok {
+			return mp, true
+		}
+	}
+	mp, ok := p.paused[normalizeMarket(tokenID)]
+	return mp, ok
+}
+
+// normalizeMarket lets condition IDs match whatever hex case they were
+// given in; token IDs are decimal and unaffected.
+func normalizeMarket(market string) string {
+	return strings.ToLower(strings.TrimSpace(market))
+}
+
+func marketPausedStatus(mp MarketPause) error {
+	st := status.New(codes.FailedPrecondition, ErrMarketPaused.Error()+": "+mp.Market)
+	info := &errdetails.ErrorInfo{
+		Reason:   MarketPausedReason,
+		Domain:   ErrorDomain,
+		Metadata: map[string]string{"market": mp.Market, "reason": mp.Reason},
+	}
+	if withDetails, err := st.WithDetails(info); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// WithMarketPauses refuses orders in the markets p pauses and serves the
+// pause RPCs.
+func WithMarketPauses(p *MarketPauses) Option {
+	return func(h *Handler) {
+		h.pauses = p
+	}
+}
+
+// PauseMarket stops new orders in one market.
+func (h *Handler) PauseMarket(ctx context.Context, req *signerv1.PauseMarketRequest) (*signerv1.PauseMarketResponse, error) {
+	if h.pauses == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "market pauses are not enabled")
+	}
+	if strings.TrimSpace(req.Market) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "market is required")
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required")
+	}
+	mp := h.pauses.Pause(req.Market, ClientID(ctx), req.Reason)
+	return &signerv1.PauseMarketResponse{PausedAt: mp.At.UnixNano()}, nil
+}
+
+// ResumeMarket lets orders into a paused market again.
+func (h *Handler) ResumeMarket(ctx context.Context, req *signerv1.ResumeMarketRequest) (*signerv1.ResumeMarketResponse, error) {
+	if h.pauses == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "market pauses are not enabled")
+	}
+	if err := h.pauses.Resume(req.Market, ClientID(ctx)); err != nil {
+		return nil, status.Errorf(codes.NotFound, "%v: %s", err, req.Market)
+	}
+	return &signerv1.ResumeMarketResponse{}, nil
+}
+
+// ListPausedMarkets returns the markets new orders are refused in.
+func (h *Handler) ListPausedMarkets(_ context.Context, _ *signerv1.ListPausedMarketsRequest) (*signerv1.ListPausedMarketsResponse, error) {
+	resp := &signerv1.ListPausedMarketsResponse{}
+	if h.pauses == nil {
+		return resp, nil
+	}
+	for _, mp := range h.pauses.Paused() {
+		resp.Markets = append(resp.Markets, &signerv1.PausedMarket{
+			Market:   mp.Market,
+			Reason:   mp.Reason,
+			PausedBy: mp.Client,
+			PausedAt: mp.At.UnixNano(),
+		})
+	}
+	return resp, nil
+}
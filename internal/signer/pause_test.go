@@ -0,0 +1,72 @@
+package signer
+
+import (
+	"context"
+	"testing"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPauseMarket(t *testing.T) {
+	var events []MarketPauseEvent
+	pauses := NewMarketPauses(nil, func(ev MarketPauseEvent) { events = append(events, ev) })
+	h := NewHandler(activeSession(t, 1_000_000), WithMarketPauses(pauses))
+	ctx := context.Background()
+	order := func(condition, token string) *signerv1.SignOrderRequest {
+		return &signerv1.SignOrderRequest{Order: &signerv1.PolymarketOrder{
+			Side: signerv1.OrderSide_ORDER_SIDE_BUY, ConditionId: condition, TokenId: token, MakerAmount: "1000", TakerAmount: "2000",
+		}}
+	}
+
+	if _, err := h.PauseMarket(ctx, &signerv1.PauseMarketRequest{Market: "0xAB"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("no reason: expected InvalidArgument, got %v", err)
+	}
+	if _, err := h.PauseMarket(ctx, &signerv1.PauseMarketRequest{Market: "0xAB", Reason: "candidate withdrew"}); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	if _, err := h.PauseMarket(ctx, &signerv1.PauseMarketRequest{Market: "7", Reason: "injury report"}); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+
+	_, err := h.SignOrder(ctx, order("0xab", "1"))
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("paused condition: expected FailedPrecondition, got %v", err)
+	}
+	var info *errdetails.ErrorInfo
+	for _, d := range status.Convert(err).Details() {
+		info, _ = d.(*errdetails.ErrorInfo)
+	}
+	if info == nil || info.Reason != MarketPausedReason || info.Metadata["market"] != "0xab" || info.Metadata["reason"] != "candidate withdrew" {
+		t.Errorf("error info %v", info)
+	}
+	if _, err := h.SignOrder(ctx, order("0xcd", "7")); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("paused token: expected FailedPrecondition, got %v", err)
+	}
+	if _, err := h.SignOrder(ctx, order("0xcd", "8")); err != nil {
+		t.Errorf("other outcome of a market paused by token: %v", err)
+	}
+	// A batch is all or nothing, so one paused order refuses it.
+	if _, err := h.SignOrders(ctx, &signerv1.SignOrdersRequest{Orders: []*signerv1.SignOrderRequest{order("0xef", "9"), order("0xab", "2")}}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("batch with a paused order: expected FailedPrecondition, got %v", err)
+	}
+
+	list, _ := h.ListPausedMarkets(ctx, &signerv1.ListPausedMarketsRequest{})
+	if len(list.Markets) != 2 || list.Markets[0].Market != "0xab" || list.Markets[1].Market != "7" {
+		t.Errorf("paused markets %v", list.Markets)
+	}
+	if _, err := h.ResumeMarket(ctx, &signerv1.ResumeMarketRequest{Market: "0xAB"}); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if _, err := h.ResumeMarket(ctx, &signerv1.ResumeMarketRequest{Market: "0xab"}); status.Code(err) != codes.NotFound {
+		t.Errorf("resume twice: expected NotFound, got %v", err)
+	}
+	if _, err := h.SignOrder(ctx, order("0xab", "1")); err != nil {
+		t.Errorf("resumed market: %v", err)
+	}
+	if len(events) != 3 || events[0].Market != "0xab" || events[0].Resumed || !events[2].Resumed || events[2].Reason != "candidate withdrew" {
+		t.Errorf("events %+v", events)
+	}
+}
@@ -0,0 +1,191 @@
+package signer
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ClientLabelKey is the optional metadata key a client uses to label itself
+// (e.g. "mm-bot"). Labels are self-asserted unless ClientTokens
+// authenticates them; the kernel-reported UID is the trusted part of the
+// identity.
+const ClientLabelKey = "x-caesar-client"
+
+// ClientTokenKey is the metadata key carrying the token that authenticates
+// a client's label.
+const ClientTokenKey = "x-caesar-client-token"
+
+var ErrClientToken = errors.New("client token missing or wrong for label")
+
+// PeerAuthInfo carries the Unix credentials of the process on the other end
+// of the socket, as reported by the kernel.
+type PeerAuthInfo struct {
+	credentials.CommonAuthInfo
+	UID   uint32
+	PID   int32
+	Known bool
+}
+
+// AuthType implements credentials.AuthInfo.
+func (PeerAuthInfo) AuthType() string {
+	return "unix-peer"
+}
+
+// peerCredentials is a TransportCredentials for UDS listeners that performs
+// no handshake and attaches the connecting process's credentials.
+type peerCredentials struct{}
+
+func (peerCredentials) ClientHandshake(_ context.Context, _ string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return conn, PeerAuthInfo{CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity}}, nil
+}
+
+func (peerCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	info := PeerAuthInfo{CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity}}
+	if uc, ok := conn.(*net.UnixConn); ok {
+		info.UID, info.PID, info.Known = unixPeerCred(uc)
+	}
+	return conn, info, nil
+}
+
+func (peerCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "unix-peer"}
+}
+
+func (c peerCredentials) Clone() credentials.TransportCredentials {
+	return c
+}
+
+func (peerCredentials) OverrideServerName(string) error {
+	return nil
+}
+
+// ClientID derives the caller identity from the peer credentials and the
+// optional client label, e.g. "uid:1000/mm-bot".
+func ClientID(ctx context.Context) string {
+	// Replayed audit records keep the identity they were recorded with.
+	if r, ok := ctx.Value(replayClientKey{}).(replayClient); ok {
+		return r.id
+	}
+	if label := clientLabel(ctx); label != "" {
+		return peerUID(ctx) + "/" + label
+	}
+	return peerUID(ctx)
+}
+
+// quotaClient returns the identity a caller's quota is kept under: its
+// UID, with its label only once ClientTokens has authenticated it. Callers
+// with a self-asserted label share their UID's quota, so relabelling does
+// not buy a fresh one.
+func quotaClient(ctx context.Context) string {
+	if r, ok := ctx.Value(replayClientKey{}).(replayClient); ok {
+		return r.quota
+	}
+	if label, ok := ctx.Value(authenticatedLabelKey{}).(string); ok {
+		return peerUID(ctx) + "/" + label
+	}
+	return peerUID(ctx)
+}
+
+func peerUID(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(PeerAuthInfo); ok && info.Known {
+			return fmt.Sprintf("uid:%d", info.UID)
+		}
+	}
+	return "uid:unknown"
+}
+
+// clientLabel returns the label the caller asserts, or "".
+func clientLabel(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if labels := md.Get(ClientLabelKey); len(labels) > 0 {
+		return labels[0]
+	}
+	return ""
+}
+
+// ClientTokens authenticates client labels: each label maps to the
+// SHA-256 of the token a caller must send under ClientTokenKey to use it.
+// Only labels listed here get quotas of their own.
+type ClientTokens map[string][32]byte
+
+// authenticatedLabelKey carries a label ClientTokens has checked.
+type authenticatedLabelKey struct{}
+
+// WithClientTokens authenticates the labels in tokens on every call. A
+// call claiming one of them without its token is refused.
+func WithClientTokens(tokens ClientTokens) Option {
+	return func(h *Handler) {
+		h.clientTokens = tokens
+	}
+}
+
+// ParseClientTokens parses "label=sha256" entries, comma-separated, where
+// sha256 is the hex digest HashClientToken gives for the label's token.
+func ParseClientTokens(s string) (ClientTokens, error) {
+	tokens := make(ClientTokens)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		label, digest, ok := strings.Cut(entry, "=")
+		label, digest = strings.TrimSpace(label), strings.TrimSpace(digest)
+		raw, err := hex.DecodeString(digest)
+		if !ok || label == "" || err != nil || len(raw) != sha256.Size {
+			return nil, fmt.Errorf("client token %q: want label=<hex SHA-256 of the token>", label)
+		}
+		if _, dup := tokens[label]; dup {
+			return nil, fmt.Errorf("client label %q has two tokens", label)
+		}
+		tokens[label] = [32]byte(raw)
+	}
+	return tokens, nil
+}
+
+// HashClientToken returns the hex SHA-256 of token, as ParseClientTokens
+// expects it.
+func HashClientToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// interceptor returns a unary interceptor that checks the token of every
+// call labelled with one of t's labels, refusing a missing or wrong one
+// with Unauthenticated, and marks the label authenticated for quotas.
+func (t ClientTokens) interceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		label := clientLabel(ctx)
+		want, ok := t[label]
+		if !ok {
+			return handler(ctx, req)
+		}
+		var token string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if v := md.Get(ClientTokenKey); len(v) > 0 {
+				token = v[0]
+			}
+		}
+		got := sha256.Sum256([]byte(token))
+		if subtle.ConstantTimeCompare(got[:], want[:]) != 1 {
+			return nil, status.Errorf(codes.Unauthenticated, "%v: %s", ErrClientToken, label)
+		}
+		return handler(context.WithValue(ctx, authenticatedLabelKey{}, label), req)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build linux
+
+package signer
+
+import (
+	"net"
+	"syscall"
+)
+
+// unixPeerCred reads SO_PEERCRED from the connected socket.
+func unixPeerCred(conn *net.UnixConn) (uid uint32, pid int32, ok bool) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return 0, 0, false
+	}
+
+	var cred *syscall.Ucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil || credErr != nil {
+		return 0, 0, false
+	}
+	return cred.Uid, cred.Pid, true
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package signer
+
+import "net"
+
+// unixPeerCred is unsupported off Linux; callers fall back to an unknown
+// identity.
+func unixPeerCred(*net.UnixConn) (uid uint32, pid int32, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,152 @@
+package signer
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/caesar-terminal/caesar/internal/eip712"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// setApprovalForAllSelector is the ERC-1155 setApprovalForAll(address,bool)
+// function selector.
+var setApprovalForAllSelector = []byte{0xa2, 0x2c, 0xb4, 0x65}
+
+// PermitDigest returns the EIP-2612 digest letting spender move value of
+// the token whose domain is token out of owner's balance until deadline.
+func PermitDigest(token eip712.Domain, owner, spender string, value, nonce *big.Int, deadline int64) ([32]byte, error) {
+	td := &eip712.TypedData{Types: map[string][]eip712.TypedField{"Permit": {
+		{Name: "owner", Type: "address"},
+		{Name: "spender", Type: "address"},
+		{Name: "value", Type: "uint256"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "deadline", Type: "uint256"},
+	}}}
+	return td.MessageDigest(token, "Permit", map[string]any{
+		"owner":    owner,
+		"spender":  spender,
+		"value":    value.String(),
+		"nonce":    nonce.String(),
+		"deadline": fmt.Sprint(deadline),
+	})
+}
+
+// SafeApprovalDigest returns the digest of a Safe (v1.3) transaction in
+// which safe calls setApprovalForAll(operator, approved) on the ERC-1155
+// contract token, with no refund and the given Safe nonce.
+func SafeApprovalDigest(chainID uint64, safe, token, operator string, approved bool, nonce *big.Int) ([32]byte, error) {
+	op, err := eip712.EncodeAddress(operator)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("operator: %w", err)
+	}
+	data := append(append(append([]byte(nil), setApprovalForAllSelector...), op...), eip712.EncodeBool(approved)...)
+	return safeTxDigest(chainID, safe, token, data, nonce)
+}
+
+// safeTxDigest returns the digest of a Safe (v1.3) transaction in which
+// safe calls to with data, sending no value and with no refund.
+func safeTxDigest(chainID uint64, safe, to string, data []byte, nonce *big.Int) ([32]byte, error) {
+	td := &eip712.TypedData{Types: map[string][]eip712.TypedField{"SafeTx": {
+		{Name: "to", Type: "address"},
+		{Name: "value", Type: "uint256"},
+		{Name: "data", Type: "bytes"},
+		{Name: "operation", Type: "uint8"},
+		{Name: "safeTxGas", Type: "uint256"},
+		{Name: "baseGas", Type: "uint256"},
+		{Name: "gasPrice", Type: "uint256"},
+		{Name: "gasToken", Type: "address"},
+		{Name: "refundReceiver", Type: "address"},
+		{Name: "nonce", Type: "uint256"},
+	}}}
+	const zero = "0x0000000000000000000000000000000000000000"
+	return td.MessageDigest(eip712.Domain{ChainID: chainID, VerifyingContract: safe}, "SafeTx", map[string]any{
+		"to":             to,
+		"value":          "0",
+		"data":           "0x" + hex.EncodeToString(data),
+		"operation":      "0",
+		"safeTxGas":      "0",
+		"baseGas":        "0",
+		"gasPrice":       "0",
+		"gasToken":       zero,
+		"refundReceiver": zero,
+		"nonce":          nonce.String(),
+	})
+}
+
+// IsExchange reports whether addr is one of n's exchange contracts.
+func (n Network) IsExchange(addr string) bool {
+	return strings.EqualFold(addr, n.Exchange.VerifyingContract) || strings.EqualFold(addr, n.NegRiskExchange.VerifyingContract)
+}
+
+// SignPermit signs a token authorization for one of the network's
+// exchange contracts. Permits move funds without any order, so they are
+// refused unless enabled with WithPermits, and only ever name an
+// exchange. Like SignTypedData it spends no value limit.
+func (h *Handler) SignPermit(ctx context.Context, req *signerv1.SignPermitRequest) (*signerv1.SignPermitResponse, error) {
+	if !h.permits {
+		return nil, status.Errorf(codes.PermissionDenied, "permit signing is disabled")
+	}
+	_, _, _, _, addr := h.session.Status()
+	if addr == "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "no active session")
+	}
+	if !h.network.IsExchange(req.Spender) {
+		return nil, status.Errorf(codes.PermissionDenied, "spender %s is not an exchange contract on chain %d", req.Spender, h.network.ChainID)
+	}
+	token := req.GetToken()
+	if token.GetVerifyingContract() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token verifying_contract is required")
+	}
+	nonce, ok := new(big.Int).SetString(req.Nonce, 10)
+	if !ok || nonce.Sign() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid nonce %q", req.Nonce)
+	}
+
+	var digest [32]byte
+	var err error
+	switch req.Kind {
+	case signerv1.PermitKind_PERMIT_KIND_ERC20:
+		chainID := uint64(token.ChainId)
+		if chainID == 0 {
+			chainID = h.network.ChainID
+		}
+		if chainID != h.network.ChainID {
+			return nil, status.Errorf(codes.InvalidArgument, "token domain is on chain %d, not %d", chainID, h.network.ChainID)
+		}
+		value, ok := new(big.Int).SetString(req.Value, 10)
+		if !ok || value.Sign() < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid value %q", req.Value)
+		}
+		if req.Deadline <= h.session.Clock().Now().Unix() {
+			return nil, status.Errorf(codes.InvalidArgument, "deadline has passed")
+		}
+		domain := eip712.Domain{Name: token.Name, Version: token.Version, ChainID: chainID, VerifyingContract: token.VerifyingContract}
+		digest, err = PermitDigest(domain, addr, req.Spender, value, nonce, req.Deadline)
+	case signerv1.PermitKind_PERMIT_KIND_ERC1155_APPROVAL:
+		if req.Safe == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "safe is required")
+		}
+		digest, err = SafeApprovalDigest(h.network.ChainID, req.Safe, token.VerifyingContract, req.Spender, req.Approved, nonce)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "permit kind is required")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	sig, err := h.session.Sign(ctx, digest, new(big.Int))
+	if err != nil {
+		return nil, valuelessSignStatus(err)
+	}
+	return &signerv1.SignPermitResponse{
+		Signature: "0x" + hex.EncodeToString(sig),
+		Owner:     addr,
+		Digest:    "0x" + hex.EncodeToString(digest[:]),
+		RequestId: RequestID(ctx),
+	}, nil
+}
@@ -0,0 +1,180 @@
+package signer
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	"github.com/caesar-terminal/caesar/internal/eip712"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testUSDC = "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359"
+	testCTF  = "0x4D97DCd97eC945f40cF65F87097ACe5EA0476045"
+	testSafe = "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"
+)
+
+func TestPermitDigest(t *testing.T) {
+	td, err := eip712.Parse([]byte(`{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Permit": [
+      {"name": "owner", "type": "address"},
+      {"name": "spender", "type": "address"},
+      {"name": "value", "type": "uint256"},
+      {"name": "nonce", "type": "uint256"},
+      {"name": "deadline", "type": "uint256"}
+    ]
+  },
+  "primaryType": "Permit",
+  "domain": {"name": "USD Coin", "version": "2", "chainId": 137, "verifyingContract": "` + testUSDC + `"},
+  "message": {
+    "owner": "` + testMaker + `",
+    "spender": "` + CTFExchange.VerifyingContract + `",
+    "value": "1000000",
+    "nonce": 3,
+    "deadline": 1700000000
+  }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := td.Digest()
+	usdc := eip712.Domain{Name: "USD Coin", Version: "2", ChainID: 137, VerifyingContract: testUSDC}
+	got, err := PermitDigest(usdc, testMaker, CTFExchange.VerifyingContract, big.NewInt(1_000_000), big.NewInt(3), 1_700_000_000)
+	if err != nil || got != want {
+		t.Errorf("PermitDigest = %x, %v; want %x", got, err, want)
+	}
+}
+
+func TestSafeApprovalDigest(t *testing.T) {
+	// setApprovalForAll(exchange, true) calldata.
+	data := "0xa22cb465" +
+		"000000000000000000000000" + strings.ToLower(strings.TrimPrefix(CTFExchange.VerifyingContract, "0x")) +
+		"0000000000000000000000000000000000000000000000000000000000000001"
+	td, err := eip712.Parse([]byte(`{
+  "types": {
+    "EIP712Domain": [
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "SafeTx": [
+      {"name": "to", "type": "address"},
+      {"name": "value", "type": "uint256"},
+      {"name": "data", "type": "bytes"},
+      {"name": "operation", "type": "uint8"},
+      {"name": "safeTxGas", "type": "uint256"},
+      {"name": "baseGas", "type": "uint256"},
+      {"name": "gasPrice", "type": "uint256"},
+      {"name": "gasToken", "type": "address"},
+      {"name": "refundReceiver", "type": "address"},
+      {"name": "nonce", "type": "uint256"}
+    ]
+  },
+  "primaryType": "SafeTx",
+  "domain": {"chainId": 137, "verifyingContract": "` + testSafe + `"},
+  "message": {
+    "to": "` + testCTF + `",
+    "value": 0,
+    "data": "` + data + `",
+    "operation": 0,
+    "safeTxGas": 0,
+    "baseGas": 0,
+    "gasPrice": 0,
+    "gasToken": "0x0000000000000000000000000000000000000000",
+    "refundReceiver": "0x0000000000000000000000000000000000000000",
+    "nonce": 5
+  }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := td.Digest()
+	got, err := SafeApprovalDigest(137, testSafe, testCTF, CTFExchange.VerifyingContract, true, big.NewInt(5))
+	if err != nil || got != want {
+		t.Errorf("SafeApprovalDigest = %x, %v; want %x", got, err, want)
+	}
+	if revoke, _ := SafeApprovalDigest(137, testSafe, testCTF, CTFExchange.VerifyingContract, false, big.NewInt(5)); revoke == want {
+		t.Error("the approval flag is not covered by the digest")
+	}
+}
+
+func TestSignPermit(t *testing.T) {
+	sm := NewSessionManager(time.Hour, WithClock(clock.NewFake(time.Unix(1_700_000_000, 0))))
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1_000)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(sm.Destroy)
+	permit := func() *signerv1.SignPermitRequest {
+		return &signerv1.SignPermitRequest{
+			Kind:     signerv1.PermitKind_PERMIT_KIND_ERC20,
+			Token:    &signerv1.EIP712Domain{Name: "USD Coin", Version: "2", VerifyingContract: testUSDC},
+			Spender:  CTFExchange.VerifyingContract,
+			Value:    "1000000",
+			Nonce:    "0",
+			Deadline: 1_700_000_600,
+		}
+	}
+
+	if _, err := NewHandler(sm).SignPermit(context.Background(), permit()); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("permits disabled: expected PermissionDenied, got %v", err)
+	}
+
+	h := NewHandler(sm, WithPermits(true))
+	resp, err := h.SignPermit(context.Background(), permit())
+	if err != nil {
+		t.Fatalf("sign permit: %v", err)
+	}
+	digest, _ := PermitDigest(eip712.Domain{Name: "USD Coin", Version: "2", ChainID: 137, VerifyingContract: testUSDC},
+		testMaker, CTFExchange.VerifyingContract, big.NewInt(1_000_000), big.NewInt(0), 1_700_000_600)
+	if addr, err := recoverAddress(digest, mustHex(t, resp.Signature[2:])); err != nil || addr != testMaker || resp.Owner != testMaker {
+		t.Errorf("signature recovers to %s, %v", addr, err)
+	}
+	if _, _, _, used, _ := sm.Status(); used != "0" {
+		t.Errorf("permits must not spend the value limit, used = %s", used)
+	}
+
+	approval := &signerv1.SignPermitRequest{
+		Kind:     signerv1.PermitKind_PERMIT_KIND_ERC1155_APPROVAL,
+		Token:    &signerv1.EIP712Domain{VerifyingContract: testCTF},
+		Spender:  NegRiskCTFExchange.VerifyingContract,
+		Approved: true,
+		Nonce:    "1",
+		Safe:     testSafe,
+	}
+	if _, err := h.SignPermit(context.Background(), approval); err != nil {
+		t.Errorf("sign approval: %v", err)
+	}
+
+	for name, bad := range map[string]struct {
+		mutate func(*signerv1.SignPermitRequest)
+		code   codes.Code
+	}{
+		"foreign spender": {func(r *signerv1.SignPermitRequest) { r.Spender = testSafe }, codes.PermissionDenied},
+		"other chain":     {func(r *signerv1.SignPermitRequest) { r.Token.ChainId = 1 }, codes.InvalidArgument},
+		"expired":         {func(r *signerv1.SignPermitRequest) { r.Deadline = 1_700_000_000 }, codes.InvalidArgument},
+		"no token":        {func(r *signerv1.SignPermitRequest) { r.Token = nil }, codes.InvalidArgument},
+		"bad value":       {func(r *signerv1.SignPermitRequest) { r.Value = "-1" }, codes.InvalidArgument},
+		"no kind":         {func(r *signerv1.SignPermitRequest) { r.Kind = signerv1.PermitKind_PERMIT_KIND_UNSPECIFIED }, codes.InvalidArgument},
+		"approval safe": {func(r *signerv1.SignPermitRequest) {
+			r.Kind, r.Safe = signerv1.PermitKind_PERMIT_KIND_ERC1155_APPROVAL, ""
+		}, codes.InvalidArgument},
+	} {
+		req := permit()
+		bad.mutate(req)
+		if _, err := h.SignPermit(context.Background(), req); status.Code(err) != bad.code {
+			t.Errorf("%s: expected %v, got %v", name, bad.code, err)
+		}
+	}
+}
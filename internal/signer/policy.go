@@ -0,0 +1,193 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/book"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"github.com/caesar-terminal/caesar/internal/policy"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the ErrorInfo domain of signer rejections.
+const ErrorDomain = "caesar-terminal.signer"
+
+// PolicyViolationReason is the ErrorInfo reason of policy rejections.
+const PolicyViolationReason = "POLICY_VIOLATION"
+
+// StaleDataReason is the ErrorInfo reason of policy rejections caused by
+// an outdated book rather than by the order itself.
+const StaleDataReason = "STALE_DATA"
+
+// OrderValueExceededReason is the ErrorInfo reason of orders over the
+// session's per-order cap; its metadata carries max_order_value.
+const OrderValueExceededReason = "ORDER_VALUE_EXCEEDED"
+
+// BookSource supplies the latest book for a token. *book.Cache implements
+// it.
+type BookSource interface {
+	Book(tokenID string) (book.CachedBook, bool)
+}
+
+// PolicyRejection describes an order refused by a policy rule, for the
+// audit trail. Shadow is set when the rule is only being trialled and the
+// order was not refused.
+type PolicyRejection struct {
+	Client    string
+	RequestID string
+	TokenID   string
+	Rule      string
+	RuleType  string
+	Threshold string
+	Actual    string
+	Error     string // set when the rule could not be evaluated
+	Stale     bool   // the rule's book was too old to judge against
+	Shadow    bool
+}
+
+// usdcUnit is one whole USDC or outcome share in raw 6-decimal units.
+const usdcUnit = 1_000_000
+
+// orderEnv exposes an order to policy checks. Amounts are converted from
+// raw units to whole USDC and shares so expressions read naturally:
+//
+//	order.side      "BUY" or "SELL"
+//	order.price     USDC per share
+//	order.size      shares
+//	order.value     USDC notional
+//	order.token_id, order.condition_id, client
+//
+// Attributes of the order's market are added as market.<key>. When books
+// has the token's book, it is exposed as well:
+//
+//	book.best_bid     USDC per share
+//	book.best_ask     USDC per share
+//	book.mid          USDC per share, when both sides are quoted
+//	book.age_seconds  since the book last changed
+//	book.warm         restored from disk, not yet refreshed live
+func orderEnv(o *signerv1.PolymarketOrder, client string, markets policy.Markets, books BookSource, now time.Time) policy.Env {
+	maker := rawToFloat(o.MakerAmount)
+	taker := rawToFloat(o.TakerAmount)
+
+	env := policy.Env{
+		"order.token_id":     o.TokenId,
+		"order.condition_id": o.ConditionId,
+		"client":             client,
+	}
+	// A BUY pays USDC (maker) for shares (taker); a SELL is the reverse.
+	usdc, shares := maker, taker
+	env["order.side"] = "BUY"
+	if o.Side == signerv1.OrderSide_ORDER_SIDE_SELL {
+		usdc, shares = taker, maker
+		env["order.side"] = "SELL"
+	}
+	env["order.value"] = usdc
+	env["order.size"] = shares
+	if shares > 0 {
+		env["order.price"] = usdc / shares
+	}
+	markets.AddTo(env, o.TokenId)
+	if books != nil {
+		if b, ok := books.Book(o.TokenId); ok {
+			addBook(env, b, now)
+		}
+	}
+	return env
+}
+
+func addBook(env policy.Env, b book.CachedBook, now time.Time) {
+	env[policy.BookAgeField] = now.Sub(b.At).Seconds()
+	env["book.warm"] = b.Warm
+	if len(b.Bids) > 0 {
+		env["book.best_bid"] = float64(b.Bids[0].Price) / usdcUnit
+	}
+	if len(b.Asks) > 0 {
+		env["book.best_ask"] = float64(b.Asks[0].Price) / usdcUnit
+	}
+	if len(b.Bids) > 0 && len(b.Asks) > 0 {
+		env["book.mid"] = float64(b.Bids[0].Price+b.Asks[0].Price) / 2 / usdcUnit
+	}
+}
+
+func rawToFloat(raw string) float64 {
+	n, ok := new(big.Int).SetString(raw, 10)
+	if !ok {
+		return 0
+	}
+	f, _ := new(big.Rat).SetFrac(n, big.NewInt(usdcUnit)).Float64()
+	return f
+}
+
+// policyStatus converts a rule violation into a FailedPrecondition status
+// that tells the caller which rule fired without a trip to the signer
+// logs. The ErrorInfo metadata carries rule_id, rule_type, threshold and
+// actual; a PreconditionFailure repeats the rule for generic clients.
+func policyStatus(err error) error {
+	var v *policy.Violation
+	if !errors.As(err, &v) {
+		return status.Errorf(codes.FailedPrecondition, "policy: %v", err)
+	}
+	reason := PolicyViolationReason
+	if v.Stale {
+		reason = StaleDataReason
+	}
+	info := &errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: ErrorDomain,
+		Metadata: map[string]string{
+			"rule_id":   v.Rule,
+			"rule_type": string(v.Type),
+			"threshold": v.Threshold,
+			"actual":    v.Actual,
+		},
+	}
+	if v.Err != nil {
+		info.Metadata["error"] = v.Err.Error()
+	}
+	failure := &errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{
+			Type:        "POLICY",
+			Subject:     v.Rule,
+			Description: v.Error(),
+		}},
+	}
+	st := status.New(codes.FailedPrecondition, "policy: "+v.Error())
+	if withDetails, detailErr := st.WithDetails(info, failure); detailErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+func newPolicyRejection(err error, o *signerv1.PolymarketOrder, client, requestID string) PolicyRejection {
+	r := PolicyRejection{Client: client, RequestID: requestID, TokenID: o.TokenId}
+	var v *policy.Violation
+	if errors.As(err, &v) {
+		r.Rule, r.RuleType, r.Threshold, r.Actual, r.Stale, r.Shadow = v.Rule, string(v.Type), v.Threshold, v.Actual, v.Stale, v.Shadow
+		if v.Err != nil {
+			r.Error = v.Err.Error()
+		}
+	} else {
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// orderValueExceededStatus refuses an order over the per-order cap. The
+// cumulative limit is untouched, so a smaller order may still be signed.
+func orderValueExceededStatus(max string) error {
+	st := status.New(codes.OutOfRange, fmt.Sprintf("order value exceeds the per-order cap of %s", max))
+	info := &errdetails.ErrorInfo{
+		Reason:   OrderValueExceededReason,
+		Domain:   ErrorDomain,
+		Metadata: map[string]string{"max_order_value": max},
+	}
+	if withDetails, err := st.WithDetails(info); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
@@ -0,0 +1,114 @@
+package signer
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LimitProfile tightens session limits during a recurring daily window
+// (e.g. overnight). Windows are evaluated in Location (UTC if nil); a
+// window whose start is after its end wraps past midnight.
+type LimitProfile struct {
+	Name string
+	// Start and End are offsets from local midnight in Location.
+	Start    time.Duration
+	End      time.Duration
+	Location *time.Location
+	// LimitPct scales the session's max value limit while active (1–100).
+	LimitPct int64
+	// ApprovalAbove rejects single orders above this value with
+	// ErrApprovalRequired. Nil disables the check.
+	ApprovalAbove *big.Int
+}
+
+// Active reports whether t falls within the profile window.
+func (p LimitProfile) Active(t time.Time) bool {
+	if p.Location != nil {
+		t = t.In(p.Location)
+	} else {
+		t = t.UTC()
+	}
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if p.Start <= p.End {
+		return offset >= p.Start && offset < p.End
+	}
+	return offset >= p.Start || offset < p.End
+}
+
+// scaledLimit applies LimitPct to limit.
+func (p LimitProfile) scaledLimit(limit Amount) Amount {
+	return limit.MulDiv(p.LimitPct, 100)
+}
+
+// ParseLimitProfiles parses a semicolon-separated list of profiles in the
+// form "name=HH:MM-HH:MM/pct[/approvalAbove]", e.g.
+// "night=00:00-08:00/10/50000000". Windows are interpreted in loc (UTC if
+// nil). An empty spec yields no profiles.
+func ParseLimitProfiles(spec string, loc *time.Location) ([]LimitProfile, error) {
+	var profiles []LimitProfile
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, rest, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("limit profile %q: missing name", entry)
+		}
+
+		parts := strings.Split(rest, "/")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("limit profile %q: expected window/pct[/approvalAbove]", name)
+		}
+
+		start, end, err := ParseWindow(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("limit profile %q: %w", name, err)
+		}
+
+		pct, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || pct < 1 || pct > 100 {
+			return nil, fmt.Errorf("limit profile %q: pct must be 1-100", name)
+		}
+
+		p := LimitProfile{Name: name, Start: start, End: end, Location: loc, LimitPct: pct}
+		if len(parts) == 3 {
+			above, ok := new(big.Int).SetString(parts[2], 10)
+			if !ok || above.Sign() < 0 {
+				return nil, fmt.Errorf("limit profile %q: invalid approval threshold", name)
+			}
+			p.ApprovalAbove = above
+		}
+
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// ParseWindow parses a daily "HH:MM-HH:MM" window into offsets from
+// midnight.
+func ParseWindow(s string) (start, end time.Duration, err error) {
+	startStr, endStr, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("window must be HH:MM-HH:MM")
+	}
+	if start, err = parseClock(startStr); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseClock(endStr); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
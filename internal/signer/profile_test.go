@@ -0,0 +1,102 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestParseLimitProfiles(t *testing.T) {
+	profiles, err := ParseLimitProfiles("night=22:30-08:00/10/50000000; lunch=12:00-13:00/50", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(profiles) != 2 {
+		t.Fatalf("expected 2 profiles, got %d", len(profiles))
+	}
+
+	night := profiles[0]
+	if night.Name != "night" || night.Start != 22*time.Hour+30*time.Minute || night.End != 8*time.Hour {
+		t.Errorf("unexpected night window: %+v", night)
+	}
+	if night.LimitPct != 10 || night.ApprovalAbove.Int64() != 50_000_000 {
+		t.Errorf("unexpected night limits: %+v", night)
+	}
+	if profiles[1].ApprovalAbove != nil {
+		t.Errorf("lunch should have no approval threshold")
+	}
+
+	for _, bad := range []string{"night", "=00:00-01:00/10", "n=00:00/10", "n=00:00-25:00/10", "n=00:00-01:00/0", "n=00:00-01:00/10/x"} {
+		if _, err := ParseLimitProfiles(bad, nil); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestLimitProfileActiveWrapsMidnight(t *testing.T) {
+	p := LimitProfile{Start: 22 * time.Hour, End: 8 * time.Hour}
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[time.Duration]bool{
+		21 * time.Hour: false,
+		22 * time.Hour: true,
+		2 * time.Hour:  true,
+		8 * time.Hour:  false,
+	}
+	for offset, want := range cases {
+		if got := p.Active(day.Add(offset)); got != want {
+			t.Errorf("Active at %v = %v, want %v", offset, got, want)
+		}
+	}
+}
+
+func TestSignAppliesActiveProfile(t *testing.T) {
+	sm := NewSessionManager(time.Hour)
+	sm.SetLimitProfiles([]LimitProfile{{
+		Name:          "always",
+		Start:         0,
+		End:           24 * time.Hour,
+		LimitPct:      10,
+		ApprovalAbove: big.NewInt(50),
+	}})
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	defer sm.Destroy()
+
+	if got := sm.ActiveProfile(); got != "always" {
+		t.Errorf("ActiveProfile = %q, want always", got)
+	}
+	if _, err := sm.Sign(context.Background(), [32]byte{}, big.NewInt(60)); !errors.Is(err, ErrApprovalRequired) {
+		t.Errorf("expected ErrApprovalRequired, got %v", err)
+	}
+	if _, err := sm.Sign(context.Background(), [32]byte{}, big.NewInt(50)); err != nil {
+		t.Errorf("expected sign within profile limit, got %v", err)
+	}
+	// Profile limit is 10% of 1000 = 100, so 50 + 50 fits and nothing more does.
+	if _, err := sm.Sign(context.Background(), [32]byte{}, big.NewInt(50)); err != nil {
+		t.Errorf("expected sign up to profile limit, got %v", err)
+	}
+	if _, err := sm.Sign(context.Background(), [32]byte{}, big.NewInt(1)); !errors.Is(err, ErrValueLimitExceeded) {
+		t.Errorf("expected ErrValueLimitExceeded, got %v", err)
+	}
+}
+
+func TestLimitProfileHonorsLocation(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	p := LimitProfile{Start: 0, End: 8 * time.Hour, Location: ny}
+
+	// 06:00 UTC in January is 01:00 in New York: inside the window.
+	if !p.Active(time.Date(2026, 1, 1, 6, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected window active at 01:00 New York time")
+	}
+	// 14:00 UTC is 09:00 in New York: outside the window.
+	if p.Active(time.Date(2026, 1, 1, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected window inactive at 09:00 New York time")
+	}
+}
@@ -0,0 +1,137 @@
+package signer
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+)
+
+var ErrClientQuotaExceeded = errors.New("client quota exceeded")
+
+// ClientUsage is a snapshot of one client's consumption of the session.
+type ClientUsage struct {
+	Orders   int64
+	Notional *big.Int
+}
+
+// QuotaTracker caps how much of a shared session each client identity may
+// consume, so one strategy cannot exhaust the whole session limit. Usage
+// resets whenever a new session is activated.
+type QuotaTracker struct {
+	maxOrders   int64   // 0 = unlimited
+	maxNotional *Amount // nil = unlimited
+
+	mu    sync.Mutex
+	epoch uint64
+	usage map[string]*clientUsage
+}
+
+type clientUsage struct {
+	orders   int64
+	notional Amount
+}
+
+// NewQuotaTracker creates a tracker with per-client caps. A zero maxOrders
+// or nil maxNotional disables that cap. maxNotional is copied.
+func NewQuotaTracker(maxOrders int64, maxNotional *big.Int) (*QuotaTracker, error) {
+	q := &QuotaTracker{
+		maxOrders: maxOrders,
+		usage:     make(map[string]*clientUsage),
+	}
+	if maxNotional != nil {
+		limit, err := NewAmount(maxNotional)
+		if err != nil {
+			return nil, err
+		}
+		q.maxNotional = &limit
+	}
+	return q, nil
+}
+
+// forSession returns an empty tracker with q's caps, for another session.
+func (q *QuotaTracker) forSession() *QuotaTracker {
+	return &QuotaTracker{maxOrders: q.maxOrders, maxNotional: q.maxNotional, usage: make(map[string]*clientUsage)}
+}
+
+// Reserve claims one order and value against client's quota for the
+// session identified by epoch. Call Release if signing then fails.
+func (q *QuotaTracker) Reserve(epoch uint64, client string, value *big.Int) error {
+	v, err := NewAmount(value)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if epoch != q.epoch {
+		q.epoch = epoch
+		q.usage = make(map[string]*clientUsage)
+	}
+
+	u, ok := q.usage[client]
+	if !ok {
+		u = &clientUsage{}
+		q.usage[client] = u
+	}
+
+	if q.maxOrders > 0 && u.orders+1 > q.maxOrders {
+		return ErrClientQuotaExceeded
+	}
+	newNotional, err := u.notional.Add(v)
+	if err != nil || (q.maxNotional != nil && newNotional.Cmp(*q.maxNotional) > 0) {
+		return ErrClientQuotaExceeded
+	}
+
+	u.orders++
+	u.notional = newNotional
+	return nil
+}
+
+// Release returns a reservation made under epoch.
+func (q *QuotaTracker) Release(epoch uint64, client string, value *big.Int) {
+	v, err := NewAmount(value)
+	if err != nil {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	u, ok := q.usage[client]
+	if !ok || epoch != q.epoch {
+		return
+	}
+	u.orders--
+	u.notional = u.notional.Sub(v)
+}
+
+// Credit returns value to client's notional usage under epoch, for an
+// order whose value was released after signing. The order stays counted.
+func (q *QuotaTracker) Credit(epoch uint64, client string, value *big.Int) {
+	v, err := NewAmount(value)
+	if err != nil {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	u, ok := q.usage[client]
+	if !ok || epoch != q.epoch {
+		return
+	}
+	u.notional = u.notional.Sub(v)
+}
+
+// Usage returns a copy of client's current usage.
+func (q *QuotaTracker) Usage(client string) ClientUsage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	u, ok := q.usage[client]
+	if !ok {
+		return ClientUsage{Notional: new(big.Int)}
+	}
+	return ClientUsage{Orders: u.orders, Notional: u.notional.BigInt()}
+}
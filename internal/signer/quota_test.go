@@ -0,0 +1,122 @@
+package signer
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestQuotaTrackerCapsAndResets(t *testing.T) {
+	q, err := NewQuotaTracker(2, big.NewInt(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Reserve(1, "a", big.NewInt(60)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := q.Reserve(1, "a", big.NewInt(50)); !errors.Is(err, ErrClientQuotaExceeded) {
+		t.Errorf("expected notional cap, got %v", err)
+	}
+	if err := q.Reserve(1, "b", big.NewInt(50)); err != nil {
+		t.Errorf("other clients should have their own quota, got %v", err)
+	}
+	if err := q.Reserve(1, "a", big.NewInt(40)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := q.Reserve(1, "a", big.NewInt(0)); !errors.Is(err, ErrClientQuotaExceeded) {
+		t.Errorf("expected order-count cap, got %v", err)
+	}
+
+	q.Release(1, "a", big.NewInt(40))
+	if u := q.Usage("a"); u.Orders != 1 || u.Notional.Int64() != 60 {
+		t.Errorf("unexpected usage after release: %+v", u)
+	}
+
+	// A new activation epoch starts every client from zero.
+	if err := q.Reserve(2, "a", big.NewInt(100)); err != nil {
+		t.Errorf("expected reset on new epoch, got %v", err)
+	}
+}
+
+func TestServerQuotaPerClientLabel(t *testing.T) {
+	sm := activeSession(t, 1_000_000)
+	socket := filepath.Join(t.TempDir(), "signer.sock")
+
+	quotas, err := NewQuotaTracker(1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := ParseClientTokens("mm=" + HashClientToken("mm-secret") + ", hedger=" + HashClientToken("hedger-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := New(socket, sm, WithQuotas(quotas), WithClientTokens(tokens))
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	go srv.Serve()
+	defer srv.GracefulStop()
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := signerv1.NewSignerServiceClient(conn)
+
+	sign := func(label, token string) error {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), ClientLabelKey, label, ClientTokenKey, token)
+		_, err := client.SignOrder(ctx, &signerv1.SignOrderRequest{
+			Order: &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "1"},
+		})
+		return err
+	}
+
+	if err := sign("mm", "hedger-secret"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated for another label's token, got %v", err)
+	}
+	if err := sign("mm", "mm-secret"); err != nil {
+		t.Fatalf("first order: %v", err)
+	}
+	if err := sign("mm", "mm-secret"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected ResourceExhausted for same client, got %v", err)
+	}
+	if err := sign("hedger", "hedger-secret"); err != nil {
+		t.Errorf("expected separate quota for another client, got %v", err)
+	}
+
+	// Labels without tokens are self-asserted: they share the UID's quota,
+	// so a new label does not buy a new one.
+	if err := sign("bot-1", ""); err != nil {
+		t.Fatalf("first unauthenticated order: %v", err)
+	}
+	if err := sign("bot-2", ""); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected ResourceExhausted for a relabelled client, got %v", err)
+	}
+}
+
+func TestParseClientTokens(t *testing.T) {
+	for _, spec := range []string{"mm", "mm=abc", "=" + HashClientToken("x"), "mm=" + HashClientToken("x") + ",mm=" + HashClientToken("y")} {
+		if _, err := ParseClientTokens(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+	tokens, err := ParseClientTokens(" mm = " + HashClientToken("x") + " ,, ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens["mm"] != sha256.Sum256([]byte("x")) {
+		t.Errorf("tokens %v", tokens)
+	}
+}
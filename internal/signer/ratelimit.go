@@ -0,0 +1,110 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrOrderCountExceeded = errors.New("session order count limit reached")
+	ErrSignRateExceeded   = errors.New("session signing rate exceeded")
+)
+
+// signBucket is a token bucket holding up to a minute's worth of
+// signatures, refilled continuously at perMinute a minute.
+type signBucket struct {
+	perMinute int
+	tokens    float64
+	last      time.Time
+}
+
+// available refills the bucket to now and returns the whole tokens in it.
+func (b *signBucket) available(now time.Time) int {
+	if b.last.IsZero() {
+		b.tokens = float64(b.perMinute)
+	} else if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Minutes() * float64(b.perMinute)
+		if b.tokens > float64(b.perMinute) {
+			b.tokens = float64(b.perMinute)
+		}
+	}
+	b.last = now
+	return int(b.tokens)
+}
+
+// SetOrderLimits caps how many orders a session may sign in total and how
+// fast, so a runaway bot loop is stopped after a bounded number of orders
+// rather than when it has spent the value limit. A zero maxOrders or
+// signsPerMinute disables that cap. Signing is allowed in bursts of up to a
+// minute's worth. Only signatures carrying value count as orders toward
+// maxOrders; the rate counts every signature. They apply to the current
+// and all future sessions; orders already signed in the current session
+// count.
+func (sm *SessionManager) SetOrderLimits(maxOrders int64, signsPerMinute int) error {
+	if maxOrders < 0 || signsPerMinute < 0 {
+		return fmt.Errorf("order limits must not be negative")
+	}
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.maxOrders = maxOrders
+	sm.signRate = signBucket{perMinute: signsPerMinute}
+	return nil
+}
+
+// OrderCount returns how many orders the session has signed and the most
+// it may sign, 0 when uncapped.
+func (sm *SessionManager) OrderCount() (signed, max int64) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.ordersSigned, sm.orderCapLocked()
+}
+
+// SignRate returns the most orders the session may sign a minute, 0 when
+// unlimited.
+func (sm *SessionManager) SignRate() int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.signRate.perMinute
+}
+
+// checkOrderLimitsLocked refuses n more signatures at now, orders of them
+// carrying value, if they would pass the order count or the signing rate.
+// Caller must hold sm.mu.
+func (sm *SessionManager) checkOrderLimitsLocked(now time.Time, n, orders int) error {
+	if max := sm.orderCapLocked(); max > 0 && sm.ordersSigned+int64(orders) > max {
+		return fmt.Errorf("%w: %d of %d signed", ErrOrderCountExceeded, sm.ordersSigned, max)
+	}
+	if sm.signRate.perMinute > 0 && sm.signRate.available(now) < n {
+		return fmt.Errorf("%w: at most %d a minute", ErrSignRateExceeded, sm.signRate.perMinute)
+	}
+	return nil
+}
+
+// countOrdersLocked records n signatures, orders of them carrying value,
+// after checkOrderLimitsLocked allowed them. Caller must hold sm.mu.
+func (sm *SessionManager) countOrdersLocked(n, orders int) {
+	sm.ordersSigned += int64(orders)
+	if sm.signRate.perMinute > 0 {
+		sm.signRate.tokens -= float64(n)
+	}
+}
+
+// resetOrderLimitsLocked starts a new session's count with a full bucket.
+// Caller must hold sm.mu.
+func (sm *SessionManager) resetOrderLimitsLocked() {
+	sm.ordersSigned = 0
+	sm.signRate = signBucket{perMinute: sm.signRate.perMinute}
+}
+
+// orderLimitStatus maps a refusal by the session's rolling windows, order
+// count or signing rate, and returns nil for any other error.
+func orderLimitStatus(err error) error {
+	if errors.Is(err, ErrWindowLimitExceeded) || errors.Is(err, ErrOrderCountExceeded) || errors.Is(err, ErrSignRateExceeded) {
+		return status.Errorf(codes.ResourceExhausted, "%v", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSessionSignRate(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	sm := NewSessionManager(2*time.Hour, WithClock(clk))
+	if err := sm.SetOrderLimits(0, 6); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1_000_000)); err != nil {
+		t.Fatal(err)
+	}
+	defer sm.Destroy()
+	ctx := context.Background()
+
+	// A burst of a minute's worth, then nothing until the bucket refills.
+	if _, err := sm.SignBatch(ctx, [][32]byte{{1}, {2}, {3}, {4}}, []*big.Int{big.NewInt(1), big.NewInt(1), big.NewInt(1), big.NewInt(1)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.SignBatch(ctx, [][32]byte{{5}, {6}, {7}}, []*big.Int{big.NewInt(1), big.NewInt(1), big.NewInt(1)}); !errors.Is(err, ErrSignRateExceeded) {
+		t.Fatalf("batch over the rate: expected ErrSignRateExceeded, got %v", err)
+	}
+	for i := byte(5); i < 7; i++ {
+		if _, err := sm.Sign(ctx, [32]byte{i}, big.NewInt(1)); err != nil {
+			t.Fatalf("order %d: %v", i, err)
+		}
+	}
+	if _, err := sm.Sign(ctx, [32]byte{7}, big.NewInt(1)); !errors.Is(err, ErrSignRateExceeded) {
+		t.Fatalf("expected ErrSignRateExceeded, got %v", err)
+	}
+	if signed, _ := sm.OrderCount(); signed != 6 {
+		t.Errorf("a refused order was counted: %d signed", signed)
+	}
+	clk.Advance(10 * time.Second) // one token
+	if _, err := sm.Sign(ctx, [32]byte{7}, big.NewInt(1)); err != nil {
+		t.Errorf("after refilling: %v", err)
+	}
+	if _, err := sm.Sign(ctx, [32]byte{8}, big.NewInt(1)); !errors.Is(err, ErrSignRateExceeded) {
+		t.Errorf("expected ErrSignRateExceeded, got %v", err)
+	}
+
+	// An idle hour refills no more than a minute's worth.
+	clk.Advance(time.Hour)
+	for i := byte(8); i < 14; i++ {
+		if _, err := sm.Sign(ctx, [32]byte{i}, big.NewInt(1)); err != nil {
+			t.Fatalf("order %d: %v", i, err)
+		}
+	}
+	if _, err := sm.Sign(ctx, [32]byte{14}, big.NewInt(1)); !errors.Is(err, ErrSignRateExceeded) {
+		t.Errorf("after idling: expected ErrSignRateExceeded, got %v", err)
+	}
+}
+
+func TestSessionOrderCount(t *testing.T) {
+	sm := activeSession(t, 1_000_000)
+	if err := sm.SetOrderLimits(3, 0); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := sm.SignBatch(ctx, [][32]byte{{1}, {2}}, []*big.Int{big.NewInt(1), big.NewInt(1)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.SignBatch(ctx, [][32]byte{{3}, {4}}, []*big.Int{big.NewInt(1), big.NewInt(1)}); !errors.Is(err, ErrOrderCountExceeded) {
+		t.Errorf("batch over the count: expected ErrOrderCountExceeded, got %v", err)
+	}
+	if _, err := sm.Sign(ctx, [32]byte{3}, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(sm)
+	_, err := h.SignOrder(ctx, &signerv1.SignOrderRequest{
+		Order: &signerv1.PolymarketOrder{Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "100", TakerAmount: "200"},
+	})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("SignOrder over the count: expected ResourceExhausted, got %v", err)
+	}
+	st, _ := h.GetSessionStatus(ctx, &signerv1.GetSessionStatusRequest{})
+	if st.OrdersSigned != 3 || st.MaxOrders != 3 {
+		t.Errorf("status: %d of %d orders", st.OrdersSigned, st.MaxOrders)
+	}
+
+	// A new session starts counting again.
+	if err := sm.Activate(ctx, testKey(), big.NewInt(1_000_000)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.Sign(ctx, [32]byte{4}, big.NewInt(1)); err != nil {
+		t.Errorf("new session: %v", err)
+	}
+	if err := sm.SetOrderLimits(-1, 0); err == nil {
+		t.Error("negative order limit accepted")
+	}
+}
+
+func TestSessionOrderCountSkipsValuelessSignatures(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	sm := NewSessionManager(2*time.Hour, WithClock(clk))
+	if err := sm.SetOrderLimits(1, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1_000_000)); err != nil {
+		t.Fatal(err)
+	}
+	defer sm.Destroy()
+	ctx := context.Background()
+
+	// CLOB auth, permits and typed data sign with no value: they spend the
+	// rate but leave the order cap alone.
+	for i := byte(1); i <= 2; i++ {
+		if _, err := sm.Sign(ctx, [32]byte{i}, new(big.Int)); err != nil {
+			t.Fatalf("valueless signature %d: %v", i, err)
+		}
+	}
+	if signed, _ := sm.OrderCount(); signed != 0 {
+		t.Errorf("valueless signatures counted as %d orders", signed)
+	}
+	if _, err := sm.Sign(ctx, [32]byte{3}, big.NewInt(1)); err != nil {
+		t.Fatalf("order: %v", err)
+	}
+	if _, err := sm.Sign(ctx, [32]byte{4}, big.NewInt(1)); !errors.Is(err, ErrOrderCountExceeded) {
+		t.Errorf("expected ErrOrderCountExceeded, got %v", err)
+	}
+	if _, err := sm.Sign(ctx, [32]byte{5}, new(big.Int)); err != nil {
+		t.Errorf("valueless signature at the order cap: %v", err)
+	}
+	if _, err := sm.Sign(ctx, [32]byte{6}, new(big.Int)); !errors.Is(err, ErrSignRateExceeded) {
+		t.Errorf("valueless signatures should spend the rate, got %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrRecoveryPending      = errors.New("signing is paused until the recovery report is acknowledged")
+	ErrNothingToAcknowledge = errors.New("no recovery report awaits acknowledgement")
+)
+
+// RecoveryPendingReason is the ErrorInfo reason of signing requests
+// refused until the recovery report is acknowledged.
+const RecoveryPendingReason = "RECOVERY_UNACKNOWLEDGED"
+
+// ExchangeNotReconciled is the exchange reconciliation outcome while the
+// signer has no exchange client to ask about unknown orders.
+const ExchangeNotReconciled = "not reconciled: the signer has no exchange client; check the unknown orders on the exchange"
+
+// RecoveryReport describes what an unclean shutdown left behind: the
+// session ledgers the journal reconstructed and the orders whose fate the
+// signer cannot know.
+type RecoveryReport struct {
+	Unclean    bool
+	DetectedAt time.Time
+	// Ledgers are the last journaled state of every session the shutdown
+	// left open; activating the same key resumes them.
+	Ledgers []JournalEntry
+	// UnknownOrders are the most recent orders those sessions signed,
+	// 0x-hex digests. They may have been submitted, filled or never sent.
+	UnknownOrders []string
+	Exchange      string
+
+	AckRequired    bool
+	AcknowledgedAt time.Time
+	AcknowledgedBy string
+	AckReason      string
+}
+
+// Recovery holds the startup recovery report and, when acknowledgement is
+// required, pauses signing until an operator has read it.
+type Recovery struct {
+	mu     sync.Mutex
+	report RecoveryReport
+	clock  clock.Clock
+}
+
+// NewRecovery builds the recovery report from what j recovered. A journal
+// that recovered nothing means the last shutdown was clean. requireAck
+// pauses signing after an unclean shutdown until Acknowledge is called.
+func NewRecovery(j *Journal, requireAck bool, clk clock.Clock) *Recovery {
+	r := &Recovery{clock: clock.Or(clk)}
+	ledgers := j.Recovered()
+	if len(ledgers) == 0 {
+		return r
+	}
+	r.report = RecoveryReport{
+		Unclean:     true,
+		DetectedAt:  r.clock.Now(),
+		Ledgers:     ledgers,
+		Exchange:    ExchangeNotReconciled,
+		AckRequired: requireAck,
+	}
+	for _, e := range ledgers {
+		r.report.UnknownOrders = append(r.report.UnknownOrders, e.Orders...)
+	}
+	return r
+}
+
+// Report returns the recovery report.
+func (r *Recovery) Report() RecoveryReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.report
+}
+
+// Pending reports whether signing is paused awaiting acknowledgement.
+func (r *Recovery) Pending() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pendingLocked()
+}
+
+func (r *Recovery) pendingLocked() bool {
+	return r.report.Unclean && r.report.AckRequired && r.report.AcknowledgedAt.IsZero()
+}
+
+// Acknowledge records that client has read the report, resuming signing.
+func (r *Recovery) Acknowledge(client, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.report.Unclean || !r.report.AcknowledgedAt.IsZero() {
+		return ErrNothingToAcknowledge
+	}
+	r.report.AcknowledgedAt = r.clock.Now()
+	r.report.AcknowledgedBy = client
+	r.report.AckReason = reason
+	return nil
+}
+
+// interceptor refuses the signing RPCs while acknowledgement is pending.
+// Everything else, the status and recovery RPCs included, still runs.
+func (r *Recovery) interceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		if strings.HasPrefix(method, "Sign") && r.Pending() {
+			return nil, recoveryPendingStatus()
+		}
+		return handler(ctx, req)
+	}
+}
+
+func recoveryPendingStatus() error {
+	st := status.New(codes.FailedPrecondition, ErrRecoveryPending.Error()+"; see caesarctl recovery")
+	info := &errdetails.ErrorInfo{Reason: RecoveryPendingReason, Domain: ErrorDomain}
+	if withDetails, err := st.WithDetails(info); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// WithRecovery serves the startup recovery report and, when it requires
+// acknowledgement, refuses to sign until it has been acknowledged.
+func WithRecovery(r *Recovery) Option {
+	return func(h *Handler) {
+		h.recovery = r
+	}
+}
+
+// RecoveryAck records an operator resuming signing after an unclean
+// shutdown, for the audit trail.
+type RecoveryAck struct {
+	Client        string    `json:"client"`
+	RequestID     string    `json:"request_id,omitempty"`
+	Reason        string    `json:"reason"`
+	Ledgers       int       `json:"ledgers"`
+	UnknownOrders int       `json:"unknown_orders"`
+	At            time.Time `json:"at"`
+}
+
+// WithRecoveryAudit reports every acknowledgement of the recovery report.
+func WithRecoveryAudit(fn func(RecoveryAck)) Option {
+	return func(h *Handler) {
+		h.onAck = fn
+	}
+}
+
+// GetRecoveryReport returns what the last unclean shutdown left behind.
+func (h *Handler) GetRecoveryReport(_ context.Context, _ *signerv1.GetRecoveryReportRequest) (*signerv1.GetRecoveryReportResponse, error) {
+	if h.recovery == nil {
+		return &signerv1.GetRecoveryReportResponse{}, nil
+	}
+	rep := h.recovery.Report()
+	resp := &signerv1.GetRecoveryReportResponse{
+		UncleanShutdown:         rep.Unclean,
+		UnknownOrders:           rep.UnknownOrders,
+		ExchangeReconciliation:  rep.Exchange,
+		AcknowledgementRequired: rep.AckRequired,
+		AcknowledgedBy:          rep.AcknowledgedBy,
+		AcknowledgementReason:   rep.AckReason,
+	}
+	if !rep.DetectedAt.IsZero() {
+		resp.DetectedAt = rep.DetectedAt.UnixNano()
+	}
+	if !rep.AcknowledgedAt.IsZero() {
+		resp.AcknowledgedAt = rep.AcknowledgedAt.UnixNano()
+	}
+	for _, e := range rep.Ledgers {
+		resp.Ledgers = append(resp.Ledgers, &signerv1.RecoveredLedger{
+			SessionId:      e.Session,
+			SessionAddress: e.Address,
+			MaxValueLimit:  e.Limit,
+			ValueUsed:      e.Used,
+			ExpiresAt:      e.ExpiresAt.UnixNano(),
+		})
+	}
+	return resp, nil
+}
+
+// AcknowledgeRecovery resumes signing after an unclean shutdown. With
+// elevation configured it takes the operator credential, like
+// RenewSession; either way the reason is required and audited. It is
+// accepted even when acknowledgement is not required, to mark the report
+// as read.
+func (h *Handler) AcknowledgeRecovery(ctx context.Context, req *signerv1.AcknowledgeRecoveryRequest) (*signerv1.AcknowledgeRecoveryResponse, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required")
+	}
+	if h.recovery == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", ErrNothingToAcknowledge)
+	}
+	if h.elevator != nil {
+		if err := h.elevator.Reauthenticate(ClientID(ctx), req.Credential, "acknowledge-recovery", req.Reason); err != nil {
+			return nil, elevationStatus(err)
+		}
+	}
+	if err := h.recovery.Acknowledge(ClientID(ctx), req.Reason); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+	rep := h.recovery.Report()
+	if h.onAck != nil {
+		h.onAck(RecoveryAck{
+			Client:        rep.AcknowledgedBy,
+			RequestID:     RequestID(ctx),
+			Reason:        rep.AckReason,
+			Ledgers:       len(rep.Ledgers),
+			UnknownOrders: len(rep.UnknownOrders),
+			At:            rep.AcknowledgedAt,
+		})
+	}
+	return &signerv1.AcknowledgeRecoveryResponse{AcknowledgedAt: rep.AcknowledgedAt.UnixNano()}, nil
+}
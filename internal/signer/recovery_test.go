@@ -0,0 +1,110 @@
+package signer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecoveryReport(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	rec := NewRecovery(crashedJournal(t, clk), true, clk)
+	rep := rec.Report()
+	if !rep.Unclean || !rec.Pending() {
+		t.Fatalf("report %+v, pending %v", rep, rec.Pending())
+	}
+	if len(rep.Ledgers) != 1 || rep.Ledgers[0].Session != "arb" || rep.Ledgers[0].Used != "400" {
+		t.Errorf("ledgers %+v", rep.Ledgers)
+	}
+	if want := "0x01" + "00000000000000000000000000000000000000000000000000000000000000"; len(rep.UnknownOrders) != 1 || rep.UnknownOrders[0] != want {
+		t.Errorf("unknown orders %v, want [%s]", rep.UnknownOrders, want)
+	}
+	if rep.Exchange != ExchangeNotReconciled {
+		t.Errorf("exchange %q", rep.Exchange)
+	}
+
+	j, err := OpenJournal(filepath.Join(t.TempDir(), "ledger.journal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if clean := NewRecovery(j, true, clk); clean.Report().Unclean || clean.Pending() {
+		t.Errorf("clean shutdown reported %+v", clean.Report())
+	}
+	if optional := NewRecovery(crashedJournal(t, clk), false, clk); !optional.Report().Unclean || optional.Pending() {
+		t.Errorf("ack not required: unclean=%v pending=%v", optional.Report().Unclean, optional.Pending())
+	}
+}
+
+func TestRecoveryGatesSigning(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	rec := NewRecovery(crashedJournal(t, clk), true, clk)
+	var acks []RecoveryAck
+	h := NewHandler(activeSession(t, 1_000_000), WithRecovery(rec), WithRecoveryAudit(func(a RecoveryAck) { acks = append(acks, a) }))
+	ctx := context.Background()
+
+	call := func(method string) error {
+		_, err := rec.interceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/signer.v1.SignerService/" + method},
+			func(context.Context, any) (any, error) { return nil, nil })
+		return err
+	}
+	for _, m := range []string{"SignOrder", "SignOrders", "SignTypedData"} {
+		if err := call(m); status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("%s while pending: expected FailedPrecondition, got %v", m, err)
+		}
+	}
+	if err := call("GetSessionStatus"); err != nil {
+		t.Errorf("status while pending: %v", err)
+	}
+	if st, _ := h.GetSessionStatus(ctx, &signerv1.GetSessionStatusRequest{}); !st.RecoveryPending {
+		t.Error("status does not report the pending recovery")
+	}
+	if rep, _ := h.GetRecoveryReport(ctx, &signerv1.GetRecoveryReportRequest{}); !rep.UncleanShutdown || !rep.AcknowledgementRequired || len(rep.Ledgers) != 1 || len(rep.UnknownOrders) != 1 {
+		t.Errorf("report %+v", rep)
+	}
+
+	if _, err := h.AcknowledgeRecovery(ctx, &signerv1.AcknowledgeRecoveryRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("no reason: expected InvalidArgument, got %v", err)
+	}
+	if _, err := h.AcknowledgeRecovery(ctx, &signerv1.AcknowledgeRecoveryRequest{Reason: "orders checked on the exchange"}); err != nil {
+		t.Fatalf("acknowledge: %v", err)
+	}
+	if err := call("SignOrder"); err != nil {
+		t.Errorf("sign after acknowledgement: %v", err)
+	}
+	if _, err := h.AcknowledgeRecovery(ctx, &signerv1.AcknowledgeRecoveryRequest{Reason: "again"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("acknowledged twice: expected FailedPrecondition, got %v", err)
+	}
+	if len(acks) != 1 || acks[0].Reason != "orders checked on the exchange" || acks[0].UnknownOrders != 1 || acks[0].At.IsZero() {
+		t.Errorf("audited %+v", acks)
+	}
+	if rep, _ := h.GetRecoveryReport(ctx, &signerv1.GetRecoveryReportRequest{}); rep.AcknowledgedAt == 0 || rep.AcknowledgementReason != "orders checked on the exchange" {
+		t.Errorf("report after acknowledgement %+v", rep)
+	}
+}
+
+func TestRecoveryAcknowledgementNeedsCredential(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	rec := NewRecovery(crashedJournal(t, clk), true, clk)
+	h := NewHandler(activeSession(t, 1_000_000), WithRecovery(rec), WithElevation(testElevator(t, clk, nil)))
+	ctx := context.Background()
+	if _, err := h.AcknowledgeRecovery(ctx, &signerv1.AcknowledgeRecoveryRequest{Credential: "wrong", Reason: "checked"}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("wrong credential: expected Unauthenticated, got %v", err)
+	}
+	if !rec.Pending() {
+		t.Fatal("acknowledged without the credential")
+	}
+	if _, err := h.AcknowledgeRecovery(ctx, &signerv1.AcknowledgeRecoveryRequest{Credential: "open sesame", Reason: "checked"}); err != nil {
+		t.Fatalf("acknowledge: %v", err)
+	}
+	if rec.Pending() {
+		t.Error("still pending after acknowledgement")
+	}
+}
@@ -0,0 +1,237 @@
+package signer
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrNotReleasable       = errors.New("no releasable order with this hash in the session")
+	ErrReleaseExceedsOrder = errors.New("release exceeds the order's unreleased value")
+	ErrReleaseDisabled     = errors.New("value release is disabled")
+)
+
+// releasableOrders bounds how many signed orders a session remembers for
+// ReleaseValue. Older orders can no longer be released; a cancel or fill
+// is normally reported long before this many more orders are signed.
+const releasableOrders = 1 << 16
+
+// releaseLedger remembers the unreleased value of the session's most
+// recent signed orders, by digest.
+type releaseLedger struct {
+	values map[[32]byte]releasable
+	ring   [][32]byte // digests in signing order, oldest at next once full
+	next   int
+}
+
+// releasable is a signed order's unreleased value, when it was last
+// signed, which finds the rolling window buckets its value was spent in,
+// and the client whose quota it was reserved from.
+type releasable struct {
+	value    Amount
+	signedAt time.Time
+	client   string
+}
+
+// record adds value signed at for client to digest's releasable value.
+func (l *releaseLedger) record(digest [32]byte, value Amount, at time.Time, client string) {
+	if value.IsZero() {
+		return
+	}
+	if l.values == nil {
+		l.values = make(map[[32]byte]releasable)
+	}
+	if prev, ok := l.values[digest]; ok {
+		if sum, err := prev.value.Add(value); err == nil {
+			l.values[digest] = releasable{value: sum, signedAt: at, client: client}
+		}
+		return
+	}
+	if len(l.ring) < releasableOrders {
+		l.ring = append(l.ring, digest)
+	} else {
+		delete(l.values, l.ring[l.next])
+		l.ring[l.next] = digest
+		l.next = (l.next + 1) % releasableOrders
+	}
+	l.values[digest] = releasable{value: value, signedAt: at, client: client}
+}
+
+// ValueRelease records value credited back to a session's ledger for an
+// order that was cancelled or did not fill, for the audit trail.
+type ValueRelease struct {
+	Session   string    `json:"session,omitempty"`
+	Client    string    `json:"client"`
+	RequestID string    `json:"request_id,omitempty"`
+	OrderHash string    `json:"order_hash"`
+	Released  string    `json:"released"`
+	ValueUsed string    `json:"value_used"`
+	Reason    string    `json:"reason"`
+	At        time.Time `json:"at"`
+}
+
+// Release credits amount of a signed order's value back to the session's
+// cumulative limit, for an order that was cancelled or only partly
+// filled. A nil amount releases all of the order's unreleased value. Only
+// orders signed in the current session can be released, each at most up
+// to the value it was signed for. The release is also credited to the
+// rolling windows the order was signed in, while they still track it. It
+// returns the amount released and the value used afterwards.
+func (sm *SessionManager) Release(ctx context.Context, digest [32]byte, amount *big.Int) (released, used Amount, err error) {
+	c, err := sm.release(ctx, digest, amount)
+	return c.released, c.used, err
+}
+
+// valueCredit is the outcome of a release: what was released, the value
+// used afterwards, and the client quota and session epoch it came from.
+type valueCredit struct {
+	released, used Amount
+	client         string
+	epoch          uint64
+}
+
+func (sm *SessionManager) release(ctx context.Context, digest [32]byte, amount *big.Int) (valueCredit, error) {
+	var want Amount
+	if amount != nil {
+		var err error
+		if want, err = NewAmount(amount); err != nil {
+			return valueCredit{}, err
+		}
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return valueCredit{}, err
+	}
+	if sm.key == nil {
+		return valueCredit{}, ErrNoActiveSession
+	}
+	if sm.isExpired() {
+		sm.destroyLocked()
+		return valueCredit{}, ErrSessionExpired
+	}
+	// The ledger is on its way to another host.
+	if sm.handoff != nil {
+		return valueCredit{}, ErrHandoffPending
+	}
+
+	order, ok := sm.releasable.values[digest]
+	remaining := order.value
+	if !ok {
+		return valueCredit{}, ErrNotReleasable
+	}
+	if amount == nil {
+		want = remaining
+	}
+	if want.Cmp(remaining) > 0 {
+		return valueCredit{}, fmt.Errorf("%w: %s unreleased", ErrReleaseExceedsOrder, remaining)
+	}
+	newUsed := sm.valueUsed.Sub(want)
+	if err := sm.journalLocked(JournalRelease, sm.key.Address(), sm.expiresAt, sm.maxValueLimit, newUsed); err != nil {
+		return valueCredit{}, err
+	}
+	sm.valueUsed = newUsed
+	for i := range sm.windows {
+		sm.windows[i].credit(order.signedAt, want)
+	}
+	if left := remaining.Sub(want); left.IsZero() {
+		delete(sm.releasable.values, digest)
+	} else {
+		sm.releasable.values[digest] = releasable{value: left, signedAt: order.signedAt, client: order.client}
+	}
+	return valueCredit{released: want, used: newUsed, client: order.client, epoch: sm.epoch}, nil
+}
+
+// WithValueRelease enables ReleaseValue. The released order's signature
+// stays valid, so a credit is only as good as the operator's word that the
+// order will never fill; it is off unless enabled, and each release takes
+// the elevation credential.
+func WithValueRelease(allow bool) Option {
+	return func(h *Handler) {
+		h.releases = allow
+	}
+}
+
+// WithReleaseAudit reports every value release, so credits to the limit
+// are as traceable as the signatures that spent it.
+func WithReleaseAudit(fn func(ValueRelease)) Option {
+	return func(h *Handler) {
+		h.onRelease = fn
+	}
+}
+
+// ReleaseValue credits back the value of a cancelled or unfilled order,
+// so cancel/replace strategies do not exhaust the limit without moving
+// any money. It is refused unless enabled with WithValueRelease, and the
+// operator re-authenticates with the elevation credential, like
+// RenewSession. The credit also returns to the quota of the client that
+// signed the order.
+func (h *Handler) ReleaseValue(ctx context.Context, req *signerv1.ReleaseValueRequest) (*signerv1.ReleaseValueResponse, error) {
+	if !h.releases {
+		return nil, status.Errorf(codes.PermissionDenied, "%v", ErrReleaseDisabled)
+	}
+	if h.elevator == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", ErrElevationDisabled)
+	}
+	var digest [32]byte
+	raw, err := hex.DecodeString(strings.TrimPrefix(req.OrderHash, "0x"))
+	if err != nil || len(raw) != len(digest) {
+		return nil, status.Errorf(codes.InvalidArgument, "order_hash must be 32 hex-encoded bytes")
+	}
+	copy(digest[:], raw)
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required")
+	}
+	var amount *big.Int
+	if req.Amount != "" {
+		var ok bool
+		if amount, ok = new(big.Int).SetString(req.Amount, 10); !ok || amount.Sign() <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "amount must be a positive integer")
+		}
+	}
+
+	if err := h.elevator.Reauthenticate(ClientID(ctx), req.Credential, "release-value", req.Reason); err != nil {
+		return nil, elevationStatus(err)
+	}
+
+	c, err := h.session.release(ctx, digest, amount)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrNotReleasable):
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	case errors.Is(err, ErrReleaseExceedsOrder):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	default:
+		return nil, valuelessSignStatus(err)
+	}
+	// Keep the client's quota in step with the session ledger. The order
+	// itself still counts, as it does toward the session's order cap.
+	if h.quotas != nil {
+		h.quotas.Credit(c.epoch, c.client, c.released.BigInt())
+	}
+	released, used := c.released, c.used
+	if h.onRelease != nil {
+		h.onRelease(ValueRelease{
+			Session:   h.id,
+			Client:    ClientID(ctx),
+			RequestID: RequestID(ctx),
+			OrderHash: "0x" + hex.EncodeToString(digest[:]),
+			Released:  released.String(),
+			ValueUsed: used.String(),
+			Reason:    req.Reason,
+			At:        h.session.Clock().Now(),
+		})
+	}
+	return &signerv1.ReleaseValueResponse{Released: released.String(), ValueUsed: used.String()}, nil
+}
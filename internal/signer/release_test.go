@@ -0,0 +1,140 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSessionRelease(t *testing.T) {
+	sm := activeSession(t, 1000)
+	ctx := context.Background()
+	if _, err := sm.SignBatch(ctx, [][32]byte{{1}, {2}}, []*big.Int{big.NewInt(600), big.NewInt(400)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.Sign(ctx, [32]byte{3}, big.NewInt(1)); !errors.Is(err, ErrValueLimitExceeded) {
+		t.Fatalf("expected the limit spent, got %v", err)
+	}
+
+	// Order 1 filled 250 of 600; the rest is credited back.
+	released, used, err := sm.Release(ctx, [32]byte{1}, big.NewInt(350))
+	if err != nil || released.String() != "350" || used.String() != "650" {
+		t.Fatalf("partial release: %s, %s, %v", released, used, err)
+	}
+	if _, _, err := sm.Release(ctx, [32]byte{1}, big.NewInt(251)); !errors.Is(err, ErrReleaseExceedsOrder) {
+		t.Errorf("over the order's value: expected ErrReleaseExceedsOrder, got %v", err)
+	}
+	// Order 2 was cancelled outright.
+	if released, used, err = sm.Release(ctx, [32]byte{2}, nil); err != nil || released.String() != "400" || used.String() != "250" {
+		t.Fatalf("full release: %s, %s, %v", released, used, err)
+	}
+	if _, _, err := sm.Release(ctx, [32]byte{2}, nil); !errors.Is(err, ErrNotReleasable) {
+		t.Errorf("released twice: expected ErrNotReleasable, got %v", err)
+	}
+	if _, _, err := sm.Release(ctx, [32]byte{9}, nil); !errors.Is(err, ErrNotReleasable) {
+		t.Errorf("never signed: expected ErrNotReleasable, got %v", err)
+	}
+	if _, err := sm.Sign(ctx, [32]byte{3}, big.NewInt(750)); err != nil {
+		t.Errorf("sign within the released budget: %v", err)
+	}
+
+	// A new session cannot release the last one's orders.
+	if err := sm.Activate(ctx, testKey(), big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := sm.Release(ctx, [32]byte{1}, nil); !errors.Is(err, ErrNotReleasable) {
+		t.Errorf("after reactivation: expected ErrNotReleasable, got %v", err)
+	}
+}
+
+func TestReleaseLedgerForgetsOldestOrders(t *testing.T) {
+	var l releaseLedger
+	for i := 0; i <= releasableOrders; i++ {
+		var d [32]byte
+		d[0], d[1], d[2] = byte(i), byte(i>>8), byte(i>>16)
+		l.record(d, AmountOf(1), time.Time{}, "")
+	}
+	if len(l.values) != releasableOrders {
+		t.Errorf("remembers %d orders, want %d", len(l.values), releasableOrders)
+	}
+	if _, ok := l.values[[32]byte{}]; ok {
+		t.Error("the oldest order is still releasable")
+	}
+	if _, ok := l.values[[32]byte{0, 0, 1}]; !ok {
+		t.Error("the newest order is not releasable")
+	}
+}
+
+func TestReleaseValue(t *testing.T) {
+	sm := activeSession(t, 1_000_000)
+	var audited []ValueRelease
+	quotas, err := NewQuotaTracker(0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := []Option{WithQuotas(quotas), WithReleaseAudit(func(r ValueRelease) { audited = append(audited, r) })}
+	ctx := context.Background()
+
+	if _, err := NewHandler(sm, opts...).ReleaseValue(ctx, &signerv1.ReleaseValueRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("not enabled: expected PermissionDenied, got %v", err)
+	}
+	if _, err := NewHandler(sm, append(opts, WithValueRelease(true))...).ReleaseValue(ctx, &signerv1.ReleaseValueRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("no elevation: expected FailedPrecondition, got %v", err)
+	}
+	h := NewHandler(sm, append(opts, WithValueRelease(true), WithElevation(testElevator(t, nil, nil)))...)
+
+	signed, err := h.SignOrder(ctx, &signerv1.SignOrderRequest{
+		Order: &signerv1.PolymarketOrder{Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "500000", TakerAmount: "1000000"},
+	})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	hash, err := h.ComputeOrderHash(ctx, &signerv1.ComputeOrderHashRequest{Order: signed.Order})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, req := range []*signerv1.ReleaseValueRequest{
+		{OrderHash: "0x1234", Reason: "cancelled"},
+		{OrderHash: hash.Digest},
+		{OrderHash: hash.Digest, Amount: "-1", Reason: "cancelled"},
+	} {
+		if _, err := h.ReleaseValue(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%+v: expected InvalidArgument, got %v", req, err)
+		}
+	}
+	if _, err := h.ReleaseValue(ctx, &signerv1.ReleaseValueRequest{OrderHash: hash.Digest, Reason: "cancelled", Credential: "wrong"}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("bad credential: expected Unauthenticated, got %v", err)
+	}
+	resp, err := h.ReleaseValue(ctx, &signerv1.ReleaseValueRequest{OrderHash: hash.Digest, Amount: "200000", Reason: "partial fill", Credential: "open sesame"})
+	if err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if resp.Released != "200000" || resp.ValueUsed != "300000" {
+		t.Errorf("released %s, used %s", resp.Released, resp.ValueUsed)
+	}
+	if u := quotas.Usage(ClientID(ctx)); u.Orders != 1 || u.Notional.String() != "300000" {
+		t.Errorf("quota after release: %d orders, %s notional", u.Orders, u.Notional)
+	}
+	if _, err := h.ReleaseValue(ctx, &signerv1.ReleaseValueRequest{OrderHash: hash.Digest, Amount: "300001", Reason: "cancelled", Credential: "open sesame"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("over the order's value: expected InvalidArgument, got %v", err)
+	}
+	if _, err := h.ReleaseValue(ctx, &signerv1.ReleaseValueRequest{OrderHash: "0x" + strings.Repeat("00", 31) + "ff", Reason: "cancelled", Credential: "open sesame"}); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown order: expected NotFound, got %v", err)
+	}
+	if len(audited) != 1 || audited[0].OrderHash != hash.Digest || audited[0].Released != "200000" || audited[0].Reason != "partial fill" || audited[0].At.IsZero() {
+		t.Errorf("audited %+v", audited)
+	}
+
+	sm.Destroy()
+	if _, err := h.ReleaseValue(ctx, &signerv1.ReleaseValueRequest{OrderHash: hash.Digest, Reason: "cancelled", Credential: "open sesame"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("no session: expected FailedPrecondition, got %v", err)
+	}
+}
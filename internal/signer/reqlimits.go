@@ -0,0 +1,151 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// maxViolations bounds the field violations reported for one request.
+const maxViolations = 10
+
+// RequestLimits bounds the shape of every request before a handler sees
+// it, so a client cannot make the signer allocate or iterate in proportion
+// to whatever it sends. Limits apply to every field of every message, at
+// any depth; Fields overrides the byte length of a singular field, or the
+// element count of a repeated one, named in full (e.g.
+// "signer.v1.SignOrdersRequest.orders").
+type RequestLimits struct {
+	MaxMessageBytes int // encoded size of a request, enforced by gRPC
+	MaxStringLen    int // bytes in one string or bytes field
+	MaxRepeated     int // elements in one repeated field or map
+	MaxDepth        int // nesting of messages
+	Fields          map[protoreflect.FullName]int
+}
+
+// DefaultRequestLimits admits every legitimate request with room to spare.
+// Only typed data payloads and handoff bundles carry more than a few
+// hundred bytes in one field.
+var DefaultRequestLimits = RequestLimits{
+	MaxMessageBytes: 1 << 20,
+	MaxStringLen:    1024,
+	MaxRepeated:     16,
+	MaxDepth:        8,
+	Fields: map[protoreflect.FullName]int{
+		"signer.v1.SignOrdersRequest.orders":        MaxBatchOrders,
+		"signer.v1.SignTypedDataRequest.typed_data": 64 << 10,
+		"signer.v1.ImportSessionRequest.bundle":     64 << 10,
+	},
+}
+
+// WithRequestLimits replaces DefaultRequestLimits for the server built
+// around the handler.
+func WithRequestLimits(l RequestLimits) Option {
+	return func(h *Handler) {
+		h.limits = l
+	}
+}
+
+// interceptor returns a unary interceptor refusing requests that exceed l
+// with InvalidArgument and a BadRequest detail naming each field.
+func (l RequestLimits) interceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if m, ok := req.(proto.Message); ok {
+			if violations := l.check(m.ProtoReflect(), "", 1, nil); len(violations) > 0 {
+				st := status.New(codes.InvalidArgument, "request exceeds size limits: "+violations[0].Description)
+				if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+					st = detailed
+				}
+				return nil, st.Err()
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// check walks the set fields of m, appending a violation for each limit
+// exceeded until maxViolations are found.
+func (l RequestLimits) check(m protoreflect.Message, path string, depth int, out []*errdetails.BadRequest_FieldViolation) []*errdetails.BadRequest_FieldViolation {
+	if depth > l.MaxDepth {
+		return append(out, &errdetails.BadRequest_FieldViolation{
+			Field:       path,
+			Description: fmt.Sprintf("%s nests deeper than %d messages", path, l.MaxDepth),
+		})
+	}
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		field := string(fd.Name())
+		if path != "" {
+			field = path + "." + field
+		}
+		violate := func(format string, a ...any) {
+			out = append(out, &errdetails.BadRequest_FieldViolation{Field: field, Description: field + " " + fmt.Sprintf(format, a...)})
+		}
+
+		switch {
+		case fd.IsList():
+			list := v.List()
+			if allowed := l.limit(fd, l.MaxRepeated); list.Len() > allowed {
+				violate("has %d elements, over %d", list.Len(), allowed)
+				break
+			}
+			for i := 0; i < list.Len() && len(out) < maxViolations; i++ {
+				out = l.checkValue(fd, list.Get(i), fmt.Sprintf("%s[%d]", field, i), l.MaxStringLen, depth, out)
+			}
+		case fd.IsMap():
+			entries := v.Map()
+			if allowed := l.limit(fd, l.MaxRepeated); entries.Len() > allowed {
+				violate("has %d entries, over %d", entries.Len(), allowed)
+				break
+			}
+			entries.Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				if len(k.String()) > l.MaxStringLen {
+					violate("has a key of %d bytes, over %d", len(k.String()), l.MaxStringLen)
+					return false
+				}
+				out = l.checkValue(fd.MapValue(), mv, field+"["+k.String()+"]", l.MaxStringLen, depth, out)
+				return len(out) < maxViolations
+			})
+		default:
+			out = l.checkValue(fd, v, field, l.limit(fd, l.MaxStringLen), depth, out)
+		}
+		return len(out) < maxViolations
+	})
+	return out
+}
+
+// checkValue checks one value of fd: a message is walked, and a string or
+// bytes value may hold at most allowed bytes.
+func (l RequestLimits) checkValue(fd protoreflect.FieldDescriptor, v protoreflect.Value, field string, allowed, depth int, out []*errdetails.BadRequest_FieldViolation) []*errdetails.BadRequest_FieldViolation {
+	n := 0
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return l.check(v.Message(), field, depth+1, out)
+	case protoreflect.StringKind:
+		n = len(v.String())
+	case protoreflect.BytesKind:
+		n = len(v.Bytes())
+	default:
+		return out
+	}
+	if n > allowed {
+		out = append(out, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: fmt.Sprintf("%s is %d bytes, over %d", field, n, allowed),
+		})
+	}
+	return out
+}
+
+// limit returns the override for fd, or def.
+func (l RequestLimits) limit(fd protoreflect.FieldDescriptor, def int) int {
+	if n, ok := l.Fields[fd.FullName()]; ok {
+		return n
+	}
+	return def
+}
@@ -0,0 +1,124 @@
+package signer
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+func TestRequestLimits(t *testing.T) {
+	limits := RequestLimits{MaxStringLen: 8, MaxRepeated: 2, MaxDepth: 2, Fields: DefaultRequestLimits.Fields}
+	intercept := limits.interceptor()
+	called := false
+	handler := func(context.Context, any) (any, error) {
+		called = true
+		return nil, nil
+	}
+	order := func() *signerv1.SignOrderRequest {
+		return &signerv1.SignOrderRequest{Order: &signerv1.PolymarketOrder{TokenId: "1", MakerAmount: "60"}}
+	}
+
+	for name, tc := range map[string]struct {
+		req    any
+		fields []string
+	}{
+		"within limits": {order(), nil},
+		"long string": {&signerv1.SignOrderRequest{Order: &signerv1.PolymarketOrder{TokenId: "123456789"}},
+			[]string{"order.token_id"}},
+		"override length": {&signerv1.SignTypedDataRequest{TypedData: strings.Repeat("x", 1024)}, nil},
+		"override count":  {&signerv1.SignOrdersRequest{Orders: []*signerv1.SignOrderRequest{{}, {}, {}}}, nil},
+		"long element": {&signerv1.ElevateSessionRequest{Scopes: []string{"approval", "policy:long-rule"}},
+			[]string{"scopes[1]"}},
+		"many elements": {&signerv1.ElevateSessionRequest{Scopes: []string{"a", "b", "c"}},
+			[]string{"scopes"}},
+		"every violation": {&signerv1.ElevateSessionRequest{Credential: "passphrase", Reason: "because I can"},
+			[]string{"credential", "reason"}},
+		"too deep": {&signerv1.SignOrdersRequest{Orders: []*signerv1.SignOrderRequest{order()}},
+			[]string{"orders[0].order"}},
+	} {
+		called = false
+		_, err := intercept(context.Background(), tc.req, nil, handler)
+		if tc.fields == nil {
+			if err != nil || !called {
+				t.Errorf("%s: expected the handler to run, got %v", name, err)
+			}
+			continue
+		}
+		if called || status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument before the handler, got %v", name, err)
+			continue
+		}
+		var got []string
+		for _, d := range status.Convert(err).Details() {
+			if br, ok := d.(*errdetails.BadRequest); ok {
+				for _, v := range br.FieldViolations {
+					got = append(got, v.Field)
+				}
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tc.fields, ",") {
+			t.Errorf("%s: violations on %v, want %v", name, got, tc.fields)
+		}
+	}
+}
+
+func TestDefaultRequestLimitFields(t *testing.T) {
+	for name := range DefaultRequestLimits.Fields {
+		if _, err := protoregistry.GlobalFiles.FindDescriptorByName(name); err != nil {
+			t.Errorf("limit on unknown field %s", name)
+		}
+	}
+}
+
+func TestServerRefusesOversizedRequest(t *testing.T) {
+	sm := activeSession(t, 100)
+	socket := filepath.Join(t.TempDir(), "signer.sock")
+	srv, err := New(socket, sm)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	go srv.Serve()
+	defer srv.GracefulStop()
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := signerv1.NewSignerServiceClient(conn)
+
+	orders := make([]*signerv1.SignOrderRequest, MaxBatchOrders+1)
+	for i := range orders {
+		orders[i] = &signerv1.SignOrderRequest{Order: &signerv1.PolymarketOrder{Maker: testMaker, TokenId: "1", MakerAmount: "1"}}
+	}
+	_, err = client.SignOrders(context.Background(), &signerv1.SignOrdersRequest{Orders: orders})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+	var badRequest, requestInfo bool
+	for _, d := range status.Convert(err).Details() {
+		switch d.(type) {
+		case *errdetails.BadRequest:
+			badRequest = true
+		case *errdetails.RequestInfo:
+			requestInfo = true
+		}
+	}
+	if !badRequest || !requestInfo {
+		t.Errorf("expected BadRequest and RequestInfo details, got %v", status.Convert(err).Details())
+	}
+
+	_, err = client.SignTypedData(context.Background(), &signerv1.SignTypedDataRequest{TypedData: strings.Repeat("x", 2<<20)})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("over the message size: expected ResourceExhausted, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package signer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDKey is the metadata key carrying a caller-supplied request ID.
+// The signer generates one when it is absent or malformed and echoes it in
+// the response header and in the RequestInfo detail of every error.
+const RequestIDKey = "x-request-id"
+
+// TraceParentKey is the W3C Trace Context header. Only the trace ID is used,
+// for correlation; the signer does not emit spans.
+const TraceParentKey = "traceparent"
+
+// maxRequestIDLen bounds caller-supplied IDs so they are safe to log.
+const maxRequestIDLen = 128
+
+type requestIDsKey struct{}
+
+type requestIDs struct {
+	requestID string
+	traceID   string
+}
+
+// RequestID returns the request ID attached by the server interceptor, or
+// "" outside a gRPC call.
+func RequestID(ctx context.Context) string {
+	ids, _ := ctx.Value(requestIDsKey{}).(requestIDs)
+	return ids.requestID
+}
+
+// TraceID returns the W3C trace ID from the caller's traceparent, or "".
+func TraceID(ctx context.Context) string {
+	ids, _ := ctx.Value(requestIDsKey{}).(requestIDs)
+	return ids.traceID
+}
+
+// requestIDInterceptor attaches request and trace IDs to the context,
+// echoes the request ID as a response header, and adds it to errors.
+func requestIDInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var ids requestIDs
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(RequestIDKey); len(v) > 0 && validRequestID(v[0]) {
+			ids.requestID = v[0]
+		}
+		if v := md.Get(TraceParentKey); len(v) > 0 {
+			ids.traceID = parseTraceParent(v[0])
+		}
+	}
+	if ids.requestID == "" {
+		ids.requestID = newRequestID()
+	}
+	ctx = context.WithValue(ctx, requestIDsKey{}, ids)
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, ids.requestID))
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, withRequestInfo(err, ids)
+	}
+	return resp, nil
+}
+
+// withRequestInfo adds a RequestInfo detail to err's status.
+func withRequestInfo(err error, ids requestIDs) error {
+	info := &errdetails.RequestInfo{RequestId: ids.requestID}
+	if ids.traceID != "" {
+		info.ServingData = "trace_id=" + ids.traceID
+	}
+	st, detailErr := status.Convert(err).WithDetails(info)
+	if detailErr != nil {
+		return err
+	}
+	return st.Err()
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID accepts short printable ASCII IDs without spaces.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTraceParent extracts the trace ID from
+// "version-traceid-parentid-flags", returning "" if it is malformed.
+func parseTraceParent(tp string) string {
+	parts := strings.Split(tp, "-")
+	if len(parts) < 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil || parts[1] == strings.Repeat("0", 32) {
+		return ""
+	}
+	return strings.ToLower(parts[1])
+}
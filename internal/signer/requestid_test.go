@@ -0,0 +1,90 @@
+package signer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestServerEchoesRequestID(t *testing.T) {
+	sm := activeSession(t, 100)
+	socket := filepath.Join(t.TempDir(), "signer.sock")
+	srv, err := New(socket, sm)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	go srv.Serve()
+	defer srv.GracefulStop()
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := signerv1.NewSignerServiceClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		RequestIDKey, "strat-42",
+		TraceParentKey, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	var header metadata.MD
+	resp, err := client.SignOrder(ctx, &signerv1.SignOrderRequest{
+		Order: &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "60"},
+	}, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if resp.RequestId != "strat-42" {
+		t.Errorf("response request_id = %q", resp.RequestId)
+	}
+	if got := header.Get(RequestIDKey); len(got) != 1 || got[0] != "strat-42" {
+		t.Errorf("header request id = %v", got)
+	}
+
+	// A rejected order carries the ID in its error details.
+	_, err = client.SignOrder(ctx, &signerv1.SignOrderRequest{
+		Order: &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "60"},
+	})
+	var info *errdetails.RequestInfo
+	for _, d := range status.Convert(err).Details() {
+		if ri, ok := d.(*errdetails.RequestInfo); ok {
+			info = ri
+		}
+	}
+	if info == nil || info.RequestId != "strat-42" {
+		t.Fatalf("expected RequestInfo detail, got %v", status.Convert(err).Details())
+	}
+	if info.ServingData != "trace_id=4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("serving data = %q", info.ServingData)
+	}
+
+	// Without a caller ID the signer generates one.
+	header = nil
+	_, err = client.GetSessionStatus(context.Background(), &signerv1.GetSessionStatusRequest{}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get(RequestIDKey); len(got) != 1 || len(got[0]) != 32 {
+		t.Errorf("expected generated request id, got %v", got)
+	}
+}
+
+func TestValidRequestID(t *testing.T) {
+	for id, want := range map[string]bool{
+		"abc-123":                               true,
+		"":                                      false,
+		"has space":                             false,
+		"new\nline":                             false,
+		string(make([]byte, maxRequestIDLen+1)): false,
+	} {
+		if got := validRequestID(id); got != want {
+			t.Errorf("validRequestID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package signer
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	"github.com/caesar-terminal/caesar/internal/eip712"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+)
+
+var ErrOrderReplayed = errors.New("order with this token and salt already signed this session")
+
+// Salt strategies for ParseSaltStrategy.
+const (
+	// SaltRandom draws salts uniformly below 2^53, so they survive a
+	// round trip through a JSON number.
+	SaltRandom = "random"
+	// SaltTime uses the clock in microseconds, bumped past the last salt
+	// issued so two orders in the same microsecond still differ.
+	SaltTime = "time"
+	// SaltClient generates nothing; orders must carry their own salt.
+	SaltClient = "client"
+)
+
+// SaltStrategy generates the salt of an order submitted without one.
+type SaltStrategy func() (string, error)
+
+var maxRandomSalt = new(big.Int).Lsh(big.NewInt(1), 53)
+
+// ParseSaltStrategy returns the named strategy, or nil for SaltClient.
+// A nil clock uses the wall clock.
+func ParseSaltStrategy(name string, c clock.Clock) (SaltStrategy, error) {
+	switch name {
+	case SaltClient:
+		return nil, nil
+	case SaltRandom, "":
+		return func() (string, error) {
+			n, err := rand.Int(rand.Reader, maxRandomSalt)
+			if err != nil {
+				return "", err
+			}
+			return n.String(), nil
+		}, nil
+	case SaltTime:
+		c = clock.Or(c)
+		var mu sync.Mutex
+		var last int64
+		return func() (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			last = max(c.Now().UnixMicro(), last+1)
+			return strconv.FormatInt(last, 10), nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown salt strategy %q", name)
+	}
+}
+
+// ReplayGuard remembers the (token ID, salt) pairs signed in the current
+// session and refuses to sign one twice, so a client retrying a request
+// whose response it lost cannot submit the order a second time. It
+// resets whenever a new session is activated.
+type ReplayGuard struct {
+	mu     sync.Mutex
+	epoch  uint64
+	signed map[replayKey]struct{}
+}
+
+type replayKey struct{ tokenID, salt string }
+
+// NewReplayGuard creates an empty guard.
+func NewReplayGuard() *ReplayGuard {
+	return &ReplayGuard{signed: make(map[replayKey]struct{})}
+}
+
+// Claim records orders as signed in the session identified by epoch. If
+// any was already signed, or appears twice, nothing is recorded and
+// ErrOrderReplayed names the first. Call Release if signing then fails.
+func (g *ReplayGuard) Claim(epoch uint64, orders ...*signerv1.PolymarketOrder) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if epoch != g.epoch {
+		g.epoch = epoch
+		g.signed = make(map[replayKey]struct{})
+	}
+	keys := make(map[replayKey]struct{}, len(orders))
+	for _, o := range orders {
+		k := replayKeyOf(o)
+		_, signed := g.signed[k]
+		if _, dup := keys[k]; signed || dup {
+			return fmt.Errorf("%w: token %s salt %s", ErrOrderReplayed, k.tokenID, k.salt)
+		}
+		keys[k] = struct{}{}
+	}
+	for k := range keys {
+		g.signed[k] = struct{}{}
+	}
+	return nil
+}
+
+// Release forgets orders claimed under epoch.
+func (g *ReplayGuard) Release(epoch uint64, orders ...*signerv1.PolymarketOrder) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if epoch != g.epoch {
+		return
+	}
+	for _, o := range orders {
+		delete(g.signed, replayKeyOf(o))
+	}
+}
+
+// replayKeyOf compares token IDs and salts by the value signed: "007"
+// and "0x7" replay "7", and an empty salt is zero.
+func replayKeyOf(o *signerv1.PolymarketOrder) replayKey {
+	return replayKey{uintValue(o.TokenId), uintValue(o.Salt)}
+}
+
+func uintValue(s string) string {
+	if enc, err := eip712.EncodeUintString(s); err == nil {
+		return new(big.Int).SetBytes(enc).String()
+	}
+	return s
+}
@@ -0,0 +1,138 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseSaltStrategy(t *testing.T) {
+	if s, err := ParseSaltStrategy(SaltClient, nil); err != nil || s != nil {
+		t.Errorf("client strategy should generate nothing, got %v", err)
+	}
+	if _, err := ParseSaltStrategy("sequential", nil); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+
+	random, err := ParseSaltStrategy(SaltRandom, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := random()
+	b, _ := random()
+	n, ok := new(big.Int).SetString(a, 10)
+	if !ok || n.Cmp(maxRandomSalt) >= 0 || a == b {
+		t.Errorf("random salts %s, %s", a, b)
+	}
+
+	clk := clock.NewFake(time.UnixMicro(1_700_000_000_000_000))
+	timed, err := ParseSaltStrategy(SaltTime, clk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for range 2 {
+		s, _ := timed()
+		got = append(got, s)
+	}
+	clk.Advance(time.Second)
+	s, _ := timed()
+	got = append(got, s)
+	want := []string{"1700000000000000", "1700000000000001", "1700000001000000"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("time salts = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestReplayGuard(t *testing.T) {
+	g := NewReplayGuard()
+	order := func(token, salt string) *signerv1.PolymarketOrder {
+		return &signerv1.PolymarketOrder{TokenId: token, Salt: salt}
+	}
+
+	if err := g.Claim(1, order("1", "7"), order("2", "7")); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	for _, o := range []*signerv1.PolymarketOrder{order("1", "7"), order("1", "007"), order("0x1", "0x7")} {
+		if err := g.Claim(1, o); !errors.Is(err, ErrOrderReplayed) {
+			t.Errorf("%s/%s: expected ErrOrderReplayed, got %v", o.TokenId, o.Salt, err)
+		}
+	}
+
+	// A batch with a repeat is refused whole.
+	if err := g.Claim(1, order("3", "1"), order("3", "1")); !errors.Is(err, ErrOrderReplayed) {
+		t.Errorf("expected ErrOrderReplayed, got %v", err)
+	}
+	if err := g.Claim(1, order("3", "1")); err != nil {
+		t.Errorf("a refused batch must claim nothing: %v", err)
+	}
+
+	g.Release(1, order("1", "7"))
+	if err := g.Claim(1, order("1", "7")); err != nil {
+		t.Errorf("released order should be claimable: %v", err)
+	}
+
+	// A new session starts afresh; releases from the old one are ignored.
+	if err := g.Claim(2, order("1", "7")); err != nil {
+		t.Errorf("new session: %v", err)
+	}
+	g.Release(1, order("1", "7"))
+	if err := g.Claim(2, order("1", "7")); !errors.Is(err, ErrOrderReplayed) {
+		t.Errorf("stale release should not free the order, got %v", err)
+	}
+}
+
+func TestSignOrderSaltsAndReplays(t *testing.T) {
+	sm := activeSession(t, 1_000)
+	clk := clock.NewFake(time.UnixMicro(42))
+	salts, _ := ParseSaltStrategy(SaltTime, clk)
+	h := NewHandler(sm, WithSalts(salts), WithReplayGuard(NewReplayGuard()))
+	sign := func(salt, amount string) (*signerv1.SignOrderResponse, error) {
+		return h.SignOrder(context.Background(), &signerv1.SignOrderRequest{
+			Order: &signerv1.PolymarketOrder{Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: amount, Salt: salt},
+		})
+	}
+
+	// Generated salts are signed and returned; a retry gets a fresh one.
+	first, err := sign("", "100")
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	second, err := sign("", "100")
+	if err != nil {
+		t.Fatalf("sign again: %v", err)
+	}
+	if first.Order.Salt != "42" || second.Order.Salt != "43" {
+		t.Errorf("salts = %s, %s", first.Order.Salt, second.Order.Salt)
+	}
+	digest, _ := OrderDigest(first.Order)
+	if addr, err := recoverAddress(digest, mustHex(t, first.Signature[2:])); err != nil || addr != testMaker {
+		t.Errorf("signature does not cover the generated salt: %s, %v", addr, err)
+	}
+
+	// A client retry of its own order is refused.
+	if _, err := sign("9", "100"); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if _, err := sign("9", "100"); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected AlreadyExists, got %v", err)
+	}
+
+	// An order the session refused can be retried once it fits.
+	if _, err := sign("10", "5000"); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+	if _, err := sign("10", "100"); err != nil {
+		t.Errorf("refused order should not be recorded as signed: %v", err)
+	}
+}
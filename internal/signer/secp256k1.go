@@ -0,0 +1,162 @@
+package signer
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/caesar-terminal/caesar/internal/eip712"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+var (
+	ErrInvalidKey       = errors.New("session key is not a valid secp256k1 private key")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Conventions for v, the last byte of a 65-byte signature.
+const (
+	// VOffsetEthereum gives v of 27 or 28, which ecrecover and the CTF
+	// Exchange expect.
+	VOffsetEthereum byte = 27
+	// VOffsetRaw gives v of 0 or 1, the bare recovery ID, as some
+	// libraries and hardware signers produce.
+	VOffsetRaw byte = 0
+)
+
+// privateKey validates a 32-byte private key and loads it as a scalar.
+// The caller MUST Zero the result once done with it.
+func privateKey(key []byte) (*secp256k1.PrivateKey, error) {
+	if len(key) != 32 {
+		return nil, ErrInvalidKey
+	}
+	var d secp256k1.ModNScalar
+	if overflow := d.SetByteSlice(key); overflow || d.IsZero() {
+		d.Zero()
+		return nil, ErrInvalidKey
+	}
+	priv := secp256k1.NewPrivateKey(&d)
+	d.Zero()
+	return priv, nil
+}
+
+// keyAddress derives the Ethereum address of a private key: the last 20
+// bytes of the Keccak-256 of the uncompressed public key, EIP-55
+// checksummed.
+func keyAddress(key []byte) (string, error) {
+	priv, err := privateKey(key)
+	if err != nil {
+		return "", err
+	}
+	defer priv.Zero()
+	return pubKeyAddress(priv.PubKey()), nil
+}
+
+func pubKeyAddress(pub *secp256k1.PublicKey) string {
+	h := eip712.Keccak256(pub.SerializeUncompressed()[1:])
+	return checksumAddress(h[12:])
+}
+
+// parseSignature checks a 65-byte r ‖ s ‖ v signature, v in either
+// convention, and returns its scalars and recovery ID.
+func parseSignature(sig []byte) (r, s secp256k1.ModNScalar, v byte, err error) {
+	if len(sig) != 65 {
+		return r, s, 0, fmt.Errorf("%w: length %d", ErrInvalidSignature, len(sig))
+	}
+	v = sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	rOverflow := r.SetByteSlice(sig[:32])
+	sOverflow := s.SetByteSlice(sig[32:64])
+	if v > 1 || rOverflow || r.IsZero() || sOverflow || s.IsZero() {
+		return r, s, 0, ErrInvalidSignature
+	}
+	return r, s, v, nil
+}
+
+// recoverAddress returns the address whose key produced sig, a 65-byte
+// r ‖ s ‖ v signature over digest as made by signDigest.
+func recoverAddress(digest [32]byte, sig []byte) (string, error) {
+	_, _, v, err := parseSignature(sig)
+	if err != nil {
+		return "", err
+	}
+	// The compact form is v ‖ r ‖ s, with v offset by 27 for an
+	// uncompressed key.
+	var compact [65]byte
+	compact[0] = 27 + v
+	copy(compact[1:], sig[:64])
+	pub, _, err := ecdsa.RecoverCompact(compact[:], digest[:])
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return pubKeyAddress(pub), nil
+}
+
+// NormalizeSignature returns sig, a 65-byte r ‖ s ‖ v signature with v
+// in either convention, in canonical form: s in the lower half of the
+// curve order, with v adjusted to match, and v offset by vOffset. A
+// signature with a high s recovers the same address but is a second
+// valid encoding of it, which contracts and the CLOB reject.
+func NormalizeSignature(sig []byte, vOffset byte) ([]byte, error) {
+	if vOffset != VOffsetEthereum && vOffset != VOffsetRaw {
+		return nil, fmt.Errorf("v offset %d is neither %d nor %d", vOffset, VOffsetEthereum, VOffsetRaw)
+	}
+	_, s, v, err := parseSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+
+	out := append([]byte(nil), sig...)
+	if s.IsOverHalfOrder() {
+		// (r, n−s) verifies with the negated R, whose y has the other
+		// parity.
+		s.Negate()
+		s.PutBytesUnchecked(out[32:64])
+		v ^= 1
+	}
+	out[64] = vOffset + v
+	return out, nil
+}
+
+// checksumAddress renders a 20-byte address in EIP-55 mixed case.
+func checksumAddress(addr []byte) string {
+	lower := hex.EncodeToString(addr)
+	h := eip712.Keccak256([]byte(lower))
+	out := []byte(lower)
+	for i, c := range out {
+		nibble := h[i/2] >> 4
+		if i%2 == 1 {
+			nibble = h[i/2] & 0x0f
+		}
+		if c >= 'a' && nibble >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+// signDigest signs a 32-byte digest with key and returns the 65-byte
+// Ethereum signature r ‖ s ‖ v, with a low s and v of 27 or 28. The nonce
+// is derived deterministically (RFC 6979), so no randomness is needed
+// inside the enclave window.
+//
+// The arithmetic is constant-time, and the scalar loaded from key is
+// zeroed before returning, so the key exists outside its locked buffer
+// only for the duration of the signature.
+func signDigest(key []byte, digest [32]byte) ([]byte, error) {
+	priv, err := privateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	defer priv.Zero()
+	// The compact form is v ‖ r ‖ s, with v of 27 or 28 for an
+	// uncompressed key.
+	compact := ecdsa.SignCompact(priv, digest[:], false)
+	sig := make([]byte, 65)
+	copy(sig, compact[1:])
+	sig[64] = compact[0]
+	return sig, nil
+}
@@ -0,0 +1,57 @@
+package signer
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// malleate returns the other valid encoding of a low-s signature: s
+// replaced by n−s, v flipped, in the raw v convention.
+func malleate(sig []byte) []byte {
+	out := append([]byte(nil), sig...)
+	var s secp256k1.ModNScalar
+	s.SetByteSlice(sig[32:64])
+	s.Negate().PutBytesUnchecked(out[32:64])
+	out[64] = (sig[64] - 27) ^ 1
+	return out
+}
+
+func TestNormalizeSignature(t *testing.T) {
+	digest := [32]byte{7}
+	sig, err := signDigest(testKey(), digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	high := malleate(sig)
+	if addr, err := recoverAddress(digest, high); err != nil || addr != testMaker {
+		t.Fatalf("malleated signature recovers to %s (%v)", addr, err)
+	}
+
+	for _, in := range [][]byte{sig, high} {
+		got, err := NormalizeSignature(in, VOffsetEthereum)
+		if err != nil || !bytes.Equal(got, sig) {
+			t.Errorf("NormalizeSignature(%x) = %x, %v; want %x", in, got, err, sig)
+		}
+	}
+	raw, err := NormalizeSignature(high, VOffsetRaw)
+	if err != nil || !bytes.Equal(raw[:64], sig[:64]) || raw[64] != sig[64]-27 {
+		t.Errorf("raw v: got %x, %v", raw, err)
+	}
+	if high[32] == sig[32] {
+		t.Fatal("malleate left s unchanged")
+	}
+
+	bad := append([]byte(nil), sig...)
+	bad[64] = 29
+	for name, in := range map[string][]byte{"short": sig[:64], "v": bad, "zero r": make([]byte, 65)} {
+		if _, err := NormalizeSignature(in, VOffsetEthereum); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: expected ErrInvalidSignature, got %v", name, err)
+		}
+	}
+	if _, err := NormalizeSignature(sig, 1); err == nil {
+		t.Error("expected an error for v offset 1")
+	}
+}
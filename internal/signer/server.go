@@ -19,7 +19,7 @@ type Server struct {
 
 // New creates a new Signer gRPC server bound to the given UDS path.
 // It registers the SignerService handler and prepares the listener.
-func New(socketPath string, session *SessionManager) (*Server, error) {
+func New(socketPath string, session *SessionManager, opts ...Option) (*Server, error) {
 	// Ensure the socket directory exists.
 	if err := os.MkdirAll(filepath.Dir(socketPath), 0o700); err != nil {
 		return nil, fmt.Errorf("create socket directory: %w", err)
@@ -41,8 +41,36 @@ func New(socketPath string, session *SessionManager) (*Server, error) {
 		return nil, fmt.Errorf("chmod socket: %w", err)
 	}
 
-	gs := grpc.NewServer()
-	handler := NewHandler(session)
+	// Peer credentials expose the connecting process's UID to handlers;
+	// the interceptors tag every call with a request ID, refuse oversized
+	// requests before any handler allocates for them, authenticate client
+	// labels that have tokens, hold signing back
+	// until an unclean shutdown's recovery report is acknowledged, then
+	// hand requests naming a session to that session's handler.
+	handler := NewHandler(session, opts...)
+	interceptors := []grpc.UnaryServerInterceptor{requestIDInterceptor, handler.limits.interceptor()}
+	if len(handler.clientTokens) > 0 {
+		interceptors = append(interceptors, handler.clientTokens.interceptor())
+	}
+	if handler.recovery != nil {
+		interceptors = append(interceptors, handler.recovery.interceptor())
+	}
+	if len(handler.named) > 0 {
+		router, err := newSessionRouter(handler, opts)
+		if err != nil {
+			lis.Close()
+			return nil, err
+		}
+		interceptors = append(interceptors, router.interceptor())
+	}
+	serverOpts := []grpc.ServerOption{
+		grpc.Creds(peerCredentials{}),
+		grpc.ChainUnaryInterceptor(interceptors...),
+	}
+	if handler.limits.MaxMessageBytes > 0 {
+		serverOpts = append(serverOpts, grpc.MaxRecvMsgSize(handler.limits.MaxMessageBytes))
+	}
+	gs := grpc.NewServer(serverOpts...)
 	signerv1.RegisterSignerServiceServer(gs, handler)
 
 	return &Server{
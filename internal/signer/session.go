@@ -1,70 +1,363 @@
 package signer
 
 import (
+	"context"
+	"crypto/ecdh"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"math/big"
 	"sync"
 	"time"
 
-	"github.com/awnumar/memguard"
+	"github.com/caesar-terminal/caesar/internal/clock"
 )
 
 var (
-	ErrNoActiveSession   = errors.New("no active session")
-	ErrSessionExpired    = errors.New("session expired")
+	ErrNoActiveSession    = errors.New("no active session")
+	ErrSessionExpired     = errors.New("session expired")
 	ErrValueLimitExceeded = errors.New("cumulative value limit exceeded")
+	ErrApprovalRequired   = errors.New("order requires approval under active limit profile")
+	ErrInvalidRenewal     = errors.New("invalid session renewal")
+	ErrOrderValueExceeded = errors.New("order value exceeds the per-order cap")
 )
 
-// SessionManager holds a decrypted session key in locked memory with TTL
-// and cumulative value-limit enforcement. The key is encrypted at rest via
-// memguard.Enclave and only opened momentarily during Sign.
+// SessionManager enforces TTL and cumulative value limits on a session
+// key. The key itself sits behind a Signer; Activate seals a raw key in a
+// memguard Enclave (see EnclaveSigner) that is only opened during Sign.
 type SessionManager struct {
 	mu            sync.RWMutex
-	enclave       *memguard.Enclave // encrypted-at-rest key buffer
-	address       string            // derived signer address (hex)
+	key           Signer // nil when no session is active
 	expiresAt     time.Time
-	maxValueLimit *big.Int // USDC atomic units (6 decimals)
-	valueUsed     *big.Int // cumulative USDC signed
+	maxValueLimit Amount         // USDC atomic units (6 decimals)
+	maxOrderValue Amount         // per-order cap; zero means none
+	valueUsed     Amount         // cumulative USDC signed
+	windows       []windowLedger // rolling value limits, shortest first
+	maxOrders     int64          // orders a session may sign; zero means no cap
+	ordersSigned  int64
+	signRate      signBucket
+	releasable    releaseLedger // signed orders whose value can be released
 	ttl           time.Duration
+	idleTimeout   time.Duration    // zero means none
+	lastActive    time.Time        // last order, activation or renewal
+	profiles      []LimitProfile   // scheduled limit overrides, first match wins
+	epoch         uint64           // incremented on every Activate
+	activatedAt   time.Time        // set by Activate and ImportSession
+	handoff       *pendingHandoff  // set while an export awaits confirmation
+	paused        *SessionPause    // set while signing is frozen
+	clamp         sessionClamp     // caps tightened for this session alone
+	importKey     *ecdh.PrivateKey // receives the next imported session
+	keyGuard      func(address string) error
+	onReap        func(SessionExpiry)
+	journal       *Journal
+	journalID     string // the session's name in the journal
+	vOffset       byte   // added to the recovery ID of every signature
+	clock         clock.Clock
+}
+
+// SessionOption configures a SessionManager.
+type SessionOption func(*SessionManager)
+
+// WithClock sets the time source for TTL and limit-profile checks.
+// Defaults to the wall clock.
+func WithClock(c clock.Clock) SessionOption {
+	return func(sm *SessionManager) {
+		sm.clock = clock.Or(c)
+	}
+}
+
+// WithKeyGuard vets the address of every key before it is activated or
+// imported. An error refuses the key and keeps the previous session.
+func WithKeyGuard(guard func(address string) error) SessionOption {
+	return func(sm *SessionManager) {
+		sm.keyGuard = guard
+	}
+}
+
+// WithVOffset sets the v convention of returned signatures, VOffsetEthereum
+// (the default) or VOffsetRaw.
+func WithVOffset(offset byte) SessionOption {
+	return func(sm *SessionManager) {
+		sm.vOffset = offset
+	}
+}
+
+// WithReapHook calls fn after Reap destroys an expired session. It runs
+// without the session lock held.
+func WithReapHook(fn func(SessionExpiry)) SessionOption {
+	return func(sm *SessionManager) {
+		sm.onReap = fn
+	}
+}
+
+// WithJournal journals the session's ledger to j under name, which must
+// be unique among the sessions sharing j. Signing fails rather than hand
+// out a signature whose spend did not reach the journal, and activating the
+// key a crash left open under name resumes its ledger.
+func WithJournal(j *Journal, name string) SessionOption {
+	return func(sm *SessionManager) {
+		sm.journal = j
+		sm.journalID = name
+	}
 }
 
 // NewSessionManager creates a manager with the given default TTL.
 // No session is active until Activate is called.
-func NewSessionManager(ttl time.Duration) *SessionManager {
-	return &SessionManager{
-		ttl:       ttl,
-		valueUsed: new(big.Int),
+func NewSessionManager(ttl time.Duration, opts ...SessionOption) *SessionManager {
+	sm := &SessionManager{
+		ttl:     ttl,
+		vOffset: VOffsetEthereum,
+		clock:   clock.Real{},
+	}
+	for _, opt := range opts {
+		opt(sm)
+	}
+	return sm
+}
+
+// Clock returns the session's time source.
+func (sm *SessionManager) Clock() clock.Clock {
+	return sm.clock
+}
+
+// SetLimitProfiles installs scheduled limit profiles. They apply to the
+// current and all future sessions.
+func (sm *SessionManager) SetLimitProfiles(profiles []LimitProfile) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.profiles = make([]LimitProfile, len(profiles))
+	for i, p := range profiles {
+		if p.ApprovalAbove != nil {
+			p.ApprovalAbove = new(big.Int).Set(p.ApprovalAbove)
+		}
+		sm.profiles[i] = p
+	}
+}
+
+// SetMaxOrderValue caps the value of any one order, so a single
+// fat-fingered order cannot consume the whole cumulative limit. Nil or zero
+// removes the cap. It applies to the current and all future sessions.
+func (sm *SessionManager) SetMaxOrderValue(v *big.Int) error {
+	var max Amount
+	if v != nil {
+		var err error
+		if max, err = NewAmount(v); err != nil {
+			return err
+		}
+	}
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.maxOrderValue = max
+	return nil
+}
+
+// MaxOrderValue returns the per-order cap as a decimal string, or "" when
+// there is none.
+func (sm *SessionManager) MaxOrderValue() string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	if c := sm.orderValueCapLocked(); !c.IsZero() {
+		return c.String()
+	}
+	return ""
+}
+
+// ActiveProfile returns the name of the limit profile in effect, or "" if
+// the full session limits apply.
+func (sm *SessionManager) ActiveProfile() string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	if p := sm.activeProfileLocked(); p != nil {
+		return p.Name
+	}
+	return ""
+}
+
+// Epoch identifies the current activation. It changes every time a new
+// session is activated, letting per-session state elsewhere reset.
+func (sm *SessionManager) Epoch() uint64 {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.epoch
+}
+
+// ActivatedAt returns when the current session was activated or
+// imported, or the zero time if none ever was.
+func (sm *SessionManager) ActivatedAt() time.Time {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.activatedAt
+}
+
+// Activate seals keyBytes, a 32-byte secp256k1 private key, into an
+// EnclaveSigner and activates it (see ActivateSigner). The caller MUST
+// zero their copy of keyBytes after calling this.
+func (sm *SessionManager) Activate(ctx context.Context, keyBytes []byte, maxValueLimit *big.Int) error {
+	limit, err := NewAmount(maxValueLimit)
+	if err != nil {
+		return err
+	}
+	key, err := NewEnclaveSigner(keyBytes)
+	if err != nil {
+		return err
+	}
+	if err := sm.activate(ctx, key, limit); err != nil {
+		key.Destroy()
+		return err
+	}
+	return nil
+}
+
+// ActivateSigner starts a session signing with key, sets expiry, and
+// resets counters. If ctx is done first, or the key guard refuses the
+// key's address, the previous session is kept. maxValueLimit is copied;
+// later changes to it do not affect the session.
+func (sm *SessionManager) ActivateSigner(ctx context.Context, key Signer, maxValueLimit *big.Int) error {
+	limit, err := NewAmount(maxValueLimit)
+	if err != nil {
+		return err
 	}
+	return sm.activate(ctx, key, limit)
 }
 
-// Activate seals keyBytes into a memguard Enclave, sets expiry, and resets
-// counters. The caller MUST zero their copy of keyBytes after calling this.
-func (sm *SessionManager) Activate(keyBytes []byte, maxValueLimit *big.Int) error {
+func (sm *SessionManager) activate(ctx context.Context, key Signer, limit Amount) error {
+	if sm.keyGuard != nil {
+		if err := sm.keyGuard(key.Address()); err != nil {
+			return err
+		}
+	}
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	// Clear any previous session.
-	sm.enclave = nil
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	sm.enclave = memguard.NewEnclave(keyBytes)
-	sm.expiresAt = time.Now().Add(sm.ttl)
-	sm.maxValueLimit = new(big.Int).Set(maxValueLimit)
-	sm.valueUsed = new(big.Int)
+	now := sm.clock.Now()
+	expiresAt, limit, used, err := sm.openLedgerLocked(key.Address(), now.Add(sm.ttl), limit, Amount{})
+	if err != nil {
+		return err
+	}
 
-	// TODO: derive address from key via secp256k1 public key recovery.
-	sm.address = "0x0000000000000000000000000000000000000000"
+	// Replace any previous session.
+	sm.handoff = nil
+	if sm.key != key {
+		destroyKey(sm.key)
+	}
+	sm.key = key
+	sm.activatedAt = now
+	sm.lastActive = now
+	sm.expiresAt = expiresAt
+	sm.maxValueLimit = limit
+	sm.valueUsed = used
+	sm.releasable = releaseLedger{}
+	sm.resetWindowsLocked()
+	sm.resetOrderLimitsLocked()
+	sm.paused = nil
+	sm.clamp = sessionClamp{}
+	sm.epoch++
 
 	return nil
 }
 
-// Sign opens the enclave momentarily, performs signing (currently stubbed),
-// and destroys the locked buffer. It enforces session active, TTL, and
-// cumulative value limit checks.
-func (sm *SessionManager) Sign(orderValue *big.Int) ([]byte, error) {
+// Renew extends the active session to expire d from now, at most one TTL,
+// without touching the key, the value used or the epoch. It never
+// shortens the session. Renewal is refused once the session has expired or
+// while a handoff is pending; callers gate it on a fresh operator
+// credential (see Elevator.Reauthenticate).
+func (sm *SessionManager) Renew(ctx context.Context, d time.Duration) (time.Time, error) {
+	if d <= 0 || d > sm.ttl {
+		return time.Time{}, fmt.Errorf("%w: duration must be in (0, %s]", ErrInvalidRenewal, sm.ttl)
+	}
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	if sm.enclave == nil {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, err
+	}
+	if sm.key == nil {
+		return time.Time{}, ErrNoActiveSession
+	}
+	if sm.isExpired() {
+		sm.destroyLocked()
+		return time.Time{}, ErrSessionExpired
+	}
+	if sm.handoff != nil {
+		return time.Time{}, ErrHandoffPending
+	}
+
+	if next := sm.clock.Now().Add(d); next.After(sm.expiresAt) {
+		if err := sm.journalLocked(JournalRenew, sm.key.Address(), next, sm.maxValueLimit, sm.valueUsed); err != nil {
+			return time.Time{}, err
+		}
+		sm.expiresAt = next
+	}
+	sm.lastActive = sm.clock.Now()
+	return sm.expiresAt, nil
+}
+
+// Sign has the session's Signer sign the EIP-712 digest (see
+// OrderDigest) with secp256k1. It enforces
+// session active, TTL, and cumulative value limit checks. A ctx that is
+// done by the time the lock is acquired aborts before the key is touched
+// or any value is committed. orderValue is copied on entry and must be
+// non-negative. The result is the 65-byte r ‖ s ‖ v signature.
+func (sm *SessionManager) Sign(ctx context.Context, digest [32]byte, orderValue *big.Int) ([]byte, error) {
+	return sm.sign(ctx, digest, orderValue, false)
+}
+
+// SignApproved is Sign for an order a human has approved: the limit
+// profile's ApprovalAbove threshold is skipped, every other check still
+// applies.
+func (sm *SessionManager) SignApproved(ctx context.Context, digest [32]byte, orderValue *big.Int) ([]byte, error) {
+	return sm.sign(ctx, digest, orderValue, true)
+}
+
+// SignBatch is Sign for several orders, in one operation when the
+// Signer is a BatchSigner. The approval threshold applies to each of
+// orderValues and the cumulative limit to their sum, all before the key
+// is used, so the batch is
+// signed whole or not at all. Signatures are returned in digest order.
+func (sm *SessionManager) SignBatch(ctx context.Context, digests [][32]byte, orderValues []*big.Int) ([][]byte, error) {
+	return sm.signBatch(ctx, digests, orderValues, false)
+}
+
+// SignBatchApproved is SignBatch for orders a human has approved.
+func (sm *SessionManager) SignBatchApproved(ctx context.Context, digests [][32]byte, orderValues []*big.Int) ([][]byte, error) {
+	return sm.signBatch(ctx, digests, orderValues, true)
+}
+
+func (sm *SessionManager) sign(ctx context.Context, digest [32]byte, orderValue *big.Int, approved bool) ([]byte, error) {
+	sigs, err := sm.signBatch(ctx, [][32]byte{digest}, []*big.Int{orderValue}, approved)
+	if err != nil {
+		return nil, err
+	}
+	return sigs[0], nil
+}
+
+func (sm *SessionManager) signBatch(ctx context.Context, digests [][32]byte, orderValues []*big.Int, approved bool) ([][]byte, error) {
+	if len(digests) != len(orderValues) {
+		return nil, errors.New("digests and order values differ in length")
+	}
+	values := make([]Amount, len(orderValues))
+	for i, v := range orderValues {
+		value, err := NewAmount(v)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if sm.key == nil {
 		return nil, ErrNoActiveSession
 	}
 
@@ -73,29 +366,84 @@ func (sm *SessionManager) Sign(orderValue *big.Int) ([]byte, error) {
 		return nil, ErrSessionExpired
 	}
 
+	if sm.handoff != nil {
+		return nil, ErrHandoffPending
+	}
+
+	if sm.paused != nil {
+		return nil, ErrSessionPaused
+	}
+
+	// Apply any scheduled limit profile before the cumulative check.
+	limit := sm.maxValueLimit
+	p := sm.activeProfileLocked()
+	if p != nil {
+		limit = p.scaledLimit(sm.maxValueLimit)
+	}
+
 	// Check cumulative value limit.
-	newTotal := new(big.Int).Add(sm.valueUsed, orderValue)
-	if newTotal.Cmp(sm.maxValueLimit) > 0 {
+	newTotal, batch, orders := sm.valueUsed, Amount{}, 0
+	for _, value := range values {
+		// Approval relaxes a profile's threshold, never the cap.
+		if c := sm.orderValueCapLocked(); !c.IsZero() && value.Cmp(c) > 0 {
+			return nil, ErrOrderValueExceeded
+		}
+		if !approved && p != nil && p.ApprovalAbove != nil && value.BigInt().Cmp(p.ApprovalAbove) > 0 {
+			return nil, ErrApprovalRequired
+		}
+		var err error
+		if newTotal, err = newTotal.Add(value); err != nil {
+			return nil, ErrValueLimitExceeded
+		}
+		batch, _ = batch.Add(value)
+		if !value.IsZero() {
+			orders++
+		}
+	}
+	if newTotal.Cmp(limit) > 0 {
 		return nil, ErrValueLimitExceeded
 	}
+	now := sm.clock.Now()
+	if err := sm.checkWindowsLocked(now, batch); err != nil {
+		return nil, err
+	}
+	if err := sm.checkOrderLimitsLocked(now, len(digests), orders); err != nil {
+		return nil, err
+	}
 
-	// Open the enclave into a LockedBuffer for signing.
-	buf, err := sm.enclave.Open()
+	sigs, err := signDigests(sm.key, digests)
 	if err != nil {
 		return nil, err
 	}
+	// A Signer other than EnclaveSigner may hand back a high s or the
+	// other v convention; never let one reach the exchange.
+	for i := range sigs {
+		if sigs[i], err = NormalizeSignature(sigs[i], sm.vOffset); err != nil {
+			return nil, err
+		}
+	}
 
-	// TODO: perform EIP-712 typed-data hashing + ECDSA sign with buf.Bytes().
-	// For now, return a 65-byte placeholder signature.
-	_ = buf.Bytes()
-	sig := make([]byte, 65)
-
-	buf.Destroy()
-
-	// Commit value usage only after successful signing.
-	sm.valueUsed.Set(newTotal)
+	// Commit value usage only after successful signing, and journal it
+	// before any signature leaves.
+	if err := sm.journalLocked(JournalCommit, sm.key.Address(), sm.expiresAt, sm.maxValueLimit, newTotal, digests...); err != nil {
+		return nil, err
+	}
+	sm.valueUsed = newTotal
+	client := quotaClient(ctx)
+	for i, digest := range digests {
+		sm.releasable.record(digest, values[i], now, client)
+	}
+	for i := range sm.windows {
+		sm.windows[i].add(now, batch)
+	}
+	sm.countOrdersLocked(len(digests), orders)
+	// Valueless signatures (CLOB auth, permits, typed data) are what an
+	// unattended client keeps making; only orders count as activity.
+	if !batch.IsZero() {
+		sm.lastActive = now
+	}
 
-	return sig, nil
+	return sigs, nil
 }
 
 // Status returns a read-only snapshot of the current session state.
@@ -104,7 +452,7 @@ func (sm *SessionManager) Status() (active bool, ttlRemaining int64, maxLimit st
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
-	if sm.enclave == nil {
+	if sm.key == nil {
 		return false, 0, "0", "0", ""
 	}
 
@@ -112,30 +460,155 @@ func (sm *SessionManager) Status() (active bool, ttlRemaining int64, maxLimit st
 		return false, 0, "0", "0", ""
 	}
 
-	remaining := time.Until(sm.expiresAt).Seconds()
+	remaining := sm.expiresAt.Sub(sm.clock.Now()).Seconds()
 	if remaining < 0 {
 		remaining = 0
 	}
 
-	return true, int64(remaining), sm.maxValueLimit.String(), sm.valueUsed.String(), sm.address
+	return true, int64(remaining), sm.maxValueLimit.String(), sm.valueUsed.String(), sm.key.Address()
 }
 
-// Destroy zeroes and destroys the enclave, resetting all session state.
+// Destroy drops the session's Signer, destroying it if it is an
+// EnclaveSigner, and resets all session state.
 func (sm *SessionManager) Destroy() {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 	sm.destroyLocked()
 }
 
+// SessionExpiry describes a session the reaper destroyed.
+type SessionExpiry struct {
+	Address   string    `json:"address"`
+	Epoch     uint64    `json:"epoch"`
+	ExpiredAt time.Time `json:"expired_at"`
+	ReapedAt  time.Time `json:"reaped_at"`
+	Idle      bool      `json:"idle,omitempty"` // ended by the idle timeout, not the TTL
+}
+
+// Reap destroys the session if its TTL or idle timeout has elapsed,
+// reporting whether it did. Expiry is otherwise only noticed by the next call that needs the
+// key, which leaves the key in memory for as long as the session sits
+// idle.
+func (sm *SessionManager) Reap() bool {
+	sm.mu.Lock()
+	if sm.key == nil || !sm.isExpired() {
+		sm.mu.Unlock()
+		return false
+	}
+	deadline, idle := sm.deadlineLocked()
+	ev := SessionExpiry{
+		Address:   sm.key.Address(),
+		Epoch:     sm.epoch,
+		ExpiredAt: deadline,
+		ReapedAt:  sm.clock.Now(),
+		Idle:      idle,
+	}
+	sm.destroyLocked()
+	sm.mu.Unlock()
+
+	if sm.onReap != nil {
+		sm.onReap(ev)
+	}
+	return true
+}
+
+// Run reaps an expired session within interval of its expiry. It returns
+// when ctx is done.
+func (sm *SessionManager) Run(ctx context.Context, interval time.Duration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sm.clock.After(interval):
+			sm.Reap()
+		}
+	}
+}
+
 // destroyLocked performs the actual cleanup. Caller must hold sm.mu.
 func (sm *SessionManager) destroyLocked() {
-	sm.enclave = nil
-	sm.address = ""
-	sm.valueUsed = new(big.Int)
-	sm.maxValueLimit = nil
+	if sm.key != nil {
+		// Nothing is left to spend; a failed close only means a restart
+		// resumes a ledger that is already settled.
+		_ = sm.journalLocked(JournalClose, sm.key.Address(), time.Time{}, Amount{}, Amount{})
+	}
+	destroyKey(sm.key)
+	sm.key = nil
+	sm.valueUsed = Amount{}
+	sm.maxValueLimit = Amount{}
+	sm.releasable = releaseLedger{}
+	sm.resetWindowsLocked()
+	sm.resetOrderLimitsLocked()
+	sm.handoff = nil
+	sm.paused = nil
+	sm.clamp = sessionClamp{}
+}
+
+// openLedgerLocked journals the ledger of a session starting on address.
+// A crash that ended the last session on the same key left its ledger
+// unsettled; the new session keeps the earlier expiry, the lower limit and
+// the higher spend of the two, rather than the limit being granted again.
+// Caller must hold sm.mu.
+func (sm *SessionManager) openLedgerLocked(address string, expiresAt time.Time, limit, used Amount) (time.Time, Amount, Amount, error) {
+	if sm.journal == nil {
+		return expiresAt, limit, used, nil
+	}
+	prevExpiry, prevLimit, prevUsed, ok, err := sm.journal.recoveredLedger(sm.journalID, address)
+	if err != nil {
+		return time.Time{}, Amount{}, Amount{}, err
+	}
+	if ok && sm.clock.Now().Before(prevExpiry) {
+		if prevExpiry.Before(expiresAt) {
+			expiresAt = prevExpiry
+		}
+		if prevLimit.Cmp(limit) < 0 {
+			limit = prevLimit
+		}
+		if prevUsed.Cmp(used) > 0 {
+			used = prevUsed
+		}
+	}
+	if err := sm.journalLocked(JournalOpen, address, expiresAt, limit, used); err != nil {
+		return time.Time{}, Amount{}, Amount{}, err
+	}
+	return expiresAt, limit, used, nil
+}
+
+// journalLocked appends the session's ledger to its journal, if it has
+// one, with the digests of any orders the change signed. Caller must hold
+// sm.mu.
+func (sm *SessionManager) journalLocked(kind, address string, expiresAt time.Time, limit, used Amount, orders ...[32]byte) error {
+	if sm.journal == nil {
+		return nil
+	}
+	e := JournalEntry{Kind: kind, Session: sm.journalID, Address: address, ExpiresAt: expiresAt, At: sm.clock.Now()}
+	if kind != JournalClose {
+		e.Limit, e.Used = limit.String(), used.String()
+	}
+	for _, d := range orders {
+		e.Orders = append(e.Orders, "0x"+hex.EncodeToString(d[:]))
+	}
+	if err := sm.journal.append(e); err != nil {
+		return fmt.Errorf("journal %s: %w", kind, err)
+	}
+	return nil
+}
+
+// activeProfileLocked returns the first profile whose window contains the
+// current time, or nil. Caller must hold sm.mu.
+func (sm *SessionManager) activeProfileLocked() *LimitProfile {
+	now := sm.clock.Now()
+	for i := range sm.profiles {
+		if sm.profiles[i].Active(now) {
+			return &sm.profiles[i]
+		}
+	}
+	return nil
 }
 
-// isExpired checks whether the session TTL has elapsed. Caller must hold sm.mu.
+// isExpired checks whether the session TTL or idle timeout has elapsed.
+// Caller must hold sm.mu.
 func (sm *SessionManager) isExpired() bool {
-	return time.Now().After(sm.expiresAt)
+	deadline, _ := sm.deadlineLocked()
+	return sm.clock.Now().After(deadline)
 }
@@ -0,0 +1,206 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+)
+
+func TestSessionExpiresOnClock(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	sm := NewSessionManager(time.Hour, WithClock(clk))
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	defer sm.Destroy()
+
+	clk.Advance(59 * time.Minute)
+	if _, ttl, _, _, _ := sm.Status(); ttl != 60 {
+		t.Errorf("ttl = %d, want 60", ttl)
+	}
+	if _, err := sm.Sign(context.Background(), [32]byte{}, big.NewInt(1)); err != nil {
+		t.Fatalf("sign before expiry: %v", err)
+	}
+
+	clk.Advance(time.Minute + time.Nanosecond)
+	if active, _, _, _, _ := sm.Status(); active {
+		t.Error("expected session inactive after TTL")
+	}
+	if _, err := sm.Sign(context.Background(), [32]byte{}, big.NewInt(1)); !errors.Is(err, ErrSessionExpired) {
+		t.Errorf("expected ErrSessionExpired, got %v", err)
+	}
+}
+
+func TestSessionRenew(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	sm := NewSessionManager(time.Hour, WithClock(clk))
+	if _, err := sm.Renew(context.Background(), time.Hour); !errors.Is(err, ErrNoActiveSession) {
+		t.Errorf("no session: expected ErrNoActiveSession, got %v", err)
+	}
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	defer sm.Destroy()
+	if _, err := sm.Sign(context.Background(), [32]byte{}, big.NewInt(400)); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	epoch := sm.Epoch()
+
+	for _, d := range []time.Duration{0, -time.Minute, time.Hour + time.Second} {
+		if _, err := sm.Renew(context.Background(), d); !errors.Is(err, ErrInvalidRenewal) {
+			t.Errorf("%s: expected ErrInvalidRenewal, got %v", d, err)
+		}
+	}
+
+	clk.Advance(50 * time.Minute)
+	expiresAt, err := sm.Renew(context.Background(), time.Hour)
+	if err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	if want := clk.Now().Add(time.Hour); !expiresAt.Equal(want) {
+		t.Errorf("expires at %s, want %s", expiresAt, want)
+	}
+	// A shorter renewal does not cut the session short.
+	if again, err := sm.Renew(context.Background(), time.Minute); err != nil || !again.Equal(expiresAt) {
+		t.Errorf("shorter renewal: got %s, %v; want %s", again, err, expiresAt)
+	}
+
+	// The key, value used and epoch carry over.
+	clk.Advance(59 * time.Minute)
+	active, ttl, _, used, addr := sm.Status()
+	if !active || ttl != 60 || used != "400" || addr != testMaker || sm.Epoch() != epoch {
+		t.Errorf("after renewal: active=%v ttl=%d used=%s addr=%s epoch=%d", active, ttl, used, addr, sm.Epoch())
+	}
+
+	clk.Advance(time.Minute + time.Nanosecond)
+	if _, err := sm.Renew(context.Background(), time.Hour); !errors.Is(err, ErrSessionExpired) {
+		t.Errorf("after expiry: expected ErrSessionExpired, got %v", err)
+	}
+}
+
+func TestSessionReaper(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	reaped := make(chan SessionExpiry, 1)
+	sm := NewSessionManager(time.Hour, WithClock(clk), WithReapHook(func(ev SessionExpiry) { reaped <- ev }))
+	if sm.Reap() {
+		t.Error("reaped with no session")
+	}
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	defer sm.Destroy()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sm.Run(ctx, 5*time.Second)
+	tick := func(d time.Duration) {
+		for clk.Waiters() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		clk.Advance(d)
+	}
+
+	tick(time.Hour)
+	select {
+	case ev := <-reaped:
+		t.Fatalf("reaped a live session: %+v", ev)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// Expiry is noticed on the next tick, with no call touching the key.
+	tick(5 * time.Second)
+	ev := <-reaped
+	if ev.Address != testMaker || ev.Epoch != sm.Epoch() || !ev.ExpiredAt.Equal(start.Add(time.Hour)) || !ev.ReapedAt.Equal(clk.Now()) {
+		t.Errorf("reaped %+v", ev)
+	}
+	if _, err := sm.Sign(context.Background(), [32]byte{}, big.NewInt(1)); !errors.Is(err, ErrNoActiveSession) {
+		t.Errorf("expected ErrNoActiveSession after reaping, got %v", err)
+	}
+	if sm.Reap() {
+		t.Error("reaped twice")
+	}
+}
+
+func TestSessionMaxOrderValue(t *testing.T) {
+	sm := NewSessionManager(time.Hour)
+	if err := sm.SetMaxOrderValue(big.NewInt(-1)); err == nil {
+		t.Error("expected a negative cap refused")
+	}
+	if err := sm.SetMaxOrderValue(big.NewInt(300)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	defer sm.Destroy()
+
+	if _, err := sm.Sign(context.Background(), [32]byte{1}, big.NewInt(300)); err != nil {
+		t.Fatalf("order at the cap: %v", err)
+	}
+	if _, err := sm.Sign(context.Background(), [32]byte{2}, big.NewInt(301)); !errors.Is(err, ErrOrderValueExceeded) {
+		t.Errorf("expected ErrOrderValueExceeded, got %v", err)
+	}
+	// Approval does not lift the cap, and one capped order refuses its batch.
+	if _, err := sm.SignApproved(context.Background(), [32]byte{3}, big.NewInt(301)); !errors.Is(err, ErrOrderValueExceeded) {
+		t.Errorf("approved: expected ErrOrderValueExceeded, got %v", err)
+	}
+	if _, err := sm.SignBatch(context.Background(), [][32]byte{{4}, {5}}, []*big.Int{big.NewInt(1), big.NewInt(400)}); !errors.Is(err, ErrOrderValueExceeded) {
+		t.Errorf("batch: expected ErrOrderValueExceeded, got %v", err)
+	}
+	if _, _, _, used, _ := sm.Status(); used != "300" || sm.MaxOrderValue() != "300" {
+		t.Errorf("used %s, cap %s", used, sm.MaxOrderValue())
+	}
+
+	if err := sm.SetMaxOrderValue(nil); err != nil || sm.MaxOrderValue() != "" {
+		t.Fatalf("clear cap: %q, %v", sm.MaxOrderValue(), err)
+	}
+	if _, err := sm.Sign(context.Background(), [32]byte{6}, big.NewInt(500)); err != nil {
+		t.Errorf("uncapped: %v", err)
+	}
+}
+
+func TestSessionProfileFollowsClock(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 7, 59, 0, 0, time.UTC))
+	sm := NewSessionManager(24*time.Hour, WithClock(clk))
+	sm.SetLimitProfiles([]LimitProfile{{Name: "night", Start: 0, End: 8 * time.Hour, LimitPct: 10}})
+
+	if got := sm.ActiveProfile(); got != "night" {
+		t.Errorf("at 07:59 profile = %q, want night", got)
+	}
+	clk.Advance(time.Minute)
+	if got := sm.ActiveProfile(); got != "" {
+		t.Errorf("at 08:00 profile = %q, want none", got)
+	}
+}
+
+func TestSignHonorsCancelledContext(t *testing.T) {
+	sm := activeSession(t, 1000)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := sm.Sign(ctx, [32]byte{}, big.NewInt(100)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, _, _, used, _ := sm.Status(); used != "0" {
+		t.Errorf("cancelled sign must not commit value, used = %s", used)
+	}
+}
+
+func TestActivateDerivesAddress(t *testing.T) {
+	sm := NewSessionManager(time.Hour)
+	if err := sm.Activate(context.Background(), make([]byte, 32), big.NewInt(1000)); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("zero key: expected ErrInvalidKey, got %v", err)
+	}
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1000)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	defer sm.Destroy()
+	if _, _, _, _, addr := sm.Status(); addr != testMaker {
+		t.Errorf("address = %s, want %s", addr, testMaker)
+	}
+}
@@ -0,0 +1,129 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrSessionPaused    = errors.New("session is paused")
+	ErrSessionNotPaused = errors.New("session is not paused")
+)
+
+// SessionPausedReason is the ErrorInfo reason of signing refused because
+// the session is paused.
+const SessionPausedReason = "SESSION_PAUSED"
+
+// SessionPause records who paused a session and why.
+type SessionPause struct {
+	Client string    `json:"client"`
+	Reason string    `json:"reason"`
+	At     time.Time `json:"at"`
+}
+
+// SessionPauseEvent is an audit record of a session pause or resume.
+type SessionPauseEvent struct {
+	SessionPause
+	SessionID string `json:"session_id,omitempty"`
+	Address   string `json:"address"`
+	Resumed   bool   `json:"resumed,omitempty"`
+}
+
+// Pause freezes signing in the active session: every Sign fails with
+// ErrSessionPaused until Resume. The key stays sealed in its enclave and
+// the value used, windows and order counts are kept, so trading can resume
+// without re-entering the key. The TTL keeps running. Pausing a paused
+// session replaces its reason.
+func (sm *SessionManager) Pause(client, reason string) (SessionPause, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.key == nil {
+		return SessionPause{}, ErrNoActiveSession
+	}
+	if sm.isExpired() {
+		sm.destroyLocked()
+		return SessionPause{}, ErrSessionExpired
+	}
+	sm.paused = &SessionPause{Client: client, Reason: reason, At: sm.clock.Now()}
+	return *sm.paused, nil
+}
+
+// Resume lets the session sign again and returns the pause it lifted.
+func (sm *SessionManager) Resume() (SessionPause, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.paused == nil {
+		return SessionPause{}, ErrSessionNotPaused
+	}
+	p := *sm.paused
+	sm.paused = nil
+	return p, nil
+}
+
+// Paused returns the pause in force, if the session is paused.
+func (sm *SessionManager) Paused() (SessionPause, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	if sm.paused == nil {
+		return SessionPause{}, false
+	}
+	return *sm.paused, true
+}
+
+func sessionPausedStatus() error {
+	st := status.New(codes.FailedPrecondition, ErrSessionPaused.Error())
+	info := &errdetails.ErrorInfo{Reason: SessionPausedReason, Domain: ErrorDomain}
+	if withDetails, err := st.WithDetails(info); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// WithSessionPauseAudit calls onEvent for every PauseSession and
+// ResumeSession.
+func WithSessionPauseAudit(onEvent func(SessionPauseEvent)) Option {
+	return func(h *Handler) {
+		h.onPause = onEvent
+	}
+}
+
+// PauseSession freezes signing in the session without destroying it.
+func (h *Handler) PauseSession(ctx context.Context, req *signerv1.PauseSessionRequest) (*signerv1.PauseSessionResponse, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required")
+	}
+	p, err := h.session.Pause(ClientID(ctx), req.Reason)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+	if h.onPause != nil {
+		_, _, _, _, addr := h.session.Status()
+		h.onPause(SessionPauseEvent{SessionPause: p, SessionID: h.id, Address: addr})
+	}
+	return &signerv1.PauseSessionResponse{PausedAt: p.At.UnixNano()}, nil
+}
+
+// ResumeSession lets a paused session sign again.
+func (h *Handler) ResumeSession(ctx context.Context, _ *signerv1.ResumeSessionRequest) (*signerv1.ResumeSessionResponse, error) {
+	p, err := h.session.Resume()
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+	if h.onPause != nil {
+		_, _, _, _, addr := h.session.Status()
+		h.onPause(SessionPauseEvent{
+			SessionPause: SessionPause{Client: ClientID(ctx), Reason: p.Reason, At: h.session.Clock().Now()},
+			SessionID:    h.id,
+			Address:      addr,
+			Resumed:      true,
+		})
+	}
+	return &signerv1.ResumeSessionResponse{}, nil
+}
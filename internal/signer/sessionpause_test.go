@@ -0,0 +1,115 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPauseSession(t *testing.T) {
+	sm := activeSession(t, 1_000_000)
+	var events []SessionPauseEvent
+	h := NewHandler(sm, WithSessionPauseAudit(func(ev SessionPauseEvent) { events = append(events, ev) }))
+	ctx := context.Background()
+	sign := func() error {
+		_, err := h.SignOrder(ctx, &signerv1.SignOrderRequest{Order: &signerv1.PolymarketOrder{
+			Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "1000", TakerAmount: "2000",
+		}})
+		return err
+	}
+	if err := sign(); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	if _, err := h.PauseSession(ctx, &signerv1.PauseSessionRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("no reason: expected InvalidArgument, got %v", err)
+	}
+	if _, err := h.ResumeSession(ctx, &signerv1.ResumeSessionRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("resume unpaused: expected FailedPrecondition, got %v", err)
+	}
+	if _, err := h.PauseSession(ctx, &signerv1.PauseSessionRequest{Reason: "exchange incident"}); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+
+	err := sign()
+	if status.Code(err) != codes.FailedPrecondition || rejectionReason(err) != SessionPausedReason {
+		t.Fatalf("paused: expected SESSION_PAUSED, got %v", err)
+	}
+	if _, err := h.SignClobAuth(ctx, &signerv1.SignClobAuthRequest{}); rejectionReason(err) != SessionPausedReason {
+		t.Errorf("clob auth while paused: expected SESSION_PAUSED, got %v", err)
+	}
+	st, _ := h.GetSessionStatus(ctx, &signerv1.GetSessionStatusRequest{})
+	if !st.Active || !st.Paused || st.PauseReason != "exchange incident" || st.ValueUsed != "1000" {
+		t.Errorf("status while paused %+v", st)
+	}
+
+	// Resuming keeps the key and the value already used.
+	if _, err := h.ResumeSession(ctx, &signerv1.ResumeSessionRequest{}); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if err := sign(); err != nil {
+		t.Fatalf("sign after resume: %v", err)
+	}
+	if st, _ := h.GetSessionStatus(ctx, &signerv1.GetSessionStatusRequest{}); st.Paused || st.ValueUsed != "2000" {
+		t.Errorf("status after resume %+v", st)
+	}
+
+	if len(events) != 2 || events[0].Resumed || events[0].Reason != "exchange incident" || !events[1].Resumed || events[1].Address == "" {
+		t.Errorf("events %+v", events)
+	}
+}
+
+func TestPausedSessionNotExported(t *testing.T) {
+	sm := activeSession(t, 1_000_000)
+	h := NewHandler(sm)
+	dst := NewSessionManager(time.Hour)
+	t.Cleanup(dst.Destroy)
+	pub, err := dst.PrepareImport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.Pause("ops", "incident"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The import would start unpaused, so a pause cannot be shed by
+	// moving the session.
+	_, err = h.ExportSession(context.Background(), &signerv1.ExportSessionRequest{DestinationPublicKey: pub})
+	if rejectionReason(err) != SessionPausedReason {
+		t.Fatalf("export while paused: expected SESSION_PAUSED, got %v", err)
+	}
+	if err := sm.AbortExport(); !errors.Is(err, ErrNoHandoff) {
+		t.Errorf("a refused export should leave no handoff pending, got %v", err)
+	}
+
+	if _, err := sm.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.Export(context.Background(), pub); err != nil {
+		t.Errorf("export after resume: %v", err)
+	}
+}
+
+func TestPauseClearedByActivate(t *testing.T) {
+	sm := activeSession(t, 1_000_000)
+	if _, err := sm.Pause("ops", "incident"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1_000_000)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if _, paused := sm.Paused(); paused {
+		t.Error("a new session should start unpaused")
+	}
+
+	sm.Destroy()
+	if _, err := sm.Pause("ops", "incident"); err != ErrNoActiveSession {
+		t.Errorf("pause without a session: expected ErrNoActiveSession, got %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrLoosening            = errors.New("session policy can only be tightened")
+	ErrInvalidSessionPolicy = errors.New("invalid session policy")
+)
+
+// SessionTightening lowers the limits of the active session. Zero fields
+// are left as they are.
+type SessionTightening struct {
+	MaxValueLimit *big.Int      // cumulative value limit
+	MaxOrderValue *big.Int      // per-order cap
+	MaxOrders     int64         // orders the session may sign in total
+	ExpiresIn     time.Duration // time left before the session expires
+}
+
+// sessionClamp holds caps tightened for the current session alone, over
+// the ones configured for every session. Zero fields clamp nothing.
+type sessionClamp struct {
+	maxOrderValue Amount
+	maxOrders     int64
+}
+
+// SessionPolicy is the active session's limits after a tightening.
+type SessionPolicy struct {
+	ExpiresAt     time.Time `json:"expires_at"`
+	MaxValueLimit string    `json:"max_value_limit"`
+	MaxOrderValue string    `json:"max_order_value,omitempty"`
+	MaxOrders     int64     `json:"max_orders,omitempty"`
+}
+
+// SessionPolicyEvent is an audit record of an UpdateSessionPolicy.
+type SessionPolicyEvent struct {
+	SessionPolicy
+	SessionID string    `json:"session_id,omitempty"`
+	Address   string    `json:"address"`
+	Client    string    `json:"client"`
+	Reason    string    `json:"reason"`
+	At        time.Time `json:"at"`
+}
+
+// Tighten lowers the active session's limits or brings its expiry
+// forward, keeping the key, the value used and the epoch. It never raises
+// a limit or extends the session: a change that would fails the whole
+// update with ErrLoosening, and loosening again takes a new Activate or
+// RenewSession. A value limit below the value used stops all further
+// signing. The per-order and order caps hold until the session ends, and
+// travel with it in a handoff; the value limit and expiry are journaled
+// and survive a restart or handoff.
+func (sm *SessionManager) Tighten(ctx context.Context, t SessionTightening) (SessionPolicy, error) {
+	var limit, orderValue Amount
+	var err error
+	if t.MaxValueLimit != nil {
+		if limit, err = NewAmount(t.MaxValueLimit); err != nil {
+			return SessionPolicy{}, fmt.Errorf("%w: value limit: %v", ErrInvalidSessionPolicy, err)
+		}
+	}
+	if t.MaxOrderValue != nil {
+		if orderValue, err = NewAmount(t.MaxOrderValue); err != nil {
+			return SessionPolicy{}, fmt.Errorf("%w: per-order cap: %v", ErrInvalidSessionPolicy, err)
+		}
+		if orderValue.IsZero() {
+			return SessionPolicy{}, fmt.Errorf("%w: a zero per-order cap means none; pause the session instead", ErrInvalidSessionPolicy)
+		}
+	}
+	if t.MaxOrders < 0 || t.ExpiresIn < 0 {
+		return SessionPolicy{}, fmt.Errorf("%w: order cap and TTL must not be negative", ErrInvalidSessionPolicy)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return SessionPolicy{}, err
+	}
+	if sm.key == nil {
+		return SessionPolicy{}, ErrNoActiveSession
+	}
+	if sm.isExpired() {
+		sm.destroyLocked()
+		return SessionPolicy{}, ErrSessionExpired
+	}
+	if sm.handoff != nil {
+		return SessionPolicy{}, ErrHandoffPending
+	}
+
+	newLimit, expiresAt, clamp := sm.maxValueLimit, sm.expiresAt, sm.clamp
+	if t.MaxValueLimit != nil {
+		if limit.Cmp(newLimit) > 0 {
+			return SessionPolicy{}, fmt.Errorf("%w: value limit %s is above %s", ErrLoosening, limit, newLimit)
+		}
+		newLimit = limit
+	}
+	if t.MaxOrderValue != nil {
+		if current := sm.orderValueCapLocked(); !current.IsZero() && orderValue.Cmp(current) > 0 {
+			return SessionPolicy{}, fmt.Errorf("%w: per-order cap %s is above %s", ErrLoosening, orderValue, current)
+		}
+		clamp.maxOrderValue = orderValue
+	}
+	if t.MaxOrders > 0 {
+		if current := sm.orderCapLocked(); current > 0 && t.MaxOrders > current {
+			return SessionPolicy{}, fmt.Errorf("%w: order cap %d is above %d", ErrLoosening, t.MaxOrders, current)
+		}
+		clamp.maxOrders = t.MaxOrders
+	}
+	if t.ExpiresIn > 0 {
+		next := sm.clock.Now().Add(t.ExpiresIn)
+		if next.After(sm.expiresAt) {
+			return SessionPolicy{}, fmt.Errorf("%w: the session already expires at %s", ErrLoosening, sm.expiresAt.Format(time.RFC3339))
+		}
+		expiresAt = next
+	}
+
+	if newLimit.Cmp(sm.maxValueLimit) != 0 || !expiresAt.Equal(sm.expiresAt) {
+		if err := sm.journalLocked(JournalTighten, sm.key.Address(), expiresAt, newLimit, sm.valueUsed); err != nil {
+			return SessionPolicy{}, err
+		}
+	}
+	sm.maxValueLimit, sm.expiresAt, sm.clamp = newLimit, expiresAt, clamp
+	return sm.policyLocked(), nil
+}
+
+// policyLocked returns the session's limits in force. Caller must hold
+// sm.mu.
+func (sm *SessionManager) policyLocked() SessionPolicy {
+	p := SessionPolicy{ExpiresAt: sm.expiresAt, MaxValueLimit: sm.maxValueLimit.String(), MaxOrders: sm.orderCapLocked()}
+	if c := sm.orderValueCapLocked(); !c.IsZero() {
+		p.MaxOrderValue = c.String()
+	}
+	return p
+}
+
+// orderValueCapLocked returns the per-order cap in force, the lower of the
+// configured and the tightened one, or zero for none. Caller must hold
+// sm.mu.
+func (sm *SessionManager) orderValueCapLocked() Amount {
+	if c := sm.clamp.maxOrderValue; !c.IsZero() && (sm.maxOrderValue.IsZero() || c.Cmp(sm.maxOrderValue) < 0) {
+		return c
+	}
+	return sm.maxOrderValue
+}
+
+// orderCapLocked returns the order cap in force, or zero for none. Caller
+// must hold sm.mu.
+func (sm *SessionManager) orderCapLocked() int64 {
+	if c := sm.clamp.maxOrders; c > 0 && (sm.maxOrders == 0 || c < sm.maxOrders) {
+		return c
+	}
+	return sm.maxOrders
+}
+
+// WithSessionPolicyAudit calls onEvent for every UpdateSessionPolicy that
+// tightened the session.
+func WithSessionPolicyAudit(onEvent func(SessionPolicyEvent)) Option {
+	return func(h *Handler) {
+		h.onTighten = onEvent
+	}
+}
+
+// UpdateSessionPolicy lowers the session's limits or shortens its TTL
+// without ending it.
+func (h *Handler) UpdateSessionPolicy(ctx context.Context, req *signerv1.UpdateSessionPolicyRequest) (*signerv1.UpdateSessionPolicyResponse, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required")
+	}
+	var t SessionTightening
+	for _, f := range []struct {
+		name, value string
+		dst         **big.Int
+	}{
+		{"max_value_limit", req.MaxValueLimit, &t.MaxValueLimit},
+		{"max_order_value", req.MaxOrderValue, &t.MaxOrderValue},
+	} {
+		if f.value == "" {
+			continue
+		}
+		v, ok := new(big.Int).SetString(f.value, 10)
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid %s %q", f.name, f.value)
+		}
+		*f.dst = v
+	}
+	t.MaxOrders = req.MaxOrders
+	t.ExpiresIn = time.Duration(req.TtlSeconds) * time.Second
+	if t == (SessionTightening{}) {
+		return nil, status.Errorf(codes.InvalidArgument, "nothing to update")
+	}
+
+	p, err := h.session.Tighten(ctx, t)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrLoosening), errors.Is(err, ErrInvalidSessionPolicy):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	case errors.Is(err, ErrNoActiveSession), errors.Is(err, ErrSessionExpired), errors.Is(err, ErrHandoffPending):
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	default:
+		return nil, status.FromContextError(err).Err()
+	}
+	if h.onTighten != nil {
+		_, _, _, _, addr := h.session.Status()
+		h.onTighten(SessionPolicyEvent{
+			SessionPolicy: p,
+			SessionID:     h.id,
+			Address:       addr,
+			Client:        ClientID(ctx),
+			Reason:        req.Reason,
+			At:            h.session.Clock().Now(),
+		})
+	}
+	return &signerv1.UpdateSessionPolicyResponse{
+		ExpiresAt:     p.ExpiresAt.UnixNano(),
+		MaxValueLimit: p.MaxValueLimit,
+		MaxOrderValue: p.MaxOrderValue,
+		MaxOrders:     p.MaxOrders,
+	}, nil
+}
@@ -0,0 +1,99 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUpdateSessionPolicyTightensOnly(t *testing.T) {
+	sm := activeSession(t, 1_000_000)
+	if err := sm.SetMaxOrderValue(big.NewInt(5000)); err != nil {
+		t.Fatal(err)
+	}
+	var events []SessionPolicyEvent
+	h := NewHandler(sm, WithSessionPolicyAudit(func(ev SessionPolicyEvent) { events = append(events, ev) }))
+	ctx := context.Background()
+	sign := func(maker string) error {
+		_, err := h.SignOrder(ctx, &signerv1.SignOrderRequest{Order: &signerv1.PolymarketOrder{
+			Maker: testMaker, Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: maker, TakerAmount: "2000",
+		}})
+		return err
+	}
+
+	for _, req := range []*signerv1.UpdateSessionPolicyRequest{
+		{MaxValueLimit: "2000000", Reason: "raise"},
+		{MaxOrderValue: "6000", Reason: "raise"},
+		{TtlSeconds: 7200, Reason: "extend"},
+		{MaxOrderValue: "0", Reason: "zero"},
+		{MaxValueLimit: "abc", Reason: "bad"},
+		{Reason: "nothing"},
+		{MaxValueLimit: "1000"},
+	} {
+		if _, err := h.UpdateSessionPolicy(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%+v: expected InvalidArgument, got %v", req, err)
+		}
+	}
+	if len(events) != 0 {
+		t.Fatalf("refused updates were audited: %+v", events)
+	}
+
+	resp, err := h.UpdateSessionPolicy(ctx, &signerv1.UpdateSessionPolicyRequest{
+		MaxValueLimit: "3000", MaxOrderValue: "2000", MaxOrders: 5, TtlSeconds: 600, Reason: "volatile",
+	})
+	if err != nil {
+		t.Fatalf("tighten: %v", err)
+	}
+	if resp.MaxValueLimit != "3000" || resp.MaxOrderValue != "2000" || resp.MaxOrders != 5 {
+		t.Errorf("response %+v", resp)
+	}
+	if st, _ := h.GetSessionStatus(ctx, &signerv1.GetSessionStatusRequest{}); st.TtlSeconds > 600 || st.MaxOrderValue != "2000" {
+		t.Errorf("status %+v", st)
+	}
+	if len(events) != 1 || events[0].Reason != "volatile" || events[0].Address == "" {
+		t.Errorf("events %+v", events)
+	}
+
+	if err := sign("2500"); status.Code(err) == codes.OK {
+		t.Error("order over the tightened per-order cap was signed")
+	}
+	if err := sign("2000"); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if err := sign("1500"); status.Code(err) == codes.OK {
+		t.Error("order over the tightened value limit was signed")
+	}
+
+	// What was tightened cannot be loosened back.
+	if _, err := sm.Tighten(ctx, SessionTightening{MaxOrders: 6}); !errors.Is(err, ErrLoosening) {
+		t.Errorf("raise order cap: expected ErrLoosening, got %v", err)
+	}
+	if _, err := sm.Tighten(ctx, SessionTightening{ExpiresIn: time.Hour}); !errors.Is(err, ErrLoosening) {
+		t.Errorf("extend: expected ErrLoosening, got %v", err)
+	}
+}
+
+func TestTightenClearedByActivate(t *testing.T) {
+	sm := activeSession(t, 1_000_000)
+	if err := sm.SetOrderLimits(10, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.Tighten(context.Background(), SessionTightening{MaxOrders: 2, MaxOrderValue: big.NewInt(100)}); err != nil {
+		t.Fatalf("tighten: %v", err)
+	}
+	if _, max := sm.OrderCount(); max != 2 {
+		t.Errorf("order cap %d, want 2", max)
+	}
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(1_000_000)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if _, max := sm.OrderCount(); max != 10 || sm.MaxOrderValue() != "" {
+		t.Errorf("new session kept the clamp: orders %d, per order %q", max, sm.MaxOrderValue())
+	}
+}
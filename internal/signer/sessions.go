@@ -0,0 +1,183 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrUnknownSession   = errors.New("unknown session")
+	ErrInvalidSessionID = errors.New("invalid session ID")
+)
+
+// DefaultSessionID selects the session every request without a session_id
+// uses.
+const DefaultSessionID = ""
+
+var sessionIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// ParseSessions parses a comma-separated list of named sessions, each
+// "id" or "id=ttl" (e.g. "momentum,arb=2h"), into their TTLs. A zero TTL
+// means the signer's session TTL.
+func ParseSessions(s string) (map[string]time.Duration, error) {
+	sessions := make(map[string]time.Duration)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, ttl, hasTTL := strings.Cut(entry, "=")
+		id = strings.TrimSpace(id)
+		if !sessionIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("%w %q: use up to 32 lowercase letters, digits, '-' and '_'", ErrInvalidSessionID, id)
+		}
+		if _, dup := sessions[id]; dup {
+			return nil, fmt.Errorf("%w %q: listed twice", ErrInvalidSessionID, id)
+		}
+		var d time.Duration
+		if hasTTL {
+			var err error
+			if d, err = time.ParseDuration(strings.TrimSpace(ttl)); err != nil || d <= 0 {
+				return nil, fmt.Errorf("session %s: invalid TTL %q", id, ttl)
+			}
+		}
+		sessions[id] = d
+	}
+	return sessions, nil
+}
+
+// WithSessions serves named sessions alongside the default one. Each has
+// its own key, TTL and limits; a request's session_id selects one. The
+// other options apply to every session, except that replay protection,
+// client quotas and analytics are tracked per session.
+func WithSessions(named map[string]*SessionManager) Option {
+	return func(h *Handler) {
+		h.named = named
+	}
+}
+
+// sessionRouter sends each request naming a session to that session's
+// Handler. Requests without a session_id, or with an empty one, fall
+// through to the default session's Handler.
+type sessionRouter struct {
+	handlers map[string]*Handler
+	methods  map[string]grpc.MethodDesc // by full method name
+}
+
+// newSessionRouter builds a Handler for each of def's named sessions,
+// configured with the options def was built with.
+func newSessionRouter(def *Handler, opts []Option) (*sessionRouter, error) {
+	r := &sessionRouter{handlers: make(map[string]*Handler), methods: make(map[string]grpc.MethodDesc)}
+	all := map[string]*SessionManager{DefaultSessionID: def.session}
+	for id, sm := range def.named {
+		if !sessionIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("%w %q", ErrInvalidSessionID, id)
+		}
+		h := NewHandler(sm, opts...)
+		h.id = id
+		// These track one session's epochs and reset when it changes, so
+		// sharing them would wipe them on every switch between sessions.
+		if h.replays != nil {
+			h.replays = NewReplayGuard()
+		}
+		if h.quotas != nil {
+			h.quotas = h.quotas.forSession()
+		}
+		if h.analytics != nil {
+			h.analytics = NewSessionAnalytics(h.analytics.loc)
+		}
+		r.handlers[id] = h
+		all[id] = sm
+	}
+	def.sessions = all
+	for _, h := range r.handlers {
+		h.sessions = all
+	}
+
+	desc := signerv1.SignerService_ServiceDesc
+	for _, m := range desc.Methods {
+		r.methods["/"+desc.ServiceName+"/"+m.MethodName] = m
+	}
+	return r, nil
+}
+
+// interceptor returns a unary interceptor that hands a request naming a
+// session to that session's Handler, or refuses an unknown name with
+// NotFound.
+func (r *sessionRouter) interceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id := requestSessionID(req)
+		if id == DefaultSessionID {
+			return handler(ctx, req)
+		}
+		h, ok := r.handlers[id]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "%v %q", ErrUnknownSession, id)
+		}
+		m, ok := r.methods[info.FullMethod]
+		if !ok {
+			return nil, status.Errorf(codes.Unimplemented, "%s is not routed to named sessions", info.FullMethod)
+		}
+		// The generated method handler decodes into a fresh request; hand
+		// it the one already decoded.
+		dec := func(v any) error {
+			proto.Merge(v.(proto.Message), req.(proto.Message))
+			return nil
+		}
+		return m.Handler(h, ctx, dec, nil)
+	}
+}
+
+// requestSessionID returns req's session_id field, or DefaultSessionID
+// when it has none.
+func requestSessionID(req any) string {
+	m, ok := req.(proto.Message)
+	if !ok {
+		return DefaultSessionID
+	}
+	msg := m.ProtoReflect()
+	fd := msg.Descriptor().Fields().ByName("session_id")
+	if fd == nil {
+		return DefaultSessionID
+	}
+	return msg.Get(fd).String()
+}
+
+// ListSessions returns the status of every session the signer serves.
+func (h *Handler) ListSessions(_ context.Context, _ *signerv1.ListSessionsRequest) (*signerv1.ListSessionsResponse, error) {
+	sessions := h.sessions
+	if len(sessions) == 0 {
+		sessions = map[string]*SessionManager{DefaultSessionID: h.session}
+	}
+	ids := make([]string, 0, len(sessions))
+	for id := range sessions {
+		ids = append(ids, id)
+	}
+	// The default session's empty ID sorts first.
+	sort.Strings(ids)
+	resp := &signerv1.ListSessionsResponse{}
+	for _, id := range ids {
+		resp.Sessions = append(resp.Sessions, &signerv1.NamedSession{SessionId: id, Status: sessionStatus(sessions[id])})
+	}
+	return resp, nil
+}
+
+// destroySessions drops every session's key: a canary trip means the
+// client may be compromised, and it could name any session.
+func (h *Handler) destroySessions() {
+	h.session.Destroy()
+	for _, sm := range h.sessions {
+		sm.Destroy()
+	}
+}
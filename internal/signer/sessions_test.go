@@ -0,0 +1,150 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseSessions(t *testing.T) {
+	got, err := ParseSessions(" momentum, arb=2h ,,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["momentum"] != 0 || got["arb"] != 2*time.Hour {
+		t.Errorf("got %v", got)
+	}
+	if got, err := ParseSessions(""); err != nil || len(got) != 0 {
+		t.Errorf("empty: got %v, %v", got, err)
+	}
+	for _, bad := range []string{"Arb", "-arb", "a b", "arb,arb", strings.Repeat("a", 33)} {
+		if _, err := ParseSessions(bad); !errors.Is(err, ErrInvalidSessionID) {
+			t.Errorf("%q: expected ErrInvalidSessionID, got %v", bad, err)
+		}
+	}
+	for _, bad := range []string{"arb=", "arb=soon", "arb=-1h"} {
+		if _, err := ParseSessions(bad); err == nil {
+			t.Errorf("%q: expected an invalid TTL", bad)
+		}
+	}
+}
+
+// namedSessionServer serves the default session on testKey and an "arb"
+// session on another key, and returns a client and the arb session.
+func namedSessionServer(t *testing.T, opts ...Option) (signerv1.SignerServiceClient, *SessionManager) {
+	t.Helper()
+	arb := NewSessionManager(time.Hour)
+	key := make([]byte, 32)
+	key[31] = 2
+	if err := arb.Activate(context.Background(), key, big.NewInt(1000)); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	t.Cleanup(arb.Destroy)
+
+	socket := filepath.Join(t.TempDir(), "signer.sock")
+	srv, err := New(socket, activeSession(t, 100), append(opts, WithSessions(map[string]*SessionManager{"arb": arb}))...)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	go srv.Serve()
+	t.Cleanup(srv.GracefulStop)
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return signerv1.NewSignerServiceClient(conn), arb
+}
+
+func TestServerRoutesNamedSessions(t *testing.T) {
+	var audited []AuditRecord
+	client, arb := namedSessionServer(t, WithReplayGuard(NewReplayGuard()), WithAudit(func(r AuditRecord) { audited = append(audited, r) }))
+	ctx := context.Background()
+	_, _, _, _, arbAddr := arb.Status()
+
+	sign := func(session, amount, salt string) (*signerv1.SignOrderResponse, error) {
+		return client.SignOrder(ctx, &signerv1.SignOrderRequest{
+			Order:     &signerv1.PolymarketOrder{Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: amount, Salt: salt},
+			SessionId: session,
+		})
+	}
+
+	// The arb session signs with its own key and limit.
+	resp, err := sign("arb", "500", "7")
+	if err != nil {
+		t.Fatalf("sign arb: %v", err)
+	}
+	if resp.Order.Maker != arbAddr || resp.Order.Maker == testMaker {
+		t.Errorf("arb order made by %s, want %s", resp.Order.Maker, arbAddr)
+	}
+	// The same token and salt is no replay in another session.
+	if _, err := sign("", "50", "7"); err != nil {
+		t.Fatalf("sign default: %v", err)
+	}
+	if _, err := sign("arb", "1", "7"); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("replay in arb: expected AlreadyExists, got %v", err)
+	}
+	if _, err := sign("", "200", "8"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("over the default limit: expected ResourceExhausted, got %v", err)
+	}
+	if _, err := sign("momentum", "1", "9"); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown session: expected NotFound, got %v", err)
+	}
+	if len(audited) != 4 || audited[0].SessionID != "arb" || audited[1].SessionID != "" {
+		t.Errorf("audited %+v", audited)
+	}
+
+	list, err := client.ListSessions(ctx, &signerv1.ListSessionsRequest{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list.Sessions) != 2 || list.Sessions[0].SessionId != "" || list.Sessions[1].SessionId != "arb" {
+		t.Fatalf("sessions %+v", list.Sessions)
+	}
+	if def, named := list.Sessions[0].Status, list.Sessions[1].Status; def.ValueUsed != "50" || named.ValueUsed != "500" || named.SessionAddress != arbAddr {
+		t.Errorf("default %+v, arb %+v", def, named)
+	}
+	st, err := client.GetSessionStatus(ctx, &signerv1.GetSessionStatusRequest{SessionId: "arb"})
+	if err != nil || st.MaxValueLimit != "1000" {
+		t.Errorf("arb status %+v, %v", st, err)
+	}
+
+	// A batch names its session once.
+	_, err = client.SignOrders(ctx, &signerv1.SignOrdersRequest{
+		SessionId: "arb",
+		Orders:    []*signerv1.SignOrderRequest{{Order: &signerv1.PolymarketOrder{TokenId: "1", MakerAmount: "1"}, SessionId: "arb"}},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("session on a batched order: expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestCanaryDestroysEverySession(t *testing.T) {
+	client, arb := namedSessionServer(t, WithCanaries([]string{"canary-1"}, nil))
+	ctx := context.Background()
+
+	_, err := client.SignOrder(ctx, &signerv1.SignOrderRequest{
+		Order:     &signerv1.PolymarketOrder{TokenId: "canary-1", MakerAmount: "1"},
+		SessionId: "arb",
+	})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied, got %v", err)
+	}
+	if active, _, _, _, _ := arb.Status(); active {
+		t.Error("expected the arb session destroyed")
+	}
+	if st, _ := client.GetSessionStatus(ctx, &signerv1.GetSessionStatusRequest{}); st.GetActive() {
+		t.Error("expected the default session destroyed")
+	}
+}
@@ -0,0 +1,181 @@
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrSecondFactorRequired = errors.New("activation requires a second factor code")
+	ErrSecondFactorDenied   = errors.New("second factor code rejected")
+	ErrSecondFactorLocked   = errors.New("too many failed second factor codes")
+)
+
+// RFC 6238 parameters, the ones every authenticator app defaults to:
+// six-digit codes over 30-second steps.
+const (
+	totpStep = 30 * time.Second
+	// Codes from one step either side are accepted, for clock drift.
+	totpSkew = 1
+	// totpMinSecret is the shortest secret accepted, in bytes: RFC 4226's
+	// 128-bit minimum.
+	totpMinSecret = 16
+)
+
+// SecondFactorEvent is an audit record of a second factor check.
+type SecondFactorEvent struct {
+	Client  string    `json:"client"`
+	Action  string    `json:"action"`
+	Granted bool      `json:"granted"`
+	Detail  string    `json:"detail,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// TOTP checks time-based one-time passwords (RFC 6238: HMAC-SHA1, six
+// digits, 30-second steps) from an authenticator app, as a second factor
+// on activating a session. Each code is accepted once, and failures lock
+// it out like elevation credentials.
+type TOTP struct {
+	secret  []byte
+	clock   clock.Clock
+	onEvent func(SecondFactorEvent)
+
+	mu          sync.Mutex
+	lastStep    int64 // newest step accepted; older codes are replays
+	failures    int
+	lockedUntil time.Time
+}
+
+// NewTOTP creates a TOTP checker for the base32 secret. onEvent may be
+// nil; a nil clk uses the real clock.
+func NewTOTP(secret string, clk clock.Clock, onEvent func(SecondFactorEvent)) (*TOTP, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	if onEvent == nil {
+		onEvent = func(SecondFactorEvent) {}
+	}
+	return &TOTP{secret: key, clock: clock.Or(clk), onEvent: onEvent}, nil
+}
+
+// GenerateTOTPSecret returns a new random base32 secret.
+func GenerateTOTPSecret() (string, error) {
+	key := make([]byte, 20)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key), nil
+}
+
+// TOTPURI returns the otpauth:// URI authenticator apps enroll secret
+// from, usually shown as a QR code.
+func TOTPURI(secret, account string) string {
+	q := url.Values{"secret": {secret}, "issuer": {"Caesar"}}
+	return "otpauth://totp/" + url.PathEscape("Caesar:"+account) + "?" + q.Encode()
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	s := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(secret), " ", ""))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(s, "="))
+	if err != nil || len(key) < totpMinSecret {
+		return nil, fmt.Errorf("totp secret: want base32 of at least %d bytes", totpMinSecret)
+	}
+	return key, nil
+}
+
+// Verify checks code for action on behalf of client and audits the
+// attempt.
+func (t *TOTP) Verify(client, action, code string) error {
+	t.mu.Lock()
+	now := t.clock.Now()
+	err := t.verifyLocked(strings.TrimSpace(code), now)
+	t.mu.Unlock()
+
+	ev := SecondFactorEvent{Client: client, Action: action, Granted: err == nil, At: now}
+	switch {
+	case errors.Is(err, ErrSecondFactorRequired):
+		ev.Detail = "no code"
+	case errors.Is(err, ErrSecondFactorLocked):
+		ev.Detail = "locked out"
+	case err != nil:
+		ev.Detail = "bad code"
+	}
+	t.onEvent(ev)
+	return err
+}
+
+// verifyLocked accepts code if it matches a step within the skew that is
+// newer than the last one accepted. Caller must hold t.mu.
+func (t *TOTP) verifyLocked(code string, now time.Time) error {
+	if code == "" {
+		return ErrSecondFactorRequired
+	}
+	if now.Before(t.lockedUntil) {
+		return ErrSecondFactorLocked
+	}
+	step := now.Unix() / int64(totpStep/time.Second)
+	for s := step - totpSkew; s <= step+totpSkew; s++ {
+		if s <= t.lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(code), []byte(totpCode(t.secret, s))) == 1 {
+			t.lastStep, t.failures = s, 0
+			return nil
+		}
+	}
+	t.failures++
+	if t.failures >= elevationMaxFailures {
+		t.failures = 0
+		t.lockedUntil = now.Add(elevationLockout)
+	}
+	return ErrSecondFactorDenied
+}
+
+// totpCode returns the code for counter (RFC 4226 dynamic truncation).
+func totpCode(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	off := sum[len(sum)-1] & 0x0f
+	v := binary.BigEndian.Uint32(sum[off:off+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", v%1_000_000)
+}
+
+func secondFactorStatus(err error) error {
+	switch {
+	case errors.Is(err, ErrSecondFactorRequired), errors.Is(err, ErrSecondFactorDenied):
+		return status.Errorf(codes.Unauthenticated, "%v", err)
+	case errors.Is(err, ErrSecondFactorLocked):
+		return status.Errorf(codes.ResourceExhausted, "%v", err)
+	default:
+		return status.Errorf(codes.Internal, "second factor: %v", err)
+	}
+}
+
+// WithActivationTOTP requires a TOTP code, checked by t, on every
+// ImportSession. That is the only RPC that installs a session key; the
+// other path to a live key, offline activation in cmd/signer, checks the
+// same secret itself. SessionManager.Activate is not gated: its remaining
+// callers, audit replay and loadgen, use throwaway keys.
+func WithActivationTOTP(t *TOTP) Option {
+	return func(h *Handler) {
+		h.totp = t
+	}
+}
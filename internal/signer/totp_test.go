@@ -0,0 +1,104 @@
+package signer
+
+import (
+	"context"
+	"encoding/base32"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors.
+var rfc6238Secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPVectors(t *testing.T) {
+	key, err := decodeTOTPSecret(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The RFC's eight-digit codes, truncated to six.
+	for unix, want := range map[int64]string{59: "287082", 1111111109: "081804", 1234567890: "005924", 2000000000: "279037"} {
+		if got := totpCode(key, unix/30); got != want {
+			t.Errorf("t=%d: got %s, want %s", unix, got, want)
+		}
+	}
+	if _, err := NewTOTP("JBSWY3DPEHPK3PXP", nil, nil); err == nil {
+		t.Error("expected a short secret refused")
+	}
+}
+
+func TestTOTPVerify(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1111111109, 0))
+	var events []SecondFactorEvent
+	totp, err := NewTOTP(rfc6238Secret, clk, func(ev SecondFactorEvent) { events = append(events, ev) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := totp.Verify("ops", "test", ""); !errors.Is(err, ErrSecondFactorRequired) {
+		t.Errorf("no code: expected ErrSecondFactorRequired, got %v", err)
+	}
+	if err := totp.Verify("ops", "test", "081804"); err != nil {
+		t.Fatalf("current code: %v", err)
+	}
+	if err := totp.Verify("ops", "test", "081804"); !errors.Is(err, ErrSecondFactorDenied) {
+		t.Errorf("replayed code: expected ErrSecondFactorDenied, got %v", err)
+	}
+	// The next step's code is accepted early, for clock drift.
+	key, _ := decodeTOTPSecret(rfc6238Secret)
+	if err := totp.Verify("ops", "test", totpCode(key, 1111111109/30+1)); err != nil {
+		t.Errorf("next step: %v", err)
+	}
+
+	for range elevationMaxFailures {
+		totp.Verify("ops", "test", "000000")
+	}
+	if err := totp.Verify("ops", "test", totpCode(key, 1111111109/30+2)); !errors.Is(err, ErrSecondFactorLocked) {
+		t.Errorf("after failures: expected ErrSecondFactorLocked, got %v", err)
+	}
+	if len(events) < 3 || events[1].Detail != "" || !events[1].Granted || events[2].Detail != "bad code" {
+		t.Errorf("events %+v", events)
+	}
+}
+
+func TestImportSessionRequiresTOTP(t *testing.T) {
+	src := activeSession(t, 1_000_000)
+	dst := NewSessionManager(time.Hour)
+	t.Cleanup(dst.Destroy)
+
+	clk := clock.NewFake(time.Unix(1111111109, 0))
+	totp, err := NewTOTP(rfc6238Secret, clk, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(dst, WithActivationTOTP(totp))
+	ctx := context.Background()
+
+	pub, err := dst.PrepareImport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := src.Export(ctx, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := h.ImportSession(ctx, &signerv1.ImportSessionRequest{Bundle: bundle}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("without a code: expected Unauthenticated, got %v", err)
+	}
+	if active, _, _, _, _ := dst.Status(); active {
+		t.Fatal("imported without a second factor")
+	}
+	if _, err := h.ImportSession(ctx, &signerv1.ImportSessionRequest{Bundle: bundle, SecondFactor: "081804"}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if _, err := dst.Sign(ctx, [32]byte{}, big.NewInt(1)); err != nil {
+		t.Errorf("sign after import: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package signer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/caesar-terminal/caesar/internal/eip712"
+)
+
+var ErrTypedDataNotAllowed = errors.New("typed data not whitelisted")
+
+// TypedDataSchema whitelists one EIP-712 message type on one domain for
+// SignTypedData. Type is the full encodeType of the primary type,
+// referenced structs included, so a payload cannot reuse the name with
+// different fields. Domain fields left empty (or a zero ChainID) must be
+// absent from the payload's domain too. Create schemas with
+// NewTypedDataSchema.
+type TypedDataSchema struct {
+	Name   string
+	Type   string
+	Domain eip712.Domain
+
+	separator [32]byte
+}
+
+// WithTypedDataSchemas enables SignTypedData for payloads matching one of
+// schemas. Without it the RPC is refused.
+func WithTypedDataSchemas(schemas []TypedDataSchema) Option {
+	return func(h *Handler) {
+		h.typedSchemas = schemas
+	}
+}
+
+// typedSchemaFile is the on-disk form of a schema list:
+//
+//	schemas:
+//	  - name: clob-auth
+//	    type: ClobAuth(address address,string timestamp,uint256 nonce,string message)
+//	    domain:
+//	      name: ClobAuthDomain
+//	      version: "1"
+//	      chain_id: 137
+type typedSchemaFile struct {
+	Schemas []struct {
+		Name   string `yaml:"name"`
+		Type   string `yaml:"type"`
+		Domain struct {
+			Name              string `yaml:"name"`
+			Version           string `yaml:"version"`
+			ChainID           uint64 `yaml:"chain_id"`
+			VerifyingContract string `yaml:"verifying_contract"`
+		} `yaml:"domain"`
+	} `yaml:"schemas"`
+}
+
+// LoadTypedDataSchemas reads a schema list from a YAML or JSON file. An
+// empty path returns no schemas, which leaves SignTypedData disabled.
+func LoadTypedDataSchemas(path string) ([]TypedDataSchema, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read typed data schemas: %w", err)
+	}
+	return ParseTypedDataSchemas(data)
+}
+
+// ParseTypedDataSchemas decodes and checks a schema list in the file
+// format LoadTypedDataSchemas reads.
+func ParseTypedDataSchemas(data []byte) ([]TypedDataSchema, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var f typedSchemaFile
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decode typed data schemas: %w", err)
+	}
+	schemas := make([]TypedDataSchema, len(f.Schemas))
+	var errs []error
+	for i, s := range f.Schemas {
+		var err error
+		if schemas[i], err = NewTypedDataSchema(s.Name, s.Type, eip712.Domain(s.Domain)); err != nil {
+			errs = append(errs, fmt.Errorf("schema %d (%s): %w", i+1, s.Name, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+// NewTypedDataSchema checks a schema and computes the domain separator
+// payloads must match. Exchange orders are refused: signing them here
+// would skip the value limits SignOrder enforces.
+func NewTypedDataSchema(name, typ string, domain eip712.Domain) (TypedDataSchema, error) {
+	if name == "" {
+		return TypedDataSchema{}, errors.New("name is required")
+	}
+	primary, _, ok := strings.Cut(typ, "(")
+	if !ok || primary == "" || !strings.HasSuffix(typ, ")") {
+		return TypedDataSchema{}, fmt.Errorf("type %q is not an EIP-712 type string", typ)
+	}
+	sep, err := domain.Separator()
+	if err != nil {
+		return TypedDataSchema{}, err
+	}
+	if isExchangeDomain(sep) {
+		return TypedDataSchema{}, errors.New("exchange orders are signed through SignOrder, not SignTypedData")
+	}
+	return TypedDataSchema{Name: name, Type: typ, Domain: domain, separator: sep}, nil
+}
+
+// primaryType returns the struct name Type defines.
+func (s *TypedDataSchema) primaryType() string {
+	primary, _, _ := strings.Cut(s.Type, "(")
+	return primary
+}
+
+func isExchangeDomain(sep [32]byte) bool {
+	for _, d := range []eip712.Domain{CTFExchange, NegRiskCTFExchange, AmoyCTFExchange, AmoyNegRiskCTFExchange} {
+		if exch, err := d.Separator(); err == nil && exch == sep {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTypedData returns the schema td conforms to.
+func matchTypedData(schemas []TypedDataSchema, td *eip712.TypedData) (*TypedDataSchema, error) {
+	sep, err := td.Separator()
+	if err != nil {
+		return nil, fmt.Errorf("domain: %w", err)
+	}
+	if isExchangeDomain(sep) {
+		return nil, fmt.Errorf("%w: exchange orders are signed through SignOrder", ErrTypedDataNotAllowed)
+	}
+	enc, err := td.EncodeType(td.PrimaryType)
+	if err != nil {
+		return nil, err
+	}
+	for i := range schemas {
+		if s := &schemas[i]; s.primaryType() == td.PrimaryType && s.Type == enc && s.separator == sep {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s on domain %v", ErrTypedDataNotAllowed, enc, td.Domain)
+}
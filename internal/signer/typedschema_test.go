@@ -0,0 +1,155 @@
+package signer
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/caesar-terminal/caesar/internal/eip712"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func writeSchemas(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schemas.yaml")
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const mailSchemas = `
+schemas:
+  - name: mail
+    type: Mail(Person from,Person to,string contents)Person(string name,address wallet)
+    domain:
+      name: Ether Mail
+      version: "1"
+      chain_id: 1
+      verifying_contract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+`
+
+// mailTypedData is the example from the EIP-712 specification.
+const mailTypedData = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Person": [
+      {"name": "name", "type": "string"},
+      {"name": "wallet", "type": "address"}
+    ],
+    "Mail": [
+      {"name": "from", "type": "Person"},
+      {"name": "to", "type": "Person"},
+      {"name": "contents", "type": "string"}
+    ]
+  },
+  "primaryType": "Mail",
+  "domain": {
+    "name": "Ether Mail",
+    "version": "1",
+    "chainId": 1,
+    "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+  },
+  "message": {
+    "from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+    "to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+    "contents": "Hello, Bob!"
+  }
+}`
+
+func TestTypedDataSchemas(t *testing.T) {
+	if schemas, err := LoadTypedDataSchemas(""); schemas != nil || err != nil {
+		t.Errorf("empty path = %v, %v", schemas, err)
+	}
+	schemas, err := LoadTypedDataSchemas(writeSchemas(t, mailSchemas))
+	if err != nil {
+		t.Fatal(err)
+	}
+	td, _ := eip712.Parse([]byte(mailTypedData))
+	if s, err := matchTypedData(schemas, td); err != nil || s.Name != "mail" {
+		t.Errorf("match = %v, %v", s, err)
+	}
+
+	for name, src := range map[string]string{
+		"extra field":  strings.Replace(mailTypedData, `{"name": "contents", "type": "string"}`, `{"name": "contents", "type": "string"}, {"name": "cc", "type": "string"}`, 1),
+		"other domain": strings.Replace(mailTypedData, `"chainId": 1`, `"chainId": 137`, 1),
+	} {
+		td, err := eip712.Parse([]byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := matchTypedData(schemas, td); !errors.Is(err, ErrTypedDataNotAllowed) {
+			t.Errorf("%s: expected ErrTypedDataNotAllowed, got %v", name, err)
+		}
+	}
+
+	for name, src := range map[string]string{
+		"exchange": `
+schemas:
+  - name: orders
+    type: Order(uint256 salt)
+    domain: {name: Polymarket CTF Exchange, version: "1", chain_id: 137, verifying_contract: "0x4bFb41d5B3570DeFd03C39a9A4D8dE6Bd8B8982E"}
+`,
+		"unknown key": "schemas: [{name: a, typ: A()}]",
+		"bad type":    "schemas: [{name: a, type: A, domain: {name: x}}]",
+		"no name":     "schemas: [{type: A(), domain: {name: x}}]",
+	} {
+		if _, err := LoadTypedDataSchemas(writeSchemas(t, src)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestSignTypedData(t *testing.T) {
+	req := &signerv1.SignTypedDataRequest{TypedData: mailTypedData}
+	if _, err := NewHandler(activeSession(t, 1_000_000)).SignTypedData(context.Background(), req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("without schemas: expected FailedPrecondition, got %v", err)
+	}
+
+	schemas, err := LoadTypedDataSchemas(writeSchemas(t, mailSchemas))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm := activeSession(t, 1_000_000)
+	h := NewHandler(sm, WithTypedDataSchemas(schemas))
+	resp, err := h.SignTypedData(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, _ := hex.DecodeString(resp.Digest[2:])
+	sig, _ := hex.DecodeString(resp.Signature[2:])
+	if addr, err := recoverAddress([32]byte(digest), sig); err != nil || addr != testMaker || resp.SignerAddress != testMaker {
+		t.Errorf("signature recovers to %s (%v), signer %s", addr, err, resp.SignerAddress)
+	}
+	if resp.Schema != "mail" {
+		t.Errorf("schema = %s", resp.Schema)
+	}
+	if _, _, _, used, _ := sm.Status(); used != "0" {
+		t.Errorf("value used = %s, typed data must not consume limit", used)
+	}
+
+	req.TypedData = strings.Replace(mailTypedData, "Hello, Bob!", "Hello, Eve!", 1)
+	if _, err := h.SignTypedData(context.Background(), req); err != nil {
+		t.Errorf("another message of the same schema: %v", err)
+	}
+	req.TypedData = strings.Replace(mailTypedData, `"Mail": [`, `"Letter": [`, 1)
+	req.TypedData = strings.Replace(req.TypedData, `"primaryType": "Mail"`, `"primaryType": "Letter"`, 1)
+	if _, err := h.SignTypedData(context.Background(), req); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("unlisted type: expected PermissionDenied, got %v", err)
+	}
+	req.TypedData = "{"
+	if _, err := h.SignTypedData(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("malformed: expected InvalidArgument, got %v", err)
+	}
+}
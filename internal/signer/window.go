@@ -0,0 +1,216 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrWindowLimitExceeded = errors.New("rolling window value limit exceeded")
+
+// windowBuckets is how many buckets a window is split into. Spend leaves a
+// window when its whole bucket has, so it can count for up to one bucket
+// (a sixtieth of the span) longer than the span: the limit errs on the
+// side of refusing.
+const windowBuckets = 60
+
+// ValueWindow limits the value a session may sign in any trailing Span,
+// alongside its cumulative limit, e.g. at most 500 USDC per hour.
+type ValueWindow struct {
+	Span  time.Duration
+	Limit *big.Int // USDC atomic units
+}
+
+// WindowStatus is a window's spend and headroom at one instant.
+type WindowStatus struct {
+	Span      time.Duration
+	Limit     string
+	Used      string
+	Remaining string
+}
+
+// ParseValueWindows parses a semicolon-separated list of windows in the
+// form "span=limit", e.g. "1h=500000000;24h=2000000000". Spans are Go
+// durations. An empty spec yields no windows.
+func ParseValueWindows(spec string) ([]ValueWindow, error) {
+	var windows []ValueWindow
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		span, limit, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("value window %q: expected span=limit", entry)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(span))
+		if err != nil || d < windowBuckets*time.Second {
+			return nil, fmt.Errorf("value window %q: span must be a duration of at least %s", entry, windowBuckets*time.Second)
+		}
+		v, ok := new(big.Int).SetString(strings.TrimSpace(limit), 10)
+		if !ok || v.Sign() < 0 {
+			return nil, fmt.Errorf("value window %q: invalid limit", entry)
+		}
+		windows = append(windows, ValueWindow{Span: d, Limit: v})
+	}
+	return windows, nil
+}
+
+// windowLedger tracks one window's spend in buckets of span/windowBuckets.
+type windowLedger struct {
+	span    time.Duration
+	limit   Amount
+	width   time.Duration
+	buckets []windowBucket // oldest first
+}
+
+type windowBucket struct {
+	start time.Time
+	value Amount
+}
+
+func newWindowLedger(w ValueWindow) (windowLedger, error) {
+	limit, err := NewAmount(w.Limit)
+	if err != nil {
+		return windowLedger{}, err
+	}
+	if w.Span < windowBuckets*time.Second {
+		return windowLedger{}, fmt.Errorf("value window span %s is under %s", w.Span, windowBuckets*time.Second)
+	}
+	return windowLedger{span: w.Span, limit: limit, width: w.Span / windowBuckets}, nil
+}
+
+// live reports whether b still counts toward the window at now.
+func (l *windowLedger) live(b windowBucket, now time.Time) bool {
+	return b.start.Add(l.width).After(now.Add(-l.span))
+}
+
+// used sums the spend still inside the window at now.
+func (l *windowLedger) used(now time.Time) Amount {
+	var sum Amount
+	for _, b := range l.buckets {
+		if l.live(b, now) {
+			sum, _ = sum.Add(b.value)
+		}
+	}
+	return sum
+}
+
+// add records value spent at now, dropping buckets that have left the
+// window.
+func (l *windowLedger) add(now time.Time, value Amount) {
+	drop := 0
+	for drop < len(l.buckets) && !l.live(l.buckets[drop], now) {
+		drop++
+	}
+	l.buckets = l.buckets[drop:]
+	start := now.Truncate(l.width)
+	if n := len(l.buckets); n > 0 && l.buckets[n-1].start.Equal(start) {
+		if sum, err := l.buckets[n-1].value.Add(value); err == nil {
+			l.buckets[n-1].value = sum
+		}
+		return
+	}
+	l.buckets = append(l.buckets, windowBucket{start: start, value: value})
+}
+
+// credit takes value spent at at back out of the window, as far as its
+// bucket is still tracked.
+func (l *windowLedger) credit(at time.Time, value Amount) {
+	start := at.Truncate(l.width)
+	for i := range l.buckets {
+		if l.buckets[i].start.Equal(start) {
+			l.buckets[i].value = l.buckets[i].value.Sub(value)
+			return
+		}
+	}
+}
+
+// SetValueWindows installs rolling value limits, replacing any before.
+// They apply to the current and all future sessions; spend already signed
+// in the current session counts from the moment they are set. Window
+// spend is kept in memory only: a restart starts the windows empty, and
+// the journaled cumulative limit still bounds the session. A handoff
+// carries the spend to the destination.
+func (sm *SessionManager) SetValueWindows(windows []ValueWindow) error {
+	ledgers := make([]windowLedger, len(windows))
+	for i, w := range windows {
+		var err error
+		if ledgers[i], err = newWindowLedger(w); err != nil {
+			return err
+		}
+	}
+	sort.Slice(ledgers, func(a, b int) bool { return ledgers[a].span < ledgers[b].span })
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.windows = ledgers
+	return nil
+}
+
+// ValueWindows returns each window's spend and headroom, shortest span
+// first. It is empty without windows or an active session.
+func (sm *SessionManager) ValueWindows() []WindowStatus {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	if sm.key == nil || sm.isExpired() {
+		return nil
+	}
+	now := sm.clock.Now()
+	out := make([]WindowStatus, len(sm.windows))
+	for i := range sm.windows {
+		w := &sm.windows[i]
+		used := w.used(now)
+		out[i] = WindowStatus{Span: w.span, Limit: w.limit.String(), Used: used.String(), Remaining: w.limit.Sub(used).String()}
+	}
+	return out
+}
+
+// checkWindowsLocked returns the first window that spending total at now
+// would overrun. Caller must hold sm.mu.
+func (sm *SessionManager) checkWindowsLocked(now time.Time, total Amount) error {
+	for i := range sm.windows {
+		w := &sm.windows[i]
+		if sum, err := w.used(now).Add(total); err != nil || sum.Cmp(w.limit) > 0 {
+			return fmt.Errorf("%w: %s spent of %s in the last %s", ErrWindowLimitExceeded, w.used(now), w.limit, w.span)
+		}
+	}
+	return nil
+}
+
+// resetWindowsLocked empties every window for a new session. Caller must
+// hold sm.mu.
+func (sm *SessionManager) resetWindowsLocked() {
+	for i := range sm.windows {
+		sm.windows[i].buckets = nil
+	}
+}
+
+// restoreWindowsLocked refills every window with the spend of a handed-off
+// session, taken from the source window with the same span. Without one,
+// the source's shortest window spanning at least as long stands in, which
+// can only count more spend, or else its longest. Caller must hold sm.mu.
+func (sm *SessionManager) restoreWindowsLocked(src []windowLedger) {
+	sm.resetWindowsLocked()
+	for i := range sm.windows {
+		w := &sm.windows[i]
+		var from *windowLedger
+		for j := range src {
+			s := &src[j]
+			switch {
+			case from == nil,
+				s.span >= w.span && (from.span < w.span || s.span < from.span),
+				s.span < w.span && from.span < w.span && s.span > from.span:
+				from = s
+			}
+		}
+		if from == nil {
+			continue
+		}
+		for _, b := range from.buckets {
+			w.add(b.start, b.value)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/clock"
+	signerv1 "github.com/caesar-terminal/caesar/internal/gen/signer/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseValueWindows(t *testing.T) {
+	windows, err := ParseValueWindows(" 24h=2000000000; 1h=500000000 ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 || windows[0].Span != 24*time.Hour || windows[0].Limit.String() != "2000000000" || windows[1].Span != time.Hour {
+		t.Errorf("windows %+v", windows)
+	}
+	if windows, err := ParseValueWindows(""); err != nil || len(windows) != 0 {
+		t.Errorf("empty spec: %v, %v", windows, err)
+	}
+	for _, spec := range []string{"1h", "hourly=5", "30s=5", "1h=-5", "1h=five"} {
+		if _, err := ParseValueWindows(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+func TestSessionValueWindows(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	sm := NewSessionManager(48*time.Hour, WithClock(clk))
+	if err := sm.SetValueWindows([]ValueWindow{{Span: 24 * time.Hour, Limit: big.NewInt(2000)}, {Span: time.Hour, Limit: big.NewInt(500)}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.Activate(context.Background(), testKey(), big.NewInt(5000)); err != nil {
+		t.Fatal(err)
+	}
+	defer sm.Destroy()
+	ctx := context.Background()
+	sign := func(b byte, v int64) error {
+		_, err := sm.Sign(ctx, [32]byte{b}, big.NewInt(v))
+		return err
+	}
+
+	if err := sign(1, 400); err != nil {
+		t.Fatal(err)
+	}
+	if err := sign(2, 101); !errors.Is(err, ErrWindowLimitExceeded) {
+		t.Fatalf("over the hourly window: expected ErrWindowLimitExceeded, got %v", err)
+	}
+	if _, _, _, used, _ := sm.Status(); used != "400" {
+		t.Errorf("a refused order spent the limit: used %s", used)
+	}
+	got := sm.ValueWindows()
+	if len(got) != 2 || got[0].Span != time.Hour || got[0].Used != "400" || got[0].Remaining != "100" || got[1].Remaining != "1600" {
+		t.Errorf("windows %+v", got)
+	}
+
+	// Released value refills the window it was spent in.
+	if _, _, err := sm.Release(ctx, [32]byte{1}, big.NewInt(300)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sign(2, 400); err != nil {
+		t.Errorf("after the release: %v", err)
+	}
+
+	// The hour slides past, the day does not.
+	for i := 0; i < 3; i++ {
+		clk.Advance(time.Hour + time.Minute)
+		if err := sign(byte(10+i), 500); err != nil {
+			t.Fatalf("hour %d: %v", i, err)
+		}
+	}
+	if err := sign(20, 1); !errors.Is(err, ErrWindowLimitExceeded) {
+		t.Errorf("over the daily window: expected ErrWindowLimitExceeded, got %v", err)
+	}
+	h := NewHandler(sm)
+	_, err := h.SignOrder(ctx, &signerv1.SignOrderRequest{
+		Order: &signerv1.PolymarketOrder{Side: signerv1.OrderSide_ORDER_SIDE_BUY, TokenId: "1", MakerAmount: "100", TakerAmount: "200"},
+	})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("SignOrder over a window: expected ResourceExhausted, got %v", err)
+	}
+	st, _ := h.GetSessionStatus(ctx, &signerv1.GetSessionStatusRequest{})
+	if w := st.ValueWindows; len(w) != 2 || w[0].SpanSeconds != 3600 || w[1].Used != "2000" || w[1].Remaining != "0" {
+		t.Errorf("status windows %v", w)
+	}
+
+	// A new session starts with empty windows.
+	if err := sm.Activate(ctx, testKey(), big.NewInt(5000)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sign(30, 500); err != nil {
+		t.Errorf("new session: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package strategy
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/caesar-terminal/caesar/internal/order"
+)
+
+var ErrInvalidMarketMakerConfig = errors.New("invalid market maker config")
+
+// MarketMakerConfig parameterises the reference market-making strategy.
+// Prices are fixed-point (order.PriceScale); sizes are raw share units.
+type MarketMakerConfig struct {
+	TokenID string
+	// Spread is the full quoted width around the skewed fair price.
+	Spread int64
+	// QuoteSize is the size quoted on each side.
+	QuoteSize *big.Int
+	// MaxInventory caps the shares held; the bid is pulled once reached.
+	MaxInventory *big.Int
+	// Skew is how far fair price shifts down at MaxInventory, scaled
+	// linearly with current inventory to encourage offloading.
+	Skew int64
+	// TickSize is the price increment quotes are rounded to.
+	TickSize int64
+}
+
+// MarketMaker is a reference two-sided quoting strategy with inventory
+// skew. It is intentionally simple and doubles as the canonical example of
+// the Strategy interface.
+type MarketMaker struct {
+	cfg MarketMakerConfig
+
+	mu        sync.Mutex
+	inventory *big.Int
+}
+
+// NewMarketMaker validates cfg and returns a strategy with zero inventory.
+func NewMarketMaker(cfg MarketMakerConfig) (*MarketMaker, error) {
+	switch {
+	case cfg.Spread <= 0, cfg.TickSize <= 0, cfg.Skew < 0:
+		return nil, ErrInvalidMarketMakerConfig
+	case cfg.QuoteSize == nil || cfg.QuoteSize.Sign() <= 0:
+		return nil, ErrInvalidMarketMakerConfig
+	case cfg.MaxInventory == nil || cfg.MaxInventory.Sign() <= 0:
+		return nil, ErrInvalidMarketMakerConfig
+	}
+	return &MarketMaker{cfg: cfg, inventory: new(big.Int)}, nil
+}
+
+// Name implements Strategy.
+func (m *MarketMaker) Name() string {
+	return "market_maker"
+}
+
+// Inventory returns a copy of the current share inventory.
+func (m *MarketMaker) Inventory() *big.Int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return new(big.Int).Set(m.inventory)
+}
+
+// OnBook implements Strategy. Quotes are placed around the mid shifted by
+// inventory skew, kept post-only (never crossing the touch), and sized so
+// inventory stays within [0, MaxInventory].
+func (m *MarketMaker) OnBook(b Book) []order.Leg {
+	if b.TokenID != m.cfg.TokenID {
+		return nil
+	}
+	mid, ok := b.Mid()
+	if !ok {
+		return nil
+	}
+
+	m.mu.Lock()
+	inv := new(big.Int).Set(m.inventory)
+	m.mu.Unlock()
+
+	fair := mid - m.skewOffset(inv)
+	tick := m.cfg.TickSize
+	half := m.cfg.Spread / 2
+
+	bid := (fair - half) / tick * tick
+	if bid >= b.BestAsk {
+		bid = b.BestAsk - tick
+	}
+	ask := (fair + half + tick - 1) / tick * tick
+	if ask <= b.BestBid {
+		ask = b.BestBid + tick
+	}
+
+	var legs []order.Leg
+
+	headroom := new(big.Int).Sub(m.cfg.MaxInventory, inv)
+	if bidSize := minInt(m.cfg.QuoteSize, headroom); bidSize.Sign() > 0 && bid > 0 {
+		legs = append(legs, order.NewLeg(m.cfg.TokenID, order.SideBuy, bid, bidSize))
+	}
+	if askSize := minInt(m.cfg.QuoteSize, inv); askSize.Sign() > 0 && ask < order.PriceScale {
+		legs = append(legs, order.NewLeg(m.cfg.TokenID, order.SideSell, ask, askSize))
+	}
+
+	return legs
+}
+
+// OnFill implements Strategy by updating inventory.
+func (m *MarketMaker) OnFill(f Fill) {
+	if f.TokenID != m.cfg.TokenID {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch f.Side {
+	case order.SideBuy:
+		m.inventory.Add(m.inventory, f.Size)
+	case order.SideSell:
+		m.inventory.Sub(m.inventory, f.Size)
+		if m.inventory.Sign() < 0 {
+			m.inventory.SetInt64(0)
+		}
+	}
+}
+
+// skewOffset scales cfg.Skew by inventory / MaxInventory.
+func (m *MarketMaker) skewOffset(inv *big.Int) int64 {
+	off := new(big.Int).Mul(inv, big.NewInt(m.cfg.Skew))
+	off.Quo(off, m.cfg.MaxInventory)
+	return off.Int64()
+}
+
+func minInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
+
+var _ Strategy = (*MarketMaker)(nil)
@@ -0,0 +1,81 @@
+package strategy
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/caesar-terminal/caesar/internal/order"
+)
+
+func newTestMaker(t *testing.T) *MarketMaker {
+	t.Helper()
+	mm, err := NewMarketMaker(MarketMakerConfig{
+		TokenID:      "tok",
+		Spread:       40_000,
+		QuoteSize:    big.NewInt(10_000_000),
+		MaxInventory: big.NewInt(50_000_000),
+		Skew:         20_000,
+		TickSize:     10_000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return mm
+}
+
+func TestMarketMakerFlatInventoryQuotesBidOnly(t *testing.T) {
+	mm := newTestMaker(t)
+
+	legs := mm.OnBook(Book{TokenID: "tok", BestBid: 480_000, BestAsk: 520_000})
+	if len(legs) != 1 {
+		t.Fatalf("expected bid only with zero inventory, got %d legs", len(legs))
+	}
+	if legs[0].Side != order.SideBuy || legs[0].Price != 480_000 {
+		t.Errorf("unexpected bid: side=%v price=%d", legs[0].Side, legs[0].Price)
+	}
+}
+
+func TestMarketMakerSkewsWithInventory(t *testing.T) {
+	mm := newTestMaker(t)
+	mm.OnFill(Fill{TokenID: "tok", Side: order.SideBuy, Price: 480_000, Size: big.NewInt(50_000_000)})
+
+	// At max inventory: fair = 0.50 - 0.02 = 0.48; bid pulled, ask at 0.50.
+	legs := mm.OnBook(Book{TokenID: "tok", BestBid: 480_000, BestAsk: 520_000})
+	if len(legs) != 1 {
+		t.Fatalf("expected ask only at max inventory, got %d legs", len(legs))
+	}
+	if legs[0].Side != order.SideSell || legs[0].Price != 500_000 {
+		t.Errorf("unexpected ask: side=%v price=%d", legs[0].Side, legs[0].Price)
+	}
+
+	mm.OnFill(Fill{TokenID: "tok", Side: order.SideSell, Price: 500_000, Size: big.NewInt(25_000_000)})
+	if inv := mm.Inventory(); inv.Int64() != 25_000_000 {
+		t.Errorf("inventory %s, want 25000000", inv)
+	}
+
+	// Half inventory: fair = 0.49; both sides quoted and never crossing.
+	legs = mm.OnBook(Book{TokenID: "tok", BestBid: 480_000, BestAsk: 520_000})
+	if len(legs) != 2 {
+		t.Fatalf("expected two-sided quote, got %d legs", len(legs))
+	}
+	if legs[0].Price != 470_000 || legs[1].Price != 510_000 {
+		t.Errorf("unexpected quotes: bid=%d ask=%d", legs[0].Price, legs[1].Price)
+	}
+}
+
+func TestMarketMakerIgnoresOtherTokensAndEmptyBooks(t *testing.T) {
+	mm := newTestMaker(t)
+	if legs := mm.OnBook(Book{TokenID: "other", BestBid: 1, BestAsk: 2}); legs != nil {
+		t.Errorf("expected no quotes for other token")
+	}
+	if legs := mm.OnBook(Book{TokenID: "tok", BestBid: 480_000}); legs != nil {
+		t.Errorf("expected no quotes for one-sided book")
+	}
+}
+
+func TestNewMarketMakerValidates(t *testing.T) {
+	if _, err := NewMarketMaker(MarketMakerConfig{}); !errors.Is(err, ErrInvalidMarketMakerConfig) {
+		t.Errorf("expected ErrInvalidMarketMakerConfig, got %v", err)
+	}
+}
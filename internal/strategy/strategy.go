@@ -0,0 +1,50 @@
+package strategy
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/order"
+)
+
+// Book is a top-of-book snapshot for a single outcome token.
+// Prices are fixed-point (order.PriceScale); zero means no liquidity.
+type Book struct {
+	TokenID   string
+	BestBid   int64
+	BestAsk   int64
+	Timestamp time.Time
+}
+
+// Mid returns the midpoint of the book, or false if either side is empty.
+func (b Book) Mid() (int64, bool) {
+	if b.BestBid <= 0 || b.BestAsk <= 0 {
+		return 0, false
+	}
+	return (b.BestBid + b.BestAsk) / 2, true
+}
+
+// Fill reports an execution against one of the strategy's quotes.
+type Fill struct {
+	TokenID string
+	Side    order.Side
+	Price   int64
+	Size    *big.Int
+}
+
+// Strategy is the interface every trading strategy implements. The host
+// drives it with market data and fills; the strategy answers with the full
+// set of quotes it wants resting. The host diffs that set against live
+// orders and routes every new leg through the Signer's policy checks, so a
+// strategy never talks to the exchange or the key directly.
+type Strategy interface {
+	// Name identifies the strategy in logs and metrics.
+	Name() string
+
+	// OnBook is called on every book update for a subscribed token and
+	// returns the desired resting quotes for that token.
+	OnBook(b Book) []order.Leg
+
+	// OnFill is called when one of the strategy's orders executes.
+	OnFill(f Fill)
+}
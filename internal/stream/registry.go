@@ -0,0 +1,279 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/caesar-terminal/caesar/internal/book"
+)
+
+var (
+	ErrClosed       = errors.New("stream registry closed")
+	ErrUnknownTopic = errors.New("unknown stream topic")
+	ErrSlowConsumer = errors.New("subscriber fell behind")
+)
+
+// Source produces a topic's values with publish until ctx is cancelled.
+// It returns nil when cancelled, or the error that ended the feed.
+type Source func(ctx context.Context, publish func(v any)) error
+
+// Opener returns the Source for a topic, or ErrUnknownTopic.
+type Opener func(topic string) (Source, error)
+
+// Registry owns every stream goroutine. A topic's Source runs once, however
+// many clients subscribe, from the first subscription to the last Close;
+// a client's subscriptions all end when its context does, so a
+// disconnected gRPC stream cannot leave a producer running.
+type Registry struct {
+	open   Opener
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+	topics map[string]*topic
+}
+
+type topic struct {
+	name   string
+	cancel context.CancelFunc
+	subs   map[*Subscription]struct{}
+}
+
+// NewRegistry creates a registry that starts sources with open.
+func NewRegistry(open Opener) *Registry {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Registry{open: open, ctx: ctx, cancel: cancel, topics: make(map[string]*topic)}
+}
+
+// Subscription receives one topic's values for one client.
+type Subscription struct {
+	// C receives values. It is closed when the subscription ends; Err
+	// then says why.
+	C <-chan any
+
+	out   chan any
+	topic *topic
+	r     *Registry
+	stop  func() bool // detaches the client-context hook
+
+	mu     sync.Mutex
+	err    error
+	closed bool
+}
+
+// Subscribe delivers topic's values to a buffered channel until Close,
+// until ctx is done, or until the subscriber falls buffer values behind,
+// which ends the subscription with ErrSlowConsumer rather than stalling
+// every other subscriber of the topic.
+func (r *Registry) Subscribe(ctx context.Context, name string, buffer int) (*Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil, ErrClosed
+	}
+
+	t, ok := r.topics[name]
+	if !ok {
+		src, err := r.open(name)
+		if err != nil {
+			return nil, err
+		}
+		t = &topic{name: name, subs: make(map[*Subscription]struct{})}
+		r.start(t, src)
+		r.topics[name] = t
+	}
+
+	out := make(chan any, buffer)
+	s := &Subscription{C: out, out: out, topic: t, r: r}
+	t.subs[s] = struct{}{}
+	s.mu.Lock()
+	s.stop = context.AfterFunc(ctx, func() { s.end(ctx.Err()) })
+	s.mu.Unlock()
+	return s, nil
+}
+
+// start runs src for t until t's last subscriber leaves or the registry
+// closes. r.mu must be held.
+func (r *Registry) start(t *topic, src Source) {
+	ctx, cancel := context.WithCancel(r.ctx)
+	t.cancel = cancel
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		err := src(ctx, func(v any) { r.publish(t, v) })
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = fmt.Errorf("%s: source ended", t.name)
+		}
+		r.fail(t, err)
+	}()
+}
+
+// publish offers v to every subscriber of t.
+func (r *Registry) publish(t *topic, v any) {
+	r.mu.Lock()
+	subs := make([]*Subscription, 0, len(t.subs))
+	for s := range t.subs {
+		subs = append(subs, s)
+	}
+	r.mu.Unlock()
+
+	for _, s := range subs {
+		s.offer(v)
+	}
+}
+
+// fail ends every subscription to t with err. Later subscribers to the
+// same name start a new source.
+func (r *Registry) fail(t *topic, err error) {
+	r.mu.Lock()
+	if r.topics[t.name] == t {
+		delete(r.topics, t.name)
+	}
+	t.cancel()
+	subs := make([]*Subscription, 0, len(t.subs))
+	for s := range t.subs {
+		subs = append(subs, s)
+	}
+	r.mu.Unlock()
+
+	for _, s := range subs {
+		s.end(err)
+	}
+}
+
+// Close stops every source, ends every subscription with ErrClosed, and
+// waits for the sources to return.
+func (r *Registry) Close() {
+	r.mu.Lock()
+	r.closed = true
+	var subs []*Subscription
+	for _, t := range r.topics {
+		for s := range t.subs {
+			subs = append(subs, s)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, s := range subs {
+		s.end(ErrClosed)
+	}
+	r.cancel()
+	r.wg.Wait()
+}
+
+// TopicStats describes one running topic.
+type TopicStats struct {
+	Topic       string
+	Subscribers int
+}
+
+// Stats returns the running topics, sorted.
+func (r *Registry) Stats() []TopicStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := make([]TopicStats, 0, len(r.topics))
+	for _, t := range r.topics {
+		stats = append(stats, TopicStats{Topic: t.name, Subscribers: len(t.subs)})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Topic < stats[j].Topic })
+	return stats
+}
+
+// Close ends the subscription. The topic's source stops with its last
+// subscriber.
+func (s *Subscription) Close() {
+	s.end(nil)
+}
+
+// Err returns why the subscription ended: nil after Close, the client
+// context's error, ErrSlowConsumer, ErrClosed, or the source's error.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *Subscription) offer(v any) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	select {
+	case s.out <- v:
+		s.mu.Unlock()
+	default:
+		s.mu.Unlock()
+		s.end(ErrSlowConsumer)
+	}
+}
+
+// end closes C with err and releases the topic reference, once.
+func (s *Subscription) end(err error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.err = err
+	close(s.out)
+	stop := s.stop
+	s.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+
+	r, t := s.r, s.topic
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(t.subs, s)
+	if len(t.subs) == 0 && r.topics[t.name] == t {
+		delete(r.topics, t.name)
+		t.cancel()
+	}
+}
+
+// BookTopic is the topic of one token's order book.
+func BookTopic(tokenID string) string {
+	return "book:" + tokenID
+}
+
+// Books opens BookTopic topics from a book broadcaster, publishing each
+// book.Snapshot. One broadcaster subscription serves every client of a
+// token.
+func Books(b *book.Broadcaster, opts book.SubscribeOptions) Opener {
+	return func(topic string) (Source, error) {
+		token, ok := strings.CutPrefix(topic, "book:")
+		if !ok || token == "" {
+			return nil, fmt.Errorf("%w %q", ErrUnknownTopic, topic)
+		}
+		return func(ctx context.Context, publish func(any)) error {
+			sub := b.Subscribe([]string{token}, opts)
+			defer sub.Close()
+			for {
+				select {
+				case snap, ok := <-sub.C:
+					if !ok {
+						return nil
+					}
+					publish(snap)
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}, nil
+	}
+}
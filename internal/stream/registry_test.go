@@ -0,0 +1,266 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caesar-terminal/caesar/internal/book"
+)
+
+// feeds is an Opener whose sources publish whatever is sent on their
+// topic's channel, and record how many are running.
+type feeds struct {
+	mu      sync.Mutex
+	running map[string]int
+	started map[string]int
+	in      map[string]chan any
+	fail    map[string]chan error
+}
+
+func newFeeds() *feeds {
+	return &feeds{running: map[string]int{}, started: map[string]int{}, in: map[string]chan any{}, fail: map[string]chan error{}}
+}
+
+func (f *feeds) open(topic string) (Source, error) {
+	if topic == "nope" {
+		return nil, ErrUnknownTopic
+	}
+	f.mu.Lock()
+	if f.in[topic] == nil {
+		f.in[topic] = make(chan any)
+		f.fail[topic] = make(chan error, 1)
+	}
+	in, fail := f.in[topic], f.fail[topic]
+	f.mu.Unlock()
+	return func(ctx context.Context, publish func(any)) error {
+		f.mu.Lock()
+		f.running[topic]++
+		f.started[topic]++
+		f.mu.Unlock()
+		defer func() {
+			f.mu.Lock()
+			f.running[topic]--
+			f.mu.Unlock()
+		}()
+		for {
+			select {
+			case v := <-in:
+				publish(v)
+			case err := <-fail:
+				return err
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}, nil
+}
+
+func (f *feeds) count(m map[string]int, topic string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return m[topic]
+}
+
+func (f *feeds) send(topic string, v any) {
+	f.mu.Lock()
+	in := f.in[topic]
+	f.mu.Unlock()
+	in <- v
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func recv(t *testing.T, s *Subscription) any {
+	t.Helper()
+	select {
+	case v := <-s.C:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatal("no value received")
+		return nil
+	}
+}
+
+func TestRegistrySharesSource(t *testing.T) {
+	f := newFeeds()
+	r := NewRegistry(f.open)
+	defer r.Close()
+	ctx := context.Background()
+
+	a, err := r.Subscribe(ctx, "orders", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := r.Subscribe(ctx, "orders", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return f.count(f.running, "orders") == 1 })
+
+	f.send("orders", "filled")
+	if recv(t, a) != "filled" || recv(t, b) != "filled" {
+		t.Fatal("expected both subscribers to receive the value")
+	}
+	if stats := r.Stats(); len(stats) != 1 || stats[0] != (TopicStats{Topic: "orders", Subscribers: 2}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// The source runs until the last subscriber leaves.
+	a.Close()
+	f.send("orders", "cancelled")
+	if recv(t, b) != "cancelled" {
+		t.Fatal("expected the remaining subscriber to receive the value")
+	}
+	b.Close()
+	waitFor(t, func() bool { return f.count(f.running, "orders") == 0 })
+	if len(r.Stats()) != 0 {
+		t.Errorf("expected no topics, got %+v", r.Stats())
+	}
+	if _, ok := <-a.C; ok || a.Err() != nil {
+		t.Errorf("closed subscription: channel open or err %v", a.Err())
+	}
+
+	// A new subscriber starts the source again.
+	c, err := r.Subscribe(ctx, "orders", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	waitFor(t, func() bool { return f.count(f.started, "orders") == 2 })
+
+	if _, err := r.Subscribe(ctx, "nope", 1); !errors.Is(err, ErrUnknownTopic) {
+		t.Errorf("expected ErrUnknownTopic, got %v", err)
+	}
+}
+
+func TestRegistryClientDisconnect(t *testing.T) {
+	f := newFeeds()
+	r := NewRegistry(f.open)
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	books, _ := r.Subscribe(ctx, "book:1", 1)
+	events, _ := r.Subscribe(ctx, "events", 1)
+	waitFor(t, func() bool { return f.count(f.running, "book:1")+f.count(f.running, "events") == 2 })
+
+	cancel()
+	for _, s := range []*Subscription{books, events} {
+		if _, ok := <-s.C; ok {
+			t.Fatal("expected the channel closed on disconnect")
+		}
+		if !errors.Is(s.Err(), context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", s.Err())
+		}
+	}
+	waitFor(t, func() bool { return f.count(f.running, "book:1")+f.count(f.running, "events") == 0 })
+
+	if _, err := r.Subscribe(ctx, "events", 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a done context refused, got %v", err)
+	}
+}
+
+func TestRegistrySlowConsumer(t *testing.T) {
+	f := newFeeds()
+	r := NewRegistry(f.open)
+	defer r.Close()
+
+	slow, _ := r.Subscribe(context.Background(), "orders", 1)
+	fast, _ := r.Subscribe(context.Background(), "orders", 4)
+	defer fast.Close()
+
+	f.send("orders", 1)
+	f.send("orders", 2)
+	if recv(t, fast) != 1 || recv(t, fast) != 2 {
+		t.Fatal("expected the fast subscriber unaffected")
+	}
+	if recv(t, slow) != 1 {
+		t.Fatal("expected the buffered value delivered")
+	}
+	if _, ok := <-slow.C; ok || !errors.Is(slow.Err(), ErrSlowConsumer) {
+		t.Errorf("expected ErrSlowConsumer, got %v", slow.Err())
+	}
+}
+
+func TestRegistrySourceError(t *testing.T) {
+	f := newFeeds()
+	r := NewRegistry(f.open)
+	defer r.Close()
+
+	s, _ := r.Subscribe(context.Background(), "events", 1)
+	feedErr := errors.New("upstream gone")
+	f.mu.Lock()
+	f.fail["events"] <- feedErr
+	f.mu.Unlock()
+
+	if _, ok := <-s.C; ok || !errors.Is(s.Err(), feedErr) {
+		t.Errorf("expected the source error, got %v", s.Err())
+	}
+	waitFor(t, func() bool { return len(r.Stats()) == 0 })
+
+	again, err := r.Subscribe(context.Background(), "events", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer again.Close()
+	waitFor(t, func() bool { return f.count(f.started, "events") == 2 })
+}
+
+func TestRegistryClose(t *testing.T) {
+	f := newFeeds()
+	r := NewRegistry(f.open)
+	s, _ := r.Subscribe(context.Background(), "orders", 1)
+	waitFor(t, func() bool { return f.count(f.running, "orders") == 1 })
+
+	r.Close()
+	if f.count(f.running, "orders") != 0 {
+		t.Error("expected Close to wait for sources")
+	}
+	if _, ok := <-s.C; ok || !errors.Is(s.Err(), ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", s.Err())
+	}
+	if _, err := r.Subscribe(context.Background(), "orders", 1); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestBooks(t *testing.T) {
+	b := book.NewBroadcaster(nil)
+	r := NewRegistry(Books(b, book.SubscribeOptions{}))
+	defer r.Close()
+
+	s, err := r.Subscribe(context.Background(), BookTopic("tok"), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The broadcaster subscription starts asynchronously; publish until
+	// the source is attached.
+	var got any
+	waitFor(t, func() bool {
+		b.Publish(book.Snapshot{TokenID: "tok"})
+		select {
+		case got = <-s.C:
+			return true
+		default:
+			return false
+		}
+	})
+	if snap, ok := got.(book.Snapshot); !ok || snap.TokenID != "tok" {
+		t.Errorf("unexpected value %#v", got)
+	}
+
+	if _, err := r.Subscribe(context.Background(), "trades:tok", 1); !errors.Is(err, ErrUnknownTopic) {
+		t.Errorf("expected ErrUnknownTopic, got %v", err)
+	}
+}